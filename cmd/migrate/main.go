@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/company/payment-service/internal/infrastructure/config"
+	"github.com/company/payment-service/internal/infrastructure/database"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+)
+
+// migrate 對 database.* 設定的主資料庫執行 scripts/migrations 下尚未套用的遷移
+func main() {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found")
+	}
+
+	cfg, err := config.LoadConfig("")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	dbConfig := database.Config{
+		Host:     cfg.Database.Host,
+		Port:     cfg.Database.Port,
+		User:     cfg.Database.User,
+		Password: cfg.Database.Password,
+		DBName:   cfg.Database.DBName,
+		SSLMode:  cfg.Database.SSLMode,
+		Path:     cfg.Database.Path,
+	}
+
+	var db *sqlx.DB
+	if cfg.Database.Driver == "sqlite" {
+		db, err = database.NewSQLiteConnection(dbConfig)
+	} else {
+		db, err = database.NewPostgresConnection(dbConfig)
+	}
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	if err := database.Migrate(context.Background(), db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+	version, err := database.LatestMigrationVersion(db.DriverName())
+	if err != nil {
+		log.Fatalf("Failed to read migrations: %v", err)
+	}
+	log.Printf("Database migrated to version %d", version)
+}
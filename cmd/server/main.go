@@ -2,6 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
 	"fmt"
 	"log"
 	"net/http"
@@ -11,10 +14,22 @@ import (
 	"time"
 
 	httpdelivery "github.com/company/payment-service/internal/delivery/http"
+	"github.com/company/payment-service/internal/delivery/scheduler"
+	"github.com/company/payment-service/internal/delivery/worker"
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
 	"github.com/company/payment-service/internal/domain/usecase"
 	"github.com/company/payment-service/internal/infrastructure/config"
 	"github.com/company/payment-service/internal/infrastructure/database"
+	"github.com/company/payment-service/internal/infrastructure/filestore"
+	"github.com/company/payment-service/internal/infrastructure/memory"
+	"github.com/company/payment-service/internal/infrastructure/metrics"
+	"github.com/company/payment-service/internal/infrastructure/tracing"
+	"github.com/company/payment-service/internal/infrastructure/vault"
+	"github.com/company/payment-service/pkg/health"
 	"github.com/company/payment-service/pkg/logger"
+	"github.com/company/payment-service/pkg/webhook"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
@@ -32,7 +47,7 @@ func main() {
 	}
 
 	// 初始化日誌
-	logger, err := logger.NewLogger(logger.Config{
+	appLogger, err := logger.NewLogger(logger.Config{
 		Level:      cfg.Logger.Level,
 		Format:     cfg.Logger.Format,
 		OutputPath: cfg.Logger.OutputPath,
@@ -40,33 +55,339 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to initialize logger: %v", err)
 	}
+	// 背景工作等沒有請求 context 的地方也使用同一個 logger
+	logger.SetDefault(appLogger)
 
-	// 初始化數據庫連接
-	dbConfig := database.Config{
-		Host:     cfg.Database.Host,
-		Port:     cfg.Database.Port,
-		User:     cfg.Database.User,
-		Password: cfg.Database.Password,
-		DBName:   cfg.Database.DBName,
-		SSLMode:  cfg.Database.SSLMode,
+	// 初始化追蹤
+	if cfg.Tracing.Enabled {
+		shutdownTracing, err := tracing.Setup(context.Background(), tracing.Config{
+			ServiceName:    cfg.App.Name,
+			ServiceVersion: cfg.App.Version,
+			Environment:    cfg.App.Environment,
+			Exporter:       cfg.Tracing.Exporter,
+			Endpoint:       cfg.Tracing.Endpoint,
+			Insecure:       cfg.Tracing.Insecure,
+			SampleRatio:    cfg.Tracing.SampleRatio,
+		})
+		if err != nil {
+			appLogger.Fatal("Failed to initialize tracing", zap.Error(err))
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdownTracing(ctx); err != nil {
+				appLogger.Error("Failed to flush traces", zap.Error(err))
+			}
+		}()
 	}
 
-	db, err := database.NewPostgresConnection(dbConfig)
-	if err != nil {
-		logger.Fatal("Failed to connect to database", zap.Error(err))
+	// 初始化指標
+	var appMetrics *metrics.Metrics
+	if cfg.Metrics.Enabled {
+		appMetrics = metrics.New()
 	}
-	defer db.Close()
 
 	// 初始化 repositories
-	paymentRepo := database.NewPaymentRepository(db)
-	merchantRepo := database.NewMerchantRepository(db)
-	customerRepo := database.NewCustomerRepository(db)
+	var (
+		paymentRepo   repository.PaymentRepository
+		merchantRepo  repository.MerchantRepository
+		customerRepo  repository.CustomerRepository
+		cardRepo      repository.CardRepository
+		methodRepo    repository.PaymentMethodRepository
+		planRepo      repository.PlanRepository
+		subRepo       repository.SubscriptionRepository
+		invoiceRepo   repository.InvoiceRepository
+		checkoutRepo  repository.CheckoutSessionRepository
+		linkRepo      repository.PaymentLinkRepository
+		jobRepo       repository.JobRepository
+		transferRepo  repository.BankTransferRepository
+		creditRepo    repository.BankCreditRepository
+		statementRepo repository.StatementRepository
+		actionRepo    repository.WalletActionRepository
+		webhookRepo   repository.WebhookEventRepository
+		disputeRepo   repository.DisputeRepository
+		ledgerRepo    repository.LedgerRepository
+		riskRepo      repository.RiskRepository
+		limitRepo     repository.MerchantLimitRepository
+		rateLimitRepo repository.RateLimitRepository
+		nonceRepo     repository.NonceRepository
+		dbStats       func() map[string]sql.DBStats
+		checkers      []health.Checker
+	)
+
+	switch cfg.Database.Driver {
+	case "memory":
+		// 記憶體儲存僅供本地開發與測試，重啟後資料即消失
+		store := memory.NewStore()
+		store.LoadSampleData()
+
+		paymentRepo = memory.NewPaymentRepository(store)
+		merchantRepo = memory.NewMerchantRepository(store)
+		customerRepo = memory.NewCustomerRepository(store)
+		cardRepo = memory.NewCardRepository(store)
+		methodRepo = memory.NewPaymentMethodRepository(store)
+		planRepo = memory.NewPlanRepository(store)
+		subRepo = memory.NewSubscriptionRepository(store)
+		invoiceRepo = memory.NewInvoiceRepository(store)
+		checkoutRepo = memory.NewCheckoutSessionRepository(store)
+		linkRepo = memory.NewPaymentLinkRepository(store)
+		jobRepo = memory.NewJobRepository(store)
+		transferRepo = memory.NewBankTransferRepository(store)
+		creditRepo = memory.NewBankCreditRepository(store)
+		statementRepo = memory.NewStatementRepository(store)
+		actionRepo = memory.NewWalletActionRepository(store)
+		webhookRepo = memory.NewWebhookEventRepository(store)
+		disputeRepo = memory.NewDisputeRepository(store)
+		ledgerRepo = memory.NewLedgerRepository(store)
+		riskRepo = memory.NewRiskRepository(store)
+		limitRepo = memory.NewMerchantLimitRepository(store)
+		rateLimitRepo = memory.NewRateLimitRepository(store)
+		nonceRepo = memory.NewNonceRepository(store)
+		appLogger.Warn("Using in-memory storage, data will not be persisted")
+	case "postgres", "sqlite", "":
+		cluster, err := openDatabase(cfg.Database)
+		if err != nil {
+			appLogger.Fatal("Failed to connect to database", zap.Error(err))
+		}
+		defer cluster.Close()
+
+		if cfg.Database.AutoMigrate {
+			if err := database.Migrate(context.Background(), cluster.Primary()); err != nil {
+				appLogger.Fatal("Failed to migrate database", zap.Error(err))
+			}
+		}
+
+		paymentRepo = database.NewPaymentRepository(cluster)
+		merchantRepo = database.NewMerchantRepository(cluster)
+		customerRepo = database.NewCustomerRepository(cluster)
+		cardRepo = database.NewCardRepository(cluster)
+		methodRepo = database.NewPaymentMethodRepository(cluster)
+		planRepo = database.NewPlanRepository(cluster)
+		subRepo = database.NewSubscriptionRepository(cluster)
+		invoiceRepo = database.NewInvoiceRepository(cluster)
+		checkoutRepo = database.NewCheckoutSessionRepository(cluster)
+		linkRepo = database.NewPaymentLinkRepository(cluster)
+		jobRepo = database.NewJobRepository(cluster)
+		transferRepo = database.NewBankTransferRepository(cluster)
+		creditRepo = database.NewBankCreditRepository(cluster)
+		statementRepo = database.NewStatementRepository(cluster)
+		actionRepo = database.NewWalletActionRepository(cluster)
+		webhookRepo = database.NewWebhookEventRepository(cluster)
+		disputeRepo = database.NewDisputeRepository(cluster)
+		ledgerRepo = database.NewLedgerRepository(cluster)
+		riskRepo = database.NewRiskRepository(cluster)
+		limitRepo = database.NewMerchantLimitRepository(cluster)
+		switch cfg.RateLimit.Store {
+		case "database":
+			rateLimitRepo = database.NewRateLimitRepository(cluster)
+		case "memory", "":
+			// 限流 bucket 不需持久化，單一實例使用獨立的記憶體儲存
+			rateLimitRepo = memory.NewRateLimitRepository(memory.NewStore())
+		default:
+			appLogger.Fatal("Unsupported rate limit store", zap.String("store", cfg.RateLimit.Store))
+		}
+		switch cfg.RequestSigning.NonceStore {
+		case "database":
+			nonceRepo = database.NewNonceRepository(cluster)
+		case "memory", "":
+			nonceRepo = memory.NewNonceRepository(memory.NewStore())
+		default:
+			appLogger.Fatal("Unsupported nonce store", zap.String("store", cfg.RequestSigning.NonceStore))
+		}
+		dbStats = cluster.Stats
+		if appMetrics != nil {
+			for name, db := range cluster.Pools() {
+				appMetrics.RegisterDBStats(name, db.DB)
+			}
+		}
+		checkers = append(cluster.HealthCheckers(), database.MigrationChecker(cluster.Primary()))
+	default:
+		appLogger.Fatal("Unsupported database driver", zap.String("driver", cfg.Database.Driver))
+	}
+
+	var (
+		observers       []usecase.PaymentObserver
+		metricsRecorder httpdelivery.MetricsRecorder
+	)
+	if appMetrics != nil {
+		paymentRepo = metrics.InstrumentPaymentRepository(paymentRepo, appMetrics)
+		merchantRepo = metrics.InstrumentMerchantRepository(merchantRepo, appMetrics)
+		customerRepo = metrics.InstrumentCustomerRepository(customerRepo, appMetrics)
+		cardRepo = metrics.InstrumentCardRepository(cardRepo, appMetrics)
+		methodRepo = metrics.InstrumentPaymentMethodRepository(methodRepo, appMetrics)
+		planRepo = metrics.InstrumentPlanRepository(planRepo, appMetrics)
+		subRepo = metrics.InstrumentSubscriptionRepository(subRepo, appMetrics)
+		invoiceRepo = metrics.InstrumentInvoiceRepository(invoiceRepo, appMetrics)
+		checkoutRepo = metrics.InstrumentCheckoutSessionRepository(checkoutRepo, appMetrics)
+		linkRepo = metrics.InstrumentPaymentLinkRepository(linkRepo, appMetrics)
+		jobRepo = metrics.InstrumentJobRepository(jobRepo, appMetrics)
+		transferRepo = metrics.InstrumentBankTransferRepository(transferRepo, appMetrics)
+		creditRepo = metrics.InstrumentBankCreditRepository(creditRepo, appMetrics)
+		statementRepo = metrics.InstrumentStatementRepository(statementRepo, appMetrics)
+		actionRepo = metrics.InstrumentWalletActionRepository(actionRepo, appMetrics)
+		webhookRepo = metrics.InstrumentWebhookEventRepository(webhookRepo, appMetrics)
+		disputeRepo = metrics.InstrumentDisputeRepository(disputeRepo, appMetrics)
+		ledgerRepo = metrics.InstrumentLedgerRepository(ledgerRepo, appMetrics)
+		riskRepo = metrics.InstrumentRiskRepository(riskRepo, appMetrics)
+		limitRepo = metrics.InstrumentMerchantLimitRepository(limitRepo, appMetrics)
+		rateLimitRepo = metrics.InstrumentRateLimitRepository(rateLimitRepo, appMetrics)
+		nonceRepo = metrics.InstrumentNonceRepository(nonceRepo, appMetrics)
+		observers = append(observers, appMetrics)
+		metricsRecorder = appMetrics
+	}
+	if cfg.Tracing.Enabled {
+		paymentRepo = tracing.TracePaymentRepository(paymentRepo)
+		merchantRepo = tracing.TraceMerchantRepository(merchantRepo)
+		customerRepo = tracing.TraceCustomerRepository(customerRepo)
+		cardRepo = tracing.TraceCardRepository(cardRepo)
+		methodRepo = tracing.TracePaymentMethodRepository(methodRepo)
+		planRepo = tracing.TracePlanRepository(planRepo)
+		subRepo = tracing.TraceSubscriptionRepository(subRepo)
+		invoiceRepo = tracing.TraceInvoiceRepository(invoiceRepo)
+		checkoutRepo = tracing.TraceCheckoutSessionRepository(checkoutRepo)
+		linkRepo = tracing.TracePaymentLinkRepository(linkRepo)
+		jobRepo = tracing.TraceJobRepository(jobRepo)
+		transferRepo = tracing.TraceBankTransferRepository(transferRepo)
+		creditRepo = tracing.TraceBankCreditRepository(creditRepo)
+		statementRepo = tracing.TraceStatementRepository(statementRepo)
+		actionRepo = tracing.TraceWalletActionRepository(actionRepo)
+		webhookRepo = tracing.TraceWebhookEventRepository(webhookRepo)
+		disputeRepo = tracing.TraceDisputeRepository(disputeRepo)
+		ledgerRepo = tracing.TraceLedgerRepository(ledgerRepo)
+		riskRepo = tracing.TraceRiskRepository(riskRepo)
+		limitRepo = tracing.TraceMerchantLimitRepository(limitRepo)
+		rateLimitRepo = tracing.TraceRateLimitRepository(rateLimitRepo)
+		nonceRepo = tracing.TraceNonceRepository(nonceRepo)
+	}
+
+	// 初始化卡片保險庫
+	if cfg.Vault.KEK == "" {
+		appLogger.Warn("No vault KEK configured, using an ephemeral key; vaulted cards will be unreadable after restart")
+	}
+	keyring, err := openVault(cfg.Vault)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize card vault", zap.Error(err))
+	}
 
 	// 初始化 use cases
-	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo)
+	walletConfig := usecase.WalletConfig{
+		RedirectURL:    cfg.Wallet.RedirectURL,
+		ActionTimeout:  cfg.Wallet.ActionTimeout,
+		CallbackSecret: cfg.Wallet.CallbackSecret,
+	}
+	var riskEngine usecase.RiskEngine
+	if cfg.Risk.Enabled {
+		riskEngine, err = usecase.NewRiskEngine(riskRepo, riskConfig(cfg.Risk))
+		if err != nil {
+			appLogger.Fatal("Failed to configure risk rules", zap.Error(err))
+		}
+	}
+	limitUseCase := usecase.NewLimitUseCase(limitRepo, merchantRepo)
+	var rateLimiter usecase.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter, err = usecase.NewRateLimiter(rateLimitRepo, rateLimitConfig(cfg.RateLimit))
+		if err != nil {
+			appLogger.Fatal("Failed to configure rate limits", zap.Error(err))
+		}
+	}
+	var requestVerifier usecase.RequestVerifier
+	if cfg.RequestSigning.Enabled {
+		requestVerifier = usecase.NewRequestVerifier(merchantRepo, nonceRepo, cfg.RequestSigning.Tolerance)
+	}
+	paymentUseCase := usecase.NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, cardRepo, methodRepo, invoiceRepo, jobRepo, transferRepo, usecase.BankTransferConfig{
+		ExpiresIn:     cfg.BankTransfer.ExpiresIn,
+		AccountPrefix: cfg.BankTransfer.AccountPrefix,
+		BankName:      cfg.BankTransfer.BankName,
+	}, actionRepo, walletConfig, riskEngine, limitUseCase, linkRepo, observers...)
+	if cfg.Tracing.Enabled {
+		paymentUseCase = tracing.TracePaymentUseCase(paymentUseCase)
+	}
+	vaultUseCase := usecase.NewVaultUseCase(cardRepo, keyring)
+	paymentMethodUseCase := usecase.NewPaymentMethodUseCase(methodRepo, customerRepo, cardRepo)
+	invoiceUseCase := usecase.NewInvoiceUseCase(invoiceRepo, merchantRepo, customerRepo)
+	checkoutUseCase := usecase.NewCheckoutUseCase(checkoutRepo, linkRepo, merchantRepo, customerRepo, paymentUseCase, vaultUseCase, cfg.Checkout.BaseURL)
+	paymentLinkUseCase := usecase.NewPaymentLinkUseCase(linkRepo, paymentRepo, merchantRepo, customerRepo, checkoutUseCase, cfg.Checkout.BaseURL)
+	subscriptionUseCase := usecase.NewSubscriptionUseCase(planRepo, subRepo, customerRepo, methodRepo, paymentRepo, paymentUseCase, usecase.DunningConfig{
+		RetrySchedule:     cfg.Billing.RetrySchedule,
+		CancelOnExhausted: cfg.Billing.CancelOnExhausted,
+	})
+	jobUseCase := usecase.NewJobUseCase(jobRepo, paymentUseCase, usecase.RetryPolicy{
+		MaxAttempts:       cfg.Worker.MaxAttempts,
+		BackoffBase:       cfg.Worker.BackoffBase,
+		BackoffMax:        cfg.Worker.BackoffMax,
+		VisibilityTimeout: cfg.Worker.VisibilityTimeout,
+	})
+
+	bankTransferUseCase := usecase.NewBankTransferUseCase(transferRepo, creditRepo, paymentUseCase)
+	walletUseCase := usecase.NewWalletUseCase(actionRepo, paymentUseCase, walletConfig)
+	verifiers, err := webhookVerifiers(cfg.Webhooks)
+	if err != nil {
+		appLogger.Fatal("Failed to configure gateway webhooks", zap.Error(err))
+	}
+	webhookUseCase := usecase.NewWebhookUseCase(webhookRepo, paymentUseCase, verifiers)
+	evidenceStore, err := filestore.NewLocal(cfg.Disputes.EvidenceDir)
+	if err != nil {
+		appLogger.Fatal("Failed to initialize dispute evidence storage", zap.Error(err))
+	}
+	disputeUseCase := usecase.NewDisputeUseCase(disputeRepo, paymentRepo, ledgerRepo, evidenceStore, usecase.DisputeConfig{
+		ResponseWindow:  cfg.Disputes.ResponseWindow,
+		MaxEvidenceSize: cfg.Disputes.MaxEvidenceSize,
+	})
+	reconciliationUseCase := usecase.NewReconciliationUseCase(statementRepo, paymentRepo, transferRepo, usecase.ReconciliationConfig{
+		DateTolerance: cfg.Reconciliation.DateTolerance,
+	})
+
+	merchantUseCase := usecase.NewMerchantUseCase(merchantRepo)
+	cors, err := httpdelivery.NewCORSPolicy(httpdelivery.CORSConfig{
+		AllowedOrigins:   cfg.CORS.AllowedOrigins,
+		AllowedMethods:   cfg.CORS.AllowedMethods,
+		AllowedHeaders:   cfg.CORS.AllowedHeaders,
+		ExposedHeaders:   cfg.CORS.ExposedHeaders,
+		MaxAge:           cfg.CORS.MaxAge,
+		AllowCredentials: cfg.CORS.AllowCredentials,
+	})
+	if err != nil {
+		appLogger.Fatal("Failed to configure CORS", zap.Error(err))
+	}
+
+	// 健康檢查
+	healthHandler := httpdelivery.NewHealthHandler(httpdelivery.HealthConfig{
+		Version: httpdelivery.VersionInfo{
+			Name:        cfg.App.Name,
+			Version:     cfg.App.Version,
+			Environment: cfg.App.Environment,
+		},
+		Checkers:     checkers,
+		CheckTimeout: cfg.Server.HealthCheckTimeout,
+		DBStats:      dbStats,
+	})
 
 	// 設置路由
-	router := httpdelivery.SetupRouter(paymentUseCase, merchantRepo)
+	router := httpdelivery.SetupRouter(httpdelivery.RouterConfig{
+		PaymentUseCase:        paymentUseCase,
+		VaultUseCase:          vaultUseCase,
+		PaymentMethodUseCase:  paymentMethodUseCase,
+		SubscriptionUseCase:   subscriptionUseCase,
+		InvoiceUseCase:        invoiceUseCase,
+		CheckoutUseCase:       checkoutUseCase,
+		PaymentLinkUseCase:    paymentLinkUseCase,
+		BankTransferUseCase:   bankTransferUseCase,
+		ReconciliationUseCase: reconciliationUseCase,
+		WalletUseCase:         walletUseCase,
+		WebhookUseCase:        webhookUseCase,
+		DisputeUseCase:        disputeUseCase,
+		LimitUseCase:          limitUseCase,
+		MerchantUseCase:       merchantUseCase,
+		RateLimiter:           rateLimiter,
+		RequestVerifier:       requestVerifier,
+		CORS:                  cors,
+		AdminAPIKey:           cfg.Admin.APIKey,
+		MerchantRepo:          merchantRepo,
+		Health:                healthHandler,
+		Logger:                appLogger,
+		Metrics:               metricsRecorder,
+		MetricsPath:           cfg.Metrics.Path,
+	})
 
 	// 創建 HTTP 服務器
 	server := &http.Server{
@@ -78,25 +399,252 @@ func main() {
 
 	// 啟動服務器
 	go func() {
-		logger.Info(fmt.Sprintf("Starting server on %s:%d", cfg.Server.Host, cfg.Server.Port))
+		appLogger.Info(fmt.Sprintf("Starting server on %s:%d", cfg.Server.Host, cfg.Server.Port))
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("Failed to start server", zap.Error(err))
+			appLogger.Fatal("Failed to start server", zap.Error(err))
 		}
 	}()
 
+	// 啟動訂閱續期排程
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	schedulerDone := make(chan struct{})
+	if cfg.Billing.Enabled {
+		billing := scheduler.NewBillingScheduler(subscriptionUseCase, cfg.Billing.Interval, cfg.Billing.BatchSize)
+		go func() {
+			defer close(schedulerDone)
+			billing.Run(schedulerCtx)
+		}()
+	} else {
+		close(schedulerDone)
+	}
+
+	// 啟動銀行轉帳逾期取消排程
+	expiryCtx, stopExpiry := context.WithCancel(context.Background())
+	expiryDone := make(chan struct{})
+	if cfg.BankTransfer.SweepEnabled {
+		expiry := scheduler.NewBankTransferExpiry(bankTransferUseCase, cfg.BankTransfer.SweepInterval, cfg.BankTransfer.BatchSize)
+		go func() {
+			defer close(expiryDone)
+			expiry.Run(expiryCtx)
+		}()
+	} else {
+		close(expiryDone)
+	}
+
+	// 啟動錢包確認逾時排程
+	walletCtx, stopWallet := context.WithCancel(context.Background())
+	walletDone := make(chan struct{})
+	if cfg.Wallet.SweepEnabled {
+		walletExpiry := scheduler.NewWalletActionExpiry(walletUseCase, cfg.Wallet.SweepInterval, cfg.Wallet.BatchSize)
+		go func() {
+			defer close(walletDone)
+			walletExpiry.Run(walletCtx)
+		}()
+	} else {
+		close(walletDone)
+	}
+
+	// 啟動背景工作 worker
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	workersDone := make(chan struct{})
+	if cfg.Worker.Enabled {
+		pool := worker.NewPool(jobUseCase, cfg.Worker.Concurrency, cfg.Worker.PollInterval)
+		go func() {
+			defer close(workersDone)
+			pool.Run(workerCtx)
+		}()
+	} else {
+		close(workersDone)
+	}
+
 	// 優雅關閉
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
-	logger.Info("Shutting down server...")
+	appLogger.Info("Shutting down server...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	// 先讓 readiness 失敗，等待負載平衡器摘除流量，期間仍正常服務請求與背景工作
+	healthHandler.SetShuttingDown()
+	time.Sleep(cfg.Server.ShutdownDelay)
+
+	// 停止排程與 worker 取得新批次，與 HTTP 關閉同時進行；
+	// 進行中的續期與請款會做完，未取得的工作留在佇列由下次啟動處理
+	stopScheduler()
+	stopExpiry()
+	stopWallet()
+	stopWorkers()
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := server.Shutdown(ctx); err != nil {
-		logger.Fatal("Server forced to shutdown", zap.Error(err))
+		appLogger.Error("Server forced to shutdown", zap.Error(err))
+	}
+
+	<-schedulerDone
+	<-expiryDone
+	<-walletDone
+	<-workersDone
+
+	appLogger.Info("Server exited")
+}
+
+func openDatabase(cfg config.DatabaseConfig) (*database.Cluster, error) {
+	// 初始化數據庫連接
+	dbConfig := database.Config{
+		Host:            cfg.Host,
+		Port:            cfg.Port,
+		User:            cfg.User,
+		Password:        cfg.Password,
+		DBName:          cfg.DBName,
+		SSLMode:         cfg.SSLMode,
+		Path:            cfg.Path,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: cfg.ConnMaxLifetime,
+	}
+
+	if cfg.Driver == "sqlite" {
+		if len(cfg.Replicas) > 0 {
+			return nil, fmt.Errorf("read replicas are not supported with sqlite")
+		}
+		db, err := database.NewSQLiteConnection(dbConfig)
+		if err != nil {
+			return nil, err
+		}
+		return database.NewCluster(db), nil
+	}
+
+	primary, err := database.NewPostgresConnection(dbConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var replicas []*sqlx.DB
+	for _, r := range cfg.Replicas {
+		replicaConfig := dbConfig
+		replicaConfig.Host = r.Host
+		if r.Port != 0 {
+			replicaConfig.Port = r.Port
+		}
+		if r.User != "" {
+			replicaConfig.User = r.User
+		}
+		if r.Password != "" {
+			replicaConfig.Password = r.Password
+		}
+
+		replica, err := database.NewPostgresConnection(replicaConfig)
+		if err != nil {
+			database.NewCluster(primary, replicas...).Close()
+			return nil, fmt.Errorf("replica %s: %w", r.Host, err)
+		}
+		replicas = append(replicas, replica)
+	}
+
+	return database.NewCluster(primary, replicas...), nil
+}
+
+func openVault(cfg config.VaultConfig) (*vault.Keyring, error) {
+	kek := make([]byte, 32)
+	if cfg.KEK == "" {
+		if _, err := rand.Read(kek); err != nil {
+			return nil, err
+		}
+	} else {
+		decoded, err := base64.StdEncoding.DecodeString(cfg.KEK)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vault kek: %w", err)
+		}
+		kek = decoded
+	}
+
+	keyring, err := vault.NewLocalKeyring(cfg.KeyID, kek)
+	if err != nil {
+		return nil, err
+	}
+	for keyID, encoded := range cfg.PreviousKeys {
+		previous, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid vault key %s: %w", keyID, err)
+		}
+		if err := keyring.AddDecryptionKey(keyID, previous); err != nil {
+			return nil, err
+		}
+	}
+	return keyring, nil
+}
+
+// webhookVerifiers 依設定建立各網關的簽章驗證
+func webhookVerifiers(cfg config.WebhookConfig) (map[string]webhook.Verifier, error) {
+	verifiers := make(map[string]webhook.Verifier, len(cfg.Providers))
+	for provider, p := range cfg.Providers {
+		verifier, err := webhook.NewVerifier(p.Scheme, p.Secret, p.Header, p.Tolerance)
+		if err != nil {
+			return nil, fmt.Errorf("webhook provider %s: %w", provider, err)
+		}
+		verifiers[provider] = verifier
+	}
+	return verifiers, nil
+}
+
+// riskConfig 將設定轉為風險引擎的規則
+func riskConfig(cfg config.RiskConfig) usecase.RiskConfig {
+	rc := usecase.RiskConfig{
+		ReviewScore:      cfg.ReviewScore,
+		BlockScore:       cfg.BlockScore,
+		AmountThresholds: make(map[string]usecase.RiskAmountThreshold, len(cfg.AmountThresholds)),
+		FirstTimeCustomer: usecase.RiskFirstTimeRule{
+			Amounts: cfg.FirstTimeCustomer.Amounts,
+			Score:   cfg.FirstTimeCustomer.Score,
+		},
+		Blocklist: usecase.RiskBlocklist{
+			CustomerIDs:  cfg.Blocklist.CustomerIDs,
+			Emails:       cfg.Blocklist.Emails,
+			EmailDomains: cfg.Blocklist.EmailDomains,
+			IPs:          cfg.Blocklist.IPs,
+		},
+	}
+	for currency, t := range cfg.AmountThresholds {
+		rc.AmountThresholds[currency] = usecase.RiskAmountThreshold{Review: t.Review, Block: t.Block}
+	}
+	for _, v := range cfg.Velocity {
+		rc.Velocity = append(rc.Velocity, usecase.RiskVelocityRule{
+			Key:      entity.RiskVelocityKey(v.Key),
+			Window:   v.Window,
+			MaxCount: v.MaxCount,
+			Score:    v.Score,
+		})
+	}
+	return rc
+}
+
+// rateLimitConfig 將設定轉為請求頻率限制
+func rateLimitConfig(cfg config.RateLimitConfig) usecase.RateLimitConfig {
+	rc := usecase.RateLimitConfig{
+		Default: rateLimit(cfg.Default),
+		Tiers:   rateLimitTiers(cfg.Tiers),
+	}
+	for _, r := range cfg.Routes {
+		rc.Routes = append(rc.Routes, usecase.RateLimitRoute{
+			Method: r.Method,
+			Path:   r.Path,
+			Limit:  rateLimit(r.RateLimitRule),
+			Tiers:  rateLimitTiers(r.Tiers),
+		})
+	}
+	return rc
+}
+
+func rateLimitTiers(tiers map[string]config.RateLimitRule) map[string]entity.RateLimit {
+	limits := make(map[string]entity.RateLimit, len(tiers))
+	for tier, rule := range tiers {
+		limits[tier] = rateLimit(rule)
 	}
+	return limits
+}
 
-	logger.Info("Server exited")
-}
\ No newline at end of file
+func rateLimit(rule config.RateLimitRule) entity.RateLimit {
+	return entity.RateLimit{Requests: rule.Requests, Period: rule.Period, Burst: rule.Burst}
+}
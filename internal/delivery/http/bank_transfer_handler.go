@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+// maxStatementSize 限制單次匯入的對帳單大小
+const maxStatementSize = 10 << 20
+
+type BankTransferHandler struct {
+	bankTransferUseCase usecase.BankTransferUseCase
+}
+
+func NewBankTransferHandler(bankTransferUseCase usecase.BankTransferUseCase) *BankTransferHandler {
+	return &BankTransferHandler{
+		bankTransferUseCase: bankTransferUseCase,
+	}
+}
+
+type IngestBankCreditsRequest struct {
+	Credits []usecase.BankCreditRequest `json:"credits"`
+}
+
+// IngestCredits 接收 {"credits": [...]} 或直接傳入入帳陣列
+func (h *BankTransferHandler) IngestCredits(c *gin.Context) {
+	body, ok := readStatement(c)
+	if !ok {
+		return
+	}
+
+	var req IngestBankCreditsRequest
+	var err error
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &req.Credits)
+	} else {
+		err = json.Unmarshal(body, &req)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	credits, err := h.bankTransferUseCase.IngestCredits(c.Request.Context(), req.Credits)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    credits,
+		Message: "Bank credits ingested successfully",
+	})
+}
+
+// ImportStatement 匯入 CSV 格式的銀行對帳單
+func (h *BankTransferHandler) ImportStatement(c *gin.Context) {
+	body, ok := readStatement(c)
+	if !ok {
+		return
+	}
+
+	credits, err := h.bankTransferUseCase.ImportCSV(c.Request.Context(), bytes.NewReader(body))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    credits,
+		Message: "Bank statement imported successfully",
+	})
+}
+
+// readStatement 先讀入完整的請求再匯入，超過 maxStatementSize 時回應 413，
+// 不會只匯入截斷前的部分
+func readStatement(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxStatementSize))
+	if err != nil {
+		status, message := http.StatusBadRequest, "Invalid request body: "+err.Error()
+		if bodyTooLarge(err) {
+			status, message = http.StatusRequestEntityTooLarge, "Statement is too large"
+		}
+		c.JSON(status, CreatePaymentResponse{
+			Success: false,
+			Error:   message,
+		})
+		return nil, false
+	}
+	return body, true
+}
+
+func (h *BankTransferHandler) ListCredits(c *gin.Context) {
+	status := entity.BankCreditStatus(c.Query("status"))
+	switch status {
+	case "", entity.BankCreditStatusMatched, entity.BankCreditStatusUnmatched:
+	default:
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid status",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	credits, err := h.bankTransferUseCase.ListCredits(c.Request.Context(), status, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    credits,
+	})
+}
+
+func (h *BankTransferHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
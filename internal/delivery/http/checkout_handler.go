@@ -0,0 +1,241 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CheckoutHandler struct {
+	checkoutUseCase usecase.CheckoutUseCase
+}
+
+func NewCheckoutHandler(checkoutUseCase usecase.CheckoutUseCase) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutUseCase: checkoutUseCase,
+	}
+}
+
+func (h *CheckoutHandler) CreateSession(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+
+	var req usecase.CreateCheckoutSessionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchant.ID
+
+	session, err := h.checkoutUseCase.CreateSession(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    session,
+		Message: "Checkout session created successfully",
+	})
+}
+
+func (h *CheckoutHandler) GetSession(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.checkoutUseCase.GetSession(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    session,
+	})
+}
+
+func (h *CheckoutHandler) ExpireSession(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.checkoutUseCase.ExpireSession(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    session,
+		Message: "Checkout session expired successfully",
+	})
+}
+
+// ShowPage 顯示代管付款頁
+func (h *CheckoutHandler) ShowPage(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.pageError(c, http.StatusNotFound, "This checkout could not be found.")
+		return
+	}
+
+	checkout, err := h.checkoutUseCase.GetHostedCheckout(c.Request.Context(), id)
+	if err != nil {
+		h.pageError(c, errorStatus(err, http.StatusInternalServerError), publicErrorMessage(err))
+		return
+	}
+	h.render(c, http.StatusOK, checkoutPage{HostedCheckout: checkout})
+}
+
+// Complete 處理付款頁送出的表單，成功時導回商戶的 success_url；
+// 銀行轉帳則先顯示匯款資訊
+func (h *CheckoutHandler) Complete(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.pageError(c, http.StatusNotFound, "This checkout could not be found.")
+		return
+	}
+
+	req := usecase.CompleteCheckoutRequest{
+		SessionID: id,
+		Method:    entity.PaymentMethod(c.PostForm("method")),
+		Email:     c.PostForm("email"),
+		Name:      c.PostForm("name"),
+		ClientIP:  c.ClientIP(),
+	}
+	if req.Method == entity.PaymentMethodCreditCard {
+		req.Card = usecase.TokenizeCardRequest{
+			Number:   c.PostForm("number"),
+			ExpMonth: formInt(c, "exp_month"),
+			ExpYear:  formInt(c, "exp_year"),
+			CVV:      strings.TrimSpace(c.PostForm("cvv")),
+		}
+	}
+
+	result, err := h.checkoutUseCase.CompleteSession(c.Request.Context(), req)
+	if err != nil {
+		h.rerender(c, id, err, req)
+		return
+	}
+
+	// 銀行轉帳需要付款人看到匯款資訊後再返回商戶
+	if result.Payment != nil && result.Payment.BankTransfer != nil {
+		checkout, err := h.checkoutUseCase.GetHostedCheckout(c.Request.Context(), id)
+		if err == nil {
+			checkout.Payment = result.Payment
+			h.render(c, http.StatusOK, checkoutPage{HostedCheckout: checkout, ContinueURL: result.RedirectURL})
+			return
+		}
+	}
+	c.Redirect(http.StatusSeeOther, result.RedirectURL)
+}
+
+// Cancel 取消結帳並導回商戶的 cancel_url
+func (h *CheckoutHandler) Cancel(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.pageError(c, http.StatusNotFound, "This checkout could not be found.")
+		return
+	}
+
+	result, err := h.checkoutUseCase.CancelSession(c.Request.Context(), id)
+	if err != nil {
+		h.rerender(c, id, err, usecase.CompleteCheckoutRequest{})
+		return
+	}
+	c.Redirect(http.StatusSeeOther, result.RedirectURL)
+}
+
+// rerender 帶著錯誤訊息與已填寫的欄位重新顯示付款頁
+func (h *CheckoutHandler) rerender(c *gin.Context, id uuid.UUID, cause error, req usecase.CompleteCheckoutRequest) {
+	status := errorStatus(cause, http.StatusInternalServerError)
+	checkout, err := h.checkoutUseCase.GetHostedCheckout(c.Request.Context(), id)
+	if err != nil {
+		h.pageError(c, errorStatus(err, http.StatusInternalServerError), publicErrorMessage(err))
+		return
+	}
+	h.render(c, status, checkoutPage{
+		HostedCheckout: checkout,
+		Error:          publicErrorMessage(cause),
+		Email:          req.Email,
+		Name:           req.Name,
+		Method:         req.Method,
+	})
+}
+
+func (h *CheckoutHandler) render(c *gin.Context, status int, page checkoutPage) {
+	body, err := renderCheckoutPage(page)
+	if err != nil {
+		h.pageError(c, http.StatusInternalServerError, publicErrorMessage(err))
+		return
+	}
+	setCheckoutHeaders(c)
+	c.Data(status, "text/html; charset=utf-8", body)
+}
+
+func (h *CheckoutHandler) pageError(c *gin.Context, status int, message string) {
+	setCheckoutHeaders(c)
+	c.String(status, message)
+}
+
+// setCheckoutHeaders 付款頁含有卡片欄位，禁止快取與被嵌入其他網站的 frame
+func setCheckoutHeaders(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.Header("X-Frame-Options", "DENY")
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'; frame-ancestors 'none'")
+	c.Header("Referrer-Policy", "no-referrer")
+}
+
+func (h *CheckoutHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
+
+func (h *CheckoutHandler) parseID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid checkout session ID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchant.ID, id, true
+}
+
+// formInt 解析表單整數，格式錯誤時回傳 0 交由卡片驗證處理
+func formInt(c *gin.Context, key string) int {
+	n, _ := strconv.Atoi(strings.TrimSpace(c.PostForm(key)))
+	return n
+}
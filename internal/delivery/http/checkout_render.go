@@ -0,0 +1,158 @@
+package http
+
+import (
+	"bytes"
+	stderrors "errors"
+	"html/template"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+var paymentMethodLabels = map[entity.PaymentMethod]string{
+	entity.PaymentMethodCreditCard:    "Card",
+	entity.PaymentMethodBankTransfer:  "Bank transfer",
+	entity.PaymentMethodDigitalWallet: "Digital wallet",
+}
+
+var checkoutTemplate = template.Must(template.New("checkout").Funcs(template.FuncMap{
+	"money": formatMoney,
+	"methodLabel": func(m entity.PaymentMethod) string {
+		if label, ok := paymentMethodLabels[m]; ok {
+			return label
+		}
+		return string(m)
+	},
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Pay {{.Merchant.Name}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; background: #f5f6f8; margin: 0; }
+main { max-width: 420px; margin: 40px auto; background: #fff; padding: 32px; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.1); }
+h1 { font-size: 18px; margin: 0 0 4px; }
+.amount { font-size: 32px; font-weight: bold; margin: 12px 0; }
+.error { background: #fdecea; color: #a61b1b; padding: 10px; border-radius: 4px; margin-bottom: 16px; }
+label { display: block; margin: 12px 0 4px; font-size: 14px; }
+input[type=text], input[type=email] { width: 100%; box-sizing: border-box; padding: 8px; font-size: 16px; }
+fieldset { border: 1px solid #ddd; border-radius: 4px; margin: 16px 0; }
+.row { display: flex; gap: 8px; }
+button { width: 100%; padding: 12px; font-size: 16px; margin-top: 16px; cursor: pointer; }
+.link { background: none; border: none; color: #555; text-decoration: underline; }
+dt { font-size: 14px; color: #555; margin-top: 8px; }
+dd { margin: 0; font-size: 18px; font-family: monospace; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Merchant.Name}}</h1>
+{{with .Session}}{{if .Description}}<div>{{.Description}}</div>{{end}}
+<div class="amount">{{money .Amount .Currency}}</div>
+{{if eq .Status "open"}}
+{{if $.Error}}<div class="error">{{$.Error}}</div>{{end}}
+<form method="post" action="/checkout/{{.ID}}" autocomplete="on">
+{{if not .CustomerID}}{{if .CustomerEmail}}<div>{{.CustomerEmail}}</div>{{else}}
+<label for="email">Email</label>
+<input type="email" id="email" name="email" value="{{$.Email}}" required>
+<label for="name">Name</label>
+<input type="text" id="name" name="name" value="{{$.Name}}">
+{{end}}{{end}}
+<fieldset>
+<legend>Payment method</legend>
+{{range $i, $m := .AllowedMethods}}<label><input type="radio" name="method" value="{{$m}}"{{if or (eq $m $.Method) (and (not $.Method) (eq $i 0))}} checked{{end}}> {{methodLabel $m}}</label>
+{{end}}</fieldset>
+{{if .Allows "credit_card"}}
+<fieldset>
+<legend>Card details</legend>
+<label for="number">Card number</label>
+<input type="text" id="number" name="number" inputmode="numeric" autocomplete="cc-number">
+<div class="row">
+<div><label for="exp_month">MM</label><input type="text" id="exp_month" name="exp_month" inputmode="numeric" autocomplete="cc-exp-month" size="2"></div>
+<div><label for="exp_year">YYYY</label><input type="text" id="exp_year" name="exp_year" inputmode="numeric" autocomplete="cc-exp-year" size="4"></div>
+<div><label for="cvv">CVV</label><input type="text" id="cvv" name="cvv" inputmode="numeric" autocomplete="cc-csc" size="4"></div>
+</div>
+</fieldset>
+{{end}}
+<button type="submit">Pay {{money .Amount .Currency}}</button>
+</form>
+<form method="post" action="/checkout/{{.ID}}/cancel">
+<button type="submit" class="link">Cancel and return to {{$.Merchant.Name}}</button>
+</form>
+{{else if eq .Status "processing"}}
+<p>This payment is being processed. Refresh this page in a moment.</p>
+{{else if eq .Status "complete"}}
+{{with $.PendingTransfer}}
+<p>Transfer {{money .AmountDue .Currency}} to the account below. Include the reference so we can match your payment.</p>
+<dl>
+{{if .BankName}}<dt>Bank</dt><dd>{{.BankName}}</dd>
+{{end}}<dt>Account number</dt><dd>{{.VirtualAccount}}</dd>
+<dt>Reference</dt><dd>{{.Reference}}</dd>
+</dl>
+<p>The payment is cancelled if the full amount has not arrived by {{.ExpiresAt.UTC.Format "2006-01-02 15:04 MST"}}.</p>
+{{if $.ContinueURL}}<p><a href="{{$.ContinueURL}}">Return to {{$.Merchant.Name}}</a></p>{{end}}
+{{else}}
+<p>This checkout has already been paid.</p>
+{{end}}
+{{else}}
+<p>This checkout is {{.Status}}.</p>
+<form method="post" action="/checkout/{{.ID}}/cancel">
+<button type="submit" class="link">Return to {{$.Merchant.Name}}</button>
+</form>
+{{end}}{{end}}
+</main>
+</body>
+</html>
+`))
+
+// checkoutPage 為付款頁的顯示資料；卡號與 CVV 永不回填
+type checkoutPage struct {
+	*usecase.HostedCheckout
+	Error  string
+	Email  string
+	Name   string
+	Method entity.PaymentMethod
+	// ContinueURL 為顯示匯款資訊後返回商戶的網址
+	ContinueURL string
+}
+
+// PendingTransfer 回傳仍在等待款項的銀行轉帳匯款資訊
+func (p checkoutPage) PendingTransfer() *entity.BankTransfer {
+	if p.Payment == nil || p.Payment.Status != entity.PaymentStatusPending || p.Payment.BankTransfer == nil {
+		return nil
+	}
+	if !p.Payment.BankTransfer.Open() {
+		return nil
+	}
+	return p.Payment.BankTransfer
+}
+
+func renderCheckoutPage(page checkoutPage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := checkoutTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// publicErrorMessage 取出可以顯示給付款人的錯誤訊息，不包含內部錯誤與程式位置
+func publicErrorMessage(err error) string {
+	for e := err; e != nil; e = stderrors.Unwrap(e) {
+		appErr, ok := e.(*errors.AppError)
+		if !ok || appErr.Code == "" {
+			continue
+		}
+		switch appErr.Code {
+		case "not_found":
+			return "This checkout could not be found."
+		case "payment_declined":
+			return "The payment was declined."
+		case "limit_exceeded":
+			return "This payment exceeds the merchant's limits."
+		}
+		return appErr.Message
+	}
+	return "The payment could not be completed, please try again."
+}
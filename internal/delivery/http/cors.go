@@ -0,0 +1,126 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/gin-gonic/gin"
+)
+
+// browserPathPrefix 下的路由以 publishable key 從瀏覽器呼叫，允許的來源由商戶設定，
+// 不使用 CORSConfig 的來源清單，也不允許帶 cookie
+const browserPathPrefix = "/api/v1/browser/"
+
+const (
+	browserAllowedMethods = "GET, POST"
+	browserAllowedHeaders = "Content-Type, X-Publishable-Key, X-Request-ID"
+	browserExposedHeaders = "X-Request-ID, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, Retry-After"
+)
+
+// CORSConfig 設定跨來源請求。AllowedOrigins 可用 https://*.example.com 允許子網域，
+// 單獨的 * 允許所有來源，但不能與 AllowCredentials 同時使用
+type CORSConfig struct {
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// CORSPolicy 為檢查過的 CORSConfig，來源清單為空時只允許同源請求
+type CORSPolicy struct {
+	origins     entity.AllowedOrigins
+	methods     string
+	headers     string
+	exposed     string
+	maxAge      string
+	credentials bool
+}
+
+func NewCORSPolicy(cfg CORSConfig) (*CORSPolicy, error) {
+	origins := entity.AllowedOrigins(cfg.AllowedOrigins)
+	if err := origins.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid cors allowed origins")
+	}
+	for _, origin := range origins {
+		if origin == "*" && cfg.AllowCredentials {
+			return nil, errors.New("cors allowed origin * cannot be used with allow_credentials")
+		}
+	}
+	if cfg.MaxAge < 0 {
+		return nil, errors.New("cors max_age must not be negative")
+	}
+
+	methods := make([]string, 0, len(cfg.AllowedMethods))
+	for _, method := range cfg.AllowedMethods {
+		methods = append(methods, strings.ToUpper(method))
+	}
+	return &CORSPolicy{
+		origins:     origins,
+		methods:     strings.Join(methods, ", "),
+		headers:     strings.Join(cfg.AllowedHeaders, ", "),
+		exposed:     strings.Join(cfg.ExposedHeaders, ", "),
+		maxAge:      strconv.Itoa(int(cfg.MaxAge.Seconds())),
+		credentials: cfg.AllowCredentials,
+	}, nil
+}
+
+// CORSMiddleware 依 policy 回應預檢請求並為允許的來源加上 CORS 標頭。
+// 回應會隨 Origin 不同，一律加上 Vary: Origin 避免快取把某個來源的回應給其他來源
+func CORSMiddleware(policy *CORSPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		origin := c.GetHeader("Origin")
+		browser := strings.HasPrefix(c.Request.URL.Path, browserPathPrefix)
+
+		if c.Request.Method == http.MethodOptions && origin != "" && c.GetHeader("Access-Control-Request-Method") != "" {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+			switch {
+			case browser:
+				// 預檢請求不帶 publishable key，無法得知商戶；實際請求由 PublishableKeyAuth 檢查來源
+				header.Set("Access-Control-Allow-Origin", origin)
+				header.Set("Access-Control-Allow-Methods", browserAllowedMethods)
+				header.Set("Access-Control-Allow-Headers", browserAllowedHeaders)
+			case policy.origins.Allows(origin):
+				policy.allowOrigin(c, origin)
+				header.Set("Access-Control-Allow-Methods", policy.methods)
+				header.Set("Access-Control-Allow-Headers", policy.headers)
+			default:
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			header.Set("Access-Control-Max-Age", policy.maxAge)
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		if !browser && policy.origins.Allows(origin) {
+			policy.allowOrigin(c, origin)
+			if policy.exposed != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposed)
+			}
+		}
+		c.Next()
+	}
+}
+
+// allowOrigin 回傳請求的來源而非 *，讓帶 cookie 的請求也能通過
+func (p *CORSPolicy) allowOrigin(c *gin.Context, origin string) {
+	c.Header("Access-Control-Allow-Origin", origin)
+	if p.credentials {
+		c.Header("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// allowBrowserOrigin 為通過商戶來源檢查的 publishable key 請求加上 CORS 標頭
+func allowBrowserOrigin(c *gin.Context, origin string) {
+	c.Header("Access-Control-Allow-Origin", origin)
+	c.Header("Access-Control-Expose-Headers", browserExposedHeaders)
+}
@@ -0,0 +1,322 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxEvidenceUpload 限制證據上傳請求的大小，單一附件的上限由 use case 檢查
+const maxEvidenceUpload = 32 << 20
+
+type DisputeHandler struct {
+	disputeUseCase usecase.DisputeUseCase
+}
+
+func NewDisputeHandler(disputeUseCase usecase.DisputeUseCase) *DisputeHandler {
+	return &DisputeHandler{
+		disputeUseCase: disputeUseCase,
+	}
+}
+
+// OpenDispute 由平台依網關或發卡行的通知建立爭議
+func (h *DisputeHandler) OpenDispute(c *gin.Context) {
+	var req usecase.OpenDisputeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	dispute, err := h.disputeUseCase.OpenDispute(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    dispute,
+		Message: "Dispute opened successfully",
+	})
+}
+
+// ResolveDispute 記錄裁決結果，body 為 {"outcome": "won"} 或 {"outcome": "lost"}
+func (h *DisputeHandler) ResolveDispute(c *gin.Context) {
+	id, ok := h.parseID(c, "id", "dispute")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Outcome entity.DisputeStatus `json:"outcome" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	dispute, err := h.disputeUseCase.ResolveDispute(c.Request.Context(), id, req.Outcome)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    dispute,
+		Message: "Dispute resolved successfully",
+	})
+}
+
+func (h *DisputeHandler) ListDisputes(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	status := entity.DisputeStatus(c.Query("status"))
+	switch status {
+	case "", entity.DisputeStatusNeedsResponse, entity.DisputeStatusUnderReview,
+		entity.DisputeStatusWon, entity.DisputeStatusLost:
+	default:
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid status",
+		})
+		return
+	}
+
+	limit, offset := h.pagination(c)
+	disputes, err := h.disputeUseCase.ListDisputes(c.Request.Context(), merchantID, status, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    disputes,
+	})
+}
+
+func (h *DisputeHandler) GetDispute(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c, "id", "dispute")
+	if !ok {
+		return
+	}
+
+	dispute, err := h.disputeUseCase.GetDispute(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    dispute,
+	})
+}
+
+// AddEvidence 接收 multipart 表單：description 為說明，file 為選填的附件
+func (h *DisputeHandler) AddEvidence(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c, "id", "dispute")
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxEvidenceUpload)
+	req := usecase.AddEvidenceRequest{
+		MerchantID:  merchantID,
+		DisputeID:   id,
+		Description: c.PostForm("description"),
+	}
+
+	fileHeader, err := c.FormFile("file")
+	switch {
+	case err == nil:
+		file, err := fileHeader.Open()
+		if err != nil {
+			h.error(c, err)
+			return
+		}
+		defer file.Close()
+		req.FileName = fileHeader.Filename
+		req.File = file
+	case err == http.ErrMissingFile:
+	default:
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid evidence upload: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	if c.Request.MultipartForm != nil {
+		// 刪除解析表單時寫到暫存目錄的檔案
+		defer c.Request.MultipartForm.RemoveAll()
+	}
+
+	dispute, err := h.disputeUseCase.AddEvidence(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    dispute,
+		Message: "Evidence added successfully",
+	})
+}
+
+// DownloadEvidence 以附件形式回傳證據檔案，類型為上傳時偵測的結果
+func (h *DisputeHandler) DownloadEvidence(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c, "id", "dispute")
+	if !ok {
+		return
+	}
+	evidenceID, ok := h.parseID(c, "evidenceId", "evidence")
+	if !ok {
+		return
+	}
+
+	evidence, file, err := h.disputeUseCase.OpenEvidenceFile(c.Request.Context(), merchantID, id, evidenceID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+	defer file.Close()
+
+	c.DataFromReader(http.StatusOK, evidence.Size, evidence.ContentType, file, map[string]string{
+		"Content-Disposition":    `attachment; filename="` + evidence.FileName + `"`,
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// SubmitEvidence 將證據送交審查，之後不能再新增證據
+func (h *DisputeHandler) SubmitEvidence(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+	id, ok := h.parseID(c, "id", "dispute")
+	if !ok {
+		return
+	}
+
+	dispute, err := h.disputeUseCase.SubmitEvidence(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    dispute,
+		Message: "Evidence submitted successfully",
+	})
+}
+
+// ListEvents 回傳商戶的 dispute.* 事件，新的在前
+func (h *DisputeHandler) ListEvents(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := h.pagination(c)
+	events, err := h.disputeUseCase.ListEvents(c.Request.Context(), merchantID, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    events,
+	})
+}
+
+func (h *DisputeHandler) ListLedgerEntries(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	limit, offset := h.pagination(c)
+	entries, err := h.disputeUseCase.ListLedgerEntries(c.Request.Context(), merchantID, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+func (h *DisputeHandler) pagination(c *gin.Context) (int, int) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+func (h *DisputeHandler) merchantID(c *gin.Context) (uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, false
+	}
+	return merchant.ID, true
+}
+
+func (h *DisputeHandler) parseID(c *gin.Context, param, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid " + name + " ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *DisputeHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
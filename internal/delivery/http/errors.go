@@ -0,0 +1,45 @@
+package http
+
+import (
+	stderrors "errors"
+	"net/http"
+
+	"github.com/company/payment-service/pkg/errors"
+)
+
+// errorStatuses 將 use case 回傳的錯誤代碼對應到 HTTP 狀態碼
+var errorStatuses = map[string]int{
+	"invalid_card":            http.StatusBadRequest,
+	"invalid_payment_method":  http.StatusBadRequest,
+	"invalid_invoice":         http.StatusBadRequest,
+	"invalid_checkout":        http.StatusBadRequest,
+	"invalid_payment_link":    http.StatusBadRequest,
+	"invalid_subscription":    http.StatusBadRequest,
+	"invalid_bank_credit":     http.StatusBadRequest,
+	"invalid_reconciliation":  http.StatusBadRequest,
+	"invalid_wallet_callback": http.StatusBadRequest,
+	"invalid_webhook":         http.StatusBadRequest,
+	"invalid_dispute":         http.StatusBadRequest,
+	"invalid_limit":           http.StatusBadRequest,
+	"invalid_origin":          http.StatusBadRequest,
+	"invalid_signature":       http.StatusUnauthorized,
+	"payment_declined":        http.StatusPaymentRequired,
+	"limit_exceeded":          http.StatusUnprocessableEntity,
+	"invalid_payment_status":  http.StatusConflict,
+	"invalid_dispute_status":  http.StatusConflict,
+	"not_found":               http.StatusNotFound,
+}
+
+// errorStatus 回傳錯誤代碼對應的狀態碼，沒有代碼時使用 fallback
+func errorStatus(err error, fallback int) int {
+	if status, ok := errorStatuses[errors.Code(err)]; ok {
+		return status
+	}
+	return fallback
+}
+
+// bodyTooLarge 回傳錯誤是否因請求超過 http.MaxBytesReader 的上限
+func bodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return stderrors.As(err, &maxBytesErr)
+}
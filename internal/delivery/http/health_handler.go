@@ -0,0 +1,109 @@
+package http
+
+import (
+	"database/sql"
+	"net/http"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/company/payment-service/pkg/health"
+	"github.com/gin-gonic/gin"
+)
+
+type VersionInfo struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Environment string `json:"environment"`
+}
+
+type HealthConfig struct {
+	Version VersionInfo
+	// Checkers 為 readiness 檢查，例如資料庫 ping、遷移版本、佇列積壓
+	Checkers []health.Checker
+	// CheckTimeout 為單一檢查的逾時時間
+	CheckTimeout time.Duration
+	// DBStats 回傳各連線池的統計，記憶體儲存時為 nil
+	DBStats func() map[string]sql.DBStats
+}
+
+type HealthHandler struct {
+	cfg          HealthConfig
+	shuttingDown atomic.Bool
+}
+
+func NewHealthHandler(cfg HealthConfig) *HealthHandler {
+	if cfg.CheckTimeout <= 0 {
+		cfg.CheckTimeout = 2 * time.Second
+	}
+	return &HealthHandler{cfg: cfg}
+}
+
+// SetShuttingDown 在優雅關閉開始時呼叫，讓 readiness 回報失敗以便負載平衡器摘除流量
+func (h *HealthHandler) SetShuttingDown() {
+	h.shuttingDown.Store(true)
+}
+
+// Livez 只確認程序仍能處理請求，不檢查外部依賴
+func (h *HealthHandler) Livez(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	if h.shuttingDown.Load() {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "shutting_down"})
+		return
+	}
+
+	report := health.Run(c.Request.Context(), h.cfg.CheckTimeout, h.cfg.Checkers...)
+	status := http.StatusOK
+	if !report.OK() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, report)
+}
+
+// Health 保留舊的 /health 端點，內容為 readiness 結果加上連線池統計
+func (h *HealthHandler) Health(c *gin.Context) {
+	report := health.Run(c.Request.Context(), h.cfg.CheckTimeout, h.cfg.Checkers...)
+	response := gin.H{
+		"status":  report.Status,
+		"service": h.cfg.Version.Name,
+		"checks":  report.Checks,
+	}
+	if h.cfg.DBStats != nil {
+		response["database"] = poolStats(h.cfg.DBStats())
+	}
+
+	status := http.StatusOK
+	if !report.OK() || h.shuttingDown.Load() {
+		status = http.StatusServiceUnavailable
+	}
+	c.JSON(status, response)
+}
+
+func (h *HealthHandler) Version(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"name":        h.cfg.Version.Name,
+		"version":     h.cfg.Version.Version,
+		"environment": h.cfg.Version.Environment,
+		"go_version":  runtime.Version(),
+	})
+}
+
+func poolStats(stats map[string]sql.DBStats) gin.H {
+	result := gin.H{}
+	for name, s := range stats {
+		result[name] = gin.H{
+			"max_open_connections": s.MaxOpenConnections,
+			"open_connections":     s.OpenConnections,
+			"in_use":               s.InUse,
+			"idle":                 s.Idle,
+			"wait_count":           s.WaitCount,
+			"wait_duration_ms":     s.WaitDuration.Milliseconds(),
+			"max_idle_closed":      s.MaxIdleClosed,
+			"max_lifetime_closed":  s.MaxLifetimeClosed,
+		}
+	}
+	return result
+}
@@ -0,0 +1,235 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InvoiceHandler struct {
+	invoiceUseCase usecase.InvoiceUseCase
+}
+
+func NewInvoiceHandler(invoiceUseCase usecase.InvoiceUseCase) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceUseCase: invoiceUseCase,
+	}
+}
+
+func (h *InvoiceHandler) CreateInvoice(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.CreateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+
+	invoice, err := h.invoiceUseCase.CreateInvoice(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    invoice,
+		Message: "Invoice created successfully",
+	})
+}
+
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	invoices, err := h.invoiceUseCase.ListInvoices(c.Request.Context(), merchantID, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    invoices,
+	})
+}
+
+// GetInvoice 依路徑副檔名回傳 JSON、PDF（/invoices/:id.pdf）或 HTML（/invoices/:id.html）
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	param := c.Param("id")
+	format := ""
+	if i := strings.LastIndex(param, "."); i >= 0 {
+		param, format = param[:i], param[i+1:]
+	}
+	merchantID, id, ok := h.parseID(c, param)
+	if !ok {
+		return
+	}
+
+	switch format {
+	case "":
+		invoice, err := h.invoiceUseCase.GetInvoice(c.Request.Context(), merchantID, id)
+		if err != nil {
+			h.error(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, CreatePaymentResponse{
+			Success: true,
+			Data:    invoice,
+		})
+	case "pdf", "html":
+		doc, err := h.invoiceUseCase.GetInvoiceDocument(c.Request.Context(), merchantID, id)
+		if err != nil {
+			h.error(c, err)
+			return
+		}
+		if format == "html" {
+			body, err := renderInvoiceHTML(doc)
+			if err != nil {
+				h.error(c, err)
+				return
+			}
+			c.Data(http.StatusOK, "text/html; charset=utf-8", body)
+			return
+		}
+		filename := doc.Invoice.Number
+		if filename == "" {
+			filename = doc.Invoice.ID.String()
+		}
+		c.Header("Content-Disposition", `inline; filename="`+filename+`.pdf"`)
+		c.Data(http.StatusOK, "application/pdf", renderInvoicePDF(doc))
+	default:
+		c.JSON(http.StatusNotFound, CreatePaymentResponse{
+			Success: false,
+			Error:   "Unsupported invoice format: " + format,
+		})
+	}
+}
+
+// UpdateInvoice 修改草稿的備註、到期日或明細
+func (h *InvoiceHandler) UpdateInvoice(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	var req usecase.UpdateInvoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+	req.ID = id
+
+	invoice, err := h.invoiceUseCase.UpdateInvoice(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    invoice,
+		Message: "Invoice updated successfully",
+	})
+}
+
+func (h *InvoiceHandler) FinalizeInvoice(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceUseCase.FinalizeInvoice(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    invoice,
+		Message: "Invoice finalized successfully",
+	})
+}
+
+func (h *InvoiceHandler) VoidInvoice(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, c.Param("id"))
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceUseCase.VoidInvoice(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    invoice,
+		Message: "Invoice voided successfully",
+	})
+}
+
+func (h *InvoiceHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
+
+// merchantID 取得目前商戶 ID，失敗時已寫入回應
+func (h *InvoiceHandler) merchantID(c *gin.Context) (uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, false
+	}
+	return merchant.ID, true
+}
+
+func (h *InvoiceHandler) parseID(c *gin.Context, param string) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(param)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid invoice ID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, id, true
+}
@@ -0,0 +1,227 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/pdf"
+)
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"money": formatMoney,
+	"rate":  formatRate,
+	"date":  formatDate,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; margin: 40px; }
+h1 { margin: 0 0 4px; }
+table { width: 100%; border-collapse: collapse; margin-top: 24px; }
+th, td { padding: 6px 4px; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.totals td { border: none; }
+.status { text-transform: uppercase; font-weight: bold; }
+</style>
+</head>
+<body>
+{{with .Doc}}
+<h1>Invoice {{.Invoice.Number}}</h1>
+<div class="status">{{.Invoice.Status}}</div>
+<p>
+<strong>{{.Merchant.Name}}</strong><br>{{.Merchant.Email}}
+</p>
+<p>
+Bill to:<br><strong>{{.Customer.Name}}</strong><br>{{.Customer.Email}}
+</p>
+<p>
+Issued: {{date $.Issued}}{{if .Invoice.DueDate}}<br>Due: {{date .Invoice.DueDate}}{{end}}{{if .Invoice.PaidAt}}<br>Paid: {{date .Invoice.PaidAt}}{{end}}
+</p>
+<table>
+<thead><tr><th>Description</th><th>Qty</th><th>Unit price</th><th>Discount</th><th>Tax</th><th>Amount</th></tr></thead>
+<tbody>
+{{range .Invoice.LineItems}}<tr><td>{{.Description}}</td><td>{{.Quantity}}</td><td>{{money .UnitAmount $.Currency}}</td><td>{{if .Discount}}-{{money .Discount $.Currency}}{{end}}</td><td>{{if .TaxRate}}{{.TaxName}} {{rate .TaxRate}}{{end}}</td><td>{{money .Amount $.Currency}}</td></tr>
+{{end}}</tbody>
+<tbody class="totals">
+<tr><td colspan="5">Subtotal</td><td>{{money .Invoice.Subtotal $.Currency}}</td></tr>
+{{if .Invoice.DiscountTotal}}<tr><td colspan="5">Discount</td><td>-{{money .Invoice.DiscountTotal $.Currency}}</td></tr>{{end}}
+{{range .Invoice.TaxLines}}<tr><td colspan="5">{{.Name}} {{rate .Rate}} on {{money .Taxable $.Currency}}</td><td>{{money .Amount $.Currency}}</td></tr>
+{{end}}<tr><td colspan="5"><strong>Total</strong></td><td><strong>{{money .Invoice.Total $.Currency}}</strong></td></tr>
+</tbody>
+</table>
+{{if .Invoice.Memo}}<p>{{.Invoice.Memo}}</p>{{end}}
+{{end}}
+</body>
+</html>
+`))
+
+// renderInvoiceHTML 輸出可直接在瀏覽器列印的帳單頁面
+func renderInvoiceHTML(doc *usecase.InvoiceDocument) ([]byte, error) {
+	var buf bytes.Buffer
+	err := invoiceTemplate.Execute(&buf, map[string]interface{}{
+		"Title":    invoiceTitle(doc.Invoice),
+		"Doc":      doc,
+		"Currency": doc.Invoice.Currency,
+		"Issued":   invoiceIssued(doc.Invoice),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// PDF 版面欄位的右緣 x 座標
+const (
+	pdfMargin      = 50.0
+	pdfColQty      = 330.0
+	pdfColUnit     = 400.0
+	pdfColDiscount = 460.0
+	pdfColAmount   = pdf.PageWidth - pdfMargin
+	pdfRowHeight   = 16.0
+	pdfBottom      = pdf.PageHeight - 80
+)
+
+func renderInvoicePDF(doc *usecase.InvoiceDocument) []byte {
+	inv := doc.Invoice
+	d := pdf.New()
+
+	d.Text(pdfMargin, 70, 20, pdf.HelveticaBold, invoiceTitle(inv))
+	d.TextRight(pdfColAmount, 70, 12, pdf.HelveticaBold, strings.ToUpper(string(inv.Status)))
+	d.Text(pdfMargin, 100, 11, pdf.HelveticaBold, doc.Merchant.Name)
+	d.Text(pdfMargin, 114, 10, pdf.Helvetica, doc.Merchant.Email)
+
+	d.Text(pdfMargin, 146, 9, pdf.Helvetica, "Bill to")
+	d.Text(pdfMargin, 160, 11, pdf.HelveticaBold, doc.Customer.Name)
+	d.Text(pdfMargin, 174, 10, pdf.Helvetica, doc.Customer.Email)
+
+	y := 146.0
+	dates := [][2]string{{"Issued", formatDate(invoiceIssued(inv))}}
+	if inv.DueDate != nil {
+		dates = append(dates, [2]string{"Due", formatDate(inv.DueDate)})
+	}
+	if inv.PaidAt != nil {
+		dates = append(dates, [2]string{"Paid", formatDate(inv.PaidAt)})
+	}
+	for _, row := range dates {
+		d.Text(pdfColDiscount-40, y, 10, pdf.Helvetica, row[0])
+		d.TextRight(pdfColAmount, y, 10, pdf.Helvetica, row[1])
+		y += 14
+	}
+
+	header := func(y float64) float64 {
+		d.Text(pdfMargin, y, 9, pdf.HelveticaBold, "Description")
+		d.TextRight(pdfColQty, y, 9, pdf.HelveticaBold, "Qty")
+		d.TextRight(pdfColUnit, y, 9, pdf.HelveticaBold, "Unit price")
+		d.TextRight(pdfColDiscount, y, 9, pdf.HelveticaBold, "Discount")
+		d.TextRight(pdfColAmount, y, 9, pdf.HelveticaBold, "Amount")
+		d.Line(pdfMargin, y+5, pdfColAmount, y+5)
+		return y + pdfRowHeight + 2
+	}
+
+	y = header(220)
+	for _, item := range inv.LineItems {
+		if y > pdfBottom {
+			d.AddPage()
+			y = header(70)
+		}
+		description := item.Description
+		if item.TaxRate > 0 {
+			description += fmt.Sprintf(" (%s %s)", item.TaxName, formatRate(item.TaxRate))
+		}
+		d.Text(pdfMargin, y, 10, pdf.Helvetica, fitText(description, 10, pdfColQty-pdfMargin-40))
+		d.TextRight(pdfColQty, y, 10, pdf.Helvetica, strconv.Itoa(item.Quantity))
+		d.TextRight(pdfColUnit, y, 10, pdf.Helvetica, formatMoney(item.UnitAmount, inv.Currency))
+		if item.Discount > 0 {
+			d.TextRight(pdfColDiscount, y, 10, pdf.Helvetica, "-"+formatMoney(item.Discount, inv.Currency))
+		}
+		d.TextRight(pdfColAmount, y, 10, pdf.Helvetica, formatMoney(item.Amount, inv.Currency))
+		y += pdfRowHeight
+	}
+
+	totals := [][2]string{{"Subtotal", formatMoney(inv.Subtotal, inv.Currency)}}
+	if inv.DiscountTotal > 0 {
+		totals = append(totals, [2]string{"Discount", "-" + formatMoney(inv.DiscountTotal, inv.Currency)})
+	}
+	for _, tax := range inv.TaxLines {
+		totals = append(totals, [2]string{fmt.Sprintf("%s %s", tax.Name, formatRate(tax.Rate)), formatMoney(tax.Amount, inv.Currency)})
+	}
+	if y+float64(len(totals)+2)*pdfRowHeight > pdfBottom {
+		d.AddPage()
+		y = 70
+	}
+	d.Line(pdfColUnit-60, y-6, pdfColAmount, y-6)
+	y += 4
+	for _, row := range totals {
+		d.Text(pdfColUnit-60, y, 10, pdf.Helvetica, row[0])
+		d.TextRight(pdfColAmount, y, 10, pdf.Helvetica, row[1])
+		y += pdfRowHeight
+	}
+	d.Text(pdfColUnit-60, y+2, 12, pdf.HelveticaBold, "Total")
+	d.TextRight(pdfColAmount, y+2, 12, pdf.HelveticaBold, formatMoney(inv.Total, inv.Currency))
+
+	if inv.Memo != "" && y+40 < pdfBottom {
+		d.Text(pdfMargin, y+40, 10, pdf.Helvetica, fitText(inv.Memo, 10, pdfColAmount-pdfMargin))
+	}
+	return d.Bytes()
+}
+
+func invoiceTitle(inv *entity.Invoice) string {
+	if inv.Number == "" {
+		return "Draft invoice"
+	}
+	return "Invoice " + inv.Number
+}
+
+// invoiceIssued 以定稿時間為開立日期，草稿則使用建立時間
+func invoiceIssued(inv *entity.Invoice) *time.Time {
+	if inv.FinalizedAt != nil {
+		return inv.FinalizedAt
+	}
+	return &inv.CreatedAt
+}
+
+// formatMoney 將以分為單位的金額格式化，例如 123456 → "1,234.56 USD"
+func formatMoney(amount int64, currency string) string {
+	sign := ""
+	if amount < 0 {
+		sign = "-"
+		amount = -amount
+	}
+	units := strconv.FormatInt(amount/100, 10)
+	for i := len(units) - 3; i > 0; i -= 3 {
+		units = units[:i] + "," + units[i:]
+	}
+	return fmt.Sprintf("%s%s.%02d %s", sign, units, amount%100, currency)
+}
+
+// formatRate 將萬分比格式化為百分比，例如 725 → "7.25%"
+func formatRate(rate int) string {
+	return strconv.FormatFloat(float64(rate)/100, 'f', -1, 64) + "%"
+}
+
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format("2006-01-02")
+}
+
+// fitText 截斷超過寬度的文字
+func fitText(s string, size, width float64) string {
+	if pdf.TextWidth(s, size) <= width {
+		return s
+	}
+	runes := []rune(s)
+	for len(runes) > 0 && pdf.TextWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+	return string(runes) + "..."
+}
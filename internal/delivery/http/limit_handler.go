@@ -0,0 +1,105 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LimitHandler struct {
+	limitUseCase usecase.LimitUseCase
+}
+
+func NewLimitHandler(limitUseCase usecase.LimitUseCase) *LimitHandler {
+	return &LimitHandler{
+		limitUseCase: limitUseCase,
+	}
+}
+
+// SetLimits 以 body 的 {"limits": [...]} 取代商戶的限額
+func (h *LimitHandler) SetLimits(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Limits []usecase.LimitRequest `json:"limits"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	limits, err := h.limitUseCase.SetLimits(c.Request.Context(), merchantID, req.Limits)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    limits,
+		Message: "Limits updated successfully",
+	})
+}
+
+// GetMerchantUsage 供平台查詢指定商戶的限額與當日用量
+func (h *LimitHandler) GetMerchantUsage(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+	h.usage(c, merchantID)
+}
+
+// GetUsage 回傳目前商戶的限額與當日用量
+func (h *LimitHandler) GetUsage(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+	h.usage(c, merchant.ID)
+}
+
+func (h *LimitHandler) usage(c *gin.Context, merchantID uuid.UUID) {
+	usage, err := h.limitUseCase.GetUsage(c.Request.Context(), merchantID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    usage,
+	})
+}
+
+func (h *LimitHandler) parseMerchantID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *LimitHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
@@ -0,0 +1,134 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type MerchantHandler struct {
+	merchantUseCase usecase.MerchantUseCase
+}
+
+func NewMerchantHandler(merchantUseCase usecase.MerchantUseCase) *MerchantHandler {
+	return &MerchantHandler{
+		merchantUseCase: merchantUseCase,
+	}
+}
+
+func (h *MerchantHandler) GetMerchant(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	merchant, err := h.merchantUseCase.GetMerchant(c.Request.Context(), merchantID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    merchant,
+	})
+}
+
+// SetAllowedOrigins 以 body 的 {"allowed_origins": [...]} 取代商戶允許的瀏覽器來源
+func (h *MerchantHandler) SetAllowedOrigins(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		AllowedOrigins []string `json:"allowed_origins"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	merchant, err := h.merchantUseCase.SetAllowedOrigins(c.Request.Context(), merchantID, req.AllowedOrigins)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    merchant,
+		Message: "Allowed origins updated successfully",
+	})
+}
+
+// RotatePublishableKey 發行新的 publishable key，舊的 key 立即失效
+func (h *MerchantHandler) RotatePublishableKey(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	merchant, err := h.merchantUseCase.RotatePublishableKey(c.Request.Context(), merchantID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    merchant,
+		Message: "Publishable key rotated successfully",
+	})
+}
+
+// SigningSecretResponse 為新發行的請求簽章金鑰，金鑰只在發行時回傳一次
+type SigningSecretResponse struct {
+	KeyID         uuid.UUID `json:"key_id"`
+	SigningSecret string    `json:"signing_secret"`
+}
+
+// RotateSigningSecret 發行新的請求簽章金鑰，舊的金鑰立即失效
+func (h *MerchantHandler) RotateSigningSecret(c *gin.Context) {
+	merchantID, ok := h.parseMerchantID(c)
+	if !ok {
+		return
+	}
+
+	merchant, err := h.merchantUseCase.RotateSigningSecret(c.Request.Context(), merchantID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    SigningSecretResponse{KeyID: merchant.ID, SigningSecret: merchant.SigningSecret},
+		Message: "Signing secret rotated successfully",
+	})
+}
+
+func (h *MerchantHandler) parseMerchantID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid merchant ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *MerchantHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
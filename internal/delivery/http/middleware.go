@@ -1,26 +1,56 @@
 package http
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/company/payment-service/pkg/signing"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
 )
 
 type AuthMiddleware struct {
 	merchantRepo repository.MerchantRepository
+	rateLimiter  usecase.RateLimiter
+	verifier     usecase.RequestVerifier
 }
 
-func NewAuthMiddleware(merchantRepo repository.MerchantRepository) *AuthMiddleware {
+// NewAuthMiddleware 的 rateLimiter 為 nil 時不限制請求頻率，verifier 為 nil 時不接受 HMAC 簽章
+func NewAuthMiddleware(merchantRepo repository.MerchantRepository, rateLimiter usecase.RateLimiter, verifier usecase.RequestVerifier) *AuthMiddleware {
 	return &AuthMiddleware{
 		merchantRepo: merchantRepo,
+		rateLimiter:  rateLimiter,
+		verifier:     verifier,
 	}
 }
 
+// APIKeyAuth 以 X-API-Key 或 Authorization: Bearer 驗證商戶；
+// Authorization 使用 HMAC-SHA256 時改為驗證請求簽章
 func (m *AuthMiddleware) APIKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if signing.IsSigned(c.GetHeader("Authorization")) {
+			m.signatureAuth(c)
+			return
+		}
+
 		apiKey := c.GetHeader("X-API-Key")
 		if apiKey == "" {
 			// 嘗試從 Authorization header 獲取
@@ -49,33 +79,201 @@ func (m *AuthMiddleware) APIKeyAuth() gin.HandlerFunc {
 			return
 		}
 
-		if !merchant.IsActive {
-			c.JSON(http.StatusForbidden, gin.H{
+		if m.admit(c, merchant) {
+			c.Next()
+		}
+	}
+}
+
+// maxSignedBody 限制簽章驗證前讀入記憶體的請求大小，與證據上傳的上限相同
+const maxSignedBody = maxEvidenceUpload
+
+// signatureErrors 為簽章驗證失敗時回傳給客戶端的訊息
+var signatureErrors = map[string]string{
+	"invalid_signature": "Invalid request signature",
+	"stale_request":     "Request timestamp is outside the allowed window",
+	"replayed_request":  "Request nonce has already been used",
+}
+
+// signatureAuth 讀取完整的 body 驗證簽章後放回請求，讓 handler 可以再次讀取；
+// 驗證前的請求尚未通過認證，body 超過 maxSignedBody 時回傳 413
+func (m *AuthMiddleware) signatureAuth(c *gin.Context) {
+	if m.verifier == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   "Request signing is not enabled",
+		})
+		c.Abort()
+		return
+	}
+
+	var body []byte
+	if c.Request.Body != nil {
+		var err error
+		if body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSignedBody)); err != nil {
+			status, message := http.StatusBadRequest, "Failed to read request body"
+			if bodyTooLarge(err) {
+				status, message = http.StatusRequestEntityTooLarge, "Request body is too large"
+			}
+			c.JSON(status, gin.H{
+				"success": false,
+				"error":   message,
+			})
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	merchant, err := m.verifier.Verify(c.Request.Context(), usecase.SignedRequest{
+		Authorization: c.GetHeader("Authorization"),
+		Method:        c.Request.Method,
+		URI:           c.Request.URL.RequestURI(),
+		Body:          body,
+	})
+	if err != nil {
+		message, ok := signatureErrors[errors.Code(err)]
+		if !ok {
+			// nonce 無法記錄時拒絕請求，避免重送的請求通過
+			logger.FromContext(c.Request.Context()).Error("request signature verification failed", zap.Error(err))
+			c.JSON(http.StatusServiceUnavailable, gin.H{
 				"success": false,
-				"error":   "Merchant account is inactive",
+				"error":   "Request signature could not be verified",
 			})
 			c.Abort()
 			return
 		}
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"success": false,
+			"error":   message,
+		})
+		c.Abort()
+		return
+	}
 
-		// 將商戶信息存儲在上下文中
-		c.Set("merchant", merchant)
+	if m.admit(c, merchant) {
 		c.Next()
 	}
 }
 
-func CORSMiddleware() gin.HandlerFunc {
+// PublishableKeyAuth 以 X-Publishable-Key 驗證瀏覽器呼叫，Origin 必須在商戶允許的來源內。
+// publishable key 可公開取得，只用於 /api/v1/browser 下的路由
+func (m *AuthMiddleware) PublishableKeyAuth() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		c.Header("Access-Control-Allow-Origin", "*")
-		c.Header("Access-Control-Allow-Credentials", "true")
-		c.Header("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, accept, origin, Cache-Control, X-Requested-With")
-		c.Header("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
+		publishableKey := c.GetHeader("X-Publishable-Key")
+		if publishableKey == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Publishable key is required",
+			})
+			c.Abort()
+			return
+		}
+
+		merchant, err := m.merchantRepo.GetByPublishableKey(c.Request.Context(), publishableKey)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid publishable key",
+			})
+			c.Abort()
+			return
+		}
 
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
+		origin := c.GetHeader("Origin")
+		if !merchant.AllowedOrigins.Allows(origin) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"success": false,
+				"error":   "Origin is not allowed",
+			})
+			c.Abort()
 			return
 		}
+		allowBrowserOrigin(c, origin)
+
+		if m.admit(c, merchant) {
+			c.Next()
+		}
+	}
+}
+
+// admit 拒絕停用的商戶，並將商戶存入上下文與日誌欄位後檢查請求頻率
+func (m *AuthMiddleware) admit(c *gin.Context, merchant *entity.Merchant) bool {
+	if !merchant.IsActive {
+		c.JSON(http.StatusForbidden, gin.H{
+			"success": false,
+			"error":   "Merchant account is inactive",
+		})
+		c.Abort()
+		return false
+	}
+
+	// 將商戶信息存儲在上下文中
+	c.Set("merchant", merchant)
+	ctx := c.Request.Context()
+	ctx = logger.WithContext(ctx, logger.FromContext(ctx).With(zap.String("merchant_id", merchant.ID.String())))
+	c.Request = c.Request.WithContext(ctx)
+	if m.rateLimiter != nil {
+		return m.rateLimit(c, merchant)
+	}
+	return true
+}
 
+// rateLimit 為商戶取出請求額度並設定 RateLimit-* 標頭，額度用完時回應 429。
+// 限流儲存發生錯誤時放行請求，避免影響正常交易
+func (m *AuthMiddleware) rateLimit(c *gin.Context, merchant *entity.Merchant) bool {
+	result, err := m.rateLimiter.Allow(c.Request.Context(), merchant, c.Request.Method, c.FullPath())
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Warn("rate limit check failed", zap.Error(err))
+		return true
+	}
+	if result == nil {
+		return true
+	}
+
+	c.Header("RateLimit-Limit", strconv.Itoa(result.Limit.Capacity()))
+	c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+	if result.Allowed {
+		return true
+	}
+
+	c.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"success": false,
+		"error":   "Rate limit exceeded",
+	})
+	c.Abort()
+	return false
+}
+
+// ceilSeconds 將等待時間無條件進位為整數秒
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// currentMerchant 取出 APIKeyAuth 驗證通過的商戶
+func currentMerchant(c *gin.Context) (*entity.Merchant, bool) {
+	value, ok := c.Get("merchant")
+	if !ok {
+		return nil, false
+	}
+	merchant, ok := value.(*entity.Merchant)
+	return merchant, ok
+}
+
+// AdminKeyAuth 以 X-Admin-Key 驗證平台管理 API，key 為空時一律拒絕
+func AdminKeyAuth(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		provided := c.GetHeader("X-Admin-Key")
+		if key == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(key)) != 1 {
+			c.JSON(http.StatusUnauthorized, gin.H{
+				"success": false,
+				"error":   "Invalid admin key",
+			})
+			c.Abort()
+			return
+		}
 		c.Next()
 	}
 }
@@ -92,7 +290,163 @@ func RequestIDMiddleware() gin.HandlerFunc {
 	}
 }
 
+// TracingMiddleware 從 W3C traceparent 延續上游的 trace 並為每個請求建立 server span。
+// 需放在 RequestIDMiddleware 之後：span 會帶上 request ID，回應則加上 X-Trace-ID 方便互相對照。
+func TracingMiddleware() gin.HandlerFunc {
+	tracer := otel.Tracer("github.com/company/payment-service")
+	return func(c *gin.Context) {
+		ctx := otel.GetTextMapPropagator().Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		spanName := c.Request.Method
+		if route != "" {
+			spanName += " " + route
+		}
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.HTTPRoute(route),
+				semconv.URLPath(c.Request.URL.Path),
+				attribute.String("http.request_id", c.GetString("request_id")),
+			),
+		)
+		defer span.End()
+
+		if sc := span.SpanContext(); sc.HasTraceID() {
+			c.Header("X-Trace-ID", sc.TraceID().String())
+			c.Set("trace_id", sc.TraceID().String())
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// AccessLogMiddleware 將帶有 request_id / trace_id 的 logger 放入請求 context，
+// 讓 use case 與 repository 可以透過 logger.FromContext 記錄同一請求的日誌；
+// 請求結束後輸出一筆存取日誌。需放在 RequestIDMiddleware 與 TracingMiddleware 之後。
+func AccessLogMiddleware(log logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		fields := []zap.Field{zap.String("request_id", c.GetString("request_id"))}
+		if traceID := c.GetString("trace_id"); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+		reqLogger := log.With(fields...)
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), reqLogger))
+
+		defer func() {
+			// RecoveryMiddleware 在外層，handler panic 時回應尚未寫出，先以 500 記錄後交給它處理
+			if r := recover(); r != nil {
+				logRequest(c, reqLogger, start, http.StatusInternalServerError)
+				panic(r)
+			}
+		}()
+		c.Next()
+		logRequest(c, reqLogger, start, c.Writer.Status())
+	}
+}
+
+func logRequest(c *gin.Context, reqLogger logger.Logger, start time.Time, status int) {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	entry := []zap.Field{
+		zap.String("method", c.Request.Method),
+		zap.String("route", route),
+		zap.String("path", c.Request.URL.Path),
+		zap.Int("status", status),
+		zap.Duration("latency", time.Since(start)),
+		zap.String("client_ip", c.ClientIP()),
+		zap.Int("size", c.Writer.Size()),
+	}
+	if merchant, ok := currentMerchant(c); ok {
+		entry = append(entry, zap.String("merchant_id", merchant.ID.String()))
+	}
+	if len(c.Errors) > 0 {
+		entry = append(entry, zap.String("errors", c.Errors.String()))
+	}
+
+	switch {
+	case status >= http.StatusInternalServerError:
+		reqLogger.Error("http request", entry...)
+	case status >= http.StatusBadRequest:
+		reqLogger.Warn("http request", entry...)
+	default:
+		reqLogger.Info("http request", entry...)
+	}
+}
+
+// RecoveryMiddleware 取代 gin.Recovery()：panic 會連同堆疊經由 zap 記錄，並回傳 500。
+// 需放在其他中間件之前，才能接住它們的 panic
+func RecoveryMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(c.Request.Context()).Error("panic recovered",
+					zap.Any("panic", r),
+					zap.String("method", c.Request.Method),
+					zap.String("path", c.Request.URL.Path),
+					zap.Stack("stack"),
+				)
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+					"success": false,
+					"error":   "Internal server error",
+				})
+			}
+		}()
+		c.Next()
+	}
+}
+
+// ReadYourWritesMiddleware 讓請求內寫入之後的讀取改走主庫。
+// 會修改資料的請求（先讀狀態再更新）一律讀主庫，避免副本延遲造成誤判；
+// 客戶端也可以帶 X-Read-Your-Writes: true 強制讀主庫（例如剛建立資源後立即查詢）。
+func ReadYourWritesMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		switch {
+		case c.GetHeader("X-Read-Your-Writes") == "true":
+			ctx = repository.WithPrimaryReads(ctx)
+		case c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead:
+			ctx = repository.WithPrimaryReads(ctx)
+		default:
+			ctx = repository.WithReadYourWrites(ctx)
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// MetricsRecorder 由指標實作提供，路由標籤使用 gin 的路由樣板以避免高基數
+type MetricsRecorder interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+	Handler() http.Handler
+}
+
+func MetricsMiddleware(recorder MetricsRecorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		recorder.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
+
 func generateRequestID() string {
 	// 簡單的請求ID生成，實際應用中可能需要更復雜的實現
 	return "req_" + strings.Replace(uuid.New().String(), "-", "", -1)[:16]
-}
\ No newline at end of file
+}
@@ -0,0 +1,414 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/internal/infrastructure/memory"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/company/payment-service/pkg/signing"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestTracingMiddlewarePropagatesTraceparent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), TracingMiddleware())
+	router.GET("/api/v1/payments/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/123", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("X-Request-ID", "req_test")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", rec.Header().Get("X-Trace-ID"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "GET /api/v1/payments/:id", span.Name())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+
+	attrs := make(map[string]string)
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	assert.Equal(t, "req_test", attrs["http.request_id"])
+	assert.Equal(t, "200", attrs["http.response.status_code"])
+}
+
+func TestAccessLogMiddlewareLogsRequestScopedFields(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestIDMiddleware(), AccessLogMiddleware(logger.NewFromZap(zap.New(core))), RecoveryMiddleware())
+	merchantID := uuid.New()
+	router.GET("/api/v1/payments/:id", func(c *gin.Context) {
+		c.Set("merchant", &entity.Merchant{ID: merchantID})
+		logger.FromContext(c.Request.Context()).Info("inside handler")
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/123", nil)
+	req.Header.Set("X-Request-ID", "req_test")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	inner := logs.FilterMessage("inside handler").All()
+	require.Len(t, inner, 1)
+	assert.Equal(t, "req_test", inner[0].ContextMap()["request_id"])
+
+	access := logs.FilterMessage("http request").All()
+	require.Len(t, access, 1)
+	fields := access[0].ContextMap()
+	assert.Equal(t, "GET", fields["method"])
+	assert.Equal(t, "/api/v1/payments/:id", fields["route"])
+	assert.EqualValues(t, http.StatusOK, fields["status"])
+	assert.Equal(t, merchantID.String(), fields["merchant_id"])
+	assert.Equal(t, "req_test", fields["request_id"])
+	assert.Contains(t, fields, "latency")
+}
+
+func TestRecoveryMiddlewareLogsPanicWithStack(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RecoveryMiddleware(), RequestIDMiddleware(), AccessLogMiddleware(logger.NewFromZap(zap.New(core))))
+	router.GET("/boom", func(c *gin.Context) { panic("boom") })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	panics := logs.FilterMessage("panic recovered").All()
+	require.Len(t, panics, 1)
+	assert.Equal(t, zapcore.ErrorLevel, panics[0].Level)
+	assert.Contains(t, panics[0].ContextMap()["stack"], "TestRecoveryMiddlewareLogsPanicWithStack")
+
+	access := logs.FilterMessage("http request").All()
+	require.Len(t, access, 1)
+	assert.EqualValues(t, http.StatusInternalServerError, access[0].ContextMap()["status"])
+	assert.Equal(t, panics[0].ContextMap()["request_id"], access[0].ContextMap()["request_id"])
+}
+
+func TestRecoveryMiddlewareRecoversMiddlewarePanics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RecoveryMiddleware(), func(c *gin.Context) { panic("middleware") })
+	router.GET("/api/v1/payments/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/payments/123", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.JSONEq(t, `{"success":false,"error":"Internal server error"}`, rec.Body.String())
+}
+
+func TestAdminKeyAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	serve := func(key, header string) int {
+		router := gin.New()
+		router.Use(AdminKeyAuth(key))
+		router.GET("/admin", func(c *gin.Context) { c.Status(http.StatusOK) })
+		req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+		if header != "" {
+			req.Header.Set("X-Admin-Key", header)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusOK, serve("secret", "secret"))
+	assert.Equal(t, http.StatusUnauthorized, serve("secret", "wrong"))
+	assert.Equal(t, http.StatusUnauthorized, serve("secret", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve("", ""), "no key configured")
+}
+
+func TestAPIKeyAuthRateLimit(t *testing.T) {
+	store := memory.NewStore()
+	store.LoadSampleData()
+	limiter, err := usecase.NewRateLimiter(memory.NewRateLimitRepository(store), usecase.RateLimitConfig{
+		Default: entity.RateLimit{Requests: 2, Period: time.Minute},
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewAuthMiddleware(memory.NewMerchantRepository(store), limiter, nil).APIKeyAuth())
+	router.GET("/api/v1/payments/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/payments/123", nil)
+		req.Header.Set("X-API-Key", "api_key_merchant_1")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for _, remaining := range []string{"1", "0"} {
+		rec := serve()
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, remaining, rec.Header().Get("RateLimit-Remaining"))
+	}
+
+	rec := serve()
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", rec.Header().Get("RateLimit-Reset"))
+	assert.Equal(t, "30", rec.Header().Get("Retry-After"))
+}
+
+func TestAPIKeyAuthSignedRequest(t *testing.T) {
+	store := memory.NewStore()
+	store.LoadSampleData()
+	merchantRepo := memory.NewMerchantRepository(store)
+	verifier := usecase.NewRequestVerifier(merchantRepo, memory.NewNonceRepository(store), time.Minute)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(NewAuthMiddleware(merchantRepo, nil, verifier).APIKeyAuth())
+	router.POST("/api/v1/payments", func(c *gin.Context) {
+		merchant, ok := currentMerchant(c)
+		require.True(t, ok)
+		body, err := io.ReadAll(c.Request.Body)
+		require.NoError(t, err)
+		c.String(http.StatusCreated, merchant.ID.String()+" "+string(body))
+	})
+	serve := func(req *http.Request) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	newRequest := func() *http.Request {
+		return httptest.NewRequest(http.MethodPost, "/api/v1/payments?expand=customer", strings.NewReader(`{"amount":100}`))
+	}
+	signer := signing.NewSigner("550e8400-e29b-41d4-a716-446655440001", "signing_secret_merchant_1")
+
+	// handler 仍可讀取已驗證的 body
+	req := newRequest()
+	require.NoError(t, signer.Sign(req))
+	authorization := req.Header.Get("Authorization")
+	rec := serve(req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, `550e8400-e29b-41d4-a716-446655440001 {"amount":100}`, rec.Body.String())
+
+	// 同一個簽章重送
+	req = newRequest()
+	req.Header.Set("Authorization", authorization)
+	rec = serve(req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "Request nonce has already been used")
+
+	// 簽署後修改 body
+	req = newRequest()
+	require.NoError(t, signer.Sign(req))
+	req.Body = io.NopCloser(strings.NewReader(`{"amount":1}`))
+	assert.Equal(t, http.StatusUnauthorized, serve(req).Code)
+
+	// 時鐘誤差超過容許範圍
+	stale := signing.NewSigner("550e8400-e29b-41d4-a716-446655440001", "signing_secret_merchant_1")
+	stale.Now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+	req = newRequest()
+	require.NoError(t, stale.Sign(req))
+	rec = serve(req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Contains(t, rec.Body.String(), "outside the allowed window")
+
+	// 驗證前讀入的 body 有上限
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/payments", strings.NewReader(strings.Repeat("a", maxSignedBody+1)))
+	require.NoError(t, signer.Sign(req))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, serve(req).Code)
+
+	// 商戶 2 未設定簽章金鑰
+	req = newRequest()
+	require.NoError(t, signing.NewSigner("550e8400-e29b-41d4-a716-446655440002", "").Sign(req))
+	assert.Equal(t, http.StatusUnauthorized, serve(req).Code)
+
+	// 未啟用簽章時拒絕簽署的請求，API key 不受影響
+	disabled := gin.New()
+	disabled.Use(NewAuthMiddleware(merchantRepo, nil, nil).APIKeyAuth())
+	disabled.POST("/api/v1/payments", func(c *gin.Context) { c.Status(http.StatusCreated) })
+	req = newRequest()
+	require.NoError(t, signer.Sign(req))
+	rec = httptest.NewRecorder()
+	disabled.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	req = newRequest()
+	req.Header.Set("Authorization", "Bearer api_key_merchant_1")
+	rec = httptest.NewRecorder()
+	disabled.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusCreated, rec.Code)
+}
+
+func TestCORSMiddleware(t *testing.T) {
+	policy, err := NewCORSPolicy(CORSConfig{
+		AllowedOrigins:   []string{"https://dashboard.example.com", "https://*.shop.example"},
+		AllowedMethods:   []string{"get", "post"},
+		AllowedHeaders:   []string{"Content-Type", "X-API-Key"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		MaxAge:           10 * time.Minute,
+		AllowCredentials: true,
+	})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(policy))
+	router.GET("/api/v1/payments/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func(method, origin string, preflight bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/payments/123", nil)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if preflight {
+			req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{name: "exact origin", origin: "https://dashboard.example.com", allowed: true},
+		{name: "subdomain", origin: "https://eu.shop.example", allowed: true},
+		{name: "nested subdomain", origin: "https://a.b.shop.example", allowed: true},
+		{name: "wildcard does not match the domain itself", origin: "https://shop.example"},
+		{name: "scheme must match", origin: "http://dashboard.example.com"},
+		{name: "suffix is not a subdomain", origin: "https://evilshop.example"},
+		{name: "other origin", origin: "https://attacker.test"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := serve(http.MethodGet, tt.origin, false)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Header().Values("Vary"), "Origin")
+			if tt.allowed {
+				assert.Equal(t, tt.origin, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+				assert.Equal(t, "X-Request-ID", rec.Header().Get("Access-Control-Expose-Headers"))
+			} else {
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+				assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+			}
+		})
+	}
+
+	rec := serve(http.MethodOptions, "https://dashboard.example.com", true)
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://dashboard.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "GET, POST", rec.Header().Get("Access-Control-Allow-Methods"))
+	assert.Equal(t, "Content-Type, X-API-Key", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Equal(t, "600", rec.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Access-Control-Request-Method")
+
+	rec = serve(http.MethodOptions, "https://attacker.test", true)
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	// 同源請求不帶 Origin
+	rec = serve(http.MethodGet, "", false)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestNewCORSPolicy_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config CORSConfig
+	}{
+		{name: "wildcard with credentials", config: CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true}},
+		{name: "origin with path", config: CORSConfig{AllowedOrigins: []string{"https://example.com/app"}}},
+		{name: "wildcard in the middle", config: CORSConfig{AllowedOrigins: []string{"https://app.*.example.com"}}},
+		{name: "missing scheme", config: CORSConfig{AllowedOrigins: []string{"example.com"}}},
+		{name: "negative max age", config: CORSConfig{MaxAge: -time.Second}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewCORSPolicy(tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestPublishableKeyAuth(t *testing.T) {
+	store := memory.NewStore()
+	store.LoadSampleData()
+	policy, err := NewCORSPolicy(CORSConfig{AllowedOrigins: []string{"https://dashboard.example.com"}})
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(CORSMiddleware(policy))
+	browser := router.Group("/api/v1/browser")
+	browser.Use(NewAuthMiddleware(memory.NewMerchantRepository(store), nil, nil).PublishableKeyAuth())
+	browser.POST("/vault/cards", func(c *gin.Context) {
+		merchant, ok := currentMerchant(c)
+		require.True(t, ok)
+		c.String(http.StatusCreated, merchant.ID.String())
+	})
+	serve := func(method, key, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/v1/browser/vault/cards", nil)
+		if key != "" {
+			req.Header.Set("X-Publishable-Key", key)
+		}
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if method == http.MethodOptions {
+			req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// 商戶 1 的測試資料允許 http://localhost:3000
+	rec := serve(http.MethodPost, "pk_merchant_1", "http://localhost:3000")
+	assert.Equal(t, http.StatusCreated, rec.Code)
+	assert.Equal(t, "550e8400-e29b-41d4-a716-446655440001", rec.Body.String())
+	assert.Equal(t, "http://localhost:3000", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Credentials"))
+
+	// 全域允許的來源不適用於 publishable key
+	rec = serve(http.MethodPost, "pk_merchant_1", "https://dashboard.example.com")
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "pk_merchant_2", "http://localhost:3000").Code)
+	assert.Equal(t, http.StatusForbidden, serve(http.MethodPost, "pk_merchant_1", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "pk_unknown", "http://localhost:3000").Code)
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodPost, "", "http://localhost:3000").Code)
+
+	// 預檢請求不帶 key，實際請求才檢查商戶的來源
+	rec = serve(http.MethodOptions, "", "https://any.example")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://any.example", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, browserAllowedHeaders, rec.Header().Get("Access-Control-Allow-Headers"))
+}
@@ -4,7 +4,9 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -20,27 +22,38 @@ func NewPaymentHandler(paymentUseCase usecase.PaymentUseCase) *PaymentHandler {
 }
 
 type CreatePaymentResponse struct {
-	Success bool   `json:"success"`
+	Success bool        `json:"success"`
 	Data    interface{} `json:"data,omitempty"`
-	Message string `json:"message,omitempty"`
-	Error   string `json:"error,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Error   string      `json:"error,omitempty"`
 }
 
 func (h *PaymentHandler) CreatePayment(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
 	var req usecase.CreatePaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
 			Success: false,
-			Error:   "Invalid request body: " + err.Error(),
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
 		})
 		return
 	}
+	// 商戶一律取自 API key，body 中的 merchant_id 不能用來替其他商戶建立付款
+	req.MerchantID = merchant.ID
+	req.ClientIP = c.ClientIP()
 
 	payment, err := h.paymentUseCase.CreatePayment(c.Request.Context(), req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, CreatePaymentResponse{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
@@ -53,34 +66,71 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 }
 
 func (h *PaymentHandler) GetPayment(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+	payment, ok := h.merchantPayment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    payment,
+	})
+}
+
+func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
+	owned, ok := h.merchantPayment(c)
+	if !ok {
+		return
+	}
+
+	// 請款交由背景 worker 執行，以 GET /payments/:id 查詢結果
+	payment, err := h.paymentUseCase.SubmitPayment(c.Request.Context(), owned.ID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
 			Success: false,
-			Error:   "Invalid payment ID format",
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
 
-	payment, err := h.paymentUseCase.GetPayment(c.Request.Context(), id)
-	if err != nil {
-		c.JSON(http.StatusNotFound, CreatePaymentResponse{
+	c.JSON(http.StatusAccepted, CreatePaymentResponse{
+		Success: true,
+		Data:    payment,
+		Message: "Payment accepted for processing",
+	})
+}
+
+func (h *PaymentHandler) CancelPayment(c *gin.Context) {
+	payment, ok := h.merchantPayment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.paymentUseCase.CancelPayment(c.Request.Context(), payment.ID); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, CreatePaymentResponse{
 		Success: true,
-		Data:    payment,
+		Message: "Payment cancelled successfully",
 	})
 }
 
-func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+// ApprovePayment 核准風險審核中的付款，之後照一般流程處理
+func (h *PaymentHandler) ApprovePayment(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
 			Success: false,
@@ -89,24 +139,33 @@ func (h *PaymentHandler) ProcessPayment(c *gin.Context) {
 		return
 	}
 
-	err = h.paymentUseCase.ProcessPayment(c.Request.Context(), id)
+	payment, err := h.paymentUseCase.ApprovePayment(c.Request.Context(), merchant.ID, id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, CreatePaymentResponse{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, CreatePaymentResponse{
 		Success: true,
-		Message: "Payment processed successfully",
+		Data:    payment,
+		Message: "Payment approved successfully",
 	})
 }
 
-func (h *PaymentHandler) CancelPayment(c *gin.Context) {
-	idParam := c.Param("id")
-	id, err := uuid.Parse(idParam)
+// RejectPayment 拒絕風險審核中的付款，body 可帶 {"reason": "..."}
+func (h *PaymentHandler) RejectPayment(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
 			Success: false,
@@ -115,18 +174,32 @@ func (h *PaymentHandler) CancelPayment(c *gin.Context) {
 		return
 	}
 
-	err = h.paymentUseCase.CancelPayment(c.Request.Context(), id)
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+				Success: false,
+				Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+			})
+			return
+		}
+	}
+
+	payment, err := h.paymentUseCase.RejectPayment(c.Request.Context(), merchant.ID, id, req.Reason)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, CreatePaymentResponse{
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
 
 	c.JSON(http.StatusOK, CreatePaymentResponse{
 		Success: true,
-		Message: "Payment cancelled successfully",
+		Data:    payment,
+		Message: "Payment rejected successfully",
 	})
 }
 
@@ -159,7 +232,7 @@ func (h *PaymentHandler) GetMerchantPayments(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, CreatePaymentResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   logger.RedactString(err.Error()),
 		})
 		return
 	}
@@ -168,4 +241,35 @@ func (h *PaymentHandler) GetMerchantPayments(c *gin.Context) {
 		Success: true,
 		Data:    payments,
 	})
-}
\ No newline at end of file
+}
+
+// merchantPayment 解析路徑中的付款 ID 並取得目前商戶的付款，其他商戶的付款回傳 404；
+// 失敗時已寫入回應
+func (h *PaymentHandler) merchantPayment(c *gin.Context) (*entity.Payment, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid payment ID format",
+		})
+		return nil, false
+	}
+
+	payment, err := h.paymentUseCase.GetMerchantPayment(c.Request.Context(), merchant.ID, id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusNotFound), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return nil, false
+	}
+	return payment, true
+}
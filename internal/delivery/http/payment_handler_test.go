@@ -0,0 +1,87 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/internal/infrastructure/memory"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentHandlerScopesPaymentsToMerchant(t *testing.T) {
+	store := memory.NewStore()
+	store.LoadSampleData()
+	merchantRepo := memory.NewMerchantRepository(store)
+	paymentRepo := memory.NewPaymentRepository(store)
+	payments := usecase.NewPaymentUseCase(paymentRepo, merchantRepo, memory.NewCustomerRepository(store),
+		memory.NewCardRepository(store), memory.NewPaymentMethodRepository(store), memory.NewInvoiceRepository(store),
+		memory.NewJobRepository(store), memory.NewBankTransferRepository(store), usecase.BankTransferConfig{},
+		memory.NewWalletActionRepository(store), usecase.WalletConfig{}, nil, nil, nil)
+
+	merchant1 := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	merchant2 := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
+	customerID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440101")
+	other := &entity.Payment{
+		ID: uuid.New(), MerchantID: merchant2, CustomerID: customerID, Amount: 1000, Currency: "USD",
+		Method: entity.PaymentMethodCreditCard, Status: entity.PaymentStatusPending,
+		PaymentMethodToken: "tok_merchant_2_card", CreatedAt: time.Now(), UpdatedAt: time.Now(),
+	}
+	require.NoError(t, paymentRepo.Create(context.Background(), other))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := NewPaymentHandler(payments)
+	api := router.Group("/api/v1/payments", NewAuthMiddleware(merchantRepo, nil, nil).APIKeyAuth())
+	api.POST("", handler.CreatePayment)
+	api.GET("/:id", handler.GetPayment)
+	api.POST("/:id/process", handler.ProcessPayment)
+	api.POST("/:id/cancel", handler.CancelPayment)
+	serve := func(method, path, apiKey, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-API-Key", apiKey)
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	path := "/api/v1/payments/" + other.ID.String()
+
+	t.Run("other merchant's payment is not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodGet, path, "api_key_merchant_1", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, path+"/process", "api_key_merchant_1", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, path+"/cancel", "api_key_merchant_1", "").Code)
+
+		stored, err := paymentRepo.GetByID(context.Background(), other.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusPending, stored.Status)
+	})
+
+	t.Run("owner reads the payment without the vault token", func(t *testing.T) {
+		rec := serve(http.MethodGet, path, "api_key_merchant_2", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), other.ID.String())
+		assert.NotContains(t, rec.Body.String(), "tok_merchant_2_card")
+	})
+
+	t.Run("merchant comes from the API key", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/api/v1/payments", "api_key_merchant_1",
+			`{"merchant_id": "`+merchant2.String()+`", "customer_id": "`+customerID.String()+`", "amount": 500, "currency": "USD", "method": "credit_card"}`)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+		var resp struct {
+			Data entity.Payment `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		assert.Equal(t, merchant1, resp.Data.MerchantID)
+	})
+}
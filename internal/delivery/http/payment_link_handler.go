@@ -0,0 +1,293 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaymentLinkHandler struct {
+	paymentLinkUseCase usecase.PaymentLinkUseCase
+}
+
+func NewPaymentLinkHandler(paymentLinkUseCase usecase.PaymentLinkUseCase) *PaymentLinkHandler {
+	return &PaymentLinkHandler{
+		paymentLinkUseCase: paymentLinkUseCase,
+	}
+}
+
+func (h *PaymentLinkHandler) CreateLink(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+
+	var req usecase.CreatePaymentLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchant.ID
+
+	link, err := h.paymentLinkUseCase.CreateLink(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    link,
+		Message: "Payment link created successfully",
+	})
+}
+
+func (h *PaymentLinkHandler) ListLinks(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	links, err := h.paymentLinkUseCase.ListLinks(c.Request.Context(), merchant.ID, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    links,
+	})
+}
+
+func (h *PaymentLinkHandler) GetLink(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.paymentLinkUseCase.GetLink(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    link,
+	})
+}
+
+func (h *PaymentLinkHandler) ActivateLink(c *gin.Context) {
+	h.setActive(c, true, "Payment link activated successfully")
+}
+
+func (h *PaymentLinkHandler) DeactivateLink(c *gin.Context) {
+	h.setActive(c, false, "Payment link deactivated successfully")
+}
+
+func (h *PaymentLinkHandler) setActive(c *gin.Context, active bool, message string) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	link, err := h.paymentLinkUseCase.SetActive(c.Request.Context(), merchantID, id, active)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    link,
+		Message: message,
+	})
+}
+
+func (h *PaymentLinkHandler) ListPayments(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	payments, err := h.paymentLinkUseCase.ListPayments(c.Request.Context(), merchantID, id, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    payments,
+	})
+}
+
+func (h *PaymentLinkHandler) GetStats(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.paymentLinkUseCase.GetStats(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    stats,
+	})
+}
+
+// ShowPage 顯示付款連結的公開頁面
+func (h *PaymentLinkHandler) ShowPage(c *gin.Context) {
+	h.showPage(c, false)
+}
+
+// ShowComplete 為未設定 success_url 的連結在付款後顯示完成訊息
+func (h *PaymentLinkHandler) ShowComplete(c *gin.Context) {
+	h.showPage(c, true)
+}
+
+func (h *PaymentLinkHandler) showPage(c *gin.Context, complete bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.pageError(c, http.StatusNotFound, "This payment link could not be found.")
+		return
+	}
+
+	hosted, err := h.paymentLinkUseCase.GetHostedLink(c.Request.Context(), id)
+	if err != nil {
+		h.pageError(c, errorStatus(err, http.StatusInternalServerError), linkErrorMessage(err))
+		return
+	}
+	h.render(c, http.StatusOK, paymentLinkPage{HostedPaymentLink: hosted, Complete: complete})
+}
+
+// Start 處理公開頁面送出的客戶資料，建立結帳後導向代管付款頁
+func (h *PaymentLinkHandler) Start(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		h.pageError(c, http.StatusNotFound, "This payment link could not be found.")
+		return
+	}
+
+	req := usecase.StartPaymentLinkRequest{
+		LinkID: id,
+		Name:   c.PostForm("name"),
+		Email:  c.PostForm("email"),
+		Amount: parseAmount(c.PostForm("amount")),
+	}
+	session, err := h.paymentLinkUseCase.StartCheckout(c.Request.Context(), req)
+	if err != nil {
+		h.rerender(c, id, err, req, c.PostForm("amount"))
+		return
+	}
+
+	target := session.URL
+	if target == "" {
+		target = "/checkout/" + session.ID.String()
+	}
+	c.Redirect(http.StatusSeeOther, target)
+}
+
+// rerender 帶著錯誤訊息與已填寫的欄位重新顯示公開頁面
+func (h *PaymentLinkHandler) rerender(c *gin.Context, id uuid.UUID, cause error, req usecase.StartPaymentLinkRequest, amount string) {
+	status := errorStatus(cause, http.StatusInternalServerError)
+	hosted, err := h.paymentLinkUseCase.GetHostedLink(c.Request.Context(), id)
+	if err != nil {
+		h.pageError(c, errorStatus(err, http.StatusInternalServerError), linkErrorMessage(err))
+		return
+	}
+	h.render(c, status, paymentLinkPage{
+		HostedPaymentLink: hosted,
+		Error:             linkErrorMessage(cause),
+		Name:              req.Name,
+		Email:             req.Email,
+		Amount:            amount,
+	})
+}
+
+func (h *PaymentLinkHandler) render(c *gin.Context, status int, page paymentLinkPage) {
+	body, err := renderPaymentLinkPage(page)
+	if err != nil {
+		h.pageError(c, http.StatusInternalServerError, linkErrorMessage(err))
+		return
+	}
+	setCheckoutHeaders(c)
+	c.Data(status, "text/html; charset=utf-8", body)
+}
+
+func (h *PaymentLinkHandler) pageError(c *gin.Context, status int, message string) {
+	setCheckoutHeaders(c)
+	c.String(status, message)
+}
+
+func (h *PaymentLinkHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
+
+func (h *PaymentLinkHandler) parseID(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid payment link ID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchant.ID, id, true
+}
+
+// linkErrorMessage 與 publicErrorMessage 相同，但找不到時提示付款連結
+func linkErrorMessage(err error) string {
+	if errors.Code(err) == "not_found" {
+		return "This payment link could not be found."
+	}
+	return publicErrorMessage(err)
+}
@@ -0,0 +1,94 @@
+package http
+
+import (
+	"bytes"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+)
+
+var paymentLinkTemplate = template.Must(template.New("payment_link").Funcs(template.FuncMap{
+	"money": formatMoney,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Pay {{.Merchant.Name}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; background: #f5f6f8; margin: 0; }
+main { max-width: 420px; margin: 40px auto; background: #fff; padding: 32px; border-radius: 8px; box-shadow: 0 1px 4px rgba(0,0,0,.1); }
+h1 { font-size: 18px; margin: 0 0 4px; }
+h2 { font-size: 16px; font-weight: normal; margin: 0; }
+.amount { font-size: 32px; font-weight: bold; margin: 12px 0; }
+.error { background: #fdecea; color: #a61b1b; padding: 10px; border-radius: 4px; margin-bottom: 16px; }
+label { display: block; margin: 12px 0 4px; font-size: 14px; }
+input[type=text], input[type=email] { width: 100%; box-sizing: border-box; padding: 8px; font-size: 16px; }
+button { width: 100%; padding: 12px; font-size: 16px; margin-top: 16px; cursor: pointer; }
+</style>
+</head>
+<body>
+<main>
+<h1>{{.Merchant.Name}}</h1>
+{{with .Link}}<h2>{{.Name}}</h2>
+{{if .Description}}<div>{{.Description}}</div>{{end}}
+{{if $.Complete}}
+<p>Thank you, your payment has been received.</p>
+{{else if not $.Available}}
+<p>This payment link is no longer available.</p>
+{{else}}
+{{if .Amount}}<div class="amount">{{money .Amount .Currency}}</div>{{end}}
+{{if $.Error}}<div class="error">{{$.Error}}</div>{{end}}
+<form method="post" action="/pay/{{.ID}}" autocomplete="on">
+{{if not .Amount}}
+<label for="amount">Amount ({{.Currency}})</label>
+<input type="text" id="amount" name="amount" value="{{$.Amount}}" inputmode="decimal" required>
+{{end}}
+<label for="name">Name</label>
+<input type="text" id="name" name="name" value="{{$.Name}}" autocomplete="name" required>
+<label for="email">Email</label>
+<input type="email" id="email" name="email" value="{{$.Email}}" autocomplete="email" required>
+<button type="submit">Continue to payment</button>
+</form>
+{{end}}{{end}}
+</main>
+</body>
+</html>
+`))
+
+// paymentLinkPage 為公開付款頁的顯示資料；Complete 時顯示付款完成訊息
+type paymentLinkPage struct {
+	*usecase.HostedPaymentLink
+	Complete bool
+	Error    string
+	Name     string
+	Email    string
+	Amount   string
+}
+
+func renderPaymentLinkPage(page paymentLinkPage) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := paymentLinkTemplate.Execute(&buf, page); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// parseAmount 將客戶輸入的金額（例如 "1,250.5"）轉為以分為單位，格式錯誤時回傳 0
+func parseAmount(s string) int64 {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	units, cents, found := strings.Cut(s, ".")
+	if units == "" || len(cents) > 2 || (found && cents == "") {
+		return 0
+	}
+	for len(cents) < 2 {
+		cents += "0"
+	}
+	n, err := strconv.ParseInt(units+cents, 10, 64)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,188 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaymentMethodHandler struct {
+	paymentMethodUseCase usecase.PaymentMethodUseCase
+}
+
+func NewPaymentMethodHandler(paymentMethodUseCase usecase.PaymentMethodUseCase) *PaymentMethodHandler {
+	return &PaymentMethodHandler{
+		paymentMethodUseCase: paymentMethodUseCase,
+	}
+}
+
+// CreatePaymentMethod 為客戶儲存付款方式；信用卡必須先經由卡片保險庫取得 token
+func (h *PaymentMethodHandler) CreatePaymentMethod(c *gin.Context) {
+	merchantID, customerID, ok := h.parseCustomer(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.CreatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+	req.CustomerID = customerID
+
+	method, err := h.paymentMethodUseCase.CreatePaymentMethod(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    method,
+		Message: "Payment method saved successfully",
+	})
+}
+
+func (h *PaymentMethodHandler) ListPaymentMethods(c *gin.Context) {
+	merchantID, customerID, ok := h.parseCustomer(c)
+	if !ok {
+		return
+	}
+
+	methods, err := h.paymentMethodUseCase.ListPaymentMethods(c.Request.Context(), merchantID, customerID)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    methods,
+	})
+}
+
+func (h *PaymentMethodHandler) GetPaymentMethod(c *gin.Context) {
+	merchantID, customerID, id, ok := h.parseMethod(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.paymentMethodUseCase.GetPaymentMethod(c.Request.Context(), merchantID, customerID, id)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    method,
+	})
+}
+
+// UpdatePaymentMethod 可更新效期、帳單資訊或設為預設，token 與類型不可變更
+func (h *PaymentMethodHandler) UpdatePaymentMethod(c *gin.Context) {
+	merchantID, customerID, id, ok := h.parseMethod(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.UpdatePaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+	req.CustomerID = customerID
+	req.ID = id
+
+	method, err := h.paymentMethodUseCase.UpdatePaymentMethod(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    method,
+		Message: "Payment method updated successfully",
+	})
+}
+
+func (h *PaymentMethodHandler) DeletePaymentMethod(c *gin.Context) {
+	merchantID, customerID, id, ok := h.parseMethod(c)
+	if !ok {
+		return
+	}
+
+	if err := h.paymentMethodUseCase.DeletePaymentMethod(c.Request.Context(), merchantID, customerID, id); err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Message: "Payment method deleted successfully",
+	})
+}
+
+// parseCustomer 取得目前商戶與路徑中的客戶 ID，失敗時已寫入回應
+func (h *PaymentMethodHandler) parseCustomer(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	customerID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid customer ID format",
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchant.ID, customerID, true
+}
+
+func (h *PaymentMethodHandler) parseMethod(c *gin.Context) (uuid.UUID, uuid.UUID, uuid.UUID, bool) {
+	merchantID, customerID, ok := h.parseCustomer(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("methodId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid payment method ID format",
+		})
+		return uuid.Nil, uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, customerID, id, true
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type ReconciliationHandler struct {
+	reconciliationUseCase usecase.ReconciliationUseCase
+}
+
+func NewReconciliationHandler(reconciliationUseCase usecase.ReconciliationUseCase) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationUseCase: reconciliationUseCase,
+	}
+}
+
+// ImportStatement 匯入對帳單並回傳對帳報表，?format=csv 或 camt053，未指定時自動判斷
+func (h *ReconciliationHandler) ImportStatement(c *gin.Context) {
+	body, ok := readStatement(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.reconciliationUseCase.Import(c.Request.Context(), bytes.NewReader(body), c.Query("format"))
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    report,
+		Message: "Bank statement reconciled successfully",
+	})
+}
+
+func (h *ReconciliationHandler) ListStatements(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	statements, err := h.reconciliationUseCase.ListStatements(c.Request.Context(), limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    statements,
+	})
+}
+
+func (h *ReconciliationHandler) GetReport(c *gin.Context) {
+	statementID, ok := h.parseID(c, "id", "statement")
+	if !ok {
+		return
+	}
+
+	report, err := h.reconciliationUseCase.GetReport(c.Request.Context(), statementID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// ResolveLine 處理銀行端差異，body 為 {"payment_id": "...", "note": "..."}
+func (h *ReconciliationHandler) ResolveLine(c *gin.Context) {
+	statementID, ok := h.parseID(c, "id", "statement")
+	if !ok {
+		return
+	}
+	lineID, ok := h.parseID(c, "lineId", "statement line")
+	if !ok {
+		return
+	}
+
+	var req usecase.ResolveLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	report, err := h.reconciliationUseCase.ResolveLine(c.Request.Context(), statementID, lineID, req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    report,
+		Message: "Statement line resolved successfully",
+	})
+}
+
+// ResolveException 處理系統端差異，body 為 {"note": "..."}
+func (h *ReconciliationHandler) ResolveException(c *gin.Context) {
+	statementID, ok := h.parseID(c, "id", "statement")
+	if !ok {
+		return
+	}
+	paymentID, ok := h.parseID(c, "paymentId", "payment")
+	if !ok {
+		return
+	}
+
+	var req usecase.ResolveExceptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	report, err := h.reconciliationUseCase.ResolveException(c.Request.Context(), statementID, paymentID, req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    report,
+		Message: "Statement exception resolved successfully",
+	})
+}
+
+func (h *ReconciliationHandler) parseID(c *gin.Context, param, name string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param(param))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid " + name + " ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *ReconciliationHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
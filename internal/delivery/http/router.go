@@ -1,40 +1,107 @@
 package http
 
 import (
+	"context"
+
 	"github.com/company/payment-service/internal/domain/repository"
 	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(
-	paymentUseCase usecase.PaymentUseCase,
-	merchantRepo repository.MerchantRepository,
-) *gin.Engine {
+type RouterConfig struct {
+	PaymentUseCase usecase.PaymentUseCase
+	VaultUseCase   usecase.VaultUseCase
+	// PaymentMethodUseCase 為 nil 時不註冊客戶付款方式路由
+	PaymentMethodUseCase usecase.PaymentMethodUseCase
+	// SubscriptionUseCase 為 nil 時不註冊方案與訂閱路由
+	SubscriptionUseCase usecase.SubscriptionUseCase
+	// InvoiceUseCase 為 nil 時不註冊帳單路由
+	InvoiceUseCase usecase.InvoiceUseCase
+	// CheckoutUseCase 為 nil 時不註冊結帳 API 與代管付款頁
+	CheckoutUseCase usecase.CheckoutUseCase
+	// PaymentLinkUseCase 為 nil 時不註冊付款連結 API 與公開付款頁
+	PaymentLinkUseCase usecase.PaymentLinkUseCase
+	// BankTransferUseCase 為 nil 時不註冊銀行入帳管理 API
+	BankTransferUseCase usecase.BankTransferUseCase
+	// ReconciliationUseCase 為 nil 時不註冊對帳單匯入與對帳報表 API
+	ReconciliationUseCase usecase.ReconciliationUseCase
+	// WalletUseCase 為 nil 時不註冊錢包回呼
+	WalletUseCase usecase.WalletUseCase
+	// WebhookUseCase 為 nil 時不註冊網關 webhook 與管理 API
+	WebhookUseCase usecase.WebhookUseCase
+	// DisputeUseCase 為 nil 時不註冊爭議、證據與帳務 API
+	DisputeUseCase usecase.DisputeUseCase
+	// LimitUseCase 為 nil 時不註冊商戶限額 API
+	LimitUseCase usecase.LimitUseCase
+	// MerchantUseCase 為 nil 時不註冊商戶 publishable key、允許來源與簽章金鑰的管理 API
+	MerchantUseCase usecase.MerchantUseCase
+	// RateLimiter 為 nil 時不限制商戶 API 的請求頻率
+	RateLimiter usecase.RateLimiter
+	// RequestVerifier 為 nil 時商戶 API 只接受 API key，不接受 HMAC 簽章
+	RequestVerifier usecase.RequestVerifier
+	// CORS 為 nil 時不允許跨來源請求，/api/v1/browser 下的路由仍依商戶允許的來源檢查
+	CORS *CORSPolicy
+	// AdminAPIKey 為平台管理 API 的 X-Admin-Key，為空時管理 API 一律拒絕
+	AdminAPIKey  string
+	MerchantRepo repository.MerchantRepository
+	Health       *HealthHandler
+	// Logger 為 nil 時使用 logger 套件的預設 logger
+	Logger logger.Logger
+	// Metrics 為 nil 時不輸出 /metrics
+	Metrics     MetricsRecorder
+	MetricsPath string
+}
+
+func SetupRouter(cfg RouterConfig) *gin.Engine {
 	// 設置 Gin 模式
 	gin.SetMode(gin.ReleaseMode)
 
 	router := gin.New()
 
-	// 中間件
-	router.Use(gin.Logger())
-	router.Use(gin.Recovery())
-	router.Use(CORSMiddleware())
+	// 中間件：recovery 緊接在 metrics 之後，其他中間件 panic 時也回傳 JSON 500 並記錄為 500
+	if cfg.Metrics != nil {
+		router.Use(MetricsMiddleware(cfg.Metrics))
+	}
+	router.Use(RecoveryMiddleware())
+	log := cfg.Logger
+	if log == nil {
+		log = logger.FromContext(context.Background())
+	}
+	cors := cfg.CORS
+	if cors == nil {
+		cors, _ = NewCORSPolicy(CORSConfig{})
+	}
+	router.Use(CORSMiddleware(cors))
 	router.Use(RequestIDMiddleware())
+	router.Use(TracingMiddleware())
+	router.Use(AccessLogMiddleware(log))
+	router.Use(ReadYourWritesMiddleware())
 
 	// 健康檢查
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "ok",
-			"service": "payment-service",
-		})
-	})
+	healthHandler := cfg.Health
+	if healthHandler == nil {
+		healthHandler = NewHealthHandler(HealthConfig{Version: VersionInfo{Name: "payment-service"}})
+	}
+	router.GET("/health", healthHandler.Health)
+	router.GET("/livez", healthHandler.Livez)
+	router.GET("/readyz", healthHandler.Readyz)
+	router.GET("/version", healthHandler.Version)
+
+	if cfg.Metrics != nil {
+		metricsPath := cfg.MetricsPath
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+		router.GET(metricsPath, gin.WrapH(cfg.Metrics.Handler()))
+	}
 
 	// API 路由組
 	api := router.Group("/api/v1")
 
 	// 初始化處理器
-	paymentHandler := NewPaymentHandler(paymentUseCase)
-	authMiddleware := NewAuthMiddleware(merchantRepo)
+	paymentHandler := NewPaymentHandler(cfg.PaymentUseCase)
+	authMiddleware := NewAuthMiddleware(cfg.MerchantRepo, cfg.RateLimiter, cfg.RequestVerifier)
 
 	// 支付相關路由 - 需要API密鑰驗證
 	payments := api.Group("/payments")
@@ -44,6 +111,215 @@ func SetupRouter(
 		payments.GET("/:id", paymentHandler.GetPayment)
 		payments.POST("/:id/process", paymentHandler.ProcessPayment)
 		payments.POST("/:id/cancel", paymentHandler.CancelPayment)
+		payments.POST("/:id/approve", paymentHandler.ApprovePayment)
+		payments.POST("/:id/reject", paymentHandler.RejectPayment)
+	}
+
+	// 卡片保險庫
+	if cfg.VaultUseCase != nil {
+		vaultHandler := NewVaultHandler(cfg.VaultUseCase)
+		vault := api.Group("/vault")
+		vault.Use(authMiddleware.APIKeyAuth())
+		{
+			vault.POST("/cards", vaultHandler.TokenizeCard)
+			vault.GET("/cards/:token", vaultHandler.GetCard)
+		}
+
+		// 瀏覽器直接送出卡號，卡號不經過商戶伺服器
+		browser := api.Group("/browser")
+		browser.Use(authMiddleware.PublishableKeyAuth())
+		{
+			browser.POST("/vault/cards", vaultHandler.TokenizeCard)
+		}
+	}
+
+	// 客戶儲存的付款方式
+	if cfg.PaymentMethodUseCase != nil {
+		paymentMethodHandler := NewPaymentMethodHandler(cfg.PaymentMethodUseCase)
+		customers := api.Group("/customers")
+		customers.Use(authMiddleware.APIKeyAuth())
+		{
+			customers.GET("/:id/payment-methods", paymentMethodHandler.ListPaymentMethods)
+			customers.POST("/:id/payment-methods", paymentMethodHandler.CreatePaymentMethod)
+			customers.GET("/:id/payment-methods/:methodId", paymentMethodHandler.GetPaymentMethod)
+			customers.PUT("/:id/payment-methods/:methodId", paymentMethodHandler.UpdatePaymentMethod)
+			customers.DELETE("/:id/payment-methods/:methodId", paymentMethodHandler.DeletePaymentMethod)
+		}
+	}
+
+	// 定期收費方案與訂閱
+	if cfg.SubscriptionUseCase != nil {
+		subscriptionHandler := NewSubscriptionHandler(cfg.SubscriptionUseCase)
+		plans := api.Group("/plans")
+		plans.Use(authMiddleware.APIKeyAuth())
+		{
+			plans.POST("", subscriptionHandler.CreatePlan)
+			plans.GET("", subscriptionHandler.ListPlans)
+			plans.GET("/:id", subscriptionHandler.GetPlan)
+		}
+
+		subscriptions := api.Group("/subscriptions")
+		subscriptions.Use(authMiddleware.APIKeyAuth())
+		{
+			subscriptions.POST("", subscriptionHandler.CreateSubscription)
+			subscriptions.GET("", subscriptionHandler.ListSubscriptions)
+			subscriptions.GET("/:id", subscriptionHandler.GetSubscription)
+			subscriptions.POST("/:id/change-plan", subscriptionHandler.ChangePlan)
+			subscriptions.POST("/:id/cancel", subscriptionHandler.CancelSubscription)
+			subscriptions.POST("/:id/resume", subscriptionHandler.ResumeSubscription)
+		}
+	}
+
+	// 帳單
+	if cfg.InvoiceUseCase != nil {
+		invoiceHandler := NewInvoiceHandler(cfg.InvoiceUseCase)
+		invoices := api.Group("/invoices")
+		invoices.Use(authMiddleware.APIKeyAuth())
+		{
+			invoices.POST("", invoiceHandler.CreateInvoice)
+			invoices.GET("", invoiceHandler.ListInvoices)
+			invoices.GET("/:id", invoiceHandler.GetInvoice)
+			invoices.PUT("/:id", invoiceHandler.UpdateInvoice)
+			invoices.POST("/:id/finalize", invoiceHandler.FinalizeInvoice)
+			invoices.POST("/:id/void", invoiceHandler.VoidInvoice)
+		}
+	}
+
+	// 代管結帳：API 需要密鑰，付款頁以 session ID 存取
+	if cfg.CheckoutUseCase != nil {
+		checkoutHandler := NewCheckoutHandler(cfg.CheckoutUseCase)
+		sessions := api.Group("/checkout/sessions")
+		sessions.Use(authMiddleware.APIKeyAuth())
+		{
+			sessions.POST("", checkoutHandler.CreateSession)
+			sessions.GET("/:id", checkoutHandler.GetSession)
+			sessions.POST("/:id/expire", checkoutHandler.ExpireSession)
+		}
+
+		checkout := router.Group("/checkout")
+		{
+			checkout.GET("/:id", checkoutHandler.ShowPage)
+			checkout.POST("/:id", checkoutHandler.Complete)
+			checkout.POST("/:id/cancel", checkoutHandler.Cancel)
+		}
+	}
+
+	// 付款連結：API 需要密鑰，公開頁面以連結 ID 存取，付款交由代管結帳完成
+	if cfg.PaymentLinkUseCase != nil {
+		paymentLinkHandler := NewPaymentLinkHandler(cfg.PaymentLinkUseCase)
+		links := api.Group("/payment-links")
+		links.Use(authMiddleware.APIKeyAuth())
+		{
+			links.POST("", paymentLinkHandler.CreateLink)
+			links.GET("", paymentLinkHandler.ListLinks)
+			links.GET("/:id", paymentLinkHandler.GetLink)
+			links.POST("/:id/activate", paymentLinkHandler.ActivateLink)
+			links.POST("/:id/deactivate", paymentLinkHandler.DeactivateLink)
+			links.GET("/:id/payments", paymentLinkHandler.ListPayments)
+			links.GET("/:id/stats", paymentLinkHandler.GetStats)
+		}
+
+		pay := router.Group("/pay")
+		{
+			pay.GET("/:id", paymentLinkHandler.ShowPage)
+			pay.POST("/:id", paymentLinkHandler.Start)
+			pay.GET("/:id/complete", paymentLinkHandler.ShowComplete)
+		}
+	}
+
+	// 銀行入帳：對帳單屬於平台帳戶，以管理密鑰存取
+	if cfg.BankTransferUseCase != nil {
+		bankTransferHandler := NewBankTransferHandler(cfg.BankTransferUseCase)
+		credits := api.Group("/admin/bank-credits")
+		credits.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			credits.POST("", bankTransferHandler.IngestCredits)
+			credits.POST("/import", bankTransferHandler.ImportStatement)
+			credits.GET("", bankTransferHandler.ListCredits)
+		}
+	}
+
+	// 對帳：匯入銀行對帳單並人工處理差異項目
+	if cfg.ReconciliationUseCase != nil {
+		reconciliationHandler := NewReconciliationHandler(cfg.ReconciliationUseCase)
+		statements := api.Group("/admin/statements")
+		statements.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			statements.POST("/import", reconciliationHandler.ImportStatement)
+			statements.GET("", reconciliationHandler.ListStatements)
+			statements.GET("/:id", reconciliationHandler.GetReport)
+			statements.POST("/:id/lines/:lineId/resolve", reconciliationHandler.ResolveLine)
+			statements.POST("/:id/payments/:paymentId/resolve", reconciliationHandler.ResolveException)
+		}
+	}
+
+	// 錢包回呼：不使用商戶密鑰，以回呼簽章驗證
+	if cfg.WalletUseCase != nil {
+		walletHandler := NewWalletHandler(cfg.WalletUseCase)
+		api.POST("/wallet/callback", walletHandler.Callback)
+	}
+
+	// 網關 webhook：以各網關的簽章驗證，管理 API 可查詢與重新處理保存的事件
+	if cfg.WebhookUseCase != nil {
+		webhookHandler := NewWebhookHandler(cfg.WebhookUseCase)
+		router.POST("/webhooks/:provider", webhookHandler.Receive)
+
+		webhooks := api.Group("/admin/webhooks")
+		webhooks.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			webhooks.GET("", webhookHandler.ListEvents)
+			webhooks.GET("/:id", webhookHandler.GetEvent)
+			webhooks.POST("/:id/replay", webhookHandler.Replay)
+		}
+	}
+
+	// 爭議：平台建立與裁決爭議，商戶上傳證據並查詢事件與帳務沖正
+	if cfg.DisputeUseCase != nil {
+		disputeHandler := NewDisputeHandler(cfg.DisputeUseCase)
+		disputes := api.Group("/disputes")
+		disputes.Use(authMiddleware.APIKeyAuth())
+		{
+			disputes.GET("", disputeHandler.ListDisputes)
+			disputes.GET("/events", disputeHandler.ListEvents)
+			disputes.GET("/:id", disputeHandler.GetDispute)
+			disputes.POST("/:id/evidence", disputeHandler.AddEvidence)
+			disputes.GET("/:id/evidence/:evidenceId/file", disputeHandler.DownloadEvidence)
+			disputes.POST("/:id/submit", disputeHandler.SubmitEvidence)
+		}
+		api.GET("/ledger", authMiddleware.APIKeyAuth(), disputeHandler.ListLedgerEntries)
+
+		adminDisputes := api.Group("/admin/disputes")
+		adminDisputes.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			adminDisputes.POST("", disputeHandler.OpenDispute)
+			adminDisputes.POST("/:id/resolve", disputeHandler.ResolveDispute)
+		}
+	}
+
+	// 商戶限額：平台設定限額，商戶與平台都可查詢當日用量
+	if cfg.LimitUseCase != nil {
+		limitHandler := NewLimitHandler(cfg.LimitUseCase)
+		api.GET("/limits", authMiddleware.APIKeyAuth(), limitHandler.GetUsage)
+
+		adminLimits := api.Group("/admin/merchants/:id/limits")
+		adminLimits.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			adminLimits.GET("", limitHandler.GetMerchantUsage)
+			adminLimits.PUT("", limitHandler.SetLimits)
+		}
+	}
+
+	// 商戶 publishable key、允許的瀏覽器來源與請求簽章金鑰
+	if cfg.MerchantUseCase != nil {
+		merchantHandler := NewMerchantHandler(cfg.MerchantUseCase)
+		adminMerchants := api.Group("/admin/merchants/:id")
+		adminMerchants.Use(AdminKeyAuth(cfg.AdminAPIKey))
+		{
+			adminMerchants.GET("", merchantHandler.GetMerchant)
+			adminMerchants.PUT("/allowed-origins", merchantHandler.SetAllowedOrigins)
+			adminMerchants.POST("/publishable-key", merchantHandler.RotatePublishableKey)
+			adminMerchants.POST("/signing-secret", merchantHandler.RotateSigningSecret)
+		}
 	}
 
 	// 商戶相關路由
@@ -54,4 +330,4 @@ func SetupRouter(
 	}
 
 	return router
-}
\ No newline at end of file
+}
@@ -0,0 +1,282 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type SubscriptionHandler struct {
+	subscriptionUseCase usecase.SubscriptionUseCase
+}
+
+func NewSubscriptionHandler(subscriptionUseCase usecase.SubscriptionUseCase) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionUseCase: subscriptionUseCase,
+	}
+}
+
+type CancelSubscriptionRequest struct {
+	AtPeriodEnd bool `json:"at_period_end"`
+}
+
+func (h *SubscriptionHandler) CreatePlan(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.CreatePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+
+	plan, err := h.subscriptionUseCase.CreatePlan(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    plan,
+		Message: "Plan created successfully",
+	})
+}
+
+func (h *SubscriptionHandler) ListPlans(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	plans, err := h.subscriptionUseCase.ListPlans(c.Request.Context(), merchantID)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    plans,
+	})
+}
+
+func (h *SubscriptionHandler) GetPlan(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, "Invalid plan ID format")
+	if !ok {
+		return
+	}
+
+	plan, err := h.subscriptionUseCase.GetPlan(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    plan,
+	})
+}
+
+// CreateSubscription 建立訂閱；沒有試用期時會立即扣第一期款項
+func (h *SubscriptionHandler) CreateSubscription(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+
+	sub, err := h.subscriptionUseCase.CreateSubscription(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    sub,
+		Message: "Subscription created successfully",
+	})
+}
+
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	subs, err := h.subscriptionUseCase.ListSubscriptions(c.Request.Context(), merchantID, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    subs,
+	})
+}
+
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, "Invalid subscription ID format")
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionUseCase.GetSubscription(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    sub,
+	})
+}
+
+// ChangePlan 變更方案或數量，預設將本期剩餘時間的差額計入下期帳單
+func (h *SubscriptionHandler) ChangePlan(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, "Invalid subscription ID format")
+	if !ok {
+		return
+	}
+
+	var req usecase.ChangePlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchantID
+	req.ID = id
+
+	sub, err := h.subscriptionUseCase.ChangePlan(c.Request.Context(), req)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    sub,
+		Message: "Subscription plan changed successfully",
+	})
+}
+
+// CancelSubscription 預設立即取消；at_period_end 為 true 時於本期結束後取消
+func (h *SubscriptionHandler) CancelSubscription(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, "Invalid subscription ID format")
+	if !ok {
+		return
+	}
+
+	var req CancelSubscriptionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+				Success: false,
+				Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+			})
+			return
+		}
+	}
+
+	sub, err := h.subscriptionUseCase.CancelSubscription(c.Request.Context(), merchantID, id, req.AtPeriodEnd)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    sub,
+		Message: "Subscription canceled successfully",
+	})
+}
+
+func (h *SubscriptionHandler) ResumeSubscription(c *gin.Context) {
+	merchantID, id, ok := h.parseID(c, "Invalid subscription ID format")
+	if !ok {
+		return
+	}
+
+	sub, err := h.subscriptionUseCase.ResumeSubscription(c.Request.Context(), merchantID, id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    sub,
+		Message: "Subscription resumed successfully",
+	})
+}
+
+func (h *SubscriptionHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
+
+// merchantID 取得目前商戶 ID，失敗時已寫入回應
+func (h *SubscriptionHandler) merchantID(c *gin.Context) (uuid.UUID, bool) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return uuid.Nil, false
+	}
+	return merchant.ID, true
+}
+
+func (h *SubscriptionHandler) parseID(c *gin.Context, invalid string) (uuid.UUID, uuid.UUID, bool) {
+	merchantID, ok := h.merchantID(c)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   invalid,
+		})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return merchantID, id, true
+}
@@ -0,0 +1,82 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type VaultHandler struct {
+	vaultUseCase usecase.VaultUseCase
+}
+
+func NewVaultHandler(vaultUseCase usecase.VaultUseCase) *VaultHandler {
+	return &VaultHandler{
+		vaultUseCase: vaultUseCase,
+	}
+}
+
+// TokenizeCard 接收卡號、效期與 CVV，回傳可用於建立付款的 token。
+// 回應只包含卡別、末四碼與效期，卡號與 CVV 不會出現在回應或日誌中。
+func (h *VaultHandler) TokenizeCard(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+
+	var req usecase.TokenizeCardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+	req.MerchantID = merchant.ID
+
+	card, err := h.vaultUseCase.TokenizeCard(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, CreatePaymentResponse{
+		Success: true,
+		Data:    card,
+		Message: "Card tokenized successfully",
+	})
+}
+
+func (h *VaultHandler) GetCard(c *gin.Context) {
+	merchant, ok := currentMerchant(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, CreatePaymentResponse{
+			Success: false,
+			Error:   "API key is required",
+		})
+		return
+	}
+
+	card, err := h.vaultUseCase.GetCard(c.Request.Context(), merchant.ID, c.Param("token"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    card,
+	})
+}
@@ -0,0 +1,46 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+)
+
+type WalletHandler struct {
+	walletUseCase usecase.WalletUseCase
+}
+
+func NewWalletHandler(walletUseCase usecase.WalletUseCase) *WalletHandler {
+	return &WalletHandler{
+		walletUseCase: walletUseCase,
+	}
+}
+
+// Callback 接收錢包的確認結果，body 為 {"payment_id", "result", "timestamp", "signature"}
+func (h *WalletHandler) Callback(c *gin.Context) {
+	var req usecase.WalletCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid request body: " + logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	payment, err := h.walletUseCase.HandleCallback(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+			Success: false,
+			Error:   logger.RedactString(err.Error()),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    payment,
+		Message: "Wallet callback processed successfully",
+	})
+}
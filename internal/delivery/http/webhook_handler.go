@@ -0,0 +1,150 @@
+package http
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxWebhookSize 限制網關 webhook 的大小
+const maxWebhookSize = 1 << 20
+
+type WebhookHandler struct {
+	webhookUseCase usecase.WebhookUseCase
+}
+
+func NewWebhookHandler(webhookUseCase usecase.WebhookUseCase) *WebhookHandler {
+	return &WebhookHandler{
+		webhookUseCase: webhookUseCase,
+	}
+}
+
+// Receive 接收網關的 webhook；簽章以原始 body 計算，因此不先解析 JSON。超過 maxWebhookSize
+// 時回應 413，不以截斷的 body 驗證簽章
+func (h *WebhookHandler) Receive(c *gin.Context) {
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookSize))
+	if err != nil {
+		status, message := http.StatusBadRequest, "Invalid request body: "+err.Error()
+		if bodyTooLarge(err) {
+			status, message = http.StatusRequestEntityTooLarge, "Webhook is too large"
+		}
+		c.JSON(status, CreatePaymentResponse{
+			Success: false,
+			Error:   message,
+		})
+		return
+	}
+
+	receipt, err := h.webhookUseCase.Receive(c.Request.Context(), c.Param("provider"), c.Request.Header, body)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	message := "Webhook received successfully"
+	if receipt.Duplicate {
+		message = "Webhook already received"
+	}
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    receipt,
+		Message: message,
+	})
+}
+
+func (h *WebhookHandler) ListEvents(c *gin.Context) {
+	status := entity.WebhookEventStatus(c.Query("status"))
+	switch status {
+	case "", entity.WebhookEventStatusReceived, entity.WebhookEventStatusProcessed,
+		entity.WebhookEventStatusIgnored, entity.WebhookEventStatusFailed:
+	default:
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid status",
+		})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	offset, err := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+
+	events, err := h.webhookUseCase.ListEvents(c.Request.Context(), c.Query("provider"), status, limit, offset)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    events,
+	})
+}
+
+func (h *WebhookHandler) GetEvent(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.webhookUseCase.GetEvent(c.Request.Context(), id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    event,
+	})
+}
+
+// Replay 以保存的原始內容重新處理事件
+func (h *WebhookHandler) Replay(c *gin.Context) {
+	id, ok := h.parseID(c)
+	if !ok {
+		return
+	}
+
+	event, err := h.webhookUseCase.Replay(c.Request.Context(), id)
+	if err != nil {
+		h.error(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, CreatePaymentResponse{
+		Success: true,
+		Data:    event,
+		Message: "Webhook replayed successfully",
+	})
+}
+
+func (h *WebhookHandler) parseID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, CreatePaymentResponse{
+			Success: false,
+			Error:   "Invalid webhook event ID format",
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) error(c *gin.Context, err error) {
+	c.JSON(errorStatus(err, http.StatusInternalServerError), CreatePaymentResponse{
+		Success: false,
+		Error:   logger.RedactString(err.Error()),
+	})
+}
@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+)
+
+// NewBankTransferExpiry 定期取消逾期未付足的銀行轉帳付款。
+// 逾期以條件更新標記，多個實例同時執行也只會處理一次
+func NewBankTransferExpiry(transfers usecase.BankTransferUseCase, interval time.Duration, batchSize int) *Periodic {
+	return NewPeriodic("bank_transfer_expiry", transfers.ExpireDue, interval, batchSize)
+}
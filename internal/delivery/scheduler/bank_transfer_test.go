@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeTransfers struct {
+	usecase.BankTransferUseCase
+	batches []int
+	calls   int
+}
+
+func (f *fakeTransfers) ExpireDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	if f.calls >= len(f.batches) {
+		return 0, nil
+	}
+	n := f.batches[f.calls]
+	f.calls++
+	return n, nil
+}
+
+func TestBankTransferExpiry_RunOnceDrainsFullBatches(t *testing.T) {
+	transfers := &fakeTransfers{batches: []int{3, 3, 0}}
+	s := NewBankTransferExpiry(transfers, time.Minute, 3)
+
+	n, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 6, n)
+	assert.Equal(t, 3, transfers.calls)
+}
@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+)
+
+// NewBillingScheduler 定期呼叫 SubscriptionUseCase.BillDue 續期到期的訂閱。
+// 每筆訂閱扣款前會先認領，多個實例同時啟用也不會重複扣款。
+func NewBillingScheduler(subscriptions usecase.SubscriptionUseCase, interval time.Duration, batchSize int) *Periodic {
+	return NewPeriodic("billing", subscriptions.BillDue, interval, batchSize)
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeSubscriptions struct {
+	usecase.SubscriptionUseCase
+	batches []int
+	calls   int
+}
+
+func (f *fakeSubscriptions) BillDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	if f.calls >= len(f.batches) {
+		return 0, nil
+	}
+	n := f.batches[f.calls]
+	f.calls++
+	return n, nil
+}
+
+func TestBillingScheduler_RunOnceDrainsFullBatches(t *testing.T) {
+	subs := &fakeSubscriptions{batches: []int{2, 2, 1}}
+	s := NewBillingScheduler(subs, time.Minute, 2)
+
+	n, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+	assert.Equal(t, 3, subs.calls)
+}
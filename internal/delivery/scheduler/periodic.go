@@ -0,0 +1,86 @@
+package scheduler
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// BatchFunc 處理 now 時已到期的一批資料，最多 limit 筆，回傳處理筆數
+type BatchFunc func(ctx context.Context, now time.Time, limit int) (int, error)
+
+// Periodic 每個間隔以批次呼叫 BatchFunc，直到該次到期的資料處理完
+type Periodic struct {
+	name      string
+	batch     BatchFunc
+	interval  time.Duration
+	batchSize int
+	now       func() time.Time
+}
+
+// NewPeriodic 的 name 用於日誌；interval 與 batchSize 為 0 時使用一分鐘與 100 筆
+func NewPeriodic(name string, batch BatchFunc, interval time.Duration, batchSize int) *Periodic {
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	if batchSize <= 0 {
+		batchSize = 100
+	}
+	return &Periodic{
+		name:      name,
+		batch:     batch,
+		interval:  interval,
+		batchSize: batchSize,
+		now:       time.Now,
+	}
+}
+
+// Run 每個間隔執行一次 RunOnce，直到 ctx 取消
+func (p *Periodic) Run(ctx context.Context) {
+	log := logger.FromContext(ctx).With(zap.String("scheduler", p.name))
+	log.Info("scheduler started", zap.Duration("interval", p.interval))
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := p.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Error("scheduler run failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Info("scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 重複呼叫 BatchFunc 直到某一批少於 batchSize，回傳處理筆數。
+// 到期資料依讀到的狀態處理，讀取一律走主庫
+func (p *Periodic) RunOnce(ctx context.Context) (int, error) {
+	ctx = repository.WithPrimaryReads(ctx)
+	now := p.now()
+	total := 0
+	for ctx.Err() == nil {
+		n, err := p.batch(ctx, now, p.batchSize)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if n < p.batchSize {
+			break
+		}
+	}
+	if total > 0 {
+		logger.FromContext(ctx).Info("scheduler run completed",
+			zap.String("scheduler", p.name),
+			zap.Int("processed", total),
+		)
+	}
+	return total, nil
+}
@@ -0,0 +1,79 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeriodic_RunOnceUsesOneTimestamp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var seen []time.Time
+	p := NewPeriodic("test", func(ctx context.Context, now time.Time, limit int) (int, error) {
+		seen = append(seen, now)
+		if len(seen) == 3 {
+			cancel()
+		}
+		return limit, nil
+	}, time.Minute, 2)
+	fixed := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	p.now = func() time.Time { return fixed }
+
+	n, err := p.RunOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 6, n, "stops when ctx is cancelled even if batches stay full")
+	assert.Equal(t, []time.Time{fixed, fixed, fixed}, seen)
+}
+
+func TestPeriodic_RunOnceStopsOnError(t *testing.T) {
+	calls := 0
+	p := NewPeriodic("test", func(ctx context.Context, now time.Time, limit int) (int, error) {
+		calls++
+		if calls > 1 {
+			return 0, errors.New("db down")
+		}
+		return limit, nil
+	}, time.Minute, 2)
+
+	n, err := p.RunOnce(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 2, n)
+}
+
+func TestPeriodic_RunStopsOnCancel(t *testing.T) {
+	p := NewPeriodic("test", func(ctx context.Context, now time.Time, limit int) (int, error) {
+		return 0, nil
+	}, time.Hour, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		p.Run(ctx)
+		close(done)
+	}()
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
+
+func TestPeriodic_RunOnceReadsFromPrimary(t *testing.T) {
+	var primary bool
+	p := NewPeriodic("test", func(ctx context.Context, now time.Time, limit int) (int, error) {
+		primary = repository.ReadFromPrimary(ctx)
+		return 0, nil
+	}, time.Minute, 10)
+
+	_, err := p.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.True(t, primary, "due rows must not be read from a lagging replica")
+}
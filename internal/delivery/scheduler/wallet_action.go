@@ -0,0 +1,13 @@
+package scheduler
+
+import (
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+)
+
+// NewWalletActionExpiry 定期將逾時未在錢包確認的付款標記為 failed。
+// 逾時以條件更新標記，多個實例同時執行也只會處理一次
+func NewWalletActionExpiry(wallets usecase.WalletUseCase, interval time.Duration, batchSize int) *Periodic {
+	return NewPeriodic("wallet_action_expiry", wallets.ExpireDue, interval, batchSize)
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWallets struct {
+	usecase.WalletUseCase
+	batches []int
+	calls   int
+}
+
+func (f *fakeWallets) ExpireDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	if f.calls >= len(f.batches) {
+		return 0, nil
+	}
+	n := f.batches[f.calls]
+	f.calls++
+	return n, nil
+}
+
+func TestWalletActionExpiry_RunOnceStopsAfterPartialBatch(t *testing.T) {
+	wallets := &fakeWallets{batches: []int{2, 1, 2}}
+	s := NewWalletActionExpiry(wallets, time.Minute, 2)
+
+	n, err := s.RunOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, 2, wallets.calls)
+}
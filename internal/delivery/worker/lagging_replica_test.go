@@ -0,0 +1,60 @@
+package worker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository/repositorytest"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/internal/infrastructure/database"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// 副本尚未同步 processing 狀態時，worker 仍需從主庫讀到付款並完成請款
+func TestPool_RunReadsPaymentFromPrimary(t *testing.T) {
+	ctx := context.Background()
+	open := func() *database.Cluster {
+		db, err := database.NewSQLiteConnection(database.Config{Path: ":memory:"})
+		require.NoError(t, err)
+		require.NoError(t, database.Migrate(ctx, db))
+		return database.NewCluster(db)
+	}
+	primary, replica := open(), open()
+	cluster := database.NewCluster(primary.Primary(), replica.Primary())
+	t.Cleanup(func() { cluster.Close() })
+
+	merchant := repositorytest.NewMerchant()
+	customer := repositorytest.NewCustomer()
+	payment := repositorytest.NewPayment(merchant.ID, customer.ID)
+	payment.Status = entity.PaymentStatusPending
+	for _, c := range []*database.Cluster{primary, replica} {
+		require.NoError(t, database.NewMerchantRepository(c).Create(ctx, merchant))
+		require.NoError(t, database.NewCustomerRepository(c).Create(ctx, customer))
+		require.NoError(t, database.NewPaymentRepository(c).Create(ctx, payment))
+	}
+
+	payments := database.NewPaymentRepository(cluster)
+	jobRepo := database.NewJobRepository(cluster)
+	paymentUseCase := usecase.NewPaymentUseCase(payments, database.NewMerchantRepository(cluster), database.NewCustomerRepository(cluster),
+		database.NewCardRepository(cluster), database.NewPaymentMethodRepository(cluster), database.NewInvoiceRepository(cluster), jobRepo,
+		database.NewBankTransferRepository(cluster), usecase.BankTransferConfig{}, database.NewWalletActionRepository(cluster), usecase.WalletConfig{}, nil, nil, nil)
+	_, err := paymentUseCase.SubmitPayment(ctx, payment.ID)
+	require.NoError(t, err)
+
+	stale, err := payments.GetByID(ctx, payment.ID)
+	require.NoError(t, err)
+	require.Equal(t, entity.PaymentStatusPending, stale.Status, "replica lags behind")
+
+	pool := NewPool(usecase.NewJobUseCase(jobRepo, paymentUseCase, usecase.RetryPolicy{}), 1, 5*time.Millisecond)
+	runCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go pool.Run(runCtx)
+
+	assert.Eventually(t, func() bool {
+		got, err := database.NewPaymentRepository(primary).GetByID(ctx, payment.ID)
+		return err == nil && got.Status == entity.PaymentStatusCompleted
+	}, time.Second, 5*time.Millisecond)
+}
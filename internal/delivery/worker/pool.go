@@ -0,0 +1,101 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Pool 輪詢背景工作佇列，最多同時執行 concurrency 筆工作。
+// 工作由資料庫鎖定分派，多個實例可同時執行 Pool
+type Pool struct {
+	jobs         usecase.JobUseCase
+	concurrency  int
+	pollInterval time.Duration
+}
+
+func NewPool(jobs usecase.JobUseCase, concurrency int, pollInterval time.Duration) *Pool {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+	return &Pool{
+		jobs:         jobs,
+		concurrency:  concurrency,
+		pollInterval: pollInterval,
+	}
+}
+
+// Run 持續取得並執行工作，直到 ctx 取消。取消後不再取得新工作，
+// 並等待執行中的工作結束才返回。工作依讀到的狀態決定是否略過，讀取一律走主庫，
+// 避免副本延遲時把還在 processing 的付款當成已處理
+func (p *Pool) Run(ctx context.Context) {
+	ctx = repository.WithPrimaryReads(ctx)
+	log := logger.FromContext(ctx)
+	log.Info("worker pool started",
+		zap.Int("concurrency", p.concurrency),
+		zap.Duration("poll_interval", p.pollInterval),
+	)
+
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, p.concurrency)
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Info("worker pool stopped")
+			return
+		case <-timer.C:
+		}
+
+		free := p.concurrency - len(slots)
+		claimed := 0
+		if free > 0 {
+			jobs, err := p.jobs.ClaimJobs(ctx, free)
+			if err != nil && ctx.Err() == nil {
+				log.Error("failed to claim jobs", zap.Error(err))
+			}
+			for _, job := range jobs {
+				slots <- struct{}{}
+				wg.Add(1)
+				go func(job *entity.Job) {
+					defer func() {
+						<-slots
+						wg.Done()
+					}()
+					p.run(ctx, job)
+				}(job)
+			}
+			claimed = len(jobs)
+		}
+
+		// 取得的數量等於空位時佇列可能還有工作，立即再查詢
+		next := p.pollInterval
+		if claimed > 0 && claimed == free {
+			next = 0
+		}
+		timer.Reset(next)
+	}
+}
+
+// run 以不會隨關閉取消的 context 執行工作，避免請款做到一半中斷；
+// 單次執行的時間由 JobUseCase 的 VisibilityTimeout 限制
+func (p *Pool) run(ctx context.Context, job *entity.Job) {
+	if err := p.jobs.RunJob(context.WithoutCancel(ctx), job); err != nil {
+		logger.FromContext(ctx).Error("failed to record job result",
+			zap.String("job_id", job.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
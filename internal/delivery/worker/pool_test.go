@@ -0,0 +1,104 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeJobs struct {
+	usecase.JobUseCase
+	mu      sync.Mutex
+	pending int
+	limits  []int
+	release chan struct{}
+	running atomic.Int32
+	peak    atomic.Int32
+	done    atomic.Int32
+}
+
+func (f *fakeJobs) ClaimJobs(ctx context.Context, limit int) ([]*entity.Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.limits = append(f.limits, limit)
+	n := limit
+	if n > f.pending {
+		n = f.pending
+	}
+	f.pending -= n
+	jobs := make([]*entity.Job, n)
+	for i := range jobs {
+		jobs[i] = &entity.Job{ID: uuid.New()}
+	}
+	return jobs, nil
+}
+
+func (f *fakeJobs) RunJob(ctx context.Context, job *entity.Job) error {
+	n := f.running.Add(1)
+	for {
+		peak := f.peak.Load()
+		if n <= peak || f.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	<-f.release
+	f.running.Add(-1)
+	f.done.Add(1)
+	return nil
+}
+
+func TestPool_RunRespectsConcurrency(t *testing.T) {
+	jobs := &fakeJobs{pending: 5, release: make(chan struct{})}
+	pool := NewPool(jobs, 2, 5*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go pool.Run(ctx)
+
+	assert.Eventually(t, func() bool { return jobs.running.Load() == 2 }, time.Second, time.Millisecond)
+	for i := 0; i < 5; i++ {
+		jobs.release <- struct{}{}
+	}
+	assert.Eventually(t, func() bool { return jobs.done.Load() == 5 }, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), jobs.peak.Load())
+
+	jobs.mu.Lock()
+	defer jobs.mu.Unlock()
+	for _, limit := range jobs.limits {
+		assert.LessOrEqual(t, limit, 2, "claims only free slots")
+	}
+}
+
+func TestPool_RunWaitsForRunningJobsOnCancel(t *testing.T) {
+	jobs := &fakeJobs{pending: 1, release: make(chan struct{})}
+	pool := NewPool(jobs, 2, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		pool.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return jobs.running.Load() == 1 }, time.Second, time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+		t.Fatal("pool stopped before the running job finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(jobs.release)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("pool did not stop")
+	}
+	assert.Equal(t, int32(1), jobs.done.Load())
+}
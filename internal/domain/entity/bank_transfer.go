@@ -0,0 +1,79 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BankTransferStatus string
+
+const (
+	// BankTransferStatusAwaitingFunds 表示尚未收到任何款項
+	BankTransferStatusAwaitingFunds BankTransferStatus = "awaiting_funds"
+	// BankTransferStatusUnderpaid 表示已收到部分款項，付款仍為 pending 等待補足
+	BankTransferStatusUnderpaid BankTransferStatus = "underpaid"
+	BankTransferStatusPaid      BankTransferStatus = "paid"
+	// BankTransferStatusOverpaid 表示收到的款項超過應付金額，付款已完成，超收部分需退還
+	BankTransferStatusOverpaid BankTransferStatus = "overpaid"
+	// BankTransferStatusExpired 表示逾期未付足，付款已取消，已收款項需退還
+	BankTransferStatusExpired BankTransferStatus = "expired"
+)
+
+// BankTransfer 為銀行轉帳付款的匯款資訊。客戶匯入 VirtualAccount 或在附言填寫
+// Reference，入帳時依此對應到付款
+type BankTransfer struct {
+	PaymentID      uuid.UUID          `json:"payment_id" db:"payment_id"`
+	MerchantID     uuid.UUID          `json:"merchant_id" db:"merchant_id"`
+	Reference      string             `json:"reference" db:"reference"`
+	VirtualAccount string             `json:"virtual_account" db:"virtual_account"`
+	BankName       string             `json:"bank_name" db:"bank_name"`
+	AmountExpected int64              `json:"amount_expected" db:"amount_expected"` // 以分為單位
+	AmountReceived int64              `json:"amount_received" db:"amount_received"`
+	Currency       string             `json:"currency" db:"currency"`
+	Status         BankTransferStatus `json:"status" db:"status"`
+	ExpiresAt      time.Time          `json:"expires_at" db:"expires_at"`
+	CreatedAt      time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// Open 回傳是否仍在等待款項
+func (t *BankTransfer) Open() bool {
+	return t.Status == BankTransferStatusAwaitingFunds || t.Status == BankTransferStatusUnderpaid
+}
+
+// AmountDue 回傳尚未收到的金額
+func (t *BankTransfer) AmountDue() int64 {
+	if t.AmountReceived >= t.AmountExpected {
+		return 0
+	}
+	return t.AmountExpected - t.AmountReceived
+}
+
+type BankCreditStatus string
+
+const (
+	BankCreditStatusMatched BankCreditStatus = "matched"
+	// BankCreditStatusUnmatched 表示無法自動對應到等待中的付款，需人工處理
+	BankCreditStatusUnmatched BankCreditStatus = "unmatched"
+)
+
+// BankCredit 為銀行對帳單上的一筆入帳。TransactionID 為銀行的交易序號，
+// 同一筆入帳重複匯入時只處理一次
+type BankCredit struct {
+	ID             uuid.UUID        `json:"id" db:"id"`
+	TransactionID  string           `json:"transaction_id" db:"transaction_id"`
+	Reference      string           `json:"reference" db:"reference"`
+	VirtualAccount string           `json:"virtual_account" db:"virtual_account"`
+	Amount         int64            `json:"amount" db:"amount"` // 以分為單位
+	Currency       string           `json:"currency" db:"currency"`
+	PayerName      string           `json:"payer_name" db:"payer_name" redact:"name"`
+	ReceivedAt     time.Time        `json:"received_at" db:"received_at"`
+	Status         BankCreditStatus `json:"status" db:"status"`
+	// PaymentID 為入帳對應到的付款；未對應但能辨識付款時也會填寫，方便人工處理
+	PaymentID *uuid.UUID `json:"payment_id,omitempty" db:"payment_id"`
+	// Note 說明未對應的原因或需要退還的金額
+	Note      string    `json:"note,omitempty" db:"note"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,24 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Card 是存放在卡片保險庫中的卡片。卡號以信封加密保存，
+// 對外只以 Token 引用；CVV 只用於建立時的驗證，永不保存。
+type Card struct {
+	Token       string    `json:"token" db:"token"`
+	MerchantID  uuid.UUID `json:"merchant_id" db:"merchant_id"`
+	Brand       string    `json:"brand" db:"brand"`
+	Last4       string    `json:"last4" db:"last4"`
+	ExpMonth    int       `json:"exp_month" db:"exp_month"`
+	ExpYear     int       `json:"exp_year" db:"exp_year"`
+	Fingerprint string    `json:"fingerprint" db:"fingerprint"` // 同一卡號在同一金鑰下的指紋相同，可用於判斷重複卡片
+	// 以資料金鑰（DEK）加密的卡號，DEK 再以 KeyID 指定的主金鑰（KEK）加密
+	EncryptedPAN []byte    `json:"-" db:"encrypted_pan"`
+	EncryptedKey []byte    `json:"-" db:"encrypted_key"`
+	KeyID        string    `json:"-" db:"key_id"`
+	CreatedAt    time.Time `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CheckoutSessionStatus string
+
+const (
+	CheckoutSessionStatusOpen CheckoutSessionStatus = "open"
+	// CheckoutSessionStatusProcessing 表示付款頁已送出、正在建立付款，同一結帳只有一次送出會取得
+	CheckoutSessionStatusProcessing CheckoutSessionStatus = "processing"
+	CheckoutSessionStatusComplete   CheckoutSessionStatus = "complete"
+	CheckoutSessionStatusExpired    CheckoutSessionStatus = "expired"
+	CheckoutSessionStatusCanceled   CheckoutSessionStatus = "canceled"
+)
+
+// CheckoutSession 是代管付款頁的一次結帳。商戶在伺服器端建立後把客戶導向付款頁，
+// 客戶完成付款或取消後再帶著簽章結果導回 SuccessURL 或 CancelURL。
+type CheckoutSession struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MerchantID uuid.UUID `json:"merchant_id" db:"merchant_id"`
+	// CustomerID 為空時由付款頁以客戶輸入的 email 找出或建立客戶
+	CustomerID    *uuid.UUID            `json:"customer_id,omitempty" db:"customer_id"`
+	CustomerEmail string                `json:"customer_email,omitempty" db:"customer_email" redact:"email"`
+	Amount        int64                 `json:"amount" db:"amount"` // 以分為單位
+	Currency      string                `json:"currency" db:"currency"`
+	Description   string                `json:"description" db:"description" redact:"text"`
+	Reference     string                `json:"reference" db:"reference"`
+	Status        CheckoutSessionStatus `json:"status" db:"status"`
+	// AllowedMethods 為付款頁可選擇的付款方式，依建立時的順序顯示
+	AllowedMethods []PaymentMethod `json:"allowed_methods" db:"-"`
+	SuccessURL     string          `json:"success_url" db:"success_url"`
+	CancelURL      string          `json:"cancel_url" db:"cancel_url"`
+	PaymentID      *uuid.UUID      `json:"payment_id,omitempty" db:"payment_id"`
+	// PaymentLinkID 為由付款連結建立的結帳所屬的連結
+	PaymentLinkID *uuid.UUID `json:"payment_link_id,omitempty" db:"payment_link_id"`
+	ExpiresAt     time.Time  `json:"expires_at" db:"expires_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	// URL 為代管付款頁網址，依設定的對外網址組成，不儲存
+	URL string `json:"url,omitempty" db:"-"`
+}
+
+// Allows 回傳付款方式是否可在此結帳使用
+func (s *CheckoutSession) Allows(method PaymentMethod) bool {
+	for _, m := range s.AllowedMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
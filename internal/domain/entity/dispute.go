@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DisputeStatus string
+
+const (
+	// DisputeStatusNeedsResponse 表示等待商戶在 EvidenceDueBy 前提交證據
+	DisputeStatusNeedsResponse DisputeStatus = "needs_response"
+	// DisputeStatusUnderReview 表示證據已提交，等待發卡行裁決
+	DisputeStatusUnderReview DisputeStatus = "under_review"
+	DisputeStatusWon         DisputeStatus = "won"
+	// DisputeStatusLost 表示商戶敗訴，爭議金額已從商戶帳務沖正
+	DisputeStatusLost DisputeStatus = "lost"
+)
+
+type DisputeReason string
+
+const (
+	DisputeReasonFraudulent          DisputeReason = "fraudulent"
+	DisputeReasonProductNotReceived  DisputeReason = "product_not_received"
+	DisputeReasonProductUnacceptable DisputeReason = "product_unacceptable"
+	DisputeReasonDuplicate           DisputeReason = "duplicate"
+	DisputeReasonUnrecognized        DisputeReason = "unrecognized"
+	DisputeReasonGeneral             DisputeReason = "general"
+)
+
+// Dispute 為客戶對已完成付款提出的爭議（chargeback），每筆付款最多一個
+type Dispute struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	PaymentID  uuid.UUID     `json:"payment_id" db:"payment_id"`
+	MerchantID uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	Amount     int64         `json:"amount" db:"amount"` // 爭議金額，不超過付款金額
+	Currency   string        `json:"currency" db:"currency"`
+	Reason     DisputeReason `json:"reason" db:"reason"`
+	Status     DisputeStatus `json:"status" db:"status"`
+	// EvidenceDueBy 為商戶提交證據的期限
+	EvidenceDueBy time.Time  `json:"evidence_due_by" db:"evidence_due_by"`
+	SubmittedAt   *time.Time `json:"submitted_at,omitempty" db:"submitted_at"`
+	ClosedAt      *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	// Evidence 只在取得單筆爭議時載入
+	Evidence []*DisputeEvidence `json:"evidence,omitempty" db:"-"`
+}
+
+// IsClosed 表示爭議已有裁決結果
+func (d *Dispute) IsClosed() bool {
+	return d.Status == DisputeStatusWon || d.Status == DisputeStatusLost
+}
+
+// DisputeEvidence 為商戶提交的一項證據，可以是文字說明、附件或兩者皆有
+type DisputeEvidence struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	DisputeID   uuid.UUID `json:"dispute_id" db:"dispute_id"`
+	Description string    `json:"description,omitempty" db:"description" redact:"text"`
+	FileName    string    `json:"file_name,omitempty" db:"file_name"`
+	ContentType string    `json:"content_type,omitempty" db:"content_type"`
+	Size        int64     `json:"size,omitempty" db:"size"`
+	// StorageKey 為附件在檔案儲存中的位置，沒有附件時為空
+	StorageKey string    `json:"-" db:"storage_key"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// 通知商戶的爭議事件類型
+const (
+	DisputeEventCreated           = "dispute.created"
+	DisputeEventEvidenceSubmitted = "dispute.evidence_submitted"
+	DisputeEventWon               = "dispute.won"
+	DisputeEventLost              = "dispute.lost"
+)
+
+// DisputeEvent 記錄爭議的狀態變化，商戶以事件列表追蹤爭議
+type DisputeEvent struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	Type       string        `json:"type" db:"type"`
+	DisputeID  uuid.UUID     `json:"dispute_id" db:"dispute_id"`
+	MerchantID uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	PaymentID  uuid.UUID     `json:"payment_id" db:"payment_id"`
+	Status     DisputeStatus `json:"status" db:"status"` // 事件發生後的爭議狀態
+	Amount     int64         `json:"amount" db:"amount"`
+	Currency   string        `json:"currency" db:"currency"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
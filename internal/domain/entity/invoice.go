@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type InvoiceStatus string
+
+const (
+	// Draft 可自由修改，定稿後才會編號並開放付款
+	InvoiceStatusDraft InvoiceStatus = "draft"
+	InvoiceStatusOpen  InvoiceStatus = "open"
+	InvoiceStatusPaid  InvoiceStatus = "paid"
+	InvoiceStatusVoid  InvoiceStatus = "void"
+)
+
+// Invoice 為商戶開給客戶的帳單，金額皆以分為單位並由明細計算而來
+type Invoice struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	MerchantID uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	CustomerID uuid.UUID     `json:"customer_id" db:"customer_id"`
+	Number     string        `json:"number,omitempty" db:"number"` // 定稿時產生，草稿為空
+	Currency   string        `json:"currency" db:"currency"`
+	Status     InvoiceStatus `json:"status" db:"status"`
+	Memo       string        `json:"memo,omitempty" db:"memo" redact:"text"`
+	// Subtotal 為折扣前金額，Total = Subtotal - DiscountTotal + TaxTotal
+	Subtotal      int64      `json:"subtotal" db:"subtotal"`
+	DiscountTotal int64      `json:"discount_total" db:"discount_total"`
+	TaxTotal      int64      `json:"tax_total" db:"tax_total"`
+	Total         int64      `json:"total" db:"total"`
+	DueDate       *time.Time `json:"due_date,omitempty" db:"due_date"`
+	// PaymentID 為完成付款、使帳單轉為 paid 的付款
+	PaymentID   *uuid.UUID `json:"payment_id,omitempty" db:"payment_id"`
+	FinalizedAt *time.Time `json:"finalized_at,omitempty" db:"finalized_at"`
+	PaidAt      *time.Time `json:"paid_at,omitempty" db:"paid_at"`
+	VoidedAt    *time.Time `json:"voided_at,omitempty" db:"voided_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+
+	LineItems []InvoiceLineItem `json:"line_items" db:"-"`
+	// TaxLines 依稅目與稅率彙總明細的稅額，不另外儲存
+	TaxLines []InvoiceTaxLine `json:"tax_lines" db:"-"`
+}
+
+// InvoiceLineItem 的 Amount = Quantity × UnitAmount - Discount，稅額以折扣後金額計算
+type InvoiceLineItem struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	InvoiceID   uuid.UUID `json:"-" db:"invoice_id"`
+	Position    int       `json:"-" db:"position"`
+	Description string    `json:"description" db:"description"`
+	Quantity    int       `json:"quantity" db:"quantity"`
+	UnitAmount  int64     `json:"unit_amount" db:"unit_amount"`
+	Discount    int64     `json:"discount" db:"discount"`
+	TaxName     string    `json:"tax_name,omitempty" db:"tax_name"`
+	TaxRate     int       `json:"tax_rate" db:"tax_rate"` // 萬分比，500 代表 5%
+	Amount      int64     `json:"amount" db:"amount"`
+	TaxAmount   int64     `json:"tax_amount" db:"tax_amount"`
+}
+
+type InvoiceTaxLine struct {
+	Name    string `json:"name"`
+	Rate    int    `json:"rate"`
+	Taxable int64  `json:"taxable"`
+	Amount  int64  `json:"amount"`
+}
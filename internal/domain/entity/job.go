@@ -0,0 +1,39 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued"
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusFailed    JobStatus = "failed"
+)
+
+// JobTypeProcessPayment 向網關請款，Payload 為 ProcessPaymentJob
+const JobTypeProcessPayment = "process_payment"
+
+// Job 為背景工作佇列中的一筆工作。worker 取得工作時 Attempts 加一並鎖定到 LockedUntil，
+// 逾時未回報結果的工作會被其他 worker 重新取得
+type Job struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	Type        string     `json:"type" db:"type"`
+	Payload     string     `json:"payload" db:"payload"` // JSON
+	Status      JobStatus  `json:"status" db:"status"`
+	Attempts    int        `json:"attempts" db:"attempts"`
+	RunAt       time.Time  `json:"run_at" db:"run_at"`
+	LockedBy    string     `json:"locked_by,omitempty" db:"locked_by"`
+	LockedUntil *time.Time `json:"locked_until,omitempty" db:"locked_until"`
+	LastError   string     `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type ProcessPaymentJob struct {
+	PaymentID uuid.UUID `json:"payment_id"`
+}
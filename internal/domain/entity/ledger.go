@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type LedgerEntryType string
+
+const (
+	// LedgerEntryDisputeReversal 為爭議敗訴時從商戶扣回的金額
+	LedgerEntryDisputeReversal LedgerEntryType = "dispute_reversal"
+)
+
+// LedgerEntry 為商戶帳務的一筆異動，Amount 為負數表示從商戶扣回
+type LedgerEntry struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	MerchantID  uuid.UUID       `json:"merchant_id" db:"merchant_id"`
+	PaymentID   uuid.UUID       `json:"payment_id" db:"payment_id"`
+	DisputeID   *uuid.UUID      `json:"dispute_id,omitempty" db:"dispute_id"`
+	Type        LedgerEntryType `json:"type" db:"type"`
+	Amount      int64           `json:"amount" db:"amount"`
+	Currency    string          `json:"currency" db:"currency"`
+	Description string          `json:"description" db:"description"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MerchantLimit 為商戶在一種幣別的交易限額，Method 為空時適用所有付款方式。
+// 金額以最小貨幣單位計算，0 表示不限制
+type MerchantLimit struct {
+	ID         uuid.UUID     `json:"id" db:"id"`
+	MerchantID uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	Method     PaymentMethod `json:"method,omitempty" db:"method"`
+	Currency   string        `json:"currency" db:"currency"`
+	// MaxAmount 為單筆付款的上限
+	MaxAmount int64 `json:"max_amount" db:"max_amount"`
+	// DailyAmount 與 DailyCount 為每日（UTC）累計金額與筆數的上限
+	DailyAmount int64     `json:"daily_amount" db:"daily_amount"`
+	DailyCount  int       `json:"daily_count" db:"daily_count"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Applies 回傳限額是否適用於此付款，幣別不分大小寫
+func (l *MerchantLimit) Applies(payment *Payment) bool {
+	return strings.EqualFold(l.Currency, payment.Currency) && (l.Method == "" || l.Method == payment.Method)
+}
+
+// LimitUsage 為限額在某一天累計的付款金額與筆數，Day 格式為 2006-01-02
+type LimitUsage struct {
+	MerchantID uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	Method     PaymentMethod `json:"method,omitempty" db:"method"`
+	Currency   string        `json:"currency" db:"currency"`
+	Day        string        `json:"day" db:"day"`
+	Amount     int64         `json:"amount" db:"amount"`
+	Count      int           `json:"count" db:"count"`
+	UpdatedAt  time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// MerchantLimitStatus 為限額與當日的用量
+type MerchantLimitStatus struct {
+	MerchantLimit
+	Day        string `json:"day"`
+	UsedAmount int64  `json:"used_amount"`
+	UsedCount  int    `json:"used_count"`
+}
@@ -0,0 +1,78 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// AllowedOrigins 為 CORS 允許的來源，格式為 scheme://host[:port]，不分大小寫。
+// host 可用 *. 開頭允許所有子網域（不含網域本身），單獨的 * 允許所有來源。
+// 資料庫中以逗號分隔保存
+type AllowedOrigins []string
+
+// Allows 回傳 origin 是否符合任一來源
+func (o AllowedOrigins) Allows(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	origin = strings.ToLower(origin)
+	for _, pattern := range o {
+		pattern = strings.ToLower(pattern)
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+		scheme, host, ok := strings.Cut(pattern, "://*.")
+		if !ok {
+			continue
+		}
+		prefix := scheme + "://"
+		if strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, "."+host) &&
+			len(origin) > len(prefix)+len(host)+1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 檢查每個來源都只有 scheme、host 與選填的 port
+func (o AllowedOrigins) Validate() error {
+	for _, origin := range o {
+		if origin == "*" {
+			continue
+		}
+		if strings.ContainsAny(origin, ", ") {
+			return fmt.Errorf("invalid origin %q", origin)
+		}
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://wildcard.", 1))
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil || strings.Contains(u.Host, "*") {
+			return fmt.Errorf("invalid origin %q", origin)
+		}
+	}
+	return nil
+}
+
+func (o AllowedOrigins) Value() (driver.Value, error) {
+	return strings.Join(o, ","), nil
+}
+
+func (o *AllowedOrigins) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into AllowedOrigins", src)
+	}
+	if s == "" {
+		*o = nil
+		return nil
+	}
+	*o = strings.Split(s, ",")
+	return nil
+}
@@ -9,7 +9,13 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending   PaymentStatus = "pending"
+	PaymentStatusPending PaymentStatus = "pending"
+	// PaymentStatusProcessing 表示付款已排入背景佇列，等待 worker 向網關請款
+	PaymentStatusProcessing PaymentStatus = "processing"
+	// PaymentStatusRequiresAction 表示等待客戶在錢包完成確認，下一步見 Payment.NextAction
+	PaymentStatusRequiresAction PaymentStatus = "requires_action"
+	// PaymentStatusReview 表示風險評估需要人工審核，商戶核准後轉為 pending，拒絕時轉為 failed
+	PaymentStatusReview    PaymentStatus = "review"
 	PaymentStatusCompleted PaymentStatus = "completed"
 	PaymentStatusFailed    PaymentStatus = "failed"
 	PaymentStatusCancelled PaymentStatus = "cancelled"
@@ -18,41 +24,71 @@ const (
 type PaymentMethod string
 
 const (
-	PaymentMethodCreditCard PaymentMethod = "credit_card"
-	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+	PaymentMethodCreditCard    PaymentMethod = "credit_card"
+	PaymentMethodBankTransfer  PaymentMethod = "bank_transfer"
 	PaymentMethodDigitalWallet PaymentMethod = "digital_wallet"
 )
 
 type Payment struct {
-	ID            uuid.UUID     `json:"id" db:"id"`
-	MerchantID    uuid.UUID     `json:"merchant_id" db:"merchant_id"`
-	CustomerID    uuid.UUID     `json:"customer_id" db:"customer_id"`
-	Amount        int64         `json:"amount" db:"amount"` // 以分為單位避免浮點數精度問題
-	Currency      string        `json:"currency" db:"currency"`
-	Method        PaymentMethod `json:"method" db:"method"`
-	Status        PaymentStatus `json:"status" db:"status"`
-	Description   string        `json:"description" db:"description"`
-	Reference     string        `json:"reference" db:"reference"` // 外部參考號
-	CreatedAt     time.Time     `json:"created_at" db:"created_at"`
-	UpdatedAt     time.Time     `json:"updated_at" db:"updated_at"`
-	CompletedAt   *time.Time    `json:"completed_at,omitempty" db:"completed_at"`
+	ID          uuid.UUID     `json:"id" db:"id"`
+	MerchantID  uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	CustomerID  uuid.UUID     `json:"customer_id" db:"customer_id"`
+	Amount      int64         `json:"amount" db:"amount"` // 以分為單位避免浮點數精度問題
+	Currency    string        `json:"currency" db:"currency"`
+	Method      PaymentMethod `json:"method" db:"method"`
+	Status      PaymentStatus `json:"status" db:"status"`
+	Description string        `json:"description" db:"description" redact:"text"`
+	Reference   string        `json:"reference" db:"reference"` // 外部參考號
+	// PaymentMethodToken 為卡片保險庫的 token，信用卡付款以此引用卡片而不傳遞卡號；
+	// 持有 token 即可再次扣款，不出現在 API 回應中
+	PaymentMethodToken string `json:"-" db:"payment_method_token"`
+	// PaymentMethodID 為付款時引用的客戶已儲存付款方式
+	PaymentMethodID *uuid.UUID `json:"payment_method_id,omitempty" db:"payment_method_id"`
+	// InvoiceID 為此付款支付的帳單，付款完成時帳單轉為 paid
+	InvoiceID *uuid.UUID `json:"invoice_id,omitempty" db:"invoice_id"`
+	// PaymentLinkID 為透過付款連結建立的付款所屬的連結
+	PaymentLinkID *uuid.UUID `json:"payment_link_id,omitempty" db:"payment_link_id"`
+	// RiskScore、RiskDecision 與 RiskReasons 為建立付款時的風險評估結果，未啟用風險評估時為空
+	RiskScore    int          `json:"risk_score" db:"risk_score"`
+	RiskDecision RiskDecision `json:"risk_decision,omitempty" db:"risk_decision"`
+	RiskReasons  RiskReasons  `json:"risk_reasons,omitempty" db:"risk_reasons"`
+	// ClientIP 為建立付款的客戶端 IP，用於計算同一 IP 的付款頻率
+	ClientIP    string     `json:"client_ip,omitempty" db:"client_ip"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty" db:"completed_at"`
+	// BankTransfer 為銀行轉帳付款的匯款資訊，只在取得單筆付款時載入
+	BankTransfer *BankTransfer `json:"bank_transfer,omitempty" db:"-"`
+	// NextAction 為 requires_action 付款需要客戶完成的步驟，只在取得單筆付款時載入
+	NextAction *NextAction `json:"next_action,omitempty" db:"-"`
 }
 
 type Merchant struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Email     string    `json:"email" db:"email"`
-	APIKey    string    `json:"-" db:"api_key"` // 不在JSON中暴露
-	IsActive  bool      `json:"is_active" db:"is_active"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID       uuid.UUID `json:"id" db:"id"`
+	Name     string    `json:"name" db:"name"`
+	Email    string    `json:"email" db:"email"`
+	APIKey   string    `json:"-" db:"api_key"` // 不在JSON中暴露
+	IsActive bool      `json:"is_active" db:"is_active"`
+	// Tier 為商戶等級，決定適用的請求頻率限制
+	Tier string `json:"tier" db:"tier"`
+	// PublishableKey 可公開放在網頁中，只能呼叫 /api/v1/browser 下的路由
+	PublishableKey string `json:"publishable_key,omitempty" db:"publishable_key"`
+	// AllowedOrigins 為可用 PublishableKey 從瀏覽器呼叫的來源
+	AllowedOrigins AllowedOrigins `json:"allowed_origins" db:"allowed_origins"`
+	// SigningSecret 為 HMAC 請求簽章的共用金鑰，空字串表示不接受簽章
+	SigningSecret string    `json:"-" db:"signing_secret"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at" db:"updated_at"`
 }
 
+// MerchantTierStandard 為新商戶的預設等級
+const MerchantTierStandard = "standard"
+
 type Customer struct {
 	ID        uuid.UUID `json:"id" db:"id"`
-	Name      string    `json:"name" db:"name"`
-	Email     string    `json:"email" db:"email"`
-	Phone     string    `json:"phone" db:"phone"`
+	Name      string    `json:"name" db:"name" redact:"name"`
+	Email     string    `json:"email" db:"email" redact:"email"`
+	Phone     string    `json:"phone" db:"phone" redact:"phone"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-}
\ No newline at end of file
+}
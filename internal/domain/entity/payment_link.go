@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentLink 是可分享的付款網址。客戶在公開頁面輸入姓名與 email 後，
+// 以結帳流程完成付款；Amount 為 0 時由客戶自行輸入金額。
+type PaymentLink struct {
+	ID          uuid.UUID `json:"id" db:"id"`
+	MerchantID  uuid.UUID `json:"merchant_id" db:"merchant_id"`
+	Name        string    `json:"name" db:"name"`
+	Description string    `json:"description" db:"description" redact:"text"`
+	Amount      int64     `json:"amount" db:"amount"` // 以分為單位，0 表示由客戶輸入
+	Currency    string    `json:"currency" db:"currency"`
+	// SingleUse 的連結付款一次後即失效，QuantityLimit 固定為 1
+	SingleUse bool `json:"single_use" db:"single_use"`
+	// QuantityLimit 為可完成的付款次數上限，nil 表示不限
+	QuantityLimit *int `json:"quantity_limit,omitempty" db:"quantity_limit"`
+	// PaymentCount 為已完成結帳的次數，用於檢查 QuantityLimit
+	PaymentCount   int             `json:"payment_count" db:"payment_count"`
+	AllowedMethods []PaymentMethod `json:"allowed_methods" db:"-"`
+	// SuccessURL 為空時付款後顯示本服務的完成頁面
+	SuccessURL string     `json:"success_url,omitempty" db:"success_url"`
+	IsActive   bool       `json:"is_active" db:"is_active"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
+	// URL 為公開付款頁網址，依設定的對外網址組成，不儲存
+	URL string `json:"url,omitempty" db:"-"`
+}
+
+// Available 回傳連結目前是否可以付款
+func (l *PaymentLink) Available(now time.Time) bool {
+	if !l.IsActive {
+		return false
+	}
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return false
+	}
+	return l.QuantityLimit == nil || l.PaymentCount < *l.QuantityLimit
+}
+
+// PaymentLinkStats 彙總透過連結建立的付款
+type PaymentLinkStats struct {
+	PaymentLinkID uuid.UUID `json:"payment_link_id" db:"-"`
+	Payments      int       `json:"payments" db:"payments"`
+	Completed     int       `json:"completed" db:"completed"`
+	// AmountCollected 為已完成付款的金額合計，幣別與連結相同
+	AmountCollected int64  `json:"amount_collected" db:"amount_collected"`
+	Currency        string `json:"currency" db:"-"`
+}
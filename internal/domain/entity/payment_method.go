@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BillingDetails 為付款方式的帳單資訊
+type BillingDetails struct {
+	Name       string `json:"name" db:"billing_name" redact:"name"`
+	Email      string `json:"email" db:"billing_email" redact:"email"`
+	Phone      string `json:"phone" db:"billing_phone" redact:"phone"`
+	Line1      string `json:"line1" db:"billing_line1" redact:"text"`
+	Line2      string `json:"line2" db:"billing_line2" redact:"text"`
+	City       string `json:"city" db:"billing_city"`
+	PostalCode string `json:"postal_code" db:"billing_postal_code"`
+	Country    string `json:"country" db:"billing_country"` // ISO 3166-1 alpha-2
+}
+
+// PaymentMethodRecord 是客戶儲存的付款方式，重複消費時以 ID 引用即可付款。
+// Token 對信用卡為卡片保險庫的 token，對其他付款方式為外部帳戶或錢包的參考值。
+type PaymentMethodRecord struct {
+	ID             uuid.UUID     `json:"id" db:"id"`
+	CustomerID     uuid.UUID     `json:"customer_id" db:"customer_id"`
+	MerchantID     uuid.UUID     `json:"merchant_id" db:"merchant_id"`
+	Type           PaymentMethod `json:"type" db:"type"`
+	Token          string        `json:"token" db:"token"`
+	Last4          string        `json:"last4" db:"last4"`
+	Brand          string        `json:"brand" db:"brand"`
+	ExpMonth       int           `json:"exp_month,omitempty" db:"exp_month"`
+	ExpYear        int           `json:"exp_year,omitempty" db:"exp_year"`
+	BillingDetails `json:"billing_details"`
+	IsDefault      bool      `json:"is_default" db:"is_default"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
+}
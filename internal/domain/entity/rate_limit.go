@@ -0,0 +1,73 @@
+package entity
+
+import (
+	"math"
+	"time"
+)
+
+// RateLimit 為 token bucket 設定：每個 Period 補充 Requests 個 token，最多累積 Burst 個。
+// Requests 為 0 表示不限制
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+	// Burst 為 bucket 容量，0 時等於 Requests
+	Burst int
+}
+
+// Capacity 回傳 bucket 最多能累積的 token 數
+func (l RateLimit) Capacity() int {
+	if l.Burst > 0 {
+		return l.Burst
+	}
+	return l.Requests
+}
+
+// interval 為補充一個 token 所需的時間
+func (l RateLimit) interval() time.Duration {
+	return l.Period / time.Duration(l.Requests)
+}
+
+// RateLimitBucket 為 bucket 在 UpdatedAt 時剩餘的 token 數
+type RateLimitBucket struct {
+	Key       string    `db:"bucket_key"`
+	Tokens    float64   `db:"tokens"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// RateLimitResult 為一次取 token 的結果
+type RateLimitResult struct {
+	Limit   RateLimit
+	Allowed bool
+	// Remaining 為本次請求之後剩餘的完整 token 數
+	Remaining int
+	// Reset 為 bucket 補滿所需的時間
+	Reset time.Duration
+	// RetryAfter 為被拒絕時下一個 token 補充前需等待的時間
+	RetryAfter time.Duration
+}
+
+// Take 依經過的時間補充 bucket 後嘗試取出一個 token，回傳更新後的 bucket。
+// bucket 為 nil 時視為全滿；多個實例的時鐘誤差造成時間倒退時不補充
+func (l RateLimit) Take(bucket *RateLimitBucket, key string, now time.Time) (*RateLimitBucket, *RateLimitResult) {
+	capacity := float64(l.Capacity())
+	interval := l.interval()
+
+	tokens := capacity
+	if bucket != nil {
+		tokens = bucket.Tokens
+		if elapsed := now.Sub(bucket.UpdatedAt); elapsed > 0 {
+			tokens = math.Min(capacity, tokens+float64(elapsed)/float64(interval))
+		}
+	}
+
+	result := &RateLimitResult{Limit: l}
+	if tokens >= 1 {
+		tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = time.Duration((1 - tokens) * float64(interval))
+	}
+	result.Remaining = int(math.Floor(tokens))
+	result.Reset = time.Duration((capacity - tokens) * float64(interval))
+	return &RateLimitBucket{Key: key, Tokens: tokens, UpdatedAt: now}, result
+}
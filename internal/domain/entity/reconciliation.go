@@ -0,0 +1,67 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReconciliationStatus string
+
+const (
+	ReconciliationStatusMatched ReconciliationStatus = "matched"
+	// ReconciliationStatusUnmatched 表示差異項目，等待人工處理
+	ReconciliationStatusUnmatched ReconciliationStatus = "unmatched"
+	// ReconciliationStatusResolved 表示差異已由人工處理並記錄原因
+	ReconciliationStatusResolved ReconciliationStatus = "resolved"
+)
+
+// Statement 為一次匯入的銀行對帳單與其對帳結果。Lines 為銀行端的交易，
+// Exceptions 為期間內已完成、但對帳單上找不到的付款
+type Statement struct {
+	ID       uuid.UUID `json:"id" db:"id"`
+	Format   string    `json:"format" db:"format"` // csv 或 camt053
+	BankID   string    `json:"bank_statement_id" db:"bank_statement_id"`
+	Account  string    `json:"account" db:"account"`
+	Currency string    `json:"currency" db:"currency"` // 對帳單混合多種幣別時為空
+	// PeriodStart 與 PeriodEnd 為對帳單涵蓋的期間 [PeriodStart, PeriodEnd)
+	PeriodStart time.Time             `json:"period_start" db:"period_start"`
+	PeriodEnd   time.Time             `json:"period_end" db:"period_end"`
+	CreatedAt   time.Time             `json:"created_at" db:"created_at"`
+	Lines       []*StatementLine      `json:"lines,omitempty" db:"-"`
+	Exceptions  []*StatementException `json:"exceptions,omitempty" db:"-"`
+}
+
+// StatementLine 為對帳單上的一筆交易，入帳為正數、支出為負數
+type StatementLine struct {
+	ID            uuid.UUID            `json:"id" db:"id"`
+	StatementID   uuid.UUID            `json:"statement_id" db:"statement_id"`
+	TransactionID string               `json:"transaction_id" db:"transaction_id"`
+	BookingDate   time.Time            `json:"booking_date" db:"booking_date"`
+	Amount        int64                `json:"amount" db:"amount"` // 以分為單位
+	Currency      string               `json:"currency" db:"currency"`
+	Reference     string               `json:"reference" db:"reference"`
+	Counterparty  string               `json:"counterparty" db:"counterparty" redact:"name"`
+	Status        ReconciliationStatus `json:"status" db:"status"`
+	// PaymentID 為對應到的付款；未對應但能辨識付款時也會填寫，方便人工處理
+	PaymentID  *uuid.UUID `json:"payment_id,omitempty" db:"payment_id"`
+	Note       string     `json:"note,omitempty" db:"note"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+}
+
+// StatementException 為對帳期間內已完成、但對帳單上沒有對應交易的付款。
+// 付款資訊在對帳時複製，報表不受之後的付款異動影響
+type StatementException struct {
+	StatementID uuid.UUID            `json:"statement_id" db:"statement_id"`
+	PaymentID   uuid.UUID            `json:"payment_id" db:"payment_id"`
+	MerchantID  uuid.UUID            `json:"merchant_id" db:"merchant_id"`
+	Amount      int64                `json:"amount" db:"amount"`
+	Currency    string               `json:"currency" db:"currency"`
+	Reference   string               `json:"reference" db:"reference"`
+	CompletedAt time.Time            `json:"completed_at" db:"completed_at"`
+	Status      ReconciliationStatus `json:"status" db:"status"`
+	Note        string               `json:"note,omitempty" db:"note"`
+	ResolvedAt  *time.Time           `json:"resolved_at,omitempty" db:"resolved_at"`
+	CreatedAt   time.Time            `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,60 @@
+package entity
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// RiskDecision 為建立付款時風險評估的結果
+type RiskDecision string
+
+const (
+	RiskDecisionAllow RiskDecision = "allow"
+	// RiskDecisionReview 表示付款轉為 review，等待商戶核准或拒絕
+	RiskDecisionReview RiskDecision = "review"
+	// RiskDecisionBlock 表示付款直接標記為 failed
+	RiskDecisionBlock RiskDecision = "block"
+)
+
+// RiskVelocityKey 為計算付款頻率時使用的欄位
+type RiskVelocityKey string
+
+const (
+	RiskVelocityCustomer RiskVelocityKey = "customer"
+	RiskVelocityEmail    RiskVelocityKey = "email"
+	RiskVelocityIP       RiskVelocityKey = "ip"
+)
+
+// RiskAssessment 為風險引擎對一筆付款的評估，Score 介於 0 到 100
+type RiskAssessment struct {
+	Score    int          `json:"score"`
+	Decision RiskDecision `json:"decision"`
+	Reasons  RiskReasons  `json:"reasons"`
+}
+
+// RiskReasons 為觸發的規則代碼，資料庫中以逗號分隔保存
+type RiskReasons []string
+
+func (r RiskReasons) Value() (driver.Value, error) {
+	return strings.Join(r, ","), nil
+}
+
+func (r *RiskReasons) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case nil:
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("cannot scan %T into RiskReasons", src)
+	}
+	if s == "" {
+		*r = nil
+		return nil
+	}
+	*r = strings.Split(s, ",")
+	return nil
+}
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PlanInterval string
+
+const (
+	PlanIntervalDay   PlanInterval = "day"
+	PlanIntervalWeek  PlanInterval = "week"
+	PlanIntervalMonth PlanInterval = "month"
+	PlanIntervalYear  PlanInterval = "year"
+)
+
+// Plan 是商戶的定期收費方案，每 IntervalCount 個 Interval 收取 Amount × 數量
+type Plan struct {
+	ID            uuid.UUID    `json:"id" db:"id"`
+	MerchantID    uuid.UUID    `json:"merchant_id" db:"merchant_id"`
+	Name          string       `json:"name" db:"name"`
+	Amount        int64        `json:"amount" db:"amount"` // 每單位每期金額，以分為單位
+	Currency      string       `json:"currency" db:"currency"`
+	Interval      PlanInterval `json:"interval" db:"billing_interval"`
+	IntervalCount int          `json:"interval_count" db:"interval_count"`
+	TrialDays     int          `json:"trial_days" db:"trial_days"`
+	IsActive      bool         `json:"is_active" db:"is_active"`
+	CreatedAt     time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionStatusTrialing SubscriptionStatus = "trialing"
+	SubscriptionStatusActive   SubscriptionStatus = "active"
+	// PastDue 表示本期扣款失敗，正在依催收排程重試
+	SubscriptionStatusPastDue SubscriptionStatus = "past_due"
+	// Unpaid 表示催收重試用盡，不再自動扣款
+	SubscriptionStatusUnpaid   SubscriptionStatus = "unpaid"
+	SubscriptionStatusCanceled SubscriptionStatus = "canceled"
+)
+
+// Subscription 是客戶訂閱的方案。每期的起訖以 BillingAnchor 為基準推算，
+// 例如 anchor 為 1/31 的月繳方案會在 2/28（或 2/29）、3/31 續期。
+type Subscription struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	MerchantID uuid.UUID `json:"merchant_id" db:"merchant_id"`
+	CustomerID uuid.UUID `json:"customer_id" db:"customer_id"`
+	PlanID     uuid.UUID `json:"plan_id" db:"plan_id"`
+	// PaymentMethodID 為空時使用客戶的預設付款方式
+	PaymentMethodID    *uuid.UUID         `json:"payment_method_id,omitempty" db:"payment_method_id"`
+	Quantity           int                `json:"quantity" db:"quantity"`
+	Status             SubscriptionStatus `json:"status" db:"status"`
+	BillingAnchor      time.Time          `json:"billing_anchor" db:"billing_anchor"`
+	CurrentPeriodStart time.Time          `json:"current_period_start" db:"current_period_start"`
+	CurrentPeriodEnd   time.Time          `json:"current_period_end" db:"current_period_end"`
+	TrialEnd           *time.Time         `json:"trial_end,omitempty" db:"trial_end"`
+	CancelAtPeriodEnd  bool               `json:"cancel_at_period_end" db:"cancel_at_period_end"`
+	CanceledAt         *time.Time         `json:"canceled_at,omitempty" db:"canceled_at"`
+	// ProrationBalance 為方案變更產生的差額，於下一期扣款時加計，負數代表抵扣
+	ProrationBalance int64      `json:"proration_balance" db:"proration_balance"`
+	FailedAttempts   int        `json:"failed_attempts" db:"failed_attempts"`
+	NextRetryAt      *time.Time `json:"next_retry_at,omitempty" db:"next_retry_at"`
+	LatestPaymentID  *uuid.UUID `json:"latest_payment_id,omitempty" db:"latest_payment_id"`
+	CreatedAt        time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NextActionType string
+
+const (
+	// NextActionRedirectToURL 表示將客戶導向錢包頁面確認付款
+	NextActionRedirectToURL NextActionType = "redirect_to_url"
+	// NextActionDisplayQRCode 表示顯示 QR code 讓客戶以錢包 App 掃描確認
+	NextActionDisplayQRCode NextActionType = "display_qr_code"
+)
+
+type WalletActionStatus string
+
+const (
+	WalletActionStatusPending   WalletActionStatus = "pending"
+	WalletActionStatusConfirmed WalletActionStatus = "confirmed"
+	WalletActionStatusRejected  WalletActionStatus = "rejected"
+	// WalletActionStatusExpired 表示客戶逾時未確認，付款已標記為 failed
+	WalletActionStatusExpired WalletActionStatus = "expired"
+)
+
+// WalletAction 為數位錢包付款等待客戶確認的步驟。錢包以回呼通知確認或拒絕，
+// 逾期未確認時付款轉為 failed
+type WalletAction struct {
+	PaymentID   uuid.UUID          `json:"payment_id" db:"payment_id"`
+	MerchantID  uuid.UUID          `json:"merchant_id" db:"merchant_id"`
+	Type        NextActionType     `json:"type" db:"type"`
+	RedirectURL string             `json:"redirect_url,omitempty" db:"redirect_url"`
+	QRPayload   string             `json:"qr_payload,omitempty" db:"qr_payload"`
+	Status      WalletActionStatus `json:"status" db:"status"`
+	ExpiresAt   time.Time          `json:"expires_at" db:"expires_at"`
+	CreatedAt   time.Time          `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" db:"updated_at"`
+}
+
+// NextAction 為付款回應中客戶需要完成的步驟
+type NextAction struct {
+	Type        NextActionType `json:"type"`
+	RedirectURL string         `json:"redirect_url,omitempty"`
+	QRPayload   string         `json:"qr_payload,omitempty"`
+	ExpiresAt   time.Time      `json:"expires_at"`
+}
+
+// NextAction 回傳仍在等待客戶確認時的下一步，已結束時回傳 nil
+func (a *WalletAction) NextAction() *NextAction {
+	if a.Status != WalletActionStatusPending {
+		return nil
+	}
+	return &NextAction{
+		Type:        a.Type,
+		RedirectURL: a.RedirectURL,
+		QRPayload:   a.QRPayload,
+		ExpiresAt:   a.ExpiresAt,
+	}
+}
@@ -0,0 +1,37 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type WebhookEventStatus string
+
+const (
+	// WebhookEventStatusReceived 表示事件已儲存但尚未處理完成
+	WebhookEventStatusReceived  WebhookEventStatus = "received"
+	WebhookEventStatusProcessed WebhookEventStatus = "processed"
+	// WebhookEventStatusIgnored 表示事件類型不影響付款狀態
+	WebhookEventStatusIgnored WebhookEventStatus = "ignored"
+	// WebhookEventStatusFailed 表示處理失敗，原因記錄在 Error，可重新處理
+	WebhookEventStatusFailed WebhookEventStatus = "failed"
+)
+
+// WebhookEvent 為支付網關送來的一則 webhook。EventID 為網關的事件 ID，
+// 同一網關重送相同事件時只處理一次；Payload 保存原始內容以便重新處理
+type WebhookEvent struct {
+	ID        uuid.UUID          `json:"id" db:"id"`
+	Provider  string             `json:"provider" db:"provider"`
+	EventID   string             `json:"event_id" db:"event_id"`
+	EventType string             `json:"event_type" db:"event_type"`
+	PaymentID *uuid.UUID         `json:"payment_id,omitempty" db:"payment_id"`
+	Status    WebhookEventStatus `json:"status" db:"status"`
+	Payload   string             `json:"payload" db:"payload"`
+	Error     string             `json:"error,omitempty" db:"error"`
+	// Attempts 為處理次數，包含人工重新處理
+	Attempts    int        `json:"attempts" db:"attempts"`
+	ProcessedAt *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+}
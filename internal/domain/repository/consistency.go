@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type consistencyKey struct{}
+
+type consistency struct {
+	forcePrimary bool
+	written      atomic.Bool
+}
+
+// WithReadYourWrites 為單一請求建立寫入追蹤：請求內一旦有寫入，
+// 之後的讀取都會改走主庫，避免讀到尚未同步到副本的舊資料。
+func WithReadYourWrites(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(consistencyKey{}).(*consistency); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, consistencyKey{}, &consistency{})
+}
+
+// WithPrimaryReads 強制此 context 之後的讀取都走主庫
+func WithPrimaryReads(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistencyKey{}, &consistency{forcePrimary: true})
+}
+
+// MarkWritten 由 repository 在寫入成功後呼叫
+func MarkWritten(ctx context.Context) {
+	if c, ok := ctx.Value(consistencyKey{}).(*consistency); ok {
+		c.written.Store(true)
+	}
+}
+
+// ReadFromPrimary 回報讀取是否必須走主庫
+func ReadFromPrimary(ctx context.Context) bool {
+	c, ok := ctx.Value(consistencyKey{}).(*consistency)
+	if !ok {
+		return false
+	}
+	return c.forcePrimary || c.written.Load()
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/google/uuid"
@@ -12,14 +13,21 @@ type PaymentRepository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error)
 	GetByReference(ctx context.Context, reference string) (*entity.Payment, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) error
+	// TransitionStatus 只在付款狀態仍為 from 時改為 to，否則回傳錯誤
+	TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) error
 	GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error)
 	GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*entity.Payment, error)
+	// GetByPaymentLinkID 依 created_at 由新到舊排序
+	GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) ([]*entity.Payment, error)
+	// GetCompletedBetween 回傳 completed_at 在 [from, to) 之間的已完成付款，依完成時間由舊到新排序
+	GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.Payment, error)
 }
 
 type MerchantRepository interface {
 	Create(ctx context.Context, merchant *entity.Merchant) error
 	GetByID(ctx context.Context, id uuid.UUID) (*entity.Merchant, error)
 	GetByAPIKey(ctx context.Context, apiKey string) (*entity.Merchant, error)
+	GetByPublishableKey(ctx context.Context, publishableKey string) (*entity.Merchant, error)
 	Update(ctx context.Context, merchant *entity.Merchant) error
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -30,4 +38,214 @@ type CustomerRepository interface {
 	GetByEmail(ctx context.Context, email string) (*entity.Customer, error)
 	Update(ctx context.Context, customer *entity.Customer) error
 	Delete(ctx context.Context, id uuid.UUID) error
-}
\ No newline at end of file
+}
+
+type CardRepository interface {
+	Create(ctx context.Context, card *entity.Card) error
+	GetByToken(ctx context.Context, token string) (*entity.Card, error)
+}
+
+type PaymentMethodRepository interface {
+	Create(ctx context.Context, method *entity.PaymentMethodRecord) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentMethodRecord, error)
+	// ListByCustomerID 依預設優先、再依 created_at 由新到舊排序
+	ListByCustomerID(ctx context.Context, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error)
+	Update(ctx context.Context, method *entity.PaymentMethodRecord) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	// SetDefault 將指定付款方式設為客戶的預設，並取消同一客戶其他付款方式的預設
+	SetDefault(ctx context.Context, customerID, id uuid.UUID) error
+}
+
+type PlanRepository interface {
+	Create(ctx context.Context, plan *entity.Plan) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Plan, error)
+	// ListByMerchantID 依 created_at 由新到舊排序
+	ListByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error)
+	// Update 只更新名稱與啟用狀態，價格與週期建立後不可變更
+	Update(ctx context.Context, plan *entity.Plan) error
+}
+
+type SubscriptionRepository interface {
+	Create(ctx context.Context, subscription *entity.Subscription) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error)
+	// GetByMerchantID 依 created_at 由新到舊排序
+	GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error)
+	Update(ctx context.Context, subscription *entity.Subscription) error
+	// ListDue 回傳到期需要處理的訂閱：本期已結束且未在催收中，或催收重試時間已到。
+	// 只包含 trialing、active、past_due 且未被鎖定的訂閱，依到期時間由舊到新排序。
+	ListDue(ctx context.Context, now time.Time, limit int) ([]*entity.Subscription, error)
+	// Claim 只在訂閱仍到期且未被鎖定時鎖定到 until，多個實例同時續期時只有一個會成功。
+	// 鎖定逾時後自動失效，處理到一半中斷的訂閱之後會被重新取得
+	Claim(ctx context.Context, id uuid.UUID, now, until time.Time) error
+}
+
+type InvoiceRepository interface {
+	// Create 同時寫入帳單與明細
+	Create(ctx context.Context, invoice *entity.Invoice) error
+	// GetByID 回傳帳單與依順序排列的明細
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Invoice, error)
+	// GetByMerchantID 依 created_at 由新到舊排序，不載入明細
+	GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error)
+	// Update 更新帳單欄位並以 invoice.LineItems 取代原有明細
+	Update(ctx context.Context, invoice *entity.Invoice) error
+}
+
+type CheckoutSessionRepository interface {
+	Create(ctx context.Context, session *entity.CheckoutSession) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.CheckoutSession, error)
+	// Transition 只在結帳狀態仍為 from 時寫入 session 的狀態、客戶、付款與完成時間，
+	// 狀態已變更時回傳錯誤，避免同一結帳被重複完成
+	Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) error
+}
+
+type PaymentLinkRepository interface {
+	Create(ctx context.Context, link *entity.PaymentLink) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentLink, error)
+	// GetByMerchantID 依 created_at 由新到舊排序
+	GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error)
+	// SetActive 啟用或停用連結
+	SetActive(ctx context.Context, id uuid.UUID, active bool) error
+	// ClaimPayment 在連結仍可付款時將 payment_count 加一，連結已停用、過期或
+	// 達到數量上限時回傳錯誤，確保並行結帳不會超過上限
+	ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) error
+	// ReleasePayment 歸還 ClaimPayment 佔用的次數，用於付款建立失敗時
+	ReleasePayment(ctx context.Context, id uuid.UUID) error
+	// GetStats 彙總透過連結建立的付款
+	GetStats(ctx context.Context, id uuid.UUID) (*entity.PaymentLinkStats, error)
+}
+
+type JobRepository interface {
+	Enqueue(ctx context.Context, job *entity.Job) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error)
+	// Claim 取得最多 limit 筆 run_at 已到的 queued 工作，或鎖定已逾時的 running 工作，
+	// 將其標為 running、attempts 加一並鎖定到 now+visibility。並行呼叫不會取得同一筆工作
+	Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]*entity.Job, error)
+	// Complete、Retry 與 Fail 只在工作仍為 running 且 attempts 相同時生效，
+	// 避免鎖定逾時後被重新取得的工作遭舊的 worker 覆寫
+	Complete(ctx context.Context, id uuid.UUID, attempts int) error
+	Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) error
+	Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error
+}
+
+type BankTransferRepository interface {
+	// Create 寫入匯款資訊，Reference 或 VirtualAccount 重複時回傳錯誤
+	Create(ctx context.Context, transfer *entity.BankTransfer) error
+	GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.BankTransfer, error)
+	GetByReference(ctx context.Context, reference string) (*entity.BankTransfer, error)
+	GetByVirtualAccount(ctx context.Context, account string) (*entity.BankTransfer, error)
+	// ApplyCredit 在匯款仍在等待款項且未逾期時累加已收金額，並依與應付金額的比較
+	// 設定為 underpaid、paid 或 overpaid，回傳更新後的匯款資訊。並行入帳不會遺失金額
+	ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (*entity.BankTransfer, error)
+	// ListExpired 回傳 expires_at 已到且仍在等待款項的匯款，依到期時間由舊到新排序
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.BankTransfer, error)
+	// Expire 只在匯款仍在等待款項且已到期時標為 expired，與入帳同時發生時回傳錯誤
+	Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error
+	// Cancel 在付款取消時將仍在等待款項的匯款標為 expired，不論是否已到期，
+	// 之後收到的款項會被標記為需退款
+	Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) error
+}
+
+type WalletActionRepository interface {
+	Create(ctx context.Context, action *entity.WalletAction) error
+	GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.WalletAction, error)
+	// Resolve 只在確認步驟仍為 pending 且未到期時標為 confirmed 或 rejected
+	Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) error
+	// ListExpired 回傳 expires_at 已到且仍為 pending 的確認步驟，依到期時間由舊到新排序
+	ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.WalletAction, error)
+	// Expire 只在確認步驟仍為 pending 且已到期時標為 expired，與回呼同時發生時回傳錯誤
+	Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error
+}
+
+type WebhookEventRepository interface {
+	// Create 寫入收到的事件，同一網關的 EventID 重複時回傳錯誤
+	Create(ctx context.Context, event *entity.WebhookEvent) error
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error)
+	GetByEventID(ctx context.Context, provider, eventID string) (*entity.WebhookEvent, error)
+	// Update 只更新處理結果：狀態、付款、錯誤、處理次數與處理時間
+	Update(ctx context.Context, event *entity.WebhookEvent) error
+	// List 依 created_at 由新到舊排序，provider 與 status 為空時不篩選
+	List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error)
+}
+
+type DisputeRepository interface {
+	// Create 同時寫入爭議與事件，同一筆付款已有爭議時回傳錯誤
+	Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) error
+	// GetByID 回傳爭議與依上傳時間排列的證據
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Dispute, error)
+	// GetByPaymentID 不載入證據
+	GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.Dispute, error)
+	// ListByMerchant 依 created_at 由新到舊排序，status 為空時回傳全部，不載入證據
+	ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error)
+	// AddEvidence 只在爭議仍為 needs_response 時寫入證據
+	AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) error
+	// Transition 只在爭議仍為 from 時寫入新的狀態、提交與結案時間，並在同一個交易內
+	// 寫入事件；reversal 不為 nil 時一併寫入帳務沖正
+	Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) error
+	// ListEvents 依 created_at 由新到舊排序
+	ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error)
+}
+
+type LedgerRepository interface {
+	// ListByMerchant 依 created_at 由新到舊排序
+	ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error)
+}
+
+// RiskRepository 提供風險規則所需的付款統計
+type RiskRepository interface {
+	// CountPaymentsSince 回傳 since 之後建立、key 欄位等於 value 的付款數（不分狀態）；
+	// email 不分大小寫，依付款客戶的 email 比對
+	CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (int, error)
+	// CountCompletedPayments 回傳客戶已完成的付款數
+	CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (int, error)
+}
+
+// MerchantLimitRepository 保存商戶限額與每日用量，用量以商戶、付款方式、幣別與日期累計
+type MerchantLimitRepository interface {
+	ListByMerchant(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimit, error)
+	// Replace 在同一個交易中以 limits 取代商戶現有的限額，不影響已累計的用量
+	Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) error
+	// Reserve 在同一個交易中為每個限額累加 day 的用量並記錄在付款名下，任一限額的每日金額或
+	// 筆數會超過時不寫入任何用量並回傳該限額
+	Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (*entity.MerchantLimit, error)
+	// Release 扣回付款在 Reserve 時累加的用量並刪除記錄，不受之後限額變更影響，重複呼叫不會再扣回
+	Release(ctx context.Context, paymentID uuid.UUID, now time.Time) error
+	// GetUsage 回傳商戶在 day 的所有用量
+	GetUsage(ctx context.Context, merchantID uuid.UUID, day string) ([]*entity.LimitUsage, error)
+}
+
+// RateLimitRepository 保存請求頻率限制的 token bucket。記憶體實作只在單一實例內有效，
+// 多個實例需共用資料庫實作才能合併計算
+type RateLimitRepository interface {
+	// Take 鎖定 key 的 bucket，依 limit 補充後取出一個 token；bucket 不存在時視為全滿
+	Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (*entity.RateLimitResult, error)
+}
+
+// NonceRepository 記錄簽章請求使用過的 nonce 以防止重送。記憶體實作只在單一實例內有效，
+// 多個實例需共用資料庫實作
+type NonceRepository interface {
+	// Use 記錄 key 直到 expiresAt 並回傳 true；key 已記錄且在 now 尚未到期時回傳 false
+	Use(ctx context.Context, key string, expiresAt, now time.Time) (bool, error)
+}
+
+type BankCreditRepository interface {
+	// Create 寫入入帳，TransactionID 重複時回傳錯誤
+	Create(ctx context.Context, credit *entity.BankCredit) error
+	GetByTransactionID(ctx context.Context, transactionID string) (*entity.BankCredit, error)
+	// Update 只更新對應狀態、付款與備註
+	Update(ctx context.Context, credit *entity.BankCredit) error
+	// List 依 received_at 由新到舊排序，status 為空時回傳全部
+	List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error)
+}
+
+type StatementRepository interface {
+	// Create 同時寫入對帳單、交易與差異付款
+	Create(ctx context.Context, statement *entity.Statement) error
+	// GetByID 回傳對帳單、依入帳日期排列的交易與依完成時間排列的差異付款
+	GetByID(ctx context.Context, id uuid.UUID) (*entity.Statement, error)
+	// List 依 created_at 由新到舊排序，不載入交易與差異付款
+	List(ctx context.Context, limit, offset int) ([]*entity.Statement, error)
+	// ResolveLine 只在交易仍為 unmatched 時標為 resolved，paymentID 為人工對應的付款，可為 nil
+	ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) error
+	// ResolveException 只在差異付款仍為 unmatched 時標為 resolved
+	ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) error
+}
@@ -0,0 +1,2755 @@
+// Package repositorytest 提供 repository 介面的共用一致性測試，
+// 讓各個儲存實作（PostgreSQL、記憶體等）以相同的語意驗證。
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type Repositories struct {
+	Payments       repository.PaymentRepository
+	Merchants      repository.MerchantRepository
+	Customers      repository.CustomerRepository
+	Cards          repository.CardRepository
+	PaymentMethods repository.PaymentMethodRepository
+	Plans          repository.PlanRepository
+	Subscriptions  repository.SubscriptionRepository
+	Invoices       repository.InvoiceRepository
+	Checkouts      repository.CheckoutSessionRepository
+	PaymentLinks   repository.PaymentLinkRepository
+	Jobs           repository.JobRepository
+	BankTransfers  repository.BankTransferRepository
+	BankCredits    repository.BankCreditRepository
+	Statements     repository.StatementRepository
+	WalletActions  repository.WalletActionRepository
+	WebhookEvents  repository.WebhookEventRepository
+	Disputes       repository.DisputeRepository
+	Ledger         repository.LedgerRepository
+	Risk           repository.RiskRepository
+	MerchantLimits repository.MerchantLimitRepository
+	RateLimits     repository.RateLimitRepository
+	Nonces         repository.NonceRepository
+}
+
+// Run 執行完整的一致性測試。setup 會在每個子測試開始時呼叫，
+// 測試資料皆使用隨機 ID 與 email，可在共用資料庫上重複執行。
+func Run(t *testing.T, setup func(t *testing.T) Repositories) {
+	t.Run("Merchant", func(t *testing.T) { runMerchantTests(t, setup) })
+	t.Run("Customer", func(t *testing.T) { runCustomerTests(t, setup) })
+	t.Run("Payment", func(t *testing.T) { runPaymentTests(t, setup) })
+	t.Run("Card", func(t *testing.T) { runCardTests(t, setup) })
+	t.Run("PaymentMethod", func(t *testing.T) { runPaymentMethodTests(t, setup) })
+	t.Run("Plan", func(t *testing.T) { runPlanTests(t, setup) })
+	t.Run("Subscription", func(t *testing.T) { runSubscriptionTests(t, setup) })
+	t.Run("Invoice", func(t *testing.T) { runInvoiceTests(t, setup) })
+	t.Run("CheckoutSession", func(t *testing.T) { runCheckoutSessionTests(t, setup) })
+	t.Run("PaymentLink", func(t *testing.T) { runPaymentLinkTests(t, setup) })
+	t.Run("Job", func(t *testing.T) { runJobTests(t, setup) })
+	t.Run("BankTransfer", func(t *testing.T) { runBankTransferTests(t, setup) })
+	t.Run("BankCredit", func(t *testing.T) { runBankCreditTests(t, setup) })
+	t.Run("Statement", func(t *testing.T) { runStatementTests(t, setup) })
+	t.Run("WalletAction", func(t *testing.T) { runWalletActionTests(t, setup) })
+	t.Run("WebhookEvent", func(t *testing.T) { runWebhookEventTests(t, setup) })
+	t.Run("Dispute", func(t *testing.T) { runDisputeTests(t, setup) })
+	t.Run("Risk", func(t *testing.T) { runRiskTests(t, setup) })
+	t.Run("MerchantLimit", func(t *testing.T) { runMerchantLimitTests(t, setup) })
+	t.Run("RateLimit", func(t *testing.T) { runRateLimitTests(t, setup) })
+	t.Run("Nonce", func(t *testing.T) { runNonceTests(t, setup) })
+}
+
+func runMerchantTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		got, err := repos.Merchants.GetByID(ctx, merchant.ID)
+		require.NoError(t, err)
+		assertMerchantEqual(t, merchant, got)
+
+		got, err = repos.Merchants.GetByAPIKey(ctx, merchant.APIKey)
+		require.NoError(t, err)
+		assertMerchantEqual(t, merchant, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.Merchants.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "merchant not found")
+		assert.Nil(t, got)
+
+		got, err = repos.Merchants.GetByAPIKey(ctx, "missing_"+uuid.NewString())
+		assert.Error(t, err)
+		assert.Nil(t, got)
+
+		assert.Error(t, repos.Merchants.Update(ctx, NewMerchant()))
+		assert.Error(t, repos.Merchants.Delete(ctx, uuid.New()))
+	})
+
+	t.Run("unique email and api key", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		sameEmail := NewMerchant()
+		sameEmail.Email = merchant.Email
+		assert.Error(t, repos.Merchants.Create(ctx, sameEmail))
+
+		sameKey := NewMerchant()
+		sameKey.APIKey = merchant.APIKey
+		assert.Error(t, repos.Merchants.Create(ctx, sameKey))
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		merchant.Name = "Renamed Merchant"
+		merchant.IsActive = false
+		merchant.Tier = "premium"
+		require.NoError(t, repos.Merchants.Update(ctx, merchant))
+
+		got, err := repos.Merchants.GetByID(ctx, merchant.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed Merchant", got.Name)
+		assert.False(t, got.IsActive)
+		assert.Equal(t, "premium", got.Tier)
+	})
+
+	t.Run("publishable key and origins", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		got, err := repos.Merchants.GetByPublishableKey(ctx, merchant.PublishableKey)
+		require.NoError(t, err)
+		assertMerchantEqual(t, merchant, got)
+
+		merchant.AllowedOrigins = entity.AllowedOrigins{"https://shop.example.com", "https://*.example.org"}
+		merchant.PublishableKey = "pk_rotated_" + merchant.ID.String()
+		merchant.SigningSecret = ""
+		require.NoError(t, repos.Merchants.Update(ctx, merchant))
+		got, err = repos.Merchants.GetByPublishableKey(ctx, merchant.PublishableKey)
+		require.NoError(t, err)
+		assert.Equal(t, merchant.AllowedOrigins, got.AllowedOrigins)
+		assert.Empty(t, got.SigningSecret)
+
+		_, err = repos.Merchants.GetByPublishableKey(ctx, "pk_missing_"+uuid.NewString())
+		assert.Error(t, err)
+
+		sameKey := NewMerchant()
+		sameKey.PublishableKey = merchant.PublishableKey
+		assert.Error(t, repos.Merchants.Create(ctx, sameKey))
+
+		// 未發行 publishable key 的商戶可以有多個
+		for i := 0; i < 2; i++ {
+			withoutKey := NewMerchant()
+			withoutKey.PublishableKey = ""
+			require.NoError(t, repos.Merchants.Create(ctx, withoutKey))
+			got, err := repos.Merchants.GetByID(ctx, withoutKey.ID)
+			require.NoError(t, err)
+			assert.Empty(t, got.PublishableKey)
+			assert.Empty(t, got.AllowedOrigins)
+		}
+		_, err = repos.Merchants.GetByPublishableKey(ctx, "")
+		assert.Error(t, err)
+	})
+
+	t.Run("default tier", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		merchant.Tier = ""
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		got, err := repos.Merchants.GetByID(ctx, merchant.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.MerchantTierStandard, got.Tier)
+	})
+
+	t.Run("delete is soft", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		require.NoError(t, repos.Merchants.Delete(ctx, merchant.ID))
+
+		got, err := repos.Merchants.GetByID(ctx, merchant.ID)
+		require.NoError(t, err)
+		assert.False(t, got.IsActive)
+	})
+}
+
+func runCustomerTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		got, err := repos.Customers.GetByID(ctx, customer.ID)
+		require.NoError(t, err)
+		assertCustomerEqual(t, customer, got)
+
+		got, err = repos.Customers.GetByEmail(ctx, customer.Email)
+		require.NoError(t, err)
+		assertCustomerEqual(t, customer, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.Customers.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "customer not found")
+		assert.Nil(t, got)
+
+		got, err = repos.Customers.GetByEmail(ctx, uuid.NewString()+"@example.com")
+		assert.Error(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("unique email", func(t *testing.T) {
+		repos := setup(t)
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		duplicate := NewCustomer()
+		duplicate.Email = customer.Email
+		assert.Error(t, repos.Customers.Create(ctx, duplicate))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repos := setup(t)
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		customer.Name = "Renamed Customer"
+		customer.Phone = "+886900000000"
+		customer.UpdatedAt = time.Now()
+		require.NoError(t, repos.Customers.Update(ctx, customer))
+
+		got, err := repos.Customers.GetByID(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed Customer", got.Name)
+		assert.Equal(t, "+886900000000", got.Phone)
+
+		require.NoError(t, repos.Customers.Delete(ctx, customer.ID))
+		_, err = repos.Customers.GetByID(ctx, customer.ID)
+		assert.Error(t, err)
+	})
+}
+
+func runPaymentTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// fixtures 建立付款所需的商戶與客戶
+	fixtures := func(t *testing.T, repos Repositories) (*entity.Merchant, *entity.Customer) {
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		return merchant, customer
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		got, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assertPaymentEqual(t, payment, got)
+
+		got, err = repos.Payments.GetByReference(ctx, payment.Reference)
+		require.NoError(t, err)
+		assertPaymentEqual(t, payment, got)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.Payments.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "payment not found")
+		assert.Nil(t, got)
+
+		got, err = repos.Payments.GetByReference(ctx, "missing_"+uuid.NewString())
+		assert.Error(t, err)
+		assert.Nil(t, got)
+
+		assert.Error(t, repos.Payments.UpdateStatus(ctx, uuid.New(), entity.PaymentStatusCompleted))
+	})
+
+	t.Run("unique reference", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		duplicate := NewPayment(merchant.ID, customer.ID)
+		duplicate.Reference = payment.Reference
+		assert.Error(t, repos.Payments.Create(ctx, duplicate))
+
+		// reference 為選填，多筆未指定 reference 的付款不違反唯一鍵
+		for i := 0; i < 2; i++ {
+			withoutReference := NewPayment(merchant.ID, customer.ID)
+			withoutReference.Reference = ""
+			require.NoError(t, repos.Payments.Create(ctx, withoutReference))
+			got, err := repos.Payments.GetByID(ctx, withoutReference.ID)
+			require.NoError(t, err)
+			assert.Empty(t, got.Reference)
+		}
+	})
+
+	t.Run("requires existing merchant and customer", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+
+		assert.Error(t, repos.Payments.Create(ctx, NewPayment(uuid.New(), customer.ID)))
+		assert.Error(t, repos.Payments.Create(ctx, NewPayment(merchant.ID, uuid.New())))
+	})
+
+	t.Run("update status", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		require.NoError(t, repos.Payments.UpdateStatus(ctx, payment.ID, entity.PaymentStatusCompleted))
+		got, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted, got.Status)
+		assert.NotNil(t, got.CompletedAt)
+
+		require.NoError(t, repos.Payments.UpdateStatus(ctx, payment.ID, entity.PaymentStatusCancelled))
+		got, err = repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCancelled, got.Status)
+		assert.Nil(t, got.CompletedAt)
+	})
+
+	t.Run("transition status", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		require.NoError(t, repos.Payments.TransitionStatus(ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusProcessing))
+		err := repos.Payments.TransitionStatus(ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusProcessing)
+		assert.Error(t, err, "status is no longer pending")
+		assert.Contains(t, err.Error(), "status changed")
+
+		require.NoError(t, repos.Payments.TransitionStatus(ctx, payment.ID, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted))
+		got, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted, got.Status)
+		assert.NotNil(t, got.CompletedAt)
+
+		assert.Error(t, repos.Payments.TransitionStatus(ctx, uuid.New(), entity.PaymentStatusPending, entity.PaymentStatusProcessing))
+	})
+
+	t.Run("list ordering and pagination", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		otherMerchant, otherCustomer := fixtures(t, repos)
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		var created []*entity.Payment
+		for i := 0; i < 5; i++ {
+			payment := NewPayment(merchant.ID, customer.ID)
+			payment.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+			payment.UpdatedAt = payment.CreatedAt
+			require.NoError(t, repos.Payments.Create(ctx, payment))
+			created = append(created, payment)
+		}
+		require.NoError(t, repos.Payments.Create(ctx, NewPayment(otherMerchant.ID, otherCustomer.ID)))
+
+		byMerchant, err := repos.Payments.GetByMerchantID(ctx, merchant.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, byMerchant, 5)
+		for i, payment := range byMerchant {
+			assert.Equal(t, created[4-i].ID, payment.ID, "payments must be ordered by created_at desc")
+		}
+
+		page, err := repos.Payments.GetByMerchantID(ctx, merchant.ID, 2, 1)
+		require.NoError(t, err)
+		require.Len(t, page, 2)
+		assert.Equal(t, created[3].ID, page[0].ID)
+		assert.Equal(t, created[2].ID, page[1].ID)
+
+		byCustomer, err := repos.Payments.GetByCustomerID(ctx, customer.ID, 3, 0)
+		require.NoError(t, err)
+		require.Len(t, byCustomer, 3)
+		assert.Equal(t, created[4].ID, byCustomer[0].ID)
+
+		empty, err := repos.Payments.GetByMerchantID(ctx, uuid.New(), 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, empty)
+	})
+
+	t.Run("get completed between", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		var payments []*entity.Payment
+		for i := 0; i < 3; i++ {
+			payment := NewPayment(merchant.ID, customer.ID)
+			require.NoError(t, repos.Payments.Create(ctx, payment))
+			payments = append(payments, payment)
+		}
+
+		from := time.Now().Add(-time.Second)
+		require.NoError(t, repos.Payments.TransitionStatus(ctx, payments[2].ID, entity.PaymentStatusPending, entity.PaymentStatusCompleted))
+		time.Sleep(2 * time.Millisecond)
+		require.NoError(t, repos.Payments.TransitionStatus(ctx, payments[0].ID, entity.PaymentStatusPending, entity.PaymentStatusCompleted))
+		require.NoError(t, repos.Payments.UpdateStatus(ctx, payments[1].ID, entity.PaymentStatusFailed))
+		to := time.Now().Add(time.Second)
+
+		// 共用資料庫上可能有其他付款，只檢查本測試建立的付款
+		completed, err := repos.Payments.GetCompletedBetween(ctx, from, to, 1000, 0)
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, payment := range completed {
+			if payment.MerchantID == merchant.ID {
+				ids = append(ids, payment.ID)
+				assert.NotNil(t, payment.CompletedAt)
+			}
+		}
+		assert.Equal(t, []uuid.UUID{payments[2].ID, payments[0].ID}, ids, "ordered by completed_at")
+
+		completed, err = repos.Payments.GetCompletedBetween(ctx, to, to.Add(time.Hour), 1000, 0)
+		require.NoError(t, err)
+		for _, payment := range completed {
+			assert.NotEqual(t, merchant.ID, payment.MerchantID, "to is exclusive")
+		}
+	})
+}
+
+func runCardTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		card := NewCard(merchant.ID)
+		require.NoError(t, repos.Cards.Create(ctx, card))
+
+		got, err := repos.Cards.GetByToken(ctx, card.Token)
+		require.NoError(t, err)
+		assert.Equal(t, card.Token, got.Token)
+		assert.Equal(t, card.MerchantID, got.MerchantID)
+		assert.Equal(t, card.Brand, got.Brand)
+		assert.Equal(t, card.Last4, got.Last4)
+		assert.Equal(t, card.ExpMonth, got.ExpMonth)
+		assert.Equal(t, card.ExpYear, got.ExpYear)
+		assert.Equal(t, card.Fingerprint, got.Fingerprint)
+		assert.Equal(t, card.EncryptedPAN, got.EncryptedPAN)
+		assert.Equal(t, card.EncryptedKey, got.EncryptedKey)
+		assert.Equal(t, card.KeyID, got.KeyID)
+		assert.WithinDuration(t, card.CreatedAt, got.CreatedAt, time.Millisecond)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.Cards.GetByToken(ctx, "tok_missing_"+uuid.NewString())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "card not found")
+		assert.Nil(t, got)
+	})
+
+	t.Run("unique token and existing merchant", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		card := NewCard(merchant.ID)
+		require.NoError(t, repos.Cards.Create(ctx, card))
+
+		duplicate := NewCard(merchant.ID)
+		duplicate.Token = card.Token
+		assert.Error(t, repos.Cards.Create(ctx, duplicate))
+		assert.Error(t, repos.Cards.Create(ctx, NewCard(uuid.New())))
+	})
+
+	t.Run("payment keeps token", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		card := NewCard(merchant.ID)
+		require.NoError(t, repos.Cards.Create(ctx, card))
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.PaymentMethodToken = card.Token
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		got, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.Equal(t, card.Token, got.PaymentMethodToken)
+	})
+}
+
+func runPaymentMethodTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	fixtures := func(t *testing.T, repos Repositories) (*entity.Merchant, *entity.Customer) {
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		return merchant, customer
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		method := NewPaymentMethod(merchant.ID, customer.ID)
+		require.NoError(t, repos.PaymentMethods.Create(ctx, method))
+
+		got, err := repos.PaymentMethods.GetByID(ctx, method.ID)
+		require.NoError(t, err)
+		assert.Equal(t, method.ID, got.ID)
+		assert.Equal(t, method.CustomerID, got.CustomerID)
+		assert.Equal(t, method.MerchantID, got.MerchantID)
+		assert.Equal(t, method.Type, got.Type)
+		assert.Equal(t, method.Token, got.Token)
+		assert.Equal(t, method.Last4, got.Last4)
+		assert.Equal(t, method.Brand, got.Brand)
+		assert.Equal(t, method.ExpMonth, got.ExpMonth)
+		assert.Equal(t, method.ExpYear, got.ExpYear)
+		assert.Equal(t, method.BillingDetails, got.BillingDetails)
+		assert.Equal(t, method.IsDefault, got.IsDefault)
+		assert.WithinDuration(t, method.CreatedAt, got.CreatedAt, time.Millisecond)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.PaymentMethods.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "payment method not found")
+		assert.Nil(t, got)
+
+		missing := NewPaymentMethod(uuid.New(), uuid.New())
+		assert.Error(t, repos.PaymentMethods.Update(ctx, missing))
+		assert.Error(t, repos.PaymentMethods.Delete(ctx, missing.ID))
+		assert.Error(t, repos.PaymentMethods.SetDefault(ctx, missing.CustomerID, missing.ID))
+	})
+
+	t.Run("requires existing customer", func(t *testing.T) {
+		repos := setup(t)
+		merchant, _ := fixtures(t, repos)
+		assert.Error(t, repos.PaymentMethods.Create(ctx, NewPaymentMethod(merchant.ID, uuid.New())))
+	})
+
+	t.Run("single default and ordering", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		var created []*entity.PaymentMethodRecord
+		for i := 0; i < 3; i++ {
+			method := NewPaymentMethod(merchant.ID, customer.ID)
+			method.IsDefault = i == 0
+			method.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, repos.PaymentMethods.Create(ctx, method))
+			created = append(created, method)
+		}
+
+		second := NewPaymentMethod(merchant.ID, customer.ID)
+		second.IsDefault = true
+		assert.Error(t, repos.PaymentMethods.Create(ctx, second), "only one default per customer")
+
+		list, err := repos.PaymentMethods.ListByCustomerID(ctx, customer.ID)
+		require.NoError(t, err)
+		require.Len(t, list, 3)
+		assert.Equal(t, created[0].ID, list[0].ID, "default first")
+		assert.Equal(t, created[2].ID, list[1].ID)
+		assert.Equal(t, created[1].ID, list[2].ID)
+
+		require.NoError(t, repos.PaymentMethods.SetDefault(ctx, customer.ID, created[1].ID))
+		list, err = repos.PaymentMethods.ListByCustomerID(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, created[1].ID, list[0].ID)
+		assert.True(t, list[0].IsDefault)
+		assert.False(t, list[1].IsDefault)
+		assert.False(t, list[2].IsDefault)
+
+		_, other := fixtures(t, repos)
+		assert.Error(t, repos.PaymentMethods.SetDefault(ctx, other.ID, created[2].ID), "method must belong to customer")
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		method := NewPaymentMethod(merchant.ID, customer.ID)
+		require.NoError(t, repos.PaymentMethods.Create(ctx, method))
+
+		method.ExpYear = 2035
+		method.BillingDetails.City = "Taipei"
+		method.UpdatedAt = time.Now()
+		require.NoError(t, repos.PaymentMethods.Update(ctx, method))
+
+		got, err := repos.PaymentMethods.GetByID(ctx, method.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2035, got.ExpYear)
+		assert.Equal(t, "Taipei", got.BillingDetails.City)
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.PaymentMethodID = &method.ID
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+		gotPayment, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		require.NotNil(t, gotPayment.PaymentMethodID)
+		assert.Equal(t, method.ID, *gotPayment.PaymentMethodID)
+
+		require.NoError(t, repos.PaymentMethods.Delete(ctx, method.ID))
+		_, err = repos.PaymentMethods.GetByID(ctx, method.ID)
+		assert.Error(t, err)
+
+		gotPayment, err = repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		assert.Nil(t, gotPayment.PaymentMethodID, "payments keep history after the method is removed")
+	})
+}
+
+func runPlanTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create get and update", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		plan := NewPlan(merchant.ID)
+		require.NoError(t, repos.Plans.Create(ctx, plan))
+
+		got, err := repos.Plans.GetByID(ctx, plan.ID)
+		require.NoError(t, err)
+		assert.Equal(t, plan.MerchantID, got.MerchantID)
+		assert.Equal(t, plan.Name, got.Name)
+		assert.Equal(t, plan.Amount, got.Amount)
+		assert.Equal(t, plan.Currency, got.Currency)
+		assert.Equal(t, plan.Interval, got.Interval)
+		assert.Equal(t, plan.IntervalCount, got.IntervalCount)
+		assert.Equal(t, plan.TrialDays, got.TrialDays)
+		assert.True(t, got.IsActive)
+
+		plan.Name = "Renamed"
+		plan.IsActive = false
+		plan.Amount = 1
+		plan.UpdatedAt = time.Now()
+		require.NoError(t, repos.Plans.Update(ctx, plan))
+		got, err = repos.Plans.GetByID(ctx, plan.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Renamed", got.Name)
+		assert.False(t, got.IsActive)
+		assert.Equal(t, int64(2900), got.Amount, "price is immutable")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repos := setup(t)
+
+		got, err := repos.Plans.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "plan not found")
+		assert.Nil(t, got)
+		assert.Error(t, repos.Plans.Update(ctx, NewPlan(uuid.New())))
+		assert.Error(t, repos.Plans.Create(ctx, NewPlan(uuid.New())), "merchant must exist")
+	})
+
+	t.Run("list by merchant", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		other := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, other))
+
+		base := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+		var created []*entity.Plan
+		for i := 0; i < 2; i++ {
+			plan := NewPlan(merchant.ID)
+			plan.CreatedAt = base.Add(time.Duration(i) * time.Minute)
+			require.NoError(t, repos.Plans.Create(ctx, plan))
+			created = append(created, plan)
+		}
+		require.NoError(t, repos.Plans.Create(ctx, NewPlan(other.ID)))
+
+		plans, err := repos.Plans.ListByMerchantID(ctx, merchant.ID)
+		require.NoError(t, err)
+		require.Len(t, plans, 2)
+		assert.Equal(t, created[1].ID, plans[0].ID)
+		assert.Equal(t, created[0].ID, plans[1].ID)
+	})
+}
+
+func runSubscriptionTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	fixtures := func(t *testing.T, repos Repositories) (*entity.Merchant, *entity.Customer, *entity.Plan) {
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		plan := NewPlan(merchant.ID)
+		require.NoError(t, repos.Plans.Create(ctx, plan))
+		return merchant, customer, plan
+	}
+
+	t.Run("create get and update", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer, plan := fixtures(t, repos)
+		sub := NewSubscription(merchant.ID, customer.ID, plan.ID)
+		require.NoError(t, repos.Subscriptions.Create(ctx, sub))
+
+		got, err := repos.Subscriptions.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, sub.MerchantID, got.MerchantID)
+		assert.Equal(t, sub.CustomerID, got.CustomerID)
+		assert.Equal(t, sub.PlanID, got.PlanID)
+		assert.Equal(t, sub.Quantity, got.Quantity)
+		assert.Equal(t, sub.Status, got.Status)
+		assert.WithinDuration(t, sub.BillingAnchor, got.BillingAnchor, time.Millisecond)
+		assert.WithinDuration(t, sub.CurrentPeriodStart, got.CurrentPeriodStart, time.Millisecond)
+		assert.WithinDuration(t, sub.CurrentPeriodEnd, got.CurrentPeriodEnd, time.Millisecond)
+		assert.Nil(t, got.TrialEnd)
+		assert.Nil(t, got.NextRetryAt)
+		assert.Nil(t, got.PaymentMethodID)
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+		method := NewPaymentMethod(merchant.ID, customer.ID)
+		require.NoError(t, repos.PaymentMethods.Create(ctx, method))
+
+		retryAt := time.Now().Add(24 * time.Hour).Truncate(time.Millisecond)
+		sub.Status = entity.SubscriptionStatusPastDue
+		sub.Quantity = 3
+		sub.CancelAtPeriodEnd = true
+		sub.ProrationBalance = -150
+		sub.FailedAttempts = 2
+		sub.NextRetryAt = &retryAt
+		sub.LatestPaymentID = &payment.ID
+		sub.PaymentMethodID = &method.ID
+		sub.UpdatedAt = time.Now()
+		require.NoError(t, repos.Subscriptions.Update(ctx, sub))
+
+		got, err = repos.Subscriptions.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusPastDue, got.Status)
+		assert.Equal(t, 3, got.Quantity)
+		assert.True(t, got.CancelAtPeriodEnd)
+		assert.Equal(t, int64(-150), got.ProrationBalance)
+		assert.Equal(t, 2, got.FailedAttempts)
+		require.NotNil(t, got.NextRetryAt)
+		assert.WithinDuration(t, retryAt, *got.NextRetryAt, time.Millisecond)
+		require.NotNil(t, got.LatestPaymentID)
+		assert.Equal(t, payment.ID, *got.LatestPaymentID)
+
+		require.NoError(t, repos.PaymentMethods.Delete(ctx, method.ID))
+		got, err = repos.Subscriptions.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Nil(t, got.PaymentMethodID, "deleting the method falls back to the customer default")
+	})
+
+	t.Run("not found and references", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer, _ := fixtures(t, repos)
+
+		got, err := repos.Subscriptions.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "subscription not found")
+		assert.Nil(t, got)
+		assert.Error(t, repos.Subscriptions.Update(ctx, NewSubscription(merchant.ID, customer.ID, uuid.New())))
+		assert.Error(t, repos.Subscriptions.Create(ctx, NewSubscription(merchant.ID, customer.ID, uuid.New())), "plan must exist")
+	})
+
+	t.Run("list due", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer, plan := fixtures(t, repos)
+		now := time.Now()
+		past := now.Add(-time.Hour)
+		future := now.Add(time.Hour)
+
+		create := func(status entity.SubscriptionStatus, periodEnd time.Time, retryAt *time.Time) *entity.Subscription {
+			sub := NewSubscription(merchant.ID, customer.ID, plan.ID)
+			sub.Status = status
+			sub.CurrentPeriodEnd = periodEnd
+			sub.NextRetryAt = retryAt
+			require.NoError(t, repos.Subscriptions.Create(ctx, sub))
+			return sub
+		}
+		ended := create(entity.SubscriptionStatusActive, past, nil)
+		trialEnded := create(entity.SubscriptionStatusTrialing, past.Add(-time.Minute), nil)
+		retryDue := create(entity.SubscriptionStatusPastDue, past, &past)
+		create(entity.SubscriptionStatusActive, future, nil)
+		create(entity.SubscriptionStatusPastDue, past, &future)
+		create(entity.SubscriptionStatusCanceled, past, nil)
+		create(entity.SubscriptionStatusUnpaid, past, nil)
+
+		due, err := repos.Subscriptions.ListDue(ctx, now, 10)
+		require.NoError(t, err)
+		var ids []uuid.UUID
+		for _, sub := range due {
+			ids = append(ids, sub.ID)
+		}
+		assert.Len(t, ids, 3)
+		assert.Equal(t, trialEnded.ID, ids[0], "oldest period end first")
+		assert.Contains(t, ids, ended.ID)
+		assert.Contains(t, ids, retryDue.ID)
+
+		due, err = repos.Subscriptions.ListDue(ctx, now, 1)
+		require.NoError(t, err)
+		assert.Len(t, due, 1)
+	})
+
+	t.Run("claim due", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer, plan := fixtures(t, repos)
+		now := time.Now()
+
+		sub := NewSubscription(merchant.ID, customer.ID, plan.ID)
+		sub.CurrentPeriodEnd = now.Add(-time.Hour)
+		require.NoError(t, repos.Subscriptions.Create(ctx, sub))
+		notDue := NewSubscription(merchant.ID, customer.ID, plan.ID)
+		notDue.CurrentPeriodEnd = now.Add(time.Hour)
+		require.NoError(t, repos.Subscriptions.Create(ctx, notDue))
+
+		require.NoError(t, repos.Subscriptions.Claim(ctx, sub.ID, now, now.Add(time.Minute)))
+		assert.Error(t, repos.Subscriptions.Claim(ctx, sub.ID, now, now.Add(time.Minute)), "already claimed")
+		assert.Error(t, repos.Subscriptions.Claim(ctx, notDue.ID, now, now.Add(time.Minute)), "not due")
+		assert.Error(t, repos.Subscriptions.Claim(ctx, uuid.New(), now, now.Add(time.Minute)))
+
+		listed := func(now time.Time) bool {
+			due, err := repos.Subscriptions.ListDue(ctx, now, 1000)
+			require.NoError(t, err)
+			for _, d := range due {
+				if d.ID == sub.ID {
+					return true
+				}
+			}
+			return false
+		}
+		assert.False(t, listed(now), "claimed subscriptions are not listed")
+
+		// 鎖定逾時後可重新取得
+		later := now.Add(2 * time.Minute)
+		assert.True(t, listed(later))
+		require.NoError(t, repos.Subscriptions.Claim(ctx, sub.ID, later, later.Add(time.Minute)))
+
+		// 續期後不再到期
+		sub.CurrentPeriodEnd = now.Add(30 * 24 * time.Hour)
+		require.NoError(t, repos.Subscriptions.Update(ctx, sub))
+		assert.Error(t, repos.Subscriptions.Claim(ctx, sub.ID, later.Add(time.Hour), later.Add(2*time.Hour)))
+	})
+
+	t.Run("get by merchant", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer, plan := fixtures(t, repos)
+		for i := 0; i < 3; i++ {
+			sub := NewSubscription(merchant.ID, customer.ID, plan.ID)
+			sub.CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+			require.NoError(t, repos.Subscriptions.Create(ctx, sub))
+		}
+
+		subs, err := repos.Subscriptions.GetByMerchantID(ctx, merchant.ID, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, subs, 2)
+		assert.True(t, subs[0].CreatedAt.After(subs[1].CreatedAt))
+
+		subs, err = repos.Subscriptions.GetByMerchantID(ctx, merchant.ID, 10, 2)
+		require.NoError(t, err)
+		assert.Len(t, subs, 1)
+	})
+}
+
+func runInvoiceTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	fixtures := func(t *testing.T, repos Repositories) (*entity.Merchant, *entity.Customer) {
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		return merchant, customer
+	}
+
+	t.Run("create get and update", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		invoice := NewInvoice(merchant.ID, customer.ID)
+		require.NoError(t, repos.Invoices.Create(ctx, invoice))
+
+		got, err := repos.Invoices.GetByID(ctx, invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, invoice.MerchantID, got.MerchantID)
+		assert.Equal(t, invoice.CustomerID, got.CustomerID)
+		assert.Equal(t, entity.InvoiceStatusDraft, got.Status)
+		assert.Equal(t, invoice.Memo, got.Memo)
+		assert.Equal(t, invoice.Subtotal, got.Subtotal)
+		assert.Equal(t, invoice.DiscountTotal, got.DiscountTotal)
+		assert.Equal(t, invoice.TaxTotal, got.TaxTotal)
+		assert.Equal(t, invoice.Total, got.Total)
+		require.NotNil(t, got.DueDate)
+		assert.WithinDuration(t, *invoice.DueDate, *got.DueDate, time.Millisecond)
+		require.Len(t, got.LineItems, 2)
+		assert.Equal(t, invoice.LineItems[0].ID, got.LineItems[0].ID, "line items keep their order")
+		want := invoice.LineItems[1]
+		want.InvoiceID = invoice.ID
+		want.Position = 1
+		assert.Equal(t, want, got.LineItems[1])
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.InvoiceID = &invoice.ID
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+		gotPayment, err := repos.Payments.GetByID(ctx, payment.ID)
+		require.NoError(t, err)
+		require.NotNil(t, gotPayment.InvoiceID)
+		assert.Equal(t, invoice.ID, *gotPayment.InvoiceID)
+
+		now := time.Now().Truncate(time.Millisecond)
+		invoice.Status = entity.InvoiceStatusPaid
+		invoice.Number = "INV-" + invoice.ID.String()[:8]
+		invoice.PaymentID = &payment.ID
+		invoice.FinalizedAt = &now
+		invoice.PaidAt = &now
+		invoice.LineItems = invoice.LineItems[1:]
+		invoice.UpdatedAt = now
+		require.NoError(t, repos.Invoices.Update(ctx, invoice))
+
+		got, err = repos.Invoices.GetByID(ctx, invoice.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.InvoiceStatusPaid, got.Status)
+		assert.Equal(t, invoice.Number, got.Number)
+		require.NotNil(t, got.PaymentID)
+		assert.Equal(t, payment.ID, *got.PaymentID)
+		require.NotNil(t, got.PaidAt)
+		assert.WithinDuration(t, now, *got.PaidAt, time.Millisecond)
+		require.Len(t, got.LineItems, 1, "update replaces line items")
+		assert.Equal(t, invoice.LineItems[0].ID, got.LineItems[0].ID)
+	})
+
+	t.Run("not found and references", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+
+		got, err := repos.Invoices.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invoice not found")
+		assert.Nil(t, got)
+		assert.Error(t, repos.Invoices.Update(ctx, NewInvoice(merchant.ID, customer.ID)))
+		assert.Error(t, repos.Invoices.Create(ctx, NewInvoice(merchant.ID, uuid.New())), "customer must exist")
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		missing := uuid.New()
+		payment.InvoiceID = &missing
+		assert.Error(t, repos.Payments.Create(ctx, payment), "invoice must exist")
+	})
+
+	t.Run("unique number per merchant", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		other := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, other))
+
+		number := "INV-" + uuid.NewString()[:8]
+		first := NewInvoice(merchant.ID, customer.ID)
+		first.Number = number
+		require.NoError(t, repos.Invoices.Create(ctx, first))
+
+		duplicate := NewInvoice(merchant.ID, customer.ID)
+		duplicate.Number = number
+		assert.Error(t, repos.Invoices.Create(ctx, duplicate))
+
+		otherMerchant := NewInvoice(other.ID, customer.ID)
+		otherMerchant.Number = number
+		assert.NoError(t, repos.Invoices.Create(ctx, otherMerchant))
+
+		// 草稿沒有編號，不受唯一性限制
+		require.NoError(t, repos.Invoices.Create(ctx, NewInvoice(merchant.ID, customer.ID)))
+		require.NoError(t, repos.Invoices.Create(ctx, NewInvoice(merchant.ID, customer.ID)))
+	})
+
+	t.Run("get by merchant", func(t *testing.T) {
+		repos := setup(t)
+		merchant, customer := fixtures(t, repos)
+		for i := 0; i < 3; i++ {
+			invoice := NewInvoice(merchant.ID, customer.ID)
+			invoice.CreatedAt = time.Now().Add(time.Duration(i) * time.Second)
+			require.NoError(t, repos.Invoices.Create(ctx, invoice))
+		}
+
+		invoices, err := repos.Invoices.GetByMerchantID(ctx, merchant.ID, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, invoices, 2)
+		assert.True(t, invoices[0].CreatedAt.After(invoices[1].CreatedAt))
+		assert.Empty(t, invoices[0].LineItems, "lists do not load line items")
+
+		invoices, err = repos.Invoices.GetByMerchantID(ctx, merchant.ID, 10, 2)
+		require.NoError(t, err)
+		assert.Len(t, invoices, 1)
+	})
+}
+
+func runCheckoutSessionTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create get and transition", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		session := NewCheckoutSession(merchant.ID)
+		require.NoError(t, repos.Checkouts.Create(ctx, session))
+
+		got, err := repos.Checkouts.GetByID(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, session.MerchantID, got.MerchantID)
+		assert.Nil(t, got.CustomerID)
+		assert.Equal(t, session.CustomerEmail, got.CustomerEmail)
+		assert.Equal(t, session.Amount, got.Amount)
+		assert.Equal(t, session.Currency, got.Currency)
+		assert.Equal(t, session.Description, got.Description)
+		assert.Equal(t, session.Reference, got.Reference)
+		assert.Equal(t, entity.CheckoutSessionStatusOpen, got.Status)
+		assert.Equal(t, session.AllowedMethods, got.AllowedMethods, "allowed methods keep their order")
+		assert.Equal(t, session.SuccessURL, got.SuccessURL)
+		assert.Equal(t, session.CancelURL, got.CancelURL)
+		assert.WithinDuration(t, session.ExpiresAt, got.ExpiresAt, time.Millisecond)
+		assert.Nil(t, got.PaymentID)
+		assert.Nil(t, got.CompletedAt)
+
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+		now := time.Now().Truncate(time.Millisecond)
+		session.Status = entity.CheckoutSessionStatusComplete
+		session.CustomerID = &customer.ID
+		session.PaymentID = &payment.ID
+		session.CompletedAt = &now
+		session.UpdatedAt = now
+		require.NoError(t, repos.Checkouts.Transition(ctx, session, entity.CheckoutSessionStatusOpen))
+
+		got, err = repos.Checkouts.GetByID(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.CheckoutSessionStatusComplete, got.Status)
+		require.NotNil(t, got.CustomerID)
+		assert.Equal(t, customer.ID, *got.CustomerID)
+		require.NotNil(t, got.PaymentID)
+		assert.Equal(t, payment.ID, *got.PaymentID)
+		require.NotNil(t, got.CompletedAt)
+		assert.WithinDuration(t, now, *got.CompletedAt, time.Millisecond)
+
+		session.Status = entity.CheckoutSessionStatusCanceled
+		err = repos.Checkouts.Transition(ctx, session, entity.CheckoutSessionStatusOpen)
+		assert.Error(t, err, "a completed session cannot transition from open again")
+		got, err = repos.Checkouts.GetByID(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.CheckoutSessionStatusComplete, got.Status)
+	})
+
+	t.Run("not found and references", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		got, err := repos.Checkouts.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checkout session not found")
+		assert.Nil(t, got)
+		assert.Error(t, repos.Checkouts.Transition(ctx, NewCheckoutSession(merchant.ID), entity.CheckoutSessionStatusOpen))
+		assert.Error(t, repos.Checkouts.Create(ctx, NewCheckoutSession(uuid.New())), "merchant must exist")
+
+		session := NewCheckoutSession(merchant.ID)
+		missing := uuid.New()
+		session.CustomerID = &missing
+		assert.Error(t, repos.Checkouts.Create(ctx, session), "customer must exist")
+	})
+}
+
+func runPaymentLinkTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create get and list", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		link := NewPaymentLink(merchant.ID)
+		limit := 3
+		link.QuantityLimit = &limit
+		expires := link.CreatedAt.Add(24 * time.Hour)
+		link.ExpiresAt = &expires
+		require.NoError(t, repos.PaymentLinks.Create(ctx, link))
+
+		got, err := repos.PaymentLinks.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Equal(t, link.MerchantID, got.MerchantID)
+		assert.Equal(t, link.Name, got.Name)
+		assert.Equal(t, link.Description, got.Description)
+		assert.Equal(t, link.Amount, got.Amount)
+		assert.Equal(t, link.Currency, got.Currency)
+		assert.False(t, got.SingleUse)
+		require.NotNil(t, got.QuantityLimit)
+		assert.Equal(t, 3, *got.QuantityLimit)
+		assert.Equal(t, 0, got.PaymentCount)
+		assert.Equal(t, link.AllowedMethods, got.AllowedMethods)
+		assert.Equal(t, link.SuccessURL, got.SuccessURL)
+		assert.True(t, got.IsActive)
+		require.NotNil(t, got.ExpiresAt)
+		assert.WithinDuration(t, expires, *got.ExpiresAt, time.Millisecond)
+
+		older := NewPaymentLink(merchant.ID)
+		older.CreatedAt = link.CreatedAt.Add(-time.Hour)
+		older.QuantityLimit = nil
+		require.NoError(t, repos.PaymentLinks.Create(ctx, older))
+		other := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, other))
+		require.NoError(t, repos.PaymentLinks.Create(ctx, NewPaymentLink(other.ID)))
+
+		links, err := repos.PaymentLinks.GetByMerchantID(ctx, merchant.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, links, 2)
+		assert.Equal(t, link.ID, links[0].ID, "newest first")
+		assert.Nil(t, links[1].QuantityLimit)
+		links, err = repos.PaymentLinks.GetByMerchantID(ctx, merchant.ID, 10, 1)
+		require.NoError(t, err)
+		assert.Len(t, links, 1)
+
+		require.NoError(t, repos.PaymentLinks.SetActive(ctx, link.ID, false))
+		got, err = repos.PaymentLinks.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.False(t, got.IsActive)
+	})
+
+	t.Run("claim respects availability", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		link := NewPaymentLink(merchant.ID)
+		link.SingleUse = true
+		limit := 1
+		link.QuantityLimit = &limit
+		require.NoError(t, repos.PaymentLinks.Create(ctx, link))
+
+		now := time.Now()
+		require.NoError(t, repos.PaymentLinks.ClaimPayment(ctx, link.ID, now))
+		assert.Error(t, repos.PaymentLinks.ClaimPayment(ctx, link.ID, now), "quantity limit reached")
+		got, err := repos.PaymentLinks.GetByID(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 1, got.PaymentCount)
+
+		require.NoError(t, repos.PaymentLinks.ReleasePayment(ctx, link.ID))
+		assert.Error(t, repos.PaymentLinks.ReleasePayment(ctx, link.ID), "count cannot go below zero")
+		require.NoError(t, repos.PaymentLinks.ClaimPayment(ctx, link.ID, now))
+
+		expiring := NewPaymentLink(merchant.ID)
+		expires := now.Add(time.Minute)
+		expiring.ExpiresAt = &expires
+		require.NoError(t, repos.PaymentLinks.Create(ctx, expiring))
+		assert.Error(t, repos.PaymentLinks.ClaimPayment(ctx, expiring.ID, now.Add(time.Hour)), "expired")
+		require.NoError(t, repos.PaymentLinks.ClaimPayment(ctx, expiring.ID, now))
+
+		require.NoError(t, repos.PaymentLinks.SetActive(ctx, expiring.ID, false))
+		assert.Error(t, repos.PaymentLinks.ClaimPayment(ctx, expiring.ID, now), "inactive")
+	})
+
+	t.Run("payments and stats", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		link := NewPaymentLink(merchant.ID)
+		require.NoError(t, repos.PaymentLinks.Create(ctx, link))
+
+		stats, err := repos.PaymentLinks.GetStats(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 0, stats.Payments)
+		assert.Equal(t, int64(0), stats.AmountCollected)
+		assert.Equal(t, link.Currency, stats.Currency)
+
+		for i, status := range []entity.PaymentStatus{entity.PaymentStatusCompleted, entity.PaymentStatusCompleted, entity.PaymentStatusFailed} {
+			payment := NewPayment(merchant.ID, customer.ID)
+			payment.PaymentLinkID = &link.ID
+			payment.Amount = int64(1000 * (i + 1))
+			payment.CreatedAt = payment.CreatedAt.Add(time.Duration(i) * time.Second)
+			require.NoError(t, repos.Payments.Create(ctx, payment))
+			require.NoError(t, repos.Payments.UpdateStatus(ctx, payment.ID, status))
+		}
+		require.NoError(t, repos.Payments.Create(ctx, NewPayment(merchant.ID, customer.ID)))
+
+		payments, err := repos.Payments.GetByPaymentLinkID(ctx, link.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, payments, 3)
+		assert.Equal(t, int64(3000), payments[0].Amount, "newest first")
+		require.NotNil(t, payments[0].PaymentLinkID)
+		assert.Equal(t, link.ID, *payments[0].PaymentLinkID)
+
+		stats, err = repos.PaymentLinks.GetStats(ctx, link.ID)
+		require.NoError(t, err)
+		assert.Equal(t, link.ID, stats.PaymentLinkID)
+		assert.Equal(t, 3, stats.Payments)
+		assert.Equal(t, 2, stats.Completed)
+		assert.Equal(t, int64(3000), stats.AmountCollected)
+	})
+
+	t.Run("not found and references", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		got, err := repos.PaymentLinks.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "payment link not found")
+		assert.Nil(t, got)
+		_, err = repos.PaymentLinks.GetStats(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Error(t, repos.PaymentLinks.SetActive(ctx, uuid.New(), false))
+		assert.Error(t, repos.PaymentLinks.ClaimPayment(ctx, uuid.New(), time.Now()))
+		assert.Error(t, repos.PaymentLinks.Create(ctx, NewPaymentLink(uuid.New())), "merchant must exist")
+
+		missing := uuid.New()
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.PaymentLinkID = &missing
+		assert.Error(t, repos.Payments.Create(ctx, payment), "payment link must exist")
+		session := NewCheckoutSession(merchant.ID)
+		session.PaymentLinkID = &missing
+		assert.Error(t, repos.Checkouts.Create(ctx, session), "payment link must exist")
+	})
+}
+
+func runJobTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// claim 在共用資料庫上可能取得其他測試的工作，只檢查本測試建立的工作
+	claim := func(t *testing.T, repos Repositories, now time.Time, id uuid.UUID) *entity.Job {
+		t.Helper()
+		jobs, err := repos.Jobs.Claim(ctx, now, 30*time.Second, 100)
+		require.NoError(t, err)
+		for _, job := range jobs {
+			if job.ID == id {
+				return job
+			}
+		}
+		return nil
+	}
+
+	t.Run("enqueue and get", func(t *testing.T) {
+		repos := setup(t)
+		job := NewJob(time.Now())
+		require.NoError(t, repos.Jobs.Enqueue(ctx, job))
+		assert.Error(t, repos.Jobs.Enqueue(ctx, job), "duplicate id")
+
+		got, err := repos.Jobs.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, job.Type, got.Type)
+		assert.JSONEq(t, job.Payload, got.Payload)
+		assert.Equal(t, entity.JobStatusQueued, got.Status)
+		assert.Equal(t, 0, got.Attempts)
+		assert.WithinDuration(t, job.RunAt, got.RunAt, time.Millisecond)
+		assert.Nil(t, got.LockedUntil)
+
+		got, err = repos.Jobs.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "job not found")
+		assert.Nil(t, got)
+	})
+
+	t.Run("claim locks due jobs", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now().Truncate(time.Millisecond)
+		due := NewJob(now.Add(-time.Second))
+		later := NewJob(now.Add(time.Hour))
+		require.NoError(t, repos.Jobs.Enqueue(ctx, due))
+		require.NoError(t, repos.Jobs.Enqueue(ctx, later))
+
+		claimed := claim(t, repos, now, due.ID)
+		require.NotNil(t, claimed)
+		assert.Equal(t, entity.JobStatusRunning, claimed.Status)
+		assert.Equal(t, 1, claimed.Attempts)
+		assert.NotEmpty(t, claimed.LockedBy)
+		require.NotNil(t, claimed.LockedUntil)
+		assert.WithinDuration(t, now.Add(30*time.Second), *claimed.LockedUntil, time.Millisecond)
+		assert.Nil(t, claim(t, repos, now, later.ID), "not due yet")
+		assert.Nil(t, claim(t, repos, now, due.ID), "locked")
+
+		// 鎖定逾時後視為 worker 已中斷，工作可再被取得
+		reclaimed := claim(t, repos, now.Add(31*time.Second), due.ID)
+		require.NotNil(t, reclaimed)
+		assert.Equal(t, 2, reclaimed.Attempts)
+
+		err := repos.Jobs.Complete(ctx, due.ID, 1)
+		assert.Error(t, err, "stale attempt")
+		assert.Contains(t, err.Error(), "lock lost")
+		require.NoError(t, repos.Jobs.Complete(ctx, due.ID, 2))
+
+		got, err := repos.Jobs.GetByID(ctx, due.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.JobStatusSucceeded, got.Status)
+		assert.Nil(t, got.LockedUntil)
+		assert.Nil(t, claim(t, repos, now.Add(time.Minute), due.ID), "succeeded jobs are not claimed")
+	})
+
+	t.Run("retry and fail", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now().Truncate(time.Millisecond)
+		job := NewJob(now.Add(-time.Second))
+		require.NoError(t, repos.Jobs.Enqueue(ctx, job))
+
+		require.NotNil(t, claim(t, repos, now, job.ID))
+		require.NoError(t, repos.Jobs.Retry(ctx, job.ID, 1, now.Add(time.Minute), "gateway timeout"))
+
+		got, err := repos.Jobs.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.JobStatusQueued, got.Status)
+		assert.Equal(t, "gateway timeout", got.LastError)
+		assert.WithinDuration(t, now.Add(time.Minute), got.RunAt, time.Millisecond)
+		assert.Nil(t, claim(t, repos, now, job.ID), "waiting for backoff")
+
+		claimed := claim(t, repos, now.Add(2*time.Minute), job.ID)
+		require.NotNil(t, claimed)
+		assert.Equal(t, 2, claimed.Attempts)
+		assert.Error(t, repos.Jobs.Retry(ctx, job.ID, 1, now, "stale"))
+		require.NoError(t, repos.Jobs.Fail(ctx, job.ID, 2, "gateway declined"))
+
+		got, err = repos.Jobs.GetByID(ctx, job.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.JobStatusFailed, got.Status)
+		assert.Equal(t, "gateway declined", got.LastError)
+		assert.Error(t, repos.Jobs.Fail(ctx, job.ID, 2, "again"), "no longer running")
+		assert.Error(t, repos.Jobs.Complete(ctx, uuid.New(), 1))
+	})
+}
+
+func runBankTransferTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// newTransfer 建立付款與其匯款資訊
+	newTransfer := func(t *testing.T, repos Repositories, expiresAt time.Time) *entity.BankTransfer {
+		t.Helper()
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.Method = entity.PaymentMethodBankTransfer
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		transfer := NewBankTransfer(payment, expiresAt)
+		require.NoError(t, repos.BankTransfers.Create(ctx, transfer))
+		return transfer
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		transfer := newTransfer(t, repos, time.Now().Add(time.Hour))
+
+		for _, get := range []func() (*entity.BankTransfer, error){
+			func() (*entity.BankTransfer, error) {
+				return repos.BankTransfers.GetByPaymentID(ctx, transfer.PaymentID)
+			},
+			func() (*entity.BankTransfer, error) {
+				return repos.BankTransfers.GetByReference(ctx, transfer.Reference)
+			},
+			func() (*entity.BankTransfer, error) {
+				return repos.BankTransfers.GetByVirtualAccount(ctx, transfer.VirtualAccount)
+			},
+		} {
+			got, err := get()
+			require.NoError(t, err)
+			assert.Equal(t, transfer.PaymentID, got.PaymentID)
+			assert.Equal(t, transfer.MerchantID, got.MerchantID)
+			assert.Equal(t, transfer.Reference, got.Reference)
+			assert.Equal(t, transfer.VirtualAccount, got.VirtualAccount)
+			assert.Equal(t, transfer.BankName, got.BankName)
+			assert.Equal(t, transfer.AmountExpected, got.AmountExpected)
+			assert.Equal(t, int64(0), got.AmountReceived)
+			assert.Equal(t, transfer.Currency, got.Currency)
+			assert.Equal(t, entity.BankTransferStatusAwaitingFunds, got.Status)
+			assert.WithinDuration(t, transfer.ExpiresAt, got.ExpiresAt, time.Millisecond)
+		}
+
+		got, err := repos.BankTransfers.GetByReference(ctx, "BT_MISSING")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "bank transfer not found")
+		assert.Nil(t, got)
+	})
+
+	t.Run("create rejects duplicate reference and account", func(t *testing.T) {
+		repos := setup(t)
+		transfer := newTransfer(t, repos, time.Now().Add(time.Hour))
+		other := newTransfer(t, repos, time.Now().Add(time.Hour))
+
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(transfer.MerchantID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		duplicate := NewBankTransfer(payment, time.Now().Add(time.Hour))
+		duplicate.Reference = other.Reference
+		assert.Error(t, repos.BankTransfers.Create(ctx, duplicate), "duplicate reference")
+		duplicate = NewBankTransfer(payment, time.Now().Add(time.Hour))
+		duplicate.VirtualAccount = other.VirtualAccount
+		assert.Error(t, repos.BankTransfers.Create(ctx, duplicate), "duplicate virtual account")
+		assert.NoError(t, repos.BankTransfers.Create(ctx, NewBankTransfer(payment, time.Now().Add(time.Hour))))
+	})
+
+	t.Run("apply credit accumulates", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now()
+		transfer := newTransfer(t, repos, now.Add(time.Hour))
+
+		got, err := repos.BankTransfers.ApplyCredit(ctx, transfer.PaymentID, 4000, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(4000), got.AmountReceived)
+		assert.Equal(t, entity.BankTransferStatusUnderpaid, got.Status)
+
+		got, err = repos.BankTransfers.ApplyCredit(ctx, transfer.PaymentID, 6000, now)
+		require.NoError(t, err)
+		assert.Equal(t, int64(10000), got.AmountReceived)
+		assert.Equal(t, entity.BankTransferStatusPaid, got.Status)
+
+		_, err = repos.BankTransfers.ApplyCredit(ctx, transfer.PaymentID, 100, now)
+		assert.Error(t, err, "already paid")
+
+		overpaid := newTransfer(t, repos, now.Add(time.Hour))
+		got, err = repos.BankTransfers.ApplyCredit(ctx, overpaid.PaymentID, 12000, now)
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankTransferStatusOverpaid, got.Status)
+
+		expired := newTransfer(t, repos, now.Add(-time.Minute))
+		_, err = repos.BankTransfers.ApplyCredit(ctx, expired.PaymentID, 10000, now)
+		assert.Error(t, err, "past expires_at")
+		_, err = repos.BankTransfers.ApplyCredit(ctx, uuid.New(), 10000, now)
+		assert.Error(t, err)
+	})
+
+	t.Run("list and expire due", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now().Truncate(time.Millisecond)
+		due := newTransfer(t, repos, now.Add(-time.Minute))
+		later := newTransfer(t, repos, now.Add(time.Hour))
+		paid := newTransfer(t, repos, now.Add(-time.Minute))
+		_, err := repos.BankTransfers.ApplyCredit(ctx, paid.PaymentID, paid.AmountExpected, now.Add(-2*time.Minute))
+		require.NoError(t, err)
+
+		transfers, err := repos.BankTransfers.ListExpired(ctx, now, 1000)
+		require.NoError(t, err)
+		ids := make(map[uuid.UUID]bool)
+		for _, transfer := range transfers {
+			ids[transfer.PaymentID] = true
+		}
+		assert.True(t, ids[due.PaymentID])
+		assert.False(t, ids[later.PaymentID], "not yet expired")
+		assert.False(t, ids[paid.PaymentID], "already paid")
+
+		assert.Error(t, repos.BankTransfers.Expire(ctx, later.PaymentID, now), "not yet expired")
+		assert.Error(t, repos.BankTransfers.Expire(ctx, paid.PaymentID, now), "already paid")
+		require.NoError(t, repos.BankTransfers.Expire(ctx, due.PaymentID, now))
+		assert.Error(t, repos.BankTransfers.Expire(ctx, due.PaymentID, now), "already expired")
+
+		got, err := repos.BankTransfers.GetByPaymentID(ctx, due.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankTransferStatusExpired, got.Status)
+	})
+
+	t.Run("cancel before expiry", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now().Truncate(time.Millisecond)
+		open := newTransfer(t, repos, now.Add(time.Hour))
+		paid := newTransfer(t, repos, now.Add(time.Hour))
+		_, err := repos.BankTransfers.ApplyCredit(ctx, paid.PaymentID, paid.AmountExpected, now)
+		require.NoError(t, err)
+
+		require.NoError(t, repos.BankTransfers.Cancel(ctx, open.PaymentID, now))
+		assert.Error(t, repos.BankTransfers.Cancel(ctx, open.PaymentID, now), "already cancelled")
+		assert.Error(t, repos.BankTransfers.Cancel(ctx, paid.PaymentID, now), "already paid")
+		assert.Error(t, repos.BankTransfers.Cancel(ctx, uuid.New(), now))
+
+		got, err := repos.BankTransfers.GetByPaymentID(ctx, open.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankTransferStatusExpired, got.Status)
+		_, err = repos.BankTransfers.ApplyCredit(ctx, open.PaymentID, open.AmountExpected, now)
+		assert.Error(t, err, "credits after cancel are not applied")
+	})
+}
+
+func runWalletActionTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// newAction 建立付款與其確認步驟
+	newAction := func(t *testing.T, repos Repositories, expiresAt time.Time) *entity.WalletAction {
+		t.Helper()
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.Method = entity.PaymentMethodDigitalWallet
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		action := NewWalletAction(payment, expiresAt)
+		require.NoError(t, repos.WalletActions.Create(ctx, action))
+		return action
+	}
+
+	t.Run("create and get", func(t *testing.T) {
+		repos := setup(t)
+		action := newAction(t, repos, time.Now().Add(time.Hour))
+
+		got, err := repos.WalletActions.GetByPaymentID(ctx, action.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, action.MerchantID, got.MerchantID)
+		assert.Equal(t, action.Type, got.Type)
+		assert.Equal(t, action.RedirectURL, got.RedirectURL)
+		assert.Equal(t, action.QRPayload, got.QRPayload)
+		assert.Equal(t, entity.WalletActionStatusPending, got.Status)
+		assert.WithinDuration(t, action.ExpiresAt, got.ExpiresAt, time.Millisecond)
+
+		assert.Error(t, repos.WalletActions.Create(ctx, action), "duplicate payment id")
+		_, err = repos.WalletActions.GetByPaymentID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "wallet action not found")
+
+		orphan := *action
+		orphan.PaymentID = uuid.New()
+		assert.Error(t, repos.WalletActions.Create(ctx, &orphan), "payment must exist")
+	})
+
+	t.Run("resolve only pending and unexpired", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now()
+		confirmed := newAction(t, repos, now.Add(time.Hour))
+		require.NoError(t, repos.WalletActions.Resolve(ctx, confirmed.PaymentID, entity.WalletActionStatusConfirmed, now))
+		assert.Error(t, repos.WalletActions.Resolve(ctx, confirmed.PaymentID, entity.WalletActionStatusRejected, now), "already confirmed")
+
+		got, err := repos.WalletActions.GetByPaymentID(ctx, confirmed.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WalletActionStatusConfirmed, got.Status)
+
+		expired := newAction(t, repos, now.Add(-time.Minute))
+		assert.Error(t, repos.WalletActions.Resolve(ctx, expired.PaymentID, entity.WalletActionStatusConfirmed, now), "past expires_at")
+		assert.Error(t, repos.WalletActions.Resolve(ctx, uuid.New(), entity.WalletActionStatusConfirmed, now))
+	})
+
+	t.Run("list and expire due", func(t *testing.T) {
+		repos := setup(t)
+		now := time.Now().Truncate(time.Millisecond)
+		due := newAction(t, repos, now.Add(-time.Minute))
+		later := newAction(t, repos, now.Add(time.Hour))
+		rejected := newAction(t, repos, now.Add(-time.Minute))
+		require.NoError(t, repos.WalletActions.Resolve(ctx, rejected.PaymentID, entity.WalletActionStatusRejected, now.Add(-2*time.Minute)))
+
+		actions, err := repos.WalletActions.ListExpired(ctx, now, 1000)
+		require.NoError(t, err)
+		ids := make(map[uuid.UUID]bool)
+		for _, action := range actions {
+			ids[action.PaymentID] = true
+		}
+		assert.True(t, ids[due.PaymentID])
+		assert.False(t, ids[later.PaymentID], "not yet expired")
+		assert.False(t, ids[rejected.PaymentID], "already rejected")
+
+		assert.Error(t, repos.WalletActions.Expire(ctx, later.PaymentID, now), "not yet expired")
+		assert.Error(t, repos.WalletActions.Expire(ctx, rejected.PaymentID, now), "already rejected")
+		require.NoError(t, repos.WalletActions.Expire(ctx, due.PaymentID, now))
+		assert.Error(t, repos.WalletActions.Expire(ctx, due.PaymentID, now), "already expired")
+
+		got, err := repos.WalletActions.GetByPaymentID(ctx, due.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WalletActionStatusExpired, got.Status)
+	})
+}
+
+func runWebhookEventTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create get and update", func(t *testing.T) {
+		repos := setup(t)
+		event := NewWebhookEvent("conformance", time.Now())
+		require.NoError(t, repos.WebhookEvents.Create(ctx, event))
+		duplicate := NewWebhookEvent("conformance", time.Now())
+		duplicate.EventID = event.EventID
+		assert.Error(t, repos.WebhookEvents.Create(ctx, duplicate), "duplicate event id")
+		duplicate.Provider = "other"
+		require.NoError(t, repos.WebhookEvents.Create(ctx, duplicate), "event ids are unique per provider")
+
+		got, err := repos.WebhookEvents.GetByEventID(ctx, "conformance", event.EventID)
+		require.NoError(t, err)
+		assert.Equal(t, event.ID, got.ID)
+		assert.Equal(t, event.EventType, got.EventType)
+		assert.Equal(t, event.Payload, got.Payload)
+		assert.Equal(t, entity.WebhookEventStatusReceived, got.Status)
+		assert.Nil(t, got.PaymentID)
+		assert.Nil(t, got.ProcessedAt)
+
+		paymentID := uuid.New()
+		processedAt := time.Now().Truncate(time.Millisecond)
+		got.Status = entity.WebhookEventStatusFailed
+		got.PaymentID = &paymentID
+		got.Error = "payment not found"
+		got.Attempts = 1
+		got.ProcessedAt = &processedAt
+		require.NoError(t, repos.WebhookEvents.Update(ctx, got))
+		got, err = repos.WebhookEvents.GetByID(ctx, event.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookEventStatusFailed, got.Status)
+		require.NotNil(t, got.PaymentID)
+		assert.Equal(t, paymentID, *got.PaymentID, "payment need not exist")
+		assert.Equal(t, "payment not found", got.Error)
+		assert.Equal(t, 1, got.Attempts)
+		require.NotNil(t, got.ProcessedAt)
+		assert.WithinDuration(t, processedAt, *got.ProcessedAt, time.Millisecond)
+
+		_, err = repos.WebhookEvents.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "webhook event not found")
+		_, err = repos.WebhookEvents.GetByEventID(ctx, "conformance", "evt_missing")
+		assert.Error(t, err)
+		assert.Error(t, repos.WebhookEvents.Update(ctx, NewWebhookEvent("conformance", time.Now())))
+	})
+
+	t.Run("list filters by provider and status", func(t *testing.T) {
+		repos := setup(t)
+		// 共用資料庫上可能有其他事件，使用隨機的網關名稱
+		provider := "conformance_" + uuid.NewString()[:8]
+		base := time.Now().Truncate(time.Millisecond)
+		older := NewWebhookEvent(provider, base)
+		newer := NewWebhookEvent(provider, base.Add(time.Minute))
+		processed := NewWebhookEvent(provider, base.Add(2*time.Minute))
+		processed.Status = entity.WebhookEventStatusProcessed
+		for _, event := range []*entity.WebhookEvent{older, newer, processed, NewWebhookEvent("other", base)} {
+			require.NoError(t, repos.WebhookEvents.Create(ctx, event))
+		}
+
+		events, err := repos.WebhookEvents.List(ctx, provider, entity.WebhookEventStatusReceived, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, newer.ID, events[0].ID, "newest first")
+		assert.Equal(t, older.ID, events[1].ID)
+
+		events, err = repos.WebhookEvents.List(ctx, provider, "", 10, 1)
+		require.NoError(t, err)
+		require.Len(t, events, 2)
+		assert.Equal(t, newer.ID, events[0].ID)
+
+		events, err = repos.WebhookEvents.List(ctx, "", entity.WebhookEventStatusProcessed, 1000, 0)
+		require.NoError(t, err)
+		ids := make(map[uuid.UUID]bool)
+		for _, event := range events {
+			assert.Equal(t, entity.WebhookEventStatusProcessed, event.Status)
+			ids[event.ID] = true
+		}
+		assert.True(t, ids[processed.ID])
+	})
+}
+
+func runDisputeTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// newDispute 建立已完成的付款與其爭議
+	newDispute := func(t *testing.T, repos Repositories, merchant *entity.Merchant, createdAt time.Time) *entity.Dispute {
+		t.Helper()
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(merchant.ID, customer.ID)
+		payment.Status = entity.PaymentStatusCompleted
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		dispute := NewDispute(payment, createdAt)
+		require.NoError(t, repos.Disputes.Create(ctx, dispute, NewDisputeEvent(dispute, entity.DisputeEventCreated, createdAt)))
+		return dispute
+	}
+
+	t.Run("create get and add evidence", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		dispute := newDispute(t, repos, merchant, time.Now())
+
+		got, err := repos.Disputes.GetByID(ctx, dispute.ID)
+		require.NoError(t, err)
+		assert.Equal(t, dispute.PaymentID, got.PaymentID)
+		assert.Equal(t, dispute.MerchantID, got.MerchantID)
+		assert.Equal(t, dispute.Amount, got.Amount)
+		assert.Equal(t, dispute.Reason, got.Reason)
+		assert.Equal(t, entity.DisputeStatusNeedsResponse, got.Status)
+		assert.WithinDuration(t, dispute.EvidenceDueBy, got.EvidenceDueBy, time.Millisecond)
+		assert.Empty(t, got.Evidence)
+
+		byPayment, err := repos.Disputes.GetByPaymentID(ctx, dispute.PaymentID)
+		require.NoError(t, err)
+		assert.Equal(t, dispute.ID, byPayment.ID)
+
+		duplicate := NewDispute(&entity.Payment{ID: dispute.PaymentID, MerchantID: merchant.ID, Amount: 100, Currency: "USD"}, time.Now())
+		assert.Error(t, repos.Disputes.Create(ctx, duplicate, NewDisputeEvent(duplicate, entity.DisputeEventCreated, time.Now())), "one dispute per payment")
+		_, err = repos.Disputes.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "dispute not found")
+
+		first := NewDisputeEvidence(dispute.ID, time.Now())
+		second := NewDisputeEvidence(dispute.ID, first.CreatedAt.Add(time.Second))
+		second.FileName, second.ContentType, second.Size, second.StorageKey = "receipt.pdf", "application/pdf", 1024, dispute.ID.String()+"/receipt"
+		require.NoError(t, repos.Disputes.AddEvidence(ctx, second))
+		require.NoError(t, repos.Disputes.AddEvidence(ctx, first))
+		assert.Error(t, repos.Disputes.AddEvidence(ctx, NewDisputeEvidence(uuid.New(), time.Now())), "dispute must exist")
+
+		got, err = repos.Disputes.GetByID(ctx, dispute.ID)
+		require.NoError(t, err)
+		require.Len(t, got.Evidence, 2)
+		assert.Equal(t, first.ID, got.Evidence[0].ID, "ordered by created_at")
+		assert.Equal(t, first.Description, got.Evidence[0].Description)
+		assert.Equal(t, "receipt.pdf", got.Evidence[1].FileName)
+		assert.Equal(t, "application/pdf", got.Evidence[1].ContentType)
+		assert.Equal(t, int64(1024), got.Evidence[1].Size)
+		assert.Equal(t, second.StorageKey, got.Evidence[1].StorageKey)
+	})
+
+	t.Run("transition writes events and reversal", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		now := time.Now().Truncate(time.Millisecond)
+		dispute := newDispute(t, repos, merchant, now.Add(-time.Hour))
+
+		submitted := *dispute
+		submitted.Status = entity.DisputeStatusUnderReview
+		submitted.SubmittedAt = &now
+		submitted.UpdatedAt = now
+		require.NoError(t, repos.Disputes.Transition(ctx, &submitted, entity.DisputeStatusNeedsResponse,
+			NewDisputeEvent(&submitted, entity.DisputeEventEvidenceSubmitted, now), nil))
+		assert.Error(t, repos.Disputes.Transition(ctx, &submitted, entity.DisputeStatusNeedsResponse,
+			NewDisputeEvent(&submitted, entity.DisputeEventEvidenceSubmitted, now), nil), "status already changed")
+		assert.Error(t, repos.Disputes.AddEvidence(ctx, NewDisputeEvidence(dispute.ID, now)), "evidence closed after submission")
+
+		closedAt := now.Add(time.Minute)
+		lost := submitted
+		lost.Status = entity.DisputeStatusLost
+		lost.ClosedAt = &closedAt
+		lost.UpdatedAt = closedAt
+		reversal := NewLedgerEntry(&lost, closedAt)
+		require.NoError(t, repos.Disputes.Transition(ctx, &lost, entity.DisputeStatusUnderReview,
+			NewDisputeEvent(&lost, entity.DisputeEventLost, closedAt), reversal))
+
+		got, err := repos.Disputes.GetByID(ctx, dispute.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.DisputeStatusLost, got.Status)
+		require.NotNil(t, got.SubmittedAt)
+		assert.WithinDuration(t, now, *got.SubmittedAt, time.Millisecond)
+		require.NotNil(t, got.ClosedAt)
+		assert.WithinDuration(t, closedAt, *got.ClosedAt, time.Millisecond)
+
+		events, err := repos.Disputes.ListEvents(ctx, merchant.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, events, 3)
+		assert.Equal(t, entity.DisputeEventLost, events[0].Type, "newest first")
+		assert.Equal(t, entity.DisputeStatusLost, events[0].Status)
+		assert.Equal(t, entity.DisputeEventEvidenceSubmitted, events[1].Type)
+		assert.Equal(t, entity.DisputeEventCreated, events[2].Type)
+		assert.Equal(t, dispute.PaymentID, events[0].PaymentID)
+
+		entries, err := repos.Ledger.ListByMerchant(ctx, merchant.ID, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, entity.LedgerEntryDisputeReversal, entries[0].Type)
+		assert.Equal(t, -dispute.Amount, entries[0].Amount)
+		assert.Equal(t, dispute.PaymentID, entries[0].PaymentID)
+		require.NotNil(t, entries[0].DisputeID)
+		assert.Equal(t, dispute.ID, *entries[0].DisputeID)
+
+		// 沖正重複時整個轉換不生效
+		again := newDispute(t, repos, merchant, now)
+		closed := *again
+		closed.Status = entity.DisputeStatusLost
+		closed.ClosedAt = &closedAt
+		duplicate := NewLedgerEntry(&closed, closedAt)
+		duplicate.DisputeID = &dispute.ID
+		assert.Error(t, repos.Disputes.Transition(ctx, &closed, entity.DisputeStatusNeedsResponse,
+			NewDisputeEvent(&closed, entity.DisputeEventLost, closedAt), duplicate))
+		got, err = repos.Disputes.GetByID(ctx, again.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.DisputeStatusNeedsResponse, got.Status)
+	})
+
+	t.Run("list by merchant and status", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		other := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, other))
+		base := time.Now().Add(-time.Hour)
+		older := newDispute(t, repos, merchant, base)
+		newer := newDispute(t, repos, merchant, base.Add(time.Minute))
+		newDispute(t, repos, other, base)
+
+		won := *older
+		won.Status = entity.DisputeStatusWon
+		won.ClosedAt = &base
+		require.NoError(t, repos.Disputes.Transition(ctx, &won, entity.DisputeStatusNeedsResponse,
+			NewDisputeEvent(&won, entity.DisputeEventWon, base), nil))
+
+		disputes, err := repos.Disputes.ListByMerchant(ctx, merchant.ID, "", 10, 0)
+		require.NoError(t, err)
+		require.Len(t, disputes, 2)
+		assert.Equal(t, newer.ID, disputes[0].ID)
+		assert.Equal(t, older.ID, disputes[1].ID)
+
+		disputes, err = repos.Disputes.ListByMerchant(ctx, merchant.ID, entity.DisputeStatusWon, 10, 0)
+		require.NoError(t, err)
+		require.Len(t, disputes, 1)
+		assert.Equal(t, older.ID, disputes[0].ID)
+
+		disputes, err = repos.Disputes.ListByMerchant(ctx, merchant.ID, "", 1, 1)
+		require.NoError(t, err)
+		require.Len(t, disputes, 1)
+		assert.Equal(t, older.ID, disputes[0].ID)
+
+		entries, err := repos.Ledger.ListByMerchant(ctx, merchant.ID, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, entries, "won disputes are not reversed")
+	})
+}
+
+func runBankCreditTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("create get and update", func(t *testing.T) {
+		repos := setup(t)
+		credit := NewBankCredit(time.Now())
+		require.NoError(t, repos.BankCredits.Create(ctx, credit))
+		duplicate := NewBankCredit(time.Now())
+		duplicate.TransactionID = credit.TransactionID
+		assert.Error(t, repos.BankCredits.Create(ctx, duplicate), "duplicate transaction id")
+
+		got, err := repos.BankCredits.GetByTransactionID(ctx, credit.TransactionID)
+		require.NoError(t, err)
+		assert.Equal(t, credit.ID, got.ID)
+		assert.Equal(t, credit.Reference, got.Reference)
+		assert.Equal(t, credit.VirtualAccount, got.VirtualAccount)
+		assert.Equal(t, credit.Amount, got.Amount)
+		assert.Equal(t, credit.Currency, got.Currency)
+		assert.Equal(t, credit.PayerName, got.PayerName)
+		assert.WithinDuration(t, credit.ReceivedAt, got.ReceivedAt, time.Millisecond)
+		assert.Equal(t, entity.BankCreditStatusUnmatched, got.Status)
+		assert.Nil(t, got.PaymentID)
+
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+
+		got.Status = entity.BankCreditStatusMatched
+		got.PaymentID = &payment.ID
+		got.Note = "overpaid by 100"
+		require.NoError(t, repos.BankCredits.Update(ctx, got))
+		got, err = repos.BankCredits.GetByTransactionID(ctx, credit.TransactionID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankCreditStatusMatched, got.Status)
+		require.NotNil(t, got.PaymentID)
+		assert.Equal(t, payment.ID, *got.PaymentID)
+		assert.Equal(t, "overpaid by 100", got.Note)
+
+		_, err = repos.BankCredits.GetByTransactionID(ctx, "TX_MISSING")
+		assert.Error(t, err)
+		assert.Error(t, repos.BankCredits.Update(ctx, NewBankCredit(time.Now())))
+	})
+
+	t.Run("list filters by status", func(t *testing.T) {
+		repos := setup(t)
+		// 共用資料庫上可能有其他入帳，使用未來的時間讓本測試的入帳排在最前面
+		base := time.Now().Add(24 * time.Hour).Truncate(time.Millisecond)
+		older := NewBankCredit(base)
+		newer := NewBankCredit(base.Add(time.Minute))
+		matched := NewBankCredit(base.Add(2 * time.Minute))
+		matched.Status = entity.BankCreditStatusMatched
+		for _, credit := range []*entity.BankCredit{older, newer, matched} {
+			require.NoError(t, repos.BankCredits.Create(ctx, credit))
+		}
+
+		credits, err := repos.BankCredits.List(ctx, entity.BankCreditStatusUnmatched, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, credits, 2)
+		assert.Equal(t, newer.ID, credits[0].ID, "newest first")
+		assert.Equal(t, older.ID, credits[1].ID)
+
+		credits, err = repos.BankCredits.List(ctx, "", 1, 0)
+		require.NoError(t, err)
+		require.Len(t, credits, 1)
+		assert.Equal(t, matched.ID, credits[0].ID)
+		credits, err = repos.BankCredits.List(ctx, "", 1, 1)
+		require.NoError(t, err)
+		require.Len(t, credits, 1)
+		assert.Equal(t, newer.ID, credits[0].ID)
+	})
+}
+
+func runStatementTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	// newPayment 建立對帳單交易與差異付款引用的付款
+	newPayment := func(t *testing.T, repos Repositories) *entity.Payment {
+		t.Helper()
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		payment := NewPayment(merchant.ID, customer.ID)
+		require.NoError(t, repos.Payments.Create(ctx, payment))
+		return payment
+	}
+
+	t.Run("create get and resolve", func(t *testing.T) {
+		repos := setup(t)
+		matchedPayment := newPayment(t, repos)
+		missingPayment := newPayment(t, repos)
+
+		statement := NewStatement(time.Now())
+		matched := NewStatementLine(statement.PeriodStart.Add(2 * time.Hour))
+		matched.Status = entity.ReconciliationStatusMatched
+		matched.PaymentID = &matchedPayment.ID
+		fee := NewStatementLine(statement.PeriodStart.Add(time.Hour))
+		fee.Amount = -250
+		statement.Lines = []*entity.StatementLine{matched, fee}
+		exception := NewStatementException(missingPayment)
+		statement.Exceptions = []*entity.StatementException{exception}
+		require.NoError(t, repos.Statements.Create(ctx, statement))
+
+		got, err := repos.Statements.GetByID(ctx, statement.ID)
+		require.NoError(t, err)
+		assert.Equal(t, statement.Format, got.Format)
+		assert.Equal(t, statement.BankID, got.BankID)
+		assert.Equal(t, statement.Account, got.Account)
+		assert.Equal(t, statement.Currency, got.Currency)
+		assert.WithinDuration(t, statement.PeriodStart, got.PeriodStart, time.Millisecond)
+		assert.WithinDuration(t, statement.PeriodEnd, got.PeriodEnd, time.Millisecond)
+		require.Len(t, got.Lines, 2)
+		assert.Equal(t, fee.ID, got.Lines[0].ID, "lines are ordered by booking date")
+		assert.Equal(t, statement.ID, got.Lines[0].StatementID)
+		assert.Equal(t, int64(-250), got.Lines[0].Amount)
+		assert.Equal(t, fee.TransactionID, got.Lines[0].TransactionID)
+		assert.Equal(t, fee.Reference, got.Lines[0].Reference)
+		assert.Equal(t, fee.Counterparty, got.Lines[0].Counterparty)
+		assert.Nil(t, got.Lines[0].PaymentID)
+		assert.Equal(t, &matchedPayment.ID, got.Lines[1].PaymentID)
+		assert.Equal(t, entity.ReconciliationStatusMatched, got.Lines[1].Status)
+		require.Len(t, got.Exceptions, 1)
+		assert.Equal(t, missingPayment.ID, got.Exceptions[0].PaymentID)
+		assert.Equal(t, missingPayment.MerchantID, got.Exceptions[0].MerchantID)
+		assert.Equal(t, missingPayment.Reference, got.Exceptions[0].Reference)
+		assert.Equal(t, entity.ReconciliationStatusUnmatched, got.Exceptions[0].Status)
+
+		now := time.Now().Truncate(time.Millisecond)
+		require.NoError(t, repos.Statements.ResolveLine(ctx, statement.ID, fee.ID, nil, "bank fee", now))
+		assert.Error(t, repos.Statements.ResolveLine(ctx, statement.ID, fee.ID, nil, "again", now), "already resolved")
+		assert.Error(t, repos.Statements.ResolveLine(ctx, statement.ID, matched.ID, nil, "matched", now), "only unmatched lines")
+		assert.Error(t, repos.Statements.ResolveLine(ctx, uuid.New(), fee.ID, nil, "other statement", now))
+		require.NoError(t, repos.Statements.ResolveException(ctx, statement.ID, missingPayment.ID, "settled next day", now))
+		assert.Error(t, repos.Statements.ResolveException(ctx, statement.ID, missingPayment.ID, "again", now))
+
+		got, err = repos.Statements.GetByID(ctx, statement.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.ReconciliationStatusResolved, got.Lines[0].Status)
+		assert.Equal(t, "bank fee", got.Lines[0].Note)
+		require.NotNil(t, got.Lines[0].ResolvedAt)
+		assert.WithinDuration(t, now, *got.Lines[0].ResolvedAt, time.Millisecond)
+		assert.Equal(t, entity.ReconciliationStatusResolved, got.Exceptions[0].Status)
+		assert.Equal(t, "settled next day", got.Exceptions[0].Note)
+	})
+
+	t.Run("resolve line with payment", func(t *testing.T) {
+		repos := setup(t)
+		payment := newPayment(t, repos)
+		statement := NewStatement(time.Now())
+		line := NewStatementLine(statement.PeriodStart)
+		statement.Lines = []*entity.StatementLine{line}
+		require.NoError(t, repos.Statements.Create(ctx, statement))
+
+		require.NoError(t, repos.Statements.ResolveLine(ctx, statement.ID, line.ID, &payment.ID, "paid with wrong reference", time.Now()))
+		got, err := repos.Statements.GetByID(ctx, statement.ID)
+		require.NoError(t, err)
+		assert.Equal(t, &payment.ID, got.Lines[0].PaymentID)
+		assert.Empty(t, got.Exceptions)
+	})
+
+	t.Run("not found and references", func(t *testing.T) {
+		repos := setup(t)
+		_, err := repos.Statements.GetByID(ctx, uuid.New())
+		assert.Error(t, err)
+
+		statement := NewStatement(time.Now())
+		line := NewStatementLine(statement.PeriodStart)
+		missing := uuid.New()
+		line.PaymentID = &missing
+		statement.Lines = []*entity.StatementLine{line}
+		assert.Error(t, repos.Statements.Create(ctx, statement), "payment must exist")
+		_, err = repos.Statements.GetByID(ctx, statement.ID)
+		assert.Error(t, err, "nothing is written when a line fails")
+	})
+
+	t.Run("list newest first", func(t *testing.T) {
+		repos := setup(t)
+		// 共用資料庫上可能有其他對帳單，使用未來的時間讓本測試的對帳單排在最前面
+		base := time.Now().Add(24 * time.Hour).Truncate(time.Millisecond)
+		older := NewStatement(base)
+		newer := NewStatement(base.Add(time.Minute))
+		newer.Lines = []*entity.StatementLine{NewStatementLine(newer.PeriodStart)}
+		require.NoError(t, repos.Statements.Create(ctx, older))
+		require.NoError(t, repos.Statements.Create(ctx, newer))
+
+		statements, err := repos.Statements.List(ctx, 2, 0)
+		require.NoError(t, err)
+		require.Len(t, statements, 2)
+		assert.Equal(t, newer.ID, statements[0].ID)
+		assert.Empty(t, statements[0].Lines, "lines are not loaded")
+		assert.Equal(t, older.ID, statements[1].ID)
+
+		statements, err = repos.Statements.List(ctx, 1, 1)
+		require.NoError(t, err)
+		require.Len(t, statements, 1)
+		assert.Equal(t, older.ID, statements[0].ID)
+	})
+}
+
+func runRiskTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("count payments since", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+		// 共用資料庫上以隨機值代替 IP，避免與其他測試的付款一起計算
+		ip := "ip_" + uuid.NewString()
+
+		now := time.Now()
+		for _, createdAt := range []time.Time{now.Add(-2 * time.Hour), now.Add(-30 * time.Minute), now.Add(-time.Minute)} {
+			payment := NewPayment(merchant.ID, customer.ID)
+			payment.Status = entity.PaymentStatusFailed
+			payment.ClientIP = ip
+			payment.CreatedAt = createdAt
+			require.NoError(t, repos.Payments.Create(ctx, payment))
+		}
+
+		since := now.Add(-time.Hour)
+		for key, value := range map[entity.RiskVelocityKey]string{
+			entity.RiskVelocityCustomer: customer.ID.String(),
+			entity.RiskVelocityEmail:    strings.ToUpper(customer.Email),
+			entity.RiskVelocityIP:       ip,
+		} {
+			count, err := repos.Risk.CountPaymentsSince(ctx, key, value, since)
+			require.NoError(t, err, key)
+			assert.Equal(t, 2, count, key)
+		}
+
+		count, err := repos.Risk.CountPaymentsSince(ctx, entity.RiskVelocityIP, "ip_"+uuid.NewString(), since)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+		_, err = repos.Risk.CountPaymentsSince(ctx, "card", "x", since)
+		assert.Error(t, err)
+	})
+
+	t.Run("count completed payments", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+		customer := NewCustomer()
+		require.NoError(t, repos.Customers.Create(ctx, customer))
+
+		count, err := repos.Risk.CountCompletedPayments(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Zero(t, count)
+
+		for _, status := range []entity.PaymentStatus{entity.PaymentStatusCompleted, entity.PaymentStatusReview, entity.PaymentStatusCompleted} {
+			payment := NewPayment(merchant.ID, customer.ID)
+			payment.Status = status
+			require.NoError(t, repos.Payments.Create(ctx, payment))
+		}
+		count, err = repos.Risk.CountCompletedPayments(ctx, customer.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 2, count)
+	})
+}
+
+func runMerchantLimitTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("replace and list", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		limits, err := repos.MerchantLimits.ListByMerchant(ctx, merchant.ID)
+		require.NoError(t, err)
+		assert.Empty(t, limits)
+
+		usd := NewMerchantLimit(merchant.ID, "", "USD")
+		card := NewMerchantLimit(merchant.ID, entity.PaymentMethodCreditCard, "USD")
+		require.NoError(t, repos.MerchantLimits.Replace(ctx, merchant.ID, []*entity.MerchantLimit{card, usd}))
+
+		limits, err = repos.MerchantLimits.ListByMerchant(ctx, merchant.ID)
+		require.NoError(t, err)
+		require.Len(t, limits, 2)
+		assert.Equal(t, usd.ID, limits[0].ID)
+		assert.Equal(t, card.ID, limits[1].ID)
+		assert.Equal(t, entity.PaymentMethodCreditCard, limits[1].Method)
+		assert.Equal(t, card.MaxAmount, limits[1].MaxAmount)
+		assert.Equal(t, card.DailyAmount, limits[1].DailyAmount)
+		assert.Equal(t, card.DailyCount, limits[1].DailyCount)
+
+		twd := NewMerchantLimit(merchant.ID, "", "TWD")
+		require.NoError(t, repos.MerchantLimits.Replace(ctx, merchant.ID, []*entity.MerchantLimit{twd}))
+		limits, err = repos.MerchantLimits.ListByMerchant(ctx, merchant.ID)
+		require.NoError(t, err)
+		require.Len(t, limits, 1)
+		assert.Equal(t, twd.ID, limits[0].ID)
+
+		// 同一付款方式與幣別只能有一個限額，失敗時保留原本的限額
+		err = repos.MerchantLimits.Replace(ctx, merchant.ID, []*entity.MerchantLimit{
+			NewMerchantLimit(merchant.ID, "", "USD"), NewMerchantLimit(merchant.ID, "", "USD"),
+		})
+		assert.Error(t, err)
+		limits, err = repos.MerchantLimits.ListByMerchant(ctx, merchant.ID)
+		require.NoError(t, err)
+		require.Len(t, limits, 1)
+		assert.Equal(t, twd.ID, limits[0].ID)
+
+		require.NoError(t, repos.MerchantLimits.Replace(ctx, merchant.ID, nil))
+		limits, err = repos.MerchantLimits.ListByMerchant(ctx, merchant.ID)
+		require.NoError(t, err)
+		assert.Empty(t, limits)
+	})
+
+	t.Run("reserve and release", func(t *testing.T) {
+		repos := setup(t)
+		merchant := NewMerchant()
+		require.NoError(t, repos.Merchants.Create(ctx, merchant))
+
+		all := NewMerchantLimit(merchant.ID, "", "USD")
+		all.DailyAmount = 20000
+		all.DailyCount = 0
+		card := NewMerchantLimit(merchant.ID, entity.PaymentMethodCreditCard, "USD")
+		card.DailyAmount = 0
+		card.DailyCount = 2
+		limits := []*entity.MerchantLimit{all, card}
+		now := time.Now()
+		day := now.UTC().Format("2006-01-02")
+
+		first, second := uuid.New(), uuid.New()
+		exceeded, err := repos.MerchantLimits.Reserve(ctx, first, limits, day, 4000, now)
+		require.NoError(t, err)
+		assert.Nil(t, exceeded)
+		exceeded, err = repos.MerchantLimits.Reserve(ctx, second, limits, day, 6000, now)
+		require.NoError(t, err)
+		assert.Nil(t, exceeded)
+
+		// 第三筆超過信用卡的每日筆數，所有限額的用量都不變
+		rejected := uuid.New()
+		exceeded, err = repos.MerchantLimits.Reserve(ctx, rejected, limits, day, 1, now)
+		require.NoError(t, err)
+		require.NotNil(t, exceeded)
+		assert.Equal(t, card.ID, exceeded.ID)
+
+		usage, err := repos.MerchantLimits.GetUsage(ctx, merchant.ID, day)
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+		assert.Equal(t, entity.PaymentMethod(""), usage[0].Method)
+		assert.Equal(t, int64(10000), usage[0].Amount)
+		assert.Equal(t, 2, usage[0].Count)
+		assert.Equal(t, entity.PaymentMethodCreditCard, usage[1].Method)
+		assert.Equal(t, 2, usage[1].Count)
+
+		// 其他付款方式只受全部付款方式的限額限制
+		exceeded, err = repos.MerchantLimits.Reserve(ctx, uuid.New(), []*entity.MerchantLimit{all}, day, 10001, now)
+		require.NoError(t, err)
+		require.NotNil(t, exceeded)
+		assert.Equal(t, all.ID, exceeded.ID)
+
+		// 未寫入用量的付款沒有可扣回的記錄
+		require.NoError(t, repos.MerchantLimits.Release(ctx, rejected, now))
+
+		// 之後修改限額不影響扣回的計數，重複扣回不會再扣減
+		require.NoError(t, repos.MerchantLimits.Replace(ctx, merchant.ID, []*entity.MerchantLimit{all}))
+		require.NoError(t, repos.MerchantLimits.Release(ctx, second, now))
+		require.NoError(t, repos.MerchantLimits.Release(ctx, second, now))
+		usage, err = repos.MerchantLimits.GetUsage(ctx, merchant.ID, day)
+		require.NoError(t, err)
+		require.Len(t, usage, 2)
+		assert.Equal(t, int64(4000), usage[0].Amount)
+		assert.Equal(t, 1, usage[0].Count)
+		assert.Equal(t, 1, usage[1].Count)
+
+		// 隔天重新累計
+		usage, err = repos.MerchantLimits.GetUsage(ctx, merchant.ID, now.UTC().AddDate(0, 0, 1).Format("2006-01-02"))
+		require.NoError(t, err)
+		assert.Empty(t, usage)
+	})
+}
+
+func runRateLimitTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("take and refill", func(t *testing.T) {
+		repos := setup(t)
+		key := "merchant_" + uuid.NewString()
+		limit := entity.RateLimit{Requests: 2, Period: time.Minute, Burst: 3}
+		now := time.Now().UTC().Truncate(time.Second)
+
+		for i := 2; i >= 0; i-- {
+			result, err := repos.RateLimits.Take(ctx, key, limit, now)
+			require.NoError(t, err)
+			assert.True(t, result.Allowed)
+			assert.Equal(t, i, result.Remaining)
+		}
+
+		result, err := repos.RateLimits.Take(ctx, key, limit, now)
+		require.NoError(t, err)
+		assert.False(t, result.Allowed)
+		assert.Equal(t, 30*time.Second, result.RetryAfter)
+		assert.Equal(t, 90*time.Second, result.Reset)
+
+		// 其他 key 有各自的 bucket
+		result, err = repos.RateLimits.Take(ctx, key+"|POST /api/v1/payments", limit, now)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+
+		// 30 秒補充一個 token
+		result, err = repos.RateLimits.Take(ctx, key, limit, now.Add(30*time.Second))
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 0, result.Remaining)
+	})
+
+	t.Run("concurrent takes", func(t *testing.T) {
+		repos := setup(t)
+		key := "merchant_" + uuid.NewString()
+		limit := entity.RateLimit{Requests: 5, Period: time.Hour}
+		now := time.Now().UTC()
+
+		var (
+			wg      sync.WaitGroup
+			mu      sync.Mutex
+			allowed int
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := repos.RateLimits.Take(ctx, key, limit, now)
+				if !assert.NoError(t, err) {
+					return
+				}
+				if result.Allowed {
+					mu.Lock()
+					allowed++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 5, allowed)
+	})
+}
+
+func runNonceTests(t *testing.T, setup func(t *testing.T) Repositories) {
+	ctx := context.Background()
+
+	t.Run("use once until expired", func(t *testing.T) {
+		repos := setup(t)
+		key := uuid.NewString() + "|" + uuid.NewString()
+		now := time.Now().UTC().Truncate(time.Second)
+
+		used, err := repos.Nonces.Use(ctx, key, now.Add(5*time.Minute), now)
+		require.NoError(t, err)
+		assert.True(t, used)
+
+		used, err = repos.Nonces.Use(ctx, key, now.Add(6*time.Minute), now.Add(time.Minute))
+		require.NoError(t, err)
+		assert.False(t, used)
+
+		// 其他 key 不受影響
+		used, err = repos.Nonces.Use(ctx, uuid.NewString()+"|"+uuid.NewString(), now.Add(5*time.Minute), now)
+		require.NoError(t, err)
+		assert.True(t, used)
+
+		// 到期後可再次記錄
+		used, err = repos.Nonces.Use(ctx, key, now.Add(10*time.Minute), now.Add(5*time.Minute))
+		require.NoError(t, err)
+		assert.True(t, used)
+		used, err = repos.Nonces.Use(ctx, key, now.Add(10*time.Minute), now.Add(6*time.Minute))
+		require.NoError(t, err)
+		assert.False(t, used)
+	})
+
+	t.Run("concurrent use", func(t *testing.T) {
+		repos := setup(t)
+		key := uuid.NewString() + "|" + uuid.NewString()
+		now := time.Now().UTC()
+
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			used int
+		)
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ok, err := repos.Nonces.Use(ctx, key, now.Add(5*time.Minute), now)
+				if !assert.NoError(t, err) {
+					return
+				}
+				if ok {
+					mu.Lock()
+					used++
+					mu.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, 1, used)
+	})
+}
+
+func NewMerchantLimit(merchantID uuid.UUID, method entity.PaymentMethod, currency string) *entity.MerchantLimit {
+	now := time.Now()
+	return &entity.MerchantLimit{
+		ID:          uuid.New(),
+		MerchantID:  merchantID,
+		Method:      method,
+		Currency:    currency,
+		MaxAmount:   1000000,
+		DailyAmount: 10000000,
+		DailyCount:  100,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func NewMerchant() *entity.Merchant {
+	id := uuid.New()
+	now := time.Now()
+	return &entity.Merchant{
+		ID:             id,
+		Name:           "Conformance Merchant",
+		Email:          fmt.Sprintf("merchant_%s@example.com", id),
+		APIKey:         "api_key_" + id.String(),
+		IsActive:       true,
+		Tier:           entity.MerchantTierStandard,
+		PublishableKey: "pk_" + id.String(),
+		SigningSecret:  "signing_secret_" + id.String(),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func NewCustomer() *entity.Customer {
+	id := uuid.New()
+	now := time.Now()
+	return &entity.Customer{
+		ID:        id,
+		Name:      "Conformance Customer",
+		Email:     fmt.Sprintf("customer_%s@example.com", id),
+		Phone:     "+1234567890",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func NewPayment(merchantID, customerID uuid.UUID) *entity.Payment {
+	id := uuid.New()
+	now := time.Now()
+	return &entity.Payment{
+		ID:           id,
+		MerchantID:   merchantID,
+		CustomerID:   customerID,
+		Amount:       10000,
+		Currency:     "USD",
+		Method:       entity.PaymentMethodCreditCard,
+		Status:       entity.PaymentStatusPending,
+		Description:  "Conformance payment",
+		Reference:    "REF_" + id.String(),
+		RiskScore:    20,
+		RiskDecision: entity.RiskDecisionAllow,
+		RiskReasons:  entity.RiskReasons{"first_time_customer"},
+		ClientIP:     "192.0.2.10",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+func NewCard(merchantID uuid.UUID) *entity.Card {
+	return &entity.Card{
+		Token:        "tok_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		MerchantID:   merchantID,
+		Brand:        "visa",
+		Last4:        "1111",
+		ExpMonth:     12,
+		ExpYear:      2030,
+		Fingerprint:  "fp_" + uuid.NewString(),
+		EncryptedPAN: []byte{0x01, 0x02, 0x03, 0x00, 0xff},
+		EncryptedKey: []byte{0x0a, 0x0b, 0x0c},
+		KeyID:        "test-key",
+		CreatedAt:    time.Now(),
+	}
+}
+
+func NewPaymentMethod(merchantID, customerID uuid.UUID) *entity.PaymentMethodRecord {
+	now := time.Now()
+	return &entity.PaymentMethodRecord{
+		ID:         uuid.New(),
+		CustomerID: customerID,
+		MerchantID: merchantID,
+		Type:       entity.PaymentMethodCreditCard,
+		Token:      "tok_" + strings.ReplaceAll(uuid.NewString(), "-", ""),
+		Last4:      "4242",
+		Brand:      "visa",
+		ExpMonth:   12,
+		ExpYear:    2030,
+		BillingDetails: entity.BillingDetails{
+			Name:       "Conformance Customer",
+			Email:      "billing@example.com",
+			Line1:      "1 Main St",
+			City:       "Springfield",
+			PostalCode: "12345",
+			Country:    "US",
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// 資料庫的時間精度可能只到微秒，時間欄位以容差比較
+func NewPlan(merchantID uuid.UUID) *entity.Plan {
+	now := time.Now()
+	return &entity.Plan{
+		ID:            uuid.New(),
+		MerchantID:    merchantID,
+		Name:          "Conformance Plan",
+		Amount:        2900,
+		Currency:      "USD",
+		Interval:      entity.PlanIntervalMonth,
+		IntervalCount: 1,
+		TrialDays:     14,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func NewSubscription(merchantID, customerID, planID uuid.UUID) *entity.Subscription {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.Subscription{
+		ID:                 uuid.New(),
+		MerchantID:         merchantID,
+		CustomerID:         customerID,
+		PlanID:             planID,
+		Quantity:           1,
+		Status:             entity.SubscriptionStatusActive,
+		BillingAnchor:      now,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now.AddDate(0, 1, 0),
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+}
+
+func NewInvoice(merchantID, customerID uuid.UUID) *entity.Invoice {
+	now := time.Now().Truncate(time.Millisecond)
+	due := now.AddDate(0, 0, 30)
+	return &entity.Invoice{
+		ID:            uuid.New(),
+		MerchantID:    merchantID,
+		CustomerID:    customerID,
+		Currency:      "USD",
+		Status:        entity.InvoiceStatusDraft,
+		Memo:          "Conformance invoice",
+		Subtotal:      12000,
+		DiscountTotal: 1000,
+		TaxTotal:      550,
+		Total:         11550,
+		DueDate:       &due,
+		LineItems: []entity.InvoiceLineItem{
+			{ID: uuid.New(), Description: "Consulting", Quantity: 2, UnitAmount: 5000, Discount: 1000, TaxName: "VAT", TaxRate: 500, Amount: 9000, TaxAmount: 450},
+			{ID: uuid.New(), Description: "Support", Quantity: 1, UnitAmount: 2000, TaxName: "VAT", TaxRate: 500, Amount: 2000, TaxAmount: 100},
+		},
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+func NewCheckoutSession(merchantID uuid.UUID) *entity.CheckoutSession {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.CheckoutSession{
+		ID:             uuid.New(),
+		MerchantID:     merchantID,
+		CustomerEmail:  "checkout-" + uuid.NewString()[:8] + "@example.com",
+		Amount:         4200,
+		Currency:       "USD",
+		Description:    "Conformance checkout",
+		Reference:      "CS-" + uuid.NewString()[:8],
+		Status:         entity.CheckoutSessionStatusOpen,
+		AllowedMethods: []entity.PaymentMethod{entity.PaymentMethodDigitalWallet, entity.PaymentMethodCreditCard},
+		SuccessURL:     "https://merchant.example.com/success",
+		CancelURL:      "https://merchant.example.com/cancel",
+		ExpiresAt:      now.Add(time.Hour),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func NewPaymentLink(merchantID uuid.UUID) *entity.PaymentLink {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.PaymentLink{
+		ID:             uuid.New(),
+		MerchantID:     merchantID,
+		Name:           "Conformance Link",
+		Description:    "Conformance payment link",
+		Amount:         1500,
+		Currency:       "USD",
+		AllowedMethods: []entity.PaymentMethod{entity.PaymentMethodCreditCard, entity.PaymentMethodBankTransfer},
+		SuccessURL:     "https://merchant.example.com/thanks",
+		IsActive:       true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func NewJob(runAt time.Time) *entity.Job {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.Job{
+		ID:        uuid.New(),
+		Type:      entity.JobTypeProcessPayment,
+		Payload:   fmt.Sprintf(`{"payment_id":%q}`, uuid.NewString()),
+		Status:    entity.JobStatusQueued,
+		RunAt:     runAt,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+}
+
+// NewBankTransfer 建立付款的匯款資訊，reference 與虛擬帳號為隨機值
+func NewBankTransfer(payment *entity.Payment, expiresAt time.Time) *entity.BankTransfer {
+	now := time.Now().Truncate(time.Millisecond)
+	suffix := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", ""))
+	return &entity.BankTransfer{
+		PaymentID:      payment.ID,
+		MerchantID:     payment.MerchantID,
+		Reference:      "BT" + suffix[:16],
+		VirtualAccount: fmt.Sprintf("99%d", uuid.New().ID()),
+		BankName:       "Conformance Bank",
+		AmountExpected: payment.Amount,
+		Currency:       payment.Currency,
+		Status:         entity.BankTransferStatusAwaitingFunds,
+		ExpiresAt:      expiresAt.Truncate(time.Millisecond),
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+func NewWalletAction(payment *entity.Payment, expiresAt time.Time) *entity.WalletAction {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.WalletAction{
+		PaymentID:   payment.ID,
+		MerchantID:  payment.MerchantID,
+		Type:        entity.NextActionRedirectToURL,
+		RedirectURL: "https://wallet.example.com/pay?payment_id=" + payment.ID.String(),
+		Status:      entity.WalletActionStatusPending,
+		ExpiresAt:   expiresAt.Truncate(time.Millisecond),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func NewWebhookEvent(provider string, createdAt time.Time) *entity.WebhookEvent {
+	eventID := "evt_" + uuid.NewString()
+	return &entity.WebhookEvent{
+		ID:        uuid.New(),
+		Provider:  provider,
+		EventID:   eventID,
+		EventType: "payment.succeeded",
+		Status:    entity.WebhookEventStatusReceived,
+		Payload:   `{"id":"` + eventID + `","type":"payment.succeeded"}`,
+		CreatedAt: createdAt.Truncate(time.Millisecond),
+		UpdatedAt: createdAt.Truncate(time.Millisecond),
+	}
+}
+
+func NewDispute(payment *entity.Payment, createdAt time.Time) *entity.Dispute {
+	createdAt = createdAt.Truncate(time.Millisecond)
+	return &entity.Dispute{
+		ID:            uuid.New(),
+		PaymentID:     payment.ID,
+		MerchantID:    payment.MerchantID,
+		Amount:        payment.Amount,
+		Currency:      payment.Currency,
+		Reason:        entity.DisputeReasonFraudulent,
+		Status:        entity.DisputeStatusNeedsResponse,
+		EvidenceDueBy: createdAt.Add(7 * 24 * time.Hour),
+		CreatedAt:     createdAt,
+		UpdatedAt:     createdAt,
+	}
+}
+
+func NewDisputeEvidence(disputeID uuid.UUID, createdAt time.Time) *entity.DisputeEvidence {
+	return &entity.DisputeEvidence{
+		ID:          uuid.New(),
+		DisputeID:   disputeID,
+		Description: "Customer signed for delivery",
+		CreatedAt:   createdAt.Truncate(time.Millisecond),
+	}
+}
+
+func NewDisputeEvent(dispute *entity.Dispute, eventType string, createdAt time.Time) *entity.DisputeEvent {
+	return &entity.DisputeEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		DisputeID:  dispute.ID,
+		MerchantID: dispute.MerchantID,
+		PaymentID:  dispute.PaymentID,
+		Status:     dispute.Status,
+		Amount:     dispute.Amount,
+		Currency:   dispute.Currency,
+		CreatedAt:  createdAt.Truncate(time.Millisecond),
+	}
+}
+
+func NewLedgerEntry(dispute *entity.Dispute, createdAt time.Time) *entity.LedgerEntry {
+	disputeID := dispute.ID
+	return &entity.LedgerEntry{
+		ID:          uuid.New(),
+		MerchantID:  dispute.MerchantID,
+		PaymentID:   dispute.PaymentID,
+		DisputeID:   &disputeID,
+		Type:        entity.LedgerEntryDisputeReversal,
+		Amount:      -dispute.Amount,
+		Currency:    dispute.Currency,
+		Description: "Dispute lost",
+		CreatedAt:   createdAt.Truncate(time.Millisecond),
+	}
+}
+
+func NewBankCredit(receivedAt time.Time) *entity.BankCredit {
+	now := time.Now().Truncate(time.Millisecond)
+	return &entity.BankCredit{
+		ID:            uuid.New(),
+		TransactionID: "TX_" + uuid.NewString(),
+		Reference:     "Invoice payment",
+		Amount:        10000,
+		Currency:      "USD",
+		PayerName:     "Conformance Payer",
+		ReceivedAt:    receivedAt.Truncate(time.Millisecond),
+		Status:        entity.BankCreditStatusUnmatched,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+}
+
+func assertMerchantEqual(t *testing.T, want, got *entity.Merchant) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Name, got.Name)
+	assert.Equal(t, want.Email, got.Email)
+	assert.Equal(t, want.APIKey, got.APIKey)
+	assert.Equal(t, want.IsActive, got.IsActive)
+	assert.Equal(t, want.Tier, got.Tier)
+	assert.Equal(t, want.PublishableKey, got.PublishableKey)
+	assert.Equal(t, want.AllowedOrigins, got.AllowedOrigins)
+	assert.Equal(t, want.SigningSecret, got.SigningSecret)
+	assert.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+}
+
+func assertCustomerEqual(t *testing.T, want, got *entity.Customer) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.Name, got.Name)
+	assert.Equal(t, want.Email, got.Email)
+	assert.Equal(t, want.Phone, got.Phone)
+	assert.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+}
+
+func assertPaymentEqual(t *testing.T, want, got *entity.Payment) {
+	t.Helper()
+	assert.Equal(t, want.ID, got.ID)
+	assert.Equal(t, want.MerchantID, got.MerchantID)
+	assert.Equal(t, want.CustomerID, got.CustomerID)
+	assert.Equal(t, want.Amount, got.Amount)
+	assert.Equal(t, want.Currency, got.Currency)
+	assert.Equal(t, want.Method, got.Method)
+	assert.Equal(t, want.Status, got.Status)
+	assert.Equal(t, want.Description, got.Description)
+	assert.Equal(t, want.Reference, got.Reference)
+	assert.Equal(t, want.PaymentMethodToken, got.PaymentMethodToken)
+	assert.Equal(t, want.RiskScore, got.RiskScore)
+	assert.Equal(t, want.RiskDecision, got.RiskDecision)
+	assert.Equal(t, want.RiskReasons, got.RiskReasons)
+	assert.Equal(t, want.ClientIP, got.ClientIP)
+	assert.WithinDuration(t, want.CreatedAt, got.CreatedAt, time.Millisecond)
+	assert.Nil(t, got.CompletedAt)
+}
+
+// NewStatement 建立涵蓋 createdAt 前一天的對帳單
+func NewStatement(createdAt time.Time) *entity.Statement {
+	day := time.Date(createdAt.Year(), createdAt.Month(), createdAt.Day(), 0, 0, 0, 0, time.UTC)
+	return &entity.Statement{
+		ID:          uuid.New(),
+		Format:      "csv",
+		BankID:      "STMT-" + uuid.NewString()[:8],
+		Account:     "DE89370400440532013000",
+		Currency:    "USD",
+		PeriodStart: day.AddDate(0, 0, -1),
+		PeriodEnd:   day,
+		CreatedAt:   createdAt,
+	}
+}
+
+func NewStatementLine(bookingDate time.Time) *entity.StatementLine {
+	return &entity.StatementLine{
+		ID:            uuid.New(),
+		TransactionID: "TX-" + uuid.NewString(),
+		BookingDate:   bookingDate,
+		Amount:        10000,
+		Currency:      "USD",
+		Reference:     "Conformance line",
+		Counterparty:  "Jane Doe",
+		Status:        entity.ReconciliationStatusUnmatched,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func NewStatementException(payment *entity.Payment) *entity.StatementException {
+	return &entity.StatementException{
+		PaymentID:   payment.ID,
+		MerchantID:  payment.MerchantID,
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Reference:   payment.Reference,
+		CompletedAt: time.Now().Add(-time.Hour),
+		Status:      entity.ReconciliationStatusUnmatched,
+		CreatedAt:   time.Now(),
+	}
+}
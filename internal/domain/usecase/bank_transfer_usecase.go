@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/bankfile"
+	"github.com/company/payment-service/pkg/card"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BankTransferConfig 設定銀行轉帳付款的匯款資訊
+type BankTransferConfig struct {
+	// ExpiresIn 為建立付款後等待款項的時間，逾期未付足的付款會被取消
+	ExpiresIn time.Duration
+	// AccountPrefix 為收款銀行分配的虛擬帳號前綴
+	AccountPrefix string
+	BankName      string
+}
+
+// BankTransferUseCase 處理銀行入帳：記錄對帳單上的入帳、依虛擬帳號或匯款參考號
+// 對應到等待中的銀行轉帳付款，並取消逾期未付足的付款
+type BankTransferUseCase interface {
+	// IngestCredits 記錄入帳並自動對應，交易序號已處理過的入帳直接回傳先前的結果
+	IngestCredits(ctx context.Context, credits []BankCreditRequest) ([]*entity.BankCredit, error)
+	// ImportCSV 解析 CSV 對帳單後呼叫 IngestCredits，格式見 bankfile.ParseCSV
+	ImportCSV(ctx context.Context, r io.Reader) ([]*entity.BankCredit, error)
+	ListCredits(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error)
+	// ExpireDue 將逾期仍未付足的匯款標為 expired 並取消付款，回傳處理筆數
+	ExpireDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type BankCreditRequest struct {
+	TransactionID  string    `json:"transaction_id"`
+	Reference      string    `json:"reference"`
+	VirtualAccount string    `json:"virtual_account"`
+	Amount         int64     `json:"amount"` // 以分為單位
+	Currency       string    `json:"currency"`
+	PayerName      string    `json:"payer_name"`
+	ReceivedAt     time.Time `json:"received_at"`
+}
+
+type bankTransferUseCase struct {
+	transferRepo repository.BankTransferRepository
+	creditRepo   repository.BankCreditRepository
+	payments     PaymentUseCase
+	now          func() time.Time
+}
+
+func NewBankTransferUseCase(
+	transferRepo repository.BankTransferRepository,
+	creditRepo repository.BankCreditRepository,
+	payments PaymentUseCase,
+) BankTransferUseCase {
+	return &bankTransferUseCase{
+		transferRepo: transferRepo,
+		creditRepo:   creditRepo,
+		payments:     payments,
+		now:          time.Now,
+	}
+}
+
+func invalidBankCredit(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_bank_credit")
+}
+
+func (uc *bankTransferUseCase) IngestCredits(ctx context.Context, reqs []BankCreditRequest) ([]*entity.BankCredit, error) {
+	// 先檢查整批，避免匯入到一半才發現格式錯誤
+	for i, req := range reqs {
+		switch {
+		case strings.TrimSpace(req.TransactionID) == "":
+			return nil, invalidBankCredit(fmt.Sprintf("credit %d: transaction_id is required", i))
+		case req.Amount <= 0:
+			return nil, invalidBankCredit(fmt.Sprintf("credit %d: amount must be positive", i))
+		case len(req.Currency) != 3:
+			return nil, invalidBankCredit(fmt.Sprintf("credit %d: currency must be a 3-letter code", i))
+		}
+	}
+
+	credits := make([]*entity.BankCredit, 0, len(reqs))
+	for _, req := range reqs {
+		credit, err := uc.ingest(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		credits = append(credits, credit)
+	}
+	return credits, nil
+}
+
+func (uc *bankTransferUseCase) ImportCSV(ctx context.Context, r io.Reader) ([]*entity.BankCredit, error) {
+	entries, err := bankfile.ParseCSV(r)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "invalid statement"), "invalid_bank_credit")
+	}
+	reqs := make([]BankCreditRequest, len(entries))
+	for i, e := range entries {
+		reqs[i] = BankCreditRequest{
+			TransactionID:  e.TransactionID,
+			Reference:      e.Reference,
+			VirtualAccount: e.Account,
+			Amount:         e.Amount,
+			Currency:       e.Currency,
+			PayerName:      e.Payer,
+			ReceivedAt:     e.BookingDate,
+		}
+	}
+	return uc.IngestCredits(ctx, reqs)
+}
+
+func (uc *bankTransferUseCase) ListCredits(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error) {
+	credits, err := uc.creditRepo.List(ctx, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bank credits")
+	}
+	return credits, nil
+}
+
+// ingest 先以 unmatched 寫入入帳，交易序號的唯一鍵確保並行匯入同一筆時只有一個會對應
+func (uc *bankTransferUseCase) ingest(ctx context.Context, req BankCreditRequest) (*entity.BankCredit, error) {
+	transactionID := strings.TrimSpace(req.TransactionID)
+	if existing, err := uc.creditRepo.GetByTransactionID(ctx, transactionID); err == nil {
+		return existing, nil
+	}
+
+	now := uc.now()
+	receivedAt := req.ReceivedAt
+	if receivedAt.IsZero() {
+		receivedAt = now
+	}
+	credit := &entity.BankCredit{
+		ID:             uuid.New(),
+		TransactionID:  transactionID,
+		Reference:      strings.TrimSpace(req.Reference),
+		VirtualAccount: strings.TrimSpace(req.VirtualAccount),
+		Amount:         req.Amount,
+		Currency:       strings.ToUpper(req.Currency),
+		PayerName:      strings.TrimSpace(req.PayerName),
+		ReceivedAt:     receivedAt,
+		Status:         entity.BankCreditStatusUnmatched,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := uc.creditRepo.Create(ctx, credit); err != nil {
+		if existing, getErr := uc.creditRepo.GetByTransactionID(ctx, transactionID); getErr == nil {
+			return existing, nil
+		}
+		return nil, errors.Wrap(err, "failed to record bank credit")
+	}
+
+	uc.match(ctx, credit)
+	if err := uc.creditRepo.Update(ctx, credit); err != nil {
+		return nil, errors.Wrap(err, "failed to update bank credit")
+	}
+
+	logger.FromContext(ctx).Info("bank credit recorded",
+		zap.String("bank_credit_id", credit.ID.String()),
+		zap.String("transaction_id", credit.TransactionID),
+		zap.String("status", string(credit.Status)),
+		zap.Int64("amount", credit.Amount),
+	)
+	return credit, nil
+}
+
+// match 對應入帳並設定 credit 的狀態、付款與備註。無法對應時保持 unmatched 交由人工處理
+func (uc *bankTransferUseCase) match(ctx context.Context, credit *entity.BankCredit) {
+	transfer := uc.findTransfer(ctx, credit)
+	if transfer == nil {
+		credit.Note = "no bank transfer found for reference or account"
+		return
+	}
+	credit.PaymentID = &transfer.PaymentID
+
+	if credit.Currency != transfer.Currency {
+		credit.Note = fmt.Sprintf("currency %s does not match payment currency %s", credit.Currency, transfer.Currency)
+		return
+	}
+	payment, err := uc.payments.GetPayment(ctx, transfer.PaymentID)
+	if err != nil {
+		credit.Note = "payment could not be loaded"
+		logger.FromContext(ctx).Error("failed to get payment for bank credit",
+			zap.String("payment_id", transfer.PaymentID.String()),
+			zap.Error(err),
+		)
+		return
+	}
+	if payment.Status != entity.PaymentStatusPending {
+		credit.Note = fmt.Sprintf("payment is %s, refund required", payment.Status)
+		return
+	}
+
+	updated, err := uc.transferRepo.ApplyCredit(ctx, transfer.PaymentID, credit.Amount, uc.now())
+	if err != nil {
+		// 已付足、已逾期或與逾期排程同時發生
+		status := transfer.Status
+		if current, getErr := uc.transferRepo.GetByPaymentID(ctx, transfer.PaymentID); getErr == nil {
+			status = current.Status
+		}
+		if status == entity.BankTransferStatusAwaitingFunds || status == entity.BankTransferStatusUnderpaid {
+			// 已過 expires_at，逾期排程尚未處理
+			status = entity.BankTransferStatusExpired
+		}
+		credit.Note = fmt.Sprintf("bank transfer is %s, refund required", status)
+		return
+	}
+	credit.Status = entity.BankCreditStatusMatched
+
+	switch updated.Status {
+	case entity.BankTransferStatusUnderpaid:
+		credit.Note = fmt.Sprintf("underpaid, %d still due", updated.AmountDue())
+		return
+	case entity.BankTransferStatusOverpaid:
+		credit.Note = fmt.Sprintf("overpaid by %d, refund required", updated.AmountReceived-updated.AmountExpected)
+	}
+	if err := uc.payments.SettlePayment(ctx, transfer.PaymentID); err != nil {
+		// 款項已入帳但付款在同時被取消，需人工退款
+		credit.Note = "payment could not be completed, refund required"
+		logger.FromContext(ctx).Error("failed to settle bank transfer payment",
+			zap.String("payment_id", transfer.PaymentID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// findTransfer 先以虛擬帳號、再以附言中的匯款參考號尋找匯款資訊
+func (uc *bankTransferUseCase) findTransfer(ctx context.Context, credit *entity.BankCredit) *entity.BankTransfer {
+	if account := card.Normalize(credit.VirtualAccount); account != "" {
+		if transfer, err := uc.transferRepo.GetByVirtualAccount(ctx, account); err == nil {
+			return transfer
+		}
+	}
+	if reference := findTransferReference(credit.Reference); reference != "" {
+		if transfer, err := uc.transferRepo.GetByReference(ctx, reference); err == nil {
+			return transfer
+		}
+	}
+	return nil
+}
+
+func (uc *bankTransferUseCase) ExpireDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	transfers, err := uc.transferRepo.ListExpired(ctx, now, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list expired bank transfers")
+	}
+
+	for _, transfer := range transfers {
+		log := logger.FromContext(ctx).With(zap.String("payment_id", transfer.PaymentID.String()))
+		// 其他實例已處理，或款項在同時入帳
+		if err := uc.transferRepo.Expire(ctx, transfer.PaymentID, now); err != nil {
+			log.Info("bank transfer no longer expirable, skipping", zap.Error(err))
+			continue
+		}
+		if err := uc.payments.CancelPayment(ctx, transfer.PaymentID); err != nil {
+			log.Warn("failed to cancel expired bank transfer payment", zap.Error(err))
+		}
+		if transfer.AmountReceived > 0 {
+			log.Warn("bank transfer expired with partial funds, refund required",
+				zap.Int64("amount_received", transfer.AmountReceived),
+			)
+		} else {
+			log.Info("bank transfer expired")
+		}
+	}
+	return len(transfers), nil
+}
+
+// transferReferenceAlphabet 不含容易混淆的 0、O、1、I
+const transferReferenceAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+var transferReferencePattern = regexp.MustCompile(`BT[A-HJ-NP-Z2-9]{10}`)
+
+// findTransferReference 從附言中找出匯款參考號，忽略大小寫、空白與連字號
+func findTransferReference(text string) string {
+	normalized := strings.ToUpper(strings.NewReplacer(" ", "", "-", "").Replace(text))
+	return transferReferencePattern.FindString(normalized)
+}
+
+// newTransferReference 產生 "BT" 加上 10 個隨機字元的匯款參考號
+func newTransferReference() (string, error) {
+	b := make([]byte, 10)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(transferReferenceAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		b[i] = transferReferenceAlphabet[n.Int64()]
+	}
+	return "BT" + string(b), nil
+}
+
+// newVirtualAccount 產生前綴加上 10 位隨機數字與 Luhn 檢查碼的虛擬帳號，
+// 檢查碼讓銀行可以拒絕打錯的帳號
+func newVirtualAccount(prefix string) (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1e10))
+	if err != nil {
+		return "", err
+	}
+	body := fmt.Sprintf("%s%010d", prefix, n.Int64())
+	for d := '0'; d <= '9'; d++ {
+		if card.LuhnValid(body + string(d)) {
+			return body + string(d), nil
+		}
+	}
+	return "", errors.New("failed to compute check digit")
+}
@@ -0,0 +1,402 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/card"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockBankTransferRepository struct {
+	mock.Mock
+}
+
+func (m *MockBankTransferRepository) Create(ctx context.Context, transfer *entity.BankTransfer) error {
+	args := m.Called(ctx, transfer)
+	return args.Error(0)
+}
+
+func (m *MockBankTransferRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.BankTransfer, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BankTransfer), args.Error(1)
+}
+
+func (m *MockBankTransferRepository) GetByReference(ctx context.Context, reference string) (*entity.BankTransfer, error) {
+	args := m.Called(ctx, reference)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BankTransfer), args.Error(1)
+}
+
+func (m *MockBankTransferRepository) GetByVirtualAccount(ctx context.Context, account string) (*entity.BankTransfer, error) {
+	args := m.Called(ctx, account)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BankTransfer), args.Error(1)
+}
+
+func (m *MockBankTransferRepository) ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (*entity.BankTransfer, error) {
+	args := m.Called(ctx, paymentID, amount, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BankTransfer), args.Error(1)
+}
+
+func (m *MockBankTransferRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.BankTransfer, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.BankTransfer), args.Error(1)
+}
+
+func (m *MockBankTransferRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, paymentID, now)
+	return args.Error(0)
+}
+
+func (m *MockBankTransferRepository) Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, paymentID, now)
+	return args.Error(0)
+}
+
+type MockBankCreditRepository struct {
+	mock.Mock
+}
+
+func (m *MockBankCreditRepository) Create(ctx context.Context, credit *entity.BankCredit) error {
+	args := m.Called(ctx, credit)
+	return args.Error(0)
+}
+
+func (m *MockBankCreditRepository) GetByTransactionID(ctx context.Context, transactionID string) (*entity.BankCredit, error) {
+	args := m.Called(ctx, transactionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.BankCredit), args.Error(1)
+}
+
+func (m *MockBankCreditRepository) Update(ctx context.Context, credit *entity.BankCredit) error {
+	args := m.Called(ctx, credit)
+	return args.Error(0)
+}
+
+func (m *MockBankCreditRepository) List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error) {
+	args := m.Called(ctx, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.BankCredit), args.Error(1)
+}
+
+// newTestBankTransferUseCase 建立時間固定為 now 的 use case
+func newTestBankTransferUseCase(transfers *MockBankTransferRepository, credits *MockBankCreditRepository, payments *MockPaymentUseCase, now time.Time) BankTransferUseCase {
+	uc := NewBankTransferUseCase(transfers, credits, payments).(*bankTransferUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+// appliedTransfer 回傳 ApplyCredit 後的匯款資訊
+func appliedTransfer(transfer *entity.BankTransfer, received int64) *entity.BankTransfer {
+	updated := *transfer
+	updated.AmountReceived = received
+	switch {
+	case received < updated.AmountExpected:
+		updated.Status = entity.BankTransferStatusUnderpaid
+	case received == updated.AmountExpected:
+		updated.Status = entity.BankTransferStatusPaid
+	default:
+		updated.Status = entity.BankTransferStatusOverpaid
+	}
+	return &updated
+}
+
+func TestBankTransferUseCase_IngestCredits(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	transfer := &entity.BankTransfer{
+		PaymentID: uuid.New(), Reference: "BT7K2M9QXP4R", VirtualAccount: "99001234567897",
+		AmountExpected: 10000, Currency: "USD", Status: entity.BankTransferStatusAwaitingFunds,
+		ExpiresAt: now.Add(time.Hour),
+	}
+	setup := func() (*MockBankTransferRepository, *MockBankCreditRepository, *MockPaymentUseCase, BankTransferUseCase) {
+		transfers := new(MockBankTransferRepository)
+		credits := new(MockBankCreditRepository)
+		payments := new(MockPaymentUseCase)
+		credits.On("GetByTransactionID", mock.Anything, mock.Anything).Return(nil, errors.New("bank credit not found"))
+		credits.On("Create", mock.Anything, mock.AnythingOfType("*entity.BankCredit")).Return(nil)
+		credits.On("Update", mock.Anything, mock.AnythingOfType("*entity.BankCredit")).Return(nil)
+		transfers.On("GetByReference", mock.Anything, transfer.Reference).Return(transfer, nil)
+		transfers.On("GetByReference", mock.Anything, mock.Anything).Return(nil, errors.New("bank transfer not found"))
+		transfers.On("GetByVirtualAccount", mock.Anything, transfer.VirtualAccount).Return(transfer, nil)
+		transfers.On("GetByVirtualAccount", mock.Anything, mock.Anything).Return(nil, errors.New("bank transfer not found"))
+		payments.On("GetPayment", mock.Anything, transfer.PaymentID).Return(&entity.Payment{ID: transfer.PaymentID, Status: entity.PaymentStatusPending}, nil).Maybe()
+		return transfers, credits, payments, newTestBankTransferUseCase(transfers, credits, payments, now)
+	}
+
+	t.Run("exact amount settles payment", func(t *testing.T) {
+		transfers, _, payments, useCase := setup()
+		transfers.On("ApplyCredit", ctx, transfer.PaymentID, int64(10000), now).Return(appliedTransfer(transfer, 10000), nil)
+		payments.On("SettlePayment", ctx, transfer.PaymentID).Return(nil)
+
+		credits, err := useCase.IngestCredits(ctx, []BankCreditRequest{{
+			TransactionID: "TX1", Reference: "order bt-7k2m 9qxp4r", Amount: 10000, Currency: "usd",
+		}})
+		require.NoError(t, err)
+		require.Len(t, credits, 1)
+		assert.Equal(t, entity.BankCreditStatusMatched, credits[0].Status)
+		assert.Equal(t, &transfer.PaymentID, credits[0].PaymentID)
+		assert.Empty(t, credits[0].Note)
+		assert.Equal(t, now, credits[0].ReceivedAt, "defaults to ingest time")
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("underpayment waits for the remainder", func(t *testing.T) {
+		transfers, _, payments, useCase := setup()
+		transfers.On("ApplyCredit", ctx, transfer.PaymentID, int64(4000), now).Return(appliedTransfer(transfer, 4000), nil)
+
+		credits, err := useCase.IngestCredits(ctx, []BankCreditRequest{{
+			TransactionID: "TX1", VirtualAccount: "9900 1234 5678 97", Amount: 4000, Currency: "USD",
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankCreditStatusMatched, credits[0].Status)
+		assert.Equal(t, "underpaid, 6000 still due", credits[0].Note)
+		payments.AssertNotCalled(t, "SettlePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("overpayment settles and flags refund", func(t *testing.T) {
+		transfers, _, payments, useCase := setup()
+		transfers.On("ApplyCredit", ctx, transfer.PaymentID, int64(12000), now).Return(appliedTransfer(transfer, 12000), nil)
+		payments.On("SettlePayment", ctx, transfer.PaymentID).Return(nil)
+
+		credits, err := useCase.IngestCredits(ctx, []BankCreditRequest{{
+			TransactionID: "TX1", Reference: "BT7K2M9QXP4R", Amount: 12000, Currency: "USD",
+		}})
+		require.NoError(t, err)
+		assert.Equal(t, entity.BankCreditStatusMatched, credits[0].Status)
+		assert.Equal(t, "overpaid by 2000, refund required", credits[0].Note)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("unmatched credits are kept for review", func(t *testing.T) {
+		transfers, _, payments, useCase := setup()
+		expired := *transfer
+		expired.Status = entity.BankTransferStatusExpired
+		transfers.On("ApplyCredit", ctx, transfer.PaymentID, mock.Anything, now).Return(nil, errors.New("bank transfer not found or no longer awaiting funds"))
+		transfers.On("GetByPaymentID", ctx, transfer.PaymentID).Return(&expired, nil)
+
+		credits, err := useCase.IngestCredits(ctx, []BankCreditRequest{
+			{TransactionID: "TX1", Reference: "rent october", Amount: 10000, Currency: "USD"},
+			{TransactionID: "TX2", Reference: "BT7K2M9QXP4R", Amount: 10000, Currency: "EUR"},
+			{TransactionID: "TX3", Reference: "BT7K2M9QXP4R", Amount: 10000, Currency: "USD"},
+		})
+		require.NoError(t, err)
+		require.Len(t, credits, 3)
+		for _, credit := range credits {
+			assert.Equal(t, entity.BankCreditStatusUnmatched, credit.Status)
+		}
+		assert.Nil(t, credits[0].PaymentID)
+		assert.Equal(t, "no bank transfer found for reference or account", credits[0].Note)
+		assert.Equal(t, &transfer.PaymentID, credits[1].PaymentID, "identified for manual handling")
+		assert.Contains(t, credits[1].Note, "currency EUR does not match")
+		assert.Equal(t, "bank transfer is expired, refund required", credits[2].Note)
+		payments.AssertNotCalled(t, "SettlePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate transaction is ingested once", func(t *testing.T) {
+		transfers := new(MockBankTransferRepository)
+		creditRepo := new(MockBankCreditRepository)
+		existing := &entity.BankCredit{ID: uuid.New(), TransactionID: "TX1", Status: entity.BankCreditStatusMatched}
+		creditRepo.On("GetByTransactionID", ctx, "TX1").Return(existing, nil)
+		useCase := newTestBankTransferUseCase(transfers, creditRepo, new(MockPaymentUseCase), now)
+
+		credits, err := useCase.IngestCredits(ctx, []BankCreditRequest{{TransactionID: "TX1", Amount: 10000, Currency: "USD"}})
+		require.NoError(t, err)
+		assert.Same(t, existing, credits[0])
+		creditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		transfers.AssertNotCalled(t, "ApplyCredit", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects invalid batch before ingesting", func(t *testing.T) {
+		creditRepo := new(MockBankCreditRepository)
+		useCase := newTestBankTransferUseCase(new(MockBankTransferRepository), creditRepo, new(MockPaymentUseCase), now)
+
+		_, err := useCase.IngestCredits(ctx, []BankCreditRequest{
+			{TransactionID: "TX1", Amount: 10000, Currency: "USD"},
+			{TransactionID: "TX2", Amount: 0, Currency: "USD"},
+		})
+		require.Error(t, err)
+		assert.Equal(t, "invalid_bank_credit", errors.Code(err))
+		assert.Contains(t, err.Error(), "credit 1: amount must be positive")
+		creditRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestBankTransferUseCase_ImportCSV(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	transfer := &entity.BankTransfer{
+		PaymentID: uuid.New(), Reference: "BT7K2M9QXP4R", AmountExpected: 10000, Currency: "USD",
+		Status: entity.BankTransferStatusAwaitingFunds, ExpiresAt: now.Add(time.Hour),
+	}
+	transfers := new(MockBankTransferRepository)
+	creditRepo := new(MockBankCreditRepository)
+	payments := new(MockPaymentUseCase)
+	creditRepo.On("GetByTransactionID", ctx, "TX1").Return(nil, errors.New("bank credit not found"))
+	creditRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankCredit")).Return(nil)
+	creditRepo.On("Update", ctx, mock.AnythingOfType("*entity.BankCredit")).Return(nil)
+	transfers.On("GetByReference", ctx, transfer.Reference).Return(transfer, nil)
+	transfers.On("ApplyCredit", ctx, transfer.PaymentID, int64(10000), now).Return(appliedTransfer(transfer, 10000), nil)
+	payments.On("GetPayment", ctx, transfer.PaymentID).Return(&entity.Payment{ID: transfer.PaymentID, Status: entity.PaymentStatusPending}, nil).Maybe()
+	payments.On("SettlePayment", ctx, transfer.PaymentID).Return(nil)
+	useCase := newTestBankTransferUseCase(transfers, creditRepo, payments, now)
+
+	credits, err := useCase.ImportCSV(ctx, strings.NewReader(
+		"transaction_id,date,amount,currency,reference\n"+
+			"TX1,2026-10-01,100.00,USD,BT7K2M9QXP4R\n"+
+			"TX2,2026-10-01,-5.00,USD,Fee\n"))
+	require.NoError(t, err)
+	require.Len(t, credits, 1)
+	assert.Equal(t, entity.BankCreditStatusMatched, credits[0].Status)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), credits[0].ReceivedAt)
+
+	_, err = useCase.ImportCSV(ctx, strings.NewReader("date,amount\n"))
+	assert.Equal(t, "invalid_bank_credit", errors.Code(err))
+}
+
+func TestBankTransferUseCase_ExpireDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	transfers := new(MockBankTransferRepository)
+	payments := new(MockPaymentUseCase)
+	expired := &entity.BankTransfer{PaymentID: uuid.New()}
+	raced := &entity.BankTransfer{PaymentID: uuid.New()}
+	partial := &entity.BankTransfer{PaymentID: uuid.New(), AmountReceived: 4000}
+	transfers.On("ListExpired", ctx, now, 100).Return([]*entity.BankTransfer{expired, raced, partial}, nil)
+	transfers.On("Expire", ctx, expired.PaymentID, now).Return(nil)
+	transfers.On("Expire", ctx, raced.PaymentID, now).Return(errors.New("bank transfer not found or no longer awaiting funds"))
+	transfers.On("Expire", ctx, partial.PaymentID, now).Return(nil)
+	payments.On("CancelPayment", ctx, expired.PaymentID).Return(nil)
+	payments.On("CancelPayment", ctx, partial.PaymentID).Return(errors.New("payment status is cancelled, cannot cancel"))
+
+	n, err := newTestBankTransferUseCase(transfers, new(MockBankCreditRepository), payments, now).ExpireDue(ctx, now, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+	payments.AssertExpectations(t)
+	payments.AssertNotCalled(t, "CancelPayment", ctx, raced.PaymentID)
+}
+
+func TestTransferReferenceAndAccount(t *testing.T) {
+	reference, err := newTransferReference()
+	require.NoError(t, err)
+	assert.Regexp(t, `^BT[A-HJ-NP-Z2-9]{10}$`, reference)
+	assert.Equal(t, reference, findTransferReference("Payment for order "+strings.ToLower(reference[:6])+" "+reference[6:]))
+	assert.Empty(t, findTransferReference("BT0000000000"), "ambiguous characters are never issued")
+
+	account, err := newVirtualAccount("9900")
+	require.NoError(t, err)
+	assert.Len(t, account, 15)
+	assert.True(t, strings.HasPrefix(account, "9900"))
+	assert.True(t, card.LuhnValid(account))
+}
+
+func TestPaymentUseCase_BankTransfer(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	t.Run("create issues transfer instructions", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		merchantRepo := new(MockMerchantRepository)
+		customerRepo := new(MockCustomerRepository)
+		transferRepo := new(MockBankTransferRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+		// 第一次產生的號碼重複時重新產生
+		transferRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankTransfer")).Return(errors.New("duplicate reference")).Once()
+		transferRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankTransfer")).Return(nil).Once()
+		useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{
+			ExpiresIn: 24 * time.Hour, AccountPrefix: "9900", BankName: "Example Bank",
+		}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+		payment, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+			MerchantID: merchantID, CustomerID: customerID, Amount: 10000, Currency: "USD", Method: entity.PaymentMethodBankTransfer,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusPending, payment.Status)
+		transfer := payment.BankTransfer
+		require.NotNil(t, transfer)
+		assert.Equal(t, payment.ID, transfer.PaymentID)
+		assert.Equal(t, int64(10000), transfer.AmountExpected)
+		assert.Equal(t, "Example Bank", transfer.BankName)
+		assert.True(t, strings.HasPrefix(transfer.VirtualAccount, "9900"))
+		assert.Equal(t, entity.BankTransferStatusAwaitingFunds, transfer.Status)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), transfer.ExpiresAt, time.Minute)
+		transferRepo.AssertExpectations(t)
+	})
+
+	t.Run("create cancels payment when instructions cannot be issued", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		merchantRepo := new(MockMerchantRepository)
+		customerRepo := new(MockCustomerRepository)
+		transferRepo := new(MockBankTransferRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+		paymentRepo.On("TransitionStatus", ctx, mock.AnythingOfType("uuid.UUID"), entity.PaymentStatusPending, entity.PaymentStatusCancelled).Return(nil)
+		transferRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankTransfer")).Return(errors.New("db down"))
+		useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+		_, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+			MerchantID: merchantID, CustomerID: customerID, Amount: 10000, Currency: "USD", Method: entity.PaymentMethodBankTransfer,
+		})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to issue bank transfer")
+		paymentRepo.AssertExpectations(t)
+		transferRepo.AssertNumberOfCalls(t, "Create", 3)
+	})
+
+	t.Run("process and submit are rejected", func(t *testing.T) {
+		paymentID := uuid.New()
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Method: entity.PaymentMethodBankTransfer, Status: entity.PaymentStatusPending}, nil)
+		useCase := NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+		err := useCase.ProcessPayment(ctx, paymentID)
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		_, err = useCase.SubmitPayment(ctx, paymentID)
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		paymentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+		paymentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("get attaches instructions", func(t *testing.T) {
+		paymentID := uuid.New()
+		paymentRepo := new(MockPaymentRepository)
+		transferRepo := new(MockBankTransferRepository)
+		transfer := &entity.BankTransfer{PaymentID: paymentID, Reference: "BT7K2M9QXP4R"}
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Method: entity.PaymentMethodBankTransfer}, nil)
+		transferRepo.On("GetByPaymentID", ctx, paymentID).Return(transfer, nil)
+		useCase := NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+		payment, err := useCase.GetPayment(ctx, paymentID)
+		require.NoError(t, err)
+		assert.Same(t, transfer, payment.BankTransfer)
+	})
+}
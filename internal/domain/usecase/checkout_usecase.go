@@ -0,0 +1,581 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 結帳有效期間的範圍與預設值
+const (
+	minCheckoutExpiry     = 30 * time.Minute
+	maxCheckoutExpiry     = 24 * time.Hour
+	defaultCheckoutExpiry = 24 * time.Hour
+)
+
+type CheckoutUseCase interface {
+	CreateSession(ctx context.Context, req CreateCheckoutSessionRequest) (*entity.CheckoutSession, error)
+	GetSession(ctx context.Context, merchantID, id uuid.UUID) (*entity.CheckoutSession, error)
+	// ExpireSession 讓商戶提前關閉尚未完成的結帳
+	ExpireSession(ctx context.Context, merchantID, id uuid.UUID) (*entity.CheckoutSession, error)
+
+	// GetHostedCheckout 供代管付款頁使用，不驗證商戶身分，逾期的結帳會轉為 expired
+	GetHostedCheckout(ctx context.Context, id uuid.UUID) (*HostedCheckout, error)
+	// CompleteSession 建立並處理付款，回傳導回 SuccessURL 的簽章網址
+	CompleteSession(ctx context.Context, req CompleteCheckoutRequest) (*CheckoutResult, error)
+	// CancelSession 取消結帳，回傳導回 CancelURL 的簽章網址
+	CancelSession(ctx context.Context, id uuid.UUID) (*CheckoutResult, error)
+}
+
+type CreateCheckoutSessionRequest struct {
+	MerchantID uuid.UUID `json:"-"`
+	// CustomerID 與 CustomerEmail 皆為空時，由付款頁要求客戶輸入 email
+	CustomerID     *uuid.UUID             `json:"customer_id"`
+	CustomerEmail  string                 `json:"customer_email"`
+	Amount         int64                  `json:"amount"`
+	Currency       string                 `json:"currency"`
+	Description    string                 `json:"description"`
+	Reference      string                 `json:"reference"`
+	AllowedMethods []entity.PaymentMethod `json:"allowed_methods"`
+	SuccessURL     string                 `json:"success_url"`
+	CancelURL      string                 `json:"cancel_url"`
+	// ExpiresAt 必須在 30 分鐘到 24 小時之後，未填時為 24 小時
+	ExpiresAt *time.Time `json:"expires_at"`
+	// PaymentLinkID 由付款連結設定，API 不接受此欄位
+	PaymentLinkID *uuid.UUID `json:"-"`
+}
+
+// CompleteCheckoutRequest 來自付款頁的表單；Card 只在信用卡付款時使用
+type CompleteCheckoutRequest struct {
+	SessionID uuid.UUID
+	Method    entity.PaymentMethod
+	Email     string `redact:"email"`
+	Name      string `redact:"name"`
+	Card      TokenizeCardRequest
+	// ClientIP 為付款人的 IP，用於風險評估
+	ClientIP string
+}
+
+// HostedCheckout 為付款頁顯示所需的結帳與商戶資料
+type HostedCheckout struct {
+	Session  *entity.CheckoutSession
+	Merchant *entity.Merchant
+	// Payment 為已完成結帳建立的付款，用來再次顯示銀行轉帳的匯款資訊
+	Payment *entity.Payment
+}
+
+type CheckoutResult struct {
+	Session *entity.CheckoutSession
+	// Payment 在取消時為 nil
+	Payment     *entity.Payment
+	RedirectURL string
+}
+
+type checkoutUseCase struct {
+	sessionRepo    repository.CheckoutSessionRepository
+	linkRepo       repository.PaymentLinkRepository
+	merchantRepo   repository.MerchantRepository
+	customerRepo   repository.CustomerRepository
+	paymentUseCase PaymentUseCase
+	vaultUseCase   VaultUseCase
+	baseURL        string
+}
+
+// NewCheckoutUseCase 建立結帳流程；baseURL 為服務對外網址，用於組成付款頁網址
+func NewCheckoutUseCase(
+	sessionRepo repository.CheckoutSessionRepository,
+	linkRepo repository.PaymentLinkRepository,
+	merchantRepo repository.MerchantRepository,
+	customerRepo repository.CustomerRepository,
+	paymentUseCase PaymentUseCase,
+	vaultUseCase VaultUseCase,
+	baseURL string,
+) CheckoutUseCase {
+	return &checkoutUseCase{
+		sessionRepo:    sessionRepo,
+		linkRepo:       linkRepo,
+		merchantRepo:   merchantRepo,
+		customerRepo:   customerRepo,
+		paymentUseCase: paymentUseCase,
+		vaultUseCase:   vaultUseCase,
+		baseURL:        strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (uc *checkoutUseCase) CreateSession(ctx context.Context, req CreateCheckoutSessionRequest) (*entity.CheckoutSession, error) {
+	if req.Amount <= 0 {
+		return nil, invalidCheckout("amount must be positive")
+	}
+	if len(req.Currency) != 3 {
+		return nil, invalidCheckout("currency must be a 3-letter code")
+	}
+	if err := validateRedirectURL(req.SuccessURL); err != nil {
+		return nil, invalidCheckout("invalid success_url: " + err.Error())
+	}
+	if err := validateRedirectURL(req.CancelURL); err != nil {
+		return nil, invalidCheckout("invalid cancel_url: " + err.Error())
+	}
+	methods, err := allowedMethods(req.AllowedMethods)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(defaultCheckoutExpiry)
+	if req.ExpiresAt != nil {
+		if req.ExpiresAt.Before(now.Add(minCheckoutExpiry)) || req.ExpiresAt.After(now.Add(maxCheckoutExpiry)) {
+			return nil, invalidCheckout("expires_at must be between 30 minutes and 24 hours from now")
+		}
+		expiresAt = *req.ExpiresAt
+	}
+
+	email := strings.TrimSpace(req.CustomerEmail)
+	if req.CustomerID != nil {
+		customer, err := uc.customerRepo.GetByID(ctx, *req.CustomerID)
+		if err != nil {
+			return nil, errors.WithCode(errors.Wrap(err, "failed to get customer"), "invalid_checkout")
+		}
+		email = customer.Email
+	} else if email != "" && !validEmail(email) {
+		return nil, invalidCheckout("invalid customer_email")
+	}
+
+	session := &entity.CheckoutSession{
+		ID:             uuid.New(),
+		MerchantID:     req.MerchantID,
+		CustomerID:     req.CustomerID,
+		CustomerEmail:  email,
+		Amount:         req.Amount,
+		Currency:       strings.ToUpper(req.Currency),
+		Description:    req.Description,
+		Reference:      req.Reference,
+		Status:         entity.CheckoutSessionStatusOpen,
+		AllowedMethods: methods,
+		SuccessURL:     req.SuccessURL,
+		CancelURL:      req.CancelURL,
+		PaymentLinkID:  req.PaymentLinkID,
+		ExpiresAt:      expiresAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := uc.sessionRepo.Create(ctx, session); err != nil {
+		return nil, errors.Wrap(err, "failed to create checkout session")
+	}
+
+	logger.FromContext(ctx).Info("checkout session created",
+		zap.String("checkout_session_id", session.ID.String()),
+		zap.Int64("amount", session.Amount),
+		zap.String("currency", session.Currency),
+	)
+	return uc.withURL(session), nil
+}
+
+func (uc *checkoutUseCase) GetSession(ctx context.Context, merchantID, id uuid.UUID) (*entity.CheckoutSession, error) {
+	session, err := uc.getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("checkout session not found"), "not_found")
+	}
+	return uc.withURL(session), nil
+}
+
+func (uc *checkoutUseCase) ExpireSession(ctx context.Context, merchantID, id uuid.UUID) (*entity.CheckoutSession, error) {
+	session, err := uc.GetSession(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != entity.CheckoutSessionStatusOpen {
+		return nil, invalidCheckout(fmt.Sprintf("checkout session is %s", session.Status))
+	}
+	if err := uc.transition(ctx, session, entity.CheckoutSessionStatusOpen, entity.CheckoutSessionStatusExpired); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+func (uc *checkoutUseCase) GetHostedCheckout(ctx context.Context, id uuid.UUID) (*HostedCheckout, error) {
+	session, err := uc.getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	merchant, err := uc.merchantRepo.GetByID(ctx, session.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+	checkout := &HostedCheckout{Session: uc.withURL(session), Merchant: merchant}
+	if session.Status == entity.CheckoutSessionStatusComplete && session.PaymentID != nil {
+		if payment, err := uc.paymentUseCase.GetPayment(ctx, *session.PaymentID); err == nil {
+			checkout.Payment = payment
+		}
+	}
+	return checkout, nil
+}
+
+func (uc *checkoutUseCase) CompleteSession(ctx context.Context, req CompleteCheckoutRequest) (*CheckoutResult, error) {
+	session, err := uc.getSession(ctx, req.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != entity.CheckoutSessionStatusOpen {
+		return nil, invalidCheckout(fmt.Sprintf("checkout session is %s", session.Status))
+	}
+	if !session.Allows(req.Method) {
+		return nil, invalidCheckout("payment method is not allowed for this checkout")
+	}
+	merchant, err := uc.merchantRepo.GetByID(ctx, session.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+
+	customerID, err := uc.resolveCustomer(ctx, session, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先佔用結帳再儲存卡片與建立付款，重複送出時只有一次會繼續，不會留下多餘的卡片或付款
+	if err := uc.transition(ctx, session, entity.CheckoutSessionStatusOpen, entity.CheckoutSessionStatusProcessing); err != nil {
+		return nil, err
+	}
+
+	var token string
+	if req.Method == entity.PaymentMethodCreditCard {
+		card := req.Card
+		card.MerchantID = session.MerchantID
+		c, err := uc.vaultUseCase.TokenizeCard(ctx, card)
+		if err != nil {
+			// 卡片錯誤已帶有 invalid_card 代碼，直接回傳；重新開啟結帳讓客戶修正後再送出
+			uc.reopen(ctx, session)
+			return nil, err
+		}
+		token = c.Token
+	}
+
+	// 付款連結的次數上限在此佔用，付款建立失敗時在此歸還；付款之後失敗或取消時由付款流程歸還
+	if session.PaymentLinkID != nil {
+		if err := uc.linkRepo.ClaimPayment(ctx, *session.PaymentLinkID, time.Now()); err != nil {
+			uc.reopen(ctx, session)
+			return nil, errors.WithCode(errors.Wrap(err, "this payment link is no longer available"), "invalid_checkout")
+		}
+	}
+
+	payment, err := uc.paymentUseCase.CreatePayment(ctx, CreatePaymentRequest{
+		MerchantID:         session.MerchantID,
+		CustomerID:         customerID,
+		Amount:             session.Amount,
+		Currency:           session.Currency,
+		Method:             req.Method,
+		Description:        session.Description,
+		Reference:          session.Reference,
+		PaymentMethodToken: token,
+		PaymentLinkID:      session.PaymentLinkID,
+		ClientIP:           req.ClientIP,
+	})
+	if err != nil {
+		uc.releaseLink(ctx, session)
+		uc.reopen(ctx, session)
+		return nil, errors.Wrap(err, "failed to create payment")
+	}
+
+	// 處理期間結帳逾期時改為取消付款，取消時一併歸還付款連結次數
+	now := time.Now()
+	session.CustomerID = &customerID
+	session.PaymentID = &payment.ID
+	session.CompletedAt = &now
+	if err := uc.transition(ctx, session, entity.CheckoutSessionStatusProcessing, entity.CheckoutSessionStatusComplete); err != nil {
+		if cancelErr := uc.paymentUseCase.CancelPayment(ctx, payment.ID); cancelErr != nil {
+			logger.FromContext(ctx).Error("failed to cancel payment for closed checkout session",
+				zap.String("checkout_session_id", session.ID.String()),
+				zap.String("payment_id", payment.ID.String()),
+				zap.Error(cancelErr),
+			)
+		}
+		return nil, err
+	}
+
+	// 銀行轉帳在收到款項時才完成，付款頁改為顯示匯款資訊；
+	// 數位錢包在客戶於錢包確認後才完成；風險審核中的付款等商戶核准後再處理
+	if payment.Method != entity.PaymentMethodBankTransfer && payment.Status == entity.PaymentStatusPending {
+		if err := uc.paymentUseCase.ProcessPayment(ctx, payment.ID); err != nil {
+			logger.FromContext(ctx).Error("failed to process checkout payment",
+				zap.String("checkout_session_id", session.ID.String()),
+				zap.String("payment_id", payment.ID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+	if processed, err := uc.paymentUseCase.GetPayment(ctx, payment.ID); err == nil {
+		payment = processed
+	}
+
+	redirect, err := signedRedirect(session.SuccessURL, CheckoutRedirectSecret(merchant.APIKey), session, payment)
+	if err != nil {
+		return nil, err
+	}
+	// 先導向錢包確認，確認後由錢包導回商戶；商戶收到的 payment_status 為 requires_action
+	if next := payment.NextAction; next != nil && next.RedirectURL != "" {
+		if redirect, err = withReturnURL(next.RedirectURL, redirect); err != nil {
+			return nil, err
+		}
+	}
+	return &CheckoutResult{Session: uc.withURL(session), Payment: payment, RedirectURL: redirect}, nil
+}
+
+func (uc *checkoutUseCase) CancelSession(ctx context.Context, id uuid.UUID) (*CheckoutResult, error) {
+	session, err := uc.getSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	switch session.Status {
+	case entity.CheckoutSessionStatusOpen:
+		if err := uc.transition(ctx, session, entity.CheckoutSessionStatusOpen, entity.CheckoutSessionStatusCanceled); err != nil {
+			return nil, err
+		}
+	case entity.CheckoutSessionStatusProcessing, entity.CheckoutSessionStatusComplete:
+		return nil, invalidCheckout(fmt.Sprintf("checkout session is %s", session.Status))
+	}
+	// 已取消或逾期的結帳仍可導回商戶
+	merchant, err := uc.merchantRepo.GetByID(ctx, session.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+	redirect, err := signedRedirect(session.CancelURL, CheckoutRedirectSecret(merchant.APIKey), session, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &CheckoutResult{Session: uc.withURL(session), RedirectURL: redirect}, nil
+}
+
+// getSession 讀取結帳，開啟中或處理中但已逾期的結帳會先轉為 expired，
+// 處理中斷而停在 processing 的結帳也因此會在逾期後關閉
+func (uc *checkoutUseCase) getSession(ctx context.Context, id uuid.UUID) (*entity.CheckoutSession, error) {
+	session, err := uc.sessionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get checkout session"), "not_found")
+	}
+	switch session.Status {
+	case entity.CheckoutSessionStatusOpen, entity.CheckoutSessionStatusProcessing:
+	default:
+		return session, nil
+	}
+	if time.Now().Before(session.ExpiresAt) {
+		return session, nil
+	}
+
+	if err := uc.transition(ctx, session, session.Status, entity.CheckoutSessionStatusExpired); err != nil {
+		// 同時被完成或取消時以最新狀態為準
+		session, err = uc.sessionRepo.GetByID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get checkout session")
+		}
+	}
+	return session, nil
+}
+
+// transition 將狀態為 from 的結帳轉為 to，狀態已被其他請求變更時回傳 invalid_checkout
+func (uc *checkoutUseCase) transition(ctx context.Context, session *entity.CheckoutSession, from, to entity.CheckoutSessionStatus) error {
+	session.Status = to
+	session.UpdatedAt = time.Now()
+	if err := uc.sessionRepo.Transition(ctx, session, from); err != nil {
+		return errors.WithCode(errors.Wrap(err, fmt.Sprintf("checkout session is no longer %s", from)), "invalid_checkout")
+	}
+
+	logger.FromContext(ctx).Info("checkout session updated",
+		zap.String("checkout_session_id", session.ID.String()),
+		zap.String("status", string(to)),
+	)
+	return nil
+}
+
+// reopen 在付款建立前失敗時把處理中的結帳轉回 open，讓客戶可以再次送出；失敗只記錄，
+// 結帳會在逾期後關閉
+func (uc *checkoutUseCase) reopen(ctx context.Context, session *entity.CheckoutSession) {
+	if err := uc.transition(ctx, session, entity.CheckoutSessionStatusProcessing, entity.CheckoutSessionStatusOpen); err != nil {
+		logger.FromContext(ctx).Error("failed to reopen checkout session",
+			zap.String("checkout_session_id", session.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// releaseLink 歸還 CompleteSession 佔用的付款連結次數，失敗只記錄
+func (uc *checkoutUseCase) releaseLink(ctx context.Context, session *entity.CheckoutSession) {
+	if session.PaymentLinkID == nil {
+		return
+	}
+	if err := uc.linkRepo.ReleasePayment(ctx, *session.PaymentLinkID); err != nil {
+		logger.FromContext(ctx).Error("failed to release payment link",
+			zap.String("checkout_session_id", session.ID.String()),
+			zap.String("payment_link_id", session.PaymentLinkID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// resolveCustomer 優先使用結帳指定的客戶，否則以 email 找出或建立客戶
+func (uc *checkoutUseCase) resolveCustomer(ctx context.Context, session *entity.CheckoutSession, req CompleteCheckoutRequest) (uuid.UUID, error) {
+	if session.CustomerID != nil {
+		return *session.CustomerID, nil
+	}
+
+	email := session.CustomerEmail
+	if email == "" {
+		email = strings.TrimSpace(req.Email)
+	}
+	if !validEmail(email) {
+		return uuid.Nil, invalidCheckout("a valid email is required")
+	}
+	customer, err := findOrCreateCustomer(ctx, uc.customerRepo, email, req.Name)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return customer.ID, nil
+}
+
+// findOrCreateCustomer 以 email 找出既有客戶，不存在時建立，name 為空時以 email 作為名稱
+func findOrCreateCustomer(ctx context.Context, customerRepo repository.CustomerRepository, email, name string) (*entity.Customer, error) {
+	if customer, err := customerRepo.GetByEmail(ctx, email); err == nil {
+		return customer, nil
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = email
+	}
+	now := time.Now()
+	customer := &entity.Customer{
+		ID:        uuid.New(),
+		Name:      name,
+		Email:     email,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := customerRepo.Create(ctx, customer); err != nil {
+		return nil, errors.Wrap(err, "failed to create customer")
+	}
+	return customer, nil
+}
+
+func (uc *checkoutUseCase) withURL(session *entity.CheckoutSession) *entity.CheckoutSession {
+	if uc.baseURL != "" {
+		session.URL = uc.baseURL + "/checkout/" + session.ID.String()
+	}
+	return session
+}
+
+// allowedMethods 檢查並去除重複的付款方式，未指定時允許所有付款方式
+func allowedMethods(methods []entity.PaymentMethod) ([]entity.PaymentMethod, error) {
+	if len(methods) == 0 {
+		return []entity.PaymentMethod{
+			entity.PaymentMethodCreditCard,
+			entity.PaymentMethodBankTransfer,
+			entity.PaymentMethodDigitalWallet,
+		}, nil
+	}
+
+	var result []entity.PaymentMethod
+	seen := make(map[entity.PaymentMethod]bool)
+	for _, m := range methods {
+		switch m {
+		case entity.PaymentMethodCreditCard, entity.PaymentMethodBankTransfer, entity.PaymentMethodDigitalWallet:
+		default:
+			return nil, invalidCheckout(fmt.Sprintf("unsupported payment method %q", m))
+		}
+		if !seen[m] {
+			seen[m] = true
+			result = append(result, m)
+		}
+	}
+	return result, nil
+}
+
+func validateRedirectURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func validEmail(email string) bool {
+	at := strings.LastIndex(email, "@")
+	return at > 0 && at < len(email)-1 && !strings.ContainsAny(email, " \t\r\n")
+}
+
+// signedRedirect 在商戶網址附加結帳結果與簽章，保留網址原有的查詢參數
+func signedRedirect(rawURL, secret string, session *entity.CheckoutSession, payment *entity.Payment) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid redirect url")
+	}
+
+	var paymentID, paymentStatus string
+	if payment != nil {
+		paymentID = payment.ID.String()
+		paymentStatus = string(payment.Status)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	q := u.Query()
+	q.Set("checkout_session_id", session.ID.String())
+	q.Set("status", string(session.Status))
+	if payment != nil {
+		q.Set("payment_id", paymentID)
+		q.Set("payment_status", paymentStatus)
+	}
+	q.Set("timestamp", timestamp)
+	q.Set("signature", CheckoutSignature(secret, session.ID.String(), string(session.Status), paymentID, paymentStatus, timestamp))
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// withReturnURL 在錢包確認頁加上確認後返回的網址
+func withReturnURL(rawURL, returnURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", errors.Wrap(err, "invalid wallet redirect url")
+	}
+	q := u.Query()
+	q.Set("return_url", returnURL)
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+// CheckoutRedirectSecret 由商戶 API key 衍生導回網址的簽章金鑰：對 "checkout_redirect" 以
+// API key 做 HMAC-SHA256，以十六進位表示。商戶把衍生的金鑰交給驗證導回結果的前端或服務，
+// 不必交出可呼叫 API 的 API key
+func CheckoutRedirectSecret(apiKey string) string {
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write([]byte("checkout_redirect"))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckoutSignature 計算導回網址的簽章：以 CheckoutRedirectSecret 衍生的金鑰，對
+// "checkout_session_id.status.payment_id.payment_status.timestamp" 做 HMAC-SHA256，
+// 以十六進位表示；沒有付款時 payment_id 與 payment_status 為空字串
+func CheckoutSignature(secret, sessionID, status, paymentID, paymentStatus, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{sessionID, status, paymentID, paymentStatus, timestamp}, ".")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func invalidCheckout(msg string) error {
+	return errors.WithCode(errors.New(msg), "invalid_checkout")
+}
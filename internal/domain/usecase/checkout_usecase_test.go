@@ -0,0 +1,430 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockCheckoutSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockCheckoutSessionRepository) Create(ctx context.Context, session *entity.CheckoutSession) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockCheckoutSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CheckoutSession, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.CheckoutSession), args.Error(1)
+}
+
+func (m *MockCheckoutSessionRepository) Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) error {
+	args := m.Called(ctx, session, from)
+	return args.Error(0)
+}
+
+// newTestCheckoutUseCase 建立屬於 merchant 的結帳 use case，卡片以 reverseCipher 加密
+func newTestCheckoutUseCase(sessionRepo *MockCheckoutSessionRepository, linkRepo *MockPaymentLinkRepository, customerRepo *MockCustomerRepository, cardRepo *MockCardRepository, payments *MockPaymentUseCase, merchant *entity.Merchant) CheckoutUseCase {
+	merchantRepo := new(MockMerchantRepository)
+	merchantRepo.On("GetByID", mock.Anything, merchant.ID).Return(merchant, nil)
+	return NewCheckoutUseCase(sessionRepo, linkRepo, merchantRepo, customerRepo, payments, NewVaultUseCase(cardRepo, reverseCipher{}), "https://pay.example.com/")
+}
+
+// newCheckoutSession 回傳一小時後逾期、只接受信用卡的 open 結帳
+func newCheckoutSession(merchantID uuid.UUID) *entity.CheckoutSession {
+	return &entity.CheckoutSession{
+		ID: uuid.New(), MerchantID: merchantID, Amount: 2500, Currency: "USD", Reference: "ORDER_42",
+		Status:         entity.CheckoutSessionStatusOpen,
+		AllowedMethods: []entity.PaymentMethod{entity.PaymentMethodCreditCard},
+		SuccessURL:     "https://shop.example.com/done?order=42",
+		CancelURL:      "https://shop.example.com/cart",
+		ExpiresAt:      time.Now().Add(time.Hour),
+	}
+}
+
+func TestCheckoutUseCase_CreateSession(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+	valid := func() CreateCheckoutSessionRequest {
+		return CreateCheckoutSessionRequest{
+			Amount: 2500, Currency: "usd",
+			SuccessURL: "https://shop.example.com/done", CancelURL: "https://shop.example.com/cart",
+		}
+	}
+	tooSoon := time.Now().Add(10 * time.Minute)
+
+	tests := []struct {
+		name          string
+		modify        func(req *CreateCheckoutSessionRequest)
+		expectedError string
+	}{
+		{name: "relative success url", modify: func(req *CreateCheckoutSessionRequest) { req.SuccessURL = "/done" }, expectedError: "invalid success_url"},
+		{name: "javascript cancel url", modify: func(req *CreateCheckoutSessionRequest) { req.CancelURL = "javascript:alert(1)" }, expectedError: "invalid cancel_url"},
+		{name: "unknown method", modify: func(req *CreateCheckoutSessionRequest) { req.AllowedMethods = []entity.PaymentMethod{"cash"} }, expectedError: "unsupported payment method"},
+		{name: "expiry too soon", modify: func(req *CreateCheckoutSessionRequest) { req.ExpiresAt = &tooSoon }, expectedError: "expires_at"},
+		{name: "invalid email", modify: func(req *CreateCheckoutSessionRequest) { req.CustomerEmail = "not-an-email" }, expectedError: "invalid customer_email"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := newTestCheckoutUseCase(new(MockCheckoutSessionRepository), new(MockPaymentLinkRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentUseCase), merchant)
+			req := valid()
+			tt.modify(&req)
+			_, err := useCase.CreateSession(ctx, req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Equal(t, "invalid_checkout", errors.Code(err))
+		})
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentUseCase), merchant)
+		sessionRepo.On("Create", ctx, mock.AnythingOfType("*entity.CheckoutSession")).Return(nil)
+		req := valid()
+		req.MerchantID = merchant.ID
+		req.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodDigitalWallet, entity.PaymentMethodDigitalWallet}
+
+		session, err := useCase.CreateSession(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, entity.CheckoutSessionStatusOpen, session.Status)
+		assert.Equal(t, "USD", session.Currency)
+		assert.Equal(t, []entity.PaymentMethod{entity.PaymentMethodDigitalWallet}, session.AllowedMethods)
+		assert.WithinDuration(t, time.Now().Add(24*time.Hour), session.ExpiresAt, time.Minute)
+		assert.Equal(t, "https://pay.example.com/checkout/"+session.ID.String(), session.URL)
+	})
+}
+
+func TestCheckoutUseCase_CompleteSession(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+	sessionRepo := new(MockCheckoutSessionRepository)
+	customerRepo := new(MockCustomerRepository)
+	cardRepo := new(MockCardRepository)
+	payments := new(MockPaymentUseCase)
+	useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, cardRepo, payments, merchant)
+	session := newCheckoutSession(merchant.ID)
+	sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+
+	// 以 email 找不到客戶時建立新客戶
+	customerRepo.On("GetByEmail", ctx, "buyer@example.com").Return(nil, errors.New("customer not found"))
+	var customer *entity.Customer
+	customerRepo.On("Create", ctx, mock.AnythingOfType("*entity.Customer")).Run(func(args mock.Arguments) {
+		customer = args.Get(1).(*entity.Customer)
+	}).Return(nil)
+	cardRepo.On("Create", ctx, mock.AnythingOfType("*entity.Card")).Return(nil)
+
+	payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+	payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+		return req.MerchantID == merchant.ID && req.CustomerID == customer.ID &&
+			req.Amount == 2500 && req.Currency == "USD" && req.Reference == "ORDER_42" &&
+			req.Method == entity.PaymentMethodCreditCard && req.PaymentMethodToken != ""
+	})).Return(payment, nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(nil)
+	payments.On("ProcessPayment", ctx, payment.ID).Return(nil)
+	payments.On("GetPayment", ctx, payment.ID).Return(&entity.Payment{ID: payment.ID, Status: entity.PaymentStatusCompleted}, nil)
+
+	result, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{
+		SessionID: session.ID,
+		Method:    entity.PaymentMethodCreditCard,
+		Email:     " buyer@example.com ",
+		Name:      "Buyer",
+		Card:      TokenizeCardRequest{Number: "4111 1111 1111 1111", ExpMonth: 12, ExpYear: time.Now().Year() + 2, CVV: "123"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Buyer", customer.Name)
+	assert.Equal(t, entity.CheckoutSessionStatusComplete, result.Session.Status)
+	assert.Equal(t, &payment.ID, result.Session.PaymentID)
+	assert.Equal(t, &customer.ID, result.Session.CustomerID)
+
+	redirect, err := url.Parse(result.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "shop.example.com", redirect.Host)
+	q := redirect.Query()
+	assert.Equal(t, "42", q.Get("order"), "existing query parameters are kept")
+	assert.Equal(t, "complete", q.Get("status"))
+	assert.Equal(t, "completed", q.Get("payment_status"))
+	assert.Equal(t, payment.ID.String(), q.Get("payment_id"))
+	assert.Equal(t,
+		CheckoutSignature(CheckoutRedirectSecret("api_key_test"), session.ID.String(), "complete", payment.ID.String(), "completed", q.Get("timestamp")),
+		q.Get("signature"))
+	assert.NotEqual(t,
+		CheckoutSignature(CheckoutRedirectSecret("api_key_other"), session.ID.String(), "complete", payment.ID.String(), "completed", q.Get("timestamp")),
+		q.Get("signature"))
+	assert.NotEqual(t,
+		CheckoutSignature("api_key_test", session.ID.String(), "complete", payment.ID.String(), "completed", q.Get("timestamp")),
+		q.Get("signature"), "the API key itself is not the signing key")
+}
+
+func TestCheckoutUseCase_CompleteSessionBankTransfer(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+	sessionRepo := new(MockCheckoutSessionRepository)
+	customerRepo := new(MockCustomerRepository)
+	payments := new(MockPaymentUseCase)
+	useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, new(MockCardRepository), payments, merchant)
+	session := newCheckoutSession(merchant.ID)
+	session.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodBankTransfer}
+	customerID := uuid.New()
+	session.CustomerID = &customerID
+	sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+	customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil).Maybe()
+
+	transfer := &entity.BankTransfer{Reference: "BT7K2M9QXP4R", Status: entity.BankTransferStatusAwaitingFunds}
+	payment := &entity.Payment{ID: uuid.New(), Method: entity.PaymentMethodBankTransfer, Status: entity.PaymentStatusPending, BankTransfer: transfer}
+	payments.On("CreatePayment", ctx, mock.AnythingOfType("usecase.CreatePaymentRequest")).Return(payment, nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(nil)
+	payments.On("GetPayment", ctx, payment.ID).Return(payment, nil)
+
+	result, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodBankTransfer})
+	require.NoError(t, err)
+	assert.Same(t, transfer, result.Payment.BankTransfer)
+	payments.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
+
+	redirect, err := url.Parse(result.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "pending", redirect.Query().Get("payment_status"))
+
+	// 再次開啟付款頁時帶出付款以顯示匯款資訊
+	checkout, err := useCase.GetHostedCheckout(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Same(t, payment, checkout.Payment)
+}
+
+func TestCheckoutUseCase_CompleteSessionDigitalWallet(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+	sessionRepo := new(MockCheckoutSessionRepository)
+	customerRepo := new(MockCustomerRepository)
+	payments := new(MockPaymentUseCase)
+	useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, new(MockCardRepository), payments, merchant)
+	session := newCheckoutSession(merchant.ID)
+	session.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodDigitalWallet}
+	customerID := uuid.New()
+	session.CustomerID = &customerID
+	sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+	customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil).Maybe()
+
+	payment := &entity.Payment{
+		ID: uuid.New(), Method: entity.PaymentMethodDigitalWallet, Status: entity.PaymentStatusRequiresAction,
+		NextAction: &entity.NextAction{Type: entity.NextActionRedirectToURL, RedirectURL: "https://wallet.example.com/pay?payment_id=42"},
+	}
+	payments.On("CreatePayment", ctx, mock.AnythingOfType("usecase.CreatePaymentRequest")).Return(payment, nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(nil)
+	payments.On("GetPayment", ctx, payment.ID).Return(payment, nil)
+
+	result, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodDigitalWallet})
+	require.NoError(t, err)
+	payments.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
+
+	// 先導向錢包，確認後回到商戶
+	redirect, err := url.Parse(result.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "wallet.example.com", redirect.Host)
+	assert.Equal(t, "42", redirect.Query().Get("payment_id"))
+	returnURL, err := url.Parse(redirect.Query().Get("return_url"))
+	require.NoError(t, err)
+	assert.Equal(t, "shop.example.com", returnURL.Host)
+	assert.Equal(t, "requires_action", returnURL.Query().Get("payment_status"))
+}
+
+func TestCheckoutUseCase_CompleteSessionRejected(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+
+	t.Run("method not allowed", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), new(MockCustomerRepository), new(MockCardRepository), payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodBankTransfer, Email: "a@example.com"})
+		assert.Equal(t, "invalid_checkout", errors.Code(err))
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("expired session", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentUseCase), merchant)
+		session := newCheckoutSession(merchant.ID)
+		session.ExpiresAt = time.Now().Add(-time.Minute)
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodCreditCard})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "checkout session is expired")
+		assert.Equal(t, entity.CheckoutSessionStatusExpired, session.Status)
+	})
+
+	t.Run("concurrent submit loses the claim", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		customerRepo := new(MockCustomerRepository)
+		cardRepo := new(MockCardRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, cardRepo, payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		customerID := uuid.New()
+		session.CustomerID = &customerID
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil).Maybe()
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(errors.New("checkout session not found or no longer open"))
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{
+			SessionID: session.ID,
+			Method:    entity.PaymentMethodCreditCard,
+			Card:      TokenizeCardRequest{Number: "4111 1111 1111 1111", ExpMonth: 12, ExpYear: time.Now().Year() + 2, CVV: "123"},
+		})
+		assert.Equal(t, "invalid_checkout", errors.Code(err))
+		cardRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("invalid card reopens the session", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		customerRepo := new(MockCustomerRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, new(MockCardRepository), payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		customerID := uuid.New()
+		session.CustomerID = &customerID
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil).Maybe()
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(nil)
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{
+			SessionID: session.ID,
+			Method:    entity.PaymentMethodCreditCard,
+			Card:      TokenizeCardRequest{Number: "4111 1111 1111 1112", ExpMonth: 12, ExpYear: time.Now().Year() + 2, CVV: "123"},
+		})
+		assert.Equal(t, "invalid_card", errors.Code(err))
+		assert.Equal(t, entity.CheckoutSessionStatusOpen, session.Status)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("session closed while processing cancels the payment", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		customerRepo := new(MockCustomerRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), customerRepo, new(MockCardRepository), payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		session.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodBankTransfer}
+		customerID := uuid.New()
+		session.CustomerID = &customerID
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil).Maybe()
+
+		payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+		payments.On("CreatePayment", ctx, mock.AnythingOfType("usecase.CreatePaymentRequest")).Return(payment, nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(errors.New("checkout session not found or no longer processing"))
+		payments.On("CancelPayment", ctx, payment.ID).Return(nil)
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodBankTransfer})
+		assert.Equal(t, "invalid_checkout", errors.Code(err))
+		payments.AssertCalled(t, "CancelPayment", ctx, payment.ID)
+		payments.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCheckoutUseCase_CompleteSessionPaymentLink(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+
+	t.Run("link no longer available", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		linkRepo := new(MockPaymentLinkRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, linkRepo, new(MockCustomerRepository), new(MockCardRepository), payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		linkID := uuid.New()
+		session.PaymentLinkID = &linkID
+		session.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodBankTransfer}
+		customerID := uuid.New()
+		session.CustomerID = &customerID
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(nil)
+		linkRepo.On("ClaimPayment", ctx, linkID, mock.AnythingOfType("time.Time")).Return(errors.New("payment link not found or no longer available"))
+
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodBankTransfer})
+		require.Error(t, err)
+		assert.Equal(t, "invalid_checkout", errors.Code(err))
+		assert.Contains(t, err.Error(), "no longer available")
+		assert.Equal(t, entity.CheckoutSessionStatusOpen, session.Status)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("closed session leaves the slot to the payment cancellation", func(t *testing.T) {
+		sessionRepo := new(MockCheckoutSessionRepository)
+		linkRepo := new(MockPaymentLinkRepository)
+		payments := new(MockPaymentUseCase)
+		useCase := newTestCheckoutUseCase(sessionRepo, linkRepo, new(MockCustomerRepository), new(MockCardRepository), payments, merchant)
+		session := newCheckoutSession(merchant.ID)
+		linkID := uuid.New()
+		session.PaymentLinkID = &linkID
+		session.AllowedMethods = []entity.PaymentMethod{entity.PaymentMethodBankTransfer}
+		customerID := uuid.New()
+		session.CustomerID = &customerID
+		sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+		linkRepo.On("ClaimPayment", ctx, linkID, mock.AnythingOfType("time.Time")).Return(nil)
+
+		payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+		payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+			return req.PaymentLinkID != nil && *req.PaymentLinkID == linkID
+		})).Return(payment, nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+		sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusProcessing).Return(errors.New("checkout session not found or no longer processing"))
+		payments.On("CancelPayment", ctx, payment.ID).Return(nil)
+
+		// 取消付款時由付款流程歸還次數，結帳不再重複歸還
+		_, err := useCase.CompleteSession(ctx, CompleteCheckoutRequest{SessionID: session.ID, Method: entity.PaymentMethodBankTransfer})
+		assert.Equal(t, "invalid_checkout", errors.Code(err))
+		payments.AssertCalled(t, "CancelPayment", ctx, payment.ID)
+		linkRepo.AssertNotCalled(t, "ReleasePayment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestCheckoutUseCase_CancelSession(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", APIKey: "api_key_test", IsActive: true}
+	sessionRepo := new(MockCheckoutSessionRepository)
+	useCase := newTestCheckoutUseCase(sessionRepo, new(MockPaymentLinkRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentUseCase), merchant)
+	session := newCheckoutSession(merchant.ID)
+	sessionRepo.On("GetByID", ctx, session.ID).Return(session, nil)
+	sessionRepo.On("Transition", ctx, session, entity.CheckoutSessionStatusOpen).Return(nil)
+
+	result, err := useCase.CancelSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Nil(t, result.Payment)
+
+	redirect, err := url.Parse(result.RedirectURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/cart", redirect.Path)
+	q := redirect.Query()
+	assert.Equal(t, "canceled", q.Get("status"))
+	assert.Empty(t, q.Get("payment_id"))
+	assert.Equal(t, CheckoutSignature(CheckoutRedirectSecret("api_key_test"), session.ID.String(), "canceled", "", "", q.Get("timestamp")), q.Get("signature"))
+
+	// 已完成的結帳不可取消
+	session.Status = entity.CheckoutSessionStatusComplete
+	_, err = useCase.CancelSession(ctx, session.ID)
+	assert.Equal(t, "invalid_checkout", errors.Code(err))
+}
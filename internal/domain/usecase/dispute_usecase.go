@@ -0,0 +1,433 @@
+package usecase
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// DisputeUseCase 處理客戶對已完成付款提出的爭議：平台記錄爭議與裁決，
+// 商戶在期限前提交證據，敗訴時自動從商戶帳務沖正爭議金額。
+// 每次狀態變化都會寫入 dispute.* 事件供商戶查詢
+type DisputeUseCase interface {
+	// OpenDispute 依網關或發卡行的通知建立爭議
+	OpenDispute(ctx context.Context, req OpenDisputeRequest) (*entity.Dispute, error)
+	GetDispute(ctx context.Context, merchantID, id uuid.UUID) (*entity.Dispute, error)
+	ListDisputes(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error)
+	// AddEvidence 在期限前新增一項證據，附件保存在 EvidenceStore
+	AddEvidence(ctx context.Context, req AddEvidenceRequest) (*entity.Dispute, error)
+	// OpenEvidenceFile 回傳證據與附件內容，呼叫者負責關閉
+	OpenEvidenceFile(ctx context.Context, merchantID, disputeID, evidenceID uuid.UUID) (*entity.DisputeEvidence, io.ReadCloser, error)
+	// SubmitEvidence 將證據送交審查，之後不能再新增證據
+	SubmitEvidence(ctx context.Context, merchantID, id uuid.UUID) (*entity.Dispute, error)
+	// ResolveDispute 記錄裁決結果 won 或 lost，重複記錄相同結果時回傳目前的爭議
+	ResolveDispute(ctx context.Context, id uuid.UUID, outcome entity.DisputeStatus) (*entity.Dispute, error)
+	ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error)
+	ListLedgerEntries(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error)
+}
+
+type OpenDisputeRequest struct {
+	PaymentID uuid.UUID            `json:"payment_id"`
+	Reason    entity.DisputeReason `json:"reason"`
+	// Amount 為 0 時爭議整筆付款
+	Amount int64 `json:"amount"`
+	// EvidenceDueBy 為空時使用設定的回覆期限
+	EvidenceDueBy *time.Time `json:"evidence_due_by"`
+}
+
+// AddEvidenceRequest 至少需要說明或附件其中一項
+type AddEvidenceRequest struct {
+	MerchantID  uuid.UUID
+	DisputeID   uuid.UUID
+	Description string
+	FileName    string
+	File        io.Reader // nil 表示沒有附件
+}
+
+type DisputeConfig struct {
+	// ResponseWindow 為建立爭議後商戶提交證據的期限
+	ResponseWindow time.Duration
+	// MaxEvidenceSize 為單一附件的大小上限
+	MaxEvidenceSize int64
+}
+
+// EvidenceStore 由基礎設施層保存證據附件
+type EvidenceStore interface {
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// evidenceContentTypes 為接受的附件格式，以內容判斷而不採用上傳時宣告的類型
+var evidenceContentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"text/plain":      true,
+}
+
+const maxEvidenceDescription = 20000
+
+var disputeReasons = map[entity.DisputeReason]bool{
+	entity.DisputeReasonFraudulent:          true,
+	entity.DisputeReasonProductNotReceived:  true,
+	entity.DisputeReasonProductUnacceptable: true,
+	entity.DisputeReasonDuplicate:           true,
+	entity.DisputeReasonUnrecognized:        true,
+	entity.DisputeReasonGeneral:             true,
+}
+
+type disputeUseCase struct {
+	disputeRepo repository.DisputeRepository
+	paymentRepo repository.PaymentRepository
+	ledgerRepo  repository.LedgerRepository
+	files       EvidenceStore
+	config      DisputeConfig
+	now         func() time.Time
+}
+
+func NewDisputeUseCase(
+	disputeRepo repository.DisputeRepository,
+	paymentRepo repository.PaymentRepository,
+	ledgerRepo repository.LedgerRepository,
+	files EvidenceStore,
+	config DisputeConfig,
+) DisputeUseCase {
+	if config.ResponseWindow <= 0 {
+		config.ResponseWindow = 7 * 24 * time.Hour
+	}
+	if config.MaxEvidenceSize <= 0 {
+		config.MaxEvidenceSize = 5 << 20
+	}
+	return &disputeUseCase{
+		disputeRepo: disputeRepo,
+		paymentRepo: paymentRepo,
+		ledgerRepo:  ledgerRepo,
+		files:       files,
+		config:      config,
+		now:         time.Now,
+	}
+}
+
+func (uc *disputeUseCase) OpenDispute(ctx context.Context, req OpenDisputeRequest) (*entity.Dispute, error) {
+	if !disputeReasons[req.Reason] {
+		return nil, invalidDispute(fmt.Sprintf("unsupported dispute reason %q", req.Reason))
+	}
+	payment, err := uc.paymentRepo.GetByID(ctx, req.PaymentID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment"), "not_found")
+	}
+	if payment.Status != entity.PaymentStatusCompleted {
+		return nil, invalidDispute(fmt.Sprintf("payment is %s, only completed payments can be disputed", payment.Status))
+	}
+	if _, err := uc.disputeRepo.GetByPaymentID(ctx, payment.ID); err == nil {
+		return nil, invalidDispute("payment is already disputed")
+	}
+	amount := req.Amount
+	if amount == 0 {
+		amount = payment.Amount
+	}
+	if amount < 0 || amount > payment.Amount {
+		return nil, invalidDispute(fmt.Sprintf("dispute amount must be between 1 and %d", payment.Amount))
+	}
+
+	now := uc.now()
+	dueBy := now.Add(uc.config.ResponseWindow)
+	if req.EvidenceDueBy != nil {
+		if !req.EvidenceDueBy.After(now) {
+			return nil, invalidDispute("evidence_due_by must be in the future")
+		}
+		dueBy = *req.EvidenceDueBy
+	}
+
+	dispute := &entity.Dispute{
+		ID:            uuid.New(),
+		PaymentID:     payment.ID,
+		MerchantID:    payment.MerchantID,
+		Amount:        amount,
+		Currency:      payment.Currency,
+		Reason:        req.Reason,
+		Status:        entity.DisputeStatusNeedsResponse,
+		EvidenceDueBy: dueBy,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := uc.disputeRepo.Create(ctx, dispute, disputeEvent(dispute, entity.DisputeEventCreated, now)); err != nil {
+		return nil, errors.Wrap(err, "failed to create dispute")
+	}
+	logger.FromContext(ctx).Info("dispute opened",
+		zap.String("dispute_id", dispute.ID.String()),
+		zap.String("payment_id", payment.ID.String()),
+		zap.Int64("amount", amount),
+	)
+	return dispute, nil
+}
+
+func (uc *disputeUseCase) GetDispute(ctx context.Context, merchantID, id uuid.UUID) (*entity.Dispute, error) {
+	dispute, err := uc.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get dispute"), "not_found")
+	}
+	// 其他商戶的爭議視同不存在
+	if dispute.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("dispute not found"), "not_found")
+	}
+	return dispute, nil
+}
+
+func (uc *disputeUseCase) ListDisputes(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error) {
+	disputes, err := uc.disputeRepo.ListByMerchant(ctx, merchantID, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list disputes")
+	}
+	return disputes, nil
+}
+
+func (uc *disputeUseCase) AddEvidence(ctx context.Context, req AddEvidenceRequest) (*entity.Dispute, error) {
+	description := strings.TrimSpace(req.Description)
+	if description == "" && req.File == nil {
+		return nil, invalidDispute("evidence requires a description or a file")
+	}
+	if len(description) > maxEvidenceDescription {
+		return nil, invalidDispute(fmt.Sprintf("description must be at most %d bytes", maxEvidenceDescription))
+	}
+	dispute, err := uc.GetDispute(ctx, req.MerchantID, req.DisputeID)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+	if err := checkAcceptingEvidence(dispute, now); err != nil {
+		return nil, err
+	}
+
+	evidence := &entity.DisputeEvidence{
+		ID:          uuid.New(),
+		DisputeID:   dispute.ID,
+		Description: description,
+		CreatedAt:   now,
+	}
+	if req.File != nil {
+		if err := uc.saveFile(ctx, evidence, req.FileName, req.File); err != nil {
+			return nil, err
+		}
+	}
+	if err := uc.disputeRepo.AddEvidence(ctx, evidence); err != nil {
+		if evidence.StorageKey != "" {
+			if delErr := uc.files.Delete(ctx, evidence.StorageKey); delErr != nil {
+				logger.FromContext(ctx).Warn("failed to delete orphaned evidence file",
+					zap.String("key", evidence.StorageKey), zap.Error(delErr))
+			}
+		}
+		return nil, errors.WithCode(errors.Wrap(err, "failed to add evidence"), "invalid_dispute_status")
+	}
+	return uc.GetDispute(ctx, req.MerchantID, req.DisputeID)
+}
+
+// saveFile 檢查附件格式與大小後保存，並填入證據的附件欄位
+func (uc *disputeUseCase) saveFile(ctx context.Context, evidence *entity.DisputeEvidence, fileName string, file io.Reader) error {
+	reader := bufio.NewReaderSize(file, 512)
+	head, err := reader.Peek(512)
+	if err != nil && err != io.EOF {
+		return errors.Wrap(err, "failed to read evidence file")
+	}
+	if len(head) == 0 {
+		return invalidDispute("evidence file is empty")
+	}
+	contentType, _, _ := strings.Cut(http.DetectContentType(head), ";")
+	if !evidenceContentTypes[contentType] {
+		return invalidDispute(fmt.Sprintf("unsupported evidence file type %s", contentType))
+	}
+
+	key := evidence.DisputeID.String() + "/" + evidence.ID.String()
+	// 多讀一個 byte 判斷是否超過上限
+	size, err := uc.files.Save(ctx, key, io.LimitReader(reader, uc.config.MaxEvidenceSize+1))
+	if err != nil {
+		return errors.Wrap(err, "failed to store evidence file")
+	}
+	if size > uc.config.MaxEvidenceSize {
+		if err := uc.files.Delete(ctx, key); err != nil {
+			logger.FromContext(ctx).Warn("failed to delete oversized evidence file", zap.String("key", key), zap.Error(err))
+		}
+		return invalidDispute(fmt.Sprintf("evidence file must be at most %d bytes", uc.config.MaxEvidenceSize))
+	}
+
+	evidence.FileName = sanitizeFileName(fileName)
+	evidence.ContentType = contentType
+	evidence.Size = size
+	evidence.StorageKey = key
+	return nil
+}
+
+func (uc *disputeUseCase) OpenEvidenceFile(ctx context.Context, merchantID, disputeID, evidenceID uuid.UUID) (*entity.DisputeEvidence, io.ReadCloser, error) {
+	dispute, err := uc.GetDispute(ctx, merchantID, disputeID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, evidence := range dispute.Evidence {
+		if evidence.ID != evidenceID {
+			continue
+		}
+		if evidence.StorageKey == "" {
+			return nil, nil, errors.WithCode(errors.New("evidence has no file"), "not_found")
+		}
+		file, err := uc.files.Open(ctx, evidence.StorageKey)
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "failed to open evidence file")
+		}
+		return evidence, file, nil
+	}
+	return nil, nil, errors.WithCode(errors.New("evidence not found"), "not_found")
+}
+
+func (uc *disputeUseCase) SubmitEvidence(ctx context.Context, merchantID, id uuid.UUID) (*entity.Dispute, error) {
+	dispute, err := uc.GetDispute(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := uc.now()
+	if err := checkAcceptingEvidence(dispute, now); err != nil {
+		return nil, err
+	}
+	if len(dispute.Evidence) == 0 {
+		return nil, invalidDispute("add evidence before submitting")
+	}
+
+	dispute.Status = entity.DisputeStatusUnderReview
+	dispute.SubmittedAt = &now
+	dispute.UpdatedAt = now
+	event := disputeEvent(dispute, entity.DisputeEventEvidenceSubmitted, now)
+	if err := uc.disputeRepo.Transition(ctx, dispute, entity.DisputeStatusNeedsResponse, event, nil); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to submit evidence"), "invalid_dispute_status")
+	}
+	logger.FromContext(ctx).Info("dispute evidence submitted",
+		zap.String("dispute_id", dispute.ID.String()),
+		zap.Int("evidence", len(dispute.Evidence)),
+	)
+	return dispute, nil
+}
+
+func (uc *disputeUseCase) ResolveDispute(ctx context.Context, id uuid.UUID, outcome entity.DisputeStatus) (*entity.Dispute, error) {
+	if outcome != entity.DisputeStatusWon && outcome != entity.DisputeStatusLost {
+		return nil, invalidDispute("outcome must be won or lost")
+	}
+	dispute, err := uc.disputeRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get dispute"), "not_found")
+	}
+	if dispute.Status == outcome {
+		return dispute, nil
+	}
+	if dispute.IsClosed() {
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("dispute is already %s", dispute.Status)), "invalid_dispute_status")
+	}
+
+	now := uc.now()
+	from := dispute.Status
+	dispute.Status = outcome
+	dispute.ClosedAt = &now
+	dispute.UpdatedAt = now
+
+	eventType := entity.DisputeEventWon
+	var reversal *entity.LedgerEntry
+	if outcome == entity.DisputeStatusLost {
+		eventType = entity.DisputeEventLost
+		disputeID := dispute.ID
+		reversal = &entity.LedgerEntry{
+			ID:          uuid.New(),
+			MerchantID:  dispute.MerchantID,
+			PaymentID:   dispute.PaymentID,
+			DisputeID:   &disputeID,
+			Type:        entity.LedgerEntryDisputeReversal,
+			Amount:      -dispute.Amount,
+			Currency:    dispute.Currency,
+			Description: fmt.Sprintf("Dispute %s lost (%s)", dispute.ID, dispute.Reason),
+			CreatedAt:   now,
+		}
+	}
+	if err := uc.disputeRepo.Transition(ctx, dispute, from, disputeEvent(dispute, eventType, now), reversal); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to resolve dispute"), "invalid_dispute_status")
+	}
+
+	logger.FromContext(ctx).Info("dispute resolved",
+		zap.String("dispute_id", dispute.ID.String()),
+		zap.String("outcome", string(outcome)),
+		zap.Int64("amount", dispute.Amount),
+	)
+	return dispute, nil
+}
+
+func (uc *disputeUseCase) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error) {
+	events, err := uc.disputeRepo.ListEvents(ctx, merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list dispute events")
+	}
+	return events, nil
+}
+
+func (uc *disputeUseCase) ListLedgerEntries(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error) {
+	entries, err := uc.ledgerRepo.ListByMerchant(ctx, merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list ledger entries")
+	}
+	return entries, nil
+}
+
+// checkAcceptingEvidence 確認爭議仍在等待證據且未超過期限
+func checkAcceptingEvidence(dispute *entity.Dispute, now time.Time) error {
+	if dispute.Status != entity.DisputeStatusNeedsResponse {
+		return errors.WithCode(errors.New(fmt.Sprintf("dispute is %s, evidence can no longer be changed", dispute.Status)), "invalid_dispute_status")
+	}
+	if now.After(dispute.EvidenceDueBy) {
+		return errors.WithCode(errors.New("evidence due date has passed"), "invalid_dispute_status")
+	}
+	return nil
+}
+
+func disputeEvent(dispute *entity.Dispute, eventType string, now time.Time) *entity.DisputeEvent {
+	return &entity.DisputeEvent{
+		ID:         uuid.New(),
+		Type:       eventType,
+		DisputeID:  dispute.ID,
+		MerchantID: dispute.MerchantID,
+		PaymentID:  dispute.PaymentID,
+		Status:     dispute.Status,
+		Amount:     dispute.Amount,
+		Currency:   dispute.Currency,
+		CreatedAt:  now,
+	}
+}
+
+// sanitizeFileName 只保留檔名本身，用於下載時的 Content-Disposition
+func sanitizeFileName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == '"' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name)
+	if len(name) > 255 {
+		name = name[:255]
+	}
+	if name == "" {
+		name = "evidence"
+	}
+	return name
+}
+
+func invalidDispute(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_dispute")
+}
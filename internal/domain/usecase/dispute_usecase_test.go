@@ -0,0 +1,427 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockDisputeRepository struct {
+	mock.Mock
+}
+
+func (m *MockDisputeRepository) Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) error {
+	args := m.Called(ctx, dispute, event)
+	return args.Error(0)
+}
+
+func (m *MockDisputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Dispute, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.Dispute, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error) {
+	args := m.Called(ctx, merchantID, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Dispute), args.Error(1)
+}
+
+func (m *MockDisputeRepository) AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) error {
+	args := m.Called(ctx, evidence)
+	return args.Error(0)
+}
+
+func (m *MockDisputeRepository) Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) error {
+	args := m.Called(ctx, dispute, from, event, reversal)
+	return args.Error(0)
+}
+
+func (m *MockDisputeRepository) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.DisputeEvent), args.Error(1)
+}
+
+type MockLedgerRepository struct {
+	mock.Mock
+}
+
+func (m *MockLedgerRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.LedgerEntry), args.Error(1)
+}
+
+// memoryEvidenceStore 以 map 保存附件
+type memoryEvidenceStore struct {
+	files map[string][]byte
+}
+
+func newMemoryEvidenceStore() *memoryEvidenceStore {
+	return &memoryEvidenceStore{files: make(map[string][]byte)}
+}
+
+func (s *memoryEvidenceStore) Save(ctx context.Context, key string, r io.Reader) (int64, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+	s.files[key] = content
+	return int64(len(content)), nil
+}
+
+func (s *memoryEvidenceStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	content, ok := s.files[key]
+	if !ok {
+		return nil, errors.New("file not found")
+	}
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (s *memoryEvidenceStore) Delete(ctx context.Context, key string) error {
+	delete(s.files, key)
+	return nil
+}
+
+// newTestDisputeUseCase 建立回覆期限 72 小時、證據上限 64 bytes、時間固定為 now 的 use case
+func newTestDisputeUseCase(disputes *MockDisputeRepository, payments *MockPaymentRepository, files *memoryEvidenceStore, now time.Time) DisputeUseCase {
+	uc := NewDisputeUseCase(disputes, payments, new(MockLedgerRepository), files, DisputeConfig{
+		ResponseWindow:  72 * time.Hour,
+		MaxEvidenceSize: 64,
+	}).(*disputeUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+// newDispute 建立屬於 merchantID、一小時後到期的爭議，並設定 GetByID 回傳它
+func newDispute(disputes *MockDisputeRepository, merchantID uuid.UUID, status entity.DisputeStatus, now time.Time) *entity.Dispute {
+	dispute := &entity.Dispute{
+		ID:            uuid.New(),
+		PaymentID:     uuid.New(),
+		MerchantID:    merchantID,
+		Amount:        2500,
+		Currency:      "USD",
+		Reason:        entity.DisputeReasonFraudulent,
+		Status:        status,
+		EvidenceDueBy: now.Add(time.Hour),
+	}
+	disputes.On("GetByID", mock.Anything, dispute.ID).Return(dispute, nil)
+	return dispute
+}
+
+func TestDisputeUseCase_OpenDispute(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+
+	t.Run("disputes the full payment by default", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		payments := new(MockPaymentRepository)
+		useCase := newTestDisputeUseCase(disputes, payments, newMemoryEvidenceStore(), now)
+		payment := &entity.Payment{ID: uuid.New(), MerchantID: merchantID, Amount: 2500, Currency: "USD", Status: entity.PaymentStatusCompleted}
+		payments.On("GetByID", ctx, payment.ID).Return(payment, nil)
+		disputes.On("GetByPaymentID", ctx, payment.ID).Return(nil, errors.New("dispute not found"))
+		disputes.On("Create", ctx, mock.AnythingOfType("*entity.Dispute"), mock.MatchedBy(func(e *entity.DisputeEvent) bool {
+			return e.Type == entity.DisputeEventCreated && e.MerchantID == merchantID && e.Status == entity.DisputeStatusNeedsResponse
+		})).Return(nil)
+
+		dispute, err := useCase.OpenDispute(ctx, OpenDisputeRequest{PaymentID: payment.ID, Reason: entity.DisputeReasonProductNotReceived})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2500), dispute.Amount)
+		assert.Equal(t, "USD", dispute.Currency)
+		assert.Equal(t, merchantID, dispute.MerchantID)
+		assert.Equal(t, entity.DisputeStatusNeedsResponse, dispute.Status)
+		assert.Equal(t, now.Add(72*time.Hour), dispute.EvidenceDueBy)
+		disputes.AssertExpectations(t)
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		payments := new(MockPaymentRepository)
+		useCase := newTestDisputeUseCase(disputes, payments, newMemoryEvidenceStore(), now)
+		completed := &entity.Payment{ID: uuid.New(), Amount: 2500, Status: entity.PaymentStatusCompleted}
+		pending := &entity.Payment{ID: uuid.New(), Amount: 2500, Status: entity.PaymentStatusPending}
+		disputed := &entity.Payment{ID: uuid.New(), Amount: 2500, Status: entity.PaymentStatusCompleted}
+		payments.On("GetByID", ctx, completed.ID).Return(completed, nil)
+		payments.On("GetByID", ctx, pending.ID).Return(pending, nil)
+		payments.On("GetByID", ctx, disputed.ID).Return(disputed, nil)
+		payments.On("GetByID", ctx, mock.Anything).Return(nil, errors.New("payment not found"))
+		disputes.On("GetByPaymentID", ctx, completed.ID).Return(nil, errors.New("dispute not found"))
+		disputes.On("GetByPaymentID", ctx, disputed.ID).Return(&entity.Dispute{}, nil)
+		past := now.Add(-time.Minute)
+
+		for name, tc := range map[string]struct {
+			req  OpenDisputeRequest
+			code string
+		}{
+			"unknown reason":   {OpenDisputeRequest{PaymentID: completed.ID, Reason: "angry"}, "invalid_dispute"},
+			"unknown payment":  {OpenDisputeRequest{PaymentID: uuid.New(), Reason: entity.DisputeReasonGeneral}, "not_found"},
+			"payment not done": {OpenDisputeRequest{PaymentID: pending.ID, Reason: entity.DisputeReasonGeneral}, "invalid_dispute"},
+			"already disputed": {OpenDisputeRequest{PaymentID: disputed.ID, Reason: entity.DisputeReasonGeneral}, "invalid_dispute"},
+			"exceeds payment":  {OpenDisputeRequest{PaymentID: completed.ID, Reason: entity.DisputeReasonGeneral, Amount: 2501}, "invalid_dispute"},
+			"negative amount":  {OpenDisputeRequest{PaymentID: completed.ID, Reason: entity.DisputeReasonGeneral, Amount: -1}, "invalid_dispute"},
+			"due date in past": {OpenDisputeRequest{PaymentID: completed.ID, Reason: entity.DisputeReasonGeneral, EvidenceDueBy: &past}, "invalid_dispute"},
+		} {
+			_, err := useCase.OpenDispute(ctx, tc.req)
+			assert.Equal(t, tc.code, errors.Code(err), name)
+		}
+		disputes.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestDisputeUseCase_AddEvidence(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+	pdf := "%PDF-1.4 signed delivery receipt"
+
+	t.Run("stores file and detects content type", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		files := newMemoryEvidenceStore()
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), files, now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		var evidence *entity.DisputeEvidence
+		disputes.On("AddEvidence", ctx, mock.AnythingOfType("*entity.DisputeEvidence")).Return(nil).Run(func(args mock.Arguments) {
+			evidence = args.Get(1).(*entity.DisputeEvidence)
+		})
+
+		_, err := useCase.AddEvidence(ctx, AddEvidenceRequest{
+			MerchantID:  merchantID,
+			DisputeID:   dispute.ID,
+			Description: " Delivered on 2026-09-20 ",
+			FileName:    `C:\uploads\receipt".pdf`,
+			File:        strings.NewReader(pdf),
+		})
+		require.NoError(t, err)
+
+		require.NotNil(t, evidence)
+		assert.Equal(t, "Delivered on 2026-09-20", evidence.Description)
+		assert.Equal(t, "receipt.pdf", evidence.FileName, "path and quotes are stripped")
+		assert.Equal(t, "application/pdf", evidence.ContentType)
+		assert.Equal(t, int64(len(pdf)), evidence.Size)
+		assert.Equal(t, dispute.ID.String()+"/"+evidence.ID.String(), evidence.StorageKey)
+		assert.Equal(t, pdf, string(files.files[evidence.StorageKey]))
+	})
+
+	t.Run("description only", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		files := newMemoryEvidenceStore()
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), files, now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		disputes.On("AddEvidence", ctx, mock.MatchedBy(func(e *entity.DisputeEvidence) bool {
+			return e.StorageKey == "" && e.Description == "Customer used the service"
+		})).Return(nil)
+
+		_, err := useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, Description: "Customer used the service"})
+		require.NoError(t, err)
+		assert.Empty(t, files.files)
+	})
+
+	t.Run("rejects invalid files", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		files := newMemoryEvidenceStore()
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), files, now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+
+		_, err := useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, File: strings.NewReader("<html><script>alert(1)</script></html>")})
+		assert.Equal(t, "invalid_dispute", errors.Code(err), "html is not accepted")
+		_, err = useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, File: strings.NewReader(pdf + strings.Repeat("x", 64))})
+		assert.Equal(t, "invalid_dispute", errors.Code(err), "exceeds MaxEvidenceSize")
+		_, err = useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, File: strings.NewReader("")})
+		assert.Equal(t, "invalid_dispute", errors.Code(err), "empty file")
+		_, err = useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, Description: "  "})
+		assert.Equal(t, "invalid_dispute", errors.Code(err), "nothing to add")
+
+		assert.Empty(t, files.files, "rejected files are not kept")
+		disputes.AssertNotCalled(t, "AddEvidence", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects closed or overdue disputes", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		submitted := newDispute(disputes, merchantID, entity.DisputeStatusUnderReview, now)
+		overdue := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		overdue.EvidenceDueBy = now.Add(-time.Second)
+		other := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		other.MerchantID = uuid.New()
+
+		_, err := useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: submitted.ID, Description: "late"})
+		assert.Equal(t, "invalid_dispute_status", errors.Code(err))
+		_, err = useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: overdue.ID, Description: "late"})
+		assert.Equal(t, "invalid_dispute_status", errors.Code(err))
+		_, err = useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: other.ID, Description: "late"})
+		assert.Equal(t, "not_found", errors.Code(err), "other merchant's dispute")
+	})
+
+	t.Run("removes file when the dispute changed meanwhile", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		files := newMemoryEvidenceStore()
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), files, now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		disputes.On("AddEvidence", ctx, mock.Anything).Return(errors.New("dispute not found or no longer accepting evidence"))
+
+		_, err := useCase.AddEvidence(ctx, AddEvidenceRequest{MerchantID: merchantID, DisputeID: dispute.ID, File: strings.NewReader(pdf)})
+		assert.Equal(t, "invalid_dispute_status", errors.Code(err))
+		assert.Empty(t, files.files)
+	})
+}
+
+func TestDisputeUseCase_OpenEvidenceFile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+	disputes := new(MockDisputeRepository)
+	files := newMemoryEvidenceStore()
+	useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), files, now)
+	dispute := newDispute(disputes, merchantID, entity.DisputeStatusUnderReview, now)
+	withFile := &entity.DisputeEvidence{ID: uuid.New(), StorageKey: "d/e", FileName: "receipt.pdf"}
+	textOnly := &entity.DisputeEvidence{ID: uuid.New(), Description: "note"}
+	dispute.Evidence = []*entity.DisputeEvidence{withFile, textOnly}
+	files.files["d/e"] = []byte("%PDF-1.4")
+
+	evidence, file, err := useCase.OpenEvidenceFile(ctx, merchantID, dispute.ID, withFile.ID)
+	require.NoError(t, err)
+	content, _ := io.ReadAll(file)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "receipt.pdf", evidence.FileName)
+	assert.Equal(t, "%PDF-1.4", string(content))
+
+	_, _, err = useCase.OpenEvidenceFile(ctx, merchantID, dispute.ID, textOnly.ID)
+	assert.Equal(t, "not_found", errors.Code(err))
+	_, _, err = useCase.OpenEvidenceFile(ctx, merchantID, dispute.ID, uuid.New())
+	assert.Equal(t, "not_found", errors.Code(err))
+	_, _, err = useCase.OpenEvidenceFile(ctx, uuid.New(), dispute.ID, withFile.ID)
+	assert.Equal(t, "not_found", errors.Code(err))
+}
+
+func TestDisputeUseCase_SubmitEvidence(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+
+	t.Run("moves to under review", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		dispute.Evidence = []*entity.DisputeEvidence{{ID: uuid.New(), Description: "note"}}
+		disputes.On("Transition", ctx, dispute, entity.DisputeStatusNeedsResponse, mock.MatchedBy(func(e *entity.DisputeEvent) bool {
+			return e.Type == entity.DisputeEventEvidenceSubmitted && e.Status == entity.DisputeStatusUnderReview
+		}), (*entity.LedgerEntry)(nil)).Return(nil)
+
+		submitted, err := useCase.SubmitEvidence(ctx, merchantID, dispute.ID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.DisputeStatusUnderReview, submitted.Status)
+		assert.Equal(t, &now, submitted.SubmittedAt)
+		disputes.AssertExpectations(t)
+	})
+
+	t.Run("requires evidence", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+
+		_, err := useCase.SubmitEvidence(ctx, merchantID, dispute.ID)
+		assert.Equal(t, "invalid_dispute", errors.Code(err))
+	})
+
+	t.Run("only once", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusUnderReview, now)
+
+		_, err := useCase.SubmitEvidence(ctx, merchantID, dispute.ID)
+		assert.Equal(t, "invalid_dispute_status", errors.Code(err))
+	})
+}
+
+func TestDisputeUseCase_ResolveDispute(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchantID := uuid.New()
+
+	t.Run("lost reverses the disputed amount", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusUnderReview, now)
+		dispute.Amount = 1800
+		disputes.On("Transition", ctx, dispute, entity.DisputeStatusUnderReview, mock.MatchedBy(func(e *entity.DisputeEvent) bool {
+			return e.Type == entity.DisputeEventLost && e.Status == entity.DisputeStatusLost
+		}), mock.AnythingOfType("*entity.LedgerEntry")).Return(nil)
+
+		lost, err := useCase.ResolveDispute(ctx, dispute.ID, entity.DisputeStatusLost)
+		require.NoError(t, err)
+		assert.Equal(t, entity.DisputeStatusLost, lost.Status)
+		assert.Equal(t, &now, lost.ClosedAt)
+
+		reversal := disputes.Calls[len(disputes.Calls)-1].Arguments.Get(4).(*entity.LedgerEntry)
+		assert.Equal(t, entity.LedgerEntryDisputeReversal, reversal.Type)
+		assert.Equal(t, int64(-1800), reversal.Amount)
+		assert.Equal(t, "USD", reversal.Currency)
+		assert.Equal(t, merchantID, reversal.MerchantID)
+		assert.Equal(t, dispute.PaymentID, reversal.PaymentID)
+		assert.Equal(t, &dispute.ID, reversal.DisputeID)
+	})
+
+	t.Run("won closes without reversal", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusNeedsResponse, now)
+		disputes.On("Transition", ctx, dispute, entity.DisputeStatusNeedsResponse, mock.MatchedBy(func(e *entity.DisputeEvent) bool {
+			return e.Type == entity.DisputeEventWon
+		}), (*entity.LedgerEntry)(nil)).Return(nil)
+
+		won, err := useCase.ResolveDispute(ctx, dispute.ID, entity.DisputeStatusWon)
+		require.NoError(t, err)
+		assert.Equal(t, entity.DisputeStatusWon, won.Status)
+		disputes.AssertExpectations(t)
+	})
+
+	t.Run("same outcome is a no-op", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusLost, now)
+
+		got, err := useCase.ResolveDispute(ctx, dispute.ID, entity.DisputeStatusLost)
+		require.NoError(t, err)
+		assert.Same(t, dispute, got)
+		disputes.AssertNotCalled(t, "Transition", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects changing a closed dispute", func(t *testing.T) {
+		disputes := new(MockDisputeRepository)
+		useCase := newTestDisputeUseCase(disputes, new(MockPaymentRepository), newMemoryEvidenceStore(), now)
+		dispute := newDispute(disputes, merchantID, entity.DisputeStatusWon, now)
+
+		_, err := useCase.ResolveDispute(ctx, dispute.ID, entity.DisputeStatusLost)
+		assert.Equal(t, "invalid_dispute_status", errors.Code(err))
+		_, err = useCase.ResolveDispute(ctx, dispute.ID, entity.DisputeStatusUnderReview)
+		assert.Equal(t, "invalid_dispute", errors.Code(err))
+	})
+}
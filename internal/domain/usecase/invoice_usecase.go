@@ -0,0 +1,316 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 稅率以萬分比表示，最高 100%
+const maxTaxRate = 10000
+
+type InvoiceUseCase interface {
+	CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*entity.Invoice, error)
+	GetInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error)
+	ListInvoices(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error)
+	// UpdateInvoice 只能修改草稿
+	UpdateInvoice(ctx context.Context, req UpdateInvoiceRequest) (*entity.Invoice, error)
+	// FinalizeInvoice 將草稿定稿為 open 並產生編號，之後才能付款
+	FinalizeInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error)
+	// VoidInvoice 作廢草稿或尚未付款的帳單
+	VoidInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error)
+	// GetInvoiceDocument 回傳輸出 PDF 或 HTML 所需的帳單、商戶與客戶資料
+	GetInvoiceDocument(ctx context.Context, merchantID, id uuid.UUID) (*InvoiceDocument, error)
+}
+
+type InvoiceLineItemRequest struct {
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	UnitAmount  int64  `json:"unit_amount"`
+	// Discount 為此明細的折扣金額
+	Discount int64  `json:"discount"`
+	TaxName  string `json:"tax_name"`
+	// TaxRate 為萬分比，500 代表 5%
+	TaxRate int `json:"tax_rate"`
+}
+
+type CreateInvoiceRequest struct {
+	MerchantID uuid.UUID                `json:"-"`
+	CustomerID uuid.UUID                `json:"customer_id"`
+	Currency   string                   `json:"currency"`
+	Memo       string                   `json:"memo"`
+	DueDate    *time.Time               `json:"due_date"`
+	LineItems  []InvoiceLineItemRequest `json:"line_items"`
+}
+
+// UpdateInvoiceRequest 中為空的欄位維持不變；LineItems 有值時取代全部明細
+type UpdateInvoiceRequest struct {
+	MerchantID uuid.UUID                `json:"-"`
+	ID         uuid.UUID                `json:"-"`
+	Memo       *string                  `json:"memo"`
+	DueDate    *time.Time               `json:"due_date"`
+	LineItems  []InvoiceLineItemRequest `json:"line_items"`
+}
+
+type InvoiceDocument struct {
+	Invoice  *entity.Invoice
+	Merchant *entity.Merchant
+	Customer *entity.Customer
+}
+
+type invoiceUseCase struct {
+	invoiceRepo  repository.InvoiceRepository
+	merchantRepo repository.MerchantRepository
+	customerRepo repository.CustomerRepository
+}
+
+func NewInvoiceUseCase(
+	invoiceRepo repository.InvoiceRepository,
+	merchantRepo repository.MerchantRepository,
+	customerRepo repository.CustomerRepository,
+) InvoiceUseCase {
+	return &invoiceUseCase{
+		invoiceRepo:  invoiceRepo,
+		merchantRepo: merchantRepo,
+		customerRepo: customerRepo,
+	}
+}
+
+func (uc *invoiceUseCase) CreateInvoice(ctx context.Context, req CreateInvoiceRequest) (*entity.Invoice, error) {
+	if _, err := uc.customerRepo.GetByID(ctx, req.CustomerID); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get customer"), "not_found")
+	}
+	if len(req.Currency) != 3 {
+		return nil, invalidInvoice("currency must be a 3-letter code")
+	}
+	items, err := buildLineItems(req.LineItems)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invoice := &entity.Invoice{
+		ID:         uuid.New(),
+		MerchantID: req.MerchantID,
+		CustomerID: req.CustomerID,
+		Currency:   strings.ToUpper(req.Currency),
+		Status:     entity.InvoiceStatusDraft,
+		Memo:       req.Memo,
+		DueDate:    req.DueDate,
+		LineItems:  items,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	calculateInvoice(invoice)
+
+	if err := uc.invoiceRepo.Create(ctx, invoice); err != nil {
+		return nil, errors.Wrap(err, "failed to create invoice")
+	}
+	logger.FromContext(ctx).Info("invoice created",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.Int64("total", invoice.Total),
+	)
+	return invoice, nil
+}
+
+func (uc *invoiceUseCase) GetInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error) {
+	invoice, err := uc.invoiceRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get invoice"), "not_found")
+	}
+	if invoice.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("invoice not found"), "not_found")
+	}
+	invoice.TaxLines = taxLines(invoice.LineItems)
+	return invoice, nil
+}
+
+func (uc *invoiceUseCase) ListInvoices(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error) {
+	invoices, err := uc.invoiceRepo.GetByMerchantID(ctx, merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list invoices")
+	}
+	return invoices, nil
+}
+
+func (uc *invoiceUseCase) UpdateInvoice(ctx context.Context, req UpdateInvoiceRequest) (*entity.Invoice, error) {
+	invoice, err := uc.GetInvoice(ctx, req.MerchantID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != entity.InvoiceStatusDraft {
+		return nil, invalidInvoice(fmt.Sprintf("invoice status is %s, only drafts can be updated", invoice.Status))
+	}
+
+	if req.Memo != nil {
+		invoice.Memo = *req.Memo
+	}
+	if req.DueDate != nil {
+		invoice.DueDate = req.DueDate
+	}
+	if req.LineItems != nil {
+		items, err := buildLineItems(req.LineItems)
+		if err != nil {
+			return nil, err
+		}
+		invoice.LineItems = items
+	}
+	calculateInvoice(invoice)
+	invoice.UpdatedAt = time.Now()
+
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, errors.Wrap(err, "failed to update invoice")
+	}
+	return invoice, nil
+}
+
+func (uc *invoiceUseCase) FinalizeInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error) {
+	invoice, err := uc.GetInvoice(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case invoice.Status != entity.InvoiceStatusDraft:
+		return nil, invalidInvoice(fmt.Sprintf("invoice status is %s, only drafts can be finalized", invoice.Status))
+	case len(invoice.LineItems) == 0:
+		return nil, invalidInvoice("invoice has no line items")
+	case invoice.Total <= 0:
+		return nil, invalidInvoice("invoice total must be positive")
+	}
+
+	now := time.Now()
+	invoice.Status = entity.InvoiceStatusOpen
+	invoice.Number = invoiceNumber(invoice.ID, now)
+	invoice.FinalizedAt = &now
+	invoice.UpdatedAt = now
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, errors.Wrap(err, "failed to finalize invoice")
+	}
+	logger.FromContext(ctx).Info("invoice finalized",
+		zap.String("invoice_id", invoice.ID.String()),
+		zap.String("number", invoice.Number),
+	)
+	return invoice, nil
+}
+
+func (uc *invoiceUseCase) VoidInvoice(ctx context.Context, merchantID, id uuid.UUID) (*entity.Invoice, error) {
+	invoice, err := uc.GetInvoice(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != entity.InvoiceStatusDraft && invoice.Status != entity.InvoiceStatusOpen {
+		return nil, invalidInvoice(fmt.Sprintf("invoice status is %s, cannot void", invoice.Status))
+	}
+
+	now := time.Now()
+	invoice.Status = entity.InvoiceStatusVoid
+	invoice.VoidedAt = &now
+	invoice.UpdatedAt = now
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		return nil, errors.Wrap(err, "failed to void invoice")
+	}
+	logger.FromContext(ctx).Info("invoice voided", zap.String("invoice_id", invoice.ID.String()))
+	return invoice, nil
+}
+
+func (uc *invoiceUseCase) GetInvoiceDocument(ctx context.Context, merchantID, id uuid.UUID) (*InvoiceDocument, error) {
+	invoice, err := uc.GetInvoice(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	merchant, err := uc.merchantRepo.GetByID(ctx, invoice.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+	customer, err := uc.customerRepo.GetByID(ctx, invoice.CustomerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get customer")
+	}
+	return &InvoiceDocument{Invoice: invoice, Merchant: merchant, Customer: customer}, nil
+}
+
+func buildLineItems(reqs []InvoiceLineItemRequest) ([]entity.InvoiceLineItem, error) {
+	items := make([]entity.InvoiceLineItem, 0, len(reqs))
+	for i, req := range reqs {
+		switch {
+		case strings.TrimSpace(req.Description) == "":
+			return nil, invalidInvoice(fmt.Sprintf("line item %d: description is required", i+1))
+		case req.Quantity <= 0:
+			return nil, invalidInvoice(fmt.Sprintf("line item %d: quantity must be positive", i+1))
+		case req.UnitAmount < 0:
+			return nil, invalidInvoice(fmt.Sprintf("line item %d: unit_amount cannot be negative", i+1))
+		case req.Discount < 0 || req.Discount > int64(req.Quantity)*req.UnitAmount:
+			return nil, invalidInvoice(fmt.Sprintf("line item %d: discount must be between 0 and the line amount", i+1))
+		case req.TaxRate < 0 || req.TaxRate > maxTaxRate:
+			return nil, invalidInvoice(fmt.Sprintf("line item %d: tax_rate must be between 0 and %d", i+1, maxTaxRate))
+		}
+		items = append(items, entity.InvoiceLineItem{
+			ID:          uuid.New(),
+			Position:    i,
+			Description: req.Description,
+			Quantity:    req.Quantity,
+			UnitAmount:  req.UnitAmount,
+			Discount:    req.Discount,
+			TaxName:     req.TaxName,
+			TaxRate:     req.TaxRate,
+		})
+	}
+	return items, nil
+}
+
+// calculateInvoice 由明細重新計算各行金額、稅額與帳單總計；稅額逐行四捨五入
+func calculateInvoice(invoice *entity.Invoice) {
+	invoice.Subtotal, invoice.DiscountTotal, invoice.TaxTotal = 0, 0, 0
+	for i := range invoice.LineItems {
+		item := &invoice.LineItems[i]
+		item.InvoiceID = invoice.ID
+		item.Position = i
+		gross := int64(item.Quantity) * item.UnitAmount
+		item.Amount = gross - item.Discount
+		item.TaxAmount = (item.Amount*int64(item.TaxRate) + maxTaxRate/2) / maxTaxRate
+
+		invoice.Subtotal += gross
+		invoice.DiscountTotal += item.Discount
+		invoice.TaxTotal += item.TaxAmount
+	}
+	invoice.Total = invoice.Subtotal - invoice.DiscountTotal + invoice.TaxTotal
+	invoice.TaxLines = taxLines(invoice.LineItems)
+}
+
+// taxLines 依稅目與稅率彙總，順序為各稅目第一次出現的順序
+func taxLines(items []entity.InvoiceLineItem) []entity.InvoiceTaxLine {
+	lines := []entity.InvoiceTaxLine{}
+	index := make(map[string]int)
+	for _, item := range items {
+		if item.TaxRate == 0 {
+			continue
+		}
+		key := fmt.Sprintf("%s/%d", item.TaxName, item.TaxRate)
+		i, ok := index[key]
+		if !ok {
+			i = len(lines)
+			index[key] = i
+			lines = append(lines, entity.InvoiceTaxLine{Name: item.TaxName, Rate: item.TaxRate})
+		}
+		lines[i].Taxable += item.Amount
+		lines[i].Amount += item.TaxAmount
+	}
+	return lines
+}
+
+// invoiceNumber 以定稿日期與帳單 ID 前綴產生編號，例如 INV-20240131-3F2A9C1B
+func invoiceNumber(id uuid.UUID, at time.Time) string {
+	return fmt.Sprintf("INV-%s-%s", at.UTC().Format("20060102"), strings.ToUpper(id.String()[:8]))
+}
+
+func invalidInvoice(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_invoice")
+}
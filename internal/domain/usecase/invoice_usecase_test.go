@@ -0,0 +1,216 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockInvoiceRepository struct {
+	mock.Mock
+}
+
+func (m *MockInvoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) error {
+	args := m.Called(ctx, invoice)
+	return args.Error(0)
+}
+
+func (m *MockInvoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Invoice, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Invoice), args.Error(1)
+}
+
+func (m *MockInvoiceRepository) Update(ctx context.Context, invoice *entity.Invoice) error {
+	args := m.Called(ctx, invoice)
+	return args.Error(0)
+}
+
+func TestCalculateInvoice(t *testing.T) {
+	invoice := &entity.Invoice{
+		ID: uuid.New(),
+		LineItems: []entity.InvoiceLineItem{
+			{Description: "Consulting", Quantity: 3, UnitAmount: 3333, Discount: 999, TaxName: "VAT", TaxRate: 2000},
+			{Description: "Hosting", Quantity: 1, UnitAmount: 1003, TaxName: "VAT", TaxRate: 2000},
+			{Description: "Books", Quantity: 2, UnitAmount: 1250, TaxName: "VAT", TaxRate: 500},
+			{Description: "Donation", Quantity: 1, UnitAmount: 100},
+		},
+	}
+	calculateInvoice(invoice)
+
+	assert.Equal(t, int64(9000), invoice.LineItems[0].Amount)
+	assert.Equal(t, int64(1800), invoice.LineItems[0].TaxAmount)
+	assert.Equal(t, int64(201), invoice.LineItems[1].TaxAmount, "tax rounds half up per line")
+	assert.Equal(t, int64(125), invoice.LineItems[2].TaxAmount)
+	assert.Equal(t, int64(0), invoice.LineItems[3].TaxAmount)
+
+	assert.Equal(t, int64(9999+1003+2500+100), invoice.Subtotal)
+	assert.Equal(t, int64(999), invoice.DiscountTotal)
+	assert.Equal(t, int64(1800+201+125), invoice.TaxTotal)
+	assert.Equal(t, invoice.Subtotal-invoice.DiscountTotal+invoice.TaxTotal, invoice.Total)
+	assert.Equal(t, []entity.InvoiceTaxLine{
+		{Name: "VAT", Rate: 2000, Taxable: 10003, Amount: 2001},
+		{Name: "VAT", Rate: 500, Taxable: 2500, Amount: 125},
+	}, invoice.TaxLines)
+}
+
+func TestInvoiceUseCase_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	invoiceRepo := new(MockInvoiceRepository)
+	customerRepo := new(MockCustomerRepository)
+	customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+	invoiceRepo.On("Create", ctx, mock.AnythingOfType("*entity.Invoice")).Return(nil)
+	invoiceRepo.On("Update", ctx, mock.AnythingOfType("*entity.Invoice")).Return(nil)
+	uc := NewInvoiceUseCase(invoiceRepo, new(MockMerchantRepository), customerRepo)
+
+	_, err := uc.CreateInvoice(ctx, CreateInvoiceRequest{
+		MerchantID: merchantID, CustomerID: customerID, Currency: "usd",
+		LineItems: []InvoiceLineItemRequest{{Description: "Widget", Quantity: 1, UnitAmount: 100, Discount: 200}},
+	})
+	assert.Equal(t, "invalid_invoice", errors.Code(err), "discount cannot exceed the line amount")
+
+	invoice, err := uc.CreateInvoice(ctx, CreateInvoiceRequest{
+		MerchantID: merchantID, CustomerID: customerID, Currency: "usd",
+		LineItems: []InvoiceLineItemRequest{{Description: "Widget", Quantity: 2, UnitAmount: 500, TaxName: "GST", TaxRate: 1000}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, entity.InvoiceStatusDraft, invoice.Status)
+	assert.Equal(t, "USD", invoice.Currency)
+	assert.Equal(t, int64(1100), invoice.Total)
+	assert.Empty(t, invoice.Number)
+	invoiceRepo.On("GetByID", ctx, invoice.ID).Return(invoice, nil)
+
+	_, err = uc.GetInvoice(ctx, uuid.New(), invoice.ID)
+	assert.Equal(t, "not_found", errors.Code(err), "other merchants cannot see the invoice")
+
+	invoice, err = uc.UpdateInvoice(ctx, UpdateInvoiceRequest{
+		MerchantID: merchantID, ID: invoice.ID,
+		LineItems: []InvoiceLineItemRequest{{Description: "Widget", Quantity: 3, UnitAmount: 500}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500), invoice.Total)
+
+	invoice, err = uc.FinalizeInvoice(ctx, merchantID, invoice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.InvoiceStatusOpen, invoice.Status)
+	assert.Regexp(t, `^INV-\d{8}-[0-9A-F]{8}$`, invoice.Number)
+	assert.NotNil(t, invoice.FinalizedAt)
+
+	_, err = uc.UpdateInvoice(ctx, UpdateInvoiceRequest{MerchantID: merchantID, ID: invoice.ID, Memo: new(string)})
+	assert.Equal(t, "invalid_invoice", errors.Code(err), "open invoices are immutable")
+
+	invoice.Status = entity.InvoiceStatusPaid
+	_, err = uc.VoidInvoice(ctx, merchantID, invoice.ID)
+	assert.Equal(t, "invalid_invoice", errors.Code(err), "paid invoices cannot be voided")
+}
+
+func TestPaymentUseCase_PayInvoice(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	invoiceID := uuid.New()
+
+	newInvoice := func(status entity.InvoiceStatus) *entity.Invoice {
+		return &entity.Invoice{
+			ID: invoiceID, MerchantID: merchantID, CustomerID: customerID, Number: "INV-20240101-ABCDEF12",
+			Currency: "EUR", Status: status, Total: 1500,
+		}
+	}
+
+	tests := []struct {
+		name          string
+		invoice       *entity.Invoice
+		req           CreatePaymentRequest
+		expectedError string
+	}{
+		{
+			name:    "defaults from invoice",
+			invoice: newInvoice(entity.InvoiceStatusOpen),
+			req:     CreatePaymentRequest{MerchantID: merchantID, Method: entity.PaymentMethodBankTransfer},
+		},
+		{
+			name:          "draft invoice",
+			invoice:       newInvoice(entity.InvoiceStatusDraft),
+			req:           CreatePaymentRequest{MerchantID: merchantID, Method: entity.PaymentMethodBankTransfer},
+			expectedError: "invoice status is draft",
+		},
+		{
+			name:          "partial amount",
+			invoice:       newInvoice(entity.InvoiceStatusOpen),
+			req:           CreatePaymentRequest{MerchantID: merchantID, Amount: 1000, Method: entity.PaymentMethodBankTransfer},
+			expectedError: "amount does not match invoice total",
+		},
+		{
+			name:          "other merchant",
+			invoice:       newInvoice(entity.InvoiceStatusOpen),
+			req:           CreatePaymentRequest{MerchantID: uuid.New(), Method: entity.PaymentMethodBankTransfer},
+			expectedError: "invoice not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			merchantRepo := new(MockMerchantRepository)
+			customerRepo := new(MockCustomerRepository)
+			invoiceRepo := new(MockInvoiceRepository)
+			transferRepo := new(MockBankTransferRepository)
+
+			merchantRepo.On("GetByID", ctx, tt.req.MerchantID).Return(&entity.Merchant{ID: tt.req.MerchantID, IsActive: true}, nil)
+			customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+			invoiceRepo.On("GetByID", ctx, invoiceID).Return(tt.invoice, nil)
+			if tt.expectedError == "" {
+				paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+				transferRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankTransfer")).Return(nil)
+			}
+
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), invoiceRepo, new(MockJobRepository), transferRepo, BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+			tt.req.InvoiceID = &invoiceID
+			payment, err := useCase.CreatePayment(ctx, tt.req)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_invoice", errors.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, customerID, payment.CustomerID)
+			assert.Equal(t, "EUR", payment.Currency)
+			assert.Equal(t, int64(1500), payment.Amount)
+			assert.Equal(t, "Invoice INV-20240101-ABCDEF12", payment.Description)
+			assert.Equal(t, &invoiceID, payment.InvoiceID)
+
+			require.NotNil(t, payment.BankTransfer)
+			assert.Equal(t, int64(1500), payment.BankTransfer.AmountExpected)
+
+			// 銀行轉帳款項付足、付款完成後帳單轉為 paid
+			paymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+			paymentRepo.On("TransitionStatus", ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusCompleted).Return(nil)
+			invoiceRepo.On("Update", ctx, tt.invoice).Return(nil)
+			require.NoError(t, useCase.SettlePayment(ctx, payment.ID))
+			assert.Equal(t, entity.InvoiceStatusPaid, tt.invoice.Status)
+			assert.Equal(t, &payment.ID, tt.invoice.PaymentID)
+			assert.NotNil(t, tt.invoice.PaidAt)
+		})
+	}
+}
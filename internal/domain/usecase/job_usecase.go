@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobUseCase 取得並執行背景工作佇列中的工作，由 worker pool 呼叫
+type JobUseCase interface {
+	// ClaimJobs 取得最多 limit 筆可執行的工作並鎖定 VisibilityTimeout
+	ClaimJobs(ctx context.Context, limit int) ([]*entity.Job, error)
+	// RunJob 執行工作並記錄結果：成功標為 succeeded，失敗時依 RetryPolicy 排定重試，
+	// 用盡次數後標為 failed。工作內的讀取都走主庫。回傳的錯誤只代表結果無法寫回
+	RunJob(ctx context.Context, job *entity.Job) error
+}
+
+// RetryPolicy 設定背景工作的重試。第 N 次失敗後等待 BackoffBase*2^(N-1)，最多 BackoffMax
+type RetryPolicy struct {
+	MaxAttempts int
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+	// VisibilityTimeout 為取得工作後的鎖定時間，也是單次執行的逾時；
+	// worker 中斷時工作在逾時後由其他 worker 重新取得
+	VisibilityTimeout time.Duration
+}
+
+// jobHandler 執行一種工作；exhausted 在重試用盡後呼叫，用於把業務資料標為失敗
+type jobHandler struct {
+	run       func(ctx context.Context) error
+	exhausted func(ctx context.Context, cause error) error
+}
+
+type jobUseCase struct {
+	jobRepo  repository.JobRepository
+	payments PaymentUseCase
+	policy   RetryPolicy
+	now      func() time.Time
+}
+
+func NewJobUseCase(jobRepo repository.JobRepository, payments PaymentUseCase, policy RetryPolicy) JobUseCase {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 5
+	}
+	if policy.BackoffBase <= 0 {
+		policy.BackoffBase = time.Second
+	}
+	if policy.BackoffMax < policy.BackoffBase {
+		policy.BackoffMax = policy.BackoffBase
+	}
+	if policy.VisibilityTimeout <= 0 {
+		policy.VisibilityTimeout = 30 * time.Second
+	}
+	return &jobUseCase{
+		jobRepo:  jobRepo,
+		payments: payments,
+		policy:   policy,
+		now:      time.Now,
+	}
+}
+
+func (uc *jobUseCase) ClaimJobs(ctx context.Context, limit int) ([]*entity.Job, error) {
+	jobs, err := uc.jobRepo.Claim(ctx, uc.now(), uc.policy.VisibilityTimeout, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim jobs")
+	}
+	return jobs, nil
+}
+
+func (uc *jobUseCase) RunJob(ctx context.Context, job *entity.Job) error {
+	ctx = repository.WithPrimaryReads(ctx)
+	log := logger.FromContext(ctx).With(
+		zap.String("job_id", job.ID.String()),
+		zap.String("job_type", job.Type),
+		zap.Int("attempt", job.Attempts),
+	)
+
+	handler, err := uc.handler(job)
+	if err != nil {
+		// 無法解析的工作重試也不會成功
+		log.Error("invalid job", zap.Error(err))
+		return uc.fail(ctx, job, handler, err)
+	}
+	if job.Attempts > uc.policy.MaxAttempts {
+		// 最後一次嘗試的 worker 中斷，鎖定逾時後被重新取得
+		return uc.fail(ctx, job, handler, errors.New("job exceeded max attempts"))
+	}
+
+	runCtx, cancel := context.WithTimeout(ctx, uc.policy.VisibilityTimeout)
+	err = handler.run(runCtx)
+	cancel()
+	if err == nil {
+		if err := uc.jobRepo.Complete(ctx, job.ID, job.Attempts); err != nil {
+			return errors.Wrap(err, "failed to complete job")
+		}
+		log.Info("job succeeded")
+		return nil
+	}
+
+	if job.Attempts >= uc.policy.MaxAttempts {
+		log.Error("job failed, giving up", zap.Error(err))
+		return uc.fail(ctx, job, handler, err)
+	}
+
+	delay := uc.backoff(job.Attempts)
+	if err := uc.jobRepo.Retry(ctx, job.ID, job.Attempts, uc.now().Add(delay), err.Error()); err != nil {
+		return errors.Wrap(err, "failed to schedule job retry")
+	}
+	log.Warn("job failed, will retry", zap.Error(err), zap.Duration("retry_in", delay))
+	return nil
+}
+
+// handler 依工作類型解析 payload；無法解析時回傳的 handler 沒有 exhausted
+func (uc *jobUseCase) handler(job *entity.Job) (jobHandler, error) {
+	switch job.Type {
+	case entity.JobTypeProcessPayment:
+		var payload entity.ProcessPaymentJob
+		if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+			return jobHandler{}, errors.Wrap(err, "invalid process_payment payload")
+		}
+		return jobHandler{
+			run: func(ctx context.Context) error {
+				return uc.payments.ExecutePayment(ctx, payload.PaymentID)
+			},
+			exhausted: func(ctx context.Context, cause error) error {
+				return uc.payments.FailPayment(ctx, payload.PaymentID, cause.Error())
+			},
+		}, nil
+	default:
+		return jobHandler{}, errors.New("unknown job type " + job.Type)
+	}
+}
+
+func (uc *jobUseCase) fail(ctx context.Context, job *entity.Job, handler jobHandler, cause error) error {
+	if err := uc.jobRepo.Fail(ctx, job.ID, job.Attempts, cause.Error()); err != nil {
+		return errors.Wrap(err, "failed to mark job failed")
+	}
+	if handler.exhausted != nil {
+		if err := handler.exhausted(ctx, cause); err != nil {
+			return errors.Wrap(err, "failed to handle exhausted job")
+		}
+	}
+	return nil
+}
+
+// backoff 回傳第 attempts 次失敗後到下次重試的等待時間
+func (uc *jobUseCase) backoff(attempts int) time.Duration {
+	delay := uc.policy.BackoffBase
+	for i := 1; i < attempts && delay < uc.policy.BackoffMax; i++ {
+		delay *= 2
+	}
+	if delay > uc.policy.BackoffMax {
+		delay = uc.policy.BackoffMax
+	}
+	return delay
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockJobRepository struct {
+	mock.Mock
+}
+
+func (m *MockJobRepository) Enqueue(ctx context.Context, job *entity.Job) error {
+	args := m.Called(ctx, job)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]*entity.Job, error) {
+	args := m.Called(ctx, now, visibility, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Job), args.Error(1)
+}
+
+func (m *MockJobRepository) Complete(ctx context.Context, id uuid.UUID, attempts int) error {
+	args := m.Called(ctx, id, attempts)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) error {
+	args := m.Called(ctx, id, attempts, runAt, lastError)
+	return args.Error(0)
+}
+
+func (m *MockJobRepository) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error {
+	args := m.Called(ctx, id, attempts, lastError)
+	return args.Error(0)
+}
+
+// containing 比對包含 substr 的錯誤訊息，pkg/errors 的訊息帶有呼叫位置
+func containing(substr string) interface{} {
+	return mock.MatchedBy(func(s string) bool { return strings.Contains(s, substr) })
+}
+
+// newTestJobUseCase 建立最多嘗試 3 次、退避 10 秒到 15 秒的 use case，時間固定為 now
+func newTestJobUseCase(jobRepo *MockJobRepository, payments *MockPaymentUseCase, now time.Time) JobUseCase {
+	uc := NewJobUseCase(jobRepo, payments, RetryPolicy{
+		MaxAttempts:       3,
+		BackoffBase:       10 * time.Second,
+		BackoffMax:        15 * time.Second,
+		VisibilityTimeout: time.Minute,
+	}).(*jobUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestJobUseCase_RunJob(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	paymentID := uuid.New()
+	paymentJob := func(attempts int) *entity.Job {
+		return &entity.Job{
+			ID:       uuid.New(),
+			Type:     entity.JobTypeProcessPayment,
+			Payload:  `{"payment_id":"` + paymentID.String() + `"}`,
+			Status:   entity.JobStatusRunning,
+			Attempts: attempts,
+		}
+	}
+	// 工作內的讀寫都應使用強制讀主庫的 context
+	primary := mock.MatchedBy(func(ctx context.Context) bool { return repository.ReadFromPrimary(ctx) })
+
+	t.Run("completes successful job", func(t *testing.T) {
+		jobRepo := new(MockJobRepository)
+		payments := new(MockPaymentUseCase)
+		job := paymentJob(1)
+		payments.On("ExecutePayment", primary, paymentID).Return(nil)
+		jobRepo.On("Complete", primary, job.ID, 1).Return(nil)
+
+		require.NoError(t, newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job))
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("retries with exponential backoff", func(t *testing.T) {
+		for attempts, delay := range map[int]time.Duration{1: 10 * time.Second, 2: 15 * time.Second} {
+			jobRepo := new(MockJobRepository)
+			payments := new(MockPaymentUseCase)
+			job := paymentJob(attempts)
+			payments.On("ExecutePayment", primary, paymentID).Return(errors.New("gateway timeout"))
+			jobRepo.On("Retry", primary, job.ID, attempts, now.Add(delay), containing("gateway timeout")).Return(nil)
+
+			require.NoError(t, newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job))
+			jobRepo.AssertExpectations(t)
+			payments.AssertNotCalled(t, "FailPayment", mock.Anything, mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("fails payment after last attempt", func(t *testing.T) {
+		jobRepo := new(MockJobRepository)
+		payments := new(MockPaymentUseCase)
+		job := paymentJob(3)
+		payments.On("ExecutePayment", primary, paymentID).Return(errors.New("gateway timeout"))
+		jobRepo.On("Fail", primary, job.ID, 3, containing("gateway timeout")).Return(nil)
+		payments.On("FailPayment", primary, paymentID, containing("gateway timeout")).Return(nil)
+
+		require.NoError(t, newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job))
+		jobRepo.AssertExpectations(t)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("does not run job reclaimed after last attempt", func(t *testing.T) {
+		jobRepo := new(MockJobRepository)
+		payments := new(MockPaymentUseCase)
+		job := paymentJob(4)
+		jobRepo.On("Fail", primary, job.ID, 4, containing("exceeded max attempts")).Return(nil)
+		payments.On("FailPayment", primary, paymentID, containing("exceeded max attempts")).Return(nil)
+
+		require.NoError(t, newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job))
+		payments.AssertNotCalled(t, "ExecutePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("fails unknown job without retry", func(t *testing.T) {
+		jobRepo := new(MockJobRepository)
+		payments := new(MockPaymentUseCase)
+		job := &entity.Job{ID: uuid.New(), Type: "send_fax", Payload: "{}", Attempts: 1}
+		jobRepo.On("Fail", primary, job.ID, 1, containing("unknown job type send_fax")).Return(nil)
+
+		require.NoError(t, newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job))
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("reports lost lock", func(t *testing.T) {
+		jobRepo := new(MockJobRepository)
+		payments := new(MockPaymentUseCase)
+		job := paymentJob(1)
+		payments.On("ExecutePayment", primary, paymentID).Return(nil)
+		jobRepo.On("Complete", primary, job.ID, 1).Return(errors.New("job not found or lock lost"))
+
+		err := newTestJobUseCase(jobRepo, payments, now).RunJob(ctx, job)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "lock lost")
+	})
+}
+
+func TestJobUseCase_ClaimJobs(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	jobRepo := new(MockJobRepository)
+	jobs := []*entity.Job{{ID: uuid.New(), Type: entity.JobTypeProcessPayment, Status: entity.JobStatusRunning, Attempts: 1}}
+	jobRepo.On("Claim", ctx, now, time.Minute, 4).Return(jobs, nil)
+
+	got, err := newTestJobUseCase(jobRepo, new(MockPaymentUseCase), now).ClaimJobs(ctx, 4)
+	require.NoError(t, err)
+	assert.Equal(t, jobs, got)
+}
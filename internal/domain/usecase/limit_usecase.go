@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// LimitUseCase 管理商戶的交易限額。限額以幣別區分，可針對單一付款方式或全部付款方式，
+// 每日用量以 UTC 日期累計，建立付款時計入，付款失敗、取消、逾期或審核拒絕時扣回
+type LimitUseCase interface {
+	// SetLimits 以 limits 取代商戶現有的限額，空陣列表示移除所有限額
+	SetLimits(ctx context.Context, merchantID uuid.UUID, limits []LimitRequest) ([]*entity.MerchantLimit, error)
+	// GetUsage 回傳商戶每個限額與當日的用量
+	GetUsage(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimitStatus, error)
+	// Reserve 檢查付款是否超過商戶限額並累加當日用量，超過時回傳 limit_exceeded
+	Reserve(ctx context.Context, payment *entity.Payment) error
+	// Release 扣回 Reserve 累加的用量，用於付款未能建立或轉為 failed、cancelled 時
+	Release(ctx context.Context, payment *entity.Payment) error
+}
+
+// LimitRequest 的金額與筆數為 0 時表示不限制，但至少要設定一項
+type LimitRequest struct {
+	Method      entity.PaymentMethod `json:"method"`
+	Currency    string               `json:"currency"`
+	MaxAmount   int64                `json:"max_amount"`
+	DailyAmount int64                `json:"daily_amount"`
+	DailyCount  int                  `json:"daily_count"`
+}
+
+type limitUseCase struct {
+	limitRepo    repository.MerchantLimitRepository
+	merchantRepo repository.MerchantRepository
+	now          func() time.Time
+}
+
+func NewLimitUseCase(limitRepo repository.MerchantLimitRepository, merchantRepo repository.MerchantRepository) LimitUseCase {
+	return &limitUseCase{
+		limitRepo:    limitRepo,
+		merchantRepo: merchantRepo,
+		now:          time.Now,
+	}
+}
+
+func (uc *limitUseCase) SetLimits(ctx context.Context, merchantID uuid.UUID, reqs []LimitRequest) ([]*entity.MerchantLimit, error) {
+	if _, err := uc.merchantRepo.GetByID(ctx, merchantID); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get merchant"), "not_found")
+	}
+
+	now := uc.now()
+	limits := make([]*entity.MerchantLimit, 0, len(reqs))
+	seen := make(map[string]bool, len(reqs))
+	for _, req := range reqs {
+		currency := strings.ToUpper(req.Currency)
+		switch req.Method {
+		case "", entity.PaymentMethodCreditCard, entity.PaymentMethodBankTransfer, entity.PaymentMethodDigitalWallet:
+		default:
+			return nil, invalidLimit(fmt.Sprintf("unsupported payment method %q", req.Method))
+		}
+		switch {
+		case len(currency) != 3:
+			return nil, invalidLimit("currency must be a 3-letter code")
+		case req.MaxAmount < 0 || req.DailyAmount < 0 || req.DailyCount < 0:
+			return nil, invalidLimit("limits must not be negative")
+		case req.MaxAmount == 0 && req.DailyAmount == 0 && req.DailyCount == 0:
+			return nil, invalidLimit("at least one of max_amount, daily_amount and daily_count is required")
+		}
+		key := string(req.Method) + "/" + currency
+		if seen[key] {
+			return nil, invalidLimit(fmt.Sprintf("duplicate limit for %s", limitName(req.Method, currency)))
+		}
+		seen[key] = true
+
+		limits = append(limits, &entity.MerchantLimit{
+			ID:          uuid.New(),
+			MerchantID:  merchantID,
+			Method:      req.Method,
+			Currency:    currency,
+			MaxAmount:   req.MaxAmount,
+			DailyAmount: req.DailyAmount,
+			DailyCount:  req.DailyCount,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+	}
+
+	if err := uc.limitRepo.Replace(ctx, merchantID, limits); err != nil {
+		return nil, errors.Wrap(err, "failed to save merchant limits")
+	}
+	logger.FromContext(ctx).Info("merchant limits updated",
+		zap.String("merchant_id", merchantID.String()),
+		zap.Int("limits", len(limits)),
+	)
+	return limits, nil
+}
+
+func (uc *limitUseCase) GetUsage(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimitStatus, error) {
+	limits, err := uc.limitRepo.ListByMerchant(ctx, merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant limits")
+	}
+	day := limitDay(uc.now())
+	usage, err := uc.limitRepo.GetUsage(ctx, merchantID, day)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get limit usage")
+	}
+
+	used := make(map[string]*entity.LimitUsage, len(usage))
+	for _, u := range usage {
+		used[string(u.Method)+"/"+u.Currency] = u
+	}
+	statuses := make([]*entity.MerchantLimitStatus, 0, len(limits))
+	for _, limit := range limits {
+		status := &entity.MerchantLimitStatus{MerchantLimit: *limit, Day: day}
+		if u, ok := used[string(limit.Method)+"/"+limit.Currency]; ok {
+			status.UsedAmount = u.Amount
+			status.UsedCount = u.Count
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (uc *limitUseCase) Reserve(ctx context.Context, payment *entity.Payment) error {
+	limits, err := uc.applicableLimits(ctx, payment)
+	if err != nil || len(limits) == 0 {
+		return err
+	}
+
+	for _, limit := range limits {
+		if limit.MaxAmount > 0 && payment.Amount > limit.MaxAmount {
+			return limitExceeded(fmt.Sprintf("amount exceeds the %s per-payment limit of %d",
+				limitName(limit.Method, limit.Currency), limit.MaxAmount))
+		}
+	}
+
+	exceeded, err := uc.limitRepo.Reserve(ctx, payment.ID, limits, limitDay(payment.CreatedAt), payment.Amount, uc.now())
+	if err != nil {
+		return errors.Wrap(err, "failed to reserve limit usage")
+	}
+	if exceeded != nil {
+		var caps []string
+		if exceeded.DailyAmount > 0 {
+			caps = append(caps, fmt.Sprintf("amount %d", exceeded.DailyAmount))
+		}
+		if exceeded.DailyCount > 0 {
+			caps = append(caps, fmt.Sprintf("%d payments", exceeded.DailyCount))
+		}
+		return limitExceeded(fmt.Sprintf("payment exceeds the %s daily limit (%s)",
+			limitName(exceeded.Method, exceeded.Currency), strings.Join(caps, ", ")))
+	}
+	return nil
+}
+
+// Release 依 Reserve 記錄的用量扣回，付款建立後商戶修改限額也不會扣到其他計數
+func (uc *limitUseCase) Release(ctx context.Context, payment *entity.Payment) error {
+	if err := uc.limitRepo.Release(ctx, payment.ID, uc.now()); err != nil {
+		return errors.Wrap(err, "failed to release limit usage")
+	}
+	return nil
+}
+
+func (uc *limitUseCase) applicableLimits(ctx context.Context, payment *entity.Payment) ([]*entity.MerchantLimit, error) {
+	limits, err := uc.limitRepo.ListByMerchant(ctx, payment.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant limits")
+	}
+	var applicable []*entity.MerchantLimit
+	for _, limit := range limits {
+		if limit.Applies(payment) {
+			applicable = append(applicable, limit)
+		}
+	}
+	return applicable, nil
+}
+
+// limitDay 回傳用量累計的 UTC 日期
+func limitDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+func limitName(method entity.PaymentMethod, currency string) string {
+	if method == "" {
+		return currency
+	}
+	return currency + " " + string(method)
+}
+
+func invalidLimit(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_limit")
+}
+
+func limitExceeded(message string) error {
+	return errors.WithCode(errors.New(message), "limit_exceeded")
+}
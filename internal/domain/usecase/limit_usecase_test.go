@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockMerchantLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockMerchantLimitRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimit, error) {
+	args := m.Called(ctx, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.MerchantLimit), args.Error(1)
+}
+
+func (m *MockMerchantLimitRepository) Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) error {
+	args := m.Called(ctx, merchantID, limits)
+	return args.Error(0)
+}
+
+func (m *MockMerchantLimitRepository) Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (*entity.MerchantLimit, error) {
+	args := m.Called(ctx, paymentID, limits, day, amount, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.MerchantLimit), args.Error(1)
+}
+
+func (m *MockMerchantLimitRepository) Release(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, paymentID, now)
+	return args.Error(0)
+}
+
+func (m *MockMerchantLimitRepository) GetUsage(ctx context.Context, merchantID uuid.UUID, day string) ([]*entity.LimitUsage, error) {
+	args := m.Called(ctx, merchantID, day)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.LimitUsage), args.Error(1)
+}
+
+func newTestLimitUseCase(limitRepo *MockMerchantLimitRepository, merchantRepo *MockMerchantRepository, now time.Time) LimitUseCase {
+	uc := NewLimitUseCase(limitRepo, merchantRepo).(*limitUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestLimitUseCase_SetLimits(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("replaces limits", func(t *testing.T) {
+		limitRepo := new(MockMerchantLimitRepository)
+		merchantRepo := new(MockMerchantRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID}, nil)
+		limitRepo.On("Replace", ctx, merchantID, mock.MatchedBy(func(limits []*entity.MerchantLimit) bool {
+			return len(limits) == 2 && limits[0].Currency == "USD" && limits[0].Method == "" &&
+				limits[1].Method == entity.PaymentMethodCreditCard && limits[1].MerchantID == merchantID
+		})).Return(nil)
+
+		limits, err := newTestLimitUseCase(limitRepo, merchantRepo, now).SetLimits(ctx, merchantID, []LimitRequest{
+			{Currency: "usd", DailyAmount: 10000000},
+			{Method: entity.PaymentMethodCreditCard, Currency: "USD", MaxAmount: 1000000, DailyCount: 50},
+		})
+		require.NoError(t, err)
+		assert.Len(t, limits, 2)
+		limitRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name   string
+		limits []LimitRequest
+		errMsg string
+	}{
+		{name: "unknown method", limits: []LimitRequest{{Method: "cash", Currency: "USD", MaxAmount: 100}}, errMsg: "unsupported payment method"},
+		{name: "invalid currency", limits: []LimitRequest{{Currency: "US", MaxAmount: 100}}, errMsg: "3-letter"},
+		{name: "negative", limits: []LimitRequest{{Currency: "USD", DailyAmount: -1}}, errMsg: "must not be negative"},
+		{name: "no caps", limits: []LimitRequest{{Currency: "USD"}}, errMsg: "at least one"},
+		{name: "duplicate", limits: []LimitRequest{{Currency: "USD", MaxAmount: 100}, {Currency: "usd", DailyCount: 1}}, errMsg: "duplicate limit for USD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limitRepo := new(MockMerchantLimitRepository)
+			merchantRepo := new(MockMerchantRepository)
+			merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID}, nil)
+
+			_, err := newTestLimitUseCase(limitRepo, merchantRepo, now).SetLimits(ctx, merchantID, tt.limits)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+			assert.Equal(t, "invalid_limit", errors.Code(err))
+			limitRepo.AssertNotCalled(t, "Replace", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+
+	t.Run("unknown merchant", func(t *testing.T) {
+		merchantRepo := new(MockMerchantRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(nil, errors.New("merchant not found"))
+
+		_, err := newTestLimitUseCase(new(MockMerchantLimitRepository), merchantRepo, now).SetLimits(ctx, merchantID, nil)
+		require.Error(t, err)
+		assert.Equal(t, "not_found", errors.Code(err))
+	})
+}
+
+func TestLimitUseCase_Reserve(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	now := time.Date(2024, 6, 1, 23, 30, 0, 0, time.UTC)
+	all := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Currency: "USD", MaxAmount: 1000000, DailyAmount: 10000000}
+	card := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Method: entity.PaymentMethodCreditCard, Currency: "USD", DailyCount: 100}
+	wallet := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Method: entity.PaymentMethodDigitalWallet, Currency: "USD", MaxAmount: 100}
+	twd := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Currency: "TWD", MaxAmount: 100}
+	newPayment := func(amount int64) *entity.Payment {
+		// 台北時間 6/2 凌晨仍計入 UTC 的 6/1
+		createdAt := now.In(time.FixedZone("CST", 8*3600))
+		return &entity.Payment{ID: uuid.New(), MerchantID: merchantID, Amount: amount, Currency: "usd", Method: entity.PaymentMethodCreditCard, CreatedAt: createdAt}
+	}
+
+	t.Run("reserves applicable limits", func(t *testing.T) {
+		limitRepo := new(MockMerchantLimitRepository)
+		limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{all, card, wallet, twd}, nil)
+		payment := newPayment(5000)
+		limitRepo.On("Reserve", ctx, payment.ID, []*entity.MerchantLimit{all, card}, "2024-06-01", int64(5000), now).Return(nil, nil)
+
+		err := newTestLimitUseCase(limitRepo, new(MockMerchantRepository), now).Reserve(ctx, payment)
+		require.NoError(t, err)
+		limitRepo.AssertExpectations(t)
+	})
+
+	t.Run("per payment limit", func(t *testing.T) {
+		limitRepo := new(MockMerchantLimitRepository)
+		limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{all, card}, nil)
+
+		err := newTestLimitUseCase(limitRepo, new(MockMerchantRepository), now).Reserve(ctx, newPayment(1000001))
+		require.Error(t, err)
+		assert.Equal(t, "limit_exceeded", errors.Code(err))
+		assert.Contains(t, err.Error(), "USD per-payment limit of 1000000")
+		limitRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("daily limit", func(t *testing.T) {
+		limitRepo := new(MockMerchantLimitRepository)
+		limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{all, card}, nil)
+		limitRepo.On("Reserve", ctx, mock.Anything, mock.Anything, "2024-06-01", int64(5000), now).Return(card, nil)
+
+		err := newTestLimitUseCase(limitRepo, new(MockMerchantRepository), now).Reserve(ctx, newPayment(5000))
+		require.Error(t, err)
+		assert.Equal(t, "limit_exceeded", errors.Code(err))
+		assert.Contains(t, err.Error(), "USD credit_card daily limit (100 payments)")
+	})
+
+	t.Run("no limits", func(t *testing.T) {
+		limitRepo := new(MockMerchantLimitRepository)
+		limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{twd}, nil)
+
+		err := newTestLimitUseCase(limitRepo, new(MockMerchantRepository), now).Reserve(ctx, newPayment(5000))
+		require.NoError(t, err)
+		limitRepo.AssertNotCalled(t, "Reserve", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestLimitUseCase_GetUsage(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	all := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Currency: "USD", DailyAmount: 10000000}
+	card := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Method: entity.PaymentMethodCreditCard, Currency: "USD", DailyCount: 100}
+
+	limitRepo := new(MockMerchantLimitRepository)
+	limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{all, card}, nil)
+	limitRepo.On("GetUsage", ctx, merchantID, "2024-06-01").Return([]*entity.LimitUsage{
+		{MerchantID: merchantID, Currency: "USD", Day: "2024-06-01", Amount: 250000, Count: 3},
+		{MerchantID: merchantID, Method: entity.PaymentMethodBankTransfer, Currency: "USD", Day: "2024-06-01", Amount: 1, Count: 1},
+	}, nil)
+
+	usage, err := newTestLimitUseCase(limitRepo, new(MockMerchantRepository), now).GetUsage(ctx, merchantID)
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	assert.Equal(t, all.ID, usage[0].ID)
+	assert.Equal(t, "2024-06-01", usage[0].Day)
+	assert.Equal(t, int64(250000), usage[0].UsedAmount)
+	assert.Equal(t, 3, usage[0].UsedCount)
+	assert.Equal(t, card.ID, usage[1].ID)
+	assert.Zero(t, usage[1].UsedCount)
+}
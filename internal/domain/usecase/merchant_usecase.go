@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// MerchantUseCase 管理商戶從瀏覽器呼叫 API 時使用的 publishable key 與允許的來源，
+// 以及簽署請求的共用金鑰
+type MerchantUseCase interface {
+	GetMerchant(ctx context.Context, id uuid.UUID) (*entity.Merchant, error)
+	// SetAllowedOrigins 取代商戶允許的來源，空陣列表示不允許任何瀏覽器呼叫
+	SetAllowedOrigins(ctx context.Context, id uuid.UUID, origins []string) (*entity.Merchant, error)
+	// RotatePublishableKey 發行新的 publishable key，舊的 key 立即失效
+	RotatePublishableKey(ctx context.Context, id uuid.UUID) (*entity.Merchant, error)
+	// RotateSigningSecret 發行新的請求簽章金鑰，舊的金鑰立即失效
+	RotateSigningSecret(ctx context.Context, id uuid.UUID) (*entity.Merchant, error)
+}
+
+type merchantUseCase struct {
+	merchantRepo repository.MerchantRepository
+}
+
+func NewMerchantUseCase(merchantRepo repository.MerchantRepository) MerchantUseCase {
+	return &merchantUseCase{
+		merchantRepo: merchantRepo,
+	}
+}
+
+func (uc *merchantUseCase) GetMerchant(ctx context.Context, id uuid.UUID) (*entity.Merchant, error) {
+	merchant, err := uc.merchantRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get merchant"), "not_found")
+	}
+	return merchant, nil
+}
+
+// SetAllowedOrigins 將來源轉為小寫並去除重複。publishable key 可公開取得，
+// 不接受允許所有來源的 *，子網域需以 https://*.example.com 的形式列出
+func (uc *merchantUseCase) SetAllowedOrigins(ctx context.Context, id uuid.UUID, origins []string) (*entity.Merchant, error) {
+	merchant, err := uc.GetMerchant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := make(entity.AllowedOrigins, 0, len(origins))
+	seen := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSpace(origin))
+		if origin == "*" {
+			return nil, invalidOrigin("wildcard origin * is not allowed")
+		}
+		if seen[origin] {
+			continue
+		}
+		seen[origin] = true
+		allowed = append(allowed, origin)
+	}
+	if err := allowed.Validate(); err != nil {
+		return nil, invalidOrigin(err.Error())
+	}
+
+	merchant.AllowedOrigins = allowed
+	if err := uc.merchantRepo.Update(ctx, merchant); err != nil {
+		return nil, errors.Wrap(err, "failed to update merchant")
+	}
+	return merchant, nil
+}
+
+func (uc *merchantUseCase) RotatePublishableKey(ctx context.Context, id uuid.UUID) (*entity.Merchant, error) {
+	merchant, err := uc.GetMerchant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := randomKey("pk_", 16)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate publishable key")
+	}
+	merchant.PublishableKey = key
+	if err := uc.merchantRepo.Update(ctx, merchant); err != nil {
+		return nil, errors.Wrap(err, "failed to update merchant")
+	}
+	return merchant, nil
+}
+
+func (uc *merchantUseCase) RotateSigningSecret(ctx context.Context, id uuid.UUID) (*entity.Merchant, error) {
+	merchant, err := uc.GetMerchant(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomKey("sk_sign_", 32)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate signing secret")
+	}
+	merchant.SigningSecret = secret
+	if err := uc.merchantRepo.Update(ctx, merchant); err != nil {
+		return nil, errors.Wrap(err, "failed to update merchant")
+	}
+	return merchant, nil
+}
+
+func randomKey(prefix string, size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return prefix + hex.EncodeToString(b), nil
+}
+
+func invalidOrigin(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_origin")
+}
@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerchantUseCase_SetAllowedOrigins(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+
+	tests := []struct {
+		name          string
+		origins       []string
+		expected      entity.AllowedOrigins
+		expectedError string
+	}{
+		{
+			name:     "normalizes and removes duplicates",
+			origins:  []string{" https://Shop.Example.com ", "https://shop.example.com", "https://*.example.net", "http://localhost:3000"},
+			expected: entity.AllowedOrigins{"https://shop.example.com", "https://*.example.net", "http://localhost:3000"},
+		},
+		{
+			name:     "empty list disables browser calls",
+			origins:  []string{},
+			expected: entity.AllowedOrigins{},
+		},
+		{name: "rejects wildcard", origins: []string{"*"}, expectedError: "wildcard origin"},
+		{name: "rejects path", origins: []string{"https://shop.example.com/checkout"}, expectedError: "invalid origin"},
+		{name: "rejects other schemes", origins: []string{"ftp://shop.example.com"}, expectedError: "invalid origin"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merchantRepo := new(MockMerchantRepository)
+			merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID}, nil)
+			if tt.expectedError == "" {
+				merchantRepo.On("Update", ctx, mock.MatchedBy(func(m *entity.Merchant) bool {
+					return assert.ObjectsAreEqual(tt.expected, m.AllowedOrigins)
+				})).Return(nil)
+			}
+
+			merchant, err := NewMerchantUseCase(merchantRepo).SetAllowedOrigins(ctx, merchantID, tt.origins)
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_origin", errors.Code(err))
+				merchantRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, merchant.AllowedOrigins)
+			merchantRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestMerchantUseCase_RotatePublishableKey(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+
+	merchantRepo := new(MockMerchantRepository)
+	merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, PublishableKey: "pk_old"}, nil)
+	merchantRepo.On("Update", ctx, mock.AnythingOfType("*entity.Merchant")).Return(nil)
+
+	merchant, err := NewMerchantUseCase(merchantRepo).RotatePublishableKey(ctx, merchantID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "pk_old", merchant.PublishableKey)
+	assert.True(t, strings.HasPrefix(merchant.PublishableKey, "pk_"))
+	assert.Len(t, merchant.PublishableKey, len("pk_")+32)
+	merchantRepo.AssertExpectations(t)
+}
+
+func TestMerchantUseCase_RotateSigningSecret(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+
+	merchantRepo := new(MockMerchantRepository)
+	merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, SigningSecret: "old"}, nil)
+	merchantRepo.On("Update", ctx, mock.AnythingOfType("*entity.Merchant")).Return(nil)
+
+	merchant, err := NewMerchantUseCase(merchantRepo).RotateSigningSecret(ctx, merchantID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "old", merchant.SigningSecret)
+	assert.True(t, strings.HasPrefix(merchant.SigningSecret, "sk_sign_"))
+	assert.Len(t, merchant.SigningSecret, len("sk_sign_")+64)
+	merchantRepo.AssertExpectations(t)
+}
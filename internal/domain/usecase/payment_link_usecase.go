@@ -0,0 +1,302 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type PaymentLinkUseCase interface {
+	CreateLink(ctx context.Context, req CreatePaymentLinkRequest) (*entity.PaymentLink, error)
+	GetLink(ctx context.Context, merchantID, id uuid.UUID) (*entity.PaymentLink, error)
+	ListLinks(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error)
+	// SetActive 停用或重新啟用連結，已開始的結帳在完成時仍會檢查連結是否可用
+	SetActive(ctx context.Context, merchantID, id uuid.UUID, active bool) (*entity.PaymentLink, error)
+	// ListPayments 與 GetStats 提供每個連結的付款報表
+	ListPayments(ctx context.Context, merchantID, id uuid.UUID, limit, offset int) ([]*entity.Payment, error)
+	GetStats(ctx context.Context, merchantID, id uuid.UUID) (*entity.PaymentLinkStats, error)
+
+	// GetHostedLink 供公開付款頁使用，不驗證商戶身分
+	GetHostedLink(ctx context.Context, id uuid.UUID) (*HostedPaymentLink, error)
+	// StartCheckout 以付款頁填寫的資料找出或建立客戶，並建立屬於連結的結帳
+	StartCheckout(ctx context.Context, req StartPaymentLinkRequest) (*entity.CheckoutSession, error)
+}
+
+type CreatePaymentLinkRequest struct {
+	MerchantID  uuid.UUID `json:"-"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	// Amount 為 0 時由客戶在付款頁輸入金額
+	Amount         int64                  `json:"amount"`
+	Currency       string                 `json:"currency"`
+	AllowedMethods []entity.PaymentMethod `json:"allowed_methods"`
+	SingleUse      bool                   `json:"single_use"`
+	QuantityLimit  *int                   `json:"quantity_limit"`
+	SuccessURL     string                 `json:"success_url"`
+	ExpiresAt      *time.Time             `json:"expires_at"`
+}
+
+// StartPaymentLinkRequest 來自公開付款頁的表單；Amount 只用於由客戶輸入金額的連結
+type StartPaymentLinkRequest struct {
+	LinkID uuid.UUID
+	Name   string `redact:"name"`
+	Email  string `redact:"email"`
+	Amount int64
+}
+
+// HostedPaymentLink 為公開付款頁顯示所需的連結與商戶資料
+type HostedPaymentLink struct {
+	Link     *entity.PaymentLink
+	Merchant *entity.Merchant
+	// Available 為 false 時付款頁不顯示表單
+	Available bool
+}
+
+type paymentLinkUseCase struct {
+	linkRepo        repository.PaymentLinkRepository
+	paymentRepo     repository.PaymentRepository
+	merchantRepo    repository.MerchantRepository
+	customerRepo    repository.CustomerRepository
+	checkoutUseCase CheckoutUseCase
+	baseURL         string
+}
+
+// NewPaymentLinkUseCase 建立付款連結；baseURL 與結帳相同，用於組成付款頁網址
+func NewPaymentLinkUseCase(
+	linkRepo repository.PaymentLinkRepository,
+	paymentRepo repository.PaymentRepository,
+	merchantRepo repository.MerchantRepository,
+	customerRepo repository.CustomerRepository,
+	checkoutUseCase CheckoutUseCase,
+	baseURL string,
+) PaymentLinkUseCase {
+	return &paymentLinkUseCase{
+		linkRepo:        linkRepo,
+		paymentRepo:     paymentRepo,
+		merchantRepo:    merchantRepo,
+		customerRepo:    customerRepo,
+		checkoutUseCase: checkoutUseCase,
+		baseURL:         strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func (uc *paymentLinkUseCase) CreateLink(ctx context.Context, req CreatePaymentLinkRequest) (*entity.PaymentLink, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidPaymentLink("name is required")
+	}
+	if req.Amount < 0 {
+		return nil, invalidPaymentLink("amount must not be negative")
+	}
+	if len(req.Currency) != 3 {
+		return nil, invalidPaymentLink("currency must be a 3-letter code")
+	}
+	if req.SuccessURL != "" {
+		if err := validateRedirectURL(req.SuccessURL); err != nil {
+			return nil, invalidPaymentLink("invalid success_url: " + err.Error())
+		}
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, invalidPaymentLink("expires_at must be in the future")
+	}
+
+	limit := req.QuantityLimit
+	if limit != nil && *limit < 1 {
+		return nil, invalidPaymentLink("quantity_limit must be at least 1")
+	}
+	if req.SingleUse {
+		if limit != nil && *limit != 1 {
+			return nil, invalidPaymentLink("single_use links have a quantity_limit of 1")
+		}
+		one := 1
+		limit = &one
+	}
+
+	methods, err := allowedMethods(req.AllowedMethods)
+	if err != nil {
+		return nil, errors.WithCode(err, "invalid_payment_link")
+	}
+
+	now := time.Now()
+	link := &entity.PaymentLink{
+		ID:             uuid.New(),
+		MerchantID:     req.MerchantID,
+		Name:           name,
+		Description:    req.Description,
+		Amount:         req.Amount,
+		Currency:       strings.ToUpper(req.Currency),
+		SingleUse:      req.SingleUse,
+		QuantityLimit:  limit,
+		AllowedMethods: methods,
+		SuccessURL:     req.SuccessURL,
+		IsActive:       true,
+		ExpiresAt:      req.ExpiresAt,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := uc.linkRepo.Create(ctx, link); err != nil {
+		return nil, errors.Wrap(err, "failed to create payment link")
+	}
+
+	logger.FromContext(ctx).Info("payment link created",
+		zap.String("payment_link_id", link.ID.String()),
+		zap.Int64("amount", link.Amount),
+		zap.String("currency", link.Currency),
+	)
+	return uc.withURL(link), nil
+}
+
+func (uc *paymentLinkUseCase) GetLink(ctx context.Context, merchantID, id uuid.UUID) (*entity.PaymentLink, error) {
+	link, err := uc.linkRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment link"), "not_found")
+	}
+	if link.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("payment link not found"), "not_found")
+	}
+	return uc.withURL(link), nil
+}
+
+func (uc *paymentLinkUseCase) ListLinks(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error) {
+	links, err := uc.linkRepo.GetByMerchantID(ctx, merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment links")
+	}
+	for _, link := range links {
+		uc.withURL(link)
+	}
+	return links, nil
+}
+
+func (uc *paymentLinkUseCase) SetActive(ctx context.Context, merchantID, id uuid.UUID, active bool) (*entity.PaymentLink, error) {
+	link, err := uc.GetLink(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.linkRepo.SetActive(ctx, id, active); err != nil {
+		return nil, errors.Wrap(err, "failed to update payment link")
+	}
+	link.IsActive = active
+	link.UpdatedAt = time.Now()
+
+	logger.FromContext(ctx).Info("payment link updated",
+		zap.String("payment_link_id", link.ID.String()),
+		zap.Bool("is_active", active),
+	)
+	return link, nil
+}
+
+func (uc *paymentLinkUseCase) ListPayments(ctx context.Context, merchantID, id uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	if _, err := uc.GetLink(ctx, merchantID, id); err != nil {
+		return nil, err
+	}
+	payments, err := uc.paymentRepo.GetByPaymentLinkID(ctx, id, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment link payments")
+	}
+	return payments, nil
+}
+
+func (uc *paymentLinkUseCase) GetStats(ctx context.Context, merchantID, id uuid.UUID) (*entity.PaymentLinkStats, error) {
+	if _, err := uc.GetLink(ctx, merchantID, id); err != nil {
+		return nil, err
+	}
+	stats, err := uc.linkRepo.GetStats(ctx, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get payment link stats")
+	}
+	return stats, nil
+}
+
+func (uc *paymentLinkUseCase) GetHostedLink(ctx context.Context, id uuid.UUID) (*HostedPaymentLink, error) {
+	link, err := uc.linkRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment link"), "not_found")
+	}
+	merchant, err := uc.merchantRepo.GetByID(ctx, link.MerchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get merchant")
+	}
+	return &HostedPaymentLink{
+		Link:      uc.withURL(link),
+		Merchant:  merchant,
+		Available: link.Available(time.Now()) && merchant.IsActive,
+	}, nil
+}
+
+func (uc *paymentLinkUseCase) StartCheckout(ctx context.Context, req StartPaymentLinkRequest) (*entity.CheckoutSession, error) {
+	hosted, err := uc.GetHostedLink(ctx, req.LinkID)
+	if err != nil {
+		return nil, err
+	}
+	if !hosted.Available {
+		return nil, invalidPaymentLink("this payment link is no longer available")
+	}
+	link := hosted.Link
+
+	amount := link.Amount
+	if amount == 0 {
+		if req.Amount <= 0 {
+			return nil, invalidPaymentLink("please enter an amount")
+		}
+		amount = req.Amount
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalidPaymentLink("name is required")
+	}
+	email := strings.TrimSpace(req.Email)
+	if !validEmail(email) {
+		return nil, invalidPaymentLink("a valid email is required")
+	}
+
+	customer, err := findOrCreateCustomer(ctx, uc.customerRepo, email, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// 商戶未指定 success_url 時導回本服務的完成頁，取消則回到連結頁
+	successURL := link.SuccessURL
+	if successURL == "" {
+		successURL = link.URL + "/complete"
+	}
+	session, err := uc.checkoutUseCase.CreateSession(ctx, CreateCheckoutSessionRequest{
+		MerchantID:     link.MerchantID,
+		CustomerID:     &customer.ID,
+		Amount:         amount,
+		Currency:       link.Currency,
+		Description:    link.Name,
+		AllowedMethods: link.AllowedMethods,
+		SuccessURL:     successURL,
+		CancelURL:      link.URL,
+		PaymentLinkID:  &link.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("payment link checkout started",
+		zap.String("payment_link_id", link.ID.String()),
+		zap.String("checkout_session_id", session.ID.String()),
+		zap.String("customer_id", customer.ID.String()),
+	)
+	return session, nil
+}
+
+func (uc *paymentLinkUseCase) withURL(link *entity.PaymentLink) *entity.PaymentLink {
+	if uc.baseURL != "" {
+		link.URL = uc.baseURL + "/pay/" + link.ID.String()
+	}
+	return link
+}
+
+func invalidPaymentLink(msg string) error {
+	return errors.WithCode(errors.New(msg), "invalid_payment_link")
+}
@@ -0,0 +1,215 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPaymentLinkRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentLinkRepository) Create(ctx context.Context, link *entity.PaymentLink) error {
+	args := m.Called(ctx, link)
+	return args.Error(0)
+}
+
+func (m *MockPaymentLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentLink, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PaymentLink), args.Error(1)
+}
+
+func (m *MockPaymentLinkRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.PaymentLink), args.Error(1)
+}
+
+func (m *MockPaymentLinkRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	args := m.Called(ctx, id, active)
+	return args.Error(0)
+}
+
+func (m *MockPaymentLinkRepository) ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, id, now)
+	return args.Error(0)
+}
+
+func (m *MockPaymentLinkRepository) ReleasePayment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentLinkRepository) GetStats(ctx context.Context, id uuid.UUID) (*entity.PaymentLinkStats, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PaymentLinkStats), args.Error(1)
+}
+
+// newTestPaymentLinkUseCase 以真實的結帳 use case 建立付款連結 use case，兩者共用 mock
+func newTestPaymentLinkUseCase(linkRepo *MockPaymentLinkRepository, sessionRepo *MockCheckoutSessionRepository, merchantRepo *MockMerchantRepository, customerRepo *MockCustomerRepository) PaymentLinkUseCase {
+	checkout := NewCheckoutUseCase(sessionRepo, linkRepo, merchantRepo, customerRepo, new(MockPaymentUseCase), NewVaultUseCase(new(MockCardRepository), reverseCipher{}), "https://pay.example.com/")
+	return NewPaymentLinkUseCase(linkRepo, new(MockPaymentRepository), merchantRepo, customerRepo, checkout, "https://pay.example.com")
+}
+
+func TestPaymentLinkUseCase_CreateLink(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", IsActive: true}
+	valid := func() CreatePaymentLinkRequest {
+		return CreatePaymentLinkRequest{Name: "T-shirt", Amount: 1999, Currency: "usd"}
+	}
+	past := time.Now().Add(-time.Hour)
+	zero, three := 0, 3
+
+	tests := []struct {
+		name          string
+		modify        func(req *CreatePaymentLinkRequest)
+		expectedError string
+	}{
+		{name: "missing name", modify: func(req *CreatePaymentLinkRequest) { req.Name = "  " }, expectedError: "name is required"},
+		{name: "negative amount", modify: func(req *CreatePaymentLinkRequest) { req.Amount = -1 }, expectedError: "amount must not be negative"},
+		{name: "relative success url", modify: func(req *CreatePaymentLinkRequest) { req.SuccessURL = "/thanks" }, expectedError: "invalid success_url"},
+		{name: "expired", modify: func(req *CreatePaymentLinkRequest) { req.ExpiresAt = &past }, expectedError: "expires_at must be in the future"},
+		{name: "zero quantity", modify: func(req *CreatePaymentLinkRequest) { req.QuantityLimit = &zero }, expectedError: "quantity_limit must be at least 1"},
+		{name: "single use with quantity", modify: func(req *CreatePaymentLinkRequest) { req.SingleUse, req.QuantityLimit = true, &three }, expectedError: "single_use"},
+		{name: "unknown method", modify: func(req *CreatePaymentLinkRequest) { req.AllowedMethods = []entity.PaymentMethod{"cash"} }, expectedError: "unsupported payment method"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase := newTestPaymentLinkUseCase(new(MockPaymentLinkRepository), new(MockCheckoutSessionRepository), new(MockMerchantRepository), new(MockCustomerRepository))
+			req := valid()
+			tt.modify(&req)
+			_, err := useCase.CreateLink(ctx, req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Equal(t, "invalid_payment_link", errors.Code(err))
+		})
+	}
+
+	t.Run("single use", func(t *testing.T) {
+		linkRepo := new(MockPaymentLinkRepository)
+		merchantRepo := new(MockMerchantRepository)
+		linkRepo.On("Create", ctx, mock.AnythingOfType("*entity.PaymentLink")).Return(nil)
+		merchantRepo.On("GetByID", ctx, merchant.ID).Return(merchant, nil)
+		req := valid()
+		req.MerchantID = merchant.ID
+		req.SingleUse = true
+
+		link, err := newTestPaymentLinkUseCase(linkRepo, new(MockCheckoutSessionRepository), merchantRepo, new(MockCustomerRepository)).CreateLink(ctx, req)
+		require.NoError(t, err)
+		assert.Equal(t, "USD", link.Currency)
+		require.NotNil(t, link.QuantityLimit)
+		assert.Equal(t, 1, *link.QuantityLimit)
+		assert.True(t, link.IsActive)
+		assert.Len(t, link.AllowedMethods, 3, "all methods allowed by default")
+		assert.Equal(t, "https://pay.example.com/pay/"+link.ID.String(), link.URL)
+	})
+}
+
+func TestPaymentLinkUseCase_StartCheckout(t *testing.T) {
+	ctx := context.Background()
+	merchant := &entity.Merchant{ID: uuid.New(), Name: "Shop", IsActive: true}
+	newLink := func() *entity.PaymentLink {
+		return &entity.PaymentLink{
+			ID: uuid.New(), MerchantID: merchant.ID, Name: "T-shirt", Amount: 1999, Currency: "USD",
+			AllowedMethods: []entity.PaymentMethod{entity.PaymentMethodCreditCard},
+			IsActive:       true,
+		}
+	}
+
+	t.Run("reuses customer and opens checkout", func(t *testing.T) {
+		link := newLink()
+		link.Amount = 0
+		customer := &entity.Customer{ID: uuid.New(), Name: "Buyer", Email: "buyer@example.com"}
+		linkRepo := new(MockPaymentLinkRepository)
+		sessionRepo := new(MockCheckoutSessionRepository)
+		merchantRepo := new(MockMerchantRepository)
+		customerRepo := new(MockCustomerRepository)
+		linkRepo.On("GetByID", ctx, link.ID).Return(link, nil)
+		merchantRepo.On("GetByID", ctx, merchant.ID).Return(merchant, nil)
+		customerRepo.On("GetByEmail", ctx, "buyer@example.com").Return(customer, nil)
+		customerRepo.On("GetByID", ctx, customer.ID).Return(customer, nil)
+		sessionRepo.On("Create", ctx, mock.AnythingOfType("*entity.CheckoutSession")).Return(nil)
+
+		session, err := newTestPaymentLinkUseCase(linkRepo, sessionRepo, merchantRepo, customerRepo).StartCheckout(ctx, StartPaymentLinkRequest{
+			LinkID: link.ID, Name: "Buyer", Email: " buyer@example.com ", Amount: 500,
+		})
+		require.NoError(t, err)
+		customerRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		assert.Equal(t, &customer.ID, session.CustomerID)
+		assert.Equal(t, int64(500), session.Amount, "customer-chosen amount")
+		assert.Equal(t, "T-shirt", session.Description)
+		require.NotNil(t, session.PaymentLinkID)
+		assert.Equal(t, link.ID, *session.PaymentLinkID)
+		assert.Equal(t, "https://pay.example.com/pay/"+link.ID.String()+"/complete", session.SuccessURL)
+		assert.Equal(t, "https://pay.example.com/pay/"+link.ID.String(), session.CancelURL)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		limit := 1
+		tests := []struct {
+			name          string
+			modify        func(link *entity.PaymentLink, req *StartPaymentLinkRequest)
+			expectedError string
+		}{
+			{name: "sold out", modify: func(link *entity.PaymentLink, req *StartPaymentLinkRequest) {
+				link.QuantityLimit, link.PaymentCount = &limit, 1
+			}, expectedError: "no longer available"},
+			{name: "inactive", modify: func(link *entity.PaymentLink, req *StartPaymentLinkRequest) { link.IsActive = false }, expectedError: "no longer available"},
+			{name: "missing amount", modify: func(link *entity.PaymentLink, req *StartPaymentLinkRequest) { link.Amount = 0 }, expectedError: "please enter an amount"},
+			{name: "missing name", modify: func(link *entity.PaymentLink, req *StartPaymentLinkRequest) { req.Name = "" }, expectedError: "name is required"},
+			{name: "invalid email", modify: func(link *entity.PaymentLink, req *StartPaymentLinkRequest) { req.Email = "buyer" }, expectedError: "a valid email is required"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				link := newLink()
+				req := StartPaymentLinkRequest{LinkID: link.ID, Name: "Buyer", Email: "buyer@example.com"}
+				tt.modify(link, &req)
+				linkRepo := new(MockPaymentLinkRepository)
+				sessionRepo := new(MockCheckoutSessionRepository)
+				merchantRepo := new(MockMerchantRepository)
+				linkRepo.On("GetByID", ctx, link.ID).Return(link, nil)
+				merchantRepo.On("GetByID", ctx, merchant.ID).Return(merchant, nil)
+
+				_, err := newTestPaymentLinkUseCase(linkRepo, sessionRepo, merchantRepo, new(MockCustomerRepository)).StartCheckout(ctx, req)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_payment_link", errors.Code(err))
+				sessionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			})
+		}
+	})
+}
+
+func TestPaymentLinkUseCase_GetStats(t *testing.T) {
+	ctx := context.Background()
+	link := &entity.PaymentLink{ID: uuid.New(), MerchantID: uuid.New(), Name: "T-shirt", Amount: 1999, Currency: "USD", IsActive: true}
+	stats := &entity.PaymentLinkStats{PaymentLinkID: link.ID, Payments: 2, Completed: 1, AmountCollected: 1999, Currency: "USD"}
+	linkRepo := new(MockPaymentLinkRepository)
+	linkRepo.On("GetByID", ctx, link.ID).Return(link, nil)
+	linkRepo.On("GetStats", ctx, link.ID).Return(stats, nil)
+	useCase := newTestPaymentLinkUseCase(linkRepo, new(MockCheckoutSessionRepository), new(MockMerchantRepository), new(MockCustomerRepository))
+
+	got, err := useCase.GetStats(ctx, link.MerchantID, link.ID)
+	require.NoError(t, err)
+	assert.Equal(t, stats, got)
+
+	// 其他商戶的連結視為不存在
+	_, err = useCase.GetStats(ctx, uuid.New(), link.ID)
+	assert.Equal(t, "not_found", errors.Code(err))
+}
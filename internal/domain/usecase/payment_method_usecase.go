@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/card"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type PaymentMethodUseCase interface {
+	CreatePaymentMethod(ctx context.Context, req CreatePaymentMethodRequest) (*entity.PaymentMethodRecord, error)
+	GetPaymentMethod(ctx context.Context, merchantID, customerID, id uuid.UUID) (*entity.PaymentMethodRecord, error)
+	ListPaymentMethods(ctx context.Context, merchantID, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error)
+	UpdatePaymentMethod(ctx context.Context, req UpdatePaymentMethodRequest) (*entity.PaymentMethodRecord, error)
+	DeletePaymentMethod(ctx context.Context, merchantID, customerID, id uuid.UUID) error
+}
+
+// CreatePaymentMethodRequest 的 Token 對信用卡為卡片保險庫 token，卡別、末四碼與效期會從保險庫帶入；
+// 其他付款方式為外部帳戶或錢包的參考值
+type CreatePaymentMethodRequest struct {
+	MerchantID     uuid.UUID             `json:"-"`
+	CustomerID     uuid.UUID             `json:"-"`
+	Type           entity.PaymentMethod  `json:"type"`
+	Token          string                `json:"token"`
+	Last4          string                `json:"last4"`
+	BillingDetails entity.BillingDetails `json:"billing_details"`
+	IsDefault      bool                  `json:"is_default"`
+}
+
+// UpdatePaymentMethodRequest 只更新有提供的欄位
+type UpdatePaymentMethodRequest struct {
+	MerchantID     uuid.UUID              `json:"-"`
+	CustomerID     uuid.UUID              `json:"-"`
+	ID             uuid.UUID              `json:"-"`
+	ExpMonth       *int                   `json:"exp_month"`
+	ExpYear        *int                   `json:"exp_year"`
+	BillingDetails *entity.BillingDetails `json:"billing_details"`
+	IsDefault      *bool                  `json:"is_default"`
+}
+
+type paymentMethodUseCase struct {
+	paymentMethodRepo repository.PaymentMethodRepository
+	customerRepo      repository.CustomerRepository
+	cardRepo          repository.CardRepository
+}
+
+func NewPaymentMethodUseCase(
+	paymentMethodRepo repository.PaymentMethodRepository,
+	customerRepo repository.CustomerRepository,
+	cardRepo repository.CardRepository,
+) PaymentMethodUseCase {
+	return &paymentMethodUseCase{
+		paymentMethodRepo: paymentMethodRepo,
+		customerRepo:      customerRepo,
+		cardRepo:          cardRepo,
+	}
+}
+
+func (uc *paymentMethodUseCase) CreatePaymentMethod(ctx context.Context, req CreatePaymentMethodRequest) (*entity.PaymentMethodRecord, error) {
+	if _, err := uc.customerRepo.GetByID(ctx, req.CustomerID); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get customer"), "not_found")
+	}
+	if req.Token == "" {
+		return nil, errors.WithCode(errors.New("token is required"), "invalid_payment_method")
+	}
+
+	now := time.Now()
+	method := &entity.PaymentMethodRecord{
+		ID:             uuid.New(),
+		CustomerID:     req.CustomerID,
+		MerchantID:     req.MerchantID,
+		Type:           req.Type,
+		Token:          req.Token,
+		Last4:          req.Last4,
+		BillingDetails: req.BillingDetails,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+
+	switch req.Type {
+	case entity.PaymentMethodCreditCard:
+		c, err := uc.cardRepo.GetByToken(ctx, req.Token)
+		if err != nil || c.MerchantID != req.MerchantID {
+			return nil, errors.WithCode(errors.New("card not found"), "invalid_payment_method")
+		}
+		if card.Expired(c.ExpMonth, c.ExpYear, now) {
+			return nil, errors.WithCode(errors.New("card is expired"), "invalid_payment_method")
+		}
+		method.Last4 = c.Last4
+		method.Brand = c.Brand
+		method.ExpMonth = c.ExpMonth
+		method.ExpYear = c.ExpYear
+	case entity.PaymentMethodBankTransfer, entity.PaymentMethodDigitalWallet:
+		if len(method.Last4) > 4 {
+			method.Last4 = method.Last4[len(method.Last4)-4:]
+		}
+	default:
+		return nil, errors.WithCode(errors.New("unsupported payment method type"), "invalid_payment_method")
+	}
+
+	existing, err := uc.paymentMethodRepo.ListByCustomerID(ctx, req.CustomerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment methods")
+	}
+	// 第一個付款方式自動成為預設
+	makeDefault := req.IsDefault || len(existing) == 0
+
+	if err := uc.paymentMethodRepo.Create(ctx, method); err != nil {
+		return nil, errors.Wrap(err, "failed to create payment method")
+	}
+	if makeDefault {
+		if err := uc.paymentMethodRepo.SetDefault(ctx, req.CustomerID, method.ID); err != nil {
+			return nil, errors.Wrap(err, "failed to set default payment method")
+		}
+		method.IsDefault = true
+	}
+	return method, nil
+}
+
+func (uc *paymentMethodUseCase) GetPaymentMethod(ctx context.Context, merchantID, customerID, id uuid.UUID) (*entity.PaymentMethodRecord, error) {
+	method, err := uc.paymentMethodRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment method"), "not_found")
+	}
+	// 其他客戶或其他商戶建立的付款方式一律視為不存在
+	if method.CustomerID != customerID || method.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("payment method not found"), "not_found")
+	}
+	return method, nil
+}
+
+func (uc *paymentMethodUseCase) ListPaymentMethods(ctx context.Context, merchantID, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error) {
+	methods, err := uc.paymentMethodRepo.ListByCustomerID(ctx, customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment methods")
+	}
+	result := make([]*entity.PaymentMethodRecord, 0, len(methods))
+	for _, method := range methods {
+		if method.MerchantID == merchantID {
+			result = append(result, method)
+		}
+	}
+	return result, nil
+}
+
+func (uc *paymentMethodUseCase) UpdatePaymentMethod(ctx context.Context, req UpdatePaymentMethodRequest) (*entity.PaymentMethodRecord, error) {
+	method, err := uc.GetPaymentMethod(ctx, req.MerchantID, req.CustomerID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExpMonth != nil {
+		method.ExpMonth = *req.ExpMonth
+	}
+	if req.ExpYear != nil {
+		method.ExpYear = *req.ExpYear
+	}
+	if (req.ExpMonth != nil || req.ExpYear != nil) && card.Expired(method.ExpMonth, method.ExpYear, time.Now()) {
+		return nil, errors.WithCode(errors.New("card is expired"), "invalid_payment_method")
+	}
+	if req.BillingDetails != nil {
+		method.BillingDetails = *req.BillingDetails
+	}
+	method.UpdatedAt = time.Now()
+
+	if err := uc.paymentMethodRepo.Update(ctx, method); err != nil {
+		return nil, errors.Wrap(err, "failed to update payment method")
+	}
+	if req.IsDefault != nil && *req.IsDefault && !method.IsDefault {
+		if err := uc.paymentMethodRepo.SetDefault(ctx, method.CustomerID, method.ID); err != nil {
+			return nil, errors.Wrap(err, "failed to set default payment method")
+		}
+		method.IsDefault = true
+	}
+	return method, nil
+}
+
+func (uc *paymentMethodUseCase) DeletePaymentMethod(ctx context.Context, merchantID, customerID, id uuid.UUID) error {
+	method, err := uc.GetPaymentMethod(ctx, merchantID, customerID, id)
+	if err != nil {
+		return err
+	}
+	if err := uc.paymentMethodRepo.Delete(ctx, id); err != nil {
+		return errors.Wrap(err, "failed to delete payment method")
+	}
+
+	// 刪除預設付款方式後，由最新的付款方式遞補
+	if method.IsDefault {
+		remaining, err := uc.paymentMethodRepo.ListByCustomerID(ctx, customerID)
+		if err != nil {
+			return errors.Wrap(err, "failed to list payment methods")
+		}
+		if len(remaining) > 0 {
+			if err := uc.paymentMethodRepo.SetDefault(ctx, customerID, remaining[0].ID); err != nil {
+				return errors.Wrap(err, "failed to set default payment method")
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentMethodUseCase_CreatePaymentMethod(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	tests := []struct {
+		name            string
+		request         CreatePaymentMethodRequest
+		card            *entity.Card
+		existing        []*entity.PaymentMethodRecord
+		expectedError   string
+		expectedDefault bool
+	}{
+		{
+			name:            "first card becomes default",
+			request:         CreatePaymentMethodRequest{Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			card:            &entity.Card{Token: "tok_valid", MerchantID: merchantID, Brand: "visa", Last4: "1111", ExpMonth: 12, ExpYear: 2099},
+			expectedDefault: true,
+		},
+		{
+			name:     "additional card is not default",
+			request:  CreatePaymentMethodRequest{Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			card:     &entity.Card{Token: "tok_valid", MerchantID: merchantID, Brand: "visa", Last4: "1111", ExpMonth: 12, ExpYear: 2099},
+			existing: []*entity.PaymentMethodRecord{{ID: uuid.New(), IsDefault: true}},
+		},
+		{
+			name:          "card token from another merchant",
+			request:       CreatePaymentMethodRequest{Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			card:          &entity.Card{Token: "tok_valid", MerchantID: uuid.New(), ExpMonth: 12, ExpYear: 2099},
+			expectedError: "card not found",
+		},
+		{
+			name:          "unsupported type",
+			request:       CreatePaymentMethodRequest{Type: "cash", Token: "ref"},
+			expectedError: "unsupported payment method type",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			methodRepo := new(MockPaymentMethodRepository)
+			customerRepo := new(MockCustomerRepository)
+			cardRepo := new(MockCardRepository)
+
+			customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+			if tt.card != nil {
+				cardRepo.On("GetByToken", ctx, tt.request.Token).Return(tt.card, nil)
+			}
+			if tt.expectedError == "" {
+				methodRepo.On("ListByCustomerID", ctx, customerID).Return(tt.existing, nil)
+				methodRepo.On("Create", ctx, mock.AnythingOfType("*entity.PaymentMethodRecord")).Return(nil)
+				if tt.expectedDefault {
+					methodRepo.On("SetDefault", ctx, customerID, mock.AnythingOfType("uuid.UUID")).Return(nil)
+				}
+			}
+
+			req := tt.request
+			req.MerchantID = merchantID
+			req.CustomerID = customerID
+			method, err := NewPaymentMethodUseCase(methodRepo, customerRepo, cardRepo).CreatePaymentMethod(ctx, req)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_payment_method", errors.Code(err))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.card.Last4, method.Last4)
+			assert.Equal(t, tt.card.Brand, method.Brand)
+			assert.Equal(t, tt.expectedDefault, method.IsDefault)
+			methodRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentMethodUseCase_DeleteDefaultPromotesNewest(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	deleted := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: merchantID, CustomerID: customerID, IsDefault: true}
+	newest := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: merchantID, CustomerID: customerID}
+
+	methodRepo := new(MockPaymentMethodRepository)
+	methodRepo.On("GetByID", ctx, deleted.ID).Return(deleted, nil)
+	methodRepo.On("Delete", ctx, deleted.ID).Return(nil)
+	methodRepo.On("ListByCustomerID", ctx, customerID).Return([]*entity.PaymentMethodRecord{newest}, nil)
+	methodRepo.On("SetDefault", ctx, customerID, newest.ID).Return(nil)
+
+	uc := NewPaymentMethodUseCase(methodRepo, new(MockCustomerRepository), new(MockCardRepository))
+	require.NoError(t, uc.DeletePaymentMethod(ctx, merchantID, customerID, deleted.ID))
+	methodRepo.AssertExpectations(t)
+}
+
+func TestPaymentMethodUseCase_HidesOtherMerchantsMethods(t *testing.T) {
+	ctx := context.Background()
+	customerID := uuid.New()
+	method := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: uuid.New(), CustomerID: customerID}
+
+	methodRepo := new(MockPaymentMethodRepository)
+	methodRepo.On("GetByID", ctx, method.ID).Return(method, nil)
+
+	uc := NewPaymentMethodUseCase(methodRepo, new(MockCustomerRepository), new(MockCardRepository))
+	_, err := uc.GetPaymentMethod(ctx, uuid.New(), customerID, method.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "payment method not found")
+}
@@ -2,20 +2,49 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/card"
 	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
 	"github.com/google/uuid"
+	"go.uber.org/zap"
 )
 
 type PaymentUseCase interface {
 	CreatePayment(ctx context.Context, req CreatePaymentRequest) (*entity.Payment, error)
 	GetPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error)
+	// GetMerchantPayment 取得商戶自己的付款，其他商戶的付款回傳 not_found
+	GetMerchantPayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error)
+	// ProcessPayment 在請求內同步向網關請款，供結帳與訂閱續期等需要立即知道結果的流程使用
 	ProcessPayment(ctx context.Context, id uuid.UUID) error
+	// SubmitPayment 將 pending 付款轉為 processing 並排入背景佇列，由 worker 呼叫 ExecutePayment 請款
+	SubmitPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error)
+	// ExecutePayment 向網關請款並完成 processing 付款；付款已不在 processing 時視為已處理。
+	// 回傳錯誤時 worker 會依退避重試
+	ExecutePayment(ctx context.Context, id uuid.UUID) error
+	// FailPayment 在重試用盡後將 processing 付款標記為 failed
+	FailPayment(ctx context.Context, id uuid.UUID, reason string) error
+	// SettlePayment 在銀行轉帳款項付足後將 pending 付款標記為 completed
+	SettlePayment(ctx context.Context, id uuid.UUID) error
+	// CompleteAction 在客戶於錢包確認後將 requires_action 付款標記為 completed
+	CompleteAction(ctx context.Context, id uuid.UUID) error
+	// FailAction 在客戶拒絕或逾時未確認時將 requires_action 付款標記為 failed
+	FailAction(ctx context.Context, id uuid.UUID, reason string) error
+	// ApplyGatewayResult 依網關 webhook 通知的結果將未完成的付款轉為 completed、failed 或 cancelled；
+	// 付款已是該狀態時不做任何事
+	ApplyGatewayResult(ctx context.Context, id uuid.UUID, status entity.PaymentStatus, reason string) error
 	CancelPayment(ctx context.Context, id uuid.UUID) error
+	// ApprovePayment 核准風險審核中的付款，付款轉為 pending 後依付款方式繼續流程
+	ApprovePayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error)
+	// RejectPayment 拒絕風險審核中的付款，付款轉為 failed
+	RejectPayment(ctx context.Context, merchantID, id uuid.UUID, reason string) (*entity.Payment, error)
 	GetMerchantPayments(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error)
 }
 
@@ -27,23 +56,98 @@ type CreatePaymentRequest struct {
 	Method      entity.PaymentMethod `json:"method" validate:"required"`
 	Description string               `json:"description"`
 	Reference   string               `json:"reference"`
+	// PaymentMethodToken 引用卡片保險庫中的卡片，取代直接傳入卡號
+	PaymentMethodToken string `json:"payment_method_token"`
+	// PaymentMethodID 引用客戶已儲存的付款方式，付款方式與 token 由其帶入
+	PaymentMethodID *uuid.UUID `json:"payment_method_id"`
+	// InvoiceID 指定此付款支付的帳單；客戶、幣別與金額未填時由帳單帶入，填寫時必須一致
+	InvoiceID *uuid.UUID `json:"invoice_id"`
+	// PaymentLinkID 由付款連結的結帳流程設定，API 不接受此欄位
+	PaymentLinkID *uuid.UUID `json:"-"`
+	// NextActionType 指定數位錢包付款的確認方式，預設為 redirect_to_url
+	NextActionType entity.NextActionType `json:"next_action_type"`
+	// ReturnURL 為客戶在錢包確認後返回的網址，只用於 redirect_to_url
+	ReturnURL string `json:"return_url"`
+	// ClientIP 為付款人的 IP，由 HTTP 層設定，用於風險評估
+	ClientIP string `json:"-"`
+}
+
+// WalletConfig 設定數位錢包付款的確認步驟
+type WalletConfig struct {
+	// RedirectURL 為錢包確認頁網址，付款 ID、金額與幣別以查詢參數附加
+	RedirectURL string
+	// ActionTimeout 為等待客戶確認的時間，逾時付款轉為 failed
+	ActionTimeout time.Duration
+	// CallbackSecret 為錢包回呼簽章的 HMAC 金鑰，為空時拒絕所有回呼
+	CallbackSecret string
+}
+
+// PaymentObserver 在付款建立或狀態變更後收到通知，用於指標等旁路處理，
+// 不應影響主流程的結果
+type PaymentObserver interface {
+	PaymentCreated(ctx context.Context, payment *entity.Payment)
+	PaymentStatusChanged(ctx context.Context, payment *entity.Payment, previous entity.PaymentStatus)
 }
 
 type paymentUseCase struct {
 	paymentRepo  repository.PaymentRepository
 	merchantRepo repository.MerchantRepository
 	customerRepo repository.CustomerRepository
+	cardRepo     repository.CardRepository
+	methodRepo   repository.PaymentMethodRepository
+	invoiceRepo  repository.InvoiceRepository
+	jobRepo      repository.JobRepository
+	transferRepo repository.BankTransferRepository
+	transfers    BankTransferConfig
+	actionRepo   repository.WalletActionRepository
+	wallets      WalletConfig
+	// risk 與 limits 為 nil 時不做風險評估與限額檢查
+	risk   RiskEngine
+	limits LimitUseCase
+	// linkRepo 為 nil 時付款失敗或取消不歸還付款連結的次數
+	linkRepo  repository.PaymentLinkRepository
+	observers []PaymentObserver
 }
 
 func NewPaymentUseCase(
 	paymentRepo repository.PaymentRepository,
 	merchantRepo repository.MerchantRepository,
 	customerRepo repository.CustomerRepository,
+	cardRepo repository.CardRepository,
+	methodRepo repository.PaymentMethodRepository,
+	invoiceRepo repository.InvoiceRepository,
+	jobRepo repository.JobRepository,
+	transferRepo repository.BankTransferRepository,
+	transfers BankTransferConfig,
+	actionRepo repository.WalletActionRepository,
+	wallets WalletConfig,
+	risk RiskEngine,
+	limits LimitUseCase,
+	linkRepo repository.PaymentLinkRepository,
+	observers ...PaymentObserver,
 ) PaymentUseCase {
+	if transfers.ExpiresIn <= 0 {
+		transfers.ExpiresIn = 72 * time.Hour
+	}
+	if wallets.ActionTimeout <= 0 {
+		wallets.ActionTimeout = 15 * time.Minute
+	}
 	return &paymentUseCase{
 		paymentRepo:  paymentRepo,
 		merchantRepo: merchantRepo,
 		customerRepo: customerRepo,
+		cardRepo:     cardRepo,
+		methodRepo:   methodRepo,
+		invoiceRepo:  invoiceRepo,
+		jobRepo:      jobRepo,
+		transferRepo: transferRepo,
+		transfers:    transfers,
+		actionRepo:   actionRepo,
+		wallets:      wallets,
+		risk:         risk,
+		limits:       limits,
+		linkRepo:     linkRepo,
+		observers:    observers,
 	}
 }
 
@@ -57,39 +161,182 @@ func (uc *paymentUseCase) CreatePayment(ctx context.Context, req CreatePaymentRe
 		return nil, errors.New("merchant is not active")
 	}
 
+	if req.InvoiceID != nil {
+		if req, err = uc.applyInvoice(ctx, req); err != nil {
+			return nil, err
+		}
+	}
+
 	// 驗證客戶存在
-	_, err = uc.customerRepo.GetByID(ctx, req.CustomerID)
+	customer, err := uc.customerRepo.GetByID(ctx, req.CustomerID)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get customer")
 	}
 
+	method := req.Method
+	token := req.PaymentMethodToken
+	if req.PaymentMethodID != nil {
+		saved, err := uc.resolvePaymentMethod(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		method = saved.Type
+		if method == entity.PaymentMethodCreditCard {
+			token = saved.Token
+		}
+	}
+	if token != "" {
+		if method == "" {
+			method = entity.PaymentMethodCreditCard
+		}
+		if err := uc.validateCardToken(ctx, req.MerchantID, method, token); err != nil {
+			return nil, err
+		}
+	}
+	if method == entity.PaymentMethodDigitalWallet {
+		if err := validateWalletAction(req); err != nil {
+			return nil, err
+		}
+	}
+
 	// 創建支付記錄
 	payment := &entity.Payment{
-		ID:          uuid.New(),
-		MerchantID:  req.MerchantID,
-		CustomerID:  req.CustomerID,
-		Amount:      req.Amount,
-		Currency:    req.Currency,
-		Method:      req.Method,
-		Status:      entity.PaymentStatusPending,
-		Description: req.Description,
-		Reference:   req.Reference,
-		CreatedAt:   time.Now(),
-		UpdatedAt:   time.Now(),
+		ID:                 uuid.New(),
+		MerchantID:         req.MerchantID,
+		CustomerID:         req.CustomerID,
+		Amount:             req.Amount,
+		Currency:           req.Currency,
+		Method:             method,
+		Status:             entity.PaymentStatusPending,
+		Description:        req.Description,
+		Reference:          req.Reference,
+		PaymentMethodToken: token,
+		PaymentMethodID:    req.PaymentMethodID,
+		InvoiceID:          req.InvoiceID,
+		PaymentLinkID:      req.PaymentLinkID,
+		ClientIP:           req.ClientIP,
+		CreatedAt:          time.Now(),
+		UpdatedAt:          time.Now(),
+	}
+
+	if uc.risk != nil {
+		if err := uc.assessRisk(ctx, payment, customer); err != nil {
+			return nil, err
+		}
+	}
+
+	// 被風險規則拒絕的付款不計入限額用量
+	if uc.limits != nil && payment.Status != entity.PaymentStatusFailed {
+		if err := uc.limits.Reserve(ctx, payment); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := uc.paymentRepo.Create(ctx, payment); err != nil {
+		if payment.Status != entity.PaymentStatusFailed {
+			uc.releaseLimit(ctx, payment)
+		}
 		return nil, errors.Wrap(err, "failed to create payment")
 	}
 
+	// 審核中的付款在核准後才產生匯款資訊或錢包確認步驟
+	if method == entity.PaymentMethodBankTransfer && payment.Status == entity.PaymentStatusPending {
+		if err := uc.requireBankTransfer(ctx, payment); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.FromContext(ctx).Info("payment created",
+		zap.String("payment_id", payment.ID.String()),
+		zap.Int64("amount", payment.Amount),
+		zap.String("currency", payment.Currency),
+		zap.String("method", string(payment.Method)),
+		zap.String("status", string(payment.Status)),
+	)
+	for _, o := range uc.observers {
+		o.PaymentCreated(ctx, payment)
+	}
+
+	if payment.RiskDecision == entity.RiskDecisionBlock {
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("payment %s was declined", payment.ID)), "payment_declined")
+	}
+
+	if method == entity.PaymentMethodDigitalWallet && payment.Status == entity.PaymentStatusPending {
+		if err := uc.requireWalletAction(ctx, payment, req); err != nil {
+			return nil, err
+		}
+	}
+
 	return payment, nil
 }
 
+// assessRisk 記錄風險評估結果；需要審核的付款轉為 review，拒絕的付款直接寫入為 failed
+// 以保留評估記錄
+func (uc *paymentUseCase) assessRisk(ctx context.Context, payment *entity.Payment, customer *entity.Customer) error {
+	assessment, err := uc.risk.Assess(ctx, RiskInput{
+		CustomerID: payment.CustomerID,
+		Email:      customer.Email,
+		ClientIP:   payment.ClientIP,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to assess payment risk")
+	}
+
+	payment.RiskScore = assessment.Score
+	payment.RiskDecision = assessment.Decision
+	payment.RiskReasons = assessment.Reasons
+	switch assessment.Decision {
+	case entity.RiskDecisionReview:
+		payment.Status = entity.PaymentStatusReview
+	case entity.RiskDecisionBlock:
+		payment.Status = entity.PaymentStatusFailed
+	}
+	if assessment.Decision != entity.RiskDecisionAllow {
+		logger.FromContext(ctx).Warn("payment flagged by risk screening",
+			zap.String("payment_id", payment.ID.String()),
+			zap.Int("risk_score", assessment.Score),
+			zap.String("risk_decision", string(assessment.Decision)),
+			zap.Strings("risk_reasons", assessment.Reasons),
+		)
+	}
+	return nil
+}
+
 func (uc *paymentUseCase) GetPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
 	payment, err := uc.paymentRepo.GetByID(ctx, id)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get payment")
 	}
+	if payment.Method == entity.PaymentMethodBankTransfer {
+		transfer, err := uc.transferRepo.GetByPaymentID(ctx, id)
+		switch {
+		case err == nil:
+			payment.BankTransfer = transfer
+		case payment.RiskDecision == "" || payment.RiskDecision == entity.RiskDecisionAllow:
+			return nil, errors.Wrap(err, "failed to get bank transfer")
+		}
+		// 審核中、審核後被拒絕或被阻擋的付款沒有匯款資訊
+	}
+	if payment.Status == entity.PaymentStatusRequiresAction {
+		action, err := uc.actionRepo.GetByPaymentID(ctx, id)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get wallet action")
+		}
+		payment.NextAction = action.NextAction()
+	}
+	return payment, nil
+}
+
+func (uc *paymentUseCase) GetMerchantPayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error) {
+	payment, err := uc.GetPayment(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(err, "not_found")
+	}
+	if payment.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("payment not found"), "not_found")
+	}
 	return payment, nil
 }
 
@@ -100,40 +347,608 @@ func (uc *paymentUseCase) ProcessPayment(ctx context.Context, id uuid.UUID) erro
 	}
 
 	if payment.Status != entity.PaymentStatusPending {
-		return errors.New(fmt.Sprintf("payment status is %s, cannot process", payment.Status))
+		return errors.WithCode(errors.New(fmt.Sprintf("payment status is %s, cannot process", payment.Status)), "invalid_payment_status")
 	}
+	if payment.Method == entity.PaymentMethodBankTransfer {
+		return errBankTransferNotProcessable()
+	}
+
+	// 先以條件更新轉為 processing，並行的重複請求或取消只有一個會生效，網關只會被呼叫一次
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusPending, entity.PaymentStatusProcessing); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusProcessing)
 
 	// 在實際應用中，這裡會調用第三方支付網關
 	// 為了示例，我們假設支付總是成功
 
-	if err := uc.paymentRepo.UpdateStatus(ctx, id, entity.PaymentStatusCompleted); err != nil {
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted); err != nil {
 		return errors.Wrap(err, "failed to update payment status")
 	}
 
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusCompleted)
+	if payment.InvoiceID != nil {
+		uc.settleInvoice(ctx, payment)
+	}
 	return nil
 }
 
-func (uc *paymentUseCase) CancelPayment(ctx context.Context, id uuid.UUID) error {
+func (uc *paymentUseCase) SubmitPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
 	payment, err := uc.paymentRepo.GetByID(ctx, id)
 	if err != nil {
-		return errors.Wrap(err, "failed to get payment")
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment"), "not_found")
 	}
 
 	if payment.Status != entity.PaymentStatusPending {
-		return errors.New(fmt.Sprintf("payment status is %s, cannot cancel", payment.Status))
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("payment status is %s, cannot process", payment.Status)), "invalid_payment_status")
+	}
+	if payment.Method == entity.PaymentMethodBankTransfer {
+		return nil, errBankTransferNotProcessable()
 	}
 
-	if err := uc.paymentRepo.UpdateStatus(ctx, id, entity.PaymentStatusCancelled); err != nil {
+	// 先以條件更新轉為 processing，並行的重複請求只有一個會排入佇列
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusPending, entity.PaymentStatusProcessing); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	payload, err := json.Marshal(entity.ProcessPaymentJob{PaymentID: id})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode job payload")
+	}
+	now := time.Now()
+	job := &entity.Job{
+		ID:        uuid.New(),
+		Type:      entity.JobTypeProcessPayment,
+		Payload:   string(payload),
+		Status:    entity.JobStatusQueued,
+		RunAt:     now,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.jobRepo.Enqueue(ctx, job); err != nil {
+		// 沒有工作會處理這筆付款，改回 pending 讓呼叫端可以重送
+		if rollbackErr := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusProcessing, entity.PaymentStatusPending); rollbackErr != nil {
+			logger.FromContext(ctx).Error("failed to revert payment to pending",
+				zap.String("payment_id", id.String()),
+				zap.Error(rollbackErr),
+			)
+		}
+		return nil, errors.Wrap(err, "failed to enqueue payment")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusProcessing)
+	logger.FromContext(ctx).Info("payment submitted",
+		zap.String("payment_id", id.String()),
+		zap.String("job_id", job.ID.String()),
+	)
+	return payment, nil
+}
+
+func (uc *paymentUseCase) ExecutePayment(ctx context.Context, id uuid.UUID) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get payment")
+	}
+
+	if payment.Status != entity.PaymentStatusProcessing {
+		// 先前的嘗試已完成，或鎖定逾時後由其他 worker 處理
+		logger.FromContext(ctx).Info("payment is no longer processing, skipping",
+			zap.String("payment_id", id.String()),
+			zap.String("status", string(payment.Status)),
+		)
+		return nil
+	}
+
+	// 在實際應用中，這裡會以付款 ID 作為冪等鍵調用第三方支付網關，
+	// 網關逾時或暫時性錯誤回傳給 worker 重試
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted); err != nil {
+		return errors.Wrap(err, "failed to update payment status")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusCompleted)
+	if payment.InvoiceID != nil {
+		uc.settleInvoice(ctx, payment)
+	}
+	return nil
+}
+
+func (uc *paymentUseCase) FailPayment(ctx context.Context, id uuid.UUID, reason string) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get payment")
+	}
+	if payment.Status != entity.PaymentStatusProcessing {
+		return nil
+	}
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusProcessing, entity.PaymentStatusFailed); err != nil {
 		return errors.Wrap(err, "failed to update payment status")
 	}
 
+	logger.FromContext(ctx).Warn("payment failed",
+		zap.String("payment_id", id.String()),
+		zap.String("reason", reason),
+	)
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusFailed)
+	return nil
+}
+
+func (uc *paymentUseCase) SettlePayment(ctx context.Context, id uuid.UUID) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get payment")
+	}
+
+	// 以條件更新避免與取消或逾期同時發生時覆寫狀態
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusPending, entity.PaymentStatusCompleted); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusCompleted)
+	if payment.InvoiceID != nil {
+		uc.settleInvoice(ctx, payment)
+	}
+	return nil
+}
+
+func (uc *paymentUseCase) CompleteAction(ctx context.Context, id uuid.UUID) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get payment")
+	}
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusRequiresAction, entity.PaymentStatusCompleted); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusCompleted)
+	if payment.InvoiceID != nil {
+		uc.settleInvoice(ctx, payment)
+	}
+	return nil
+}
+
+func (uc *paymentUseCase) FailAction(ctx context.Context, id uuid.UUID, reason string) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, "failed to get payment")
+	}
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusRequiresAction, entity.PaymentStatusFailed); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	logger.FromContext(ctx).Warn("payment failed",
+		zap.String("payment_id", id.String()),
+		zap.String("reason", reason),
+	)
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusFailed)
+	return nil
+}
+
+func (uc *paymentUseCase) ApplyGatewayResult(ctx context.Context, id uuid.UUID, status entity.PaymentStatus, reason string) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to get payment"), "not_found")
+	}
+	if payment.Status == status {
+		return nil
+	}
+
+	switch status {
+	case entity.PaymentStatusCompleted, entity.PaymentStatusFailed, entity.PaymentStatusCancelled:
+	default:
+		return errors.New(fmt.Sprintf("unsupported gateway result %s", status))
+	}
+	switch payment.Status {
+	case entity.PaymentStatusPending, entity.PaymentStatusProcessing, entity.PaymentStatusRequiresAction:
+	default:
+		return errors.WithCode(errors.New(fmt.Sprintf("payment status is %s, cannot apply %s", payment.Status, status)), "invalid_payment_status")
+	}
+
+	// 以讀到的狀態做條件更新，與 worker 或錢包回呼同時發生時只有一個會生效
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, payment.Status, status); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	if status == entity.PaymentStatusFailed {
+		logger.FromContext(ctx).Warn("payment failed",
+			zap.String("payment_id", id.String()),
+			zap.String("reason", reason),
+		)
+	}
+	uc.notifyStatusChanged(ctx, payment, status)
+	if status == entity.PaymentStatusCompleted && payment.InvoiceID != nil {
+		uc.settleInvoice(ctx, payment)
+	}
 	return nil
 }
 
+func (uc *paymentUseCase) CancelPayment(ctx context.Context, id uuid.UUID) error {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to get payment"), "not_found")
+	}
+
+	if payment.Status != entity.PaymentStatusPending {
+		return errors.WithCode(errors.New(fmt.Sprintf("payment status is %s, cannot cancel", payment.Status)), "invalid_payment_status")
+	}
+
+	// 以條件更新避免覆寫同時完成的付款
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusPending, entity.PaymentStatusCancelled); err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusCancelled)
+	if payment.Method == entity.PaymentMethodBankTransfer {
+		uc.cancelBankTransfer(ctx, id)
+	}
+	return nil
+}
+
+// cancelBankTransfer 關閉已取消付款的匯款資訊，之後收到的款項會被標記為需退款。
+// 匯款已逾期或已付足時不做任何事
+func (uc *paymentUseCase) cancelBankTransfer(ctx context.Context, id uuid.UUID) {
+	if err := uc.transferRepo.Cancel(ctx, id, time.Now()); err != nil {
+		logger.FromContext(ctx).Info("bank transfer no longer awaiting funds",
+			zap.String("payment_id", id.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+func (uc *paymentUseCase) ApprovePayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error) {
+	payment, err := uc.reviewedPayment(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusReview, entity.PaymentStatusPending); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusPending)
+
+	// 核准後才產生建立時略過的步驟；錢包確認使用預設的 redirect_to_url，不帶 return_url
+	switch payment.Method {
+	case entity.PaymentMethodBankTransfer:
+		if err := uc.requireBankTransfer(ctx, payment); err != nil {
+			return nil, err
+		}
+	case entity.PaymentMethodDigitalWallet:
+		if err := uc.requireWalletAction(ctx, payment, CreatePaymentRequest{}); err != nil {
+			return nil, err
+		}
+	}
+	return payment, nil
+}
+
+func (uc *paymentUseCase) RejectPayment(ctx context.Context, merchantID, id uuid.UUID, reason string) (*entity.Payment, error) {
+	payment, err := uc.reviewedPayment(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.paymentRepo.TransitionStatus(ctx, id, entity.PaymentStatusReview, entity.PaymentStatusFailed); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to update payment status"), "invalid_payment_status")
+	}
+
+	logger.FromContext(ctx).Warn("payment rejected after review",
+		zap.String("payment_id", id.String()),
+		zap.String("reason", reason),
+	)
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusFailed)
+	return payment, nil
+}
+
+// reviewedPayment 取得商戶自己的 review 付款，其他商戶的付款視為不存在
+func (uc *paymentUseCase) reviewedPayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error) {
+	payment, err := uc.paymentRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment"), "not_found")
+	}
+	if payment.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("payment not found"), "not_found")
+	}
+	if payment.Status != entity.PaymentStatusReview {
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("payment status is %s, not in review", payment.Status)), "invalid_payment_status")
+	}
+	return payment, nil
+}
+
 func (uc *paymentUseCase) GetMerchantPayments(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
 	payments, err := uc.paymentRepo.GetByMerchantID(ctx, merchantID, limit, offset)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get merchant payments")
 	}
 	return payments, nil
-}
\ No newline at end of file
+}
+
+// resolvePaymentMethod 確認儲存的付款方式屬於同一商戶與客戶，且與請求的付款方式、token 不衝突
+func (uc *paymentUseCase) resolvePaymentMethod(ctx context.Context, req CreatePaymentRequest) (*entity.PaymentMethodRecord, error) {
+	saved, err := uc.methodRepo.GetByID(ctx, *req.PaymentMethodID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get payment method"), "invalid_payment_method")
+	}
+	if saved.MerchantID != req.MerchantID || saved.CustomerID != req.CustomerID {
+		return nil, errors.WithCode(errors.New("payment method not found"), "invalid_payment_method")
+	}
+	if req.Method != "" && req.Method != saved.Type {
+		return nil, errors.WithCode(errors.New("method does not match saved payment method"), "invalid_payment_method")
+	}
+	if req.PaymentMethodToken != "" && req.PaymentMethodToken != saved.Token {
+		return nil, errors.WithCode(errors.New("payment_method_token does not match saved payment method"), "invalid_payment_method")
+	}
+	return saved, nil
+}
+
+// applyInvoice 確認帳單可由此付款支付，並以帳單內容補齊客戶、幣別、金額與說明
+func (uc *paymentUseCase) applyInvoice(ctx context.Context, req CreatePaymentRequest) (CreatePaymentRequest, error) {
+	invoice, err := uc.invoiceRepo.GetByID(ctx, *req.InvoiceID)
+	if err != nil {
+		return req, errors.WithCode(errors.Wrap(err, "failed to get invoice"), "invalid_invoice")
+	}
+	if invoice.MerchantID != req.MerchantID {
+		return req, errors.WithCode(errors.New("invoice not found"), "invalid_invoice")
+	}
+	if invoice.Status != entity.InvoiceStatusOpen {
+		return req, errors.WithCode(errors.New(fmt.Sprintf("invoice status is %s, cannot be paid", invoice.Status)), "invalid_invoice")
+	}
+
+	if req.CustomerID == uuid.Nil {
+		req.CustomerID = invoice.CustomerID
+	}
+	if req.Currency == "" {
+		req.Currency = invoice.Currency
+	}
+	if req.Amount == 0 {
+		req.Amount = invoice.Total
+	}
+	if req.Description == "" {
+		req.Description = "Invoice " + invoice.Number
+	}
+
+	switch {
+	case req.CustomerID != invoice.CustomerID:
+		return req, errors.WithCode(errors.New("customer does not match invoice"), "invalid_invoice")
+	case req.Currency != invoice.Currency:
+		return req, errors.WithCode(errors.New("currency does not match invoice"), "invalid_invoice")
+	case req.Amount != invoice.Total:
+		// 不支援部分付款
+		return req, errors.WithCode(errors.New("amount does not match invoice total"), "invalid_invoice")
+	}
+	return req, nil
+}
+
+// settleInvoice 將已完成付款的帳單標記為 paid。付款已完成，失敗只記錄錯誤不回傳
+func (uc *paymentUseCase) settleInvoice(ctx context.Context, payment *entity.Payment) {
+	log := logger.FromContext(ctx).With(
+		zap.String("payment_id", payment.ID.String()),
+		zap.String("invoice_id", payment.InvoiceID.String()),
+	)
+
+	invoice, err := uc.invoiceRepo.GetByID(ctx, *payment.InvoiceID)
+	if err != nil {
+		log.Error("failed to get invoice for completed payment", zap.Error(err))
+		return
+	}
+	if invoice.Status != entity.InvoiceStatusOpen {
+		// 例如同一張帳單有兩筆付款先後完成，需人工退款
+		log.Warn("completed payment for invoice that is not open", zap.String("status", string(invoice.Status)))
+		return
+	}
+
+	now := time.Now()
+	invoice.Status = entity.InvoiceStatusPaid
+	invoice.PaymentID = &payment.ID
+	invoice.PaidAt = &now
+	invoice.UpdatedAt = now
+	if err := uc.invoiceRepo.Update(ctx, invoice); err != nil {
+		log.Error("failed to mark invoice paid", zap.Error(err))
+		return
+	}
+	log.Info("invoice paid")
+}
+
+// issueBankTransfer 為銀行轉帳付款產生匯款參考號與虛擬帳號，碰到重複時重新產生
+func (uc *paymentUseCase) issueBankTransfer(ctx context.Context, payment *entity.Payment) (*entity.BankTransfer, error) {
+	var lastErr error
+	for attempt := 0; attempt < 3; attempt++ {
+		reference, err := newTransferReference()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate transfer reference")
+		}
+		account, err := newVirtualAccount(uc.transfers.AccountPrefix)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to generate virtual account")
+		}
+
+		now := time.Now()
+		transfer := &entity.BankTransfer{
+			PaymentID:      payment.ID,
+			MerchantID:     payment.MerchantID,
+			Reference:      reference,
+			VirtualAccount: account,
+			BankName:       uc.transfers.BankName,
+			AmountExpected: payment.Amount,
+			Currency:       payment.Currency,
+			Status:         entity.BankTransferStatusAwaitingFunds,
+			ExpiresAt:      now.Add(uc.transfers.ExpiresIn),
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if lastErr = uc.transferRepo.Create(ctx, transfer); lastErr == nil {
+			return transfer, nil
+		}
+	}
+	return nil, errors.Wrap(lastErr, "failed to issue bank transfer")
+}
+
+// requireBankTransfer 產生匯款資訊，失敗時取消付款，沒有匯款資訊客戶無法付款
+func (uc *paymentUseCase) requireBankTransfer(ctx context.Context, payment *entity.Payment) error {
+	transfer, err := uc.issueBankTransfer(ctx, payment)
+	if err != nil {
+		if cancelErr := uc.paymentRepo.TransitionStatus(ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusCancelled); cancelErr != nil {
+			logger.FromContext(ctx).Error("failed to cancel payment without bank transfer",
+				zap.String("payment_id", payment.ID.String()),
+				zap.Error(cancelErr),
+			)
+		} else {
+			uc.releaseLimit(ctx, payment)
+		}
+		return err
+	}
+	payment.BankTransfer = transfer
+	return nil
+}
+
+// requireWalletAction 建立錢包確認步驟並將付款轉為 requires_action，
+// 失敗時取消付款，避免留下客戶無法完成的付款
+func (uc *paymentUseCase) requireWalletAction(ctx context.Context, payment *entity.Payment, req CreatePaymentRequest) error {
+	action, err := uc.issueWalletAction(ctx, payment, req)
+	if err == nil {
+		err = uc.paymentRepo.TransitionStatus(ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusRequiresAction)
+	}
+	if err != nil {
+		if cancelErr := uc.paymentRepo.TransitionStatus(ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusCancelled); cancelErr != nil {
+			logger.FromContext(ctx).Error("failed to cancel payment without wallet action",
+				zap.String("payment_id", payment.ID.String()),
+				zap.Error(cancelErr),
+			)
+		} else {
+			uc.releaseLimit(ctx, payment)
+		}
+		return errors.Wrap(err, "failed to require wallet action")
+	}
+
+	uc.notifyStatusChanged(ctx, payment, entity.PaymentStatusRequiresAction)
+	payment.NextAction = action.NextAction()
+	return nil
+}
+
+// issueWalletAction 產生錢包確認頁網址；QR code 的內容為不含 return_url 的同一網址
+func (uc *paymentUseCase) issueWalletAction(ctx context.Context, payment *entity.Payment, req CreatePaymentRequest) (*entity.WalletAction, error) {
+	u, err := url.Parse(uc.wallets.RedirectURL)
+	if err != nil || u.Host == "" {
+		return nil, errors.New("wallet redirect url is not configured")
+	}
+	q := u.Query()
+	q.Set("payment_id", payment.ID.String())
+	q.Set("amount", strconv.FormatInt(payment.Amount, 10))
+	q.Set("currency", payment.Currency)
+
+	now := time.Now()
+	action := &entity.WalletAction{
+		PaymentID:  payment.ID,
+		MerchantID: payment.MerchantID,
+		Type:       req.NextActionType,
+		Status:     entity.WalletActionStatusPending,
+		ExpiresAt:  now.Add(uc.wallets.ActionTimeout),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if action.Type == entity.NextActionDisplayQRCode {
+		u.RawQuery = q.Encode()
+		action.QRPayload = u.String()
+	} else {
+		action.Type = entity.NextActionRedirectToURL
+		if req.ReturnURL != "" {
+			q.Set("return_url", req.ReturnURL)
+		}
+		u.RawQuery = q.Encode()
+		action.RedirectURL = u.String()
+	}
+
+	if err := uc.actionRepo.Create(ctx, action); err != nil {
+		return nil, errors.Wrap(err, "failed to create wallet action")
+	}
+	return action, nil
+}
+
+func validateWalletAction(req CreatePaymentRequest) error {
+	switch req.NextActionType {
+	case "", entity.NextActionRedirectToURL, entity.NextActionDisplayQRCode:
+	default:
+		return errors.WithCode(errors.New(fmt.Sprintf("unsupported next_action_type %q", req.NextActionType)), "invalid_payment_method")
+	}
+	if req.ReturnURL != "" {
+		if err := validateRedirectURL(req.ReturnURL); err != nil {
+			return errors.WithCode(errors.New("return_url "+err.Error()), "invalid_payment_method")
+		}
+	}
+	return nil
+}
+
+// validateCardToken 確認 token 屬於同一商戶且卡片尚未過期
+func (uc *paymentUseCase) validateCardToken(ctx context.Context, merchantID uuid.UUID, method entity.PaymentMethod, token string) error {
+	if method != entity.PaymentMethodCreditCard {
+		return errors.WithCode(errors.New("payment_method_token requires credit_card method"), "invalid_payment_method")
+	}
+	c, err := uc.cardRepo.GetByToken(ctx, token)
+	if err != nil {
+		return errors.WithCode(errors.Wrap(err, "failed to get card"), "invalid_payment_method")
+	}
+	if c.MerchantID != merchantID {
+		return errors.WithCode(errors.New("card not found"), "invalid_payment_method")
+	}
+	if card.Expired(c.ExpMonth, c.ExpYear, time.Now()) {
+		return errors.WithCode(errors.New("card is expired"), "invalid_payment_method")
+	}
+	return nil
+}
+
+func errBankTransferNotProcessable() error {
+	return errors.WithCode(errors.New("bank transfer payments complete when funds are received"), "invalid_payment_status")
+}
+
+func (uc *paymentUseCase) notifyStatusChanged(ctx context.Context, payment *entity.Payment, status entity.PaymentStatus) {
+	previous := payment.Status
+	now := time.Now()
+	payment.Status = status
+	payment.UpdatedAt = now
+	if status == entity.PaymentStatusCompleted {
+		payment.CompletedAt = &now
+	}
+
+	logger.FromContext(ctx).Info("payment status changed",
+		zap.String("payment_id", payment.ID.String()),
+		zap.String("from", string(previous)),
+		zap.String("to", string(status)),
+	)
+	// 所有轉為 failed 或 cancelled 的條件更新都經過這裡，包含逾期排程與審核拒絕，
+	// 在此扣回建立時佔用的限額用量與付款連結次數
+	if status == entity.PaymentStatusFailed || status == entity.PaymentStatusCancelled {
+		uc.releaseLimit(ctx, payment)
+		uc.releaseLink(ctx, payment)
+	}
+	for _, o := range uc.observers {
+		o.PaymentStatusChanged(ctx, payment, previous)
+	}
+}
+
+// releaseLimit 扣回付款佔用的當日限額用量，失敗只記錄
+func (uc *paymentUseCase) releaseLimit(ctx context.Context, payment *entity.Payment) {
+	if uc.limits == nil {
+		return
+	}
+	if err := uc.limits.Release(ctx, payment); err != nil {
+		logger.FromContext(ctx).Error("failed to release limit usage",
+			zap.String("payment_id", payment.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// releaseLink 歸還結帳時為付款佔用的付款連結次數，失敗只記錄
+func (uc *paymentUseCase) releaseLink(ctx context.Context, payment *entity.Payment) {
+	if uc.linkRepo == nil || payment.PaymentLinkID == nil {
+		return
+	}
+	if err := uc.linkRepo.ReleasePayment(ctx, *payment.PaymentLinkID); err != nil {
+		logger.FromContext(ctx).Error("failed to release payment link",
+			zap.String("payment_id", payment.ID.String()),
+			zap.String("payment_link_id", payment.PaymentLinkID.String()),
+			zap.Error(err),
+		)
+	}
+}
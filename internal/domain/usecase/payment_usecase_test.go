@@ -3,11 +3,14 @@ package usecase
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mock repositories
@@ -41,6 +44,11 @@ func (m *MockPaymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID,
 	return args.Error(0)
 }
 
+func (m *MockPaymentRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) error {
+	args := m.Called(ctx, id, from, to)
+	return args.Error(0)
+}
+
 func (m *MockPaymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
 	args := m.Called(ctx, merchantID, limit, offset)
 	if args.Get(0) == nil {
@@ -49,6 +57,14 @@ func (m *MockPaymentRepository) GetByMerchantID(ctx context.Context, merchantID
 	return args.Get(0).([]*entity.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.Payment, error) {
+	args := m.Called(ctx, from, to, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Payment), args.Error(1)
+}
+
 func (m *MockPaymentRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
 	args := m.Called(ctx, customerID, limit, offset)
 	if args.Get(0) == nil {
@@ -57,6 +73,14 @@ func (m *MockPaymentRepository) GetByCustomerID(ctx context.Context, customerID
 	return args.Get(0).([]*entity.Payment), args.Error(1)
 }
 
+func (m *MockPaymentRepository) GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	args := m.Called(ctx, linkID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Payment), args.Error(1)
+}
+
 type MockMerchantRepository struct {
 	mock.Mock
 }
@@ -82,6 +106,14 @@ func (m *MockMerchantRepository) GetByAPIKey(ctx context.Context, apiKey string)
 	return args.Get(0).(*entity.Merchant), args.Error(1)
 }
 
+func (m *MockMerchantRepository) GetByPublishableKey(ctx context.Context, publishableKey string) (*entity.Merchant, error) {
+	args := m.Called(ctx, publishableKey)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Merchant), args.Error(1)
+}
+
 func (m *MockMerchantRepository) Update(ctx context.Context, merchant *entity.Merchant) error {
 	args := m.Called(ctx, merchant)
 	return args.Error(0)
@@ -127,6 +159,63 @@ func (m *MockCustomerRepository) Delete(ctx context.Context, id uuid.UUID) error
 	return args.Error(0)
 }
 
+type MockCardRepository struct {
+	mock.Mock
+}
+
+func (m *MockCardRepository) Create(ctx context.Context, card *entity.Card) error {
+	args := m.Called(ctx, card)
+	return args.Error(0)
+}
+
+func (m *MockCardRepository) GetByToken(ctx context.Context, token string) (*entity.Card, error) {
+	args := m.Called(ctx, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Card), args.Error(1)
+}
+
+type MockPaymentMethodRepository struct {
+	mock.Mock
+}
+
+func (m *MockPaymentMethodRepository) Create(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	args := m.Called(ctx, method)
+	return args.Error(0)
+}
+
+func (m *MockPaymentMethodRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentMethodRecord, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.PaymentMethodRecord), args.Error(1)
+}
+
+func (m *MockPaymentMethodRepository) ListByCustomerID(ctx context.Context, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error) {
+	args := m.Called(ctx, customerID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.PaymentMethodRecord), args.Error(1)
+}
+
+func (m *MockPaymentMethodRepository) Update(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	args := m.Called(ctx, method)
+	return args.Error(0)
+}
+
+func (m *MockPaymentMethodRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentMethodRepository) SetDefault(ctx context.Context, customerID, id uuid.UUID) error {
+	args := m.Called(ctx, customerID, id)
+	return args.Error(0)
+}
+
 func TestPaymentUseCase_CreatePayment(t *testing.T) {
 	ctx := context.Background()
 
@@ -198,7 +287,7 @@ func TestPaymentUseCase_CreatePayment(t *testing.T) {
 
 			tt.setupMocks(paymentRepo, merchantRepo, customerRepo)
 
-			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo)
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
 
 			payment, err := useCase.CreatePayment(ctx, tt.request)
 
@@ -241,10 +330,24 @@ func TestPaymentUseCase_ProcessPayment(t *testing.T) {
 					Status: entity.PaymentStatusPending,
 				}
 				paymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
-				paymentRepo.On("UpdateStatus", ctx, paymentID, entity.PaymentStatusCompleted).Return(nil)
+				paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusPending, entity.PaymentStatusProcessing).Return(nil)
+				paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted).Return(nil)
 			},
 			expectedError: "",
 		},
+		{
+			name:      "concurrent process or cancel wins",
+			paymentID: paymentID,
+			setupMocks: func(paymentRepo *MockPaymentRepository) {
+				payment := &entity.Payment{
+					ID:     paymentID,
+					Status: entity.PaymentStatusPending,
+				}
+				paymentRepo.On("GetByID", ctx, paymentID).Return(payment, nil)
+				paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusPending, entity.PaymentStatusProcessing).Return(errors.New("payment status changed"))
+			},
+			expectedError: "failed to update payment status",
+		},
 		{
 			name:      "payment already completed",
 			paymentID: paymentID,
@@ -267,7 +370,7 @@ func TestPaymentUseCase_ProcessPayment(t *testing.T) {
 
 			tt.setupMocks(paymentRepo)
 
-			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo)
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
 
 			err := useCase.ProcessPayment(ctx, tt.paymentID)
 
@@ -281,4 +384,584 @@ func TestPaymentUseCase_ProcessPayment(t *testing.T) {
 			paymentRepo.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestPaymentUseCase_SubmitPayment(t *testing.T) {
+	ctx := context.Background()
+	paymentID := uuid.New()
+	newUseCase := func(paymentRepo *MockPaymentRepository, jobRepo *MockJobRepository) PaymentUseCase {
+		return NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), jobRepo, new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+	}
+
+	t.Run("queues pending payment", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		jobRepo := new(MockJobRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusPending}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusPending, entity.PaymentStatusProcessing).Return(nil)
+		jobRepo.On("Enqueue", ctx, mock.MatchedBy(func(job *entity.Job) bool {
+			return job.Type == entity.JobTypeProcessPayment && job.Status == entity.JobStatusQueued &&
+				job.Payload == `{"payment_id":"`+paymentID.String()+`"}`
+		})).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, jobRepo).SubmitPayment(ctx, paymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusProcessing, payment.Status)
+		paymentRepo.AssertExpectations(t)
+		jobRepo.AssertExpectations(t)
+	})
+
+	t.Run("rejects payment that is not pending", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		jobRepo := new(MockJobRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusProcessing}, nil)
+
+		_, err := newUseCase(paymentRepo, jobRepo).SubmitPayment(ctx, paymentID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "payment status is processing, cannot process")
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		jobRepo.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reverts to pending when enqueue fails", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		jobRepo := new(MockJobRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusPending}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusPending, entity.PaymentStatusProcessing).Return(nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusProcessing, entity.PaymentStatusPending).Return(nil)
+		jobRepo.On("Enqueue", ctx, mock.AnythingOfType("*entity.Job")).Return(errors.New("db down"))
+
+		_, err := newUseCase(paymentRepo, jobRepo).SubmitPayment(ctx, paymentID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to enqueue payment")
+		paymentRepo.AssertExpectations(t)
+	})
+}
+
+func TestPaymentUseCase_ExecutePayment(t *testing.T) {
+	ctx := context.Background()
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name       string
+		status     entity.PaymentStatus
+		transition error
+		wantErr    bool
+	}{
+		{name: "completes processing payment", status: entity.PaymentStatusProcessing},
+		{name: "skips payment already completed", status: entity.PaymentStatusCompleted},
+		{name: "returns error for retry", status: entity.PaymentStatusProcessing, transition: errors.New("db down"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: tt.status}, nil)
+			if tt.status == entity.PaymentStatusProcessing {
+				paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted).Return(tt.transition)
+			}
+			useCase := NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+			err := useCase.ExecutePayment(ctx, paymentID)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			paymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentUseCase_CancelPayment(t *testing.T) {
+	ctx := context.Background()
+	paymentID := uuid.New()
+
+	tests := []struct {
+		name         string
+		method       entity.PaymentMethod
+		status       entity.PaymentStatus
+		transition   error
+		expectedCode string
+	}{
+		{name: "cancels pending payment", method: entity.PaymentMethodCreditCard, status: entity.PaymentStatusPending},
+		{name: "closes bank transfer", method: entity.PaymentMethodBankTransfer, status: entity.PaymentStatusPending},
+		{name: "rejects completed payment", method: entity.PaymentMethodCreditCard, status: entity.PaymentStatusCompleted, expectedCode: "invalid_payment_status"},
+		{name: "loses race with completion", method: entity.PaymentMethodBankTransfer, status: entity.PaymentStatusPending, transition: errors.New("payment status changed"), expectedCode: "invalid_payment_status"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			transferRepo := new(MockBankTransferRepository)
+			paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Method: tt.method, Status: tt.status}, nil)
+			paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusPending, entity.PaymentStatusCancelled).Return(tt.transition)
+			transferRepo.On("Cancel", ctx, paymentID, mock.AnythingOfType("time.Time")).Return(nil)
+			useCase := NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+
+			err := useCase.CancelPayment(ctx, paymentID)
+			if tt.expectedCode != "" {
+				assert.Equal(t, tt.expectedCode, errors.Code(err))
+				transferRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything)
+				return
+			}
+			require.NoError(t, err)
+			paymentRepo.AssertNotCalled(t, "UpdateStatus", mock.Anything, mock.Anything, mock.Anything)
+			if tt.method == entity.PaymentMethodBankTransfer {
+				transferRepo.AssertExpectations(t)
+			} else {
+				transferRepo.AssertNotCalled(t, "Cancel", mock.Anything, mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+func TestPaymentUseCase_CreatePaymentWithCardToken(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	tests := []struct {
+		name          string
+		card          *entity.Card
+		method        entity.PaymentMethod
+		expectedError string
+	}{
+		{
+			name:   "token defaults method to credit card",
+			card:   &entity.Card{Token: "tok_valid", MerchantID: merchantID, ExpMonth: 12, ExpYear: 2099},
+			method: "",
+		},
+		{
+			name:          "token from another merchant",
+			card:          &entity.Card{Token: "tok_valid", MerchantID: uuid.New(), ExpMonth: 12, ExpYear: 2099},
+			method:        entity.PaymentMethodCreditCard,
+			expectedError: "card not found",
+		},
+		{
+			name:          "expired card",
+			card:          &entity.Card{Token: "tok_valid", MerchantID: merchantID, ExpMonth: 1, ExpYear: 2020},
+			method:        entity.PaymentMethodCreditCard,
+			expectedError: "card is expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			merchantRepo := new(MockMerchantRepository)
+			customerRepo := new(MockCustomerRepository)
+			cardRepo := new(MockCardRepository)
+
+			merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+			customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+			cardRepo.On("GetByToken", ctx, "tok_valid").Return(tt.card, nil)
+			if tt.expectedError == "" {
+				paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+			}
+
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, cardRepo, new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+			payment, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+				MerchantID:         merchantID,
+				CustomerID:         customerID,
+				Amount:             5000,
+				Currency:           "USD",
+				Method:             tt.method,
+				PaymentMethodToken: "tok_valid",
+			})
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_payment_method", errors.Code(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, entity.PaymentMethodCreditCard, payment.Method)
+				assert.Equal(t, "tok_valid", payment.PaymentMethodToken)
+			}
+			paymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentUseCase_CreatePaymentRejectsTokenForOtherMethods(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	merchantRepo := new(MockMerchantRepository)
+	customerRepo := new(MockCustomerRepository)
+	merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+	customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+
+	useCase := NewPaymentUseCase(new(MockPaymentRepository), merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+	_, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+		MerchantID:         merchantID,
+		CustomerID:         customerID,
+		Amount:             5000,
+		Currency:           "USD",
+		Method:             entity.PaymentMethodBankTransfer,
+		PaymentMethodToken: "tok_valid",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "requires credit_card method")
+}
+
+func TestPaymentUseCase_CreatePaymentWithSavedMethod(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	methodID := uuid.New()
+
+	tests := []struct {
+		name          string
+		saved         *entity.PaymentMethodRecord
+		method        entity.PaymentMethod
+		expectedError string
+	}{
+		{
+			name:   "card method inherits token",
+			saved:  &entity.PaymentMethodRecord{ID: methodID, MerchantID: merchantID, CustomerID: customerID, Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			method: "",
+		},
+		{
+			name:          "method belongs to another customer",
+			saved:         &entity.PaymentMethodRecord{ID: methodID, MerchantID: merchantID, CustomerID: uuid.New(), Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			expectedError: "payment method not found",
+		},
+		{
+			name:          "conflicting method",
+			saved:         &entity.PaymentMethodRecord{ID: methodID, MerchantID: merchantID, CustomerID: customerID, Type: entity.PaymentMethodCreditCard, Token: "tok_valid"},
+			method:        entity.PaymentMethodBankTransfer,
+			expectedError: "does not match saved payment method",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			merchantRepo := new(MockMerchantRepository)
+			customerRepo := new(MockCustomerRepository)
+			cardRepo := new(MockCardRepository)
+			methodRepo := new(MockPaymentMethodRepository)
+
+			merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+			customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+			methodRepo.On("GetByID", ctx, methodID).Return(tt.saved, nil)
+			if tt.expectedError == "" {
+				cardRepo.On("GetByToken", ctx, "tok_valid").Return(&entity.Card{Token: "tok_valid", MerchantID: merchantID, ExpMonth: 12, ExpYear: 2099}, nil)
+				paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+			}
+
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, cardRepo, methodRepo, new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+			payment, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+				MerchantID:      merchantID,
+				CustomerID:      customerID,
+				Amount:          5000,
+				Currency:        "USD",
+				Method:          tt.method,
+				PaymentMethodID: &methodID,
+			})
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_payment_method", errors.Code(err))
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, entity.PaymentMethodCreditCard, payment.Method)
+				assert.Equal(t, "tok_valid", payment.PaymentMethodToken)
+				assert.Equal(t, &methodID, payment.PaymentMethodID)
+			}
+			paymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentUseCase_CreatePaymentRiskScreening(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+
+	tests := []struct {
+		name          string
+		amount        int64
+		method        entity.PaymentMethod
+		status        entity.PaymentStatus
+		decision      entity.RiskDecision
+		expectedError string
+	}{
+		{name: "allowed", amount: 10000, method: entity.PaymentMethodCreditCard, status: entity.PaymentStatusPending, decision: entity.RiskDecisionAllow},
+		{name: "held for review", amount: 60000, method: entity.PaymentMethodCreditCard, status: entity.PaymentStatusReview, decision: entity.RiskDecisionReview},
+		// 審核中的銀行轉帳在核准前不發出虛擬帳號
+		{name: "bank transfer held for review", amount: 60000, method: entity.PaymentMethodBankTransfer, status: entity.PaymentStatusReview, decision: entity.RiskDecisionReview},
+		{name: "blocked", amount: 300000, method: entity.PaymentMethodCreditCard, status: entity.PaymentStatusFailed, decision: entity.RiskDecisionBlock, expectedError: "was declined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paymentRepo := new(MockPaymentRepository)
+			merchantRepo := new(MockMerchantRepository)
+			customerRepo := new(MockCustomerRepository)
+			transferRepo := new(MockBankTransferRepository)
+
+			merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+			customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID, Email: "jane@example.com"}, nil)
+			var created *entity.Payment
+			paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Run(func(args mock.Arguments) {
+				created = args.Get(1).(*entity.Payment)
+			}).Return(nil)
+
+			risk, err := NewRiskEngine(new(MockRiskRepository), RiskConfig{
+				AmountThresholds: map[string]RiskAmountThreshold{"USD": {Review: 50000, Block: 200000}},
+			})
+			require.NoError(t, err)
+			useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, risk, nil, nil)
+
+			payment, err := useCase.CreatePayment(ctx, CreatePaymentRequest{
+				MerchantID: merchantID,
+				CustomerID: customerID,
+				Amount:     tt.amount,
+				Currency:   "USD",
+				Method:     tt.method,
+				ClientIP:   "192.0.2.1",
+			})
+
+			if tt.expectedError != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "payment_declined", errors.Code(err))
+				assert.Nil(t, payment)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, created, payment)
+			}
+			require.NotNil(t, created)
+			assert.Equal(t, tt.status, created.Status)
+			assert.Equal(t, tt.decision, created.RiskDecision)
+			assert.Equal(t, "192.0.2.1", created.ClientIP)
+			transferRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+			paymentRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestPaymentUseCase_ReviewDecisions(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	paymentID := uuid.New()
+	newUseCase := func(paymentRepo *MockPaymentRepository, transferRepo *MockBankTransferRepository) PaymentUseCase {
+		return NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), transferRepo, BankTransferConfig{ExpiresIn: 72 * time.Hour, AccountPrefix: "9921"}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+	}
+
+	t.Run("approve moves payment to pending", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, MerchantID: merchantID, Method: entity.PaymentMethodCreditCard, Status: entity.PaymentStatusReview}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusReview, entity.PaymentStatusPending).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, new(MockBankTransferRepository)).ApprovePayment(ctx, merchantID, paymentID)
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusPending, payment.Status)
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("approve issues deferred bank transfer", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		transferRepo := new(MockBankTransferRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, MerchantID: merchantID, Amount: 60000, Currency: "USD", Method: entity.PaymentMethodBankTransfer, Status: entity.PaymentStatusReview}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusReview, entity.PaymentStatusPending).Return(nil)
+		transferRepo.On("Create", ctx, mock.AnythingOfType("*entity.BankTransfer")).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, transferRepo).ApprovePayment(ctx, merchantID, paymentID)
+		require.NoError(t, err)
+		require.NotNil(t, payment.BankTransfer)
+		assert.Equal(t, int64(60000), payment.BankTransfer.AmountExpected)
+		transferRepo.AssertExpectations(t)
+	})
+
+	t.Run("reject fails payment", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, MerchantID: merchantID, Status: entity.PaymentStatusReview}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusReview, entity.PaymentStatusFailed).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, new(MockBankTransferRepository)).RejectPayment(ctx, merchantID, paymentID, "card holder unreachable")
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusFailed, payment.Status)
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("payment of another merchant", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, MerchantID: uuid.New(), Status: entity.PaymentStatusReview}, nil)
+
+		_, err := newUseCase(paymentRepo, new(MockBankTransferRepository)).ApprovePayment(ctx, merchantID, paymentID)
+		require.Error(t, err)
+		assert.Equal(t, "not_found", errors.Code(err))
+		paymentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("payment not in review", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, MerchantID: merchantID, Status: entity.PaymentStatusPending}, nil)
+
+		_, err := newUseCase(paymentRepo, new(MockBankTransferRepository)).RejectPayment(ctx, merchantID, paymentID, "")
+		require.Error(t, err)
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+	})
+}
+
+func TestPaymentUseCase_CreatePaymentLimits(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	limit := &entity.MerchantLimit{ID: uuid.New(), MerchantID: merchantID, Currency: "USD", DailyAmount: 10000}
+
+	setup := func() (*MockPaymentRepository, *MockMerchantLimitRepository, PaymentUseCase) {
+		paymentRepo := new(MockPaymentRepository)
+		merchantRepo := new(MockMerchantRepository)
+		customerRepo := new(MockCustomerRepository)
+		limitRepo := new(MockMerchantLimitRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+		limitRepo.On("ListByMerchant", ctx, merchantID).Return([]*entity.MerchantLimit{limit}, nil)
+		limits := NewLimitUseCase(limitRepo, merchantRepo)
+		useCase := NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, limits, nil)
+		return paymentRepo, limitRepo, useCase
+	}
+	req := CreatePaymentRequest{MerchantID: merchantID, CustomerID: customerID, Amount: 6000, Currency: "USD", Method: entity.PaymentMethodCreditCard}
+
+	t.Run("limit exceeded", func(t *testing.T) {
+		paymentRepo, limitRepo, useCase := setup()
+		limitRepo.On("Reserve", ctx, mock.Anything, []*entity.MerchantLimit{limit}, mock.Anything, int64(6000), mock.Anything).Return(limit, nil)
+
+		payment, err := useCase.CreatePayment(ctx, req)
+		require.Error(t, err)
+		assert.Nil(t, payment)
+		assert.Equal(t, "limit_exceeded", errors.Code(err))
+		paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("releases usage when payment cannot be saved", func(t *testing.T) {
+		paymentRepo, limitRepo, useCase := setup()
+		var reserved uuid.UUID
+		limitRepo.On("Reserve", ctx, mock.Anything, []*entity.MerchantLimit{limit}, mock.Anything, int64(6000), mock.Anything).
+			Run(func(args mock.Arguments) { reserved = args.Get(1).(uuid.UUID) }).Return(nil, nil)
+		limitRepo.On("Release", ctx, mock.MatchedBy(func(id uuid.UUID) bool { return id == reserved }), mock.Anything).Return(nil)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(errors.New("db down"))
+
+		_, err := useCase.CreatePayment(ctx, req)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create payment")
+		limitRepo.AssertExpectations(t)
+	})
+}
+
+func TestPaymentUseCase_ReleasesLimitOnTerminalStatus(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+
+	setup := func(payment *entity.Payment) (*MockPaymentRepository, *MockMerchantLimitRepository, PaymentUseCase) {
+		paymentRepo := new(MockPaymentRepository)
+		merchantRepo := new(MockMerchantRepository)
+		limitRepo := new(MockMerchantLimitRepository)
+		paymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+		limits := NewLimitUseCase(limitRepo, merchantRepo)
+		useCase := NewPaymentUseCase(paymentRepo, merchantRepo, new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, limits, nil)
+		return paymentRepo, limitRepo, useCase
+	}
+	newPayment := func(status entity.PaymentStatus) *entity.Payment {
+		return &entity.Payment{ID: uuid.New(), MerchantID: merchantID, Amount: 6000, Currency: "USD", Method: entity.PaymentMethodCreditCard, Status: status, CreatedAt: time.Now()}
+	}
+
+	tests := []struct {
+		name   string
+		status entity.PaymentStatus
+		to     entity.PaymentStatus
+		run    func(PaymentUseCase, *entity.Payment) error
+	}{
+		{"cancel", entity.PaymentStatusPending, entity.PaymentStatusCancelled, func(uc PaymentUseCase, p *entity.Payment) error {
+			return uc.CancelPayment(ctx, p.ID)
+		}},
+		{"gateway failure", entity.PaymentStatusProcessing, entity.PaymentStatusFailed, func(uc PaymentUseCase, p *entity.Payment) error {
+			return uc.FailPayment(ctx, p.ID, "card declined")
+		}},
+		{"wallet expiry", entity.PaymentStatusRequiresAction, entity.PaymentStatusFailed, func(uc PaymentUseCase, p *entity.Payment) error {
+			return uc.FailAction(ctx, p.ID, "wallet confirmation timed out")
+		}},
+		{"review rejected", entity.PaymentStatusReview, entity.PaymentStatusFailed, func(uc PaymentUseCase, p *entity.Payment) error {
+			_, err := uc.RejectPayment(ctx, merchantID, p.ID, "fraud")
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := newPayment(tt.status)
+			paymentRepo, limitRepo, useCase := setup(payment)
+			paymentRepo.On("TransitionStatus", ctx, payment.ID, tt.status, tt.to).Return(nil)
+			limitRepo.On("Release", ctx, payment.ID, mock.Anything).Return(nil)
+
+			require.NoError(t, tt.run(useCase, payment))
+			limitRepo.AssertNumberOfCalls(t, "Release", 1)
+		})
+	}
+
+	t.Run("completed payment keeps its usage", func(t *testing.T) {
+		payment := newPayment(entity.PaymentStatusPending)
+		paymentRepo, limitRepo, useCase := setup(payment)
+		paymentRepo.On("TransitionStatus", ctx, payment.ID, mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, useCase.ProcessPayment(ctx, payment.ID))
+		limitRepo.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("lost transition does not release", func(t *testing.T) {
+		payment := newPayment(entity.PaymentStatusPending)
+		paymentRepo, limitRepo, useCase := setup(payment)
+		paymentRepo.On("TransitionStatus", ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusCancelled).Return(errors.New("payment status changed"))
+
+		require.Error(t, useCase.CancelPayment(ctx, payment.ID))
+		limitRepo.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+func TestPaymentUseCase_ReleasesPaymentLinkOnTerminalStatus(t *testing.T) {
+	ctx := context.Background()
+	linkID := uuid.New()
+
+	setup := func(payment *entity.Payment) (*MockPaymentRepository, *MockPaymentLinkRepository, PaymentUseCase) {
+		paymentRepo := new(MockPaymentRepository)
+		linkRepo := new(MockPaymentLinkRepository)
+		paymentRepo.On("GetByID", ctx, payment.ID).Return(payment, nil)
+		useCase := NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, linkRepo)
+		return paymentRepo, linkRepo, useCase
+	}
+	newPayment := func(status entity.PaymentStatus) *entity.Payment {
+		return &entity.Payment{ID: uuid.New(), Amount: 6000, Currency: "USD", Method: entity.PaymentMethodCreditCard, Status: status, PaymentLinkID: &linkID}
+	}
+
+	t.Run("cancelled payment frees its slot", func(t *testing.T) {
+		payment := newPayment(entity.PaymentStatusPending)
+		paymentRepo, linkRepo, useCase := setup(payment)
+		paymentRepo.On("TransitionStatus", ctx, payment.ID, entity.PaymentStatusPending, entity.PaymentStatusCancelled).Return(nil)
+		linkRepo.On("ReleasePayment", ctx, linkID).Return(nil)
+
+		require.NoError(t, useCase.CancelPayment(ctx, payment.ID))
+		linkRepo.AssertNumberOfCalls(t, "ReleasePayment", 1)
+	})
+
+	t.Run("failed payment frees its slot", func(t *testing.T) {
+		payment := newPayment(entity.PaymentStatusProcessing)
+		paymentRepo, linkRepo, useCase := setup(payment)
+		paymentRepo.On("TransitionStatus", ctx, payment.ID, entity.PaymentStatusProcessing, entity.PaymentStatusFailed).Return(nil)
+		linkRepo.On("ReleasePayment", ctx, linkID).Return(nil)
+
+		require.NoError(t, useCase.FailPayment(ctx, payment.ID, "card declined"))
+		linkRepo.AssertNumberOfCalls(t, "ReleasePayment", 1)
+	})
+
+	t.Run("completed payment keeps its slot", func(t *testing.T) {
+		payment := newPayment(entity.PaymentStatusPending)
+		paymentRepo, linkRepo, useCase := setup(payment)
+		paymentRepo.On("TransitionStatus", ctx, payment.ID, mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, useCase.ProcessPayment(ctx, payment.ID))
+		linkRepo.AssertNotCalled(t, "ReleasePayment", mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+// RateLimiter 以 token bucket 限制每個商戶（API key）的請求頻率
+type RateLimiter interface {
+	// Allow 為商戶在 method 與路由樣板（例如 /api/v1/payments/:id）的請求取出一個 token，
+	// 沒有適用的限制時回傳 nil
+	Allow(ctx context.Context, merchant *entity.Merchant, method, route string) (*entity.RateLimitResult, error)
+}
+
+// RateLimitConfig 設定請求頻率限制。商戶所有請求共用一個 bucket，
+// Routes 中的路由則另外計算，不再扣商戶共用的 bucket
+type RateLimitConfig struct {
+	// Default 適用於 Tiers 沒有列出等級的商戶
+	Default entity.RateLimit
+	// Tiers 以商戶等級為鍵
+	Tiers  map[string]entity.RateLimit
+	Routes []RateLimitRoute
+}
+
+// RateLimitRoute 為個別路由的限制，Tiers 沒有列出的等級使用 Limit；
+// 兩者都沒有設定 Requests 時沿用商戶共用的限制
+type RateLimitRoute struct {
+	Method string
+	Path   string
+	Limit  entity.RateLimit
+	Tiers  map[string]entity.RateLimit
+}
+
+type rateLimiter struct {
+	rateLimitRepo repository.RateLimitRepository
+	config        RateLimitConfig
+	routes        map[string]RateLimitRoute // 以 "METHOD path" 為鍵
+	now           func() time.Time
+}
+
+// NewRateLimiter 檢查限制設定，方法與等級不分大小寫
+func NewRateLimiter(rateLimitRepo repository.RateLimitRepository, config RateLimitConfig) (RateLimiter, error) {
+	if err := validateRateLimit("default", config.Default); err != nil {
+		return nil, err
+	}
+	tiers, err := rateLimitTiers(config.Tiers)
+	if err != nil {
+		return nil, err
+	}
+	config.Tiers = tiers
+
+	limiter := &rateLimiter{
+		rateLimitRepo: rateLimitRepo,
+		config:        config,
+		routes:        make(map[string]RateLimitRoute, len(config.Routes)),
+		now:           time.Now,
+	}
+	for _, route := range config.Routes {
+		if route.Method == "" || route.Path == "" {
+			return nil, errors.New("rate limit route requires method and path")
+		}
+		key := routeKey(route.Method, route.Path)
+		if _, exists := limiter.routes[key]; exists {
+			return nil, errors.New(fmt.Sprintf("duplicate rate limit route %s", key))
+		}
+		if err := validateRateLimit(key, route.Limit); err != nil {
+			return nil, err
+		}
+		if route.Tiers, err = rateLimitTiers(route.Tiers); err != nil {
+			return nil, err
+		}
+		limiter.routes[key] = route
+	}
+	return limiter, nil
+}
+
+func (l *rateLimiter) Allow(ctx context.Context, merchant *entity.Merchant, method, route string) (*entity.RateLimitResult, error) {
+	tier := strings.ToLower(merchant.Tier)
+	key := merchant.ID.String()
+
+	limit, ok := l.config.Tiers[tier]
+	if !ok {
+		limit = l.config.Default
+	}
+	if r, exists := l.routes[routeKey(method, route)]; exists {
+		routeLimit, ok := r.Tiers[tier]
+		if !ok {
+			routeLimit = r.Limit
+		}
+		if routeLimit.Requests > 0 {
+			limit = routeLimit
+			key += "|" + routeKey(method, route)
+		}
+	}
+	if limit.Requests == 0 {
+		return nil, nil
+	}
+
+	result, err := l.rateLimitRepo.Take(ctx, key, limit, l.now())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to take rate limit token")
+	}
+	return result, nil
+}
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
+
+func rateLimitTiers(tiers map[string]entity.RateLimit) (map[string]entity.RateLimit, error) {
+	normalized := make(map[string]entity.RateLimit, len(tiers))
+	for tier, limit := range tiers {
+		if err := validateRateLimit("tier "+tier, limit); err != nil {
+			return nil, err
+		}
+		normalized[strings.ToLower(tier)] = limit
+	}
+	return normalized, nil
+}
+
+func validateRateLimit(name string, limit entity.RateLimit) error {
+	if limit.Requests < 0 || limit.Burst < 0 {
+		return errors.New(fmt.Sprintf("rate limit for %s must not be negative", name))
+	}
+	if limit.Requests > 0 && limit.Period <= 0 {
+		return errors.New(fmt.Sprintf("rate limit for %s requires a period", name))
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRateLimitRepository struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (*entity.RateLimitResult, error) {
+	args := m.Called(ctx, key, limit, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.RateLimitResult), args.Error(1)
+}
+
+func TestRateLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	standard := entity.RateLimit{Requests: 100, Period: time.Minute, Burst: 20}
+	premium := entity.RateLimit{Requests: 1000, Period: time.Minute}
+	createPayment := entity.RateLimit{Requests: 10, Period: time.Minute}
+	premiumCreatePayment := entity.RateLimit{Requests: 100, Period: time.Minute}
+	config := RateLimitConfig{
+		Default: standard,
+		Tiers:   map[string]entity.RateLimit{"Premium": premium, "internal": {}},
+		Routes: []RateLimitRoute{{
+			Method: "post",
+			Path:   "/api/v1/payments",
+			Limit:  createPayment,
+			Tiers:  map[string]entity.RateLimit{"premium": premiumCreatePayment},
+		}},
+	}
+
+	merchantID := uuid.New()
+	routeKey := merchantID.String() + "|POST /api/v1/payments"
+	tests := []struct {
+		name   string
+		tier   string
+		method string
+		route  string
+		key    string
+		limit  entity.RateLimit
+	}{
+		{name: "tier without limits uses default", tier: "standard", method: "GET", route: "/api/v1/payments/:id", key: merchantID.String(), limit: standard},
+		{name: "tier limits", tier: "premium", method: "GET", route: "/api/v1/payments/:id", key: merchantID.String(), limit: premium},
+		{name: "route limits", tier: "standard", method: "POST", route: "/api/v1/payments", key: routeKey, limit: createPayment},
+		{name: "route tier limits", tier: "PREMIUM", method: "POST", route: "/api/v1/payments", key: routeKey, limit: premiumCreatePayment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rateLimitRepo := new(MockRateLimitRepository)
+			limiter, err := NewRateLimiter(rateLimitRepo, config)
+			require.NoError(t, err)
+			limiter.(*rateLimiter).now = func() time.Time { return now }
+
+			expected := &entity.RateLimitResult{Limit: tt.limit, Allowed: true}
+			rateLimitRepo.On("Take", ctx, tt.key, tt.limit, now).Return(expected, nil)
+
+			result, err := limiter.Allow(ctx, &entity.Merchant{ID: merchantID, Tier: tt.tier}, tt.method, tt.route)
+			require.NoError(t, err)
+			assert.Equal(t, expected, result)
+			rateLimitRepo.AssertExpectations(t)
+		})
+	}
+
+	// 等級的 Requests 為 0 時不限制
+	rateLimitRepo := new(MockRateLimitRepository)
+	limiter, err := NewRateLimiter(rateLimitRepo, config)
+	require.NoError(t, err)
+	result, err := limiter.Allow(ctx, &entity.Merchant{ID: merchantID, Tier: "internal"}, "GET", "/api/v1/payments/:id")
+	require.NoError(t, err)
+	assert.Nil(t, result)
+	rateLimitRepo.AssertNotCalled(t, "Take", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestNewRateLimiter_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RateLimitConfig
+	}{
+		{name: "default without period", config: RateLimitConfig{Default: entity.RateLimit{Requests: 10}}},
+		{name: "negative burst", config: RateLimitConfig{Tiers: map[string]entity.RateLimit{"standard": {Requests: 10, Period: time.Minute, Burst: -1}}}},
+		{name: "route without path", config: RateLimitConfig{Routes: []RateLimitRoute{{Method: "POST"}}}},
+		{name: "duplicate route", config: RateLimitConfig{Routes: []RateLimitRoute{
+			{Method: "POST", Path: "/api/v1/payments"},
+			{Method: "post", Path: "/api/v1/payments"},
+		}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRateLimiter(new(MockRateLimitRepository), tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,408 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/bankfile"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// ReconciliationConfig 設定對帳規則
+type ReconciliationConfig struct {
+	// DateTolerance 為入帳日期與付款完成時間可接受的差距
+	DateTolerance time.Duration
+}
+
+// maxReferenceLookups 限制每筆交易以附言查詢付款的次數，避免長附言造成大量查詢
+const maxReferenceLookups = 5
+
+// reconciliationPageSize 為載入期間內已完成付款時每次查詢的筆數
+const reconciliationPageSize = 500
+
+// ReconciliationUseCase 匯入銀行對帳單並與付款對帳，差異項目由人工處理
+type ReconciliationUseCase interface {
+	// Import 解析對帳單並對帳，format 為空時自動判斷格式。
+	// 每次匯入都是獨立的對帳，重複匯入同一份對帳單會產生新的報表
+	Import(ctx context.Context, r io.Reader, format string) (*ReconciliationReport, error)
+	GetReport(ctx context.Context, statementID uuid.UUID) (*ReconciliationReport, error)
+	ListStatements(ctx context.Context, limit, offset int) ([]*entity.Statement, error)
+	// ResolveLine 處理對帳單上找不到付款的交易，可指定人工對應的付款
+	ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, req ResolveLineRequest) (*ReconciliationReport, error)
+	// ResolveException 處理對帳單上沒有交易的付款
+	ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, req ResolveExceptionRequest) (*ReconciliationReport, error)
+}
+
+type ResolveLineRequest struct {
+	PaymentID *uuid.UUID `json:"payment_id,omitempty"`
+	Note      string     `json:"note"`
+}
+
+type ResolveExceptionRequest struct {
+	Note string `json:"note"`
+}
+
+// ReconciliationReport 為對帳結果，依狀態分列銀行端與系統端的項目
+type ReconciliationReport struct {
+	Statement         *entity.Statement            `json:"statement"`
+	Summary           ReconciliationSummary        `json:"summary"`
+	Matched           []*entity.StatementLine      `json:"matched"`
+	UnmatchedInBank   []*entity.StatementLine      `json:"unmatched_in_bank"`
+	UnmatchedInSystem []*entity.StatementException `json:"unmatched_in_system"`
+	ResolvedInBank    []*entity.StatementLine      `json:"resolved_in_bank"`
+	ResolvedInSystem  []*entity.StatementException `json:"resolved_in_system"`
+}
+
+type ReconciliationSummary struct {
+	Lines             int `json:"lines"`
+	Matched           int `json:"matched"`
+	UnmatchedInBank   int `json:"unmatched_in_bank"`
+	UnmatchedInSystem int `json:"unmatched_in_system"`
+	Resolved          int `json:"resolved"`
+}
+
+type reconciliationUseCase struct {
+	statementRepo repository.StatementRepository
+	paymentRepo   repository.PaymentRepository
+	transferRepo  repository.BankTransferRepository
+	config        ReconciliationConfig
+	now           func() time.Time
+}
+
+func NewReconciliationUseCase(
+	statementRepo repository.StatementRepository,
+	paymentRepo repository.PaymentRepository,
+	transferRepo repository.BankTransferRepository,
+	config ReconciliationConfig,
+) ReconciliationUseCase {
+	return &reconciliationUseCase{
+		statementRepo: statementRepo,
+		paymentRepo:   paymentRepo,
+		transferRepo:  transferRepo,
+		config:        config,
+		now:           time.Now,
+	}
+}
+
+func invalidReconciliation(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_reconciliation")
+}
+
+func (uc *reconciliationUseCase) Import(ctx context.Context, r io.Reader, format string) (*ReconciliationReport, error) {
+	parsed, err := bankfile.ParseStatement(r, format)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "invalid statement"), "invalid_reconciliation")
+	}
+	if len(parsed.Entries) == 0 {
+		return nil, invalidReconciliation("statement has no entries")
+	}
+
+	now := uc.now()
+	statement := &entity.Statement{
+		ID:          uuid.New(),
+		Format:      parsed.Format,
+		BankID:      parsed.ID,
+		Account:     parsed.Account,
+		Currency:    parsed.Currency,
+		PeriodStart: parsed.From,
+		PeriodEnd:   parsed.To,
+		CreatedAt:   now,
+	}
+
+	// matchedBy 記錄已對應的付款與對應的交易序號，同一筆付款只能對應一筆交易
+	matchedBy := make(map[uuid.UUID]string)
+	currencies := make(map[string]bool)
+	for _, e := range parsed.Entries {
+		line := &entity.StatementLine{
+			ID:            uuid.New(),
+			StatementID:   statement.ID,
+			TransactionID: e.TransactionID,
+			BookingDate:   e.BookingDate,
+			Amount:        e.Amount,
+			Currency:      e.Currency,
+			Reference:     e.Reference,
+			Counterparty:  e.Payer,
+			Status:        entity.ReconciliationStatusUnmatched,
+			CreatedAt:     now,
+		}
+		currencies[e.Currency] = true
+		if err := uc.match(ctx, line, matchedBy); err != nil {
+			return nil, err
+		}
+		statement.Lines = append(statement.Lines, line)
+	}
+
+	exceptions, err := uc.exceptions(ctx, statement, currencies, matchedBy, now)
+	if err != nil {
+		return nil, err
+	}
+	statement.Exceptions = exceptions
+
+	if err := uc.statementRepo.Create(ctx, statement); err != nil {
+		return nil, errors.Wrap(err, "failed to save statement")
+	}
+	return newReconciliationReport(statement), nil
+}
+
+// match 以附言找出付款並比對幣別、金額與日期，全部相符才標為 matched。
+// 找到付款但不相符時保留付款 ID 與原因，方便人工處理
+func (uc *reconciliationUseCase) match(ctx context.Context, line *entity.StatementLine, matchedBy map[uuid.UUID]string) error {
+	if line.Amount < 0 {
+		line.Note = "debit entry"
+		return nil
+	}
+
+	payment, err := uc.findPayment(ctx, line.Reference)
+	if err != nil {
+		return err
+	}
+	if payment == nil {
+		line.Note = "no payment found for reference"
+		return nil
+	}
+	line.PaymentID = &payment.ID
+
+	switch {
+	case payment.Status != entity.PaymentStatusCompleted:
+		line.Note = fmt.Sprintf("payment is %s", payment.Status)
+	case payment.Currency != line.Currency:
+		line.Note = fmt.Sprintf("currency mismatch: payment is %s", payment.Currency)
+	case payment.Amount != line.Amount:
+		line.Note = fmt.Sprintf("amount mismatch: payment is %d", payment.Amount)
+	case payment.CompletedAt == nil || absDuration(line.BookingDate.Sub(*payment.CompletedAt)) > uc.config.DateTolerance:
+		line.Note = "booking date outside tolerance of payment completion"
+	case matchedBy[payment.ID] != "":
+		line.Note = fmt.Sprintf("payment already matched by transaction %s", matchedBy[payment.ID])
+	default:
+		line.Status = entity.ReconciliationStatusMatched
+		line.Note = ""
+		matchedBy[payment.ID] = line.TransactionID
+	}
+	return nil
+}
+
+// findPayment 先以整段附言查詢參考號，再逐一嘗試附言中的字詞。
+// 參考號可以是付款的外部參考號、銀行轉帳的匯款參考號或付款 ID
+func (uc *reconciliationUseCase) findPayment(ctx context.Context, reference string) (*entity.Payment, error) {
+	reference = strings.TrimSpace(reference)
+	if reference == "" {
+		return nil, nil
+	}
+
+	candidates := []string{reference}
+	for _, token := range strings.FieldsFunc(reference, func(r rune) bool {
+		return r == ' ' || r == ',' || r == ';' || r == '/' || r == '\t'
+	}) {
+		if token != reference {
+			candidates = append(candidates, token)
+		}
+	}
+	if len(candidates) > maxReferenceLookups {
+		candidates = candidates[:maxReferenceLookups]
+	}
+
+	for _, candidate := range candidates {
+		if id, err := uuid.Parse(candidate); err == nil {
+			if payment, err := uc.paymentRepo.GetByID(ctx, id); err == nil {
+				return payment, nil
+			}
+			continue
+		}
+		if payment, err := uc.paymentRepo.GetByReference(ctx, candidate); err == nil {
+			return payment, nil
+		}
+		if transfer, err := uc.transferRepo.GetByReference(ctx, strings.ToUpper(candidate)); err == nil {
+			if payment, err := uc.paymentRepo.GetByID(ctx, transfer.PaymentID); err == nil {
+				return payment, nil
+			}
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return nil, nil
+}
+
+// exceptions 回傳期間內已完成、幣別出現在對帳單上但沒有對應交易的付款
+func (uc *reconciliationUseCase) exceptions(
+	ctx context.Context,
+	statement *entity.Statement,
+	currencies map[string]bool,
+	matchedBy map[uuid.UUID]string,
+	now time.Time,
+) ([]*entity.StatementException, error) {
+	if statement.PeriodStart.IsZero() || !statement.PeriodEnd.After(statement.PeriodStart) {
+		return nil, nil
+	}
+
+	var exceptions []*entity.StatementException
+	for offset := 0; ; offset += reconciliationPageSize {
+		payments, err := uc.paymentRepo.GetCompletedBetween(ctx, statement.PeriodStart, statement.PeriodEnd, reconciliationPageSize, offset)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to load completed payments")
+		}
+		for _, payment := range payments {
+			if matchedBy[payment.ID] != "" || !currencies[payment.Currency] || payment.CompletedAt == nil {
+				continue
+			}
+			exceptions = append(exceptions, &entity.StatementException{
+				StatementID: statement.ID,
+				PaymentID:   payment.ID,
+				MerchantID:  payment.MerchantID,
+				Amount:      payment.Amount,
+				Currency:    payment.Currency,
+				Reference:   payment.Reference,
+				CompletedAt: *payment.CompletedAt,
+				Status:      entity.ReconciliationStatusUnmatched,
+				CreatedAt:   now,
+			})
+		}
+		if len(payments) < reconciliationPageSize {
+			return exceptions, nil
+		}
+	}
+}
+
+func (uc *reconciliationUseCase) GetReport(ctx context.Context, statementID uuid.UUID) (*ReconciliationReport, error) {
+	statement, err := uc.statementRepo.GetByID(ctx, statementID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get statement"), "not_found")
+	}
+	return newReconciliationReport(statement), nil
+}
+
+func (uc *reconciliationUseCase) ListStatements(ctx context.Context, limit, offset int) ([]*entity.Statement, error) {
+	statements, err := uc.statementRepo.List(ctx, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list statements")
+	}
+	return statements, nil
+}
+
+func (uc *reconciliationUseCase) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, req ResolveLineRequest) (*ReconciliationReport, error) {
+	note := strings.TrimSpace(req.Note)
+	if req.PaymentID == nil && note == "" {
+		return nil, invalidReconciliation("note is required when no payment is given")
+	}
+
+	statement, err := uc.statementRepo.GetByID(ctx, statementID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get statement"), "not_found")
+	}
+	var line *entity.StatementLine
+	for _, l := range statement.Lines {
+		if l.ID == lineID {
+			line = l
+		}
+	}
+	if line == nil {
+		return nil, errors.WithCode(errors.New("statement line not found"), "not_found")
+	}
+	if line.Status != entity.ReconciliationStatusUnmatched {
+		return nil, invalidReconciliation(fmt.Sprintf("statement line is already %s", line.Status))
+	}
+	if req.PaymentID != nil {
+		if _, err := uc.paymentRepo.GetByID(ctx, *req.PaymentID); err != nil {
+			return nil, errors.WithCode(errors.Wrap(err, "failed to get payment"), "invalid_reconciliation")
+		}
+	}
+
+	now := uc.now()
+	if err := uc.statementRepo.ResolveLine(ctx, statementID, lineID, req.PaymentID, note, now); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to resolve statement line"), "invalid_reconciliation")
+	}
+	// 人工對應的付款若列在系統端差異中，一併標為已處理
+	if req.PaymentID != nil {
+		for _, exception := range statement.Exceptions {
+			if exception.PaymentID == *req.PaymentID && exception.Status == entity.ReconciliationStatusUnmatched {
+				exceptionNote := fmt.Sprintf("resolved with transaction %s", line.TransactionID)
+				if err := uc.statementRepo.ResolveException(ctx, statementID, exception.PaymentID, exceptionNote, now); err != nil {
+					return nil, errors.Wrap(err, "failed to resolve statement exception")
+				}
+			}
+		}
+	}
+	return uc.GetReport(ctx, statementID)
+}
+
+func (uc *reconciliationUseCase) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, req ResolveExceptionRequest) (*ReconciliationReport, error) {
+	note := strings.TrimSpace(req.Note)
+	if note == "" {
+		return nil, invalidReconciliation("note is required")
+	}
+
+	statement, err := uc.statementRepo.GetByID(ctx, statementID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get statement"), "not_found")
+	}
+	var exception *entity.StatementException
+	for _, e := range statement.Exceptions {
+		if e.PaymentID == paymentID {
+			exception = e
+		}
+	}
+	if exception == nil {
+		return nil, errors.WithCode(errors.New("statement exception not found"), "not_found")
+	}
+	if exception.Status != entity.ReconciliationStatusUnmatched {
+		return nil, invalidReconciliation(fmt.Sprintf("statement exception is already %s", exception.Status))
+	}
+
+	if err := uc.statementRepo.ResolveException(ctx, statementID, paymentID, note, uc.now()); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to resolve statement exception"), "invalid_reconciliation")
+	}
+	return uc.GetReport(ctx, statementID)
+}
+
+// newReconciliationReport 依狀態分列交易與差異付款，報表中的對帳單不重複列出明細
+func newReconciliationReport(statement *entity.Statement) *ReconciliationReport {
+	header := *statement
+	header.Lines = nil
+	header.Exceptions = nil
+	report := &ReconciliationReport{
+		Statement:         &header,
+		Matched:           []*entity.StatementLine{},
+		UnmatchedInBank:   []*entity.StatementLine{},
+		UnmatchedInSystem: []*entity.StatementException{},
+		ResolvedInBank:    []*entity.StatementLine{},
+		ResolvedInSystem:  []*entity.StatementException{},
+	}
+	for _, line := range statement.Lines {
+		switch line.Status {
+		case entity.ReconciliationStatusMatched:
+			report.Matched = append(report.Matched, line)
+		case entity.ReconciliationStatusResolved:
+			report.ResolvedInBank = append(report.ResolvedInBank, line)
+		default:
+			report.UnmatchedInBank = append(report.UnmatchedInBank, line)
+		}
+	}
+	for _, exception := range statement.Exceptions {
+		if exception.Status == entity.ReconciliationStatusResolved {
+			report.ResolvedInSystem = append(report.ResolvedInSystem, exception)
+		} else {
+			report.UnmatchedInSystem = append(report.UnmatchedInSystem, exception)
+		}
+	}
+	report.Summary = ReconciliationSummary{
+		Lines:             len(statement.Lines),
+		Matched:           len(report.Matched),
+		UnmatchedInBank:   len(report.UnmatchedInBank),
+		UnmatchedInSystem: len(report.UnmatchedInSystem),
+		Resolved:          len(report.ResolvedInBank) + len(report.ResolvedInSystem),
+	}
+	return report
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
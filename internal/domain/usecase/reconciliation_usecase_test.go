@@ -0,0 +1,247 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockStatementRepository struct {
+	mock.Mock
+}
+
+func (m *MockStatementRepository) Create(ctx context.Context, statement *entity.Statement) error {
+	args := m.Called(ctx, statement)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Statement, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Statement), args.Error(1)
+}
+
+func (m *MockStatementRepository) List(ctx context.Context, limit, offset int) ([]*entity.Statement, error) {
+	args := m.Called(ctx, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Statement), args.Error(1)
+}
+
+func (m *MockStatementRepository) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) error {
+	args := m.Called(ctx, statementID, lineID, paymentID, note, now)
+	return args.Error(0)
+}
+
+func (m *MockStatementRepository) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) error {
+	args := m.Called(ctx, statementID, paymentID, note, now)
+	return args.Error(0)
+}
+
+// newTestReconciliationUseCase 建立日期容許誤差 72 小時、時間固定為 now 的 use case
+func newTestReconciliationUseCase(statements *MockStatementRepository, payments *MockPaymentRepository, transfers *MockBankTransferRepository, now time.Time) ReconciliationUseCase {
+	uc := NewReconciliationUseCase(statements, payments, transfers, ReconciliationConfig{DateTolerance: 72 * time.Hour}).(*reconciliationUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func completedPayment(reference string, amount int64, currency string, completedAt time.Time) *entity.Payment {
+	return &entity.Payment{
+		ID: uuid.New(), MerchantID: uuid.New(), Amount: amount, Currency: currency,
+		Status: entity.PaymentStatusCompleted, Reference: reference, CompletedAt: &completedAt,
+	}
+}
+
+func TestReconciliationUseCase_Import(t *testing.T) {
+	now := time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC)
+	statements := new(MockStatementRepository)
+	payments := new(MockPaymentRepository)
+	transfers := new(MockBankTransferRepository)
+	useCase := newTestReconciliationUseCase(statements, payments, transfers, now)
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	exact := completedPayment("ORD-1", 10000, "USD", day.Add(9*time.Hour))
+	short := completedPayment("ORD-2", 6000, "USD", day.Add(10*time.Hour))
+	late := completedPayment("ORD-3", 2500, "USD", day.Add(-5*24*time.Hour))
+	missing := completedPayment("ORD-4", 4000, "USD", day.Add(12*time.Hour))
+	otherCurrency := completedPayment("ORD-5", 4000, "EUR", day.Add(13*time.Hour))
+
+	payments.On("GetByReference", mock.Anything, "ORD-1").Return(exact, nil)
+	payments.On("GetByReference", mock.Anything, "ORD-2").Return(short, nil)
+	payments.On("GetByReference", mock.Anything, "ORD-3").Return(late, nil)
+	payments.On("GetByReference", mock.Anything, mock.Anything).Return(nil, errors.New("payment not found"))
+	transfers.On("GetByReference", mock.Anything, mock.Anything).Return(nil, errors.New("bank transfer not found"))
+	payments.On("GetByID", mock.Anything, missing.ID).Return(nil, errors.New("payment not found"))
+	payments.On("GetCompletedBetween", mock.Anything, day, day.AddDate(0, 0, 1), reconciliationPageSize, 0).
+		Return([]*entity.Payment{exact, short, missing, otherCurrency}, nil)
+	var saved *entity.Statement
+	statements.On("Create", mock.Anything, mock.AnythingOfType("*entity.Statement")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*entity.Statement) }).Return(nil)
+
+	csv := "transaction_id,date,amount,currency,reference,payer\n" +
+		"TX1,2026-10-01,100.00,USD,ORD-1,Jane Doe\n" +
+		"TX2,2026-10-01,50.00,USD,Invoice ORD-2,John Roe\n" +
+		"TX3,2026-10-01,25.00,USD,ORD-3,\n" +
+		"TX4,2026-10-01,12.00,USD,no reference here,\n" +
+		"TX5,2026-10-01,100.00,USD,ORD-1,Jane Doe\n" +
+		"TX6,2026-10-01,-1.50,USD,Monthly fee,\n" +
+		"TX7,2026-10-01,30.00,USD," + missing.ID.String() + ",\n"
+	report, err := useCase.Import(context.Background(), strings.NewReader(csv), "")
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+
+	assert.Equal(t, "csv", report.Statement.Format)
+	assert.Equal(t, "USD", report.Statement.Currency)
+	assert.Equal(t, day, report.Statement.PeriodStart)
+	assert.Empty(t, report.Statement.Lines, "lines are listed by status, not on the statement")
+	assert.Len(t, saved.Lines, 7)
+
+	require.Len(t, report.Matched, 1)
+	assert.Equal(t, "TX1", report.Matched[0].TransactionID)
+	assert.Equal(t, &exact.ID, report.Matched[0].PaymentID)
+
+	notes := make(map[string]string)
+	for _, line := range report.UnmatchedInBank {
+		notes[line.TransactionID] = line.Note
+	}
+	assert.Equal(t, map[string]string{
+		"TX2": "amount mismatch: payment is 6000",
+		"TX3": "booking date outside tolerance of payment completion",
+		"TX4": "no payment found for reference",
+		"TX5": "payment already matched by transaction TX1",
+		"TX6": "debit entry",
+		"TX7": "no payment found for reference",
+	}, notes)
+	assert.Equal(t, &short.ID, saved.Lines[1].PaymentID, "mismatched payment is kept as a hint")
+
+	// 只有金額不符的付款與對帳單上沒有的付款列為系統端差異，其他幣別不列入
+	require.Len(t, report.UnmatchedInSystem, 2)
+	assert.Equal(t, short.ID, report.UnmatchedInSystem[0].PaymentID)
+	assert.Equal(t, missing.ID, report.UnmatchedInSystem[1].PaymentID)
+	assert.Equal(t, missing.Reference, report.UnmatchedInSystem[1].Reference)
+	assert.Equal(t, ReconciliationSummary{Lines: 7, Matched: 1, UnmatchedInBank: 6, UnmatchedInSystem: 2}, report.Summary)
+}
+
+func TestReconciliationUseCase_ImportBankTransferReference(t *testing.T) {
+	now := time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC)
+	statements := new(MockStatementRepository)
+	payments := new(MockPaymentRepository)
+	transfers := new(MockBankTransferRepository)
+	useCase := newTestReconciliationUseCase(statements, payments, transfers, now)
+	day := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	// 銀行轉帳付款的匯款參考號記錄在匯款資訊上，付款本身沒有外部參考號
+	payment := completedPayment("", 10000, "USD", day.Add(time.Hour))
+	payments.On("GetByReference", mock.Anything, mock.Anything).Return(nil, errors.New("payment not found"))
+	transfers.On("GetByReference", mock.Anything, "BT7K2M9QXP4R").Return(&entity.BankTransfer{PaymentID: payment.ID}, nil)
+	transfers.On("GetByReference", mock.Anything, mock.Anything).Return(nil, errors.New("bank transfer not found"))
+	payments.On("GetByID", mock.Anything, payment.ID).Return(payment, nil)
+	payments.On("GetCompletedBetween", mock.Anything, day, day.AddDate(0, 0, 1), reconciliationPageSize, 0).
+		Return([]*entity.Payment{payment}, nil)
+	statements.On("Create", mock.Anything, mock.AnythingOfType("*entity.Statement")).Return(nil)
+
+	csv := "transaction_id,date,amount,currency,reference\n" +
+		"TX1,2026-10-01,100.00,USD,order bt7k2m9qxp4r\n"
+	report, err := useCase.Import(context.Background(), strings.NewReader(csv), "csv")
+	require.NoError(t, err)
+	require.Len(t, report.Matched, 1)
+	assert.Equal(t, &payment.ID, report.Matched[0].PaymentID)
+	assert.Empty(t, report.UnmatchedInSystem)
+}
+
+func TestReconciliationUseCase_ImportInvalid(t *testing.T) {
+	statements := new(MockStatementRepository)
+	useCase := newTestReconciliationUseCase(statements, new(MockPaymentRepository), new(MockBankTransferRepository), time.Now())
+
+	_, err := useCase.Import(context.Background(), strings.NewReader("a,b\n1,2\n"), "csv")
+	require.Error(t, err)
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err))
+
+	_, err = useCase.Import(context.Background(), strings.NewReader("x"), "mt940")
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err))
+
+	_, err = useCase.Import(context.Background(), strings.NewReader("transaction_id,date,amount,currency\n"), "")
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err))
+	statements.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestReconciliationUseCase_Resolve(t *testing.T) {
+	now := time.Date(2026, 10, 3, 8, 0, 0, 0, time.UTC)
+	statements := new(MockStatementRepository)
+	payments := new(MockPaymentRepository)
+	transfers := new(MockBankTransferRepository)
+	useCase := newTestReconciliationUseCase(statements, payments, transfers, now)
+	payment := completedPayment("ORD-9", 5000, "USD", now)
+	line := &entity.StatementLine{ID: uuid.New(), TransactionID: "TX9", Status: entity.ReconciliationStatusUnmatched}
+	matched := &entity.StatementLine{ID: uuid.New(), TransactionID: "TX1", Status: entity.ReconciliationStatusMatched}
+	statement := &entity.Statement{
+		ID:    uuid.New(),
+		Lines: []*entity.StatementLine{matched, line},
+		Exceptions: []*entity.StatementException{
+			{PaymentID: payment.ID, Status: entity.ReconciliationStatusUnmatched},
+		},
+	}
+	statements.On("GetByID", mock.Anything, statement.ID).Return(statement, nil)
+	statements.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("statement not found"))
+	payments.On("GetByID", mock.Anything, payment.ID).Return(payment, nil)
+	payments.On("GetByID", mock.Anything, mock.Anything).Return(nil, errors.New("payment not found"))
+	statements.On("ResolveLine", mock.Anything, statement.ID, line.ID, &payment.ID, "wrong reference", now).Return(nil).Once()
+	statements.On("ResolveException", mock.Anything, statement.ID, payment.ID, "resolved with transaction TX9", now).Return(nil).Once()
+
+	ctx := context.Background()
+	_, err := useCase.ResolveLine(ctx, statement.ID, line.ID, ResolveLineRequest{Note: "  "})
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err), "note is required without a payment")
+	_, err = useCase.ResolveLine(ctx, uuid.New(), line.ID, ResolveLineRequest{Note: "x"})
+	assert.Equal(t, "not_found", errors.Code(err))
+	_, err = useCase.ResolveLine(ctx, statement.ID, uuid.New(), ResolveLineRequest{Note: "x"})
+	assert.Equal(t, "not_found", errors.Code(err))
+	_, err = useCase.ResolveLine(ctx, statement.ID, matched.ID, ResolveLineRequest{Note: "x"})
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err), "matched lines cannot be resolved")
+	unknown := uuid.New()
+	_, err = useCase.ResolveLine(ctx, statement.ID, line.ID, ResolveLineRequest{PaymentID: &unknown})
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err), "payment must exist")
+
+	_, err = useCase.ResolveLine(ctx, statement.ID, line.ID, ResolveLineRequest{PaymentID: &payment.ID, Note: " wrong reference "})
+	require.NoError(t, err)
+	statements.AssertExpectations(t)
+
+	statements.On("ResolveException", mock.Anything, statement.ID, payment.ID, "refunded", now).Return(nil).Once()
+	_, err = useCase.ResolveException(ctx, statement.ID, payment.ID, ResolveExceptionRequest{})
+	assert.Equal(t, "invalid_reconciliation", errors.Code(err))
+	_, err = useCase.ResolveException(ctx, statement.ID, uuid.New(), ResolveExceptionRequest{Note: "refunded"})
+	assert.Equal(t, "not_found", errors.Code(err))
+	_, err = useCase.ResolveException(ctx, statement.ID, payment.ID, ResolveExceptionRequest{Note: "refunded"})
+	require.NoError(t, err)
+	statements.AssertExpectations(t)
+}
+
+func TestNewReconciliationReport(t *testing.T) {
+	resolvedAt := time.Now()
+	statement := &entity.Statement{
+		ID: uuid.New(),
+		Lines: []*entity.StatementLine{
+			{TransactionID: "A", Status: entity.ReconciliationStatusMatched},
+			{TransactionID: "B", Status: entity.ReconciliationStatusResolved, ResolvedAt: &resolvedAt},
+			{TransactionID: "C", Status: entity.ReconciliationStatusUnmatched},
+		},
+		Exceptions: []*entity.StatementException{
+			{Reference: "X", Status: entity.ReconciliationStatusResolved},
+		},
+	}
+
+	report := newReconciliationReport(statement)
+	assert.Equal(t, ReconciliationSummary{Lines: 3, Matched: 1, UnmatchedInBank: 1, Resolved: 2}, report.Summary)
+	assert.Equal(t, "B", report.ResolvedInBank[0].TransactionID)
+	assert.Equal(t, "X", report.ResolvedInSystem[0].Reference)
+	assert.NotNil(t, report.UnmatchedInSystem, "empty lists are serialized as []")
+	assert.Len(t, statement.Lines, 3, "the stored statement is not modified")
+}
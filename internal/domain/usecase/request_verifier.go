@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/signing"
+	"github.com/google/uuid"
+)
+
+// RequestVerifier 驗證以 HMAC 簽署的請求，取代在 header 中傳送 API key
+type RequestVerifier interface {
+	// Verify 檢查簽章、時間戳記與 nonce，回傳簽署請求的商戶
+	Verify(ctx context.Context, req SignedRequest) (*entity.Merchant, error)
+}
+
+// SignedRequest 為驗證簽章所需的請求內容，URI 包含路徑與查詢字串
+type SignedRequest struct {
+	Authorization string
+	Method        string
+	URI           string
+	Body          []byte
+}
+
+type requestVerifier struct {
+	merchantRepo repository.MerchantRepository
+	nonceRepo    repository.NonceRepository
+	tolerance    time.Duration
+	now          func() time.Time
+}
+
+// NewRequestVerifier 的 tolerance 為請求時間戳記與伺服器時間可接受的差距，
+// 為 0 時使用 signing.DefaultTolerance
+func NewRequestVerifier(merchantRepo repository.MerchantRepository, nonceRepo repository.NonceRepository, tolerance time.Duration) RequestVerifier {
+	if tolerance <= 0 {
+		tolerance = signing.DefaultTolerance
+	}
+	return &requestVerifier{
+		merchantRepo: merchantRepo,
+		nonceRepo:    nonceRepo,
+		tolerance:    tolerance,
+		now:          time.Now,
+	}
+}
+
+// Verify 的 key ID 為商戶 ID。先檢查簽章再記錄 nonce，未通過簽章的請求無法佔用 nonce；
+// nonce 保留到時間戳記超出容許範圍為止，之後重送的請求會因時間戳記被拒絕
+func (v *requestVerifier) Verify(ctx context.Context, req SignedRequest) (*entity.Merchant, error) {
+	sig, err := signing.Parse(req.Authorization)
+	if err != nil {
+		return nil, invalidSignature()
+	}
+	merchantID, err := uuid.Parse(sig.KeyID)
+	if err != nil {
+		return nil, invalidSignature()
+	}
+	merchant, err := v.merchantRepo.GetByID(ctx, merchantID)
+	if err != nil {
+		return nil, invalidSignature()
+	}
+	if err := sig.Verify(merchant.SigningSecret, req.Method, req.URI, req.Body); err != nil {
+		return nil, invalidSignature()
+	}
+
+	now := v.now()
+	if skew := now.Sub(sig.Time()); skew > v.tolerance || skew < -v.tolerance {
+		return nil, errors.WithCode(errors.New("request timestamp is outside the allowed window"), "stale_request")
+	}
+	used, err := v.nonceRepo.Use(ctx, merchant.ID.String()+"|"+sig.Nonce, sig.Time().Add(v.tolerance), now)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to record request nonce")
+	}
+	if !used {
+		return nil, errors.WithCode(errors.New("request nonce has already been used"), "replayed_request")
+	}
+	return merchant, nil
+}
+
+func invalidSignature() error {
+	return errors.WithCode(errors.New("invalid request signature"), "invalid_signature")
+}
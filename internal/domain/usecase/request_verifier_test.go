@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/signing"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockNonceRepository struct {
+	mock.Mock
+}
+
+func (m *MockNonceRepository) Use(ctx context.Context, key string, expiresAt, now time.Time) (bool, error) {
+	args := m.Called(ctx, key, expiresAt, now)
+	return args.Bool(0), args.Error(1)
+}
+
+func TestRequestVerifier_Verify(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	merchant := &entity.Merchant{ID: uuid.New(), SigningSecret: "secret", IsActive: true}
+	body := []byte(`{"amount":100}`)
+
+	sign := func(keyID, secret string, signedAt time.Time) SignedRequest {
+		signer := signing.NewSigner(keyID, secret)
+		signer.Now = func() time.Time { return signedAt }
+		req, err := http.NewRequest(http.MethodPost, "http://localhost/api/v1/payments", strings.NewReader(string(body)))
+		require.NoError(t, err)
+		require.NoError(t, signer.Sign(req))
+		return SignedRequest{Authorization: req.Header.Get("Authorization"), Method: http.MethodPost, URI: "/api/v1/payments", Body: body}
+	}
+
+	tests := []struct {
+		name         string
+		req          SignedRequest
+		nonceUsed    bool
+		expectedCode string
+	}{
+		{name: "valid", req: sign(merchant.ID.String(), "secret", now.Add(-time.Minute))},
+		{name: "clock ahead within tolerance", req: sign(merchant.ID.String(), "secret", now.Add(4*time.Minute))},
+		{name: "wrong secret", req: sign(merchant.ID.String(), "other", now), expectedCode: "invalid_signature"},
+		{name: "unknown merchant", req: sign(uuid.NewString(), "secret", now), expectedCode: "invalid_signature"},
+		{name: "key id is not a merchant id", req: sign("merchant_1", "secret", now), expectedCode: "invalid_signature"},
+		{name: "stale timestamp", req: sign(merchant.ID.String(), "secret", now.Add(-6*time.Minute)), expectedCode: "stale_request"},
+		{name: "future timestamp", req: sign(merchant.ID.String(), "secret", now.Add(6*time.Minute)), expectedCode: "stale_request"},
+		{name: "replayed nonce", req: sign(merchant.ID.String(), "secret", now), nonceUsed: true, expectedCode: "replayed_request"},
+		{name: "malformed header", req: SignedRequest{Authorization: "HMAC-SHA256 signature=abc", Method: http.MethodPost, URI: "/api/v1/payments"}, expectedCode: "invalid_signature"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merchantRepo := new(MockMerchantRepository)
+			nonceRepo := new(MockNonceRepository)
+			merchantRepo.On("GetByID", ctx, merchant.ID).Return(merchant, nil)
+			merchantRepo.On("GetByID", ctx, mock.Anything).Return(nil, errors.New("merchant not found"))
+
+			sig, _ := signing.Parse(tt.req.Authorization)
+			if sig != nil {
+				key := merchant.ID.String() + "|" + sig.Nonce
+				nonceRepo.On("Use", ctx, key, sig.Time().Add(signing.DefaultTolerance), now).Return(!tt.nonceUsed, nil)
+			}
+
+			verifier := NewRequestVerifier(merchantRepo, nonceRepo, 0)
+			verifier.(*requestVerifier).now = func() time.Time { return now }
+
+			got, err := verifier.Verify(ctx, tt.req)
+			if tt.expectedCode != "" {
+				require.Error(t, err)
+				assert.Equal(t, tt.expectedCode, errors.Code(err))
+				if tt.expectedCode != "replayed_request" {
+					nonceRepo.AssertNotCalled(t, "Use", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, merchant, got)
+			nonceRepo.AssertExpectations(t)
+		})
+	}
+
+	// 商戶未設定簽章金鑰時不接受簽章
+	merchantRepo := new(MockMerchantRepository)
+	withoutSecret := &entity.Merchant{ID: uuid.New()}
+	merchantRepo.On("GetByID", ctx, withoutSecret.ID).Return(withoutSecret, nil)
+	verifier := NewRequestVerifier(merchantRepo, new(MockNonceRepository), 0)
+	verifier.(*requestVerifier).now = func() time.Time { return now }
+	_, err := verifier.Verify(ctx, sign(withoutSecret.ID.String(), "", now))
+	assert.Equal(t, "invalid_signature", errors.Code(err))
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// RiskEngine 在建立付款前評估風險，付款尚未寫入，頻率規則不會計入本筆付款
+type RiskEngine interface {
+	Assess(ctx context.Context, input RiskInput) (*entity.RiskAssessment, error)
+}
+
+type RiskInput struct {
+	CustomerID uuid.UUID
+	Email      string
+	ClientIP   string
+	Amount     int64
+	Currency   string
+}
+
+// RiskConfig 設定風險規則。每條觸發的規則累加分數（上限 100），
+// 分數達到 BlockScore 時拒絕付款，達到 ReviewScore 時轉為人工審核
+type RiskConfig struct {
+	ReviewScore int
+	BlockScore  int
+	// AmountThresholds 以幣別設定單筆金額門檻，超過 Review 加 ReviewScore 分，超過 Block 直接拒絕
+	AmountThresholds map[string]RiskAmountThreshold
+	Velocity         []RiskVelocityRule
+	// FirstTimeCustomer 為沒有完成過付款的客戶單筆金額超過門檻時加的分數
+	FirstTimeCustomer RiskFirstTimeRule
+	Blocklist         RiskBlocklist
+}
+
+type RiskAmountThreshold struct {
+	Review int64
+	Block  int64
+}
+
+// RiskVelocityRule 在 Window 內同一 Key 的付款數達到 MaxCount 時加 Score 分
+type RiskVelocityRule struct {
+	Key      entity.RiskVelocityKey
+	Window   time.Duration
+	MaxCount int
+	Score    int
+}
+
+type RiskFirstTimeRule struct {
+	Amounts map[string]int64
+	Score   int
+}
+
+// RiskBlocklist 中的客戶、email、email 網域或 IP（可為 CIDR）一律拒絕
+type RiskBlocklist struct {
+	CustomerIDs  []string
+	Emails       []string
+	EmailDomains []string
+	IPs          []string
+}
+
+// 觸發的規則代碼，記錄在 Payment.RiskReasons
+const (
+	riskReasonAmountReview    = "amount_over_review_threshold"
+	riskReasonAmountBlock     = "amount_over_block_threshold"
+	riskReasonFirstTime       = "first_time_customer_large_amount"
+	riskReasonBlockedEmail    = "blocked_email"
+	riskReasonBlockedDomain   = "blocked_email_domain"
+	riskReasonBlockedIP       = "blocked_ip"
+	riskReasonBlockedCustomer = "blocked_customer"
+)
+
+const maxRiskScore = 100
+
+type riskEngine struct {
+	riskRepo         repository.RiskRepository
+	config           RiskConfig
+	blockedCustomers map[uuid.UUID]bool
+	blockedEmails    map[string]bool
+	blockedDomains   map[string]bool
+	blockedNets      []*net.IPNet
+	now              func() time.Time
+}
+
+// NewRiskEngine 檢查規則設定，幣別不分大小寫
+func NewRiskEngine(riskRepo repository.RiskRepository, config RiskConfig) (RiskEngine, error) {
+	if config.ReviewScore <= 0 {
+		config.ReviewScore = 50
+	}
+	if config.BlockScore <= 0 {
+		config.BlockScore = 80
+	}
+	if config.BlockScore < config.ReviewScore {
+		return nil, errors.New("risk block score must not be lower than review score")
+	}
+
+	thresholds := make(map[string]RiskAmountThreshold, len(config.AmountThresholds))
+	for currency, t := range config.AmountThresholds {
+		if t.Review < 0 || t.Block < 0 {
+			return nil, errors.New(fmt.Sprintf("risk amount threshold for %s must not be negative", currency))
+		}
+		thresholds[strings.ToUpper(currency)] = t
+	}
+	config.AmountThresholds = thresholds
+
+	firstTime := make(map[string]int64, len(config.FirstTimeCustomer.Amounts))
+	for currency, amount := range config.FirstTimeCustomer.Amounts {
+		firstTime[strings.ToUpper(currency)] = amount
+	}
+	config.FirstTimeCustomer.Amounts = firstTime
+
+	for _, rule := range config.Velocity {
+		switch rule.Key {
+		case entity.RiskVelocityCustomer, entity.RiskVelocityEmail, entity.RiskVelocityIP:
+		default:
+			return nil, errors.New(fmt.Sprintf("unsupported risk velocity key %q", rule.Key))
+		}
+		if rule.Window <= 0 || rule.MaxCount <= 0 || rule.Score <= 0 {
+			return nil, errors.New(fmt.Sprintf("risk velocity rule for %s requires window, max_count and score", rule.Key))
+		}
+	}
+
+	engine := &riskEngine{
+		riskRepo:         riskRepo,
+		config:           config,
+		blockedCustomers: make(map[uuid.UUID]bool),
+		blockedEmails:    make(map[string]bool),
+		blockedDomains:   make(map[string]bool),
+		now:              time.Now,
+	}
+	for _, id := range config.Blocklist.CustomerIDs {
+		customerID, err := uuid.Parse(id)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid blocked customer id %q", id))
+		}
+		engine.blockedCustomers[customerID] = true
+	}
+	for _, email := range config.Blocklist.Emails {
+		engine.blockedEmails[strings.ToLower(email)] = true
+	}
+	for _, domain := range config.Blocklist.EmailDomains {
+		engine.blockedDomains[strings.ToLower(strings.TrimPrefix(domain, "@"))] = true
+	}
+	for _, ip := range config.Blocklist.IPs {
+		network, err := parseIPNet(ip)
+		if err != nil {
+			return nil, err
+		}
+		engine.blockedNets = append(engine.blockedNets, network)
+	}
+	return engine, nil
+}
+
+func (e *riskEngine) Assess(ctx context.Context, input RiskInput) (*entity.RiskAssessment, error) {
+	assessment := &entity.RiskAssessment{}
+	add := func(reason string, score int) {
+		assessment.Reasons = append(assessment.Reasons, reason)
+		assessment.Score += score
+	}
+
+	e.checkBlocklist(input, add)
+
+	currency := strings.ToUpper(input.Currency)
+	if t, ok := e.config.AmountThresholds[currency]; ok {
+		switch {
+		case t.Block > 0 && input.Amount > t.Block:
+			add(riskReasonAmountBlock, maxRiskScore)
+		case t.Review > 0 && input.Amount > t.Review:
+			add(riskReasonAmountReview, e.config.ReviewScore)
+		}
+	}
+
+	for _, rule := range e.config.Velocity {
+		value := e.velocityValue(rule.Key, input)
+		if value == "" {
+			continue
+		}
+		count, err := e.riskRepo.CountPaymentsSince(ctx, rule.Key, value, e.now().Add(-rule.Window))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to evaluate velocity rule")
+		}
+		if count >= rule.MaxCount {
+			add("velocity_"+string(rule.Key), rule.Score)
+		}
+	}
+
+	if limit, ok := e.config.FirstTimeCustomer.Amounts[currency]; ok && input.Amount > limit && e.config.FirstTimeCustomer.Score > 0 {
+		completed, err := e.riskRepo.CountCompletedPayments(ctx, input.CustomerID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to evaluate first time customer rule")
+		}
+		if completed == 0 {
+			add(riskReasonFirstTime, e.config.FirstTimeCustomer.Score)
+		}
+	}
+
+	if assessment.Score > maxRiskScore {
+		assessment.Score = maxRiskScore
+	}
+	switch {
+	case assessment.Score >= e.config.BlockScore:
+		assessment.Decision = entity.RiskDecisionBlock
+	case assessment.Score >= e.config.ReviewScore:
+		assessment.Decision = entity.RiskDecisionReview
+	default:
+		assessment.Decision = entity.RiskDecisionAllow
+	}
+	return assessment, nil
+}
+
+func (e *riskEngine) checkBlocklist(input RiskInput, add func(reason string, score int)) {
+	if e.blockedCustomers[input.CustomerID] {
+		add(riskReasonBlockedCustomer, maxRiskScore)
+	}
+	email := strings.ToLower(input.Email)
+	if email != "" && e.blockedEmails[email] {
+		add(riskReasonBlockedEmail, maxRiskScore)
+	}
+	if i := strings.LastIndex(email, "@"); i >= 0 && e.blockedDomains[email[i+1:]] {
+		add(riskReasonBlockedDomain, maxRiskScore)
+	}
+	if ip := net.ParseIP(input.ClientIP); ip != nil {
+		for _, network := range e.blockedNets {
+			if network.Contains(ip) {
+				add(riskReasonBlockedIP, maxRiskScore)
+				break
+			}
+		}
+	}
+}
+
+func (e *riskEngine) velocityValue(key entity.RiskVelocityKey, input RiskInput) string {
+	switch key {
+	case entity.RiskVelocityCustomer:
+		return input.CustomerID.String()
+	case entity.RiskVelocityEmail:
+		return input.Email
+	case entity.RiskVelocityIP:
+		return input.ClientIP
+	}
+	return ""
+}
+
+// parseIPNet 接受單一 IP 或 CIDR
+func parseIPNet(value string) (*net.IPNet, error) {
+	if strings.Contains(value, "/") {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("invalid blocked ip %q", value))
+		}
+		return network, nil
+	}
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return nil, errors.New(fmt.Sprintf("invalid blocked ip %q", value))
+	}
+	bits := 128
+	if ip.To4() != nil {
+		ip = ip.To4()
+		bits = 32
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, nil
+}
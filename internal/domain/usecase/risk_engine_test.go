@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockRiskRepository struct {
+	mock.Mock
+}
+
+func (m *MockRiskRepository) CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (int, error) {
+	args := m.Called(ctx, key, value, since)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockRiskRepository) CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (int, error) {
+	args := m.Called(ctx, customerID)
+	return args.Int(0), args.Error(1)
+}
+
+func TestRiskEngine_AmountThresholds(t *testing.T) {
+	ctx := context.Background()
+	engine, err := NewRiskEngine(new(MockRiskRepository), RiskConfig{
+		AmountThresholds: map[string]RiskAmountThreshold{"usd": {Review: 50000, Block: 200000}},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		amount   int64
+		currency string
+		decision entity.RiskDecision
+		reasons  entity.RiskReasons
+	}{
+		{name: "below review threshold", amount: 50000, currency: "USD", decision: entity.RiskDecisionAllow},
+		{name: "over review threshold", amount: 50001, currency: "USD", decision: entity.RiskDecisionReview, reasons: entity.RiskReasons{"amount_over_review_threshold"}},
+		{name: "over block threshold", amount: 200001, currency: "usd", decision: entity.RiskDecisionBlock, reasons: entity.RiskReasons{"amount_over_block_threshold"}},
+		{name: "currency without thresholds", amount: 1000000, currency: "TWD", decision: entity.RiskDecisionAllow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assessment, err := engine.Assess(ctx, RiskInput{CustomerID: uuid.New(), Amount: tt.amount, Currency: tt.currency})
+			require.NoError(t, err)
+			assert.Equal(t, tt.decision, assessment.Decision)
+			assert.Equal(t, tt.reasons, assessment.Reasons)
+		})
+	}
+}
+
+func TestRiskEngine_Velocity(t *testing.T) {
+	ctx := context.Background()
+	customerID := uuid.New()
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	riskRepo := new(MockRiskRepository)
+	engine, err := NewRiskEngine(riskRepo, RiskConfig{
+		Velocity: []RiskVelocityRule{
+			{Key: entity.RiskVelocityCustomer, Window: time.Hour, MaxCount: 3, Score: 30},
+			{Key: entity.RiskVelocityIP, Window: 10 * time.Minute, MaxCount: 5, Score: 60},
+			{Key: entity.RiskVelocityEmail, Window: 24 * time.Hour, MaxCount: 10, Score: 30},
+		},
+	})
+	require.NoError(t, err)
+	engine.(*riskEngine).now = func() time.Time { return now }
+
+	riskRepo.On("CountPaymentsSince", ctx, entity.RiskVelocityCustomer, customerID.String(), now.Add(-time.Hour)).Return(3, nil)
+	riskRepo.On("CountPaymentsSince", ctx, entity.RiskVelocityIP, "192.0.2.1", now.Add(-10*time.Minute)).Return(4, nil)
+
+	// 沒有 email 時略過 email 規則
+	assessment, err := engine.Assess(ctx, RiskInput{CustomerID: customerID, ClientIP: "192.0.2.1", Amount: 1000, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, 30, assessment.Score)
+	assert.Equal(t, entity.RiskDecisionAllow, assessment.Decision)
+	assert.Equal(t, entity.RiskReasons{"velocity_customer"}, assessment.Reasons)
+	riskRepo.AssertExpectations(t)
+}
+
+func TestRiskEngine_FirstTimeCustomer(t *testing.T) {
+	ctx := context.Background()
+	newCustomer := uuid.New()
+	returningCustomer := uuid.New()
+
+	riskRepo := new(MockRiskRepository)
+	riskRepo.On("CountCompletedPayments", ctx, newCustomer).Return(0, nil)
+	riskRepo.On("CountCompletedPayments", ctx, returningCustomer).Return(2, nil)
+
+	engine, err := NewRiskEngine(riskRepo, RiskConfig{
+		FirstTimeCustomer: RiskFirstTimeRule{Amounts: map[string]int64{"USD": 20000}, Score: 50},
+	})
+	require.NoError(t, err)
+
+	assessment, err := engine.Assess(ctx, RiskInput{CustomerID: newCustomer, Amount: 25000, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RiskDecisionReview, assessment.Decision)
+	assert.Equal(t, entity.RiskReasons{"first_time_customer_large_amount"}, assessment.Reasons)
+
+	assessment, err = engine.Assess(ctx, RiskInput{CustomerID: returningCustomer, Amount: 25000, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RiskDecisionAllow, assessment.Decision)
+
+	// 金額未超過門檻時不查詢付款記錄
+	assessment, err = engine.Assess(ctx, RiskInput{CustomerID: uuid.New(), Amount: 20000, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RiskDecisionAllow, assessment.Decision)
+	riskRepo.AssertExpectations(t)
+}
+
+func TestRiskEngine_Blocklist(t *testing.T) {
+	ctx := context.Background()
+	blockedCustomer := uuid.New()
+	engine, err := NewRiskEngine(new(MockRiskRepository), RiskConfig{
+		Blocklist: RiskBlocklist{
+			CustomerIDs:  []string{blockedCustomer.String()},
+			Emails:       []string{"Fraud@Example.com"},
+			EmailDomains: []string{"@mailinator.test"},
+			IPs:          []string{"203.0.113.0/24", "2001:db8::1"},
+		},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		input  RiskInput
+		reason string
+	}{
+		{name: "customer", input: RiskInput{CustomerID: blockedCustomer}, reason: "blocked_customer"},
+		{name: "email", input: RiskInput{CustomerID: uuid.New(), Email: "fraud@example.com"}, reason: "blocked_email"},
+		{name: "email domain", input: RiskInput{CustomerID: uuid.New(), Email: "someone@MAILINATOR.test"}, reason: "blocked_email_domain"},
+		{name: "ip in range", input: RiskInput{CustomerID: uuid.New(), ClientIP: "203.0.113.77"}, reason: "blocked_ip"},
+		{name: "ipv6", input: RiskInput{CustomerID: uuid.New(), ClientIP: "2001:db8::1"}, reason: "blocked_ip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.input.Amount = 1000
+			tt.input.Currency = "USD"
+			assessment, err := engine.Assess(ctx, tt.input)
+			require.NoError(t, err)
+			assert.Equal(t, 100, assessment.Score)
+			assert.Equal(t, entity.RiskDecisionBlock, assessment.Decision)
+			assert.Equal(t, entity.RiskReasons{tt.reason}, assessment.Reasons)
+		})
+	}
+
+	assessment, err := engine.Assess(ctx, RiskInput{CustomerID: uuid.New(), Email: "ok@example.com", ClientIP: "198.51.100.1", Amount: 1000, Currency: "USD"})
+	require.NoError(t, err)
+	assert.Equal(t, entity.RiskDecisionAllow, assessment.Decision)
+	assert.Empty(t, assessment.Reasons)
+}
+
+func TestNewRiskEngine_InvalidConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config RiskConfig
+	}{
+		{name: "block below review", config: RiskConfig{ReviewScore: 60, BlockScore: 40}},
+		{name: "unknown velocity key", config: RiskConfig{Velocity: []RiskVelocityRule{{Key: "device", Window: time.Hour, MaxCount: 1, Score: 10}}}},
+		{name: "velocity without window", config: RiskConfig{Velocity: []RiskVelocityRule{{Key: entity.RiskVelocityIP, MaxCount: 1, Score: 10}}}},
+		{name: "invalid blocked ip", config: RiskConfig{Blocklist: RiskBlocklist{IPs: []string{"not-an-ip"}}}},
+		{name: "invalid blocked customer", config: RiskConfig{Blocklist: RiskBlocklist{CustomerIDs: []string{"cus_1"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRiskEngine(new(MockRiskRepository), tt.config)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,679 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type SubscriptionUseCase interface {
+	CreatePlan(ctx context.Context, req CreatePlanRequest) (*entity.Plan, error)
+	GetPlan(ctx context.Context, merchantID, id uuid.UUID) (*entity.Plan, error)
+	ListPlans(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error)
+	CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*entity.Subscription, error)
+	GetSubscription(ctx context.Context, merchantID, id uuid.UUID) (*entity.Subscription, error)
+	ListSubscriptions(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error)
+	ChangePlan(ctx context.Context, req ChangePlanRequest) (*entity.Subscription, error)
+	// CancelSubscription 在 atPeriodEnd 為 true 時於本期結束後取消，否則立即取消
+	CancelSubscription(ctx context.Context, merchantID, id uuid.UUID, atPeriodEnd bool) (*entity.Subscription, error)
+	// ResumeSubscription 撤銷尚未生效的期末取消
+	ResumeSubscription(ctx context.Context, merchantID, id uuid.UUID) (*entity.Subscription, error)
+	// BillDue 續期到期的訂閱並重試催收中的扣款，回傳處理筆數，由排程器定期呼叫
+	BillDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+type CreatePlanRequest struct {
+	MerchantID    uuid.UUID           `json:"-"`
+	Name          string              `json:"name"`
+	Amount        int64               `json:"amount"`
+	Currency      string              `json:"currency"`
+	Interval      entity.PlanInterval `json:"interval"`
+	IntervalCount int                 `json:"interval_count"`
+	TrialDays     int                 `json:"trial_days"`
+}
+
+type CreateSubscriptionRequest struct {
+	MerchantID      uuid.UUID  `json:"-"`
+	CustomerID      uuid.UUID  `json:"customer_id"`
+	PlanID          uuid.UUID  `json:"plan_id"`
+	PaymentMethodID *uuid.UUID `json:"payment_method_id"`
+	Quantity        int        `json:"quantity"`
+	// TrialDays 為空時使用方案的試用天數
+	TrialDays *int `json:"trial_days"`
+	// BillingAnchor 決定每期的起算點（例如每月 1 日），為空時從試用結束或建立當下起算；
+	// 第一期若不足一個完整週期會按比例收費
+	BillingAnchor *time.Time `json:"billing_anchor"`
+}
+
+type ChangePlanRequest struct {
+	MerchantID uuid.UUID `json:"-"`
+	ID         uuid.UUID `json:"-"`
+	PlanID     uuid.UUID `json:"plan_id"`
+	// Quantity 為 0 時沿用目前數量
+	Quantity int `json:"quantity"`
+	// Prorate 為 false 時不計算本期剩餘天數的差額，新價格從下期開始生效
+	Prorate *bool `json:"prorate"`
+}
+
+// DunningConfig 設定續期扣款失敗後的重試排程
+type DunningConfig struct {
+	// RetrySchedule 的第 N 個值為第 N 次失敗後到下次重試的間隔
+	RetrySchedule []time.Duration
+	// CancelOnExhausted 為 true 時重試用盡即取消訂閱，否則標記為 unpaid 並停止扣款
+	CancelOnExhausted bool
+}
+
+// billingClaimTimeout 為續期前鎖定訂閱的時間，處理中斷時逾時後由下一輪重新取得
+const billingClaimTimeout = 10 * time.Minute
+
+// paymentRecheckInterval 為本期付款仍在審核或處理中時，下次檢查的間隔
+const paymentRecheckInterval = time.Hour
+
+type subscriptionUseCase struct {
+	planRepo         repository.PlanRepository
+	subscriptionRepo repository.SubscriptionRepository
+	customerRepo     repository.CustomerRepository
+	methodRepo       repository.PaymentMethodRepository
+	paymentRepo      repository.PaymentRepository
+	payments         PaymentUseCase
+	dunning          DunningConfig
+	now              func() time.Time
+}
+
+func NewSubscriptionUseCase(
+	planRepo repository.PlanRepository,
+	subscriptionRepo repository.SubscriptionRepository,
+	customerRepo repository.CustomerRepository,
+	methodRepo repository.PaymentMethodRepository,
+	paymentRepo repository.PaymentRepository,
+	payments PaymentUseCase,
+	dunning DunningConfig,
+) SubscriptionUseCase {
+	return &subscriptionUseCase{
+		planRepo:         planRepo,
+		subscriptionRepo: subscriptionRepo,
+		customerRepo:     customerRepo,
+		methodRepo:       methodRepo,
+		paymentRepo:      paymentRepo,
+		payments:         payments,
+		dunning:          dunning,
+		now:              time.Now,
+	}
+}
+
+func (uc *subscriptionUseCase) CreatePlan(ctx context.Context, req CreatePlanRequest) (*entity.Plan, error) {
+	if req.IntervalCount == 0 {
+		req.IntervalCount = 1
+	}
+	switch {
+	case strings.TrimSpace(req.Name) == "":
+		return nil, invalidSubscription("plan name is required")
+	case req.Amount <= 0:
+		return nil, invalidSubscription("plan amount must be positive")
+	case len(req.Currency) != 3:
+		return nil, invalidSubscription("currency must be a 3-letter code")
+	case req.IntervalCount < 0:
+		return nil, invalidSubscription("interval_count must be positive")
+	case req.TrialDays < 0:
+		return nil, invalidSubscription("trial_days cannot be negative")
+	}
+	switch req.Interval {
+	case entity.PlanIntervalDay, entity.PlanIntervalWeek, entity.PlanIntervalMonth, entity.PlanIntervalYear:
+	default:
+		return nil, invalidSubscription("interval must be day, week, month or year")
+	}
+
+	now := uc.now()
+	plan := &entity.Plan{
+		ID:            uuid.New(),
+		MerchantID:    req.MerchantID,
+		Name:          req.Name,
+		Amount:        req.Amount,
+		Currency:      strings.ToUpper(req.Currency),
+		Interval:      req.Interval,
+		IntervalCount: req.IntervalCount,
+		TrialDays:     req.TrialDays,
+		IsActive:      true,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := uc.planRepo.Create(ctx, plan); err != nil {
+		return nil, errors.Wrap(err, "failed to create plan")
+	}
+	return plan, nil
+}
+
+func (uc *subscriptionUseCase) GetPlan(ctx context.Context, merchantID, id uuid.UUID) (*entity.Plan, error) {
+	plan, err := uc.planRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get plan"), "not_found")
+	}
+	if plan.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("plan not found"), "not_found")
+	}
+	return plan, nil
+}
+
+func (uc *subscriptionUseCase) ListPlans(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error) {
+	plans, err := uc.planRepo.ListByMerchantID(ctx, merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list plans")
+	}
+	return plans, nil
+}
+
+func (uc *subscriptionUseCase) CreateSubscription(ctx context.Context, req CreateSubscriptionRequest) (*entity.Subscription, error) {
+	if _, err := uc.customerRepo.GetByID(ctx, req.CustomerID); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get customer"), "not_found")
+	}
+	plan, err := uc.GetPlan(ctx, req.MerchantID, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !plan.IsActive {
+		return nil, invalidSubscription("plan is not active")
+	}
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if req.Quantity < 0 {
+		return nil, invalidSubscription("quantity must be positive")
+	}
+	trialDays := plan.TrialDays
+	if req.TrialDays != nil {
+		trialDays = *req.TrialDays
+	}
+	if trialDays < 0 {
+		return nil, invalidSubscription("trial_days cannot be negative")
+	}
+
+	// 週期以 UTC 推算，避免伺服器時區影響月底與每日邊界
+	now := uc.now().UTC()
+	sub := &entity.Subscription{
+		ID:                 uuid.New(),
+		MerchantID:         req.MerchantID,
+		CustomerID:         req.CustomerID,
+		PlanID:             plan.ID,
+		PaymentMethodID:    req.PaymentMethodID,
+		Quantity:           req.Quantity,
+		Status:             entity.SubscriptionStatusActive,
+		BillingAnchor:      now,
+		CurrentPeriodStart: now,
+		CurrentPeriodEnd:   now,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+	}
+	if trialDays > 0 {
+		trialEnd := now.AddDate(0, 0, trialDays)
+		sub.Status = entity.SubscriptionStatusTrialing
+		sub.TrialEnd = &trialEnd
+		sub.CurrentPeriodEnd = trialEnd
+		sub.BillingAnchor = trialEnd
+	}
+	if req.BillingAnchor != nil {
+		sub.BillingAnchor = req.BillingAnchor.UTC()
+	}
+
+	// 指定的付款方式必須屬於同一客戶；沒有試用期時立即扣款，必須有可用的付款方式
+	if req.PaymentMethodID != nil || trialDays == 0 {
+		if _, err := uc.paymentMethodFor(ctx, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.subscriptionRepo.Create(ctx, sub); err != nil {
+		return nil, errors.Wrap(err, "failed to create subscription")
+	}
+	logger.FromContext(ctx).Info("subscription created",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("plan_id", plan.ID.String()),
+		zap.String("status", string(sub.Status)),
+	)
+
+	// 第一期扣款失敗時訂閱仍會建立並進入催收，呼叫端可由狀態判斷
+	if sub.Status == entity.SubscriptionStatusActive {
+		if err := uc.renew(ctx, sub, plan, now); err != nil {
+			return nil, err
+		}
+	}
+	return sub, nil
+}
+
+func (uc *subscriptionUseCase) GetSubscription(ctx context.Context, merchantID, id uuid.UUID) (*entity.Subscription, error) {
+	sub, err := uc.subscriptionRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get subscription"), "not_found")
+	}
+	if sub.MerchantID != merchantID {
+		return nil, errors.WithCode(errors.New("subscription not found"), "not_found")
+	}
+	return sub, nil
+}
+
+func (uc *subscriptionUseCase) ListSubscriptions(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error) {
+	subs, err := uc.subscriptionRepo.GetByMerchantID(ctx, merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list subscriptions")
+	}
+	return subs, nil
+}
+
+func (uc *subscriptionUseCase) ChangePlan(ctx context.Context, req ChangePlanRequest) (*entity.Subscription, error) {
+	sub, err := uc.GetSubscription(ctx, req.MerchantID, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !billable(sub.Status) {
+		return nil, invalidSubscription(fmt.Sprintf("subscription is %s, cannot change plan", sub.Status))
+	}
+	newPlan, err := uc.GetPlan(ctx, req.MerchantID, req.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if !newPlan.IsActive {
+		return nil, invalidSubscription("plan is not active")
+	}
+	oldPlan, err := uc.planRepo.GetByID(ctx, sub.PlanID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get current plan")
+	}
+	// 週期不同時本期的起訖無法沿用，只允許同幣別、同週期的方案互換
+	if newPlan.Currency != oldPlan.Currency {
+		return nil, invalidSubscription("plan change requires the same currency")
+	}
+	if newPlan.Interval != oldPlan.Interval || newPlan.IntervalCount != oldPlan.IntervalCount {
+		return nil, invalidSubscription("plan change requires the same billing interval")
+	}
+	quantity := req.Quantity
+	if quantity == 0 {
+		quantity = sub.Quantity
+	}
+	if quantity < 0 {
+		return nil, invalidSubscription("quantity must be positive")
+	}
+
+	now := uc.now()
+	// 本期已付舊方案的費用，剩餘天數按比例退舊收新，差額於下期扣款時結算
+	prorate := req.Prorate == nil || *req.Prorate
+	if prorate && sub.Status != entity.SubscriptionStatusTrialing && now.Before(sub.CurrentPeriodEnd) {
+		period := sub.CurrentPeriodEnd.Sub(sub.CurrentPeriodStart)
+		remaining := sub.CurrentPeriodEnd.Sub(now)
+		credit := scaleAmount(oldPlan.Amount*int64(sub.Quantity), remaining, period)
+		charge := scaleAmount(newPlan.Amount*int64(quantity), remaining, period)
+		sub.ProrationBalance += charge - credit
+	}
+	sub.PlanID = newPlan.ID
+	sub.Quantity = quantity
+	sub.UpdatedAt = now
+
+	if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+		return nil, errors.Wrap(err, "failed to update subscription")
+	}
+	logger.FromContext(ctx).Info("subscription plan changed",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.String("from_plan_id", oldPlan.ID.String()),
+		zap.String("to_plan_id", newPlan.ID.String()),
+		zap.Int64("proration_balance", sub.ProrationBalance),
+	)
+	return sub, nil
+}
+
+func (uc *subscriptionUseCase) CancelSubscription(ctx context.Context, merchantID, id uuid.UUID, atPeriodEnd bool) (*entity.Subscription, error) {
+	sub, err := uc.GetSubscription(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if sub.Status == entity.SubscriptionStatusCanceled {
+		return nil, invalidSubscription("subscription is already canceled")
+	}
+
+	now := uc.now()
+	if atPeriodEnd && billable(sub.Status) {
+		sub.CancelAtPeriodEnd = true
+	} else {
+		sub.Status = entity.SubscriptionStatusCanceled
+		sub.CanceledAt = &now
+		sub.NextRetryAt = nil
+	}
+	sub.UpdatedAt = now
+
+	if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+		return nil, errors.Wrap(err, "failed to update subscription")
+	}
+	logger.FromContext(ctx).Info("subscription canceled",
+		zap.String("subscription_id", sub.ID.String()),
+		zap.Bool("at_period_end", sub.CancelAtPeriodEnd),
+	)
+	return sub, nil
+}
+
+func (uc *subscriptionUseCase) ResumeSubscription(ctx context.Context, merchantID, id uuid.UUID) (*entity.Subscription, error) {
+	sub, err := uc.GetSubscription(ctx, merchantID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.CancelAtPeriodEnd || !billable(sub.Status) {
+		return nil, invalidSubscription("subscription is not scheduled for cancellation")
+	}
+
+	sub.CancelAtPeriodEnd = false
+	sub.UpdatedAt = uc.now()
+	if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+		return nil, errors.Wrap(err, "failed to update subscription")
+	}
+	return sub, nil
+}
+
+func (uc *subscriptionUseCase) BillDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	subs, err := uc.subscriptionRepo.ListDue(ctx, now, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list due subscriptions")
+	}
+
+	log := logger.FromContext(ctx)
+	processed := 0
+	for _, sub := range subs {
+		// 先鎖定再扣款，多個實例同時續期時只有取得鎖定的實例會處理
+		if err := uc.subscriptionRepo.Claim(ctx, sub.ID, now, now.Add(billingClaimTimeout)); err != nil {
+			log.Info("subscription claimed by another run, skipping", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+			continue
+		}
+		plan, err := uc.planRepo.GetByID(ctx, sub.PlanID)
+		if err != nil {
+			log.Error("failed to get subscription plan", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+			continue
+		}
+		if sub.NextRetryAt != nil {
+			err = uc.charge(ctx, sub, plan, now)
+		} else {
+			err = uc.renew(ctx, sub, plan, now)
+		}
+		if err != nil {
+			log.Error("failed to bill subscription", zap.String("subscription_id", sub.ID.String()), zap.Error(err))
+			continue
+		}
+		processed++
+	}
+	return processed, nil
+}
+
+// renew 在本期結束時處理期末取消，或進入下一期並扣款
+func (uc *subscriptionUseCase) renew(ctx context.Context, sub *entity.Subscription, plan *entity.Plan, now time.Time) error {
+	if sub.CancelAtPeriodEnd {
+		canceledAt := sub.CurrentPeriodEnd
+		sub.Status = entity.SubscriptionStatusCanceled
+		sub.CanceledAt = &canceledAt
+		sub.UpdatedAt = now
+		if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+			return errors.Wrap(err, "failed to update subscription")
+		}
+		logger.FromContext(ctx).Info("subscription canceled",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Bool("at_period_end", true),
+		)
+		return nil
+	}
+
+	start := sub.CurrentPeriodEnd
+	sub.CurrentPeriodStart = start
+	sub.CurrentPeriodEnd = periodEnd(plan, sub.BillingAnchor, start)
+	sub.Status = entity.SubscriptionStatusActive
+	sub.FailedAttempts = 0
+	return uc.charge(ctx, sub, plan, now)
+}
+
+// charge 收取本期費用（含方案變更差額），失敗時依催收排程安排重試
+func (uc *subscriptionUseCase) charge(ctx context.Context, sub *entity.Subscription, plan *entity.Plan, now time.Time) error {
+	log := logger.FromContext(ctx)
+	amount := periodAmount(plan, sub) + sub.ProrationBalance
+	sub.UpdatedAt = now
+
+	if amount <= 0 {
+		// 抵扣金額超過本期費用時不扣款，剩餘的抵扣留到下期
+		sub.ProrationBalance = amount
+		sub.Status = entity.SubscriptionStatusActive
+		sub.FailedAttempts = 0
+		sub.NextRetryAt = nil
+		if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+			return errors.Wrap(err, "failed to update subscription")
+		}
+		return nil
+	}
+
+	payment, err := uc.attemptPayment(ctx, sub, plan, amount)
+	switch {
+	case err == nil && payment.Status != entity.PaymentStatusCompleted:
+		// 付款在人工審核或處理中，不計入失敗次數，稍後再檢查同一筆付款
+		retryAt := now.Add(paymentRecheckInterval)
+		sub.LatestPaymentID = &payment.ID
+		sub.NextRetryAt = &retryAt
+		log.Info("subscription payment in progress",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.String("payment_id", payment.ID.String()),
+			zap.String("payment_status", string(payment.Status)),
+		)
+	case err != nil:
+		uc.recordFailure(sub, now)
+		log.Warn("subscription payment failed",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.Int("attempt", sub.FailedAttempts),
+			zap.String("status", string(sub.Status)),
+			zap.Error(err),
+		)
+	default:
+		sub.LatestPaymentID = &payment.ID
+		sub.ProrationBalance = 0
+		sub.Status = entity.SubscriptionStatusActive
+		sub.FailedAttempts = 0
+		sub.NextRetryAt = nil
+		log.Info("subscription renewed",
+			zap.String("subscription_id", sub.ID.String()),
+			zap.String("payment_id", payment.ID.String()),
+			zap.Int64("amount", amount),
+		)
+	}
+
+	if err := uc.subscriptionRepo.Update(ctx, sub); err != nil {
+		return errors.Wrap(err, "failed to update subscription")
+	}
+	return nil
+}
+
+// attemptPayment 收取本期費用。每次嘗試的 reference 由訂閱、期間與嘗試序號組成（第一次不帶序號），
+// 依序找出第一筆未失敗或取消的付款沿用；先前的嘗試已完成扣款（例如寫回訂閱前中斷）時直接視為已付款，
+// 前面的嘗試都已失敗或取消（風險阻擋、審核拒絕、網關失敗）時以下一個序號建立新的付款
+func (uc *subscriptionUseCase) attemptPayment(ctx context.Context, sub *entity.Subscription, plan *entity.Plan, amount int64) (*entity.Payment, error) {
+	var payment *entity.Payment
+	var reference string
+	for attempt := 0; ; attempt++ {
+		reference = periodReference(sub, attempt)
+		existing, err := uc.paymentRepo.GetByReference(ctx, reference)
+		if err != nil {
+			break
+		}
+		if existing.Status != entity.PaymentStatusFailed && existing.Status != entity.PaymentStatusCancelled {
+			payment = existing
+			break
+		}
+	}
+
+	if payment == nil {
+		method, err := uc.paymentMethodFor(ctx, sub)
+		if err != nil {
+			return nil, err
+		}
+		payment, err = uc.payments.CreatePayment(ctx, CreatePaymentRequest{
+			MerchantID:      sub.MerchantID,
+			CustomerID:      sub.CustomerID,
+			Amount:          amount,
+			Currency:        plan.Currency,
+			Description:     fmt.Sprintf("%s %s - %s", plan.Name, sub.CurrentPeriodStart.Format("2006-01-02"), sub.CurrentPeriodEnd.Format("2006-01-02")),
+			Reference:       reference,
+			PaymentMethodID: &method.ID,
+		})
+		if err != nil {
+			// reference 重複代表同時執行的續期已建立這次嘗試的付款；被風險規則阻擋的付款也會以 failed 寫入
+			existing, getErr := uc.paymentRepo.GetByReference(ctx, reference)
+			if getErr != nil {
+				return nil, err
+			}
+			payment = existing
+		}
+	}
+
+	switch payment.Status {
+	case entity.PaymentStatusCompleted, entity.PaymentStatusReview, entity.PaymentStatusProcessing:
+		// 審核核准後轉為 pending，由下次檢查請款；拒絕則轉為 failed，下次重試建立新的付款
+		return payment, nil
+	case entity.PaymentStatusPending:
+	default:
+		return nil, errors.New(fmt.Sprintf("subscription payment %s is %s", payment.ID, payment.Status))
+	}
+	// 請款失敗時付款可能維持 pending（下次重試沿用）或轉為 failed（下次重試建立新的付款）
+	if err := uc.payments.ProcessPayment(ctx, payment.ID); err != nil {
+		return nil, err
+	}
+	payment.Status = entity.PaymentStatusCompleted
+	return payment, nil
+}
+
+// periodReference 回傳本期第 attempt 次嘗試的付款 reference，第一次為 sub_<訂閱 ID>_<期間起始>
+func periodReference(sub *entity.Subscription, attempt int) string {
+	reference := fmt.Sprintf("sub_%s_%d", sub.ID, sub.CurrentPeriodStart.Unix())
+	if attempt > 0 {
+		reference += fmt.Sprintf("_%d", attempt)
+	}
+	return reference
+}
+
+func (uc *subscriptionUseCase) recordFailure(sub *entity.Subscription, now time.Time) {
+	sub.FailedAttempts++
+	if sub.FailedAttempts <= len(uc.dunning.RetrySchedule) {
+		retryAt := now.Add(uc.dunning.RetrySchedule[sub.FailedAttempts-1])
+		sub.Status = entity.SubscriptionStatusPastDue
+		sub.NextRetryAt = &retryAt
+		return
+	}
+
+	sub.NextRetryAt = nil
+	if uc.dunning.CancelOnExhausted {
+		sub.Status = entity.SubscriptionStatusCanceled
+		sub.CanceledAt = &now
+	} else {
+		sub.Status = entity.SubscriptionStatusUnpaid
+	}
+}
+
+// paymentMethodFor 回傳訂閱指定的付款方式，未指定時使用客戶在此商戶的預設付款方式
+func (uc *subscriptionUseCase) paymentMethodFor(ctx context.Context, sub *entity.Subscription) (*entity.PaymentMethodRecord, error) {
+	if sub.PaymentMethodID != nil {
+		method, err := uc.methodRepo.GetByID(ctx, *sub.PaymentMethodID)
+		if err != nil {
+			return nil, errors.WithCode(errors.Wrap(err, "failed to get payment method"), "invalid_payment_method")
+		}
+		if method.MerchantID != sub.MerchantID || method.CustomerID != sub.CustomerID {
+			return nil, errors.WithCode(errors.New("payment method not found"), "invalid_payment_method")
+		}
+		return method, checkSubscriptionMethod(method)
+	}
+
+	methods, err := uc.methodRepo.ListByCustomerID(ctx, sub.CustomerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment methods")
+	}
+	for _, method := range methods {
+		if method.IsDefault && method.MerchantID == sub.MerchantID {
+			return method, checkSubscriptionMethod(method)
+		}
+	}
+	return nil, errors.WithCode(errors.New("customer has no default payment method"), "invalid_payment_method")
+}
+
+// checkSubscriptionMethod 確認付款方式可自動扣款。銀行轉帳要等客戶匯款、數位錢包要客戶確認，
+// 續期無法在排程中完成，只接受信用卡
+func checkSubscriptionMethod(method *entity.PaymentMethodRecord) error {
+	if method.Type != entity.PaymentMethodCreditCard {
+		return errors.WithCode(errors.New(fmt.Sprintf("payment method %s cannot be charged automatically for subscriptions", method.Type)), "invalid_payment_method")
+	}
+	return nil
+}
+
+func invalidSubscription(message string) error {
+	return errors.WithCode(errors.New(message), "invalid_subscription")
+}
+
+func billable(status entity.SubscriptionStatus) bool {
+	switch status {
+	case entity.SubscriptionStatusTrialing, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue:
+		return true
+	}
+	return false
+}
+
+// periodAmount 回傳本期應收金額；本期不足一個完整週期（例如調整 anchor 後的第一期）時按比例計算
+func periodAmount(plan *entity.Plan, sub *entity.Subscription) int64 {
+	full := plan.Amount * int64(sub.Quantity)
+	fullStart := addInterval(sub.CurrentPeriodEnd, plan, -1)
+	if !sub.CurrentPeriodStart.After(fullStart) {
+		return full
+	}
+	return scaleAmount(full, sub.CurrentPeriodEnd.Sub(sub.CurrentPeriodStart), sub.CurrentPeriodEnd.Sub(fullStart))
+}
+
+// scaleAmount 回傳 amount × part / whole，以秒計算並四捨五入到分
+func scaleAmount(amount int64, part, whole time.Duration) int64 {
+	wholeSeconds := int64(whole / time.Second)
+	if wholeSeconds <= 0 {
+		return amount
+	}
+	partSeconds := int64(part / time.Second)
+	return (amount*partSeconds*2 + wholeSeconds) / (wholeSeconds * 2)
+}
+
+// periodEnd 回傳以 anchor 推算的週期邊界中第一個晚於 after 的時間
+func periodEnd(plan *entity.Plan, anchor, after time.Time) time.Time {
+	cycles := 0
+	if anchor.After(after) {
+		for addInterval(anchor, plan, cycles-1).After(after) {
+			cycles--
+		}
+		return addInterval(anchor, plan, cycles)
+	}
+	for !addInterval(anchor, plan, cycles).After(after) {
+		cycles++
+	}
+	return addInterval(anchor, plan, cycles)
+}
+
+// addInterval 將 t 往後（cycles 為負時往前）推算 cycles 個方案週期
+func addInterval(t time.Time, plan *entity.Plan, cycles int) time.Time {
+	n := cycles * plan.IntervalCount
+	switch plan.Interval {
+	case entity.PlanIntervalDay:
+		return t.AddDate(0, 0, n)
+	case entity.PlanIntervalWeek:
+		return t.AddDate(0, 0, 7*n)
+	case entity.PlanIntervalYear:
+		return addMonths(t, 12*n)
+	default:
+		return addMonths(t, n)
+	}
+}
+
+// addMonths 與 time.AddDate 不同，月底日期不會溢位到下個月，例如 1/31 加一個月為 2/28
+func addMonths(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+}
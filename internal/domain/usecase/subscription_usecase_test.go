@@ -0,0 +1,638 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockPlanRepository struct {
+	mock.Mock
+}
+
+func (m *MockPlanRepository) Create(ctx context.Context, plan *entity.Plan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+func (m *MockPlanRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Plan, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Plan), args.Error(1)
+}
+
+func (m *MockPlanRepository) ListByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error) {
+	args := m.Called(ctx, merchantID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Plan), args.Error(1)
+}
+
+func (m *MockPlanRepository) Update(ctx context.Context, plan *entity.Plan) error {
+	args := m.Called(ctx, plan)
+	return args.Error(0)
+}
+
+type MockSubscriptionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSubscriptionRepository) Create(ctx context.Context, sub *entity.Subscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Update(ctx context.Context, sub *entity.Subscription) error {
+	args := m.Called(ctx, sub)
+	return args.Error(0)
+}
+
+func (m *MockSubscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*entity.Subscription, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Subscription), args.Error(1)
+}
+
+func (m *MockSubscriptionRepository) Claim(ctx context.Context, id uuid.UUID, now, until time.Time) error {
+	args := m.Called(ctx, id, now, until)
+	return args.Error(0)
+}
+
+type MockPaymentUseCase struct {
+	mock.Mock
+}
+
+func (m *MockPaymentUseCase) CreatePayment(ctx context.Context, req CreatePaymentRequest) (*entity.Payment, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetMerchantPayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error) {
+	args := m.Called(ctx, merchantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ProcessPayment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) SubmitPayment(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) ExecutePayment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) FailPayment(ctx context.Context, id uuid.UUID, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) SettlePayment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) CompleteAction(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) FailAction(ctx context.Context, id uuid.UUID, reason string) error {
+	args := m.Called(ctx, id, reason)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) ApplyGatewayResult(ctx context.Context, id uuid.UUID, status entity.PaymentStatus, reason string) error {
+	args := m.Called(ctx, id, status, reason)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) CancelPayment(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockPaymentUseCase) ApprovePayment(ctx context.Context, merchantID, id uuid.UUID) (*entity.Payment, error) {
+	args := m.Called(ctx, merchantID, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) RejectPayment(ctx context.Context, merchantID, id uuid.UUID, reason string) (*entity.Payment, error) {
+	args := m.Called(ctx, merchantID, id, reason)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Payment), args.Error(1)
+}
+
+func (m *MockPaymentUseCase) GetMerchantPayments(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	args := m.Called(ctx, merchantID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Payment), args.Error(1)
+}
+
+func TestPeriodEnd(t *testing.T) {
+	monthly := &entity.Plan{Interval: entity.PlanIntervalMonth, IntervalCount: 1}
+	quarterly := &entity.Plan{Interval: entity.PlanIntervalMonth, IntervalCount: 3}
+	weekly := &entity.Plan{Interval: entity.PlanIntervalWeek, IntervalCount: 1}
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name   string
+		plan   *entity.Plan
+		anchor time.Time
+		after  time.Time
+		want   time.Time
+	}{
+		{"month end clamps to february", monthly, date(2024, 1, 31), date(2024, 1, 31), date(2024, 2, 29)},
+		{"month end restores after february", monthly, date(2024, 1, 31), date(2024, 2, 29), date(2024, 3, 31)},
+		{"quarterly", quarterly, date(2024, 1, 15), date(2024, 2, 1), date(2024, 4, 15)},
+		{"weekly", weekly, date(2024, 1, 1), date(2024, 1, 10), date(2024, 1, 15)},
+		{"future anchor gives short first period", monthly, date(2024, 3, 1), date(2024, 1, 20), date(2024, 2, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, periodEnd(tt.plan, tt.anchor, tt.after))
+		})
+	}
+}
+
+func TestSubscriptionUseCase_CreateSubscription(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	newPlan := func() *entity.Plan {
+		return &entity.Plan{
+			ID: uuid.New(), MerchantID: merchantID, Name: "Pro", Amount: 3000, Currency: "USD",
+			Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true,
+		}
+	}
+	newMethod := func() *entity.PaymentMethodRecord {
+		return &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: merchantID, CustomerID: customerID, Type: entity.PaymentMethodCreditCard, IsDefault: true}
+	}
+	setup := func(plan *entity.Plan, method *entity.PaymentMethodRecord) (*MockSubscriptionRepository, *MockPaymentUseCase, SubscriptionUseCase) {
+		planRepo := new(MockPlanRepository)
+		subRepo := new(MockSubscriptionRepository)
+		customerRepo := new(MockCustomerRepository)
+		methodRepo := new(MockPaymentMethodRepository)
+		paymentRepo := new(MockPaymentRepository)
+		payments := new(MockPaymentUseCase)
+		planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+		methodRepo.On("GetByID", ctx, method.ID).Return(method, nil)
+		methodRepo.On("ListByCustomerID", ctx, customerID).Return([]*entity.PaymentMethodRecord{method}, nil)
+		paymentRepo.On("GetByReference", ctx, mock.Anything).Return(nil, errors.New("payment not found"))
+		return subRepo, payments, NewSubscriptionUseCase(planRepo, subRepo, customerRepo, methodRepo, paymentRepo, payments, DunningConfig{})
+	}
+
+	t.Run("charges the first period immediately", func(t *testing.T) {
+		plan, method := newPlan(), newMethod()
+		subRepo, payments, useCase := setup(plan, method)
+		payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+		subRepo.On("Create", ctx, mock.AnythingOfType("*entity.Subscription")).Return(nil)
+		subRepo.On("Update", ctx, mock.AnythingOfType("*entity.Subscription")).Return(nil)
+		payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+			return req.Amount == 6000 && req.Currency == "USD" && *req.PaymentMethodID == method.ID
+		})).Return(payment, nil)
+		payments.On("ProcessPayment", ctx, payment.ID).Return(nil)
+
+		sub, err := useCase.CreateSubscription(ctx, CreateSubscriptionRequest{
+			MerchantID: merchantID, CustomerID: customerID, PlanID: plan.ID, Quantity: 2,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+		assert.Equal(t, &payment.ID, sub.LatestPaymentID)
+		assert.Equal(t, addMonths(sub.CurrentPeriodStart, 1), sub.CurrentPeriodEnd)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("trial defers billing", func(t *testing.T) {
+		plan := newPlan()
+		plan.TrialDays = 14
+		subRepo, payments, useCase := setup(plan, newMethod())
+		subRepo.On("Create", ctx, mock.AnythingOfType("*entity.Subscription")).Return(nil)
+
+		sub, err := useCase.CreateSubscription(ctx, CreateSubscriptionRequest{
+			MerchantID: merchantID, CustomerID: customerID, PlanID: plan.ID,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusTrialing, sub.Status)
+		require.NotNil(t, sub.TrialEnd)
+		assert.Equal(t, *sub.TrialEnd, sub.CurrentPeriodEnd)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("requires a payment method without trial", func(t *testing.T) {
+		plan, method := newPlan(), newMethod()
+		method.IsDefault = false
+		subRepo, _, useCase := setup(plan, method)
+
+		_, err := useCase.CreateSubscription(ctx, CreateSubscriptionRequest{
+			MerchantID: merchantID, CustomerID: customerID, PlanID: plan.ID,
+		})
+		assert.Error(t, err)
+		assert.Equal(t, "invalid_payment_method", errors.Code(err))
+		subRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	for _, methodType := range []entity.PaymentMethod{entity.PaymentMethodBankTransfer, entity.PaymentMethodDigitalWallet} {
+		t.Run("rejects "+string(methodType), func(t *testing.T) {
+			plan, method := newPlan(), newMethod()
+			method.Type = methodType
+			subRepo, _, useCase := setup(plan, method)
+			trialDays := 14
+
+			_, err := useCase.CreateSubscription(ctx, CreateSubscriptionRequest{
+				MerchantID: merchantID, CustomerID: customerID, PlanID: plan.ID,
+				PaymentMethodID: &method.ID, TrialDays: &trialDays,
+			})
+			assert.Error(t, err)
+			assert.Equal(t, "invalid_payment_method", errors.Code(err))
+			subRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestSubscriptionUseCase_BillDueDunning(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	method := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), Type: entity.PaymentMethodCreditCard, IsDefault: true}
+	sub := &entity.Subscription{
+		ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: method.CustomerID, PlanID: plan.ID, Quantity: 1,
+		Status: entity.SubscriptionStatusActive, BillingAnchor: now.AddDate(0, -1, 0), CurrentPeriodStart: now.AddDate(0, -1, 0), CurrentPeriodEnd: now,
+	}
+
+	planRepo := new(MockPlanRepository)
+	subRepo := new(MockSubscriptionRepository)
+	methodRepo := new(MockPaymentMethodRepository)
+	paymentRepo := new(MockPaymentRepository)
+	payments := new(MockPaymentUseCase)
+	planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+	methodRepo.On("ListByCustomerID", ctx, method.CustomerID).Return([]*entity.PaymentMethodRecord{method}, nil)
+	subRepo.On("Update", ctx, sub).Return(nil)
+	subRepo.On("ListDue", ctx, mock.Anything, 10).Return([]*entity.Subscription{sub}, nil)
+	subRepo.On("Claim", ctx, sub.ID, mock.Anything, mock.Anything).Return(nil)
+
+	// 第一次建立本期付款，之後的重試沿用同一筆 pending 付款
+	payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+	reference := "sub_" + sub.ID.String() + "_1714521600"
+	paymentRepo.On("GetByReference", ctx, reference).Return(nil, errors.New("payment not found")).Once()
+	paymentRepo.On("GetByReference", ctx, reference).Return(payment, nil)
+	payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+		return req.Reference == reference
+	})).Return(payment, nil).Once()
+	payments.On("ProcessPayment", ctx, payment.ID).Return(errors.New("card declined"))
+	useCase := NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), methodRepo, paymentRepo, payments, DunningConfig{
+		RetrySchedule: []time.Duration{24 * time.Hour, 72 * time.Hour},
+	})
+
+	// 第一次失敗：進入下一期並排定 1 天後重試
+	n, err := useCase.BillDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, entity.SubscriptionStatusPastDue, sub.Status)
+	assert.Equal(t, now, sub.CurrentPeriodStart)
+	assert.Equal(t, 1, sub.FailedAttempts)
+	require.NotNil(t, sub.NextRetryAt)
+	assert.Equal(t, now.Add(24*time.Hour), *sub.NextRetryAt)
+
+	// 第二次失敗：3 天後重試，期間不變
+	_, err = useCase.BillDue(ctx, *sub.NextRetryAt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, sub.FailedAttempts)
+	assert.Equal(t, now, sub.CurrentPeriodStart)
+	assert.Equal(t, now.Add(24*time.Hour+72*time.Hour), *sub.NextRetryAt)
+
+	// 重試用盡
+	_, err = useCase.BillDue(ctx, *sub.NextRetryAt, 10)
+	require.NoError(t, err)
+	assert.Equal(t, entity.SubscriptionStatusUnpaid, sub.Status)
+	assert.Nil(t, sub.NextRetryAt)
+	payments.AssertNumberOfCalls(t, "ProcessPayment", 3)
+	payments.AssertNotCalled(t, "CancelPayment", mock.Anything, mock.Anything)
+}
+
+func TestSubscriptionUseCase_BillDueRecoversAfterRetry(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 3, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	method := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), Type: entity.PaymentMethodCreditCard, IsDefault: true}
+	start := now.AddDate(0, 0, -2)
+	sub := &entity.Subscription{
+		ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: method.CustomerID, PlanID: plan.ID, Quantity: 1,
+		Status: entity.SubscriptionStatusPastDue, BillingAnchor: start, CurrentPeriodStart: start, CurrentPeriodEnd: start.AddDate(0, 1, 0),
+		FailedAttempts: 1, NextRetryAt: &now,
+	}
+
+	planRepo := new(MockPlanRepository)
+	subRepo := new(MockSubscriptionRepository)
+	methodRepo := new(MockPaymentMethodRepository)
+	paymentRepo := new(MockPaymentRepository)
+	payments := new(MockPaymentUseCase)
+	payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+	planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+	methodRepo.On("ListByCustomerID", ctx, method.CustomerID).Return([]*entity.PaymentMethodRecord{method}, nil)
+	paymentRepo.On("GetByReference", ctx, mock.Anything).Return(payment, nil)
+	payments.On("ProcessPayment", ctx, payment.ID).Return(nil)
+	subRepo.On("Update", ctx, sub).Return(nil)
+	subRepo.On("ListDue", ctx, now, 10).Return([]*entity.Subscription{sub}, nil)
+	subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(nil)
+	useCase := NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), methodRepo, paymentRepo, payments, DunningConfig{
+		RetrySchedule: []time.Duration{24 * time.Hour},
+	})
+
+	_, err := useCase.BillDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+	assert.Equal(t, 0, sub.FailedAttempts)
+	assert.Nil(t, sub.NextRetryAt)
+	assert.Equal(t, start, sub.CurrentPeriodStart, "retry charges the same period")
+	assert.Equal(t, &payment.ID, sub.LatestPaymentID)
+	payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+}
+
+func TestSubscriptionUseCase_BillDueChargesPeriodOnce(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	method := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), Type: entity.PaymentMethodCreditCard, IsDefault: true}
+	newSubscription := func(start, end time.Time) *entity.Subscription {
+		return &entity.Subscription{
+			ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: method.CustomerID, PlanID: plan.ID, Quantity: 1,
+			Status: entity.SubscriptionStatusActive, BillingAnchor: start, CurrentPeriodStart: start, CurrentPeriodEnd: end,
+		}
+	}
+	setup := func(sub *entity.Subscription, dunning DunningConfig) (*MockSubscriptionRepository, *MockPaymentRepository, *MockPaymentUseCase, SubscriptionUseCase) {
+		planRepo := new(MockPlanRepository)
+		subRepo := new(MockSubscriptionRepository)
+		methodRepo := new(MockPaymentMethodRepository)
+		paymentRepo := new(MockPaymentRepository)
+		payments := new(MockPaymentUseCase)
+		planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+		methodRepo.On("ListByCustomerID", ctx, method.CustomerID).Return([]*entity.PaymentMethodRecord{method}, nil)
+		subRepo.On("ListDue", ctx, now, 10).Return([]*entity.Subscription{sub}, nil)
+		subRepo.On("Update", ctx, sub).Return(nil)
+		return subRepo, paymentRepo, payments, NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), methodRepo, paymentRepo, payments, dunning)
+	}
+
+	t.Run("skips subscription claimed by another run", func(t *testing.T) {
+		sub := newSubscription(now.AddDate(0, -1, 0), now)
+		subRepo, _, payments, useCase := setup(sub, DunningConfig{})
+		subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(errors.New("already claimed"))
+
+		n, err := useCase.BillDue(ctx, now, 10)
+		require.NoError(t, err)
+		assert.Equal(t, 0, n)
+		subRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("completed period payment counts as paid", func(t *testing.T) {
+		sub := newSubscription(now.AddDate(0, -2, 0), now.AddDate(0, -1, 0))
+		subRepo, paymentRepo, payments, useCase := setup(sub, DunningConfig{})
+		paid := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusCompleted}
+		paymentRepo.On("GetByReference", ctx, mock.Anything).Return(paid, nil)
+		subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(nil)
+
+		_, err := useCase.BillDue(ctx, now, 10)
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+		assert.Equal(t, 0, sub.FailedAttempts)
+		assert.Equal(t, &paid.ID, sub.LatestPaymentID)
+		payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
+	})
+
+	t.Run("reference collision resolves to the existing payment", func(t *testing.T) {
+		sub := newSubscription(now.AddDate(0, -2, 0), now.AddDate(0, -1, 0))
+		subRepo, paymentRepo, payments, useCase := setup(sub, DunningConfig{RetrySchedule: []time.Duration{time.Hour}})
+		paid := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusCompleted}
+		paymentRepo.On("GetByReference", ctx, mock.Anything).Return(nil, errors.New("payment not found")).Once()
+		paymentRepo.On("GetByReference", ctx, mock.Anything).Return(paid, nil)
+		payments.On("CreatePayment", ctx, mock.AnythingOfType("usecase.CreatePaymentRequest")).Return(nil, errors.New("failed to create payment"))
+		subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(nil)
+
+		_, err := useCase.BillDue(ctx, now, 10)
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusActive, sub.Status, "collision is not a failure")
+		assert.Nil(t, sub.NextRetryAt)
+		assert.Equal(t, &paid.ID, sub.LatestPaymentID)
+	})
+
+	t.Run("payment in review is rechecked without counting a failure", func(t *testing.T) {
+		sub := newSubscription(now.AddDate(0, -1, 0), now)
+		subRepo, paymentRepo, payments, useCase := setup(sub, DunningConfig{RetrySchedule: []time.Duration{24 * time.Hour}})
+		review := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusReview}
+		paymentRepo.On("GetByReference", ctx, mock.Anything).Return(nil, errors.New("payment not found"))
+		payments.On("CreatePayment", ctx, mock.AnythingOfType("usecase.CreatePaymentRequest")).Return(review, nil)
+		subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(nil)
+
+		_, err := useCase.BillDue(ctx, now, 10)
+		require.NoError(t, err)
+		assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+		assert.Equal(t, 0, sub.FailedAttempts)
+		require.NotNil(t, sub.NextRetryAt)
+		assert.Equal(t, now.Add(paymentRecheckInterval), *sub.NextRetryAt)
+		assert.Equal(t, &review.ID, sub.LatestPaymentID)
+		payments.AssertNotCalled(t, "ProcessPayment", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSubscriptionUseCase_BillDueRetriesAfterFailedPayment(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	method := &entity.PaymentMethodRecord{ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), Type: entity.PaymentMethodCreditCard, IsDefault: true}
+
+	tests := []struct {
+		name string
+		// first 設定第一次嘗試的 CreatePayment，回傳寫入的付款
+		first func(payments *MockPaymentUseCase, reference string) *entity.Payment
+	}{
+		{"blocked by risk screening", func(payments *MockPaymentUseCase, reference string) *entity.Payment {
+			// 被阻擋的付款仍以 failed 寫入
+			blocked := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusFailed}
+			payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+				return req.Reference == reference
+			})).Return(nil, errors.WithCode(errors.New("payment was declined"), "payment_declined")).Once()
+			return blocked
+		}},
+		{"rejected in review", func(payments *MockPaymentUseCase, reference string) *entity.Payment {
+			review := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusReview}
+			payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+				return req.Reference == reference
+			})).Return(review, nil).Once()
+			return review
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub := &entity.Subscription{
+				ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: method.CustomerID, PlanID: plan.ID, Quantity: 1,
+				Status: entity.SubscriptionStatusActive, BillingAnchor: now.AddDate(0, -1, 0), CurrentPeriodStart: now.AddDate(0, -1, 0), CurrentPeriodEnd: now,
+			}
+			first := "sub_" + sub.ID.String() + "_1714521600"
+			retry := first + "_1"
+			planRepo := new(MockPlanRepository)
+			subRepo := new(MockSubscriptionRepository)
+			methodRepo := new(MockPaymentMethodRepository)
+			paymentRepo := new(MockPaymentRepository)
+			payments := new(MockPaymentUseCase)
+			planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+			methodRepo.On("ListByCustomerID", ctx, method.CustomerID).Return([]*entity.PaymentMethodRecord{method}, nil)
+			subRepo.On("ListDue", ctx, mock.Anything, 10).Return([]*entity.Subscription{sub}, nil)
+			subRepo.On("Claim", ctx, sub.ID, mock.Anything, mock.Anything).Return(nil)
+			subRepo.On("Update", ctx, sub).Return(nil)
+			useCase := NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), methodRepo, paymentRepo, payments, DunningConfig{
+				RetrySchedule: []time.Duration{24 * time.Hour, 72 * time.Hour},
+			})
+
+			firstPayment := tt.first(payments, first)
+			paymentRepo.On("GetByReference", ctx, first).Return(nil, errors.New("payment not found")).Once()
+			paymentRepo.On("GetByReference", ctx, first).Return(firstPayment, nil).Once()
+			_, err := useCase.BillDue(ctx, now, 10)
+			require.NoError(t, err)
+			require.NotNil(t, sub.NextRetryAt)
+
+			// 第一次嘗試最終失敗；客戶更新卡片後的重試以新的 reference 建立付款並成功
+			firstPayment.Status = entity.PaymentStatusFailed
+			paymentRepo.On("GetByReference", ctx, first).Return(firstPayment, nil)
+			paymentRepo.On("GetByReference", ctx, retry).Return(nil, errors.New("payment not found"))
+			payment := &entity.Payment{ID: uuid.New(), Status: entity.PaymentStatusPending}
+			payments.On("CreatePayment", ctx, mock.MatchedBy(func(req CreatePaymentRequest) bool {
+				return req.Reference == retry
+			})).Return(payment, nil).Once()
+			payments.On("ProcessPayment", ctx, payment.ID).Return(nil)
+
+			_, err = useCase.BillDue(ctx, *sub.NextRetryAt, 10)
+			require.NoError(t, err)
+			assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+			assert.Equal(t, 0, sub.FailedAttempts)
+			assert.Nil(t, sub.NextRetryAt)
+			assert.Equal(t, &payment.ID, sub.LatestPaymentID)
+			payments.AssertNumberOfCalls(t, "CreatePayment", 2)
+		})
+	}
+}
+
+func TestSubscriptionUseCase_CancelAtPeriodEnd(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	sub := &entity.Subscription{
+		ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), PlanID: plan.ID, Quantity: 1,
+		Status: entity.SubscriptionStatusActive, BillingAnchor: now.AddDate(0, -1, 0), CurrentPeriodStart: now.AddDate(0, -1, 0), CurrentPeriodEnd: now,
+	}
+	planRepo := new(MockPlanRepository)
+	subRepo := new(MockSubscriptionRepository)
+	payments := new(MockPaymentUseCase)
+	planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+	subRepo.On("GetByID", ctx, sub.ID).Return(sub, nil)
+	subRepo.On("Update", ctx, sub).Return(nil)
+	subRepo.On("ListDue", ctx, now, 10).Return([]*entity.Subscription{sub}, nil)
+	subRepo.On("Claim", ctx, sub.ID, now, now.Add(billingClaimTimeout)).Return(nil)
+	useCase := NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), new(MockPaymentMethodRepository), new(MockPaymentRepository), payments, DunningConfig{})
+
+	_, err := useCase.CancelSubscription(ctx, sub.MerchantID, sub.ID, true)
+	require.NoError(t, err)
+	assert.Equal(t, entity.SubscriptionStatusActive, sub.Status)
+	assert.True(t, sub.CancelAtPeriodEnd)
+
+	_, err = useCase.BillDue(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, entity.SubscriptionStatusCanceled, sub.Status)
+	assert.Equal(t, &now, sub.CanceledAt)
+	payments.AssertNotCalled(t, "CreatePayment", mock.Anything, mock.Anything)
+}
+
+func TestSubscriptionUseCase_ChangePlanProrates(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	plan := &entity.Plan{ID: uuid.New(), MerchantID: uuid.New(), Amount: 3000, Currency: "USD", Interval: entity.PlanIntervalMonth, IntervalCount: 1, IsActive: true}
+	sub := &entity.Subscription{
+		ID: uuid.New(), MerchantID: plan.MerchantID, CustomerID: uuid.New(), PlanID: plan.ID, Quantity: 1,
+		Status: entity.SubscriptionStatusActive, BillingAnchor: start, CurrentPeriodStart: start, CurrentPeriodEnd: start.AddDate(0, 0, 30),
+	}
+	upgrade := *plan
+	upgrade.ID = uuid.New()
+	upgrade.Amount = 9000
+	yearly := *plan
+	yearly.ID = uuid.New()
+	yearly.Interval = entity.PlanIntervalYear
+
+	planRepo := new(MockPlanRepository)
+	subRepo := new(MockSubscriptionRepository)
+	planRepo.On("GetByID", ctx, plan.ID).Return(plan, nil)
+	planRepo.On("GetByID", ctx, upgrade.ID).Return(&upgrade, nil)
+	planRepo.On("GetByID", ctx, yearly.ID).Return(&yearly, nil)
+	subRepo.On("GetByID", ctx, sub.ID).Return(sub, nil)
+	subRepo.On("Update", ctx, sub).Return(nil)
+	useCase := NewSubscriptionUseCase(planRepo, subRepo, new(MockCustomerRepository), new(MockPaymentMethodRepository), new(MockPaymentRepository), new(MockPaymentUseCase), DunningConfig{}).(*subscriptionUseCase)
+	useCase.now = func() time.Time { return start.AddDate(0, 0, 15) }
+
+	_, err := useCase.ChangePlan(ctx, ChangePlanRequest{MerchantID: sub.MerchantID, ID: sub.ID, PlanID: upgrade.ID})
+	require.NoError(t, err)
+	assert.Equal(t, upgrade.ID, sub.PlanID)
+	// 剩下半期：退 1500、收 4500
+	assert.Equal(t, int64(3000), sub.ProrationBalance)
+
+	_, err = useCase.ChangePlan(ctx, ChangePlanRequest{MerchantID: sub.MerchantID, ID: sub.ID, PlanID: yearly.ID})
+	assert.Error(t, err)
+	assert.Equal(t, "invalid_subscription", errors.Code(err))
+}
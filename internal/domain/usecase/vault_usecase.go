@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/card"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+type VaultUseCase interface {
+	TokenizeCard(ctx context.Context, req TokenizeCardRequest) (*entity.Card, error)
+	GetCard(ctx context.Context, merchantID uuid.UUID, token string) (*entity.Card, error)
+	// RevealPAN 解密卡號，只供呼叫支付網關時使用，不可經由 API 回傳
+	RevealPAN(ctx context.Context, token string) (string, error)
+}
+
+// TokenizeCardRequest 只在建立 token 時出現一次；CVV 僅用於驗證格式，不會保存
+type TokenizeCardRequest struct {
+	MerchantID uuid.UUID `json:"-"`
+	Number     string    `json:"number" redact:"pan"`
+	ExpMonth   int       `json:"exp_month"`
+	ExpYear    int       `json:"exp_year"`
+	CVV        string    `json:"cvv" redact:"secret"`
+}
+
+// CardCipher 由基礎設施層提供信封加密
+type CardCipher interface {
+	Seal(plaintext, associatedData []byte) (ciphertext, wrappedKey []byte, keyID string, err error)
+	Open(ciphertext, wrappedKey []byte, keyID string, associatedData []byte) ([]byte, error)
+	Fingerprint(pan string) string
+}
+
+type vaultUseCase struct {
+	cardRepo repository.CardRepository
+	cipher   CardCipher
+}
+
+func NewVaultUseCase(cardRepo repository.CardRepository, cipher CardCipher) VaultUseCase {
+	return &vaultUseCase{
+		cardRepo: cardRepo,
+		cipher:   cipher,
+	}
+}
+
+func (uc *vaultUseCase) TokenizeCard(ctx context.Context, req TokenizeCardRequest) (*entity.Card, error) {
+	number := card.Normalize(req.Number)
+	if !card.IsDigits(number) || len(number) < 12 || len(number) > 19 || !card.LuhnValid(number) {
+		return nil, errors.WithCode(errors.New("invalid card number"), "invalid_card")
+	}
+	brand := card.DetectBrand(number)
+	if brand == card.BrandUnknown {
+		return nil, errors.WithCode(errors.New("unsupported card brand"), "invalid_card")
+	}
+	if card.Expired(req.ExpMonth, req.ExpYear, time.Now()) {
+		return nil, errors.WithCode(errors.New("card is expired"), "invalid_card")
+	}
+	if !card.IsDigits(req.CVV) || len(req.CVV) != card.CVVLength(brand) {
+		return nil, errors.WithCode(errors.New("invalid card security code"), "invalid_card")
+	}
+
+	token, err := newCardToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate card token")
+	}
+	ciphertext, wrappedKey, keyID, err := uc.cipher.Seal([]byte(number), []byte(token))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt card")
+	}
+
+	c := &entity.Card{
+		Token:        token,
+		MerchantID:   req.MerchantID,
+		Brand:        string(brand),
+		Last4:        card.Last4(number),
+		ExpMonth:     req.ExpMonth,
+		ExpYear:      req.ExpYear,
+		Fingerprint:  uc.cipher.Fingerprint(number),
+		EncryptedPAN: ciphertext,
+		EncryptedKey: wrappedKey,
+		KeyID:        keyID,
+		CreatedAt:    time.Now(),
+	}
+	if err := uc.cardRepo.Create(ctx, c); err != nil {
+		return nil, errors.Wrap(err, "failed to store card")
+	}
+
+	logger.FromContext(ctx).Info("card tokenized",
+		zap.String("brand", c.Brand),
+		zap.String("last4", c.Last4),
+	)
+	return c, nil
+}
+
+func (uc *vaultUseCase) GetCard(ctx context.Context, merchantID uuid.UUID, token string) (*entity.Card, error) {
+	c, err := uc.cardRepo.GetByToken(ctx, token)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get card")
+	}
+	// 其他商戶的 token 一律視為不存在
+	if c.MerchantID != merchantID {
+		return nil, errors.New("card not found")
+	}
+	return c, nil
+}
+
+func (uc *vaultUseCase) RevealPAN(ctx context.Context, token string) (string, error) {
+	c, err := uc.cardRepo.GetByToken(ctx, token)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get card")
+	}
+	pan, err := uc.cipher.Open(c.EncryptedPAN, c.EncryptedKey, c.KeyID, []byte(c.Token))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt card")
+	}
+	return string(pan), nil
+}
+
+func newCardToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "tok_" + hex.EncodeToString(b), nil
+}
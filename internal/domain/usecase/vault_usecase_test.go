@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// reverseCipher 以反轉位元組模擬加密，讓測試可以檢查密文不含卡號
+type reverseCipher struct{}
+
+func (reverseCipher) Seal(plaintext, associatedData []byte) ([]byte, []byte, string, error) {
+	out := make([]byte, len(plaintext))
+	for i, b := range plaintext {
+		out[len(plaintext)-1-i] = b
+	}
+	return out, associatedData, "test-key", nil
+}
+
+func (c reverseCipher) Open(ciphertext, wrappedKey []byte, keyID string, associatedData []byte) ([]byte, error) {
+	if string(wrappedKey) != string(associatedData) {
+		return nil, errors.New("associated data mismatch")
+	}
+	out, _, _, _ := c.Seal(ciphertext, nil)
+	return out, nil
+}
+
+func (reverseCipher) Fingerprint(pan string) string {
+	return "fp_" + pan[len(pan)-4:]
+}
+
+func TestVaultUseCase_TokenizeCard(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+
+	tests := []struct {
+		name          string
+		request       TokenizeCardRequest
+		expectedError string
+	}{
+		{
+			name:    "valid visa",
+			request: TokenizeCardRequest{Number: "4111 1111 1111 1111", ExpMonth: 12, ExpYear: 2099, CVV: "123"},
+		},
+		{
+			name:    "valid amex needs four digit cvv",
+			request: TokenizeCardRequest{Number: "378282246310005", ExpMonth: 12, ExpYear: 2099, CVV: "1234"},
+		},
+		{
+			name:          "luhn failure",
+			request:       TokenizeCardRequest{Number: "4111111111111112", ExpMonth: 12, ExpYear: 2099, CVV: "123"},
+			expectedError: "invalid card number",
+		},
+		{
+			name:          "unknown brand",
+			request:       TokenizeCardRequest{Number: "9999999999999995", ExpMonth: 12, ExpYear: 2099, CVV: "123"},
+			expectedError: "unsupported card brand",
+		},
+		{
+			name:          "expired",
+			request:       TokenizeCardRequest{Number: "4111111111111111", ExpMonth: 1, ExpYear: 2020, CVV: "123"},
+			expectedError: "card is expired",
+		},
+		{
+			name:          "bad cvv",
+			request:       TokenizeCardRequest{Number: "378282246310005", ExpMonth: 12, ExpYear: 2099, CVV: "123"},
+			expectedError: "invalid card security code",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cardRepo := new(MockCardRepository)
+			if tt.expectedError == "" {
+				cardRepo.On("Create", ctx, mock.AnythingOfType("*entity.Card")).Return(nil)
+			}
+
+			tt.request.MerchantID = merchantID
+			card, err := NewVaultUseCase(cardRepo, reverseCipher{}).TokenizeCard(ctx, tt.request)
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				assert.Equal(t, "invalid_card", errors.Code(err))
+				assert.Nil(t, card)
+			} else {
+				require.NoError(t, err)
+				assert.True(t, strings.HasPrefix(card.Token, "tok_"))
+				assert.Equal(t, merchantID, card.MerchantID)
+				assert.NotContains(t, string(card.EncryptedPAN), strings.ReplaceAll(tt.request.Number, " ", ""))
+				assert.Len(t, card.Last4, 4)
+			}
+			cardRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestVaultUseCase_CVVIsNeverStored(t *testing.T) {
+	ctx := context.Background()
+	cardRepo := new(MockCardRepository)
+	var stored *entity.Card
+	cardRepo.On("Create", ctx, mock.AnythingOfType("*entity.Card")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*entity.Card)
+	}).Return(nil)
+
+	_, err := NewVaultUseCase(cardRepo, reverseCipher{}).TokenizeCard(ctx, TokenizeCardRequest{
+		MerchantID: uuid.New(),
+		Number:     "5555555555554444",
+		ExpMonth:   12,
+		ExpYear:    2099,
+		CVV:        "987",
+	})
+	require.NoError(t, err)
+
+	// token 為隨機 hex，可能碰巧包含相同數字，檢查前先去除；reverseCipher 以 token 作為 wrapped key
+	for _, field := range []string{string(stored.EncryptedPAN), string(stored.EncryptedKey), stored.Fingerprint} {
+		assert.NotContains(t, strings.ReplaceAll(field, stored.Token, ""), "987")
+	}
+}
+
+func TestVaultUseCase_RevealPAN(t *testing.T) {
+	ctx := context.Background()
+	cardRepo := new(MockCardRepository)
+	var stored *entity.Card
+	cardRepo.On("Create", ctx, mock.AnythingOfType("*entity.Card")).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(*entity.Card)
+	}).Return(nil)
+
+	vault := NewVaultUseCase(cardRepo, reverseCipher{})
+	card, err := vault.TokenizeCard(ctx, TokenizeCardRequest{MerchantID: uuid.New(), Number: "4111111111111111", ExpMonth: 12, ExpYear: 2099, CVV: "123"})
+	require.NoError(t, err)
+
+	cardRepo.On("GetByToken", ctx, card.Token).Return(stored, nil)
+	pan, err := vault.RevealPAN(ctx, card.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", pan)
+
+	_, err = vault.GetCard(ctx, uuid.New(), card.Token)
+	assert.Error(t, err, "cards must not be visible to other merchants")
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// walletCallbackTolerance 為回呼時間戳記與伺服器時間可接受的差距，避免舊的回呼被重送
+const walletCallbackTolerance = 5 * time.Minute
+
+// WalletUseCase 處理數位錢包付款的客戶確認：錢包以簽章回呼通知確認或拒絕，
+// 逾時未確認的付款由排程標記為 failed
+type WalletUseCase interface {
+	// HandleCallback 驗證簽章後依結果完成或拒絕付款，重送相同結果時回傳目前的付款
+	HandleCallback(ctx context.Context, req WalletCallbackRequest) (*entity.Payment, error)
+	// ExpireDue 將逾時仍未確認的付款標記為 failed，回傳處理筆數
+	ExpireDue(ctx context.Context, now time.Time, limit int) (int, error)
+}
+
+// WalletCallbackRequest 為錢包的回呼內容，Signature 見 WalletCallbackSignature
+type WalletCallbackRequest struct {
+	PaymentID uuid.UUID `json:"payment_id"`
+	Result    string    `json:"result"` // confirmed 或 rejected
+	Timestamp int64     `json:"timestamp"`
+	Signature string    `json:"signature"`
+}
+
+type walletUseCase struct {
+	actionRepo repository.WalletActionRepository
+	payments   PaymentUseCase
+	secret     string
+	now        func() time.Time
+}
+
+func NewWalletUseCase(actionRepo repository.WalletActionRepository, payments PaymentUseCase, config WalletConfig) WalletUseCase {
+	return &walletUseCase{
+		actionRepo: actionRepo,
+		payments:   payments,
+		secret:     config.CallbackSecret,
+		now:        time.Now,
+	}
+}
+
+func (uc *walletUseCase) HandleCallback(ctx context.Context, req WalletCallbackRequest) (*entity.Payment, error) {
+	now := uc.now()
+	if err := uc.verify(req, now); err != nil {
+		return nil, err
+	}
+
+	var status entity.WalletActionStatus
+	switch entity.WalletActionStatus(req.Result) {
+	case entity.WalletActionStatusConfirmed, entity.WalletActionStatusRejected:
+		status = entity.WalletActionStatus(req.Result)
+	default:
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("unsupported result %q", req.Result)), "invalid_wallet_callback")
+	}
+
+	action, err := uc.actionRepo.GetByPaymentID(ctx, req.PaymentID)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get wallet action"), "not_found")
+	}
+	if action.Status == status {
+		return uc.payments.GetPayment(ctx, req.PaymentID)
+	}
+	if action.Status != entity.WalletActionStatusPending {
+		return nil, errors.WithCode(errors.New(fmt.Sprintf("wallet action is %s", action.Status)), "invalid_payment_status")
+	}
+
+	// 先以條件更新記錄結果，逾時排程或重複回呼同時發生時只有一個會生效
+	if err := uc.actionRepo.Resolve(ctx, req.PaymentID, status, now); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to resolve wallet action"), "invalid_payment_status")
+	}
+	if status == entity.WalletActionStatusConfirmed {
+		err = uc.payments.CompleteAction(ctx, req.PaymentID)
+	} else {
+		err = uc.payments.FailAction(ctx, req.PaymentID, "rejected in wallet")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return uc.payments.GetPayment(ctx, req.PaymentID)
+}
+
+// verify 檢查簽章與時間戳記，未設定金鑰時拒絕所有回呼
+func (uc *walletUseCase) verify(req WalletCallbackRequest, now time.Time) error {
+	invalid := errors.WithCode(errors.New("invalid wallet callback signature"), "invalid_signature")
+	if uc.secret == "" {
+		return invalid
+	}
+	expected := WalletCallbackSignature(uc.secret, req.PaymentID.String(), req.Result, strconv.FormatInt(req.Timestamp, 10))
+	if !hmac.Equal([]byte(expected), []byte(strings.ToLower(req.Signature))) {
+		return invalid
+	}
+	if skew := now.Sub(time.Unix(req.Timestamp, 0)); skew > walletCallbackTolerance || skew < -walletCallbackTolerance {
+		return errors.WithCode(errors.New("wallet callback timestamp is outside the allowed window"), "invalid_signature")
+	}
+	return nil
+}
+
+func (uc *walletUseCase) ExpireDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	actions, err := uc.actionRepo.ListExpired(ctx, now, limit)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list expired wallet actions")
+	}
+
+	for _, action := range actions {
+		log := logger.FromContext(ctx).With(zap.String("payment_id", action.PaymentID.String()))
+		// 其他實例已處理，或錢包在同時回呼
+		if err := uc.actionRepo.Expire(ctx, action.PaymentID, now); err != nil {
+			log.Info("wallet action no longer expirable, skipping", zap.Error(err))
+			continue
+		}
+		if err := uc.payments.FailAction(ctx, action.PaymentID, "wallet confirmation timed out"); err != nil {
+			log.Warn("failed to fail expired wallet payment", zap.Error(err))
+		}
+	}
+	return len(actions), nil
+}
+
+// WalletCallbackSignature 計算錢包回呼的簽章：以 wallet.callback_secret 為金鑰，對
+// "payment_id.result.timestamp" 做 HMAC-SHA256，以十六進位表示
+func WalletCallbackSignature(secret, paymentID, result, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{paymentID, result, timestamp}, ".")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
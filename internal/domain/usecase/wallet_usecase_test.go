@@ -0,0 +1,299 @@
+package usecase
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWalletActionRepository struct {
+	mock.Mock
+}
+
+func (m *MockWalletActionRepository) Create(ctx context.Context, action *entity.WalletAction) error {
+	args := m.Called(ctx, action)
+	return args.Error(0)
+}
+
+func (m *MockWalletActionRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.WalletAction, error) {
+	args := m.Called(ctx, paymentID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WalletAction), args.Error(1)
+}
+
+func (m *MockWalletActionRepository) Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) error {
+	args := m.Called(ctx, paymentID, status, now)
+	return args.Error(0)
+}
+
+func (m *MockWalletActionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.WalletAction, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.WalletAction), args.Error(1)
+}
+
+func (m *MockWalletActionRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	args := m.Called(ctx, paymentID, now)
+	return args.Error(0)
+}
+
+const testWalletSecret = "wallet-secret"
+
+// newTestWalletUseCase 建立以 secret 驗證回呼、時間固定為 now 的 use case
+func newTestWalletUseCase(actions *MockWalletActionRepository, payments *MockPaymentUseCase, secret string, now time.Time) WalletUseCase {
+	uc := NewWalletUseCase(actions, payments, WalletConfig{CallbackSecret: secret}).(*walletUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+func TestWalletUseCase_HandleCallback(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	// callback 產生以測試金鑰簽章的回呼
+	callback := func(paymentID uuid.UUID, result string) WalletCallbackRequest {
+		req := WalletCallbackRequest{PaymentID: paymentID, Result: result, Timestamp: now.Unix()}
+		req.Signature = WalletCallbackSignature(testWalletSecret, paymentID.String(), result, strconv.FormatInt(req.Timestamp, 10))
+		return req
+	}
+	setup := func(status entity.WalletActionStatus) (*entity.WalletAction, *MockWalletActionRepository, *MockPaymentUseCase) {
+		action := &entity.WalletAction{
+			PaymentID: uuid.New(), Type: entity.NextActionRedirectToURL,
+			Status: status, ExpiresAt: now.Add(15 * time.Minute),
+		}
+		actions := new(MockWalletActionRepository)
+		actions.On("GetByPaymentID", mock.Anything, action.PaymentID).Return(action, nil).Maybe()
+		return action, actions, new(MockPaymentUseCase)
+	}
+
+	t.Run("confirmed completes payment", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+		actions.On("Resolve", ctx, action.PaymentID, entity.WalletActionStatusConfirmed, now).Return(nil)
+		payments.On("CompleteAction", ctx, action.PaymentID).Return(nil)
+		payments.On("GetPayment", ctx, action.PaymentID).Return(&entity.Payment{ID: action.PaymentID, Status: entity.PaymentStatusCompleted}, nil)
+
+		payment, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "confirmed"))
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusCompleted, payment.Status)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("rejected fails payment", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+		actions.On("Resolve", ctx, action.PaymentID, entity.WalletActionStatusRejected, now).Return(nil)
+		payments.On("FailAction", ctx, action.PaymentID, "rejected in wallet").Return(nil)
+		payments.On("GetPayment", ctx, action.PaymentID).Return(&entity.Payment{ID: action.PaymentID, Status: entity.PaymentStatusFailed}, nil)
+
+		payment, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "rejected"))
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusFailed, payment.Status)
+		payments.AssertNotCalled(t, "CompleteAction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("repeated result is idempotent", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusConfirmed)
+		payments.On("GetPayment", ctx, action.PaymentID).Return(&entity.Payment{ID: action.PaymentID, Status: entity.PaymentStatusCompleted}, nil)
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "confirmed"))
+		require.NoError(t, err)
+		actions.AssertNotCalled(t, "Resolve", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "CompleteAction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("conflicting result after resolution is rejected", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusExpired)
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "confirmed"))
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		payments.AssertNotCalled(t, "CompleteAction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("lost race with expiry is rejected", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+		actions.On("Resolve", ctx, action.PaymentID, entity.WalletActionStatusConfirmed, now).Return(errors.New("wallet action not found or no longer pending"))
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "confirmed"))
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		payments.AssertNotCalled(t, "CompleteAction", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects bad signature", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+		req := callback(action.PaymentID, "confirmed")
+		req.Result = "rejected"
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, req)
+		assert.Equal(t, "invalid_signature", errors.Code(err))
+		actions.AssertNotCalled(t, "GetByPaymentID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects stale timestamp", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now.Add(10*time.Minute)).HandleCallback(ctx, callback(action.PaymentID, "confirmed"))
+		assert.Equal(t, "invalid_signature", errors.Code(err))
+	})
+
+	t.Run("rejects all callbacks without secret", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+		req := callback(action.PaymentID, "confirmed")
+		req.Signature = WalletCallbackSignature("", req.PaymentID.String(), req.Result, strconv.FormatInt(req.Timestamp, 10))
+
+		_, err := newTestWalletUseCase(actions, payments, "", now).HandleCallback(ctx, req)
+		assert.Equal(t, "invalid_signature", errors.Code(err))
+	})
+
+	t.Run("rejects unknown result", func(t *testing.T) {
+		action, actions, payments := setup(entity.WalletActionStatusPending)
+
+		_, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).HandleCallback(ctx, callback(action.PaymentID, "maybe"))
+		assert.Equal(t, "invalid_wallet_callback", errors.Code(err))
+	})
+
+	t.Run("unknown payment", func(t *testing.T) {
+		paymentID := uuid.New()
+		actions := new(MockWalletActionRepository)
+		actions.On("GetByPaymentID", ctx, paymentID).Return(nil, errors.New("wallet action not found"))
+
+		_, err := newTestWalletUseCase(actions, new(MockPaymentUseCase), testWalletSecret, now).HandleCallback(ctx, callback(paymentID, "confirmed"))
+		assert.Equal(t, "not_found", errors.Code(err))
+	})
+}
+
+func TestWalletUseCase_ExpireDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	actions := new(MockWalletActionRepository)
+	payments := new(MockPaymentUseCase)
+	expired := &entity.WalletAction{PaymentID: uuid.New()}
+	raced := &entity.WalletAction{PaymentID: uuid.New()}
+	actions.On("ListExpired", ctx, now, 100).Return([]*entity.WalletAction{expired, raced}, nil)
+	actions.On("Expire", ctx, expired.PaymentID, now).Return(nil)
+	actions.On("Expire", ctx, raced.PaymentID, now).Return(errors.New("wallet action not found or no longer pending"))
+	payments.On("FailAction", ctx, expired.PaymentID, "wallet confirmation timed out").Return(nil)
+
+	n, err := newTestWalletUseCase(actions, payments, testWalletSecret, now).ExpireDue(ctx, now, 100)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+	payments.AssertExpectations(t)
+	payments.AssertNotCalled(t, "FailAction", ctx, raced.PaymentID, mock.Anything)
+}
+
+func TestPaymentUseCase_DigitalWallet(t *testing.T) {
+	ctx := context.Background()
+	merchantID := uuid.New()
+	customerID := uuid.New()
+	wallets := WalletConfig{RedirectURL: "https://wallet.example.com/pay", ActionTimeout: 10 * time.Minute}
+
+	newUseCase := func(paymentRepo *MockPaymentRepository, actionRepo *MockWalletActionRepository) PaymentUseCase {
+		merchantRepo := new(MockMerchantRepository)
+		customerRepo := new(MockCustomerRepository)
+		merchantRepo.On("GetByID", ctx, merchantID).Return(&entity.Merchant{ID: merchantID, IsActive: true}, nil)
+		customerRepo.On("GetByID", ctx, customerID).Return(&entity.Customer{ID: customerID}, nil)
+		return NewPaymentUseCase(paymentRepo, merchantRepo, customerRepo, new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, actionRepo, wallets, nil, nil, nil)
+	}
+	request := func(actionType entity.NextActionType, returnURL string) CreatePaymentRequest {
+		return CreatePaymentRequest{
+			MerchantID: merchantID, CustomerID: customerID, Amount: 2500, Currency: "USD", Method: entity.PaymentMethodDigitalWallet,
+			NextActionType: actionType, ReturnURL: returnURL,
+		}
+	}
+
+	t.Run("create requires redirect", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		actionRepo := new(MockWalletActionRepository)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+		paymentRepo.On("TransitionStatus", ctx, mock.AnythingOfType("uuid.UUID"), entity.PaymentStatusPending, entity.PaymentStatusRequiresAction).Return(nil)
+		actionRepo.On("Create", ctx, mock.AnythingOfType("*entity.WalletAction")).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, actionRepo).CreatePayment(ctx, request("", "https://shop.example.com/done"))
+		require.NoError(t, err)
+		assert.Equal(t, entity.PaymentStatusRequiresAction, payment.Status)
+		require.NotNil(t, payment.NextAction)
+		assert.Equal(t, entity.NextActionRedirectToURL, payment.NextAction.Type)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), payment.NextAction.ExpiresAt, time.Minute)
+		u, err := url.Parse(payment.NextAction.RedirectURL)
+		require.NoError(t, err)
+		assert.Equal(t, "wallet.example.com", u.Host)
+		assert.Equal(t, payment.ID.String(), u.Query().Get("payment_id"))
+		assert.Equal(t, "2500", u.Query().Get("amount"))
+		assert.Equal(t, "https://shop.example.com/done", u.Query().Get("return_url"))
+	})
+
+	t.Run("create displays qr code", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		actionRepo := new(MockWalletActionRepository)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+		paymentRepo.On("TransitionStatus", ctx, mock.AnythingOfType("uuid.UUID"), entity.PaymentStatusPending, entity.PaymentStatusRequiresAction).Return(nil)
+		actionRepo.On("Create", ctx, mock.AnythingOfType("*entity.WalletAction")).Return(nil)
+
+		payment, err := newUseCase(paymentRepo, actionRepo).CreatePayment(ctx, request(entity.NextActionDisplayQRCode, ""))
+		require.NoError(t, err)
+		require.NotNil(t, payment.NextAction)
+		assert.Equal(t, entity.NextActionDisplayQRCode, payment.NextAction.Type)
+		assert.Empty(t, payment.NextAction.RedirectURL)
+		assert.Contains(t, payment.NextAction.QRPayload, "payment_id="+payment.ID.String())
+	})
+
+	t.Run("create rejects invalid action", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		useCase := newUseCase(paymentRepo, new(MockWalletActionRepository))
+
+		_, err := useCase.CreatePayment(ctx, request("sms_code", ""))
+		assert.Equal(t, "invalid_payment_method", errors.Code(err))
+		_, err = useCase.CreatePayment(ctx, request("", "javascript:alert(1)"))
+		assert.Equal(t, "invalid_payment_method", errors.Code(err))
+		paymentRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("create cancels payment when action cannot be issued", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		actionRepo := new(MockWalletActionRepository)
+		paymentRepo.On("Create", ctx, mock.AnythingOfType("*entity.Payment")).Return(nil)
+		paymentRepo.On("TransitionStatus", ctx, mock.AnythingOfType("uuid.UUID"), entity.PaymentStatusPending, entity.PaymentStatusCancelled).Return(nil)
+		actionRepo.On("Create", ctx, mock.AnythingOfType("*entity.WalletAction")).Return(errors.New("db down"))
+
+		_, err := newUseCase(paymentRepo, actionRepo).CreatePayment(ctx, request("", ""))
+		require.Error(t, err)
+		paymentRepo.AssertExpectations(t)
+		paymentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, entity.PaymentStatusPending, entity.PaymentStatusRequiresAction)
+	})
+
+	t.Run("get attaches next action", func(t *testing.T) {
+		paymentID := uuid.New()
+		paymentRepo := new(MockPaymentRepository)
+		actionRepo := new(MockWalletActionRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Method: entity.PaymentMethodDigitalWallet, Status: entity.PaymentStatusRequiresAction}, nil)
+		actionRepo.On("GetByPaymentID", ctx, paymentID).Return(&entity.WalletAction{
+			PaymentID: paymentID, Type: entity.NextActionRedirectToURL, RedirectURL: "https://wallet.example.com/pay?payment_id=" + paymentID.String(),
+			Status: entity.WalletActionStatusPending,
+		}, nil)
+
+		payment, err := newUseCase(paymentRepo, actionRepo).GetPayment(ctx, paymentID)
+		require.NoError(t, err)
+		require.NotNil(t, payment.NextAction)
+		assert.Contains(t, payment.NextAction.RedirectURL, paymentID.String())
+	})
+
+	t.Run("complete action requires pending confirmation", func(t *testing.T) {
+		paymentID := uuid.New()
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusRequiresAction}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusRequiresAction, entity.PaymentStatusCompleted).Return(errors.New("payment status changed concurrently")).Once()
+
+		err := newUseCase(paymentRepo, new(MockWalletActionRepository)).CompleteAction(ctx, paymentID)
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+	})
+}
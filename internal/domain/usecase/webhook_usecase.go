@@ -0,0 +1,176 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
+	"github.com/company/payment-service/pkg/webhook"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// WebhookUseCase 接收支付網關的 webhook：驗證簽章後保存原始內容，
+// 依網關的事件 ID 去除重複，再透過 PaymentUseCase 更新付款狀態
+type WebhookUseCase interface {
+	// Receive 處理網關送來的 webhook，重送已處理的事件時回傳既有記錄而不重新處理
+	Receive(ctx context.Context, provider string, header http.Header, body []byte) (*WebhookReceipt, error)
+	// Replay 以保存的原始內容重新處理事件，不再檢查簽章
+	Replay(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error)
+	GetEvent(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error)
+	ListEvents(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error)
+}
+
+type WebhookReceipt struct {
+	Event *entity.WebhookEvent `json:"event"`
+	// Duplicate 表示事件先前已收到，這次沒有重新處理
+	Duplicate bool `json:"duplicate"`
+}
+
+// webhookResults 為網關事件對應的付款狀態，其他事件類型記為 ignored
+var webhookResults = map[string]entity.PaymentStatus{
+	webhook.EventPaymentSucceeded: entity.PaymentStatusCompleted,
+	webhook.EventPaymentFailed:    entity.PaymentStatusFailed,
+	webhook.EventPaymentCanceled:  entity.PaymentStatusCancelled,
+}
+
+type webhookUseCase struct {
+	eventRepo repository.WebhookEventRepository
+	payments  PaymentUseCase
+	verifiers map[string]webhook.Verifier
+	now       func() time.Time
+}
+
+// NewWebhookUseCase 的 verifiers 以網址中的網關名稱為鍵，未列出的網關一律拒絕
+func NewWebhookUseCase(eventRepo repository.WebhookEventRepository, payments PaymentUseCase, verifiers map[string]webhook.Verifier) WebhookUseCase {
+	return &webhookUseCase{
+		eventRepo: eventRepo,
+		payments:  payments,
+		verifiers: verifiers,
+		now:       time.Now,
+	}
+}
+
+func (uc *webhookUseCase) Receive(ctx context.Context, provider string, header http.Header, body []byte) (*WebhookReceipt, error) {
+	verifier, ok := uc.verifiers[provider]
+	if !ok {
+		return nil, errors.WithCode(errors.New("unknown webhook provider "+provider), "not_found")
+	}
+	now := uc.now()
+	if err := verifier.Verify(header, body, now); err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to verify webhook"), "invalid_signature")
+	}
+	parsed, err := webhook.Parse(body)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to parse webhook"), "invalid_webhook")
+	}
+
+	if existing, err := uc.eventRepo.GetByEventID(ctx, provider, parsed.ID); err == nil {
+		return uc.redelivered(ctx, existing, parsed)
+	}
+
+	event := &entity.WebhookEvent{
+		ID:        uuid.New(),
+		Provider:  provider,
+		EventID:   parsed.ID,
+		EventType: parsed.Type,
+		Status:    entity.WebhookEventStatusReceived,
+		Payload:   string(body),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := uc.eventRepo.Create(ctx, event); err != nil {
+		// 同一事件同時送達時只有一個寫入成功
+		if existing, getErr := uc.eventRepo.GetByEventID(ctx, provider, parsed.ID); getErr == nil {
+			return &WebhookReceipt{Event: existing, Duplicate: true}, nil
+		}
+		return nil, errors.Wrap(err, "failed to store webhook event")
+	}
+
+	if err := uc.process(ctx, event, parsed); err != nil {
+		return nil, err
+	}
+	return &WebhookReceipt{Event: event}, nil
+}
+
+// redelivered 處理重送的事件；上次儲存後未記錄處理結果時重新處理，
+// 付款狀態的更新是冪等的，重複套用不會改變結果
+func (uc *webhookUseCase) redelivered(ctx context.Context, event *entity.WebhookEvent, parsed *webhook.Event) (*WebhookReceipt, error) {
+	if event.Status != entity.WebhookEventStatusReceived {
+		return &WebhookReceipt{Event: event, Duplicate: true}, nil
+	}
+	if err := uc.process(ctx, event, parsed); err != nil {
+		return nil, err
+	}
+	return &WebhookReceipt{Event: event, Duplicate: true}, nil
+}
+
+func (uc *webhookUseCase) Replay(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error) {
+	event, err := uc.GetEvent(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := webhook.Parse([]byte(event.Payload))
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to parse stored webhook"), "invalid_webhook")
+	}
+	if err := uc.process(ctx, event, parsed); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// process 套用事件並記錄結果；付款無法更新時事件記為 failed，可稍後重新處理
+func (uc *webhookUseCase) process(ctx context.Context, event *entity.WebhookEvent, parsed *webhook.Event) error {
+	now := uc.now()
+	event.Attempts++
+	event.ProcessedAt = &now
+	event.Error = ""
+
+	if status, ok := webhookResults[parsed.Type]; !ok {
+		event.Status = entity.WebhookEventStatusIgnored
+	} else if paymentID, err := uuid.Parse(parsed.PaymentID); err != nil {
+		event.Status = entity.WebhookEventStatusFailed
+		event.Error = "invalid payment_id " + parsed.PaymentID
+	} else {
+		event.PaymentID = &paymentID
+		if err := uc.payments.ApplyGatewayResult(ctx, paymentID, status, parsed.Reason); err != nil {
+			event.Status = entity.WebhookEventStatusFailed
+			event.Error = err.Error()
+		} else {
+			event.Status = entity.WebhookEventStatusProcessed
+		}
+	}
+
+	if event.Status == entity.WebhookEventStatusFailed {
+		logger.FromContext(ctx).Warn("failed to apply webhook event",
+			zap.String("provider", event.Provider),
+			zap.String("event_id", event.EventID),
+			zap.String("error", event.Error),
+		)
+	}
+	if err := uc.eventRepo.Update(ctx, event); err != nil {
+		return errors.Wrap(err, "failed to update webhook event")
+	}
+	return nil
+}
+
+func (uc *webhookUseCase) GetEvent(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error) {
+	event, err := uc.eventRepo.GetByID(ctx, id)
+	if err != nil {
+		return nil, errors.WithCode(errors.Wrap(err, "failed to get webhook event"), "not_found")
+	}
+	return event, nil
+}
+
+func (uc *webhookUseCase) ListEvents(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error) {
+	events, err := uc.eventRepo.List(ctx, provider, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook events")
+	}
+	return events, nil
+}
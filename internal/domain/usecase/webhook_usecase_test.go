@@ -0,0 +1,274 @@
+package usecase
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/webhook"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockWebhookEventRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookEventRepository) Create(ctx context.Context, event *entity.WebhookEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookEvent), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) GetByEventID(ctx context.Context, provider, eventID string) (*entity.WebhookEvent, error) {
+	args := m.Called(ctx, provider, eventID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.WebhookEvent), args.Error(1)
+}
+
+func (m *MockWebhookEventRepository) Update(ctx context.Context, event *entity.WebhookEvent) error {
+	args := m.Called(ctx, event)
+	return args.Error(0)
+}
+
+func (m *MockWebhookEventRepository) List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error) {
+	args := m.Called(ctx, provider, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.WebhookEvent), args.Error(1)
+}
+
+const testWebhookSecret = "whsec"
+
+// newTestWebhookUseCase 建立驗證 examplepay 簽章、時間固定為 now 的 use case
+func newTestWebhookUseCase(events *MockWebhookEventRepository, payments *MockPaymentUseCase, now time.Time) WebhookUseCase {
+	uc := NewWebhookUseCase(events, payments, map[string]webhook.Verifier{
+		"examplepay": &webhook.TimestampedHMAC{Secret: testWebhookSecret, Header: "Examplepay-Signature", Tolerance: time.Minute},
+	}).(*webhookUseCase)
+	uc.now = func() time.Time { return now }
+	return uc
+}
+
+// webhookPayload 產生事件內容
+func webhookPayload(eventID, eventType string, paymentID uuid.UUID) []byte {
+	return []byte(`{"id":"` + eventID + `","type":"` + eventType + `","data":{"payment_id":"` + paymentID.String() + `","reason":"card declined"}}`)
+}
+
+func TestWebhookUseCase_Receive(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	paymentID := uuid.New()
+	// signed 產生帶有正確簽章的 header
+	signed := func(body []byte) http.Header {
+		header := http.Header{}
+		header.Set("Examplepay-Signature", webhook.SignTimestamped(testWebhookSecret, body, now))
+		return header
+	}
+
+	t.Run("succeeded completes payment", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+		events.On("GetByEventID", ctx, "examplepay", "evt_1").Return(nil, errors.New("webhook event not found"))
+		events.On("Create", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(nil)
+		events.On("Update", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(nil)
+		payments.On("ApplyGatewayResult", ctx, paymentID, entity.PaymentStatusCompleted, "card declined").Return(nil)
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err)
+		assert.False(t, receipt.Duplicate)
+		event := receipt.Event
+		assert.Equal(t, "evt_1", event.EventID)
+		assert.Equal(t, webhook.EventPaymentSucceeded, event.EventType)
+		assert.Equal(t, string(body), event.Payload, "raw payload is kept for replay")
+		assert.Equal(t, entity.WebhookEventStatusProcessed, event.Status)
+		assert.Equal(t, &paymentID, event.PaymentID)
+		assert.Equal(t, 1, event.Attempts)
+		assert.Equal(t, &now, event.ProcessedAt)
+		payments.AssertExpectations(t)
+	})
+
+	t.Run("failed payment update is recorded", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_2", webhook.EventPaymentFailed, paymentID)
+		events.On("GetByEventID", ctx, "examplepay", "evt_2").Return(nil, errors.New("webhook event not found"))
+		events.On("Create", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(nil)
+		var stored *entity.WebhookEvent
+		events.On("Update", ctx, mock.AnythingOfType("*entity.WebhookEvent")).
+			Run(func(args mock.Arguments) { stored = args.Get(1).(*entity.WebhookEvent) }).Return(nil)
+		payments.On("ApplyGatewayResult", ctx, paymentID, entity.PaymentStatusFailed, "card declined").
+			Return(errors.WithCode(errors.New("payment status is completed, cannot apply failed"), "invalid_payment_status"))
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err, "the gateway does not need to retry")
+		assert.Equal(t, entity.WebhookEventStatusFailed, receipt.Event.Status)
+		assert.Contains(t, receipt.Event.Error, "cannot apply failed")
+		assert.Same(t, receipt.Event, stored)
+	})
+
+	t.Run("unhandled event type is ignored", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_3", "payment.created", paymentID)
+		events.On("GetByEventID", ctx, "examplepay", "evt_3").Return(nil, errors.New("webhook event not found"))
+		events.On("Create", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(nil)
+		events.On("Update", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(nil)
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err)
+		assert.Equal(t, entity.WebhookEventStatusIgnored, receipt.Event.Status)
+		payments.AssertNotCalled(t, "ApplyGatewayResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate event is not reprocessed", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+		existing := &entity.WebhookEvent{ID: uuid.New(), EventID: "evt_1", Status: entity.WebhookEventStatusProcessed, Attempts: 1}
+		events.On("GetByEventID", ctx, "examplepay", "evt_1").Return(existing, nil)
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err)
+		assert.True(t, receipt.Duplicate)
+		assert.Same(t, existing, receipt.Event)
+		events.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		payments.AssertNotCalled(t, "ApplyGatewayResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("duplicate of unfinished event is processed", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+		existing := &entity.WebhookEvent{ID: uuid.New(), EventID: "evt_1", Status: entity.WebhookEventStatusReceived}
+		events.On("GetByEventID", ctx, "examplepay", "evt_1").Return(existing, nil)
+		events.On("Update", ctx, existing).Return(nil)
+		payments.On("ApplyGatewayResult", ctx, paymentID, entity.PaymentStatusCompleted, "card declined").Return(nil)
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err)
+		assert.True(t, receipt.Duplicate)
+		assert.Equal(t, entity.WebhookEventStatusProcessed, existing.Status)
+	})
+
+	t.Run("concurrent delivery stores once", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		payments := new(MockPaymentUseCase)
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+		existing := &entity.WebhookEvent{ID: uuid.New(), EventID: "evt_1", Status: entity.WebhookEventStatusReceived}
+		events.On("GetByEventID", ctx, "examplepay", "evt_1").Return(nil, errors.New("webhook event not found")).Once()
+		events.On("GetByEventID", ctx, "examplepay", "evt_1").Return(existing, nil).Once()
+		events.On("Create", ctx, mock.AnythingOfType("*entity.WebhookEvent")).Return(errors.New("duplicate key"))
+
+		receipt, err := newTestWebhookUseCase(events, payments, now).Receive(ctx, "examplepay", signed(body), body)
+		require.NoError(t, err)
+		assert.True(t, receipt.Duplicate)
+		payments.AssertNotCalled(t, "ApplyGatewayResult", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects unknown provider", func(t *testing.T) {
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+
+		_, err := newTestWebhookUseCase(new(MockWebhookEventRepository), new(MockPaymentUseCase), now).Receive(ctx, "otherpay", signed(body), body)
+		assert.Equal(t, "not_found", errors.Code(err))
+	})
+
+	t.Run("rejects bad signature", func(t *testing.T) {
+		events := new(MockWebhookEventRepository)
+		body := webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)
+		header := signed(webhookPayload("evt_1", webhook.EventPaymentFailed, paymentID))
+
+		_, err := newTestWebhookUseCase(events, new(MockPaymentUseCase), now).Receive(ctx, "examplepay", header, body)
+		assert.Equal(t, "invalid_signature", errors.Code(err))
+		events.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("rejects invalid payload", func(t *testing.T) {
+		body := []byte(`{"type":"payment.succeeded"}`)
+
+		_, err := newTestWebhookUseCase(new(MockWebhookEventRepository), new(MockPaymentUseCase), now).Receive(ctx, "examplepay", signed(body), body)
+		assert.Equal(t, "invalid_webhook", errors.Code(err))
+	})
+}
+
+func TestWebhookUseCase_Replay(t *testing.T) {
+	ctx := context.Background()
+	paymentID := uuid.New()
+	events := new(MockWebhookEventRepository)
+	payments := new(MockPaymentUseCase)
+	useCase := newTestWebhookUseCase(events, payments, time.Now())
+	event := &entity.WebhookEvent{
+		ID: uuid.New(), Provider: "examplepay", EventID: "evt_1", Status: entity.WebhookEventStatusFailed,
+		Payload: string(webhookPayload("evt_1", webhook.EventPaymentSucceeded, paymentID)), Error: "db down", Attempts: 1,
+	}
+	events.On("GetByID", ctx, event.ID).Return(event, nil)
+	events.On("Update", ctx, event).Return(nil)
+	payments.On("ApplyGatewayResult", ctx, paymentID, entity.PaymentStatusCompleted, "card declined").Return(nil)
+
+	replayed, err := useCase.Replay(ctx, event.ID)
+	require.NoError(t, err)
+	assert.Equal(t, entity.WebhookEventStatusProcessed, replayed.Status)
+	assert.Empty(t, replayed.Error)
+	assert.Equal(t, 2, replayed.Attempts)
+
+	events.On("GetByID", ctx, mock.Anything).Return(nil, errors.New("webhook event not found"))
+	_, err = useCase.Replay(ctx, uuid.New())
+	assert.Equal(t, "not_found", errors.Code(err))
+}
+
+func TestPaymentUseCase_ApplyGatewayResult(t *testing.T) {
+	ctx := context.Background()
+	paymentID := uuid.New()
+	newUseCase := func(paymentRepo *MockPaymentRepository) PaymentUseCase {
+		return NewPaymentUseCase(paymentRepo, new(MockMerchantRepository), new(MockCustomerRepository), new(MockCardRepository), new(MockPaymentMethodRepository), new(MockInvoiceRepository), new(MockJobRepository), new(MockBankTransferRepository), BankTransferConfig{}, new(MockWalletActionRepository), WalletConfig{}, nil, nil, nil)
+	}
+
+	t.Run("transitions from current status", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusProcessing}, nil)
+		paymentRepo.On("TransitionStatus", ctx, paymentID, entity.PaymentStatusProcessing, entity.PaymentStatusCompleted).Return(nil)
+
+		require.NoError(t, newUseCase(paymentRepo).ApplyGatewayResult(ctx, paymentID, entity.PaymentStatusCompleted, ""))
+		paymentRepo.AssertExpectations(t)
+	})
+
+	t.Run("same status is a no-op", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusFailed}, nil)
+
+		require.NoError(t, newUseCase(paymentRepo).ApplyGatewayResult(ctx, paymentID, entity.PaymentStatusFailed, "declined"))
+		paymentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("final status is not overwritten", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(&entity.Payment{ID: paymentID, Status: entity.PaymentStatusCompleted}, nil)
+
+		err := newUseCase(paymentRepo).ApplyGatewayResult(ctx, paymentID, entity.PaymentStatusFailed, "declined")
+		assert.Equal(t, "invalid_payment_status", errors.Code(err))
+		paymentRepo.AssertNotCalled(t, "TransitionStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("unknown payment", func(t *testing.T) {
+		paymentRepo := new(MockPaymentRepository)
+		paymentRepo.On("GetByID", ctx, paymentID).Return(nil, errors.New("payment not found"))
+
+		err := newUseCase(paymentRepo).ApplyGatewayResult(ctx, paymentID, entity.PaymentStatusCompleted, "")
+		assert.Equal(t, "not_found", errors.Code(err))
+	})
+}
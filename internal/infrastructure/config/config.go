@@ -2,16 +2,33 @@ package config
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server"`
-	Database DatabaseConfig `mapstructure:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger"`
-	App      AppConfig      `mapstructure:"app"`
+	Server         ServerConfig         `mapstructure:"server"`
+	CORS           CORSConfig           `mapstructure:"cors"`
+	Database       DatabaseConfig       `mapstructure:"database"`
+	Logger         LoggerConfig         `mapstructure:"logger"`
+	App            AppConfig            `mapstructure:"app"`
+	Metrics        MetricsConfig        `mapstructure:"metrics"`
+	Tracing        TracingConfig        `mapstructure:"tracing"`
+	Vault          VaultConfig          `mapstructure:"vault"`
+	Billing        BillingConfig        `mapstructure:"billing"`
+	Checkout       CheckoutConfig       `mapstructure:"checkout"`
+	Worker         WorkerConfig         `mapstructure:"worker"`
+	BankTransfer   BankTransferConfig   `mapstructure:"bank_transfer"`
+	Reconciliation ReconciliationConfig `mapstructure:"reconciliation"`
+	Wallet         WalletConfig         `mapstructure:"wallet"`
+	Webhooks       WebhookConfig        `mapstructure:"webhooks"`
+	Disputes       DisputeConfig        `mapstructure:"disputes"`
+	Risk           RiskConfig           `mapstructure:"risk"`
+	RateLimit      RateLimitConfig      `mapstructure:"rate_limit"`
+	RequestSigning RequestSigningConfig `mapstructure:"request_signing"`
+	Admin          AdminConfig          `mapstructure:"admin"`
 }
 
 type ServerConfig struct {
@@ -19,18 +36,45 @@ type ServerConfig struct {
 	Port         int           `mapstructure:"port"`
 	ReadTimeout  time.Duration `mapstructure:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout"`
+	// ShutdownDelay 為收到停止訊號後、readiness 轉為失敗到實際關閉前的等待時間
+	ShutdownDelay      time.Duration `mapstructure:"shutdown_delay"`
+	ShutdownTimeout    time.Duration `mapstructure:"shutdown_timeout"`
+	HealthCheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+}
+
+// CORSConfig 設定跨來源請求，allowed_origins 可用 https://*.example.com 允許子網域，
+// 為空時只允許同源請求；* 不能與 allow_credentials 同時使用
+type CORSConfig struct {
+	AllowedOrigins   []string      `mapstructure:"allowed_origins"`
+	AllowedMethods   []string      `mapstructure:"allowed_methods"`
+	AllowedHeaders   []string      `mapstructure:"allowed_headers"`
+	ExposedHeaders   []string      `mapstructure:"exposed_headers"`
+	MaxAge           time.Duration `mapstructure:"max_age"`
+	AllowCredentials bool          `mapstructure:"allow_credentials"`
 }
 
 type DatabaseConfig struct {
-	Host            string        `mapstructure:"host"`
-	Port            int           `mapstructure:"port"`
-	User            string        `mapstructure:"user"`
-	Password        string        `mapstructure:"password"`
-	DBName          string        `mapstructure:"dbname"`
-	SSLMode         string        `mapstructure:"sslmode"`
-	MaxOpenConns    int           `mapstructure:"max_open_conns"`
-	MaxIdleConns    int           `mapstructure:"max_idle_conns"`
-	ConnMaxLifetime time.Duration `mapstructure:"conn_max_lifetime"`
+	Driver          string          `mapstructure:"driver"` // postgres, sqlite or memory
+	Host            string          `mapstructure:"host"`
+	Port            int             `mapstructure:"port"`
+	User            string          `mapstructure:"user"`
+	Password        string          `mapstructure:"password"`
+	DBName          string          `mapstructure:"dbname"`
+	SSLMode         string          `mapstructure:"sslmode"`
+	MaxOpenConns    int             `mapstructure:"max_open_conns"`
+	MaxIdleConns    int             `mapstructure:"max_idle_conns"`
+	ConnMaxLifetime time.Duration   `mapstructure:"conn_max_lifetime"`
+	Path            string          `mapstructure:"path"` // SQLite 資料庫檔案
+	AutoMigrate     bool            `mapstructure:"auto_migrate"`
+	Replicas        []ReplicaConfig `mapstructure:"replicas"`
+}
+
+// ReplicaConfig 描述一個 PostgreSQL 唯讀副本，未設定的帳密沿用主庫
+type ReplicaConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	User     string `mapstructure:"user"`
+	Password string `mapstructure:"password"`
 }
 
 type LoggerConfig struct {
@@ -39,6 +83,198 @@ type LoggerConfig struct {
 	OutputPath string `mapstructure:"output_path"`
 }
 
+type MetricsConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Path    string `mapstructure:"path"`
+}
+
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"` // otlp or stdout
+	Endpoint    string  `mapstructure:"endpoint"` // OTLP/HTTP endpoint, e.g. localhost:4318
+	Insecure    bool    `mapstructure:"insecure"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
+// VaultConfig 設定卡片保險庫的主金鑰（KEK），金鑰為 base64 編碼的 32 bytes。
+// 輪替時把舊金鑰移到 previous_keys，新卡片會以新金鑰加密，舊卡片仍可解密。
+type VaultConfig struct {
+	KeyID        string            `mapstructure:"key_id"`
+	KEK          string            `mapstructure:"kek"`
+	PreviousKeys map[string]string `mapstructure:"previous_keys"`
+}
+
+// BillingConfig 設定訂閱續期排程與催收重試。排程器只應在單一實例上啟用，
+// 多個實例同時執行會重複扣款。
+type BillingConfig struct {
+	Enabled   bool          `mapstructure:"enabled"`
+	Interval  time.Duration `mapstructure:"interval"`
+	BatchSize int           `mapstructure:"batch_size"`
+	// RetrySchedule 為扣款失敗後每次重試前的等待時間，用盡後停止扣款
+	RetrySchedule     []time.Duration `mapstructure:"retry_schedule"`
+	CancelOnExhausted bool            `mapstructure:"cancel_on_exhausted"`
+}
+
+// CheckoutConfig 設定代管付款頁。BaseURL 為客戶瀏覽器可連到的服務網址，
+// 經過反向代理時應填寫對外網址
+type CheckoutConfig struct {
+	BaseURL string `mapstructure:"base_url"`
+}
+
+// WorkerConfig 設定執行背景工作佇列（例如非同步請款）的 worker pool。
+// 工作以資料列鎖分派，可在多個實例同時啟用
+type WorkerConfig struct {
+	Enabled     bool `mapstructure:"enabled"`
+	Concurrency int  `mapstructure:"concurrency"`
+	// PollInterval 為佇列沒有工作時再次查詢前的等待時間
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	// VisibilityTimeout 為單次執行的上限，worker 中斷時工作在逾時後由其他 worker 重新取得
+	VisibilityTimeout time.Duration `mapstructure:"visibility_timeout"`
+	MaxAttempts       int           `mapstructure:"max_attempts"`
+	// BackoffBase 為第一次失敗後的重試間隔，之後每次加倍直到 BackoffMax
+	BackoffBase time.Duration `mapstructure:"backoff_base"`
+	BackoffMax  time.Duration `mapstructure:"backoff_max"`
+}
+
+// BankTransferConfig 設定銀行轉帳付款的匯款資訊與逾期取消排程。
+// 逾期以條件更新標記，可在多個實例同時啟用
+type BankTransferConfig struct {
+	// ExpiresIn 為發出匯款資訊後等待付足的時間，逾期取消付款
+	ExpiresIn time.Duration `mapstructure:"expires_in"`
+	// AccountPrefix 為虛擬帳號的前綴，通常是銀行配發的號段
+	AccountPrefix string        `mapstructure:"account_prefix"`
+	BankName      string        `mapstructure:"bank_name"`
+	SweepEnabled  bool          `mapstructure:"sweep_enabled"`
+	SweepInterval time.Duration `mapstructure:"sweep_interval"`
+	BatchSize     int           `mapstructure:"batch_size"`
+}
+
+// ReconciliationConfig 設定銀行對帳單與付款的比對規則
+type ReconciliationConfig struct {
+	// DateTolerance 為入帳日期與付款完成時間可接受的差距，
+	// 對帳單只有日期且銀行通常隔日入帳，預設為三天
+	DateTolerance time.Duration `mapstructure:"date_tolerance"`
+}
+
+// WalletConfig 設定數位錢包付款的確認頁與回呼。
+// 逾時未確認的付款由排程標記為 failed，可在多個實例同時啟用
+type WalletConfig struct {
+	// RedirectURL 為錢包確認頁，付款資訊以 query string 附加
+	RedirectURL string `mapstructure:"redirect_url"`
+	// ActionTimeout 為等待客戶在錢包確認的時間
+	ActionTimeout time.Duration `mapstructure:"action_timeout"`
+	// CallbackSecret 為回呼簽章的金鑰，為空時拒絕所有回呼
+	CallbackSecret string        `mapstructure:"callback_secret"`
+	SweepEnabled   bool          `mapstructure:"sweep_enabled"`
+	SweepInterval  time.Duration `mapstructure:"sweep_interval"`
+	BatchSize      int           `mapstructure:"batch_size"`
+}
+
+// WebhookConfig 設定接收 webhook 的支付網關，鍵為 /webhooks/:provider 中的網關名稱，
+// 未列出的網關一律拒絕
+type WebhookConfig struct {
+	Providers map[string]WebhookProviderConfig `mapstructure:"providers"`
+}
+
+// WebhookProviderConfig 設定網關的簽章驗證。Scheme 為 timestamped_hmac 或 hmac_sha256，
+// Secret 為空時拒絕該網關的所有 webhook
+type WebhookProviderConfig struct {
+	Scheme string `mapstructure:"scheme"`
+	Secret string `mapstructure:"secret"`
+	// Header 為帶簽章的 HTTP header
+	Header string `mapstructure:"header"`
+	// Tolerance 為 timestamped_hmac 可接受的時間差距，預設五分鐘
+	Tolerance time.Duration `mapstructure:"tolerance"`
+}
+
+// DisputeConfig 設定爭議的證據期限與附件保存位置。
+// 多實例部署時 EvidenceDir 需為共用儲存
+type DisputeConfig struct {
+	// ResponseWindow 為建立爭議後商戶提交證據的期限
+	ResponseWindow time.Duration `mapstructure:"response_window"`
+	EvidenceDir    string        `mapstructure:"evidence_dir"`
+	// MaxEvidenceSize 為單一附件的大小上限（bytes）
+	MaxEvidenceSize int64 `mapstructure:"max_evidence_size"`
+}
+
+// RiskConfig 設定建立付款前的風險規則，金額門檻以幣別為鍵、單位為最小貨幣單位
+type RiskConfig struct {
+	Enabled          bool                           `mapstructure:"enabled"`
+	ReviewScore      int                            `mapstructure:"review_score"`
+	BlockScore       int                            `mapstructure:"block_score"`
+	AmountThresholds map[string]RiskAmountThreshold `mapstructure:"amount_thresholds"`
+	Velocity         []RiskVelocityRule             `mapstructure:"velocity"`
+	// FirstTimeCustomer 對沒有完成過付款的客戶加嚴金額門檻
+	FirstTimeCustomer RiskFirstTimeRule `mapstructure:"first_time_customer"`
+	Blocklist         RiskBlocklist     `mapstructure:"blocklist"`
+}
+
+type RiskAmountThreshold struct {
+	Review int64 `mapstructure:"review"`
+	Block  int64 `mapstructure:"block"`
+}
+
+// RiskVelocityRule 的 Key 為 customer、email 或 ip
+type RiskVelocityRule struct {
+	Key      string        `mapstructure:"key"`
+	Window   time.Duration `mapstructure:"window"`
+	MaxCount int           `mapstructure:"max_count"`
+	Score    int           `mapstructure:"score"`
+}
+
+type RiskFirstTimeRule struct {
+	Amounts map[string]int64 `mapstructure:"amounts"`
+	Score   int              `mapstructure:"score"`
+}
+
+// RiskBlocklist 的 IPs 可為單一 IP 或 CIDR
+type RiskBlocklist struct {
+	CustomerIDs  []string `mapstructure:"customer_ids"`
+	Emails       []string `mapstructure:"emails"`
+	EmailDomains []string `mapstructure:"email_domains"`
+	IPs          []string `mapstructure:"ips"`
+}
+
+// RateLimitConfig 設定商戶 API 的 token bucket 請求頻率限制。Store 為 memory 時只在單一實例內計算，
+// 多個實例需設為 database 共用資料庫中的 bucket
+type RateLimitConfig struct {
+	Enabled bool   `mapstructure:"enabled"`
+	Store   string `mapstructure:"store"` // memory or database
+	// Default 適用於 Tiers 沒有列出等級的商戶
+	Default RateLimitRule            `mapstructure:"default"`
+	Tiers   map[string]RateLimitRule `mapstructure:"tiers"`
+	// Routes 為另外計算的個別路由限制，Path 為路由樣板，例如 /api/v1/payments/:id
+	Routes []RateLimitRoute `mapstructure:"routes"`
+}
+
+// RateLimitRule 每個 Period 補充 Requests 個請求，最多累積 Burst 個；Burst 為 0 時等於 Requests
+type RateLimitRule struct {
+	Requests int           `mapstructure:"requests"`
+	Period   time.Duration `mapstructure:"period"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+type RateLimitRoute struct {
+	Method        string `mapstructure:"method"`
+	Path          string `mapstructure:"path"`
+	RateLimitRule `mapstructure:",squash"`
+	Tiers         map[string]RateLimitRule `mapstructure:"tiers"`
+}
+
+// RequestSigningConfig 設定以 HMAC 簽署的商戶 API 請求。NonceStore 為 memory 時只在單一實例內
+// 防止重送，多個實例需設為 database
+type RequestSigningConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Tolerance 為請求時間戳記與伺服器時間可接受的差距
+	Tolerance  time.Duration `mapstructure:"tolerance"`
+	NonceStore string        `mapstructure:"nonce_store"` // memory or database
+}
+
+// AdminConfig 設定平台管理 API（例如銀行入帳匯入與對帳），APIKey 為空時管理 API 一律拒絕
+type AdminConfig struct {
+	APIKey string `mapstructure:"api_key"`
+}
+
 type AppConfig struct {
 	Name        string `mapstructure:"name"`
 	Version     string `mapstructure:"version"`
@@ -59,6 +295,8 @@ func LoadConfig(configPath string) (*Config, error) {
 
 	// 設置環境變量前綴
 	viper.SetEnvPrefix("PAYMENT")
+	// 巢狀設定以底線對應，例如 vault.kek 對應 PAYMENT_VAULT_KEK
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	// 設置默認值
@@ -82,8 +320,20 @@ func setDefaults() {
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.read_timeout", "30s")
 	viper.SetDefault("server.write_timeout", "30s")
+	viper.SetDefault("server.shutdown_delay", "5s")
+	viper.SetDefault("server.shutdown_timeout", "30s")
+	viper.SetDefault("server.health_check_timeout", "2s")
+
+	// CORS defaults
+	viper.SetDefault("cors.allowed_origins", []string{})
+	viper.SetDefault("cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE"})
+	viper.SetDefault("cors.allowed_headers", []string{"Content-Type", "Authorization", "X-API-Key", "X-Request-ID", "X-Read-Your-Writes", "traceparent"})
+	viper.SetDefault("cors.exposed_headers", []string{"X-Request-ID", "X-Trace-ID", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After"})
+	viper.SetDefault("cors.max_age", "10m")
+	viper.SetDefault("cors.allow_credentials", false)
 
 	// Database defaults
+	viper.SetDefault("database.driver", "postgres")
 	viper.SetDefault("database.host", "localhost")
 	viper.SetDefault("database.port", 5432)
 	viper.SetDefault("database.user", "postgres")
@@ -93,14 +343,94 @@ func setDefaults() {
 	viper.SetDefault("database.max_open_conns", 25)
 	viper.SetDefault("database.max_idle_conns", 5)
 	viper.SetDefault("database.conn_max_lifetime", "5m")
+	viper.SetDefault("database.path", "payment_service.db")
+	viper.SetDefault("database.auto_migrate", false)
 
 	// Logger defaults
 	viper.SetDefault("logger.level", "info")
 	viper.SetDefault("logger.format", "json")
 	viper.SetDefault("logger.output_path", "stdout")
 
+	// Metrics defaults
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
+
+	// Tracing defaults
+	viper.SetDefault("tracing.enabled", false)
+	viper.SetDefault("tracing.exporter", "stdout")
+	viper.SetDefault("tracing.endpoint", "localhost:4318")
+	viper.SetDefault("tracing.insecure", true)
+	viper.SetDefault("tracing.sample_ratio", 1.0)
+
+	// Vault defaults
+	viper.SetDefault("vault.key_id", "local-1")
+	viper.SetDefault("vault.kek", "")
+
+	// Billing defaults
+	viper.SetDefault("billing.enabled", false)
+	viper.SetDefault("billing.interval", "1m")
+	viper.SetDefault("billing.batch_size", 100)
+	viper.SetDefault("billing.retry_schedule", []string{"24h", "72h", "120h"})
+	viper.SetDefault("billing.cancel_on_exhausted", false)
+
+	// Checkout defaults
+	viper.SetDefault("checkout.base_url", "http://localhost:8080")
+
+	// Worker defaults
+	viper.SetDefault("worker.enabled", true)
+	viper.SetDefault("worker.concurrency", 4)
+	viper.SetDefault("worker.poll_interval", "1s")
+	viper.SetDefault("worker.visibility_timeout", "30s")
+	viper.SetDefault("worker.max_attempts", 5)
+	viper.SetDefault("worker.backoff_base", "5s")
+	viper.SetDefault("worker.backoff_max", "10m")
+
+	// Bank transfer defaults
+	viper.SetDefault("bank_transfer.expires_in", "72h")
+	viper.SetDefault("bank_transfer.account_prefix", "9900")
+	viper.SetDefault("bank_transfer.bank_name", "")
+	viper.SetDefault("bank_transfer.sweep_enabled", true)
+	viper.SetDefault("bank_transfer.sweep_interval", "1m")
+	viper.SetDefault("bank_transfer.batch_size", 100)
+
+	// Reconciliation defaults
+	viper.SetDefault("reconciliation.date_tolerance", "72h")
+
+	// Wallet defaults
+	viper.SetDefault("wallet.redirect_url", "https://wallet.example.com/pay")
+	viper.SetDefault("wallet.action_timeout", "15m")
+	viper.SetDefault("wallet.callback_secret", "")
+	viper.SetDefault("wallet.sweep_enabled", true)
+	viper.SetDefault("wallet.sweep_interval", "1m")
+	viper.SetDefault("wallet.batch_size", 100)
+
+	// Dispute defaults
+	viper.SetDefault("disputes.response_window", "168h")
+	viper.SetDefault("disputes.evidence_dir", "data/disputes")
+	viper.SetDefault("disputes.max_evidence_size", 5242880)
+
+	// Risk defaults
+	viper.SetDefault("risk.enabled", true)
+	viper.SetDefault("risk.review_score", 50)
+	viper.SetDefault("risk.block_score", 80)
+
+	// Rate limit defaults
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.store", "memory")
+	viper.SetDefault("rate_limit.default.requests", 600)
+	viper.SetDefault("rate_limit.default.period", "1m")
+	viper.SetDefault("rate_limit.default.burst", 100)
+
+	// Request signing defaults
+	viper.SetDefault("request_signing.enabled", true)
+	viper.SetDefault("request_signing.tolerance", "5m")
+	viper.SetDefault("request_signing.nonce_store", "memory")
+
+	// Admin defaults
+	viper.SetDefault("admin.api_key", "")
+
 	// App defaults
 	viper.SetDefault("app.name", "payment-service")
 	viper.SetDefault("app.version", "1.0.0")
 	viper.SetDefault("app.environment", "development")
-}
\ No newline at end of file
+}
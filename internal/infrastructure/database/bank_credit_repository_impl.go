@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+const bankCreditColumns = `
+	id, transaction_id, reference, virtual_account, amount, currency,
+	payer_name, received_at, status, payment_id, note, created_at, updated_at`
+
+type bankCreditRepository struct {
+	db *Cluster
+}
+
+func NewBankCreditRepository(db *Cluster) repository.BankCreditRepository {
+	return &bankCreditRepository{db: db}
+}
+
+func (r *bankCreditRepository) Create(ctx context.Context, credit *entity.BankCredit) error {
+	query := `
+		INSERT INTO bank_credits (` + bankCreditColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		credit.ID, credit.TransactionID, credit.Reference, credit.VirtualAccount,
+		credit.Amount, credit.Currency, credit.PayerName, credit.ReceivedAt,
+		credit.Status, credit.PaymentID, credit.Note, credit.CreatedAt, credit.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bank credit")
+	}
+	return nil
+}
+
+func (r *bankCreditRepository) GetByTransactionID(ctx context.Context, transactionID string) (*entity.BankCredit, error) {
+	var credit entity.BankCredit
+	query := `SELECT ` + bankCreditColumns + ` FROM bank_credits WHERE transaction_id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &credit, r.db.Rebind(query), transactionID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("bank credit not found")
+		}
+		return nil, errors.Wrap(err, "failed to get bank credit by transaction id")
+	}
+	return &credit, nil
+}
+
+func (r *bankCreditRepository) Update(ctx context.Context, credit *entity.BankCredit) error {
+	query := `
+		UPDATE bank_credits
+		SET status = ?, payment_id = ?, note = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		credit.Status, credit.PaymentID, credit.Note, time.Now(), credit.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to update bank credit")
+	}
+	return requireAffected(result, "bank credit not found")
+}
+
+func (r *bankCreditRepository) List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error) {
+	query := `
+		SELECT ` + bankCreditColumns + `
+		FROM bank_credits
+		WHERE (? = '' OR status = ?)
+		ORDER BY received_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var credits []*entity.BankCredit
+	err := r.db.Reader(ctx).SelectContext(ctx, &credits, r.db.Rebind(query), status, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list bank credits")
+	}
+	return credits, nil
+}
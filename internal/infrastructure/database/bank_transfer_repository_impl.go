@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const bankTransferColumns = `
+	payment_id, merchant_id, reference, virtual_account, bank_name,
+	amount_expected, amount_received, currency, status, expires_at,
+	created_at, updated_at`
+
+type bankTransferRepository struct {
+	db *Cluster
+}
+
+func NewBankTransferRepository(db *Cluster) repository.BankTransferRepository {
+	return &bankTransferRepository{db: db}
+}
+
+func (r *bankTransferRepository) Create(ctx context.Context, transfer *entity.BankTransfer) error {
+	query := `
+		INSERT INTO bank_transfers (` + bankTransferColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		transfer.PaymentID, transfer.MerchantID, transfer.Reference, transfer.VirtualAccount,
+		transfer.BankName, transfer.AmountExpected, transfer.AmountReceived, transfer.Currency,
+		transfer.Status, transfer.ExpiresAt, transfer.CreatedAt, transfer.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create bank transfer")
+	}
+	return nil
+}
+
+func (r *bankTransferRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.BankTransfer, error) {
+	return r.get(ctx, "payment_id", paymentID)
+}
+
+func (r *bankTransferRepository) GetByReference(ctx context.Context, reference string) (*entity.BankTransfer, error) {
+	return r.get(ctx, "reference", reference)
+}
+
+func (r *bankTransferRepository) GetByVirtualAccount(ctx context.Context, account string) (*entity.BankTransfer, error) {
+	return r.get(ctx, "virtual_account", account)
+}
+
+// get 以唯一欄位查詢，column 只由本檔傳入常數
+func (r *bankTransferRepository) get(ctx context.Context, column string, value interface{}) (*entity.BankTransfer, error) {
+	var transfer entity.BankTransfer
+	query := `SELECT ` + bankTransferColumns + ` FROM bank_transfers WHERE ` + column + ` = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &transfer, r.db.Rebind(query), value); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("bank transfer not found")
+		}
+		return nil, errors.Wrap(err, "failed to get bank transfer by "+column)
+	}
+	return &transfer, nil
+}
+
+func (r *bankTransferRepository) ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (*entity.BankTransfer, error) {
+	// SET 中的 amount_received 為更新前的值，狀態依累加後的金額判斷
+	query := `
+		UPDATE bank_transfers
+		SET amount_received = amount_received + ?,
+		    status = CASE
+		        WHEN amount_received + ? < amount_expected THEN ?
+		        WHEN amount_received + ? = amount_expected THEN ?
+		        ELSE ?
+		    END,
+		    updated_at = ?
+		WHERE payment_id = ? AND status IN (?, ?) AND expires_at > ?
+	`
+	db := r.db.Writer(ctx)
+	result, err := db.ExecContext(ctx, r.db.Rebind(query),
+		amount,
+		amount, entity.BankTransferStatusUnderpaid,
+		amount, entity.BankTransferStatusPaid,
+		entity.BankTransferStatusOverpaid,
+		now, paymentID,
+		entity.BankTransferStatusAwaitingFunds, entity.BankTransferStatusUnderpaid, now,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to apply bank credit")
+	}
+	if err := requireAffected(result, "bank transfer not found or no longer awaiting funds"); err != nil {
+		return nil, err
+	}
+
+	var transfer entity.BankTransfer
+	query = `SELECT ` + bankTransferColumns + ` FROM bank_transfers WHERE payment_id = ?`
+	if err := db.GetContext(ctx, &transfer, r.db.Rebind(query), paymentID); err != nil {
+		return nil, errors.Wrap(err, "failed to get bank transfer by payment_id")
+	}
+	return &transfer, nil
+}
+
+func (r *bankTransferRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.BankTransfer, error) {
+	query := `
+		SELECT ` + bankTransferColumns + `
+		FROM bank_transfers
+		WHERE status IN (?, ?) AND expires_at <= ?
+		ORDER BY expires_at
+		LIMIT ?
+	`
+	var transfers []*entity.BankTransfer
+	err := r.db.Reader(ctx).SelectContext(ctx, &transfers, r.db.Rebind(query),
+		entity.BankTransferStatusAwaitingFunds, entity.BankTransferStatusUnderpaid, now, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list expired bank transfers")
+	}
+	return transfers, nil
+}
+
+func (r *bankTransferRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE bank_transfers
+		SET status = ?, updated_at = ?
+		WHERE payment_id = ? AND status IN (?, ?) AND expires_at <= ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.BankTransferStatusExpired, now, paymentID,
+		entity.BankTransferStatusAwaitingFunds, entity.BankTransferStatusUnderpaid, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to expire bank transfer")
+	}
+	return requireAffected(result, "bank transfer not found or no longer awaiting funds")
+}
+
+func (r *bankTransferRepository) Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE bank_transfers
+		SET status = ?, updated_at = ?
+		WHERE payment_id = ? AND status IN (?, ?)
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.BankTransferStatusExpired, now, paymentID,
+		entity.BankTransferStatusAwaitingFunds, entity.BankTransferStatusUnderpaid)
+	if err != nil {
+		return errors.Wrap(err, "failed to cancel bank transfer")
+	}
+	return requireAffected(result, "bank transfer not found or no longer awaiting funds")
+}
@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+type cardRepository struct {
+	db *Cluster
+}
+
+func NewCardRepository(db *Cluster) repository.CardRepository {
+	return &cardRepository{db: db}
+}
+
+func (r *cardRepository) Create(ctx context.Context, card *entity.Card) error {
+	query := `
+		INSERT INTO cards (token, merchant_id, brand, last4, exp_month, exp_year, fingerprint, encrypted_pan, encrypted_key, key_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		card.Token, card.MerchantID, card.Brand, card.Last4, card.ExpMonth, card.ExpYear,
+		card.Fingerprint, card.EncryptedPAN, card.EncryptedKey, card.KeyID, card.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create card")
+	}
+	return nil
+}
+
+func (r *cardRepository) GetByToken(ctx context.Context, token string) (*entity.Card, error) {
+	query := `
+		SELECT token, merchant_id, brand, last4, exp_month, exp_year, fingerprint,
+		       encrypted_pan, encrypted_key, key_id, created_at
+		FROM cards WHERE token = ?
+	`
+	var card entity.Card
+	err := r.db.Reader(ctx).GetContext(ctx, &card, r.db.Rebind(query), token)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("card not found")
+		}
+		return nil, errors.Wrap(err, "failed to get card by token")
+	}
+	return &card, nil
+}
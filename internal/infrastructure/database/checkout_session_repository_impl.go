@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const checkoutSessionColumns = `
+	id, merchant_id, customer_id, customer_email, amount, currency, description,
+	reference, status, allowed_methods, success_url, cancel_url, payment_id,
+	payment_link_id, expires_at, completed_at, created_at, updated_at`
+
+// checkoutSessionRow 以逗號分隔字串保存付款方式清單
+type checkoutSessionRow struct {
+	entity.CheckoutSession
+	AllowedMethods string `db:"allowed_methods"`
+}
+
+type checkoutSessionRepository struct {
+	db *Cluster
+}
+
+func NewCheckoutSessionRepository(db *Cluster) repository.CheckoutSessionRepository {
+	return &checkoutSessionRepository{db: db}
+}
+
+func (r *checkoutSessionRepository) Create(ctx context.Context, session *entity.CheckoutSession) error {
+	query := `
+		INSERT INTO checkout_sessions (` + checkoutSessionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		session.ID, session.MerchantID, session.CustomerID, session.CustomerEmail,
+		session.Amount, session.Currency, session.Description, session.Reference,
+		session.Status, joinPaymentMethods(session.AllowedMethods), session.SuccessURL,
+		session.CancelURL, session.PaymentID, session.PaymentLinkID, session.ExpiresAt, session.CompletedAt,
+		session.CreatedAt, session.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create checkout session")
+	}
+	return nil
+}
+
+func (r *checkoutSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CheckoutSession, error) {
+	var row checkoutSessionRow
+	query := `SELECT ` + checkoutSessionColumns + ` FROM checkout_sessions WHERE id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &row, r.db.Rebind(query), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("checkout session not found")
+		}
+		return nil, errors.Wrap(err, "failed to get checkout session by id")
+	}
+
+	session := row.CheckoutSession
+	session.AllowedMethods = splitPaymentMethods(row.AllowedMethods)
+	return &session, nil
+}
+
+func (r *checkoutSessionRepository) Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) error {
+	query := `
+		UPDATE checkout_sessions
+		SET status = ?, customer_id = ?, payment_id = ?, completed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		session.Status, session.CustomerID, session.PaymentID, session.CompletedAt,
+		session.UpdatedAt, session.ID, from,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update checkout session")
+	}
+	return requireAffected(result, fmt.Sprintf("checkout session not found or no longer %s", from))
+}
+
+func joinPaymentMethods(methods []entity.PaymentMethod) string {
+	parts := make([]string, len(methods))
+	for i, m := range methods {
+		parts[i] = string(m)
+	}
+	return strings.Join(parts, ",")
+}
+
+func splitPaymentMethods(s string) []entity.PaymentMethod {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	methods := make([]entity.PaymentMethod, len(parts))
+	for i, p := range parts {
+		methods[i] = entity.PaymentMethod(p)
+	}
+	return methods
+}
@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/jmoiron/sqlx"
+)
+
+// Cluster 封裝主庫與唯讀副本。寫入一律走主庫，讀取以輪詢方式分散到副本；
+// 沒有設定副本或請求內已有寫入時，讀取也走主庫。
+type Cluster struct {
+	primary  *sqlx.DB
+	replicas []*sqlx.DB
+	next     atomic.Uint64
+}
+
+func NewCluster(primary *sqlx.DB, replicas ...*sqlx.DB) *Cluster {
+	return &Cluster{
+		primary:  primary,
+		replicas: replicas,
+	}
+}
+
+// Writer 回傳主庫，並記錄此請求已發生寫入
+func (c *Cluster) Writer(ctx context.Context) *sqlx.DB {
+	repository.MarkWritten(ctx)
+	return c.primary
+}
+
+// Reader 回傳唯讀查詢應使用的連線
+func (c *Cluster) Reader(ctx context.Context) *sqlx.DB {
+	if len(c.replicas) == 0 || repository.ReadFromPrimary(ctx) {
+		return c.primary
+	}
+	n := c.next.Add(1)
+	return c.replicas[(n-1)%uint64(len(c.replicas))]
+}
+
+func (c *Cluster) Primary() *sqlx.DB {
+	return c.primary
+}
+
+// Rebind 依主庫的驅動轉換查詢中的 ? 佔位符號
+func (c *Cluster) Rebind(query string) string {
+	return c.primary.Rebind(query)
+}
+
+// Pools 回傳所有連線池，key 為 primary 或 replica_N
+func (c *Cluster) Pools() map[string]*sqlx.DB {
+	pools := map[string]*sqlx.DB{"primary": c.primary}
+	for i, replica := range c.replicas {
+		pools[fmt.Sprintf("replica_%d", i)] = replica
+	}
+	return pools
+}
+
+// Stats 回傳各連線池的統計資料
+func (c *Cluster) Stats() map[string]sql.DBStats {
+	stats := make(map[string]sql.DBStats)
+	for name, db := range c.Pools() {
+		stats[name] = db.Stats()
+	}
+	return stats
+}
+
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, replica := range c.replicas {
+		errs = append(errs, replica.Close())
+	}
+	errs = append(errs, c.primary.Close())
+	return errors.Join(errs...)
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterRouting(t *testing.T) {
+	open := func() *sqlx.DB {
+		db, err := NewSQLiteConnection(Config{Path: ":memory:"})
+		require.NoError(t, err)
+		return db
+	}
+	primary, replicaA, replicaB := open(), open(), open()
+	cluster := NewCluster(primary, replicaA, replicaB)
+	t.Cleanup(func() { cluster.Close() })
+
+	t.Run("reads round robin across replicas", func(t *testing.T) {
+		ctx := context.Background()
+		first := cluster.Reader(ctx)
+		second := cluster.Reader(ctx)
+		assert.NotSame(t, primary, first)
+		assert.NotSame(t, primary, second)
+		assert.NotSame(t, first, second)
+	})
+
+	t.Run("reads after a write in the same request use primary", func(t *testing.T) {
+		ctx := repository.WithReadYourWrites(context.Background())
+		assert.NotSame(t, primary, cluster.Reader(ctx))
+
+		assert.Same(t, primary, cluster.Writer(ctx))
+		assert.Same(t, primary, cluster.Reader(ctx))
+
+		// 其他請求不受影響
+		assert.NotSame(t, primary, cluster.Reader(repository.WithReadYourWrites(context.Background())))
+	})
+
+	t.Run("forced primary reads", func(t *testing.T) {
+		ctx := repository.WithPrimaryReads(context.Background())
+		assert.Same(t, primary, cluster.Reader(ctx))
+	})
+
+	t.Run("stats include every pool", func(t *testing.T) {
+		stats := cluster.Stats()
+		assert.Len(t, stats, 3)
+		assert.Contains(t, stats, "primary")
+		assert.Contains(t, stats, "replica_1")
+	})
+
+	t.Run("without replicas reads use primary", func(t *testing.T) {
+		assert.Same(t, primary, NewCluster(primary).Reader(context.Background()))
+	})
+}
@@ -6,15 +6,15 @@ import (
 
 	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 )
 
 type customerRepositoryImpl struct {
-	db *sqlx.DB
+	db *Cluster
 }
 
-func NewCustomerRepository(db *sqlx.DB) repository.CustomerRepository {
+func NewCustomerRepository(db *Cluster) repository.CustomerRepository {
 	return &customerRepositoryImpl{
 		db: db,
 	}
@@ -23,11 +23,11 @@ func NewCustomerRepository(db *sqlx.DB) repository.CustomerRepository {
 func (r *customerRepositoryImpl) Create(ctx context.Context, customer *entity.Customer) error {
 	query := `
 		INSERT INTO customers (id, name, email, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		VALUES (?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(
+	_, err := r.db.Writer(ctx).ExecContext(
 		ctx,
-		query,
+		r.db.Rebind(query),
 		customer.ID,
 		customer.Name,
 		customer.Email,
@@ -43,13 +43,16 @@ func (r *customerRepositoryImpl) GetByID(ctx context.Context, id uuid.UUID) (*en
 	query := `
 		SELECT id, name, email, phone, created_at, updated_at
 		FROM customers
-		WHERE id = $1
+		WHERE id = ?
 	`
-	err := r.db.GetContext(ctx, &customer, query, id)
-	if err == sql.ErrNoRows {
-		return nil, err
+	err := r.db.Reader(ctx).GetContext(ctx, &customer, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("customer not found")
+		}
+		return nil, errors.Wrap(err, "failed to get customer by id")
 	}
-	return &customer, err
+	return &customer, nil
 }
 
 func (r *customerRepositoryImpl) GetByEmail(ctx context.Context, email string) (*entity.Customer, error) {
@@ -57,35 +60,38 @@ func (r *customerRepositoryImpl) GetByEmail(ctx context.Context, email string) (
 	query := `
 		SELECT id, name, email, phone, created_at, updated_at
 		FROM customers
-		WHERE email = $1
+		WHERE email = ?
 	`
-	err := r.db.GetContext(ctx, &customer, query, email)
-	if err == sql.ErrNoRows {
-		return nil, err
+	err := r.db.Reader(ctx).GetContext(ctx, &customer, r.db.Rebind(query), email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("customer not found")
+		}
+		return nil, errors.Wrap(err, "failed to get customer by email")
 	}
-	return &customer, err
+	return &customer, nil
 }
 
 func (r *customerRepositoryImpl) Update(ctx context.Context, customer *entity.Customer) error {
 	query := `
 		UPDATE customers
-		SET name = $2, email = $3, phone = $4, updated_at = $5
-		WHERE id = $1
+		SET name = ?, email = ?, phone = ?, updated_at = ?
+		WHERE id = ?
 	`
-	_, err := r.db.ExecContext(
+	_, err := r.db.Writer(ctx).ExecContext(
 		ctx,
-		query,
-		customer.ID,
+		r.db.Rebind(query),
 		customer.Name,
 		customer.Email,
 		customer.Phone,
 		customer.UpdatedAt,
+		customer.ID,
 	)
 	return err
 }
 
 func (r *customerRepositoryImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	query := `DELETE FROM customers WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	query := `DELETE FROM customers WHERE id = ?`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), id)
 	return err
-}
\ No newline at end of file
+}
@@ -0,0 +1,246 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const disputeColumns = `
+	id, payment_id, merchant_id, amount, currency, reason, status,
+	evidence_due_by, submitted_at, closed_at, created_at, updated_at`
+
+const disputeEvidenceColumns = `
+	id, dispute_id, description, file_name, content_type, size, storage_key, created_at`
+
+const disputeEventColumns = `
+	id, type, dispute_id, merchant_id, payment_id, status, amount, currency, created_at`
+
+const ledgerEntryColumns = `
+	id, merchant_id, payment_id, dispute_id, type, amount, currency, description, created_at`
+
+type disputeRepository struct {
+	db *Cluster
+}
+
+func NewDisputeRepository(db *Cluster) repository.DisputeRepository {
+	return &disputeRepository{db: db}
+}
+
+func (r *disputeRepository) Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO disputes (` + disputeColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		dispute.ID, dispute.PaymentID, dispute.MerchantID, dispute.Amount, dispute.Currency,
+		dispute.Reason, dispute.Status, dispute.EvidenceDueBy, dispute.SubmittedAt,
+		dispute.ClosedAt, dispute.CreatedAt, dispute.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create dispute")
+	}
+	if err := insertDisputeEvent(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit dispute")
+	}
+	return nil
+}
+
+func (r *disputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Dispute, error) {
+	db := r.db.Reader(ctx)
+
+	var dispute entity.Dispute
+	err := db.GetContext(ctx, &dispute, r.db.Rebind(`SELECT `+disputeColumns+` FROM disputes WHERE id = ?`), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("dispute not found")
+		}
+		return nil, errors.Wrap(err, "failed to get dispute by id")
+	}
+
+	query := `
+		SELECT ` + disputeEvidenceColumns + `
+		FROM dispute_evidence
+		WHERE dispute_id = ?
+		ORDER BY created_at, id
+	`
+	if err := db.SelectContext(ctx, &dispute.Evidence, r.db.Rebind(query), id); err != nil {
+		return nil, errors.Wrap(err, "failed to get dispute evidence")
+	}
+	return &dispute, nil
+}
+
+func (r *disputeRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.Dispute, error) {
+	var dispute entity.Dispute
+	query := `SELECT ` + disputeColumns + ` FROM disputes WHERE payment_id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &dispute, r.db.Rebind(query), paymentID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("dispute not found")
+		}
+		return nil, errors.Wrap(err, "failed to get dispute by payment_id")
+	}
+	return &dispute, nil
+}
+
+func (r *disputeRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error) {
+	query := `
+		SELECT ` + disputeColumns + `
+		FROM disputes
+		WHERE merchant_id = ? AND (? = '' OR status = ?)
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var disputes []*entity.Dispute
+	err := r.db.Reader(ctx).SelectContext(ctx, &disputes, r.db.Rebind(query),
+		merchantID, string(status), string(status), limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list disputes")
+	}
+	return disputes, nil
+}
+
+func (r *disputeRepository) AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	// 先更新爭議鎖定該列，避免證據寫入時爭議同時被提交或結案
+	result, err := tx.ExecContext(ctx, tx.Rebind(`UPDATE disputes SET updated_at = ? WHERE id = ? AND status = ?`),
+		evidence.CreatedAt, evidence.DisputeID, entity.DisputeStatusNeedsResponse)
+	if err != nil {
+		return errors.Wrap(err, "failed to lock dispute")
+	}
+	if err := requireAffected(result, "dispute not found or no longer accepting evidence"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO dispute_evidence (` + disputeEvidenceColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		evidence.ID, evidence.DisputeID, evidence.Description, evidence.FileName,
+		evidence.ContentType, evidence.Size, evidence.StorageKey, evidence.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create dispute evidence")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit dispute evidence")
+	}
+	return nil
+}
+
+func (r *disputeRepository) Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE disputes
+		SET status = ?, submitted_at = ?, closed_at = ?, updated_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query),
+		dispute.Status, dispute.SubmittedAt, dispute.ClosedAt, dispute.UpdatedAt, dispute.ID, from)
+	if err != nil {
+		return errors.Wrap(err, "failed to update dispute")
+	}
+	if err := requireAffected(result, "dispute not found or status changed"); err != nil {
+		return err
+	}
+	if err := insertDisputeEvent(ctx, tx, event); err != nil {
+		return err
+	}
+	if reversal != nil {
+		query := `
+			INSERT INTO ledger_entries (` + ledgerEntryColumns + `)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`
+		_, err := tx.ExecContext(ctx, tx.Rebind(query),
+			reversal.ID, reversal.MerchantID, reversal.PaymentID, reversal.DisputeID, reversal.Type,
+			reversal.Amount, reversal.Currency, reversal.Description, reversal.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create ledger entry")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit dispute")
+	}
+	return nil
+}
+
+func (r *disputeRepository) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error) {
+	query := `
+		SELECT ` + disputeEventColumns + `
+		FROM dispute_events
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var events []*entity.DisputeEvent
+	if err := r.db.Reader(ctx).SelectContext(ctx, &events, r.db.Rebind(query), merchantID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to list dispute events")
+	}
+	return events, nil
+}
+
+func insertDisputeEvent(ctx context.Context, tx *sqlx.Tx, event *entity.DisputeEvent) error {
+	query := `
+		INSERT INTO dispute_events (` + disputeEventColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := tx.ExecContext(ctx, tx.Rebind(query),
+		event.ID, event.Type, event.DisputeID, event.MerchantID, event.PaymentID,
+		event.Status, event.Amount, event.Currency, event.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create dispute event")
+	}
+	return nil
+}
+
+type ledgerRepository struct {
+	db *Cluster
+}
+
+func NewLedgerRepository(db *Cluster) repository.LedgerRepository {
+	return &ledgerRepository{db: db}
+}
+
+func (r *ledgerRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error) {
+	query := `
+		SELECT ` + ledgerEntryColumns + `
+		FROM ledger_entries
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var entries []*entity.LedgerEntry
+	if err := r.db.Reader(ctx).SelectContext(ctx, &entries, r.db.Rebind(query), merchantID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to list ledger entries")
+	}
+	return entries, nil
+}
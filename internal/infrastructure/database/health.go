@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/company/payment-service/pkg/health"
+	"github.com/jmoiron/sqlx"
+)
+
+// HealthCheckers 為主庫與每個副本各建立一個 ping 檢查
+func (c *Cluster) HealthCheckers() []health.Checker {
+	checkers := []health.Checker{pingChecker("database.primary", c.primary)}
+	for i, replica := range c.replicas {
+		checkers = append(checkers, pingChecker(fmt.Sprintf("database.replica_%d", i), replica))
+	}
+	return checkers
+}
+
+func pingChecker(name string, db *sqlx.DB) health.Checker {
+	return health.NewChecker(name, db.PingContext)
+}
+
+// MigrationChecker 確認資料庫已套用內嵌遷移腳本的最新版本
+func MigrationChecker(db *sqlx.DB) health.Checker {
+	return health.NewChecker("database.migrations", func(ctx context.Context) error {
+		latest, err := LatestMigrationVersion(db.DriverName())
+		if err != nil {
+			return err
+		}
+
+		var current int64
+		if err := db.GetContext(ctx, &current, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations"); err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if current < latest {
+			return fmt.Errorf("schema version %d is behind expected version %d", current, latest)
+		}
+		return nil
+	})
+}
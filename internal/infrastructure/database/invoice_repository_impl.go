@@ -0,0 +1,155 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const invoiceColumns = `
+	id, merchant_id, customer_id, number, currency, status, memo, subtotal,
+	discount_total, tax_total, total, due_date, payment_id, finalized_at,
+	paid_at, voided_at, created_at, updated_at`
+
+const invoiceLineItemColumns = `
+	id, invoice_id, position, description, quantity, unit_amount, discount,
+	tax_name, tax_rate, amount, tax_amount`
+
+type invoiceRepository struct {
+	db *Cluster
+}
+
+func NewInvoiceRepository(db *Cluster) repository.InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO invoices (` + invoiceColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		invoice.ID, invoice.MerchantID, invoice.CustomerID, invoice.Number, invoice.Currency,
+		invoice.Status, invoice.Memo, invoice.Subtotal, invoice.DiscountTotal, invoice.TaxTotal,
+		invoice.Total, invoice.DueDate, invoice.PaymentID, invoice.FinalizedAt,
+		invoice.PaidAt, invoice.VoidedAt, invoice.CreatedAt, invoice.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create invoice")
+	}
+	if err := insertLineItems(ctx, tx, invoice); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit invoice")
+	}
+	return nil
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Invoice, error) {
+	db := r.db.Reader(ctx)
+
+	var invoice entity.Invoice
+	err := db.GetContext(ctx, &invoice, r.db.Rebind(`SELECT `+invoiceColumns+` FROM invoices WHERE id = ?`), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("invoice not found")
+		}
+		return nil, errors.Wrap(err, "failed to get invoice by id")
+	}
+
+	query := `
+		SELECT ` + invoiceLineItemColumns + `
+		FROM invoice_line_items
+		WHERE invoice_id = ?
+		ORDER BY position
+	`
+	if err := db.SelectContext(ctx, &invoice.LineItems, r.db.Rebind(query), id); err != nil {
+		return nil, errors.Wrap(err, "failed to get invoice line items")
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error) {
+	query := `
+		SELECT ` + invoiceColumns + `
+		FROM invoices
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var invoices []*entity.Invoice
+	err := r.db.Reader(ctx).SelectContext(ctx, &invoices, r.db.Rebind(query), merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get invoices by merchant id")
+	}
+	return invoices, nil
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice *entity.Invoice) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE invoices
+		SET number = ?, currency = ?, status = ?, memo = ?, subtotal = ?, discount_total = ?,
+		    tax_total = ?, total = ?, due_date = ?, payment_id = ?, finalized_at = ?,
+		    paid_at = ?, voided_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := tx.ExecContext(ctx, tx.Rebind(query),
+		invoice.Number, invoice.Currency, invoice.Status, invoice.Memo, invoice.Subtotal,
+		invoice.DiscountTotal, invoice.TaxTotal, invoice.Total, invoice.DueDate, invoice.PaymentID,
+		invoice.FinalizedAt, invoice.PaidAt, invoice.VoidedAt, invoice.UpdatedAt, invoice.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update invoice")
+	}
+	if err := requireAffected(result, "invoice not found"); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM invoice_line_items WHERE invoice_id = ?`), invoice.ID); err != nil {
+		return errors.Wrap(err, "failed to delete invoice line items")
+	}
+	if err := insertLineItems(ctx, tx, invoice); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit invoice")
+	}
+	return nil
+}
+
+func insertLineItems(ctx context.Context, tx *sqlx.Tx, invoice *entity.Invoice) error {
+	query := tx.Rebind(`
+		INSERT INTO invoice_line_items (` + invoiceLineItemColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	for i, item := range invoice.LineItems {
+		_, err := tx.ExecContext(ctx, query,
+			item.ID, invoice.ID, i, item.Description, item.Quantity, item.UnitAmount,
+			item.Discount, item.TaxName, item.TaxRate, item.Amount, item.TaxAmount,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create invoice line item")
+		}
+	}
+	return nil
+}
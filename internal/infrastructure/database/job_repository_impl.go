@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const jobColumns = `
+	id, type, payload, status, attempts, run_at, locked_by, locked_until,
+	last_error, created_at, updated_at`
+
+type jobRepository struct {
+	db *Cluster
+}
+
+func NewJobRepository(db *Cluster) repository.JobRepository {
+	return &jobRepository{db: db}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.Job) error {
+	query := `
+		INSERT INTO jobs (` + jobColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		job.ID, job.Type, job.Payload, job.Status, job.Attempts, job.RunAt,
+		job.LockedBy, job.LockedUntil, job.LastError, job.CreatedAt, job.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to enqueue job")
+	}
+	return nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	var job entity.Job
+	query := `SELECT ` + jobColumns + ` FROM jobs WHERE id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &job, r.db.Rebind(query), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("job not found")
+		}
+		return nil, errors.Wrap(err, "failed to get job by id")
+	}
+	return &job, nil
+}
+
+func (r *jobRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]*entity.Job, error) {
+	// 以單一 UPDATE 取得工作並寫入這次取得的識別碼，再以識別碼讀回。
+	// PostgreSQL 以 SKIP LOCKED 讓並行的 worker 略過彼此鎖定的資料列；
+	// SQLite 的寫入本身即為序列化，不需要也不支援資料列鎖
+	lock := ""
+	if r.db.Primary().DriverName() == "postgres" {
+		lock = "FOR UPDATE SKIP LOCKED"
+	}
+	token := uuid.New().String()
+	query := `
+		UPDATE jobs
+		SET status = ?, attempts = attempts + 1, locked_by = ?, locked_until = ?, updated_at = ?
+		WHERE id IN (
+			SELECT id FROM jobs
+			WHERE (status = ? AND run_at <= ?) OR (status = ? AND locked_until <= ?)
+			ORDER BY run_at
+			LIMIT ?
+			` + lock + `
+		)
+	`
+	db := r.db.Writer(ctx)
+	result, err := db.ExecContext(ctx, r.db.Rebind(query),
+		entity.JobStatusRunning, token, now.Add(visibility), now,
+		entity.JobStatusQueued, now, entity.JobStatusRunning, now, limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to claim jobs")
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, errors.Wrap(err, "failed to get affected rows")
+	} else if n == 0 {
+		return nil, nil
+	}
+
+	var jobs []*entity.Job
+	query = `SELECT ` + jobColumns + ` FROM jobs WHERE locked_by = ? ORDER BY run_at`
+	if err := db.SelectContext(ctx, &jobs, r.db.Rebind(query), token); err != nil {
+		return nil, errors.Wrap(err, "failed to get claimed jobs")
+	}
+	return jobs, nil
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, attempts int) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, locked_until = NULL, last_error = '', updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.JobStatusSucceeded, time.Now(), id, entity.JobStatusRunning, attempts)
+	if err != nil {
+		return errors.Wrap(err, "failed to complete job")
+	}
+	return requireAffected(result, "job not found or lock lost")
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, run_at = ?, locked_until = NULL, last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.JobStatusQueued, runAt, lastError, time.Now(), id, entity.JobStatusRunning, attempts)
+	if err != nil {
+		return errors.Wrap(err, "failed to retry job")
+	}
+	return requireAffected(result, "job not found or lock lost")
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error {
+	query := `
+		UPDATE jobs
+		SET status = ?, locked_until = NULL, last_error = ?, updated_at = ?
+		WHERE id = ? AND status = ? AND attempts = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.JobStatusFailed, lastError, time.Now(), id, entity.JobStatusRunning, attempts)
+	if err != nil {
+		return errors.Wrap(err, "failed to fail job")
+	}
+	return requireAffected(result, "job not found or lock lost")
+}
@@ -0,0 +1,194 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const merchantLimitColumns = `
+	id, merchant_id, method, currency, max_amount, daily_amount, daily_count,
+	created_at, updated_at`
+
+type merchantLimitRepository struct {
+	db *Cluster
+}
+
+func NewMerchantLimitRepository(db *Cluster) repository.MerchantLimitRepository {
+	return &merchantLimitRepository{db: db}
+}
+
+func (r *merchantLimitRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimit, error) {
+	query := `SELECT ` + merchantLimitColumns + ` FROM merchant_limits WHERE merchant_id = ? ORDER BY currency, method`
+	var limits []*entity.MerchantLimit
+	if err := r.db.Reader(ctx).SelectContext(ctx, &limits, r.db.Rebind(query), merchantID); err != nil {
+		return nil, errors.Wrap(err, "failed to list merchant limits")
+	}
+	return limits, nil
+}
+
+func (r *merchantLimitRepository) Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`DELETE FROM merchant_limits WHERE merchant_id = ?`), merchantID); err != nil {
+		return errors.Wrap(err, "failed to delete merchant limits")
+	}
+	query := `
+		INSERT INTO merchant_limits (` + merchantLimitColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	for _, limit := range limits {
+		_, err := tx.ExecContext(ctx, tx.Rebind(query),
+			limit.ID, merchantID, limit.Method, limit.Currency, limit.MaxAmount,
+			limit.DailyAmount, limit.DailyCount, limit.CreatedAt, limit.UpdatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create merchant limit")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit merchant limits")
+	}
+	return nil
+}
+
+func (r *merchantLimitRepository) Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (*entity.MerchantLimit, error) {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	for _, limit := range limits {
+		if err := ensureLimitUsage(ctx, tx, limit, day, now); err != nil {
+			return nil, err
+		}
+		// 以條件更新累加，並行的付款不會讓用量超過上限
+		query := `
+			UPDATE merchant_limit_usage SET amount = amount + ?, count = count + 1, updated_at = ?
+			WHERE merchant_id = ? AND method = ? AND currency = ? AND day = ?
+		`
+		args := []interface{}{amount, now, limit.MerchantID, limit.Method, limit.Currency, day}
+		if limit.DailyAmount > 0 {
+			query += ` AND amount + ? <= ?`
+			args = append(args, amount, limit.DailyAmount)
+		}
+		if limit.DailyCount > 0 {
+			query += ` AND count + 1 <= ?`
+			args = append(args, limit.DailyCount)
+		}
+		result, err := tx.ExecContext(ctx, tx.Rebind(query), args...)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to update limit usage")
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get rows affected")
+		}
+		if rowsAffected == 0 {
+			return limit, nil
+		}
+
+		reservation := `
+			INSERT INTO merchant_limit_reservations (payment_id, merchant_id, method, currency, day, amount, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`
+		if _, err := tx.ExecContext(ctx, tx.Rebind(reservation), paymentID, limit.MerchantID, limit.Method, limit.Currency, day, amount, now); err != nil {
+			return nil, errors.Wrap(err, "failed to create limit reservation")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "failed to commit limit usage")
+	}
+	return nil, nil
+}
+
+// ensureLimitUsage 建立當日的用量列，已存在時不做任何變更
+func ensureLimitUsage(ctx context.Context, tx *sqlx.Tx, limit *entity.MerchantLimit, day string, now time.Time) error {
+	query := `
+		INSERT INTO merchant_limit_usage (merchant_id, method, currency, day, amount, count, updated_at)
+		VALUES (?, ?, ?, ?, 0, 0, ?)
+		ON CONFLICT (merchant_id, method, currency, day) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(query), limit.MerchantID, limit.Method, limit.Currency, day, now); err != nil {
+		return errors.Wrap(err, "failed to create limit usage")
+	}
+	return nil
+}
+
+// limitReservation 對應 merchant_limit_reservations 的一列
+type limitReservation struct {
+	MerchantID uuid.UUID            `db:"merchant_id"`
+	Method     entity.PaymentMethod `db:"method"`
+	Currency   string               `db:"currency"`
+	Day        string               `db:"day"`
+	Amount     int64                `db:"amount"`
+}
+
+func (r *merchantLimitRepository) Release(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	var reservations []limitReservation
+	query := `SELECT merchant_id, method, currency, day, amount FROM merchant_limit_reservations WHERE payment_id = ?`
+	if err := tx.SelectContext(ctx, &reservations, tx.Rebind(query), paymentID); err != nil {
+		return errors.Wrap(err, "failed to get limit reservations")
+	}
+	for _, res := range reservations {
+		// 先刪除記錄，並行的扣回只有刪除成功的一方會扣減用量
+		result, err := tx.ExecContext(ctx, tx.Rebind(`
+			DELETE FROM merchant_limit_reservations WHERE payment_id = ? AND method = ? AND currency = ?
+		`), paymentID, res.Method, res.Currency)
+		if err != nil {
+			return errors.Wrap(err, "failed to delete limit reservation")
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "failed to get rows affected")
+		}
+		if rowsAffected == 0 {
+			continue
+		}
+		_, err = tx.ExecContext(ctx, tx.Rebind(`
+			UPDATE merchant_limit_usage SET amount = amount - ?, count = count - 1, updated_at = ?
+			WHERE merchant_id = ? AND method = ? AND currency = ? AND day = ? AND count > 0
+		`), res.Amount, now, res.MerchantID, res.Method, res.Currency, res.Day)
+		if err != nil {
+			return errors.Wrap(err, "failed to release limit usage")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit limit usage")
+	}
+	return nil
+}
+
+// GetUsage 讀取主庫，用量在付款建立時持續變動
+func (r *merchantLimitRepository) GetUsage(ctx context.Context, merchantID uuid.UUID, day string) ([]*entity.LimitUsage, error) {
+	query := `
+		SELECT merchant_id, method, currency, day, amount, count, updated_at
+		FROM merchant_limit_usage
+		WHERE merchant_id = ? AND day = ?
+		ORDER BY currency, method
+	`
+	var usage []*entity.LimitUsage
+	if err := r.db.Primary().SelectContext(ctx, &usage, r.db.Rebind(query), merchantID, day); err != nil {
+		return nil, errors.Wrap(err, "failed to get limit usage")
+	}
+	return usage, nil
+}
@@ -9,25 +9,29 @@ import (
 	"github.com/company/payment-service/internal/domain/repository"
 	"github.com/company/payment-service/pkg/errors"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 )
 
+// merchantColumns 中未發行的 publishable key 為 NULL（唯一索引允許多筆），讀取時轉為空字串
+const merchantColumns = `id, name, email, api_key, is_active, tier,
+		COALESCE(publishable_key, '') AS publishable_key, allowed_origins, signing_secret, created_at, updated_at`
+
 type merchantRepository struct {
-	db *sqlx.DB
+	db *Cluster
 }
 
-func NewMerchantRepository(db *sqlx.DB) repository.MerchantRepository {
+func NewMerchantRepository(db *Cluster) repository.MerchantRepository {
 	return &merchantRepository{db: db}
 }
 
 func (r *merchantRepository) Create(ctx context.Context, merchant *entity.Merchant) error {
 	query := `
-		INSERT INTO merchants (id, name, email, api_key, is_active, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		INSERT INTO merchants (id, name, email, api_key, is_active, tier, publishable_key, allowed_origins, signing_secret, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NULLIF(?, ''), ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
 		merchant.ID, merchant.Name, merchant.Email, merchant.APIKey,
-		merchant.IsActive, merchant.CreatedAt, merchant.UpdatedAt,
+		merchant.IsActive, merchantTier(merchant), merchant.PublishableKey, merchant.AllowedOrigins,
+		merchant.SigningSecret, merchant.CreatedAt, merchant.UpdatedAt,
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to create merchant")
@@ -37,11 +41,11 @@ func (r *merchantRepository) Create(ctx context.Context, merchant *entity.Mercha
 
 func (r *merchantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Merchant, error) {
 	query := `
-		SELECT id, name, email, api_key, is_active, created_at, updated_at
-		FROM merchants WHERE id = $1
+		SELECT ` + merchantColumns + `
+		FROM merchants WHERE id = ?
 	`
 	var merchant entity.Merchant
-	err := r.db.GetContext(ctx, &merchant, query, id)
+	err := r.db.Reader(ctx).GetContext(ctx, &merchant, r.db.Rebind(query), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("merchant not found")
@@ -53,11 +57,11 @@ func (r *merchantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity
 
 func (r *merchantRepository) GetByAPIKey(ctx context.Context, apiKey string) (*entity.Merchant, error) {
 	query := `
-		SELECT id, name, email, api_key, is_active, created_at, updated_at
-		FROM merchants WHERE api_key = $1
+		SELECT ` + merchantColumns + `
+		FROM merchants WHERE api_key = ?
 	`
 	var merchant entity.Merchant
-	err := r.db.GetContext(ctx, &merchant, query, apiKey)
+	err := r.db.Reader(ctx).GetContext(ctx, &merchant, r.db.Rebind(query), apiKey)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("merchant not found")
@@ -67,16 +71,34 @@ func (r *merchantRepository) GetByAPIKey(ctx context.Context, apiKey string) (*e
 	return &merchant, nil
 }
 
+func (r *merchantRepository) GetByPublishableKey(ctx context.Context, publishableKey string) (*entity.Merchant, error) {
+	query := `
+		SELECT ` + merchantColumns + `
+		FROM merchants WHERE publishable_key = ?
+	`
+	var merchant entity.Merchant
+	err := r.db.Reader(ctx).GetContext(ctx, &merchant, r.db.Rebind(query), publishableKey)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("merchant not found")
+		}
+		return nil, errors.Wrap(err, "failed to get merchant by publishable key")
+	}
+	return &merchant, nil
+}
+
 func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Merchant) error {
 	merchant.UpdatedAt = time.Now()
 	query := `
 		UPDATE merchants
-		SET name = $1, email = $2, api_key = $3, is_active = $4, updated_at = $5
-		WHERE id = $6
+		SET name = ?, email = ?, api_key = ?, is_active = ?, tier = ?,
+			publishable_key = NULLIF(?, ''), allowed_origins = ?, signing_secret = ?, updated_at = ?
+		WHERE id = ?
 	`
-	result, err := r.db.ExecContext(ctx, query,
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
 		merchant.Name, merchant.Email, merchant.APIKey, merchant.IsActive,
-		merchant.UpdatedAt, merchant.ID,
+		merchantTier(merchant), merchant.PublishableKey, merchant.AllowedOrigins,
+		merchant.SigningSecret, merchant.UpdatedAt, merchant.ID,
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to update merchant")
@@ -94,8 +116,8 @@ func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Mercha
 }
 
 func (r *merchantRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	query := "UPDATE merchants SET is_active = false, updated_at = $1 WHERE id = $2"
-	result, err := r.db.ExecContext(ctx, query, time.Now(), id)
+	query := "UPDATE merchants SET is_active = false, updated_at = ? WHERE id = ?"
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), time.Now(), id)
 	if err != nil {
 		return errors.Wrap(err, "failed to delete merchant")
 	}
@@ -109,4 +131,12 @@ func (r *merchantRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// merchantTier 讓未指定等級的商戶使用預設等級
+func merchantTier(merchant *entity.Merchant) string {
+	if merchant.Tier == "" {
+		return entity.MerchantTierStandard
+	}
+	return merchant.Tier
+}
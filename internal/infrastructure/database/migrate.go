@@ -0,0 +1,210 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/scripts/migrations"
+	"github.com/jmoiron/sqlx"
+)
+
+// 驅動名稱對應到 scripts/migrations 下的方言目錄
+var migrationDialects = map[string]string{
+	"postgres": "postgres",
+	"sqlite3":  "sqlite",
+}
+
+// baselineVersion 為 schema_migrations 出現前既有資料庫的 schema 版本
+const baselineVersion = 1
+
+// migrationLockKey 為 PostgreSQL advisory lock 的鍵，同時啟動的實例依序執行遷移
+const migrationLockKey int64 = 7_372_019_027
+
+type migration struct {
+	version int64
+	name    string
+	sql     string
+}
+
+// Migrate 依版本順序執行尚未套用的遷移腳本，已套用的版本記錄在 schema_migrations。
+// PostgreSQL 在檢查與套用期間持有 advisory lock，後取得鎖的實例會看到已套用的版本
+func Migrate(ctx context.Context, db *sqlx.DB) (err error) {
+	pending, err := loadMigrations(db.DriverName())
+	if err != nil {
+		return err
+	}
+
+	if db.DriverName() == "postgres" {
+		unlock, err := lockMigrations(ctx, db)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if unlockErr := unlock(); unlockErr != nil && err == nil {
+				err = unlockErr
+			}
+		}()
+	}
+
+	baseline, err := needsBaseline(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		)
+	`); err != nil {
+		return errors.Wrap(err, "failed to create schema_migrations table")
+	}
+	// 在引入 schema_migrations 之前以 psql 建立的資料庫已有初始 schema，直接記錄 001 為已套用
+	if baseline {
+		query := db.Rebind("INSERT INTO schema_migrations (version) VALUES (?) ON CONFLICT (version) DO NOTHING")
+		if _, err := db.ExecContext(ctx, query, baselineVersion); err != nil {
+			return errors.Wrap(err, "failed to record baseline migration")
+		}
+	}
+
+	var versions []int64
+	if err := db.SelectContext(ctx, &versions, "SELECT version FROM schema_migrations"); err != nil {
+		return errors.Wrap(err, "failed to read applied migrations")
+	}
+	applied := make(map[int64]bool, len(versions))
+	for _, v := range versions {
+		applied[v] = true
+	}
+
+	for _, m := range pending {
+		if applied[m.version] {
+			continue
+		}
+		if err := applyMigration(ctx, db, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lockMigrations 在專用連線上取得 session 層級的 advisory lock，回傳的函式釋放鎖並歸還連線
+func lockMigrations(ctx context.Context, db *sqlx.DB) (func() error, error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get migration connection")
+	}
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", migrationLockKey); err != nil {
+		conn.Close()
+		return nil, errors.Wrap(err, "failed to acquire migration lock")
+	}
+	return func() error {
+		// ctx 取消時仍要釋放鎖，否則連線回到連線池後會一直持有
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", migrationLockKey)
+		if err != nil {
+			// 無法確認已釋放時丟棄連線，結束 session 即釋放鎖
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+			conn.Close()
+			return errors.Wrap(err, "failed to release migration lock")
+		}
+		return conn.Close()
+	}, nil
+}
+
+// LatestMigrationVersion 回傳指定驅動內嵌遷移腳本的最新版本號
+func LatestMigrationVersion(driverName string) (int64, error) {
+	all, err := loadMigrations(driverName)
+	if err != nil {
+		return 0, err
+	}
+	if len(all) == 0 {
+		return 0, nil
+	}
+	return all[len(all)-1].version, nil
+}
+
+// needsBaseline 回傳資料庫是否已有 payments 資料表但沒有 schema_migrations
+func needsBaseline(ctx context.Context, db *sqlx.DB) (bool, error) {
+	hasPayments, err := tableExists(ctx, db, "payments")
+	if err != nil || !hasPayments {
+		return false, err
+	}
+	hasMigrations, err := tableExists(ctx, db, "schema_migrations")
+	if err != nil {
+		return false, err
+	}
+	return !hasMigrations, nil
+}
+
+func tableExists(ctx context.Context, db *sqlx.DB, table string) (bool, error) {
+	query := "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?"
+	if db.DriverName() == "sqlite3" {
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	}
+	var count int
+	if err := db.GetContext(ctx, &count, db.Rebind(query), table); err != nil {
+		return false, errors.Wrap(err, "failed to inspect table "+table)
+	}
+	return count > 0, nil
+}
+
+func applyMigration(ctx context.Context, db *sqlx.DB, m migration) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin migration "+m.name)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+		return errors.Wrap(err, "failed to apply migration "+m.name)
+	}
+	// 腳本本身也會寫入版本（方便直接用 psql 執行），這裡以 ON CONFLICT 確保不重複
+	query := tx.Rebind("INSERT INTO schema_migrations (version) VALUES (?) ON CONFLICT (version) DO NOTHING")
+	if _, err := tx.ExecContext(ctx, query, m.version); err != nil {
+		return errors.Wrap(err, "failed to record migration "+m.name)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit migration "+m.name)
+	}
+	return nil
+}
+
+func loadMigrations(driverName string) ([]migration, error) {
+	dir, ok := migrationDialects[driverName]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("no migrations for driver %q", driverName))
+	}
+
+	entries, err := fs.ReadDir(migrations.FS, dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read migrations")
+	}
+
+	var result []migration
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".sql") {
+			continue
+		}
+		prefix, _, _ := strings.Cut(name, "_")
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid migration file name "+name)
+		}
+		content, err := fs.ReadFile(migrations.FS, path.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to read migration "+name)
+		}
+		result = append(result, migration{version: version, name: name, sql: string(content)})
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].version < result[j].version })
+	return result, nil
+}
@@ -0,0 +1,41 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+type nonceRepository struct {
+	db *Cluster
+}
+
+func NewNonceRepository(db *Cluster) repository.NonceRepository {
+	return &nonceRepository{db: db}
+}
+
+// Use 先刪除已到期的 nonce，再以主鍵衝突判斷 nonce 是否已使用，
+// 多個實例同時寫入同一 nonce 時只有一個成功
+func (r *nonceRepository) Use(ctx context.Context, key string, expiresAt, now time.Time) (bool, error) {
+	purge := `DELETE FROM request_nonces WHERE expires_at <= ?`
+	if _, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(purge), now); err != nil {
+		return false, errors.Wrap(err, "failed to purge expired nonces")
+	}
+
+	insert := `
+		INSERT INTO request_nonces (nonce_key, expires_at)
+		VALUES (?, ?)
+		ON CONFLICT (nonce_key) DO NOTHING
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(insert), key, expiresAt)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to record nonce")
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get affected rows")
+	}
+	return rowsAffected == 1, nil
+}
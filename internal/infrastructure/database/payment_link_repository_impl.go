@@ -0,0 +1,152 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const paymentLinkColumns = `
+	id, merchant_id, name, description, amount, currency, single_use,
+	quantity_limit, payment_count, allowed_methods, success_url, is_active,
+	expires_at, created_at, updated_at`
+
+// paymentLinkRow 以逗號分隔字串保存付款方式清單
+type paymentLinkRow struct {
+	entity.PaymentLink
+	AllowedMethods string `db:"allowed_methods"`
+}
+
+func (row *paymentLinkRow) toEntity() *entity.PaymentLink {
+	link := row.PaymentLink
+	link.AllowedMethods = splitPaymentMethods(row.AllowedMethods)
+	return &link
+}
+
+type paymentLinkRepository struct {
+	db *Cluster
+}
+
+func NewPaymentLinkRepository(db *Cluster) repository.PaymentLinkRepository {
+	return &paymentLinkRepository{db: db}
+}
+
+func (r *paymentLinkRepository) Create(ctx context.Context, link *entity.PaymentLink) error {
+	query := `
+		INSERT INTO payment_links (` + paymentLinkColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		link.ID, link.MerchantID, link.Name, link.Description, link.Amount,
+		link.Currency, link.SingleUse, link.QuantityLimit, link.PaymentCount,
+		joinPaymentMethods(link.AllowedMethods), link.SuccessURL, link.IsActive,
+		link.ExpiresAt, link.CreatedAt, link.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create payment link")
+	}
+	return nil
+}
+
+func (r *paymentLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentLink, error) {
+	var row paymentLinkRow
+	query := `SELECT ` + paymentLinkColumns + ` FROM payment_links WHERE id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &row, r.db.Rebind(query), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment link not found")
+		}
+		return nil, errors.Wrap(err, "failed to get payment link by id")
+	}
+	return row.toEntity(), nil
+}
+
+func (r *paymentLinkRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error) {
+	query := `
+		SELECT ` + paymentLinkColumns + `
+		FROM payment_links
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var rows []paymentLinkRow
+	if err := r.db.Reader(ctx).SelectContext(ctx, &rows, r.db.Rebind(query), merchantID, limit, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to get payment links by merchant id")
+	}
+
+	links := make([]*entity.PaymentLink, len(rows))
+	for i := range rows {
+		links[i] = rows[i].toEntity()
+	}
+	return links, nil
+}
+
+func (r *paymentLinkRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	query := `UPDATE payment_links SET is_active = ?, updated_at = ? WHERE id = ?`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), active, time.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, "failed to update payment link")
+	}
+	return requireAffected(result, "payment link not found")
+}
+
+func (r *paymentLinkRepository) ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) error {
+	// 以條件更新檢查可用性，並行結帳時只有未超過上限的請求會成功
+	query := `
+		UPDATE payment_links
+		SET payment_count = payment_count + 1, updated_at = ?
+		WHERE id = ? AND is_active = ?
+		  AND (quantity_limit IS NULL OR payment_count < quantity_limit)
+		  AND (expires_at IS NULL OR expires_at > ?)
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), now, id, true, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to claim payment link")
+	}
+	return requireAffected(result, "payment link not found or no longer available")
+}
+
+func (r *paymentLinkRepository) ReleasePayment(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE payment_links
+		SET payment_count = payment_count - 1, updated_at = ?
+		WHERE id = ? AND payment_count > 0
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), time.Now(), id)
+	if err != nil {
+		return errors.Wrap(err, "failed to release payment link")
+	}
+	return requireAffected(result, "payment link not found or has no claimed payments")
+}
+
+func (r *paymentLinkRepository) GetStats(ctx context.Context, id uuid.UUID) (*entity.PaymentLinkStats, error) {
+	db := r.db.Reader(ctx)
+
+	var currency string
+	err := db.GetContext(ctx, &currency, r.db.Rebind(`SELECT currency FROM payment_links WHERE id = ?`), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment link not found")
+		}
+		return nil, errors.Wrap(err, "failed to get payment link by id")
+	}
+
+	query := `
+		SELECT COUNT(*) AS payments,
+		       COUNT(CASE WHEN status = ? THEN 1 END) AS completed,
+		       COALESCE(SUM(CASE WHEN status = ? THEN amount ELSE 0 END), 0) AS amount_collected
+		FROM payments
+		WHERE payment_link_id = ?
+	`
+	stats := entity.PaymentLinkStats{PaymentLinkID: id, Currency: currency}
+	err = db.GetContext(ctx, &stats, r.db.Rebind(query),
+		entity.PaymentStatusCompleted, entity.PaymentStatusCompleted, id)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get payment link stats")
+	}
+	return &stats, nil
+}
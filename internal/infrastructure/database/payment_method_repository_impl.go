@@ -0,0 +1,146 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const paymentMethodColumns = `
+	id, customer_id, merchant_id, type, token, last4, brand, exp_month, exp_year,
+	billing_name, billing_email, billing_phone, billing_line1, billing_line2,
+	billing_city, billing_postal_code, billing_country, is_default, created_at, updated_at`
+
+type paymentMethodRepository struct {
+	db *Cluster
+}
+
+func NewPaymentMethodRepository(db *Cluster) repository.PaymentMethodRepository {
+	return &paymentMethodRepository{db: db}
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	query := `
+		INSERT INTO payment_methods (` + paymentMethodColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		method.ID, method.CustomerID, method.MerchantID, method.Type, method.Token,
+		method.Last4, method.Brand, method.ExpMonth, method.ExpYear,
+		method.BillingDetails.Name, method.BillingDetails.Email, method.BillingDetails.Phone,
+		method.BillingDetails.Line1, method.BillingDetails.Line2, method.BillingDetails.City,
+		method.BillingDetails.PostalCode, method.BillingDetails.Country,
+		method.IsDefault, method.CreatedAt, method.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create payment method")
+	}
+	return nil
+}
+
+func (r *paymentMethodRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentMethodRecord, error) {
+	query := `SELECT ` + paymentMethodColumns + ` FROM payment_methods WHERE id = ?`
+
+	var method entity.PaymentMethodRecord
+	err := r.db.Reader(ctx).GetContext(ctx, &method, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("payment method not found")
+		}
+		return nil, errors.Wrap(err, "failed to get payment method by id")
+	}
+	return &method, nil
+}
+
+func (r *paymentMethodRepository) ListByCustomerID(ctx context.Context, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error) {
+	query := `
+		SELECT ` + paymentMethodColumns + `
+		FROM payment_methods
+		WHERE customer_id = ?
+		ORDER BY is_default DESC, created_at DESC
+	`
+	var methods []*entity.PaymentMethodRecord
+	err := r.db.Reader(ctx).SelectContext(ctx, &methods, r.db.Rebind(query), customerID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list payment methods")
+	}
+	return methods, nil
+}
+
+func (r *paymentMethodRepository) Update(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	query := `
+		UPDATE payment_methods
+		SET exp_month = ?, exp_year = ?, billing_name = ?, billing_email = ?, billing_phone = ?,
+		    billing_line1 = ?, billing_line2 = ?, billing_city = ?, billing_postal_code = ?,
+		    billing_country = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		method.ExpMonth, method.ExpYear, method.BillingDetails.Name, method.BillingDetails.Email,
+		method.BillingDetails.Phone, method.BillingDetails.Line1, method.BillingDetails.Line2,
+		method.BillingDetails.City, method.BillingDetails.PostalCode, method.BillingDetails.Country,
+		method.UpdatedAt, method.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update payment method")
+	}
+	return requireAffected(result, "payment method not found")
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(`DELETE FROM payment_methods WHERE id = ?`), id)
+	if err != nil {
+		return errors.Wrap(err, "failed to delete payment method")
+	}
+	return requireAffected(result, "payment method not found")
+}
+
+func (r *paymentMethodRepository) SetDefault(ctx context.Context, customerID, id uuid.UUID) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	// 先清除再設定，避免違反每位客戶只有一個預設的唯一索引
+	if _, err := tx.ExecContext(ctx, tx.Rebind(`
+		UPDATE payment_methods SET is_default = ?, updated_at = ?
+		WHERE customer_id = ? AND is_default = ?
+	`), false, now, customerID, true); err != nil {
+		return errors.Wrap(err, "failed to clear default payment method")
+	}
+
+	result, err := tx.ExecContext(ctx, tx.Rebind(`
+		UPDATE payment_methods SET is_default = ?, updated_at = ?
+		WHERE id = ? AND customer_id = ?
+	`), true, now, id, customerID)
+	if err != nil {
+		return errors.Wrap(err, "failed to set default payment method")
+	}
+	if err := requireAffected(result, "payment method not found"); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit default payment method")
+	}
+	return nil
+}
+
+// requireAffected 在沒有任何資料列被更新時回傳 notFound 錯誤
+func requireAffected(result sql.Result, notFound string) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "failed to get affected rows")
+	}
+	if rowsAffected == 0 {
+		return errors.New(notFound)
+	}
+	return nil
+}
@@ -8,27 +8,36 @@ import (
 	"github.com/company/payment-service/internal/domain/entity"
 	"github.com/company/payment-service/internal/domain/repository"
 	"github.com/company/payment-service/pkg/errors"
+	"github.com/company/payment-service/pkg/logger"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
 )
 
+// paymentColumns 為讀取付款時選取的欄位
+const paymentColumns = `id, merchant_id, customer_id, amount, currency, method, status,
+	description, COALESCE(reference, '') AS reference, payment_method_token, payment_method_id, invoice_id, payment_link_id,
+	risk_score, risk_decision, risk_reasons, client_ip, created_at, updated_at, completed_at`
+
 type paymentRepository struct {
-	db *sqlx.DB
+	db *Cluster
 }
 
-func NewPaymentRepository(db *sqlx.DB) repository.PaymentRepository {
+func NewPaymentRepository(db *Cluster) repository.PaymentRepository {
 	return &paymentRepository{db: db}
 }
 
 func (r *paymentRepository) Create(ctx context.Context, payment *entity.Payment) error {
 	query := `
-		INSERT INTO payments (id, merchant_id, customer_id, amount, currency, method, status, description, reference, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		INSERT INTO payments (id, merchant_id, customer_id, amount, currency, method, status, description, reference, payment_method_token, payment_method_id, invoice_id, payment_link_id,
+		                      risk_score, risk_decision, risk_reasons, client_ip, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
-	_, err := r.db.ExecContext(ctx, query,
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
 		payment.ID, payment.MerchantID, payment.CustomerID, payment.Amount,
 		payment.Currency, payment.Method, payment.Status, payment.Description,
-		payment.Reference, payment.CreatedAt, payment.UpdatedAt,
+		nullableReference(payment.Reference), payment.PaymentMethodToken, payment.PaymentMethodID, payment.InvoiceID,
+		payment.PaymentLinkID, payment.RiskScore, payment.RiskDecision, payment.RiskReasons, payment.ClientIP,
+		payment.CreatedAt, payment.UpdatedAt,
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to create payment")
@@ -36,14 +45,21 @@ func (r *paymentRepository) Create(ctx context.Context, payment *entity.Payment)
 	return nil
 }
 
+// nullableReference 將空的 reference 存為 NULL，未指定 reference 的付款才不會違反唯一鍵
+func nullableReference(reference string) *string {
+	if reference == "" {
+		return nil
+	}
+	return &reference
+}
+
 func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
 	query := `
-		SELECT id, merchant_id, customer_id, amount, currency, method, status,
-		       description, reference, created_at, updated_at, completed_at
-		FROM payments WHERE id = $1
+		SELECT ` + paymentColumns + `
+		FROM payments WHERE id = ?
 	`
 	var payment entity.Payment
-	err := r.db.GetContext(ctx, &payment, query, id)
+	err := r.db.Reader(ctx).GetContext(ctx, &payment, r.db.Rebind(query), id)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("payment not found")
@@ -55,12 +71,11 @@ func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.
 
 func (r *paymentRepository) GetByReference(ctx context.Context, reference string) (*entity.Payment, error) {
 	query := `
-		SELECT id, merchant_id, customer_id, amount, currency, method, status,
-		       description, reference, created_at, updated_at, completed_at
-		FROM payments WHERE reference = $1
+		SELECT ` + paymentColumns + `
+		FROM payments WHERE reference = ?
 	`
 	var payment entity.Payment
-	err := r.db.GetContext(ctx, &payment, query, reference)
+	err := r.db.Reader(ctx).GetContext(ctx, &payment, r.db.Rebind(query), reference)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, errors.New("payment not found")
@@ -79,10 +94,10 @@ func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 
 	query := `
 		UPDATE payments
-		SET status = $1, updated_at = $2, completed_at = $3
-		WHERE id = $4
+		SET status = ?, updated_at = ?, completed_at = ?
+		WHERE id = ?
 	`
-	result, err := r.db.ExecContext(ctx, query, status, time.Now(), completedAt, id)
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), status, time.Now(), completedAt, id)
 	if err != nil {
 		return errors.Wrap(err, "failed to update payment status")
 	}
@@ -95,20 +110,42 @@ func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, stat
 		return errors.New("payment not found")
 	}
 
+	logger.FromContext(ctx).Debug("payment status updated",
+		zap.String("payment_id", id.String()),
+		zap.String("status", string(status)),
+	)
 	return nil
 }
 
+func (r *paymentRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) error {
+	now := time.Now()
+	var completedAt *time.Time
+	if to == entity.PaymentStatusCompleted {
+		completedAt = &now
+	}
+
+	query := `
+		UPDATE payments
+		SET status = ?, updated_at = ?, completed_at = ?
+		WHERE id = ? AND status = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query), to, now, completedAt, id, from)
+	if err != nil {
+		return errors.Wrap(err, "failed to transition payment status")
+	}
+	return requireAffected(result, "payment not found or status changed")
+}
+
 func (r *paymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
 	query := `
-		SELECT id, merchant_id, customer_id, amount, currency, method, status,
-		       description, reference, created_at, updated_at, completed_at
+		SELECT ` + paymentColumns + `
 		FROM payments
-		WHERE merchant_id = $1
+		WHERE merchant_id = ?
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT ? OFFSET ?
 	`
 	var payments []*entity.Payment
-	err := r.db.SelectContext(ctx, &payments, query, merchantID, limit, offset)
+	err := r.db.Reader(ctx).SelectContext(ctx, &payments, r.db.Rebind(query), merchantID, limit, offset)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get payments by merchant id")
 	}
@@ -117,17 +154,48 @@ func (r *paymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid
 
 func (r *paymentRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
 	query := `
-		SELECT id, merchant_id, customer_id, amount, currency, method, status,
-		       description, reference, created_at, updated_at, completed_at
+		SELECT ` + paymentColumns + `
 		FROM payments
-		WHERE customer_id = $1
+		WHERE customer_id = ?
 		ORDER BY created_at DESC
-		LIMIT $2 OFFSET $3
+		LIMIT ? OFFSET ?
 	`
 	var payments []*entity.Payment
-	err := r.db.SelectContext(ctx, &payments, query, customerID, limit, offset)
+	err := r.db.Reader(ctx).SelectContext(ctx, &payments, r.db.Rebind(query), customerID, limit, offset)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get payments by customer id")
 	}
 	return payments, nil
-}
\ No newline at end of file
+}
+
+func (r *paymentRepository) GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE payment_link_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var payments []*entity.Payment
+	err := r.db.Reader(ctx).SelectContext(ctx, &payments, r.db.Rebind(query), linkID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get payments by payment link id")
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.Payment, error) {
+	query := `
+		SELECT ` + paymentColumns + `
+		FROM payments
+		WHERE status = ? AND completed_at >= ? AND completed_at < ?
+		ORDER BY completed_at, id
+		LIMIT ? OFFSET ?
+	`
+	var payments []*entity.Payment
+	err := r.db.Reader(ctx).SelectContext(ctx, &payments, r.db.Rebind(query), entity.PaymentStatusCompleted, from, to, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get completed payments")
+	}
+	return payments, nil
+}
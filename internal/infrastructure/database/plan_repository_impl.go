@@ -0,0 +1,78 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const planColumns = `
+	id, merchant_id, name, amount, currency, billing_interval, interval_count,
+	trial_days, is_active, created_at, updated_at`
+
+type planRepository struct {
+	db *Cluster
+}
+
+func NewPlanRepository(db *Cluster) repository.PlanRepository {
+	return &planRepository{db: db}
+}
+
+func (r *planRepository) Create(ctx context.Context, plan *entity.Plan) error {
+	query := `
+		INSERT INTO plans (` + planColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		plan.ID, plan.MerchantID, plan.Name, plan.Amount, plan.Currency, plan.Interval,
+		plan.IntervalCount, plan.TrialDays, plan.IsActive, plan.CreatedAt, plan.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create plan")
+	}
+	return nil
+}
+
+func (r *planRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Plan, error) {
+	query := `SELECT ` + planColumns + ` FROM plans WHERE id = ?`
+
+	var plan entity.Plan
+	err := r.db.Reader(ctx).GetContext(ctx, &plan, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("plan not found")
+		}
+		return nil, errors.Wrap(err, "failed to get plan by id")
+	}
+	return &plan, nil
+}
+
+func (r *planRepository) ListByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error) {
+	query := `
+		SELECT ` + planColumns + `
+		FROM plans
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+	`
+	var plans []*entity.Plan
+	err := r.db.Reader(ctx).SelectContext(ctx, &plans, r.db.Rebind(query), merchantID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list plans")
+	}
+	return plans, nil
+}
+
+func (r *planRepository) Update(ctx context.Context, plan *entity.Plan) error {
+	query := `UPDATE plans SET name = ?, is_active = ?, updated_at = ? WHERE id = ?`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		plan.Name, plan.IsActive, plan.UpdatedAt, plan.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update plan")
+	}
+	return requireAffected(result, "plan not found")
+}
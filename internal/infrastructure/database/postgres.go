@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -15,6 +16,11 @@ type Config struct {
 	Password string
 	DBName   string
 	SSLMode  string
+	Path     string // SQLite 資料庫檔案路徑
+
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
 }
 
 func NewPostgresConnection(cfg Config) (*sqlx.DB, error) {
@@ -28,9 +34,7 @@ func NewPostgresConnection(cfg Config) (*sqlx.DB, error) {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
 
-	// 設置連接池參數
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
+	configurePool(db, cfg)
 
 	// 測試連接
 	if err := db.PingContext(context.Background()); err != nil {
@@ -38,4 +42,18 @@ func NewPostgresConnection(cfg Config) (*sqlx.DB, error) {
 	}
 
 	return db, nil
-}
\ No newline at end of file
+}
+
+// configurePool 套用連接池參數，未設定的欄位沿用原本的預設值
+func configurePool(db *sqlx.DB, cfg Config) {
+	maxOpen, maxIdle := cfg.MaxOpenConns, cfg.MaxIdleConns
+	if maxOpen <= 0 {
+		maxOpen = 25
+	}
+	if maxIdle <= 0 {
+		maxIdle = 5
+	}
+	db.SetMaxOpenConns(maxOpen)
+	db.SetMaxIdleConns(maxIdle)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+}
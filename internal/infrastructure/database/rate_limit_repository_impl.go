@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+type rateLimitRepository struct {
+	db *Cluster
+}
+
+func NewRateLimitRepository(db *Cluster) repository.RateLimitRepository {
+	return &rateLimitRepository{db: db}
+}
+
+// Take 先以不改變資料的 UPDATE 鎖定 bucket（PostgreSQL 鎖住該列，SQLite 取得寫鎖），
+// 讓多個實例對同一 bucket 的讀取與寫回依序進行
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (*entity.RateLimitResult, error) {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	insert := `
+		INSERT INTO rate_limit_buckets (bucket_key, tokens, updated_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT (bucket_key) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(insert), key, float64(limit.Capacity()), now); err != nil {
+		return nil, errors.Wrap(err, "failed to create rate limit bucket")
+	}
+	lock := `UPDATE rate_limit_buckets SET tokens = tokens WHERE bucket_key = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(lock), key); err != nil {
+		return nil, errors.Wrap(err, "failed to lock rate limit bucket")
+	}
+
+	var bucket entity.RateLimitBucket
+	query := `SELECT bucket_key, tokens, updated_at FROM rate_limit_buckets WHERE bucket_key = ?`
+	if err := tx.GetContext(ctx, &bucket, tx.Rebind(query), key); err != nil {
+		return nil, errors.Wrap(err, "failed to get rate limit bucket")
+	}
+
+	updated, result := limit.Take(&bucket, key, now)
+	update := `UPDATE rate_limit_buckets SET tokens = ?, updated_at = ? WHERE bucket_key = ?`
+	if _, err := tx.ExecContext(ctx, tx.Rebind(update), updated.Tokens, updated.UpdatedAt, key); err != nil {
+		return nil, errors.Wrap(err, "failed to update rate limit bucket")
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, errors.Wrap(err, "failed to commit rate limit bucket")
+	}
+	return result, nil
+}
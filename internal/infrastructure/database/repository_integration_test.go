@@ -0,0 +1,100 @@
+//go:build integration
+
+package database
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/repository/repositorytest"
+	"github.com/stretchr/testify/require"
+)
+
+// 需要已執行遷移的 PostgreSQL，連線參數沿用 PAYMENT_DATABASE_* 環境變數
+func TestPostgresRepositoryConformance(t *testing.T) {
+	db, err := NewPostgresConnection(postgresTestConfig())
+	if err != nil {
+		t.Skipf("postgres not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		cluster := NewCluster(db)
+		return repositorytest.Repositories{
+			Payments:       NewPaymentRepository(cluster),
+			Merchants:      NewMerchantRepository(cluster),
+			Customers:      NewCustomerRepository(cluster),
+			Cards:          NewCardRepository(cluster),
+			PaymentMethods: NewPaymentMethodRepository(cluster),
+			Plans:          NewPlanRepository(cluster),
+			Subscriptions:  NewSubscriptionRepository(cluster),
+			Invoices:       NewInvoiceRepository(cluster),
+			Checkouts:      NewCheckoutSessionRepository(cluster),
+			PaymentLinks:   NewPaymentLinkRepository(cluster),
+			Jobs:           NewJobRepository(cluster),
+			BankTransfers:  NewBankTransferRepository(cluster),
+			BankCredits:    NewBankCreditRepository(cluster),
+			Statements:     NewStatementRepository(cluster),
+			WalletActions:  NewWalletActionRepository(cluster),
+			WebhookEvents:  NewWebhookEventRepository(cluster),
+			Disputes:       NewDisputeRepository(cluster),
+			Ledger:         NewLedgerRepository(cluster),
+			Risk:           NewRiskRepository(cluster),
+			MerchantLimits: NewMerchantLimitRepository(cluster),
+			RateLimits:     NewRateLimitRepository(cluster),
+			Nonces:         NewNonceRepository(cluster),
+		}
+	})
+}
+
+// 同時啟動的實例依序取得遷移鎖，都應成功且不留下未釋放的鎖
+func TestPostgresMigrateConcurrently(t *testing.T) {
+	db, err := NewPostgresConnection(postgresTestConfig())
+	if err != nil {
+		t.Skipf("postgres not available: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	errs := make(chan error, 3)
+	for i := 0; i < cap(errs); i++ {
+		go func() { errs <- Migrate(ctx, db) }()
+	}
+	for i := 0; i < cap(errs); i++ {
+		require.NoError(t, <-errs)
+	}
+
+	// advisory lock 屬於 session，檢查與釋放須在同一條連線
+	conn, err := db.Connx(ctx)
+	require.NoError(t, err)
+	defer conn.Close()
+	var locked bool
+	require.NoError(t, conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock($1)", migrationLockKey))
+	require.True(t, locked)
+	_, err = conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", migrationLockKey)
+	require.NoError(t, err)
+}
+
+func postgresTestConfig() Config {
+	port, err := strconv.Atoi(getEnv("PAYMENT_DATABASE_PORT", "5432"))
+	if err != nil {
+		port = 5432
+	}
+	return Config{
+		Host:     getEnv("PAYMENT_DATABASE_HOST", "localhost"),
+		Port:     port,
+		User:     getEnv("PAYMENT_DATABASE_USER", "postgres"),
+		Password: getEnv("PAYMENT_DATABASE_PASSWORD", "postgres"),
+		DBName:   getEnv("PAYMENT_DATABASE_DBNAME", "payment_service"),
+		SSLMode:  getEnv("PAYMENT_DATABASE_SSLMODE", "disable"),
+	}
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
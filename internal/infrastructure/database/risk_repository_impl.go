@@ -0,0 +1,55 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type riskRepository struct {
+	db *Cluster
+}
+
+func NewRiskRepository(db *Cluster) repository.RiskRepository {
+	return &riskRepository{db: db}
+}
+
+// CountPaymentsSince 讀取主庫，副本的延遲會讓短時間內的連續付款漏算
+func (r *riskRepository) CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (int, error) {
+	var query string
+	switch key {
+	case entity.RiskVelocityCustomer:
+		query = `SELECT COUNT(*) FROM payments WHERE customer_id = ? AND created_at >= ?`
+	case entity.RiskVelocityEmail:
+		query = `
+			SELECT COUNT(*) FROM payments p
+			JOIN customers c ON c.id = p.customer_id
+			WHERE LOWER(c.email) = LOWER(?) AND p.created_at >= ?
+		`
+	case entity.RiskVelocityIP:
+		query = `SELECT COUNT(*) FROM payments WHERE client_ip = ? AND created_at >= ?`
+	default:
+		return 0, errors.New(fmt.Sprintf("unsupported velocity key %q", key))
+	}
+
+	var count int
+	if err := r.db.Primary().GetContext(ctx, &count, r.db.Rebind(query), value, since); err != nil {
+		return 0, errors.Wrap(err, "failed to count payments")
+	}
+	return count, nil
+}
+
+func (r *riskRepository) CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM payments WHERE customer_id = ? AND status = ?`
+	var count int
+	err := r.db.Reader(ctx).GetContext(ctx, &count, r.db.Rebind(query), customerID, entity.PaymentStatusCompleted)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to count completed payments")
+	}
+	return count, nil
+}
@@ -0,0 +1,32 @@
+package database
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewSQLiteConnection 開啟單一檔案的 SQLite 資料庫，需以 CGO_ENABLED=1 編譯
+func NewSQLiteConnection(cfg Config) (*sqlx.DB, error) {
+	// 啟用外鍵檢查以對齊 PostgreSQL 的語意，WAL 模式允許讀寫並行
+	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000&_journal_mode=WAL", cfg.Path)
+
+	db, err := sqlx.Connect("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %w", err)
+	}
+
+	configurePool(db, cfg)
+	// :memory: 資料庫每個連線都是獨立的，只能使用單一連線
+	if cfg.Path == ":memory:" {
+		db.SetMaxOpenConns(1)
+	}
+
+	if err := db.PingContext(context.Background()); err != nil {
+		return nil, fmt.Errorf("failed to ping sqlite database: %w", err)
+	}
+
+	return db, nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/repository/repositorytest"
+	"github.com/company/payment-service/scripts/migrations"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLiteRepositoryConformance(t *testing.T) {
+	db, err := NewSQLiteConnection(Config{Path: filepath.Join(t.TempDir(), "payment_service.db")})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, Migrate(context.Background(), db))
+
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		cluster := NewCluster(db)
+		return repositorytest.Repositories{
+			Payments:       NewPaymentRepository(cluster),
+			Merchants:      NewMerchantRepository(cluster),
+			Customers:      NewCustomerRepository(cluster),
+			Cards:          NewCardRepository(cluster),
+			PaymentMethods: NewPaymentMethodRepository(cluster),
+			Plans:          NewPlanRepository(cluster),
+			Subscriptions:  NewSubscriptionRepository(cluster),
+			Invoices:       NewInvoiceRepository(cluster),
+			Checkouts:      NewCheckoutSessionRepository(cluster),
+			PaymentLinks:   NewPaymentLinkRepository(cluster),
+			Jobs:           NewJobRepository(cluster),
+			BankTransfers:  NewBankTransferRepository(cluster),
+			BankCredits:    NewBankCreditRepository(cluster),
+			Statements:     NewStatementRepository(cluster),
+			WalletActions:  NewWalletActionRepository(cluster),
+			WebhookEvents:  NewWebhookEventRepository(cluster),
+			Disputes:       NewDisputeRepository(cluster),
+			Ledger:         NewLedgerRepository(cluster),
+			Risk:           NewRiskRepository(cluster),
+			MerchantLimits: NewMerchantLimitRepository(cluster),
+			RateLimits:     NewRateLimitRepository(cluster),
+			Nonces:         NewNonceRepository(cluster),
+		}
+	})
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	db, err := NewSQLiteConnection(Config{Path: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	require.NoError(t, Migrate(ctx, db))
+	require.NoError(t, Migrate(ctx, db))
+
+	latest, err := LatestMigrationVersion(db.DriverName())
+	require.NoError(t, err)
+
+	var applied int64
+	require.NoError(t, db.GetContext(ctx, &applied, "SELECT MAX(version) FROM schema_migrations"))
+	require.Equal(t, latest, applied)
+}
+
+func TestMigrateBaselinesExistingSchema(t *testing.T) {
+	db, err := NewSQLiteConnection(Config{Path: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// 模擬在 schema_migrations 出現前以 psql 建立的資料庫
+	ctx := context.Background()
+	initial, err := fs.ReadFile(migrations.FS, "sqlite/001_initial_schema.sql")
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, string(initial))
+	require.NoError(t, err)
+	_, err = db.ExecContext(ctx, "DROP TABLE schema_migrations")
+	require.NoError(t, err)
+
+	require.NoError(t, Migrate(ctx, db))
+
+	latest, err := LatestMigrationVersion(db.DriverName())
+	require.NoError(t, err)
+	var applied int64
+	require.NoError(t, db.GetContext(ctx, &applied, "SELECT MAX(version) FROM schema_migrations"))
+	require.Equal(t, latest, applied)
+}
+
+func TestMigrationChecker(t *testing.T) {
+	db, err := NewSQLiteConnection(Config{Path: ":memory:"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	ctx := context.Background()
+	checker := MigrationChecker(db)
+	require.Error(t, checker.Check(ctx), "missing schema_migrations table must fail")
+
+	require.NoError(t, Migrate(ctx, db))
+	require.NoError(t, checker.Check(ctx))
+}
@@ -0,0 +1,166 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const statementColumns = `
+	id, format, bank_statement_id, account, currency, period_start, period_end, created_at`
+
+const statementLineColumns = `
+	id, statement_id, transaction_id, booking_date, amount, currency, reference,
+	counterparty, status, payment_id, note, resolved_at, created_at`
+
+const statementExceptionColumns = `
+	statement_id, payment_id, merchant_id, amount, currency, reference,
+	completed_at, status, note, resolved_at, created_at`
+
+type statementRepository struct {
+	db *Cluster
+}
+
+func NewStatementRepository(db *Cluster) repository.StatementRepository {
+	return &statementRepository{db: db}
+}
+
+func (r *statementRepository) Create(ctx context.Context, statement *entity.Statement) error {
+	tx, err := r.db.Writer(ctx).BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO statements (` + statementColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err = tx.ExecContext(ctx, tx.Rebind(query),
+		statement.ID, statement.Format, statement.BankID, statement.Account, statement.Currency,
+		statement.PeriodStart, statement.PeriodEnd, statement.CreatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create statement")
+	}
+
+	lineQuery := tx.Rebind(`
+		INSERT INTO statement_lines (` + statementLineColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	for _, line := range statement.Lines {
+		_, err := tx.ExecContext(ctx, lineQuery,
+			line.ID, statement.ID, line.TransactionID, line.BookingDate, line.Amount, line.Currency,
+			line.Reference, line.Counterparty, line.Status, line.PaymentID, line.Note,
+			line.ResolvedAt, line.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create statement line")
+		}
+	}
+
+	exceptionQuery := tx.Rebind(`
+		INSERT INTO statement_exceptions (` + statementExceptionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`)
+	for _, exception := range statement.Exceptions {
+		_, err := tx.ExecContext(ctx, exceptionQuery,
+			statement.ID, exception.PaymentID, exception.MerchantID, exception.Amount,
+			exception.Currency, exception.Reference, exception.CompletedAt, exception.Status,
+			exception.Note, exception.ResolvedAt, exception.CreatedAt,
+		)
+		if err != nil {
+			return errors.Wrap(err, "failed to create statement exception")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit statement")
+	}
+	return nil
+}
+
+func (r *statementRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Statement, error) {
+	db := r.db.Reader(ctx)
+
+	var statement entity.Statement
+	err := db.GetContext(ctx, &statement, r.db.Rebind(`SELECT `+statementColumns+` FROM statements WHERE id = ?`), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("statement not found")
+		}
+		return nil, errors.Wrap(err, "failed to get statement by id")
+	}
+
+	query := `
+		SELECT ` + statementLineColumns + `
+		FROM statement_lines
+		WHERE statement_id = ?
+		ORDER BY booking_date, transaction_id
+	`
+	if err := db.SelectContext(ctx, &statement.Lines, r.db.Rebind(query), id); err != nil {
+		return nil, errors.Wrap(err, "failed to get statement lines")
+	}
+
+	query = `
+		SELECT ` + statementExceptionColumns + `
+		FROM statement_exceptions
+		WHERE statement_id = ?
+		ORDER BY completed_at, payment_id
+	`
+	if err := db.SelectContext(ctx, &statement.Exceptions, r.db.Rebind(query), id); err != nil {
+		return nil, errors.Wrap(err, "failed to get statement exceptions")
+	}
+	return &statement, nil
+}
+
+func (r *statementRepository) List(ctx context.Context, limit, offset int) ([]*entity.Statement, error) {
+	query := `
+		SELECT ` + statementColumns + `
+		FROM statements
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var statements []*entity.Statement
+	if err := r.db.Reader(ctx).SelectContext(ctx, &statements, r.db.Rebind(query), limit, offset); err != nil {
+		return nil, errors.Wrap(err, "failed to list statements")
+	}
+	return statements, nil
+}
+
+func (r *statementRepository) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) error {
+	query := `
+		UPDATE statement_lines
+		SET status = ?, payment_id = COALESCE(?, payment_id), note = ?, resolved_at = ?
+		WHERE id = ? AND statement_id = ? AND status = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.ReconciliationStatusResolved, paymentID, note, now,
+		lineID, statementID, entity.ReconciliationStatusUnmatched,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve statement line")
+	}
+	return requireAffected(result, "statement line not found or not unmatched")
+}
+
+func (r *statementRepository) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) error {
+	query := `
+		UPDATE statement_exceptions
+		SET status = ?, note = ?, resolved_at = ?
+		WHERE statement_id = ? AND payment_id = ? AND status = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.ReconciliationStatusResolved, note, now,
+		statementID, paymentID, entity.ReconciliationStatusUnmatched,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve statement exception")
+	}
+	return requireAffected(result, "statement exception not found or not unmatched")
+}
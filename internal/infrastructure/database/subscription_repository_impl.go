@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const subscriptionColumns = `
+	id, merchant_id, customer_id, plan_id, payment_method_id, quantity, status,
+	billing_anchor, current_period_start, current_period_end, trial_end,
+	cancel_at_period_end, canceled_at, proration_balance, failed_attempts,
+	next_retry_at, latest_payment_id, created_at, updated_at`
+
+type subscriptionRepository struct {
+	db *Cluster
+}
+
+func NewSubscriptionRepository(db *Cluster) repository.SubscriptionRepository {
+	return &subscriptionRepository{db: db}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, sub *entity.Subscription) error {
+	query := `
+		INSERT INTO subscriptions (` + subscriptionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		sub.ID, sub.MerchantID, sub.CustomerID, sub.PlanID, sub.PaymentMethodID, sub.Quantity, sub.Status,
+		utc(sub.BillingAnchor), utc(sub.CurrentPeriodStart), utc(sub.CurrentPeriodEnd), utcPtr(sub.TrialEnd),
+		sub.CancelAtPeriodEnd, utcPtr(sub.CanceledAt), sub.ProrationBalance, sub.FailedAttempts,
+		utcPtr(sub.NextRetryAt), sub.LatestPaymentID, sub.CreatedAt, sub.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create subscription")
+	}
+	return nil
+}
+
+func (r *subscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
+	query := `SELECT ` + subscriptionColumns + ` FROM subscriptions WHERE id = ?`
+
+	var sub entity.Subscription
+	err := r.db.Reader(ctx).GetContext(ctx, &sub, r.db.Rebind(query), id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("subscription not found")
+		}
+		return nil, errors.Wrap(err, "failed to get subscription by id")
+	}
+	return &sub, nil
+}
+
+func (r *subscriptionRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE merchant_id = ?
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var subs []*entity.Subscription
+	err := r.db.Reader(ctx).SelectContext(ctx, &subs, r.db.Rebind(query), merchantID, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get subscriptions by merchant id")
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepository) Update(ctx context.Context, sub *entity.Subscription) error {
+	query := `
+		UPDATE subscriptions
+		SET plan_id = ?, payment_method_id = ?, quantity = ?, status = ?,
+		    current_period_start = ?, current_period_end = ?, trial_end = ?,
+		    cancel_at_period_end = ?, canceled_at = ?, proration_balance = ?,
+		    failed_attempts = ?, next_retry_at = ?, latest_payment_id = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		sub.PlanID, sub.PaymentMethodID, sub.Quantity, sub.Status,
+		utc(sub.CurrentPeriodStart), utc(sub.CurrentPeriodEnd), utcPtr(sub.TrialEnd),
+		sub.CancelAtPeriodEnd, utcPtr(sub.CanceledAt), sub.ProrationBalance,
+		sub.FailedAttempts, utcPtr(sub.NextRetryAt), sub.LatestPaymentID, sub.UpdatedAt, sub.ID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to update subscription")
+	}
+	return requireAffected(result, "subscription not found")
+}
+
+func (r *subscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*entity.Subscription, error) {
+	// 排程器讀完立即寫入，讀取走主庫避免副本延遲造成重複扣款
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE status IN (?, ?, ?)
+		  AND ((next_retry_at IS NULL AND current_period_end <= ?) OR next_retry_at <= ?)
+		  AND (billing_locked_until IS NULL OR billing_locked_until <= ?)
+		ORDER BY current_period_end
+		LIMIT ?
+	`
+	var subs []*entity.Subscription
+	err := r.db.Writer(ctx).SelectContext(ctx, &subs, r.db.Rebind(query),
+		entity.SubscriptionStatusTrialing, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue,
+		utc(now), utc(now), utc(now), limit,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list due subscriptions")
+	}
+	return subs, nil
+}
+
+func (r *subscriptionRepository) Claim(ctx context.Context, id uuid.UUID, now, until time.Time) error {
+	// 以與 ListDue 相同的條件做條件更新，已續期或已被其他實例鎖定的訂閱不會被取得
+	query := `
+		UPDATE subscriptions
+		SET billing_locked_until = ?
+		WHERE id = ?
+		  AND status IN (?, ?, ?)
+		  AND ((next_retry_at IS NULL AND current_period_end <= ?) OR next_retry_at <= ?)
+		  AND (billing_locked_until IS NULL OR billing_locked_until <= ?)
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		utc(until), id,
+		entity.SubscriptionStatusTrialing, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue,
+		utc(now), utc(now), utc(now),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to claim subscription")
+	}
+	return requireAffected(result, "subscription not found, not due or already claimed")
+}
+
+// utc 統一以 UTC 寫入排程用的時間欄位，SQLite 以字串保存時間，時區一致才能正確比較
+func utc(t time.Time) time.Time {
+	return t.UTC()
+}
+
+func utcPtr(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	u := t.UTC()
+	return &u
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const walletActionColumns = `
+	payment_id, merchant_id, type, redirect_url, qr_payload, status,
+	expires_at, created_at, updated_at`
+
+type walletActionRepository struct {
+	db *Cluster
+}
+
+func NewWalletActionRepository(db *Cluster) repository.WalletActionRepository {
+	return &walletActionRepository{db: db}
+}
+
+func (r *walletActionRepository) Create(ctx context.Context, action *entity.WalletAction) error {
+	query := `
+		INSERT INTO wallet_actions (` + walletActionColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		action.PaymentID, action.MerchantID, action.Type, action.RedirectURL, action.QRPayload,
+		action.Status, action.ExpiresAt, action.CreatedAt, action.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create wallet action")
+	}
+	return nil
+}
+
+func (r *walletActionRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.WalletAction, error) {
+	var action entity.WalletAction
+	query := `SELECT ` + walletActionColumns + ` FROM wallet_actions WHERE payment_id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &action, r.db.Rebind(query), paymentID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("wallet action not found")
+		}
+		return nil, errors.Wrap(err, "failed to get wallet action by payment_id")
+	}
+	return &action, nil
+}
+
+func (r *walletActionRepository) Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) error {
+	query := `
+		UPDATE wallet_actions
+		SET status = ?, updated_at = ?
+		WHERE payment_id = ? AND status = ? AND expires_at > ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		status, now, paymentID, entity.WalletActionStatusPending, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve wallet action")
+	}
+	return requireAffected(result, "wallet action not found or no longer pending")
+}
+
+func (r *walletActionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.WalletAction, error) {
+	query := `
+		SELECT ` + walletActionColumns + `
+		FROM wallet_actions
+		WHERE status = ? AND expires_at <= ?
+		ORDER BY expires_at
+		LIMIT ?
+	`
+	var actions []*entity.WalletAction
+	err := r.db.Reader(ctx).SelectContext(ctx, &actions, r.db.Rebind(query),
+		entity.WalletActionStatusPending, now, limit)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list expired wallet actions")
+	}
+	return actions, nil
+}
+
+func (r *walletActionRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	query := `
+		UPDATE wallet_actions
+		SET status = ?, updated_at = ?
+		WHERE payment_id = ? AND status = ? AND expires_at <= ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		entity.WalletActionStatusExpired, now, paymentID, entity.WalletActionStatusPending, now)
+	if err != nil {
+		return errors.Wrap(err, "failed to expire wallet action")
+	}
+	return requireAffected(result, "wallet action not found or no longer pending")
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+const webhookEventColumns = `
+	id, provider, event_id, event_type, payment_id, status, payload,
+	error, attempts, processed_at, created_at, updated_at`
+
+type webhookEventRepository struct {
+	db *Cluster
+}
+
+func NewWebhookEventRepository(db *Cluster) repository.WebhookEventRepository {
+	return &webhookEventRepository{db: db}
+}
+
+func (r *webhookEventRepository) Create(ctx context.Context, event *entity.WebhookEvent) error {
+	query := `
+		INSERT INTO webhook_events (` + webhookEventColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	_, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		event.ID, event.Provider, event.EventID, event.EventType, event.PaymentID,
+		event.Status, event.Payload, event.Error, event.Attempts, event.ProcessedAt,
+		event.CreatedAt, event.UpdatedAt,
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create webhook event")
+	}
+	return nil
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error) {
+	var event entity.WebhookEvent
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events WHERE id = ?`
+	if err := r.db.Reader(ctx).GetContext(ctx, &event, r.db.Rebind(query), id); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("webhook event not found")
+		}
+		return nil, errors.Wrap(err, "failed to get webhook event")
+	}
+	return &event, nil
+}
+
+func (r *webhookEventRepository) GetByEventID(ctx context.Context, provider, eventID string) (*entity.WebhookEvent, error) {
+	var event entity.WebhookEvent
+	query := `SELECT ` + webhookEventColumns + ` FROM webhook_events WHERE provider = ? AND event_id = ?`
+	// 網關重送可能緊接在第一次寫入之後，讀取走主庫避免副本延遲造成重複處理
+	if err := r.db.Writer(ctx).GetContext(ctx, &event, r.db.Rebind(query), provider, eventID); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, errors.New("webhook event not found")
+		}
+		return nil, errors.Wrap(err, "failed to get webhook event by event id")
+	}
+	return &event, nil
+}
+
+func (r *webhookEventRepository) Update(ctx context.Context, event *entity.WebhookEvent) error {
+	query := `
+		UPDATE webhook_events
+		SET status = ?, payment_id = ?, error = ?, attempts = ?, processed_at = ?, updated_at = ?
+		WHERE id = ?
+	`
+	result, err := r.db.Writer(ctx).ExecContext(ctx, r.db.Rebind(query),
+		event.Status, event.PaymentID, event.Error, event.Attempts, event.ProcessedAt, time.Now(), event.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to update webhook event")
+	}
+	return requireAffected(result, "webhook event not found")
+}
+
+func (r *webhookEventRepository) List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error) {
+	query := `
+		SELECT ` + webhookEventColumns + `
+		FROM webhook_events
+		WHERE (? = '' OR provider = ?) AND (? = '' OR status = ?)
+		ORDER BY created_at DESC
+		LIMIT ? OFFSET ?
+	`
+	var events []*entity.WebhookEvent
+	err := r.db.Reader(ctx).SelectContext(ctx, &events, r.db.Rebind(query),
+		provider, provider, status, status, limit, offset)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list webhook events")
+	}
+	return events, nil
+}
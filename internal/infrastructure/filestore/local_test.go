@@ -0,0 +1,53 @@
+package filestore
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocal(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "evidence")
+	store, err := NewLocal(dir)
+	require.NoError(t, err)
+
+	n, err := store.Save(ctx, "dispute/receipt", strings.NewReader("signed receipt"))
+	require.NoError(t, err)
+	assert.Equal(t, int64(14), n)
+
+	f, err := store.Open(ctx, "dispute/receipt")
+	require.NoError(t, err)
+	content, err := io.ReadAll(f)
+	require.NoError(t, f.Close())
+	require.NoError(t, err)
+	assert.Equal(t, "signed receipt", string(content))
+
+	entries, err := os.ReadDir(filepath.Join(dir, "dispute"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "temporary file is removed")
+
+	require.NoError(t, store.Delete(ctx, "dispute/receipt"))
+	require.NoError(t, store.Delete(ctx, "dispute/receipt"), "deleting a missing file is not an error")
+	_, err = store.Open(ctx, "dispute/receipt")
+	assert.Error(t, err)
+}
+
+func TestLocalRejectsKeysOutsideDirectory(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", ".", "../escape", "a/../../escape", "/etc/passwd"} {
+		_, err := store.Save(ctx, key, strings.NewReader("x"))
+		assert.Error(t, err, key)
+		_, err = store.Open(ctx, key)
+		assert.Error(t, err, key)
+	}
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+type bankCreditRepository struct {
+	store *Store
+}
+
+func NewBankCreditRepository(store *Store) repository.BankCreditRepository {
+	return &bankCreditRepository{store: store}
+}
+
+func (r *bankCreditRepository) Create(ctx context.Context, credit *entity.BankCredit) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.bankCredits[credit.ID]; exists {
+		return errors.New("failed to create bank credit: duplicate id")
+	}
+	if credit.PaymentID != nil {
+		if _, exists := r.store.payments[*credit.PaymentID]; !exists {
+			return errors.New("failed to create bank credit: payment does not exist")
+		}
+	}
+	for _, existing := range r.store.bankCredits {
+		if existing.TransactionID == credit.TransactionID {
+			return errors.New("failed to create bank credit: duplicate transaction id")
+		}
+	}
+
+	r.store.bankCredits[credit.ID] = copyBankCredit(credit)
+	return nil
+}
+
+func (r *bankCreditRepository) GetByTransactionID(ctx context.Context, transactionID string) (*entity.BankCredit, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, credit := range r.store.bankCredits {
+		if credit.TransactionID == transactionID {
+			return copyBankCredit(credit), nil
+		}
+	}
+	return nil, errors.New("bank credit not found")
+}
+
+func (r *bankCreditRepository) Update(ctx context.Context, credit *entity.BankCredit) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.bankCredits[credit.ID]
+	if !ok {
+		return errors.New("bank credit not found")
+	}
+	if credit.PaymentID != nil {
+		if _, exists := r.store.payments[*credit.PaymentID]; !exists {
+			return errors.New("failed to update bank credit: payment does not exist")
+		}
+	}
+	existing.Status = credit.Status
+	existing.PaymentID = copyUUID(credit.PaymentID)
+	existing.Note = credit.Note
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *bankCreditRepository) List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) ([]*entity.BankCredit, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var credits []*entity.BankCredit
+	for _, credit := range r.store.bankCredits {
+		if status == "" || credit.Status == status {
+			credits = append(credits, copyBankCredit(credit))
+		}
+	}
+	sort.Slice(credits, func(i, j int) bool {
+		return credits[i].ReceivedAt.After(credits[j].ReceivedAt)
+	})
+	return paginate(credits, limit, offset), nil
+}
+
+func copyBankCredit(c *entity.BankCredit) *entity.BankCredit {
+	credit := *c
+	credit.PaymentID = copyUUID(c.PaymentID)
+	return &credit
+}
@@ -0,0 +1,146 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type bankTransferRepository struct {
+	store *Store
+}
+
+func NewBankTransferRepository(store *Store) repository.BankTransferRepository {
+	return &bankTransferRepository{store: store}
+}
+
+func (r *bankTransferRepository) Create(ctx context.Context, transfer *entity.BankTransfer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.bankTransfers[transfer.PaymentID]; exists {
+		return errors.New("failed to create bank transfer: duplicate payment id")
+	}
+	if _, exists := r.store.payments[transfer.PaymentID]; !exists {
+		return errors.New("failed to create bank transfer: payment does not exist")
+	}
+	if _, exists := r.store.merchants[transfer.MerchantID]; !exists {
+		return errors.New("failed to create bank transfer: merchant does not exist")
+	}
+	for _, existing := range r.store.bankTransfers {
+		if existing.Reference == transfer.Reference {
+			return errors.New("failed to create bank transfer: duplicate reference")
+		}
+		if existing.VirtualAccount == transfer.VirtualAccount {
+			return errors.New("failed to create bank transfer: duplicate virtual account")
+		}
+	}
+
+	c := *transfer
+	r.store.bankTransfers[transfer.PaymentID] = &c
+	return nil
+}
+
+func (r *bankTransferRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.BankTransfer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	transfer, ok := r.store.bankTransfers[paymentID]
+	if !ok {
+		return nil, errors.New("bank transfer not found")
+	}
+	c := *transfer
+	return &c, nil
+}
+
+func (r *bankTransferRepository) GetByReference(ctx context.Context, reference string) (*entity.BankTransfer, error) {
+	return r.find(func(t *entity.BankTransfer) bool { return t.Reference == reference })
+}
+
+func (r *bankTransferRepository) GetByVirtualAccount(ctx context.Context, account string) (*entity.BankTransfer, error) {
+	return r.find(func(t *entity.BankTransfer) bool { return t.VirtualAccount == account })
+}
+
+func (r *bankTransferRepository) find(match func(*entity.BankTransfer) bool) (*entity.BankTransfer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, transfer := range r.store.bankTransfers {
+		if match(transfer) {
+			c := *transfer
+			return &c, nil
+		}
+	}
+	return nil, errors.New("bank transfer not found")
+}
+
+func (r *bankTransferRepository) ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (*entity.BankTransfer, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transfer, ok := r.store.bankTransfers[paymentID]
+	if !ok || !transfer.Open() || !transfer.ExpiresAt.After(now) {
+		return nil, errors.New("bank transfer not found or no longer awaiting funds")
+	}
+	transfer.AmountReceived += amount
+	switch {
+	case transfer.AmountReceived < transfer.AmountExpected:
+		transfer.Status = entity.BankTransferStatusUnderpaid
+	case transfer.AmountReceived == transfer.AmountExpected:
+		transfer.Status = entity.BankTransferStatusPaid
+	default:
+		transfer.Status = entity.BankTransferStatusOverpaid
+	}
+	transfer.UpdatedAt = now
+
+	c := *transfer
+	return &c, nil
+}
+
+func (r *bankTransferRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.BankTransfer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var transfers []*entity.BankTransfer
+	for _, transfer := range r.store.bankTransfers {
+		if transfer.Open() && !transfer.ExpiresAt.After(now) {
+			c := *transfer
+			transfers = append(transfers, &c)
+		}
+	}
+	sort.Slice(transfers, func(i, j int) bool {
+		return transfers[i].ExpiresAt.Before(transfers[j].ExpiresAt)
+	})
+	return paginate(transfers, limit, 0), nil
+}
+
+func (r *bankTransferRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transfer, ok := r.store.bankTransfers[paymentID]
+	if !ok || !transfer.Open() || transfer.ExpiresAt.After(now) {
+		return errors.New("bank transfer not found or no longer awaiting funds")
+	}
+	transfer.Status = entity.BankTransferStatusExpired
+	transfer.UpdatedAt = now
+	return nil
+}
+
+func (r *bankTransferRepository) Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	transfer, ok := r.store.bankTransfers[paymentID]
+	if !ok || !transfer.Open() {
+		return errors.New("bank transfer not found or no longer awaiting funds")
+	}
+	transfer.Status = entity.BankTransferStatusExpired
+	transfer.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+)
+
+type cardRepository struct {
+	store *Store
+}
+
+func NewCardRepository(store *Store) repository.CardRepository {
+	return &cardRepository{store: store}
+}
+
+func (r *cardRepository) Create(ctx context.Context, card *entity.Card) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.cards[card.Token]; exists {
+		return errors.New("failed to create card: duplicate token")
+	}
+	if _, exists := r.store.merchants[card.MerchantID]; !exists {
+		return errors.New("failed to create card: merchant does not exist")
+	}
+
+	r.store.cards[card.Token] = copyCard(card)
+	return nil
+}
+
+func (r *cardRepository) GetByToken(ctx context.Context, token string) (*entity.Card, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	card, ok := r.store.cards[token]
+	if !ok {
+		return nil, errors.New("card not found")
+	}
+	return copyCard(card), nil
+}
+
+func copyCard(card *entity.Card) *entity.Card {
+	c := *card
+	c.EncryptedPAN = append([]byte(nil), card.EncryptedPAN...)
+	c.EncryptedKey = append([]byte(nil), card.EncryptedKey...)
+	return &c
+}
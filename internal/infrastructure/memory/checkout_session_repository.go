@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type checkoutSessionRepository struct {
+	store *Store
+}
+
+func NewCheckoutSessionRepository(store *Store) repository.CheckoutSessionRepository {
+	return &checkoutSessionRepository{store: store}
+}
+
+func (r *checkoutSessionRepository) Create(ctx context.Context, session *entity.CheckoutSession) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.checkoutSessions[session.ID]; exists {
+		return errors.New("failed to create checkout session: duplicate id")
+	}
+	if _, exists := r.store.merchants[session.MerchantID]; !exists {
+		return errors.New("failed to create checkout session: merchant does not exist")
+	}
+	if err := r.checkReferences(session); err != nil {
+		return errors.Wrap(err, "failed to create checkout session")
+	}
+
+	r.store.checkoutSessions[session.ID] = copyCheckoutSession(session)
+	return nil
+}
+
+func (r *checkoutSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.CheckoutSession, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	session, ok := r.store.checkoutSessions[id]
+	if !ok {
+		return nil, errors.New("checkout session not found")
+	}
+	return copyCheckoutSession(session), nil
+}
+
+func (r *checkoutSessionRepository) Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.checkoutSessions[session.ID]
+	if !ok || existing.Status != from {
+		return errors.New(fmt.Sprintf("checkout session not found or no longer %s", from))
+	}
+	if err := r.checkReferences(session); err != nil {
+		return errors.Wrap(err, "failed to update checkout session")
+	}
+
+	// 與 SQL 實作相同，只更新狀態與結果欄位
+	existing.Status = session.Status
+	existing.CustomerID = copyUUID(session.CustomerID)
+	existing.PaymentID = copyUUID(session.PaymentID)
+	existing.CompletedAt = copyTime(session.CompletedAt)
+	existing.UpdatedAt = session.UpdatedAt
+	return nil
+}
+
+// checkReferences 對齊 customer_id、payment_id 與 payment_link_id 的外鍵，呼叫者需持有寫鎖
+func (r *checkoutSessionRepository) checkReferences(session *entity.CheckoutSession) error {
+	if session.CustomerID != nil {
+		if _, exists := r.store.customers[*session.CustomerID]; !exists {
+			return errors.New("customer does not exist")
+		}
+	}
+	if session.PaymentID != nil {
+		if _, exists := r.store.payments[*session.PaymentID]; !exists {
+			return errors.New("payment does not exist")
+		}
+	}
+	if session.PaymentLinkID != nil {
+		if _, exists := r.store.paymentLinks[*session.PaymentLinkID]; !exists {
+			return errors.New("payment link does not exist")
+		}
+	}
+	return nil
+}
+
+func copyCheckoutSession(s *entity.CheckoutSession) *entity.CheckoutSession {
+	c := *s
+	c.CustomerID = copyUUID(s.CustomerID)
+	c.PaymentID = copyUUID(s.PaymentID)
+	c.PaymentLinkID = copyUUID(s.PaymentLinkID)
+	c.CompletedAt = copyTime(s.CompletedAt)
+	c.AllowedMethods = append([]entity.PaymentMethod(nil), s.AllowedMethods...)
+	return &c
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type customerRepository struct {
+	store *Store
+}
+
+func NewCustomerRepository(store *Store) repository.CustomerRepository {
+	return &customerRepository{store: store}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.customers[customer.ID]; exists {
+		return errors.New("failed to create customer: duplicate id")
+	}
+	if err := r.checkUnique(customer); err != nil {
+		return errors.Wrap(err, "failed to create customer")
+	}
+
+	c := *customer
+	r.store.customers[customer.ID] = &c
+	return nil
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	customer, ok := r.store.customers[id]
+	if !ok {
+		return nil, errors.New("customer not found")
+	}
+	c := *customer
+	return &c, nil
+}
+
+func (r *customerRepository) GetByEmail(ctx context.Context, email string) (*entity.Customer, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, customer := range r.store.customers {
+		if customer.Email == email {
+			c := *customer
+			return &c, nil
+		}
+	}
+	return nil, errors.New("customer not found")
+}
+
+// Update 與 SQL 實作一致，找不到客戶時不回傳錯誤
+func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.customers[customer.ID]
+	if !ok {
+		return nil
+	}
+	if err := r.checkUnique(customer); err != nil {
+		return errors.Wrap(err, "failed to update customer")
+	}
+
+	existing.Name = customer.Name
+	existing.Email = customer.Email
+	existing.Phone = customer.Phone
+	existing.UpdatedAt = customer.UpdatedAt
+	return nil
+}
+
+func (r *customerRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	// 對齊 payments.customer_id 的外鍵限制
+	for _, p := range r.store.payments {
+		if p.CustomerID == id {
+			return errors.New("failed to delete customer: customer has payments")
+		}
+	}
+	// 對齊 subscriptions.customer_id 的外鍵限制
+	for _, sub := range r.store.subscriptions {
+		if sub.CustomerID == id {
+			return errors.New("failed to delete customer: customer has subscriptions")
+		}
+	}
+	// 對齊 invoices.customer_id 的外鍵限制
+	for _, inv := range r.store.invoices {
+		if inv.CustomerID == id {
+			return errors.New("failed to delete customer: customer has invoices")
+		}
+	}
+	// 對齊 checkout_sessions.customer_id 的外鍵限制
+	for _, session := range r.store.checkoutSessions {
+		if session.CustomerID != nil && *session.CustomerID == id {
+			return errors.New("failed to delete customer: customer has checkout sessions")
+		}
+	}
+	delete(r.store.customers, id)
+
+	// 對齊 payment_methods.customer_id 的 ON DELETE CASCADE
+	for methodID, method := range r.store.paymentMethods {
+		if method.CustomerID == id {
+			delete(r.store.paymentMethods, methodID)
+		}
+	}
+	return nil
+}
+
+// checkUnique 檢查 email 的唯一性，呼叫者需持有寫鎖
+func (r *customerRepository) checkUnique(customer *entity.Customer) error {
+	for _, c := range r.store.customers {
+		if c.ID != customer.ID && c.Email == customer.Email {
+			return errors.New("duplicate customer email")
+		}
+	}
+	return nil
+}
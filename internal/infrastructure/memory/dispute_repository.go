@@ -0,0 +1,206 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type disputeRepository struct {
+	store *Store
+}
+
+func NewDisputeRepository(store *Store) repository.DisputeRepository {
+	return &disputeRepository{store: store}
+}
+
+func (r *disputeRepository) Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.disputes[dispute.ID]; exists {
+		return errors.New("failed to create dispute: duplicate id")
+	}
+	for _, existing := range r.store.disputes {
+		if existing.PaymentID == dispute.PaymentID {
+			return errors.New("failed to create dispute: duplicate payment id")
+		}
+	}
+	if _, exists := r.store.payments[dispute.PaymentID]; !exists {
+		return errors.New("failed to create dispute: payment does not exist")
+	}
+	if _, exists := r.store.merchants[dispute.MerchantID]; !exists {
+		return errors.New("failed to create dispute: merchant does not exist")
+	}
+	if _, exists := r.store.disputeEvents[event.ID]; exists {
+		return errors.New("failed to create dispute event: duplicate id")
+	}
+
+	c := copyDispute(dispute)
+	c.Evidence = nil
+	r.store.disputes[dispute.ID] = c
+	e := *event
+	r.store.disputeEvents[event.ID] = &e
+	return nil
+}
+
+func (r *disputeRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Dispute, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	dispute, ok := r.store.disputes[id]
+	if !ok {
+		return nil, errors.New("dispute not found")
+	}
+	c := copyDispute(dispute)
+	sort.SliceStable(c.Evidence, func(i, j int) bool {
+		return c.Evidence[i].CreatedAt.Before(c.Evidence[j].CreatedAt)
+	})
+	return c, nil
+}
+
+func (r *disputeRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.Dispute, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, dispute := range r.store.disputes {
+		if dispute.PaymentID == paymentID {
+			c := copyDispute(dispute)
+			c.Evidence = nil
+			return c, nil
+		}
+	}
+	return nil, errors.New("dispute not found")
+}
+
+func (r *disputeRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) ([]*entity.Dispute, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var disputes []*entity.Dispute
+	for _, dispute := range r.store.disputes {
+		if dispute.MerchantID == merchantID && (status == "" || dispute.Status == status) {
+			c := copyDispute(dispute)
+			c.Evidence = nil
+			disputes = append(disputes, c)
+		}
+	}
+	sort.Slice(disputes, func(i, j int) bool {
+		return disputes[i].CreatedAt.After(disputes[j].CreatedAt)
+	})
+	return paginate(disputes, limit, offset), nil
+}
+
+func (r *disputeRepository) AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	dispute, ok := r.store.disputes[evidence.DisputeID]
+	if !ok || dispute.Status != entity.DisputeStatusNeedsResponse {
+		return errors.New("dispute not found or no longer accepting evidence")
+	}
+	for _, existing := range dispute.Evidence {
+		if existing.ID == evidence.ID {
+			return errors.New("failed to create dispute evidence: duplicate id")
+		}
+	}
+
+	c := *evidence
+	dispute.Evidence = append(dispute.Evidence, &c)
+	dispute.UpdatedAt = evidence.CreatedAt
+	return nil
+}
+
+func (r *disputeRepository) Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.disputes[dispute.ID]
+	if !ok || existing.Status != from {
+		return errors.New("dispute not found or status changed")
+	}
+	if _, exists := r.store.disputeEvents[event.ID]; exists {
+		return errors.New("failed to create dispute event: duplicate id")
+	}
+	if reversal != nil {
+		// 對齊 (dispute_id, type) 唯一鍵
+		for _, entry := range r.store.ledgerEntries {
+			if entry.ID == reversal.ID || (entry.DisputeID != nil && reversal.DisputeID != nil &&
+				*entry.DisputeID == *reversal.DisputeID && entry.Type == reversal.Type) {
+				return errors.New("failed to create ledger entry: duplicate entry")
+			}
+		}
+	}
+
+	existing.Status = dispute.Status
+	existing.SubmittedAt = copyTime(dispute.SubmittedAt)
+	existing.ClosedAt = copyTime(dispute.ClosedAt)
+	existing.UpdatedAt = dispute.UpdatedAt
+	e := *event
+	r.store.disputeEvents[event.ID] = &e
+	if reversal != nil {
+		c := *reversal
+		c.DisputeID = copyUUID(reversal.DisputeID)
+		r.store.ledgerEntries[reversal.ID] = &c
+	}
+	return nil
+}
+
+func (r *disputeRepository) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.DisputeEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var events []*entity.DisputeEvent
+	for _, event := range r.store.disputeEvents {
+		if event.MerchantID == merchantID {
+			c := *event
+			events = append(events, &c)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	return paginate(events, limit, offset), nil
+}
+
+func copyDispute(d *entity.Dispute) *entity.Dispute {
+	c := *d
+	c.SubmittedAt = copyTime(d.SubmittedAt)
+	c.ClosedAt = copyTime(d.ClosedAt)
+	c.Evidence = make([]*entity.DisputeEvidence, len(d.Evidence))
+	for i, evidence := range d.Evidence {
+		e := *evidence
+		c.Evidence[i] = &e
+	}
+	return &c
+}
+
+type ledgerRepository struct {
+	store *Store
+}
+
+func NewLedgerRepository(store *Store) repository.LedgerRepository {
+	return &ledgerRepository{store: store}
+}
+
+func (r *ledgerRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.LedgerEntry, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var entries []*entity.LedgerEntry
+	for _, entry := range r.store.ledgerEntries {
+		if entry.MerchantID == merchantID {
+			c := *entry
+			c.DisputeID = copyUUID(entry.DisputeID)
+			entries = append(entries, &c)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return paginate(entries, limit, offset), nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type invoiceRepository struct {
+	store *Store
+}
+
+func NewInvoiceRepository(store *Store) repository.InvoiceRepository {
+	return &invoiceRepository{store: store}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.invoices[invoice.ID]; exists {
+		return errors.New("failed to create invoice: duplicate id")
+	}
+	if _, exists := r.store.merchants[invoice.MerchantID]; !exists {
+		return errors.New("failed to create invoice: merchant does not exist")
+	}
+	if _, exists := r.store.customers[invoice.CustomerID]; !exists {
+		return errors.New("failed to create invoice: customer does not exist")
+	}
+	if err := r.checkInvoice(invoice); err != nil {
+		return errors.Wrap(err, "failed to create invoice")
+	}
+
+	r.store.invoices[invoice.ID] = copyInvoice(invoice)
+	return nil
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Invoice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	invoice, ok := r.store.invoices[id]
+	if !ok {
+		return nil, errors.New("invoice not found")
+	}
+	return copyInvoice(invoice), nil
+}
+
+func (r *invoiceRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Invoice, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var invoices []*entity.Invoice
+	for _, invoice := range r.store.invoices {
+		if invoice.MerchantID == merchantID {
+			c := copyInvoice(invoice)
+			// 與 SQL 實作相同，列表不載入明細
+			c.LineItems = nil
+			invoices = append(invoices, c)
+		}
+	}
+	sort.Slice(invoices, func(i, j int) bool {
+		return invoices[i].CreatedAt.After(invoices[j].CreatedAt)
+	})
+	return paginate(invoices, limit, offset), nil
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice *entity.Invoice) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.invoices[invoice.ID]
+	if !ok {
+		return errors.New("invoice not found")
+	}
+	if err := r.checkInvoice(invoice); err != nil {
+		return errors.Wrap(err, "failed to update invoice")
+	}
+
+	// 與 SQL 實作相同，商戶、客戶與建立時間不可變更
+	updated := copyInvoice(invoice)
+	updated.MerchantID = existing.MerchantID
+	updated.CustomerID = existing.CustomerID
+	updated.CreatedAt = existing.CreatedAt
+	r.store.invoices[invoice.ID] = updated
+	return nil
+}
+
+// checkInvoice 對齊編號唯一索引與 payment_id 外鍵，呼叫者需持有寫鎖
+func (r *invoiceRepository) checkInvoice(invoice *entity.Invoice) error {
+	if invoice.Number != "" {
+		for _, inv := range r.store.invoices {
+			if inv.ID != invoice.ID && inv.MerchantID == invoice.MerchantID && inv.Number == invoice.Number {
+				return errors.New("duplicate invoice number")
+			}
+		}
+	}
+	if invoice.PaymentID != nil {
+		if _, exists := r.store.payments[*invoice.PaymentID]; !exists {
+			return errors.New("payment does not exist")
+		}
+	}
+	return nil
+}
+
+func copyInvoice(inv *entity.Invoice) *entity.Invoice {
+	c := *inv
+	c.DueDate = copyTime(inv.DueDate)
+	c.PaymentID = copyUUID(inv.PaymentID)
+	c.FinalizedAt = copyTime(inv.FinalizedAt)
+	c.PaidAt = copyTime(inv.PaidAt)
+	c.VoidedAt = copyTime(inv.VoidedAt)
+	c.LineItems = make([]entity.InvoiceLineItem, len(inv.LineItems))
+	for i, item := range inv.LineItems {
+		item.InvoiceID = inv.ID
+		item.Position = i
+		c.LineItems[i] = item
+	}
+	// 稅額彙總不儲存，與 SQL 實作一致
+	c.TaxLines = nil
+	return &c
+}
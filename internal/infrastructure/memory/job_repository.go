@@ -0,0 +1,118 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type jobRepository struct {
+	store *Store
+}
+
+func NewJobRepository(store *Store) repository.JobRepository {
+	return &jobRepository{store: store}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.Job) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.jobs[job.ID]; exists {
+		return errors.New("failed to enqueue job: duplicate id")
+	}
+	r.store.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Job, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	job, ok := r.store.jobs[id]
+	if !ok {
+		return nil, errors.New("job not found")
+	}
+	return copyJob(job), nil
+}
+
+func (r *jobRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) ([]*entity.Job, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	var due []*entity.Job
+	for _, job := range r.store.jobs {
+		queued := job.Status == entity.JobStatusQueued && !job.RunAt.After(now)
+		expired := job.Status == entity.JobStatusRunning && job.LockedUntil != nil && !job.LockedUntil.After(now)
+		if queued || expired {
+			due = append(due, job)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].RunAt.Before(due[j].RunAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	token := uuid.New().String()
+	lockedUntil := now.Add(visibility)
+	jobs := make([]*entity.Job, 0, len(due))
+	for _, job := range due {
+		job.Status = entity.JobStatusRunning
+		job.Attempts++
+		job.LockedBy = token
+		job.LockedUntil = &lockedUntil
+		job.UpdatedAt = now
+		jobs = append(jobs, copyJob(job))
+	}
+	return jobs, nil
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, attempts int) error {
+	return r.finish(id, attempts, func(job *entity.Job) {
+		job.Status = entity.JobStatusSucceeded
+		job.LastError = ""
+	})
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) error {
+	return r.finish(id, attempts, func(job *entity.Job) {
+		job.Status = entity.JobStatusQueued
+		job.RunAt = runAt
+		job.LastError = lastError
+	})
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) error {
+	return r.finish(id, attempts, func(job *entity.Job) {
+		job.Status = entity.JobStatusFailed
+		job.LastError = lastError
+	})
+}
+
+// finish 與 SQL 實作相同，只在工作仍由同一次取得持有時更新
+func (r *jobRepository) finish(id uuid.UUID, attempts int, update func(*entity.Job)) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	job, ok := r.store.jobs[id]
+	if !ok || job.Status != entity.JobStatusRunning || job.Attempts != attempts {
+		return errors.New("job not found or lock lost")
+	}
+	update(job)
+	job.LockedUntil = nil
+	job.UpdatedAt = time.Now()
+	return nil
+}
+
+func copyJob(j *entity.Job) *entity.Job {
+	c := *j
+	c.LockedUntil = copyTime(j.LockedUntil)
+	return &c
+}
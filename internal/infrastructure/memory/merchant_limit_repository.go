@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+// limitUsageKey 對應 merchant_limit_usage 的主鍵
+type limitUsageKey struct {
+	merchantID uuid.UUID
+	method     entity.PaymentMethod
+	currency   string
+	day        string
+}
+
+func usageKey(limit *entity.MerchantLimit, day string) limitUsageKey {
+	return limitUsageKey{merchantID: limit.MerchantID, method: limit.Method, currency: limit.Currency, day: day}
+}
+
+// limitReservation 記錄付款在某個用量列累加的金額
+type limitReservation struct {
+	key    limitUsageKey
+	amount int64
+}
+
+type merchantLimitRepository struct {
+	store *Store
+}
+
+func NewMerchantLimitRepository(store *Store) repository.MerchantLimitRepository {
+	return &merchantLimitRepository{store: store}
+}
+
+func (r *merchantLimitRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID) ([]*entity.MerchantLimit, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var limits []*entity.MerchantLimit
+	for _, limit := range r.store.merchantLimits {
+		if limit.MerchantID == merchantID {
+			c := *limit
+			limits = append(limits, &c)
+		}
+	}
+	sort.Slice(limits, func(i, j int) bool {
+		if limits[i].Currency != limits[j].Currency {
+			return limits[i].Currency < limits[j].Currency
+		}
+		return limits[i].Method < limits[j].Method
+	})
+	return limits, nil
+}
+
+func (r *merchantLimitRepository) Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.merchants[merchantID]; !exists {
+		return errors.New("failed to create merchant limit: merchant does not exist")
+	}
+	seen := make(map[limitUsageKey]bool, len(limits))
+	for _, limit := range limits {
+		key := limitUsageKey{method: limit.Method, currency: limit.Currency}
+		if seen[key] {
+			return errors.New("failed to create merchant limit: duplicate method and currency")
+		}
+		seen[key] = true
+		if existing, exists := r.store.merchantLimits[limit.ID]; exists && existing.MerchantID != merchantID {
+			return errors.New("failed to create merchant limit: duplicate id")
+		}
+	}
+
+	for id, limit := range r.store.merchantLimits {
+		if limit.MerchantID == merchantID {
+			delete(r.store.merchantLimits, id)
+		}
+	}
+	for _, limit := range limits {
+		c := *limit
+		c.MerchantID = merchantID
+		r.store.merchantLimits[limit.ID] = &c
+	}
+	return nil
+}
+
+func (r *merchantLimitRepository) Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (*entity.MerchantLimit, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.limitReserved[paymentID]; exists {
+		return nil, errors.New("failed to create limit reservation: duplicate payment")
+	}
+
+	// 先檢查所有限額，全部通過才寫入，與資料庫交易回滾的結果一致
+	for _, limit := range limits {
+		var used entity.LimitUsage
+		if usage, ok := r.store.limitUsage[usageKey(limit, day)]; ok {
+			used = *usage
+		}
+		if limit.DailyAmount > 0 && used.Amount+amount > limit.DailyAmount {
+			return limit, nil
+		}
+		if limit.DailyCount > 0 && used.Count+1 > limit.DailyCount {
+			return limit, nil
+		}
+	}
+	for _, limit := range limits {
+		key := usageKey(limit, day)
+		usage, ok := r.store.limitUsage[key]
+		if !ok {
+			usage = &entity.LimitUsage{MerchantID: limit.MerchantID, Method: limit.Method, Currency: limit.Currency, Day: day}
+			r.store.limitUsage[key] = usage
+		}
+		usage.Amount += amount
+		usage.Count++
+		usage.UpdatedAt = now
+		r.store.limitReserved[paymentID] = append(r.store.limitReserved[paymentID], limitReservation{key: key, amount: amount})
+	}
+	return nil, nil
+}
+
+func (r *merchantLimitRepository) Release(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for _, res := range r.store.limitReserved[paymentID] {
+		if usage, ok := r.store.limitUsage[res.key]; ok && usage.Count > 0 {
+			usage.Amount -= res.amount
+			usage.Count--
+			usage.UpdatedAt = now
+		}
+	}
+	delete(r.store.limitReserved, paymentID)
+	return nil
+}
+
+func (r *merchantLimitRepository) GetUsage(ctx context.Context, merchantID uuid.UUID, day string) ([]*entity.LimitUsage, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var usage []*entity.LimitUsage
+	for key, u := range r.store.limitUsage {
+		if key.merchantID == merchantID && key.day == day {
+			c := *u
+			usage = append(usage, &c)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Currency != usage[j].Currency {
+			return usage[i].Currency < usage[j].Currency
+		}
+		return usage[i].Method < usage[j].Method
+	})
+	return usage, nil
+}
@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type merchantRepository struct {
+	store *Store
+}
+
+func NewMerchantRepository(store *Store) repository.MerchantRepository {
+	return &merchantRepository{store: store}
+}
+
+func (r *merchantRepository) Create(ctx context.Context, merchant *entity.Merchant) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.merchants[merchant.ID]; exists {
+		return errors.New("failed to create merchant: duplicate id")
+	}
+	if err := r.checkUnique(merchant); err != nil {
+		return errors.Wrap(err, "failed to create merchant")
+	}
+
+	c := *merchant
+	if c.Tier == "" {
+		c.Tier = entity.MerchantTierStandard
+	}
+	r.store.merchants[merchant.ID] = &c
+	return nil
+}
+
+func (r *merchantRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Merchant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	merchant, ok := r.store.merchants[id]
+	if !ok {
+		return nil, errors.New("merchant not found")
+	}
+	c := *merchant
+	return &c, nil
+}
+
+func (r *merchantRepository) GetByAPIKey(ctx context.Context, apiKey string) (*entity.Merchant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, merchant := range r.store.merchants {
+		if merchant.APIKey == apiKey {
+			c := *merchant
+			return &c, nil
+		}
+	}
+	return nil, errors.New("merchant not found")
+}
+
+func (r *merchantRepository) GetByPublishableKey(ctx context.Context, publishableKey string) (*entity.Merchant, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, merchant := range r.store.merchants {
+		if merchant.PublishableKey != "" && merchant.PublishableKey == publishableKey {
+			c := *merchant
+			return &c, nil
+		}
+	}
+	return nil, errors.New("merchant not found")
+}
+
+func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Merchant) error {
+	merchant.UpdatedAt = time.Now()
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.merchants[merchant.ID]
+	if !ok {
+		return errors.New("merchant not found")
+	}
+	if err := r.checkUnique(merchant); err != nil {
+		return errors.Wrap(err, "failed to update merchant")
+	}
+
+	c := *merchant
+	c.CreatedAt = existing.CreatedAt
+	if c.Tier == "" {
+		c.Tier = entity.MerchantTierStandard
+	}
+	r.store.merchants[merchant.ID] = &c
+	return nil
+}
+
+// Delete 為軟刪除，與 SQL 實作一致只將商戶設為停用
+func (r *merchantRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	merchant, ok := r.store.merchants[id]
+	if !ok {
+		return errors.New("merchant not found")
+	}
+	merchant.IsActive = false
+	merchant.UpdatedAt = time.Now()
+	return nil
+}
+
+// checkUnique 檢查 email、api_key 與 publishable_key 的唯一性，呼叫者需持有寫鎖
+func (r *merchantRepository) checkUnique(merchant *entity.Merchant) error {
+	for _, m := range r.store.merchants {
+		if m.ID == merchant.ID {
+			continue
+		}
+		if m.Email == merchant.Email {
+			return errors.New("duplicate merchant email")
+		}
+		if m.APIKey == merchant.APIKey {
+			return errors.New("duplicate merchant api key")
+		}
+		if merchant.PublishableKey != "" && m.PublishableKey == merchant.PublishableKey {
+			return errors.New("duplicate merchant publishable key")
+		}
+	}
+	return nil
+}
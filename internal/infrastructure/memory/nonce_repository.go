@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/repository"
+)
+
+// nonceSweepInterval 為清除到期 nonce 的最短間隔，避免每個請求都掃描整個 map
+const nonceSweepInterval = time.Minute
+
+type nonceRepository struct {
+	store *Store
+}
+
+func NewNonceRepository(store *Store) repository.NonceRepository {
+	return &nonceRepository{store: store}
+}
+
+func (r *nonceRepository) Use(ctx context.Context, key string, expiresAt, now time.Time) (bool, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if now.Sub(r.store.noncesSweptAt) >= nonceSweepInterval {
+		for k, expires := range r.store.nonces {
+			if !expires.After(now) {
+				delete(r.store.nonces, k)
+			}
+		}
+		r.store.noncesSweptAt = now
+	}
+
+	if expires, ok := r.store.nonces[key]; ok && expires.After(now) {
+		return false, nil
+	}
+	r.store.nonces[key] = expiresAt
+	return true, nil
+}
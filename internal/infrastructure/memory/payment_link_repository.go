@@ -0,0 +1,135 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type paymentLinkRepository struct {
+	store *Store
+}
+
+func NewPaymentLinkRepository(store *Store) repository.PaymentLinkRepository {
+	return &paymentLinkRepository{store: store}
+}
+
+func (r *paymentLinkRepository) Create(ctx context.Context, link *entity.PaymentLink) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.paymentLinks[link.ID]; exists {
+		return errors.New("failed to create payment link: duplicate id")
+	}
+	if _, exists := r.store.merchants[link.MerchantID]; !exists {
+		return errors.New("failed to create payment link: merchant does not exist")
+	}
+
+	r.store.paymentLinks[link.ID] = copyPaymentLink(link)
+	return nil
+}
+
+func (r *paymentLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	link, ok := r.store.paymentLinks[id]
+	if !ok {
+		return nil, errors.New("payment link not found")
+	}
+	return copyPaymentLink(link), nil
+}
+
+func (r *paymentLinkRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.PaymentLink, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var links []*entity.PaymentLink
+	for _, link := range r.store.paymentLinks {
+		if link.MerchantID == merchantID {
+			links = append(links, copyPaymentLink(link))
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].CreatedAt.After(links[j].CreatedAt)
+	})
+	return paginate(links, limit, offset), nil
+}
+
+func (r *paymentLinkRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.paymentLinks[id]
+	if !ok {
+		return errors.New("payment link not found")
+	}
+	link.IsActive = active
+	link.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *paymentLinkRepository) ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.paymentLinks[id]
+	if !ok || !link.Available(now) {
+		return errors.New("payment link not found or no longer available")
+	}
+	link.PaymentCount++
+	link.UpdatedAt = now
+	return nil
+}
+
+func (r *paymentLinkRepository) ReleasePayment(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	link, ok := r.store.paymentLinks[id]
+	if !ok || link.PaymentCount == 0 {
+		return errors.New("payment link not found or has no claimed payments")
+	}
+	link.PaymentCount--
+	link.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *paymentLinkRepository) GetStats(ctx context.Context, id uuid.UUID) (*entity.PaymentLinkStats, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	link, ok := r.store.paymentLinks[id]
+	if !ok {
+		return nil, errors.New("payment link not found")
+	}
+
+	stats := &entity.PaymentLinkStats{PaymentLinkID: id, Currency: link.Currency}
+	for _, payment := range r.store.payments {
+		if payment.PaymentLinkID == nil || *payment.PaymentLinkID != id {
+			continue
+		}
+		stats.Payments++
+		if payment.Status == entity.PaymentStatusCompleted {
+			stats.Completed++
+			stats.AmountCollected += payment.Amount
+		}
+	}
+	return stats, nil
+}
+
+func copyPaymentLink(l *entity.PaymentLink) *entity.PaymentLink {
+	c := *l
+	if l.QuantityLimit != nil {
+		limit := *l.QuantityLimit
+		c.QuantityLimit = &limit
+	}
+	c.ExpiresAt = copyTime(l.ExpiresAt)
+	c.AllowedMethods = append([]entity.PaymentMethod(nil), l.AllowedMethods...)
+	return &c
+}
@@ -0,0 +1,143 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type paymentMethodRepository struct {
+	store *Store
+}
+
+func NewPaymentMethodRepository(store *Store) repository.PaymentMethodRepository {
+	return &paymentMethodRepository{store: store}
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.paymentMethods[method.ID]; exists {
+		return errors.New("failed to create payment method: duplicate id")
+	}
+	if _, exists := r.store.customers[method.CustomerID]; !exists {
+		return errors.New("failed to create payment method: customer does not exist")
+	}
+	if _, exists := r.store.merchants[method.MerchantID]; !exists {
+		return errors.New("failed to create payment method: merchant does not exist")
+	}
+	// 對齊每位客戶只有一個預設的唯一索引
+	if method.IsDefault && r.defaultOf(method.CustomerID) != nil {
+		return errors.New("failed to create payment method: customer already has a default")
+	}
+
+	m := *method
+	r.store.paymentMethods[method.ID] = &m
+	return nil
+}
+
+func (r *paymentMethodRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.PaymentMethodRecord, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	method, ok := r.store.paymentMethods[id]
+	if !ok {
+		return nil, errors.New("payment method not found")
+	}
+	m := *method
+	return &m, nil
+}
+
+func (r *paymentMethodRepository) ListByCustomerID(ctx context.Context, customerID uuid.UUID) ([]*entity.PaymentMethodRecord, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var methods []*entity.PaymentMethodRecord
+	for _, method := range r.store.paymentMethods {
+		if method.CustomerID == customerID {
+			m := *method
+			methods = append(methods, &m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		if methods[i].IsDefault != methods[j].IsDefault {
+			return methods[i].IsDefault
+		}
+		return methods[i].CreatedAt.After(methods[j].CreatedAt)
+	})
+	return methods, nil
+}
+
+func (r *paymentMethodRepository) Update(ctx context.Context, method *entity.PaymentMethodRecord) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.paymentMethods[method.ID]
+	if !ok {
+		return errors.New("payment method not found")
+	}
+	// 與 SQL 實作相同，只更新效期與帳單資訊
+	existing.ExpMonth = method.ExpMonth
+	existing.ExpYear = method.ExpYear
+	existing.BillingDetails = method.BillingDetails
+	existing.UpdatedAt = method.UpdatedAt
+	return nil
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, ok := r.store.paymentMethods[id]; !ok {
+		return errors.New("payment method not found")
+	}
+	delete(r.store.paymentMethods, id)
+
+	// 對齊 payments 與 subscriptions 的 payment_method_id ON DELETE SET NULL
+	for _, p := range r.store.payments {
+		if p.PaymentMethodID != nil && *p.PaymentMethodID == id {
+			p.PaymentMethodID = nil
+		}
+	}
+	for _, sub := range r.store.subscriptions {
+		if sub.PaymentMethodID != nil && *sub.PaymentMethodID == id {
+			sub.PaymentMethodID = nil
+		}
+	}
+	return nil
+}
+
+func (r *paymentMethodRepository) SetDefault(ctx context.Context, customerID, id uuid.UUID) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	method, ok := r.store.paymentMethods[id]
+	if !ok || method.CustomerID != customerID {
+		return errors.New("payment method not found")
+	}
+
+	now := time.Now()
+	if current := r.defaultOf(customerID); current != nil {
+		current.IsDefault = false
+		current.UpdatedAt = now
+	}
+	method.IsDefault = true
+	method.UpdatedAt = now
+	return nil
+}
+
+// defaultOf 呼叫前需持有鎖
+func (r *paymentMethodRepository) defaultOf(customerID uuid.UUID) *entity.PaymentMethodRecord {
+	for _, method := range r.store.paymentMethods {
+		if method.CustomerID == customerID && method.IsDefault {
+			return method
+		}
+	}
+	return nil
+}
@@ -0,0 +1,208 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type paymentRepository struct {
+	store *Store
+}
+
+func NewPaymentRepository(store *Store) repository.PaymentRepository {
+	return &paymentRepository{store: store}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment *entity.Payment) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.payments[payment.ID]; exists {
+		return errors.New("failed to create payment: duplicate id")
+	}
+	if _, exists := r.store.merchants[payment.MerchantID]; !exists {
+		return errors.New("failed to create payment: merchant does not exist")
+	}
+	if _, exists := r.store.customers[payment.CustomerID]; !exists {
+		return errors.New("failed to create payment: customer does not exist")
+	}
+	if payment.PaymentMethodID != nil {
+		if _, exists := r.store.paymentMethods[*payment.PaymentMethodID]; !exists {
+			return errors.New("failed to create payment: payment method does not exist")
+		}
+	}
+	if payment.InvoiceID != nil {
+		if _, exists := r.store.invoices[*payment.InvoiceID]; !exists {
+			return errors.New("failed to create payment: invoice does not exist")
+		}
+	}
+	if payment.PaymentLinkID != nil {
+		if _, exists := r.store.paymentLinks[*payment.PaymentLinkID]; !exists {
+			return errors.New("failed to create payment: payment link does not exist")
+		}
+	}
+	// reference 為選填，空字串不納入唯一性檢查
+	if payment.Reference != "" {
+		for _, p := range r.store.payments {
+			if p.Reference == payment.Reference {
+				return errors.New("failed to create payment: duplicate reference")
+			}
+		}
+	}
+
+	r.store.payments[payment.ID] = copyPayment(payment)
+	return nil
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Payment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	payment, ok := r.store.payments[id]
+	if !ok {
+		return nil, errors.New("payment not found")
+	}
+	return copyPayment(payment), nil
+}
+
+func (r *paymentRepository) GetByReference(ctx context.Context, reference string) (*entity.Payment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, payment := range r.store.payments {
+		if payment.Reference == reference {
+			return copyPayment(payment), nil
+		}
+	}
+	return nil, errors.New("payment not found")
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payment, ok := r.store.payments[id]
+	if !ok {
+		return errors.New("payment not found")
+	}
+
+	now := time.Now()
+	payment.Status = status
+	payment.UpdatedAt = now
+	payment.CompletedAt = nil
+	if status == entity.PaymentStatusCompleted {
+		payment.CompletedAt = &now
+	}
+	return nil
+}
+
+func (r *paymentRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	payment, ok := r.store.payments[id]
+	if !ok || payment.Status != from {
+		return errors.New("payment not found or status changed")
+	}
+
+	now := time.Now()
+	payment.Status = to
+	payment.UpdatedAt = now
+	payment.CompletedAt = nil
+	if to == entity.PaymentStatusCompleted {
+		payment.CompletedAt = &now
+	}
+	return nil
+}
+
+func (r *paymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	return r.list(func(p *entity.Payment) bool { return p.MerchantID == merchantID }, limit, offset), nil
+}
+
+func (r *paymentRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	return r.list(func(p *entity.Payment) bool { return p.CustomerID == customerID }, limit, offset), nil
+}
+
+func (r *paymentRepository) GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) ([]*entity.Payment, error) {
+	return r.list(func(p *entity.Payment) bool {
+		return p.PaymentLinkID != nil && *p.PaymentLinkID == linkID
+	}, limit, offset), nil
+}
+
+func (r *paymentRepository) GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) ([]*entity.Payment, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var payments []*entity.Payment
+	for _, payment := range r.store.payments {
+		if payment.Status != entity.PaymentStatusCompleted || payment.CompletedAt == nil {
+			continue
+		}
+		if !payment.CompletedAt.Before(from) && payment.CompletedAt.Before(to) {
+			payments = append(payments, copyPayment(payment))
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		a, b := payments[i], payments[j]
+		if !a.CompletedAt.Equal(*b.CompletedAt) {
+			return a.CompletedAt.Before(*b.CompletedAt)
+		}
+		return a.ID.String() < b.ID.String()
+	})
+	return paginate(payments, limit, offset), nil
+}
+
+// list 依 created_at 由新到舊排序後分頁，與 SQL 實作的 ORDER BY created_at DESC LIMIT/OFFSET 一致
+func (r *paymentRepository) list(match func(*entity.Payment) bool, limit, offset int) []*entity.Payment {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var payments []*entity.Payment
+	for _, payment := range r.store.payments {
+		if match(payment) {
+			payments = append(payments, copyPayment(payment))
+		}
+	}
+	sort.Slice(payments, func(i, j int) bool {
+		return payments[i].CreatedAt.After(payments[j].CreatedAt)
+	})
+	return paginate(payments, limit, offset)
+}
+
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+func copyPayment(p *entity.Payment) *entity.Payment {
+	c := *p
+	if p.CompletedAt != nil {
+		completedAt := *p.CompletedAt
+		c.CompletedAt = &completedAt
+	}
+	if p.PaymentMethodID != nil {
+		methodID := *p.PaymentMethodID
+		c.PaymentMethodID = &methodID
+	}
+	if p.InvoiceID != nil {
+		invoiceID := *p.InvoiceID
+		c.InvoiceID = &invoiceID
+	}
+	c.PaymentLinkID = copyUUID(p.PaymentLinkID)
+	c.RiskReasons = append(entity.RiskReasons(nil), p.RiskReasons...)
+	// 匯款資訊由 bankTransferRepository 保存，與資料庫相同不隨付款寫入
+	c.BankTransfer = nil
+	return &c
+}
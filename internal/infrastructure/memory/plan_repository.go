@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type planRepository struct {
+	store *Store
+}
+
+func NewPlanRepository(store *Store) repository.PlanRepository {
+	return &planRepository{store: store}
+}
+
+func (r *planRepository) Create(ctx context.Context, plan *entity.Plan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.plans[plan.ID]; exists {
+		return errors.New("failed to create plan: duplicate id")
+	}
+	if _, exists := r.store.merchants[plan.MerchantID]; !exists {
+		return errors.New("failed to create plan: merchant does not exist")
+	}
+
+	p := *plan
+	r.store.plans[plan.ID] = &p
+	return nil
+}
+
+func (r *planRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Plan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	plan, ok := r.store.plans[id]
+	if !ok {
+		return nil, errors.New("plan not found")
+	}
+	p := *plan
+	return &p, nil
+}
+
+func (r *planRepository) ListByMerchantID(ctx context.Context, merchantID uuid.UUID) ([]*entity.Plan, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var plans []*entity.Plan
+	for _, plan := range r.store.plans {
+		if plan.MerchantID == merchantID {
+			p := *plan
+			plans = append(plans, &p)
+		}
+	}
+	sort.Slice(plans, func(i, j int) bool {
+		return plans[i].CreatedAt.After(plans[j].CreatedAt)
+	})
+	return plans, nil
+}
+
+func (r *planRepository) Update(ctx context.Context, plan *entity.Plan) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.plans[plan.ID]
+	if !ok {
+		return errors.New("plan not found")
+	}
+	// 與 SQL 實作相同，只更新名稱與啟用狀態
+	existing.Name = plan.Name
+	existing.IsActive = plan.IsActive
+	existing.UpdatedAt = plan.UpdatedAt
+	return nil
+}
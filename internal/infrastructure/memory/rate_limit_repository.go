@@ -0,0 +1,26 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+)
+
+type rateLimitRepository struct {
+	store *Store
+}
+
+func NewRateLimitRepository(store *Store) repository.RateLimitRepository {
+	return &rateLimitRepository{store: store}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (*entity.RateLimitResult, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	bucket, result := limit.Take(r.store.rateLimits[key], key, now)
+	r.store.rateLimits[key] = bucket
+	return result, nil
+}
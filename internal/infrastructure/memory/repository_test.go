@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/repository/repositorytest"
+)
+
+func TestRepositoryConformance(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) repositorytest.Repositories {
+		store := NewStore()
+		return repositorytest.Repositories{
+			Payments:       NewPaymentRepository(store),
+			Merchants:      NewMerchantRepository(store),
+			Customers:      NewCustomerRepository(store),
+			Cards:          NewCardRepository(store),
+			PaymentMethods: NewPaymentMethodRepository(store),
+			Plans:          NewPlanRepository(store),
+			Subscriptions:  NewSubscriptionRepository(store),
+			Invoices:       NewInvoiceRepository(store),
+			Checkouts:      NewCheckoutSessionRepository(store),
+			PaymentLinks:   NewPaymentLinkRepository(store),
+			Jobs:           NewJobRepository(store),
+			BankTransfers:  NewBankTransferRepository(store),
+			BankCredits:    NewBankCreditRepository(store),
+			Statements:     NewStatementRepository(store),
+			WalletActions:  NewWalletActionRepository(store),
+			WebhookEvents:  NewWebhookEventRepository(store),
+			Disputes:       NewDisputeRepository(store),
+			Ledger:         NewLedgerRepository(store),
+			Risk:           NewRiskRepository(store),
+			MerchantLimits: NewMerchantLimitRepository(store),
+			RateLimits:     NewRateLimitRepository(store),
+			Nonces:         NewNonceRepository(store),
+		}
+	})
+}
@@ -0,0 +1,62 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type riskRepository struct {
+	store *Store
+}
+
+func NewRiskRepository(store *Store) repository.RiskRepository {
+	return &riskRepository{store: store}
+}
+
+func (r *riskRepository) CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var match func(p *entity.Payment) bool
+	switch key {
+	case entity.RiskVelocityCustomer:
+		match = func(p *entity.Payment) bool { return p.CustomerID.String() == value }
+	case entity.RiskVelocityEmail:
+		match = func(p *entity.Payment) bool {
+			customer, ok := r.store.customers[p.CustomerID]
+			return ok && strings.EqualFold(customer.Email, value)
+		}
+	case entity.RiskVelocityIP:
+		match = func(p *entity.Payment) bool { return p.ClientIP == value }
+	default:
+		return 0, errors.New(fmt.Sprintf("unsupported velocity key %q", key))
+	}
+
+	count := 0
+	for _, payment := range r.store.payments {
+		if !payment.CreatedAt.Before(since) && match(payment) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *riskRepository) CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	count := 0
+	for _, payment := range r.store.payments {
+		if payment.CustomerID == customerID && payment.Status == entity.PaymentStatusCompleted {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,161 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type statementRepository struct {
+	store *Store
+}
+
+func NewStatementRepository(store *Store) repository.StatementRepository {
+	return &statementRepository{store: store}
+}
+
+func (r *statementRepository) Create(ctx context.Context, statement *entity.Statement) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.statements[statement.ID]; exists {
+		return errors.New("failed to create statement: duplicate id")
+	}
+	for _, line := range statement.Lines {
+		if line.PaymentID != nil {
+			if _, exists := r.store.payments[*line.PaymentID]; !exists {
+				return errors.New("failed to create statement line: payment does not exist")
+			}
+		}
+	}
+	seen := make(map[uuid.UUID]bool)
+	for _, exception := range statement.Exceptions {
+		if _, exists := r.store.payments[exception.PaymentID]; !exists {
+			return errors.New("failed to create statement exception: payment does not exist")
+		}
+		if seen[exception.PaymentID] {
+			return errors.New("failed to create statement exception: duplicate payment")
+		}
+		seen[exception.PaymentID] = true
+	}
+
+	c := copyStatement(statement)
+	for _, line := range c.Lines {
+		line.StatementID = c.ID
+	}
+	for _, exception := range c.Exceptions {
+		exception.StatementID = c.ID
+	}
+	r.store.statements[statement.ID] = c
+	return nil
+}
+
+func (r *statementRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Statement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	statement, ok := r.store.statements[id]
+	if !ok {
+		return nil, errors.New("statement not found")
+	}
+	c := copyStatement(statement)
+	sort.SliceStable(c.Lines, func(i, j int) bool {
+		a, b := c.Lines[i], c.Lines[j]
+		if !a.BookingDate.Equal(b.BookingDate) {
+			return a.BookingDate.Before(b.BookingDate)
+		}
+		return a.TransactionID < b.TransactionID
+	})
+	sort.SliceStable(c.Exceptions, func(i, j int) bool {
+		a, b := c.Exceptions[i], c.Exceptions[j]
+		if !a.CompletedAt.Equal(b.CompletedAt) {
+			return a.CompletedAt.Before(b.CompletedAt)
+		}
+		return a.PaymentID.String() < b.PaymentID.String()
+	})
+	return c, nil
+}
+
+func (r *statementRepository) List(ctx context.Context, limit, offset int) ([]*entity.Statement, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var statements []*entity.Statement
+	for _, statement := range r.store.statements {
+		c := *statement
+		c.Lines = nil
+		c.Exceptions = nil
+		statements = append(statements, &c)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		return statements[i].CreatedAt.After(statements[j].CreatedAt)
+	})
+	return paginate(statements, limit, offset), nil
+}
+
+func (r *statementRepository) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if paymentID != nil {
+		if _, exists := r.store.payments[*paymentID]; !exists {
+			return errors.New("failed to resolve statement line: payment does not exist")
+		}
+	}
+	if statement, ok := r.store.statements[statementID]; ok {
+		for _, line := range statement.Lines {
+			if line.ID != lineID || line.Status != entity.ReconciliationStatusUnmatched {
+				continue
+			}
+			line.Status = entity.ReconciliationStatusResolved
+			if paymentID != nil {
+				line.PaymentID = copyUUID(paymentID)
+			}
+			line.Note = note
+			line.ResolvedAt = &now
+			return nil
+		}
+	}
+	return errors.New("statement line not found or not unmatched")
+}
+
+func (r *statementRepository) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if statement, ok := r.store.statements[statementID]; ok {
+		for _, exception := range statement.Exceptions {
+			if exception.PaymentID != paymentID || exception.Status != entity.ReconciliationStatusUnmatched {
+				continue
+			}
+			exception.Status = entity.ReconciliationStatusResolved
+			exception.Note = note
+			exception.ResolvedAt = &now
+			return nil
+		}
+	}
+	return errors.New("statement exception not found or not unmatched")
+}
+
+func copyStatement(s *entity.Statement) *entity.Statement {
+	statement := *s
+	statement.Lines = make([]*entity.StatementLine, len(s.Lines))
+	for i, l := range s.Lines {
+		line := *l
+		line.PaymentID = copyUUID(l.PaymentID)
+		line.ResolvedAt = copyTime(l.ResolvedAt)
+		statement.Lines[i] = &line
+	}
+	statement.Exceptions = make([]*entity.StatementException, len(s.Exceptions))
+	for i, e := range s.Exceptions {
+		exception := *e
+		exception.ResolvedAt = copyTime(e.ResolvedAt)
+		statement.Exceptions[i] = &exception
+	}
+	return &statement
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"sync"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/google/uuid"
+)
+
+// Store 是所有記憶體 repository 共用的資料儲存，行為對齊 PostgreSQL schema
+// （唯一鍵、外鍵），供測試與本地開發使用。
+type Store struct {
+	mu               sync.RWMutex
+	payments         map[uuid.UUID]*entity.Payment
+	merchants        map[uuid.UUID]*entity.Merchant
+	customers        map[uuid.UUID]*entity.Customer
+	cards            map[string]*entity.Card
+	paymentMethods   map[uuid.UUID]*entity.PaymentMethodRecord
+	plans            map[uuid.UUID]*entity.Plan
+	subscriptions    map[uuid.UUID]*entity.Subscription
+	billingLocks     map[uuid.UUID]time.Time // 以訂閱 ID 為鍵，值為鎖定到期時間
+	invoices         map[uuid.UUID]*entity.Invoice
+	checkoutSessions map[uuid.UUID]*entity.CheckoutSession
+	paymentLinks     map[uuid.UUID]*entity.PaymentLink
+	jobs             map[uuid.UUID]*entity.Job
+	bankTransfers    map[uuid.UUID]*entity.BankTransfer // 以付款 ID 為鍵
+	bankCredits      map[uuid.UUID]*entity.BankCredit
+	statements       map[uuid.UUID]*entity.Statement    // 交易與差異付款存放在對帳單內
+	walletActions    map[uuid.UUID]*entity.WalletAction // 以付款 ID 為鍵
+	webhookEvents    map[uuid.UUID]*entity.WebhookEvent
+	disputes         map[uuid.UUID]*entity.Dispute // 證據存放在爭議內
+	disputeEvents    map[uuid.UUID]*entity.DisputeEvent
+	ledgerEntries    map[uuid.UUID]*entity.LedgerEntry
+	merchantLimits   map[uuid.UUID]*entity.MerchantLimit
+	limitUsage       map[limitUsageKey]*entity.LimitUsage
+	limitReserved    map[uuid.UUID][]limitReservation // 以付款 ID 為鍵
+	rateLimits       map[string]*entity.RateLimitBucket
+	nonces           map[string]time.Time // 以到期時間為值
+	noncesSweptAt    time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		payments:         make(map[uuid.UUID]*entity.Payment),
+		merchants:        make(map[uuid.UUID]*entity.Merchant),
+		customers:        make(map[uuid.UUID]*entity.Customer),
+		cards:            make(map[string]*entity.Card),
+		paymentMethods:   make(map[uuid.UUID]*entity.PaymentMethodRecord),
+		plans:            make(map[uuid.UUID]*entity.Plan),
+		subscriptions:    make(map[uuid.UUID]*entity.Subscription),
+		billingLocks:     make(map[uuid.UUID]time.Time),
+		invoices:         make(map[uuid.UUID]*entity.Invoice),
+		checkoutSessions: make(map[uuid.UUID]*entity.CheckoutSession),
+		paymentLinks:     make(map[uuid.UUID]*entity.PaymentLink),
+		jobs:             make(map[uuid.UUID]*entity.Job),
+		bankTransfers:    make(map[uuid.UUID]*entity.BankTransfer),
+		bankCredits:      make(map[uuid.UUID]*entity.BankCredit),
+		statements:       make(map[uuid.UUID]*entity.Statement),
+		walletActions:    make(map[uuid.UUID]*entity.WalletAction),
+		webhookEvents:    make(map[uuid.UUID]*entity.WebhookEvent),
+		disputes:         make(map[uuid.UUID]*entity.Dispute),
+		disputeEvents:    make(map[uuid.UUID]*entity.DisputeEvent),
+		ledgerEntries:    make(map[uuid.UUID]*entity.LedgerEntry),
+		merchantLimits:   make(map[uuid.UUID]*entity.MerchantLimit),
+		limitUsage:       make(map[limitUsageKey]*entity.LimitUsage),
+		limitReserved:    make(map[uuid.UUID][]limitReservation),
+		rateLimits:       make(map[string]*entity.RateLimitBucket),
+		nonces:           make(map[string]time.Time),
+	}
+}
+
+// LoadSampleData 載入與 001_initial_schema.sql（及後續遷移補上的商戶欄位）相同的測試資料
+func (s *Store) LoadSampleData() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	merchants := []*entity.Merchant{
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"), Name: "Test Merchant 1", Email: "merchant1@example.com", APIKey: "api_key_merchant_1", IsActive: true,
+			PublishableKey: "pk_merchant_1", AllowedOrigins: entity.AllowedOrigins{"http://localhost:3000"}, SigningSecret: "signing_secret_merchant_1"},
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440002"), Name: "Test Merchant 2", Email: "merchant2@example.com", APIKey: "api_key_merchant_2", IsActive: true,
+			PublishableKey: "pk_merchant_2"},
+	}
+	for _, m := range merchants {
+		m.Tier = entity.MerchantTierStandard
+		m.CreatedAt, m.UpdatedAt = now, now
+		s.merchants[m.ID] = m
+	}
+
+	customers := []*entity.Customer{
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440101"), Name: "John Doe", Email: "john@example.com", Phone: "+1234567890"},
+		{ID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440102"), Name: "Jane Smith", Email: "jane@example.com", Phone: "+1234567891"},
+	}
+	for _, c := range customers {
+		c.CreatedAt, c.UpdatedAt = now, now
+		s.customers[c.ID] = c
+	}
+}
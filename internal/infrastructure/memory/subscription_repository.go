@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type subscriptionRepository struct {
+	store *Store
+}
+
+func NewSubscriptionRepository(store *Store) repository.SubscriptionRepository {
+	return &subscriptionRepository{store: store}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, sub *entity.Subscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.subscriptions[sub.ID]; exists {
+		return errors.New("failed to create subscription: duplicate id")
+	}
+	if err := r.checkReferences(sub); err != nil {
+		return errors.Wrap(err, "failed to create subscription")
+	}
+
+	r.store.subscriptions[sub.ID] = copySubscription(sub)
+	return nil
+}
+
+func (r *subscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.Subscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	sub, ok := r.store.subscriptions[id]
+	if !ok {
+		return nil, errors.New("subscription not found")
+	}
+	return copySubscription(sub), nil
+}
+
+func (r *subscriptionRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) ([]*entity.Subscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var subs []*entity.Subscription
+	for _, sub := range r.store.subscriptions {
+		if sub.MerchantID == merchantID {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CreatedAt.After(subs[j].CreatedAt)
+	})
+	return paginate(subs, limit, offset), nil
+}
+
+func (r *subscriptionRepository) Update(ctx context.Context, sub *entity.Subscription) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.subscriptions[sub.ID]
+	if !ok {
+		return errors.New("subscription not found")
+	}
+	if err := r.checkReferences(sub); err != nil {
+		return errors.Wrap(err, "failed to update subscription")
+	}
+
+	// 與 SQL 實作相同，商戶、客戶、anchor 與建立時間不可變更
+	updated := copySubscription(sub)
+	updated.MerchantID = existing.MerchantID
+	updated.CustomerID = existing.CustomerID
+	updated.BillingAnchor = existing.BillingAnchor
+	updated.CreatedAt = existing.CreatedAt
+	r.store.subscriptions[sub.ID] = updated
+	return nil
+}
+
+func (r *subscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]*entity.Subscription, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var subs []*entity.Subscription
+	for _, sub := range r.store.subscriptions {
+		if r.claimable(sub, now) {
+			subs = append(subs, copySubscription(sub))
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool {
+		return subs[i].CurrentPeriodEnd.Before(subs[j].CurrentPeriodEnd)
+	})
+	return paginate(subs, limit, 0), nil
+}
+
+func (r *subscriptionRepository) Claim(ctx context.Context, id uuid.UUID, now, until time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	sub, ok := r.store.subscriptions[id]
+	if !ok || !r.claimable(sub, now) {
+		return errors.New("subscription not found, not due or already claimed")
+	}
+	r.store.billingLocks[id] = until
+	return nil
+}
+
+// claimable 回傳訂閱是否到期且未被鎖定，呼叫者需持有鎖
+func (r *subscriptionRepository) claimable(sub *entity.Subscription, now time.Time) bool {
+	switch sub.Status {
+	case entity.SubscriptionStatusTrialing, entity.SubscriptionStatusActive, entity.SubscriptionStatusPastDue:
+	default:
+		return false
+	}
+	if lockedUntil, ok := r.store.billingLocks[sub.ID]; ok && lockedUntil.After(now) {
+		return false
+	}
+	due := sub.NextRetryAt == nil && !sub.CurrentPeriodEnd.After(now)
+	retry := sub.NextRetryAt != nil && !sub.NextRetryAt.After(now)
+	return due || retry
+}
+
+// checkReferences 對齊 subscriptions 的外鍵，呼叫者需持有鎖
+func (r *subscriptionRepository) checkReferences(sub *entity.Subscription) error {
+	if _, exists := r.store.merchants[sub.MerchantID]; !exists {
+		return errors.New("merchant does not exist")
+	}
+	if _, exists := r.store.customers[sub.CustomerID]; !exists {
+		return errors.New("customer does not exist")
+	}
+	if _, exists := r.store.plans[sub.PlanID]; !exists {
+		return errors.New("plan does not exist")
+	}
+	if sub.PaymentMethodID != nil {
+		if _, exists := r.store.paymentMethods[*sub.PaymentMethodID]; !exists {
+			return errors.New("payment method does not exist")
+		}
+	}
+	if sub.LatestPaymentID != nil {
+		if _, exists := r.store.payments[*sub.LatestPaymentID]; !exists {
+			return errors.New("payment does not exist")
+		}
+	}
+	return nil
+}
+
+func copySubscription(s *entity.Subscription) *entity.Subscription {
+	c := *s
+	c.PaymentMethodID = copyUUID(s.PaymentMethodID)
+	c.LatestPaymentID = copyUUID(s.LatestPaymentID)
+	c.TrialEnd = copyTime(s.TrialEnd)
+	c.CanceledAt = copyTime(s.CanceledAt)
+	c.NextRetryAt = copyTime(s.NextRetryAt)
+	return &c
+}
+
+func copyUUID(id *uuid.UUID) *uuid.UUID {
+	if id == nil {
+		return nil
+	}
+	c := *id
+	return &c
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	c := *t
+	return &c
+}
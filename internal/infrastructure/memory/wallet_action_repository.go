@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type walletActionRepository struct {
+	store *Store
+}
+
+func NewWalletActionRepository(store *Store) repository.WalletActionRepository {
+	return &walletActionRepository{store: store}
+}
+
+func (r *walletActionRepository) Create(ctx context.Context, action *entity.WalletAction) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.walletActions[action.PaymentID]; exists {
+		return errors.New("failed to create wallet action: duplicate payment id")
+	}
+	if _, exists := r.store.payments[action.PaymentID]; !exists {
+		return errors.New("failed to create wallet action: payment does not exist")
+	}
+	if _, exists := r.store.merchants[action.MerchantID]; !exists {
+		return errors.New("failed to create wallet action: merchant does not exist")
+	}
+
+	c := *action
+	r.store.walletActions[action.PaymentID] = &c
+	return nil
+}
+
+func (r *walletActionRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (*entity.WalletAction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	action, ok := r.store.walletActions[paymentID]
+	if !ok {
+		return nil, errors.New("wallet action not found")
+	}
+	c := *action
+	return &c, nil
+}
+
+func (r *walletActionRepository) Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	action, ok := r.store.walletActions[paymentID]
+	if !ok || action.Status != entity.WalletActionStatusPending || !action.ExpiresAt.After(now) {
+		return errors.New("wallet action not found or no longer pending")
+	}
+	action.Status = status
+	action.UpdatedAt = now
+	return nil
+}
+
+func (r *walletActionRepository) ListExpired(ctx context.Context, now time.Time, limit int) ([]*entity.WalletAction, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var actions []*entity.WalletAction
+	for _, action := range r.store.walletActions {
+		if action.Status == entity.WalletActionStatusPending && !action.ExpiresAt.After(now) {
+			c := *action
+			actions = append(actions, &c)
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].ExpiresAt.Before(actions[j].ExpiresAt)
+	})
+	return paginate(actions, limit, 0), nil
+}
+
+func (r *walletActionRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	action, ok := r.store.walletActions[paymentID]
+	if !ok || action.Status != entity.WalletActionStatusPending || action.ExpiresAt.After(now) {
+		return errors.New("wallet action not found or no longer pending")
+	}
+	action.Status = entity.WalletActionStatusExpired
+	action.UpdatedAt = now
+	return nil
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/company/payment-service/pkg/errors"
+	"github.com/google/uuid"
+)
+
+type webhookEventRepository struct {
+	store *Store
+}
+
+func NewWebhookEventRepository(store *Store) repository.WebhookEventRepository {
+	return &webhookEventRepository{store: store}
+}
+
+func (r *webhookEventRepository) Create(ctx context.Context, event *entity.WebhookEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if _, exists := r.store.webhookEvents[event.ID]; exists {
+		return errors.New("failed to create webhook event: duplicate id")
+	}
+	for _, existing := range r.store.webhookEvents {
+		if existing.Provider == event.Provider && existing.EventID == event.EventID {
+			return errors.New("failed to create webhook event: duplicate event id")
+		}
+	}
+
+	r.store.webhookEvents[event.ID] = copyWebhookEvent(event)
+	return nil
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (*entity.WebhookEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	event, ok := r.store.webhookEvents[id]
+	if !ok {
+		return nil, errors.New("webhook event not found")
+	}
+	return copyWebhookEvent(event), nil
+}
+
+func (r *webhookEventRepository) GetByEventID(ctx context.Context, provider, eventID string) (*entity.WebhookEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for _, event := range r.store.webhookEvents {
+		if event.Provider == provider && event.EventID == eventID {
+			return copyWebhookEvent(event), nil
+		}
+	}
+	return nil, errors.New("webhook event not found")
+}
+
+func (r *webhookEventRepository) Update(ctx context.Context, event *entity.WebhookEvent) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	existing, ok := r.store.webhookEvents[event.ID]
+	if !ok {
+		return errors.New("webhook event not found")
+	}
+	existing.Status = event.Status
+	existing.PaymentID = copyUUID(event.PaymentID)
+	existing.Error = event.Error
+	existing.Attempts = event.Attempts
+	existing.ProcessedAt = copyTime(event.ProcessedAt)
+	existing.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *webhookEventRepository) List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) ([]*entity.WebhookEvent, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var events []*entity.WebhookEvent
+	for _, event := range r.store.webhookEvents {
+		if (provider == "" || event.Provider == provider) && (status == "" || event.Status == status) {
+			events = append(events, copyWebhookEvent(event))
+		}
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	return paginate(events, limit, offset), nil
+}
+
+func copyWebhookEvent(e *entity.WebhookEvent) *entity.WebhookEvent {
+	event := *e
+	event.PaymentID = copyUUID(e.PaymentID)
+	event.ProcessedAt = copyTime(e.ProcessedAt)
+	return &event
+}
@@ -0,0 +1,115 @@
+// Package metrics 以 Prometheus 格式輸出 HTTP、業務與資料庫指標。
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "payment_service"
+
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+
+	payments       *prometheus.CounterVec
+	paymentAmounts *prometheus.CounterVec
+
+	repositoryDuration *prometheus.HistogramVec
+}
+
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Total number of HTTP requests by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by method, route and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		payments: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payments_total",
+			Help:      "Payments by lifecycle event (created, completed, cancelled), method and currency.",
+		}, []string{"event", "method", "currency"}),
+		paymentAmounts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "payment_amount_minor_units_total",
+			Help:      "Sum of payment amounts in minor currency units by lifecycle event, method and currency.",
+		}, []string{"event", "method", "currency"}),
+		repositoryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "repository_query_duration_seconds",
+			Help:      "Repository method latency by repository, method and outcome.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"repository", "method", "outcome"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.httpDuration,
+		m.payments,
+		m.paymentAmounts,
+		m.repositoryDuration,
+	)
+	return m
+}
+
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
+
+// RegisterDBStats 以 sql.DBStats 輸出連線池 gauge，name 會成為 db_name 標籤
+func (m *Metrics) RegisterDBStats(name string, db *sql.DB) {
+	m.registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	labels := prometheus.Labels{"method": method, "route": route, "status": strconv.Itoa(status)}
+	m.httpRequests.With(labels).Inc()
+	m.httpDuration.With(labels).Observe(duration.Seconds())
+}
+
+// PaymentCreated 與 PaymentStatusChanged 實作 usecase.PaymentObserver
+func (m *Metrics) PaymentCreated(ctx context.Context, payment *entity.Payment) {
+	m.recordPayment("created", payment)
+}
+
+func (m *Metrics) PaymentStatusChanged(ctx context.Context, payment *entity.Payment, previous entity.PaymentStatus) {
+	m.recordPayment(string(payment.Status), payment)
+}
+
+func (m *Metrics) recordPayment(event string, payment *entity.Payment) {
+	labels := prometheus.Labels{
+		"event":    event,
+		"method":   string(payment.Method),
+		"currency": payment.Currency,
+	}
+	m.payments.With(labels).Inc()
+	m.paymentAmounts.With(labels).Add(float64(payment.Amount))
+}
+
+func (m *Metrics) observeQuery(repository, method string, start time.Time, err error) {
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	m.repositoryDuration.WithLabelValues(repository, method, outcome).Observe(time.Since(start).Seconds())
+}
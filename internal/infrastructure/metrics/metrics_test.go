@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/infrastructure/memory"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaymentObserver(t *testing.T) {
+	m := New()
+	ctx := context.Background()
+	payment := &entity.Payment{Amount: 2500, Currency: "USD", Method: entity.PaymentMethodCreditCard, Status: entity.PaymentStatusPending}
+
+	m.PaymentCreated(ctx, payment)
+	payment.Status = entity.PaymentStatusCompleted
+	m.PaymentStatusChanged(ctx, payment, entity.PaymentStatusPending)
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.payments.WithLabelValues("created", "credit_card", "USD")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.payments.WithLabelValues("completed", "credit_card", "USD")))
+	assert.Equal(t, float64(2500), testutil.ToFloat64(m.paymentAmounts.WithLabelValues("completed", "credit_card", "USD")))
+}
+
+func TestInstrumentedRepository(t *testing.T) {
+	m := New()
+	repo := InstrumentPaymentRepository(memory.NewPaymentRepository(memory.NewStore()), m)
+
+	_, err := repo.GetByID(context.Background(), uuid.New())
+	require.Error(t, err)
+
+	assert.Equal(t, 1, testutil.CollectAndCount(m.repositoryDuration, "payment_service_repository_query_duration_seconds"))
+	expected := `payment_service_repository_query_duration_seconds_count{method="GetByID",outcome="error",repository="payment"} 1`
+	body := scrape(t, m)
+	assert.Contains(t, body, expected)
+}
+
+func TestHandlerExposesHTTPMetrics(t *testing.T) {
+	m := New()
+	m.ObserveHTTPRequest(http.MethodPost, "/api/v1/payments", http.StatusCreated, 15*time.Millisecond)
+
+	body := scrape(t, m)
+	assert.Contains(t, body, `payment_service_http_requests_total{method="POST",route="/api/v1/payments",status="201"} 1`)
+	assert.Contains(t, body, "go_goroutines")
+}
+
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	return strings.TrimSpace(rec.Body.String())
+}
@@ -0,0 +1,721 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/google/uuid"
+)
+
+// 以下 decorator 為每個 repository 方法記錄延遲；內嵌原介面，
+// 新增但尚未在此覆寫的方法會直接轉呼叫而不計量。
+
+type paymentRepository struct {
+	repository.PaymentRepository
+	m *Metrics
+}
+
+func InstrumentPaymentRepository(repo repository.PaymentRepository, m *Metrics) repository.PaymentRepository {
+	return &paymentRepository{PaymentRepository: repo, m: m}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment *entity.Payment) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "Create", start, err) }(time.Now())
+	return r.PaymentRepository.Create(ctx, payment)
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetByID", start, err) }(time.Now())
+	return r.PaymentRepository.GetByID(ctx, id)
+}
+
+func (r *paymentRepository) GetByReference(ctx context.Context, reference string) (_ *entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetByReference", start, err) }(time.Now())
+	return r.PaymentRepository.GetByReference(ctx, reference)
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "UpdateStatus", start, err) }(time.Now())
+	return r.PaymentRepository.UpdateStatus(ctx, id, status)
+}
+
+func (r *paymentRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "TransitionStatus", start, err) }(time.Now())
+	return r.PaymentRepository.TransitionStatus(ctx, id, from, to)
+}
+
+func (r *paymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetByMerchantID", start, err) }(time.Now())
+	return r.PaymentRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *paymentRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetByCustomerID", start, err) }(time.Now())
+	return r.PaymentRepository.GetByCustomerID(ctx, customerID, limit, offset)
+}
+
+func (r *paymentRepository) GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetByPaymentLinkID", start, err) }(time.Now())
+	return r.PaymentRepository.GetByPaymentLinkID(ctx, linkID, limit, offset)
+}
+
+func (r *paymentRepository) GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) (_ []*entity.Payment, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment", "GetCompletedBetween", start, err) }(time.Now())
+	return r.PaymentRepository.GetCompletedBetween(ctx, from, to, limit, offset)
+}
+
+type merchantRepository struct {
+	repository.MerchantRepository
+	m *Metrics
+}
+
+func InstrumentMerchantRepository(repo repository.MerchantRepository, m *Metrics) repository.MerchantRepository {
+	return &merchantRepository{MerchantRepository: repo, m: m}
+}
+
+func (r *merchantRepository) Create(ctx context.Context, merchant *entity.Merchant) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "Create", start, err) }(time.Now())
+	return r.MerchantRepository.Create(ctx, merchant)
+}
+
+func (r *merchantRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Merchant, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "GetByID", start, err) }(time.Now())
+	return r.MerchantRepository.GetByID(ctx, id)
+}
+
+func (r *merchantRepository) GetByAPIKey(ctx context.Context, apiKey string) (_ *entity.Merchant, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "GetByAPIKey", start, err) }(time.Now())
+	return r.MerchantRepository.GetByAPIKey(ctx, apiKey)
+}
+
+func (r *merchantRepository) GetByPublishableKey(ctx context.Context, publishableKey string) (_ *entity.Merchant, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "GetByPublishableKey", start, err) }(time.Now())
+	return r.MerchantRepository.GetByPublishableKey(ctx, publishableKey)
+}
+
+func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Merchant) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "Update", start, err) }(time.Now())
+	return r.MerchantRepository.Update(ctx, merchant)
+}
+
+func (r *merchantRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant", "Delete", start, err) }(time.Now())
+	return r.MerchantRepository.Delete(ctx, id)
+}
+
+type customerRepository struct {
+	repository.CustomerRepository
+	m *Metrics
+}
+
+func InstrumentCustomerRepository(repo repository.CustomerRepository, m *Metrics) repository.CustomerRepository {
+	return &customerRepository{CustomerRepository: repo, m: m}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("customer", "Create", start, err) }(time.Now())
+	return r.CustomerRepository.Create(ctx, customer)
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Customer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("customer", "GetByID", start, err) }(time.Now())
+	return r.CustomerRepository.GetByID(ctx, id)
+}
+
+func (r *customerRepository) GetByEmail(ctx context.Context, email string) (_ *entity.Customer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("customer", "GetByEmail", start, err) }(time.Now())
+	return r.CustomerRepository.GetByEmail(ctx, email)
+}
+
+func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("customer", "Update", start, err) }(time.Now())
+	return r.CustomerRepository.Update(ctx, customer)
+}
+
+func (r *customerRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("customer", "Delete", start, err) }(time.Now())
+	return r.CustomerRepository.Delete(ctx, id)
+}
+
+type cardRepository struct {
+	repository.CardRepository
+	m *Metrics
+}
+
+func InstrumentCardRepository(repo repository.CardRepository, m *Metrics) repository.CardRepository {
+	return &cardRepository{CardRepository: repo, m: m}
+}
+
+func (r *cardRepository) Create(ctx context.Context, card *entity.Card) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("card", "Create", start, err) }(time.Now())
+	return r.CardRepository.Create(ctx, card)
+}
+
+func (r *cardRepository) GetByToken(ctx context.Context, token string) (_ *entity.Card, err error) {
+	defer func(start time.Time) { r.m.observeQuery("card", "GetByToken", start, err) }(time.Now())
+	return r.CardRepository.GetByToken(ctx, token)
+}
+
+type paymentMethodRepository struct {
+	repository.PaymentMethodRepository
+	m *Metrics
+}
+
+func InstrumentPaymentMethodRepository(repo repository.PaymentMethodRepository, m *Metrics) repository.PaymentMethodRepository {
+	return &paymentMethodRepository{PaymentMethodRepository: repo, m: m}
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, method *entity.PaymentMethodRecord) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "Create", start, err) }(time.Now())
+	return r.PaymentMethodRepository.Create(ctx, method)
+}
+
+func (r *paymentMethodRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.PaymentMethodRecord, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "GetByID", start, err) }(time.Now())
+	return r.PaymentMethodRepository.GetByID(ctx, id)
+}
+
+func (r *paymentMethodRepository) ListByCustomerID(ctx context.Context, customerID uuid.UUID) (_ []*entity.PaymentMethodRecord, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "ListByCustomerID", start, err) }(time.Now())
+	return r.PaymentMethodRepository.ListByCustomerID(ctx, customerID)
+}
+
+func (r *paymentMethodRepository) Update(ctx context.Context, method *entity.PaymentMethodRecord) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "Update", start, err) }(time.Now())
+	return r.PaymentMethodRepository.Update(ctx, method)
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "Delete", start, err) }(time.Now())
+	return r.PaymentMethodRepository.Delete(ctx, id)
+}
+
+func (r *paymentMethodRepository) SetDefault(ctx context.Context, customerID, id uuid.UUID) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_method", "SetDefault", start, err) }(time.Now())
+	return r.PaymentMethodRepository.SetDefault(ctx, customerID, id)
+}
+
+type planRepository struct {
+	repository.PlanRepository
+	m *Metrics
+}
+
+func InstrumentPlanRepository(repo repository.PlanRepository, m *Metrics) repository.PlanRepository {
+	return &planRepository{PlanRepository: repo, m: m}
+}
+
+func (r *planRepository) Create(ctx context.Context, plan *entity.Plan) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("plan", "Create", start, err) }(time.Now())
+	return r.PlanRepository.Create(ctx, plan)
+}
+
+func (r *planRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Plan, err error) {
+	defer func(start time.Time) { r.m.observeQuery("plan", "GetByID", start, err) }(time.Now())
+	return r.PlanRepository.GetByID(ctx, id)
+}
+
+func (r *planRepository) ListByMerchantID(ctx context.Context, merchantID uuid.UUID) (_ []*entity.Plan, err error) {
+	defer func(start time.Time) { r.m.observeQuery("plan", "ListByMerchantID", start, err) }(time.Now())
+	return r.PlanRepository.ListByMerchantID(ctx, merchantID)
+}
+
+func (r *planRepository) Update(ctx context.Context, plan *entity.Plan) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("plan", "Update", start, err) }(time.Now())
+	return r.PlanRepository.Update(ctx, plan)
+}
+
+type subscriptionRepository struct {
+	repository.SubscriptionRepository
+	m *Metrics
+}
+
+func InstrumentSubscriptionRepository(repo repository.SubscriptionRepository, m *Metrics) repository.SubscriptionRepository {
+	return &subscriptionRepository{SubscriptionRepository: repo, m: m}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, subscription *entity.Subscription) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "Create", start, err) }(time.Now())
+	return r.SubscriptionRepository.Create(ctx, subscription)
+}
+
+func (r *subscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Subscription, err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "GetByID", start, err) }(time.Now())
+	return r.SubscriptionRepository.GetByID(ctx, id)
+}
+
+func (r *subscriptionRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Subscription, err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "GetByMerchantID", start, err) }(time.Now())
+	return r.SubscriptionRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *subscriptionRepository) Update(ctx context.Context, subscription *entity.Subscription) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "Update", start, err) }(time.Now())
+	return r.SubscriptionRepository.Update(ctx, subscription)
+}
+
+func (r *subscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) (_ []*entity.Subscription, err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "ListDue", start, err) }(time.Now())
+	return r.SubscriptionRepository.ListDue(ctx, now, limit)
+}
+
+func (r *subscriptionRepository) Claim(ctx context.Context, id uuid.UUID, now, until time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("subscription", "Claim", start, err) }(time.Now())
+	return r.SubscriptionRepository.Claim(ctx, id, now, until)
+}
+
+type invoiceRepository struct {
+	repository.InvoiceRepository
+	m *Metrics
+}
+
+func InstrumentInvoiceRepository(repo repository.InvoiceRepository, m *Metrics) repository.InvoiceRepository {
+	return &invoiceRepository{InvoiceRepository: repo, m: m}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("invoice", "Create", start, err) }(time.Now())
+	return r.InvoiceRepository.Create(ctx, invoice)
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Invoice, err error) {
+	defer func(start time.Time) { r.m.observeQuery("invoice", "GetByID", start, err) }(time.Now())
+	return r.InvoiceRepository.GetByID(ctx, id)
+}
+
+func (r *invoiceRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Invoice, err error) {
+	defer func(start time.Time) { r.m.observeQuery("invoice", "GetByMerchantID", start, err) }(time.Now())
+	return r.InvoiceRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice *entity.Invoice) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("invoice", "Update", start, err) }(time.Now())
+	return r.InvoiceRepository.Update(ctx, invoice)
+}
+
+type checkoutSessionRepository struct {
+	repository.CheckoutSessionRepository
+	m *Metrics
+}
+
+func InstrumentCheckoutSessionRepository(repo repository.CheckoutSessionRepository, m *Metrics) repository.CheckoutSessionRepository {
+	return &checkoutSessionRepository{CheckoutSessionRepository: repo, m: m}
+}
+
+func (r *checkoutSessionRepository) Create(ctx context.Context, session *entity.CheckoutSession) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("checkout_session", "Create", start, err) }(time.Now())
+	return r.CheckoutSessionRepository.Create(ctx, session)
+}
+
+func (r *checkoutSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.CheckoutSession, err error) {
+	defer func(start time.Time) { r.m.observeQuery("checkout_session", "GetByID", start, err) }(time.Now())
+	return r.CheckoutSessionRepository.GetByID(ctx, id)
+}
+
+func (r *checkoutSessionRepository) Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("checkout_session", "Transition", start, err) }(time.Now())
+	return r.CheckoutSessionRepository.Transition(ctx, session, from)
+}
+
+type paymentLinkRepository struct {
+	repository.PaymentLinkRepository
+	m *Metrics
+}
+
+func InstrumentPaymentLinkRepository(repo repository.PaymentLinkRepository, m *Metrics) repository.PaymentLinkRepository {
+	return &paymentLinkRepository{PaymentLinkRepository: repo, m: m}
+}
+
+func (r *paymentLinkRepository) Create(ctx context.Context, link *entity.PaymentLink) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "Create", start, err) }(time.Now())
+	return r.PaymentLinkRepository.Create(ctx, link)
+}
+
+func (r *paymentLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.PaymentLink, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "GetByID", start, err) }(time.Now())
+	return r.PaymentLinkRepository.GetByID(ctx, id)
+}
+
+func (r *paymentLinkRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.PaymentLink, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "GetByMerchantID", start, err) }(time.Now())
+	return r.PaymentLinkRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *paymentLinkRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "SetActive", start, err) }(time.Now())
+	return r.PaymentLinkRepository.SetActive(ctx, id, active)
+}
+
+func (r *paymentLinkRepository) ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "ClaimPayment", start, err) }(time.Now())
+	return r.PaymentLinkRepository.ClaimPayment(ctx, id, now)
+}
+
+func (r *paymentLinkRepository) ReleasePayment(ctx context.Context, id uuid.UUID) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "ReleasePayment", start, err) }(time.Now())
+	return r.PaymentLinkRepository.ReleasePayment(ctx, id)
+}
+
+func (r *paymentLinkRepository) GetStats(ctx context.Context, id uuid.UUID) (_ *entity.PaymentLinkStats, err error) {
+	defer func(start time.Time) { r.m.observeQuery("payment_link", "GetStats", start, err) }(time.Now())
+	return r.PaymentLinkRepository.GetStats(ctx, id)
+}
+
+type jobRepository struct {
+	repository.JobRepository
+	m *Metrics
+}
+
+func InstrumentJobRepository(repo repository.JobRepository, m *Metrics) repository.JobRepository {
+	return &jobRepository{JobRepository: repo, m: m}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.Job) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "Enqueue", start, err) }(time.Now())
+	return r.JobRepository.Enqueue(ctx, job)
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Job, err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "GetByID", start, err) }(time.Now())
+	return r.JobRepository.GetByID(ctx, id)
+}
+
+func (r *jobRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) (_ []*entity.Job, err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "Claim", start, err) }(time.Now())
+	return r.JobRepository.Claim(ctx, now, visibility, limit)
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, attempts int) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "Complete", start, err) }(time.Now())
+	return r.JobRepository.Complete(ctx, id, attempts)
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "Retry", start, err) }(time.Now())
+	return r.JobRepository.Retry(ctx, id, attempts, runAt, lastError)
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("job", "Fail", start, err) }(time.Now())
+	return r.JobRepository.Fail(ctx, id, attempts, lastError)
+}
+
+type bankTransferRepository struct {
+	repository.BankTransferRepository
+	m *Metrics
+}
+
+func InstrumentBankTransferRepository(repo repository.BankTransferRepository, m *Metrics) repository.BankTransferRepository {
+	return &bankTransferRepository{BankTransferRepository: repo, m: m}
+}
+
+func (r *bankTransferRepository) Create(ctx context.Context, transfer *entity.BankTransfer) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "Create", start, err) }(time.Now())
+	return r.BankTransferRepository.Create(ctx, transfer)
+}
+
+func (r *bankTransferRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.BankTransfer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "GetByPaymentID", start, err) }(time.Now())
+	return r.BankTransferRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *bankTransferRepository) GetByReference(ctx context.Context, reference string) (_ *entity.BankTransfer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "GetByReference", start, err) }(time.Now())
+	return r.BankTransferRepository.GetByReference(ctx, reference)
+}
+
+func (r *bankTransferRepository) GetByVirtualAccount(ctx context.Context, account string) (_ *entity.BankTransfer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "GetByVirtualAccount", start, err) }(time.Now())
+	return r.BankTransferRepository.GetByVirtualAccount(ctx, account)
+}
+
+func (r *bankTransferRepository) ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (_ *entity.BankTransfer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "ApplyCredit", start, err) }(time.Now())
+	return r.BankTransferRepository.ApplyCredit(ctx, paymentID, amount, now)
+}
+
+func (r *bankTransferRepository) ListExpired(ctx context.Context, now time.Time, limit int) (_ []*entity.BankTransfer, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "ListExpired", start, err) }(time.Now())
+	return r.BankTransferRepository.ListExpired(ctx, now, limit)
+}
+
+func (r *bankTransferRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "Expire", start, err) }(time.Now())
+	return r.BankTransferRepository.Expire(ctx, paymentID, now)
+}
+
+func (r *bankTransferRepository) Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_transfer", "Cancel", start, err) }(time.Now())
+	return r.BankTransferRepository.Cancel(ctx, paymentID, now)
+}
+
+type bankCreditRepository struct {
+	repository.BankCreditRepository
+	m *Metrics
+}
+
+func InstrumentBankCreditRepository(repo repository.BankCreditRepository, m *Metrics) repository.BankCreditRepository {
+	return &bankCreditRepository{BankCreditRepository: repo, m: m}
+}
+
+func (r *bankCreditRepository) Create(ctx context.Context, credit *entity.BankCredit) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_credit", "Create", start, err) }(time.Now())
+	return r.BankCreditRepository.Create(ctx, credit)
+}
+
+func (r *bankCreditRepository) GetByTransactionID(ctx context.Context, transactionID string) (_ *entity.BankCredit, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_credit", "GetByTransactionID", start, err) }(time.Now())
+	return r.BankCreditRepository.GetByTransactionID(ctx, transactionID)
+}
+
+func (r *bankCreditRepository) Update(ctx context.Context, credit *entity.BankCredit) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_credit", "Update", start, err) }(time.Now())
+	return r.BankCreditRepository.Update(ctx, credit)
+}
+
+func (r *bankCreditRepository) List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) (_ []*entity.BankCredit, err error) {
+	defer func(start time.Time) { r.m.observeQuery("bank_credit", "List", start, err) }(time.Now())
+	return r.BankCreditRepository.List(ctx, status, limit, offset)
+}
+
+type statementRepository struct {
+	repository.StatementRepository
+	m *Metrics
+}
+
+func InstrumentStatementRepository(repo repository.StatementRepository, m *Metrics) repository.StatementRepository {
+	return &statementRepository{StatementRepository: repo, m: m}
+}
+
+func (r *statementRepository) Create(ctx context.Context, statement *entity.Statement) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("statement", "Create", start, err) }(time.Now())
+	return r.StatementRepository.Create(ctx, statement)
+}
+
+func (r *statementRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Statement, err error) {
+	defer func(start time.Time) { r.m.observeQuery("statement", "GetByID", start, err) }(time.Now())
+	return r.StatementRepository.GetByID(ctx, id)
+}
+
+func (r *statementRepository) List(ctx context.Context, limit, offset int) (_ []*entity.Statement, err error) {
+	defer func(start time.Time) { r.m.observeQuery("statement", "List", start, err) }(time.Now())
+	return r.StatementRepository.List(ctx, limit, offset)
+}
+
+func (r *statementRepository) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("statement", "ResolveLine", start, err) }(time.Now())
+	return r.StatementRepository.ResolveLine(ctx, statementID, lineID, paymentID, note, now)
+}
+
+func (r *statementRepository) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("statement", "ResolveException", start, err) }(time.Now())
+	return r.StatementRepository.ResolveException(ctx, statementID, paymentID, note, now)
+}
+
+type walletActionRepository struct {
+	repository.WalletActionRepository
+	m *Metrics
+}
+
+func InstrumentWalletActionRepository(repo repository.WalletActionRepository, m *Metrics) repository.WalletActionRepository {
+	return &walletActionRepository{WalletActionRepository: repo, m: m}
+}
+
+func (r *walletActionRepository) Create(ctx context.Context, action *entity.WalletAction) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("wallet_action", "Create", start, err) }(time.Now())
+	return r.WalletActionRepository.Create(ctx, action)
+}
+
+func (r *walletActionRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.WalletAction, err error) {
+	defer func(start time.Time) { r.m.observeQuery("wallet_action", "GetByPaymentID", start, err) }(time.Now())
+	return r.WalletActionRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *walletActionRepository) Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("wallet_action", "Resolve", start, err) }(time.Now())
+	return r.WalletActionRepository.Resolve(ctx, paymentID, status, now)
+}
+
+func (r *walletActionRepository) ListExpired(ctx context.Context, now time.Time, limit int) (_ []*entity.WalletAction, err error) {
+	defer func(start time.Time) { r.m.observeQuery("wallet_action", "ListExpired", start, err) }(time.Now())
+	return r.WalletActionRepository.ListExpired(ctx, now, limit)
+}
+
+func (r *walletActionRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("wallet_action", "Expire", start, err) }(time.Now())
+	return r.WalletActionRepository.Expire(ctx, paymentID, now)
+}
+
+type webhookEventRepository struct {
+	repository.WebhookEventRepository
+	m *Metrics
+}
+
+func InstrumentWebhookEventRepository(repo repository.WebhookEventRepository, m *Metrics) repository.WebhookEventRepository {
+	return &webhookEventRepository{WebhookEventRepository: repo, m: m}
+}
+
+func (r *webhookEventRepository) Create(ctx context.Context, event *entity.WebhookEvent) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("webhook_event", "Create", start, err) }(time.Now())
+	return r.WebhookEventRepository.Create(ctx, event)
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.WebhookEvent, err error) {
+	defer func(start time.Time) { r.m.observeQuery("webhook_event", "GetByID", start, err) }(time.Now())
+	return r.WebhookEventRepository.GetByID(ctx, id)
+}
+
+func (r *webhookEventRepository) GetByEventID(ctx context.Context, provider, eventID string) (_ *entity.WebhookEvent, err error) {
+	defer func(start time.Time) { r.m.observeQuery("webhook_event", "GetByEventID", start, err) }(time.Now())
+	return r.WebhookEventRepository.GetByEventID(ctx, provider, eventID)
+}
+
+func (r *webhookEventRepository) Update(ctx context.Context, event *entity.WebhookEvent) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("webhook_event", "Update", start, err) }(time.Now())
+	return r.WebhookEventRepository.Update(ctx, event)
+}
+
+func (r *webhookEventRepository) List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) (_ []*entity.WebhookEvent, err error) {
+	defer func(start time.Time) { r.m.observeQuery("webhook_event", "List", start, err) }(time.Now())
+	return r.WebhookEventRepository.List(ctx, provider, status, limit, offset)
+}
+
+type disputeRepository struct {
+	repository.DisputeRepository
+	m *Metrics
+}
+
+func InstrumentDisputeRepository(repo repository.DisputeRepository, m *Metrics) repository.DisputeRepository {
+	return &disputeRepository{DisputeRepository: repo, m: m}
+}
+
+func (r *disputeRepository) Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "Create", start, err) }(time.Now())
+	return r.DisputeRepository.Create(ctx, dispute, event)
+}
+
+func (r *disputeRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Dispute, err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "GetByID", start, err) }(time.Now())
+	return r.DisputeRepository.GetByID(ctx, id)
+}
+
+func (r *disputeRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.Dispute, err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "GetByPaymentID", start, err) }(time.Now())
+	return r.DisputeRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *disputeRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) (_ []*entity.Dispute, err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "ListByMerchant", start, err) }(time.Now())
+	return r.DisputeRepository.ListByMerchant(ctx, merchantID, status, limit, offset)
+}
+
+func (r *disputeRepository) AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "AddEvidence", start, err) }(time.Now())
+	return r.DisputeRepository.AddEvidence(ctx, evidence)
+}
+
+func (r *disputeRepository) Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "Transition", start, err) }(time.Now())
+	return r.DisputeRepository.Transition(ctx, dispute, from, event, reversal)
+}
+
+func (r *disputeRepository) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.DisputeEvent, err error) {
+	defer func(start time.Time) { r.m.observeQuery("dispute", "ListEvents", start, err) }(time.Now())
+	return r.DisputeRepository.ListEvents(ctx, merchantID, limit, offset)
+}
+
+type ledgerRepository struct {
+	repository.LedgerRepository
+	m *Metrics
+}
+
+func InstrumentLedgerRepository(repo repository.LedgerRepository, m *Metrics) repository.LedgerRepository {
+	return &ledgerRepository{LedgerRepository: repo, m: m}
+}
+
+func (r *ledgerRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.LedgerEntry, err error) {
+	defer func(start time.Time) { r.m.observeQuery("ledger_entry", "ListByMerchant", start, err) }(time.Now())
+	return r.LedgerRepository.ListByMerchant(ctx, merchantID, limit, offset)
+}
+
+type riskRepository struct {
+	repository.RiskRepository
+	m *Metrics
+}
+
+func InstrumentRiskRepository(repo repository.RiskRepository, m *Metrics) repository.RiskRepository {
+	return &riskRepository{RiskRepository: repo, m: m}
+}
+
+func (r *riskRepository) CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (_ int, err error) {
+	defer func(start time.Time) { r.m.observeQuery("risk", "CountPaymentsSince", start, err) }(time.Now())
+	return r.RiskRepository.CountPaymentsSince(ctx, key, value, since)
+}
+
+func (r *riskRepository) CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (_ int, err error) {
+	defer func(start time.Time) { r.m.observeQuery("risk", "CountCompletedPayments", start, err) }(time.Now())
+	return r.RiskRepository.CountCompletedPayments(ctx, customerID)
+}
+
+type merchantLimitRepository struct {
+	repository.MerchantLimitRepository
+	m *Metrics
+}
+
+func InstrumentMerchantLimitRepository(repo repository.MerchantLimitRepository, m *Metrics) repository.MerchantLimitRepository {
+	return &merchantLimitRepository{MerchantLimitRepository: repo, m: m}
+}
+
+func (r *merchantLimitRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID) (_ []*entity.MerchantLimit, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant_limit", "ListByMerchant", start, err) }(time.Now())
+	return r.MerchantLimitRepository.ListByMerchant(ctx, merchantID)
+}
+
+func (r *merchantLimitRepository) Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant_limit", "Replace", start, err) }(time.Now())
+	return r.MerchantLimitRepository.Replace(ctx, merchantID, limits)
+}
+
+func (r *merchantLimitRepository) Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (_ *entity.MerchantLimit, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant_limit", "Reserve", start, err) }(time.Now())
+	return r.MerchantLimitRepository.Reserve(ctx, paymentID, limits, day, amount, now)
+}
+
+func (r *merchantLimitRepository) Release(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant_limit", "Release", start, err) }(time.Now())
+	return r.MerchantLimitRepository.Release(ctx, paymentID, now)
+}
+
+func (r *merchantLimitRepository) GetUsage(ctx context.Context, merchantID uuid.UUID, day string) (_ []*entity.LimitUsage, err error) {
+	defer func(start time.Time) { r.m.observeQuery("merchant_limit", "GetUsage", start, err) }(time.Now())
+	return r.MerchantLimitRepository.GetUsage(ctx, merchantID, day)
+}
+
+type rateLimitRepository struct {
+	repository.RateLimitRepository
+	m *Metrics
+}
+
+func InstrumentRateLimitRepository(repo repository.RateLimitRepository, m *Metrics) repository.RateLimitRepository {
+	return &rateLimitRepository{RateLimitRepository: repo, m: m}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (_ *entity.RateLimitResult, err error) {
+	defer func(start time.Time) { r.m.observeQuery("rate_limit", "Take", start, err) }(time.Now())
+	return r.RateLimitRepository.Take(ctx, key, limit, now)
+}
+
+type nonceRepository struct {
+	repository.NonceRepository
+	m *Metrics
+}
+
+func InstrumentNonceRepository(repo repository.NonceRepository, m *Metrics) repository.NonceRepository {
+	return &nonceRepository{NonceRepository: repo, m: m}
+}
+
+func (r *nonceRepository) Use(ctx context.Context, key string, expiresAt, now time.Time) (_ bool, err error) {
+	defer func(start time.Time) { r.m.observeQuery("nonce", "Use", start, err) }(time.Now())
+	return r.NonceRepository.Use(ctx, key, expiresAt, now)
+}
@@ -0,0 +1,810 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/repository"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 以下 decorator 為每個 repository 方法建立 client span；內嵌原介面，
+// 新增但尚未在此覆寫的方法會直接轉呼叫而不建立 span。
+
+type paymentRepository struct {
+	repository.PaymentRepository
+}
+
+func TracePaymentRepository(repo repository.PaymentRepository) repository.PaymentRepository {
+	return &paymentRepository{PaymentRepository: repo}
+}
+
+func (r *paymentRepository) Create(ctx context.Context, payment *entity.Payment) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.Create(ctx, payment)
+}
+
+func (r *paymentRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetByID(ctx, id)
+}
+
+func (r *paymentRepository) GetByReference(ctx context.Context, reference string) (_ *entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetByReference")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetByReference(ctx, reference)
+}
+
+func (r *paymentRepository) UpdateStatus(ctx context.Context, id uuid.UUID, status entity.PaymentStatus) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.UpdateStatus")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.UpdateStatus(ctx, id, status)
+}
+
+func (r *paymentRepository) TransitionStatus(ctx context.Context, id uuid.UUID, from, to entity.PaymentStatus) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.TransitionStatus")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.TransitionStatus(ctx, id, from, to)
+}
+
+func (r *paymentRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetByMerchantID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *paymentRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetByCustomerID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetByCustomerID(ctx, customerID, limit, offset)
+}
+
+func (r *paymentRepository) GetByPaymentLinkID(ctx context.Context, linkID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetByPaymentLinkID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetByPaymentLinkID(ctx, linkID, limit, offset)
+}
+
+func (r *paymentRepository) GetCompletedBetween(ctx context.Context, from, to time.Time, limit, offset int) (_ []*entity.Payment, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentRepository.GetCompletedBetween")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentRepository.GetCompletedBetween(ctx, from, to, limit, offset)
+}
+
+type merchantRepository struct {
+	repository.MerchantRepository
+}
+
+func TraceMerchantRepository(repo repository.MerchantRepository) repository.MerchantRepository {
+	return &merchantRepository{MerchantRepository: repo}
+}
+
+func (r *merchantRepository) Create(ctx context.Context, merchant *entity.Merchant) (err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.Create(ctx, merchant)
+}
+
+func (r *merchantRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Merchant, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.GetByID(ctx, id)
+}
+
+func (r *merchantRepository) GetByAPIKey(ctx context.Context, apiKey string) (_ *entity.Merchant, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.GetByAPIKey")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.GetByAPIKey(ctx, apiKey)
+}
+
+func (r *merchantRepository) GetByPublishableKey(ctx context.Context, publishableKey string) (_ *entity.Merchant, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.GetByPublishableKey")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.GetByPublishableKey(ctx, publishableKey)
+}
+
+func (r *merchantRepository) Update(ctx context.Context, merchant *entity.Merchant) (err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.Update(ctx, merchant)
+}
+
+func (r *merchantRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantRepository.Delete(ctx, id)
+}
+
+type customerRepository struct {
+	repository.CustomerRepository
+}
+
+func TraceCustomerRepository(repo repository.CustomerRepository) repository.CustomerRepository {
+	return &customerRepository{CustomerRepository: repo}
+}
+
+func (r *customerRepository) Create(ctx context.Context, customer *entity.Customer) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CustomerRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.CustomerRepository.Create(ctx, customer)
+}
+
+func (r *customerRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Customer, err error) {
+	ctx, span := startRepositorySpan(ctx, "CustomerRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.CustomerRepository.GetByID(ctx, id)
+}
+
+func (r *customerRepository) GetByEmail(ctx context.Context, email string) (_ *entity.Customer, err error) {
+	ctx, span := startRepositorySpan(ctx, "CustomerRepository.GetByEmail")
+	defer func() { endSpan(span, err) }()
+	return r.CustomerRepository.GetByEmail(ctx, email)
+}
+
+func (r *customerRepository) Update(ctx context.Context, customer *entity.Customer) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CustomerRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.CustomerRepository.Update(ctx, customer)
+}
+
+func (r *customerRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CustomerRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.CustomerRepository.Delete(ctx, id)
+}
+
+type cardRepository struct {
+	repository.CardRepository
+}
+
+func TraceCardRepository(repo repository.CardRepository) repository.CardRepository {
+	return &cardRepository{CardRepository: repo}
+}
+
+func (r *cardRepository) Create(ctx context.Context, card *entity.Card) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CardRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.CardRepository.Create(ctx, card)
+}
+
+func (r *cardRepository) GetByToken(ctx context.Context, token string) (_ *entity.Card, err error) {
+	ctx, span := startRepositorySpan(ctx, "CardRepository.GetByToken")
+	defer func() { endSpan(span, err) }()
+	return r.CardRepository.GetByToken(ctx, token)
+}
+
+type paymentMethodRepository struct {
+	repository.PaymentMethodRepository
+}
+
+func TracePaymentMethodRepository(repo repository.PaymentMethodRepository) repository.PaymentMethodRepository {
+	return &paymentMethodRepository{PaymentMethodRepository: repo}
+}
+
+func (r *paymentMethodRepository) Create(ctx context.Context, method *entity.PaymentMethodRecord) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.Create(ctx, method)
+}
+
+func (r *paymentMethodRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.PaymentMethodRecord, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.GetByID(ctx, id)
+}
+
+func (r *paymentMethodRepository) ListByCustomerID(ctx context.Context, customerID uuid.UUID) (_ []*entity.PaymentMethodRecord, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.ListByCustomerID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.ListByCustomerID(ctx, customerID)
+}
+
+func (r *paymentMethodRepository) Update(ctx context.Context, method *entity.PaymentMethodRecord) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.Update(ctx, method)
+}
+
+func (r *paymentMethodRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.Delete")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.Delete(ctx, id)
+}
+
+func (r *paymentMethodRepository) SetDefault(ctx context.Context, customerID, id uuid.UUID) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentMethodRepository.SetDefault")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentMethodRepository.SetDefault(ctx, customerID, id)
+}
+
+type planRepository struct {
+	repository.PlanRepository
+}
+
+func TracePlanRepository(repo repository.PlanRepository) repository.PlanRepository {
+	return &planRepository{PlanRepository: repo}
+}
+
+func (r *planRepository) Create(ctx context.Context, plan *entity.Plan) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PlanRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.PlanRepository.Create(ctx, plan)
+}
+
+func (r *planRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Plan, err error) {
+	ctx, span := startRepositorySpan(ctx, "PlanRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.PlanRepository.GetByID(ctx, id)
+}
+
+func (r *planRepository) ListByMerchantID(ctx context.Context, merchantID uuid.UUID) (_ []*entity.Plan, err error) {
+	ctx, span := startRepositorySpan(ctx, "PlanRepository.ListByMerchantID")
+	defer func() { endSpan(span, err) }()
+	return r.PlanRepository.ListByMerchantID(ctx, merchantID)
+}
+
+func (r *planRepository) Update(ctx context.Context, plan *entity.Plan) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PlanRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.PlanRepository.Update(ctx, plan)
+}
+
+type subscriptionRepository struct {
+	repository.SubscriptionRepository
+}
+
+func TraceSubscriptionRepository(repo repository.SubscriptionRepository) repository.SubscriptionRepository {
+	return &subscriptionRepository{SubscriptionRepository: repo}
+}
+
+func (r *subscriptionRepository) Create(ctx context.Context, subscription *entity.Subscription) (err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.Create(ctx, subscription)
+}
+
+func (r *subscriptionRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Subscription, err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.GetByID(ctx, id)
+}
+
+func (r *subscriptionRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Subscription, err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.GetByMerchantID")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *subscriptionRepository) Update(ctx context.Context, subscription *entity.Subscription) (err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.Update(ctx, subscription)
+}
+
+func (r *subscriptionRepository) ListDue(ctx context.Context, now time.Time, limit int) (_ []*entity.Subscription, err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.ListDue")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.ListDue(ctx, now, limit)
+}
+
+func (r *subscriptionRepository) Claim(ctx context.Context, id uuid.UUID, now, until time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "SubscriptionRepository.Claim")
+	defer func() { endSpan(span, err) }()
+	return r.SubscriptionRepository.Claim(ctx, id, now, until)
+}
+
+type invoiceRepository struct {
+	repository.InvoiceRepository
+}
+
+func TraceInvoiceRepository(repo repository.InvoiceRepository) repository.InvoiceRepository {
+	return &invoiceRepository{InvoiceRepository: repo}
+}
+
+func (r *invoiceRepository) Create(ctx context.Context, invoice *entity.Invoice) (err error) {
+	ctx, span := startRepositorySpan(ctx, "InvoiceRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.InvoiceRepository.Create(ctx, invoice)
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Invoice, err error) {
+	ctx, span := startRepositorySpan(ctx, "InvoiceRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.InvoiceRepository.GetByID(ctx, id)
+}
+
+func (r *invoiceRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Invoice, err error) {
+	ctx, span := startRepositorySpan(ctx, "InvoiceRepository.GetByMerchantID")
+	defer func() { endSpan(span, err) }()
+	return r.InvoiceRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *invoiceRepository) Update(ctx context.Context, invoice *entity.Invoice) (err error) {
+	ctx, span := startRepositorySpan(ctx, "InvoiceRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.InvoiceRepository.Update(ctx, invoice)
+}
+
+type checkoutSessionRepository struct {
+	repository.CheckoutSessionRepository
+}
+
+func TraceCheckoutSessionRepository(repo repository.CheckoutSessionRepository) repository.CheckoutSessionRepository {
+	return &checkoutSessionRepository{CheckoutSessionRepository: repo}
+}
+
+func (r *checkoutSessionRepository) Create(ctx context.Context, session *entity.CheckoutSession) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CheckoutSessionRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.CheckoutSessionRepository.Create(ctx, session)
+}
+
+func (r *checkoutSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.CheckoutSession, err error) {
+	ctx, span := startRepositorySpan(ctx, "CheckoutSessionRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.CheckoutSessionRepository.GetByID(ctx, id)
+}
+
+func (r *checkoutSessionRepository) Transition(ctx context.Context, session *entity.CheckoutSession, from entity.CheckoutSessionStatus) (err error) {
+	ctx, span := startRepositorySpan(ctx, "CheckoutSessionRepository.Transition")
+	defer func() { endSpan(span, err) }()
+	return r.CheckoutSessionRepository.Transition(ctx, session, from)
+}
+
+type paymentLinkRepository struct {
+	repository.PaymentLinkRepository
+}
+
+func TracePaymentLinkRepository(repo repository.PaymentLinkRepository) repository.PaymentLinkRepository {
+	return &paymentLinkRepository{PaymentLinkRepository: repo}
+}
+
+func (r *paymentLinkRepository) Create(ctx context.Context, link *entity.PaymentLink) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.Create(ctx, link)
+}
+
+func (r *paymentLinkRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.PaymentLink, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.GetByID(ctx, id)
+}
+
+func (r *paymentLinkRepository) GetByMerchantID(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.PaymentLink, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.GetByMerchantID")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.GetByMerchantID(ctx, merchantID, limit, offset)
+}
+
+func (r *paymentLinkRepository) SetActive(ctx context.Context, id uuid.UUID, active bool) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.SetActive")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.SetActive(ctx, id, active)
+}
+
+func (r *paymentLinkRepository) ClaimPayment(ctx context.Context, id uuid.UUID, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.ClaimPayment")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.ClaimPayment(ctx, id, now)
+}
+
+func (r *paymentLinkRepository) ReleasePayment(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.ReleasePayment")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.ReleasePayment(ctx, id)
+}
+
+func (r *paymentLinkRepository) GetStats(ctx context.Context, id uuid.UUID) (_ *entity.PaymentLinkStats, err error) {
+	ctx, span := startRepositorySpan(ctx, "PaymentLinkRepository.GetStats")
+	defer func() { endSpan(span, err) }()
+	return r.PaymentLinkRepository.GetStats(ctx, id)
+}
+
+type jobRepository struct {
+	repository.JobRepository
+}
+
+func TraceJobRepository(repo repository.JobRepository) repository.JobRepository {
+	return &jobRepository{JobRepository: repo}
+}
+
+func (r *jobRepository) Enqueue(ctx context.Context, job *entity.Job) (err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.Enqueue")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.Enqueue(ctx, job)
+}
+
+func (r *jobRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Job, err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.GetByID(ctx, id)
+}
+
+func (r *jobRepository) Claim(ctx context.Context, now time.Time, visibility time.Duration, limit int) (_ []*entity.Job, err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.Claim")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.Claim(ctx, now, visibility, limit)
+}
+
+func (r *jobRepository) Complete(ctx context.Context, id uuid.UUID, attempts int) (err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.Complete")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.Complete(ctx, id, attempts)
+}
+
+func (r *jobRepository) Retry(ctx context.Context, id uuid.UUID, attempts int, runAt time.Time, lastError string) (err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.Retry")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.Retry(ctx, id, attempts, runAt, lastError)
+}
+
+func (r *jobRepository) Fail(ctx context.Context, id uuid.UUID, attempts int, lastError string) (err error) {
+	ctx, span := startRepositorySpan(ctx, "JobRepository.Fail")
+	defer func() { endSpan(span, err) }()
+	return r.JobRepository.Fail(ctx, id, attempts, lastError)
+}
+
+type bankTransferRepository struct {
+	repository.BankTransferRepository
+}
+
+func TraceBankTransferRepository(repo repository.BankTransferRepository) repository.BankTransferRepository {
+	return &bankTransferRepository{BankTransferRepository: repo}
+}
+
+func (r *bankTransferRepository) Create(ctx context.Context, transfer *entity.BankTransfer) (err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.Create(ctx, transfer)
+}
+
+func (r *bankTransferRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.BankTransfer, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.GetByPaymentID")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *bankTransferRepository) GetByReference(ctx context.Context, reference string) (_ *entity.BankTransfer, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.GetByReference")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.GetByReference(ctx, reference)
+}
+
+func (r *bankTransferRepository) GetByVirtualAccount(ctx context.Context, account string) (_ *entity.BankTransfer, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.GetByVirtualAccount")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.GetByVirtualAccount(ctx, account)
+}
+
+func (r *bankTransferRepository) ApplyCredit(ctx context.Context, paymentID uuid.UUID, amount int64, now time.Time) (_ *entity.BankTransfer, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.ApplyCredit")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.ApplyCredit(ctx, paymentID, amount, now)
+}
+
+func (r *bankTransferRepository) ListExpired(ctx context.Context, now time.Time, limit int) (_ []*entity.BankTransfer, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.ListExpired")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.ListExpired(ctx, now, limit)
+}
+
+func (r *bankTransferRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.Expire")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.Expire(ctx, paymentID, now)
+}
+
+func (r *bankTransferRepository) Cancel(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "BankTransferRepository.Cancel")
+	defer func() { endSpan(span, err) }()
+	return r.BankTransferRepository.Cancel(ctx, paymentID, now)
+}
+
+type bankCreditRepository struct {
+	repository.BankCreditRepository
+}
+
+func TraceBankCreditRepository(repo repository.BankCreditRepository) repository.BankCreditRepository {
+	return &bankCreditRepository{BankCreditRepository: repo}
+}
+
+func (r *bankCreditRepository) Create(ctx context.Context, credit *entity.BankCredit) (err error) {
+	ctx, span := startRepositorySpan(ctx, "BankCreditRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.BankCreditRepository.Create(ctx, credit)
+}
+
+func (r *bankCreditRepository) GetByTransactionID(ctx context.Context, transactionID string) (_ *entity.BankCredit, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankCreditRepository.GetByTransactionID")
+	defer func() { endSpan(span, err) }()
+	return r.BankCreditRepository.GetByTransactionID(ctx, transactionID)
+}
+
+func (r *bankCreditRepository) Update(ctx context.Context, credit *entity.BankCredit) (err error) {
+	ctx, span := startRepositorySpan(ctx, "BankCreditRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.BankCreditRepository.Update(ctx, credit)
+}
+
+func (r *bankCreditRepository) List(ctx context.Context, status entity.BankCreditStatus, limit, offset int) (_ []*entity.BankCredit, err error) {
+	ctx, span := startRepositorySpan(ctx, "BankCreditRepository.List")
+	defer func() { endSpan(span, err) }()
+	return r.BankCreditRepository.List(ctx, status, limit, offset)
+}
+
+type statementRepository struct {
+	repository.StatementRepository
+}
+
+func TraceStatementRepository(repo repository.StatementRepository) repository.StatementRepository {
+	return &statementRepository{StatementRepository: repo}
+}
+
+func (r *statementRepository) Create(ctx context.Context, statement *entity.Statement) (err error) {
+	ctx, span := startRepositorySpan(ctx, "StatementRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.StatementRepository.Create(ctx, statement)
+}
+
+func (r *statementRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Statement, err error) {
+	ctx, span := startRepositorySpan(ctx, "StatementRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.StatementRepository.GetByID(ctx, id)
+}
+
+func (r *statementRepository) List(ctx context.Context, limit, offset int) (_ []*entity.Statement, err error) {
+	ctx, span := startRepositorySpan(ctx, "StatementRepository.List")
+	defer func() { endSpan(span, err) }()
+	return r.StatementRepository.List(ctx, limit, offset)
+}
+
+func (r *statementRepository) ResolveLine(ctx context.Context, statementID, lineID uuid.UUID, paymentID *uuid.UUID, note string, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "StatementRepository.ResolveLine")
+	defer func() { endSpan(span, err) }()
+	return r.StatementRepository.ResolveLine(ctx, statementID, lineID, paymentID, note, now)
+}
+
+func (r *statementRepository) ResolveException(ctx context.Context, statementID, paymentID uuid.UUID, note string, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "StatementRepository.ResolveException")
+	defer func() { endSpan(span, err) }()
+	return r.StatementRepository.ResolveException(ctx, statementID, paymentID, note, now)
+}
+
+type walletActionRepository struct {
+	repository.WalletActionRepository
+}
+
+func TraceWalletActionRepository(repo repository.WalletActionRepository) repository.WalletActionRepository {
+	return &walletActionRepository{WalletActionRepository: repo}
+}
+
+func (r *walletActionRepository) Create(ctx context.Context, action *entity.WalletAction) (err error) {
+	ctx, span := startRepositorySpan(ctx, "WalletActionRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.WalletActionRepository.Create(ctx, action)
+}
+
+func (r *walletActionRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.WalletAction, err error) {
+	ctx, span := startRepositorySpan(ctx, "WalletActionRepository.GetByPaymentID")
+	defer func() { endSpan(span, err) }()
+	return r.WalletActionRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *walletActionRepository) Resolve(ctx context.Context, paymentID uuid.UUID, status entity.WalletActionStatus, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "WalletActionRepository.Resolve")
+	defer func() { endSpan(span, err) }()
+	return r.WalletActionRepository.Resolve(ctx, paymentID, status, now)
+}
+
+func (r *walletActionRepository) ListExpired(ctx context.Context, now time.Time, limit int) (_ []*entity.WalletAction, err error) {
+	ctx, span := startRepositorySpan(ctx, "WalletActionRepository.ListExpired")
+	defer func() { endSpan(span, err) }()
+	return r.WalletActionRepository.ListExpired(ctx, now, limit)
+}
+
+func (r *walletActionRepository) Expire(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "WalletActionRepository.Expire")
+	defer func() { endSpan(span, err) }()
+	return r.WalletActionRepository.Expire(ctx, paymentID, now)
+}
+
+type webhookEventRepository struct {
+	repository.WebhookEventRepository
+}
+
+func TraceWebhookEventRepository(repo repository.WebhookEventRepository) repository.WebhookEventRepository {
+	return &webhookEventRepository{WebhookEventRepository: repo}
+}
+
+func (r *webhookEventRepository) Create(ctx context.Context, event *entity.WebhookEvent) (err error) {
+	ctx, span := startRepositorySpan(ctx, "WebhookEventRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.WebhookEventRepository.Create(ctx, event)
+}
+
+func (r *webhookEventRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.WebhookEvent, err error) {
+	ctx, span := startRepositorySpan(ctx, "WebhookEventRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.WebhookEventRepository.GetByID(ctx, id)
+}
+
+func (r *webhookEventRepository) GetByEventID(ctx context.Context, provider, eventID string) (_ *entity.WebhookEvent, err error) {
+	ctx, span := startRepositorySpan(ctx, "WebhookEventRepository.GetByEventID")
+	defer func() { endSpan(span, err) }()
+	return r.WebhookEventRepository.GetByEventID(ctx, provider, eventID)
+}
+
+func (r *webhookEventRepository) Update(ctx context.Context, event *entity.WebhookEvent) (err error) {
+	ctx, span := startRepositorySpan(ctx, "WebhookEventRepository.Update")
+	defer func() { endSpan(span, err) }()
+	return r.WebhookEventRepository.Update(ctx, event)
+}
+
+func (r *webhookEventRepository) List(ctx context.Context, provider string, status entity.WebhookEventStatus, limit, offset int) (_ []*entity.WebhookEvent, err error) {
+	ctx, span := startRepositorySpan(ctx, "WebhookEventRepository.List")
+	defer func() { endSpan(span, err) }()
+	return r.WebhookEventRepository.List(ctx, provider, status, limit, offset)
+}
+
+type disputeRepository struct {
+	repository.DisputeRepository
+}
+
+func TraceDisputeRepository(repo repository.DisputeRepository) repository.DisputeRepository {
+	return &disputeRepository{DisputeRepository: repo}
+}
+
+func (r *disputeRepository) Create(ctx context.Context, dispute *entity.Dispute, event *entity.DisputeEvent) (err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.Create")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.Create(ctx, dispute, event)
+}
+
+func (r *disputeRepository) GetByID(ctx context.Context, id uuid.UUID) (_ *entity.Dispute, err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.GetByID")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.GetByID(ctx, id)
+}
+
+func (r *disputeRepository) GetByPaymentID(ctx context.Context, paymentID uuid.UUID) (_ *entity.Dispute, err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.GetByPaymentID")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.GetByPaymentID(ctx, paymentID)
+}
+
+func (r *disputeRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, status entity.DisputeStatus, limit, offset int) (_ []*entity.Dispute, err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.ListByMerchant")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.ListByMerchant(ctx, merchantID, status, limit, offset)
+}
+
+func (r *disputeRepository) AddEvidence(ctx context.Context, evidence *entity.DisputeEvidence) (err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.AddEvidence")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.AddEvidence(ctx, evidence)
+}
+
+func (r *disputeRepository) Transition(ctx context.Context, dispute *entity.Dispute, from entity.DisputeStatus, event *entity.DisputeEvent, reversal *entity.LedgerEntry) (err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.Transition")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.Transition(ctx, dispute, from, event, reversal)
+}
+
+func (r *disputeRepository) ListEvents(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.DisputeEvent, err error) {
+	ctx, span := startRepositorySpan(ctx, "DisputeRepository.ListEvents")
+	defer func() { endSpan(span, err) }()
+	return r.DisputeRepository.ListEvents(ctx, merchantID, limit, offset)
+}
+
+type ledgerRepository struct {
+	repository.LedgerRepository
+}
+
+func TraceLedgerRepository(repo repository.LedgerRepository) repository.LedgerRepository {
+	return &ledgerRepository{LedgerRepository: repo}
+}
+
+func (r *ledgerRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.LedgerEntry, err error) {
+	ctx, span := startRepositorySpan(ctx, "LedgerRepository.ListByMerchant")
+	defer func() { endSpan(span, err) }()
+	return r.LedgerRepository.ListByMerchant(ctx, merchantID, limit, offset)
+}
+
+type riskRepository struct {
+	repository.RiskRepository
+}
+
+func TraceRiskRepository(repo repository.RiskRepository) repository.RiskRepository {
+	return &riskRepository{RiskRepository: repo}
+}
+
+func (r *riskRepository) CountPaymentsSince(ctx context.Context, key entity.RiskVelocityKey, value string, since time.Time) (_ int, err error) {
+	ctx, span := startRepositorySpan(ctx, "RiskRepository.CountPaymentsSince")
+	defer func() { endSpan(span, err) }()
+	return r.RiskRepository.CountPaymentsSince(ctx, key, value, since)
+}
+
+func (r *riskRepository) CountCompletedPayments(ctx context.Context, customerID uuid.UUID) (_ int, err error) {
+	ctx, span := startRepositorySpan(ctx, "RiskRepository.CountCompletedPayments")
+	defer func() { endSpan(span, err) }()
+	return r.RiskRepository.CountCompletedPayments(ctx, customerID)
+}
+
+type merchantLimitRepository struct {
+	repository.MerchantLimitRepository
+}
+
+func TraceMerchantLimitRepository(repo repository.MerchantLimitRepository) repository.MerchantLimitRepository {
+	return &merchantLimitRepository{MerchantLimitRepository: repo}
+}
+
+func (r *merchantLimitRepository) ListByMerchant(ctx context.Context, merchantID uuid.UUID) (_ []*entity.MerchantLimit, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantLimitRepository.ListByMerchant")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantLimitRepository.ListByMerchant(ctx, merchantID)
+}
+
+func (r *merchantLimitRepository) Replace(ctx context.Context, merchantID uuid.UUID, limits []*entity.MerchantLimit) (err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantLimitRepository.Replace")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantLimitRepository.Replace(ctx, merchantID, limits)
+}
+
+func (r *merchantLimitRepository) Reserve(ctx context.Context, paymentID uuid.UUID, limits []*entity.MerchantLimit, day string, amount int64, now time.Time) (_ *entity.MerchantLimit, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantLimitRepository.Reserve")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantLimitRepository.Reserve(ctx, paymentID, limits, day, amount, now)
+}
+
+func (r *merchantLimitRepository) Release(ctx context.Context, paymentID uuid.UUID, now time.Time) (err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantLimitRepository.Release")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantLimitRepository.Release(ctx, paymentID, now)
+}
+
+func (r *merchantLimitRepository) GetUsage(ctx context.Context, merchantID uuid.UUID, day string) (_ []*entity.LimitUsage, err error) {
+	ctx, span := startRepositorySpan(ctx, "MerchantLimitRepository.GetUsage")
+	defer func() { endSpan(span, err) }()
+	return r.MerchantLimitRepository.GetUsage(ctx, merchantID, day)
+}
+
+type rateLimitRepository struct {
+	repository.RateLimitRepository
+}
+
+func TraceRateLimitRepository(repo repository.RateLimitRepository) repository.RateLimitRepository {
+	return &rateLimitRepository{RateLimitRepository: repo}
+}
+
+func (r *rateLimitRepository) Take(ctx context.Context, key string, limit entity.RateLimit, now time.Time) (_ *entity.RateLimitResult, err error) {
+	ctx, span := startRepositorySpan(ctx, "RateLimitRepository.Take")
+	defer func() { endSpan(span, err) }()
+	return r.RateLimitRepository.Take(ctx, key, limit, now)
+}
+
+type nonceRepository struct {
+	repository.NonceRepository
+}
+
+func TraceNonceRepository(repo repository.NonceRepository) repository.NonceRepository {
+	return &nonceRepository{NonceRepository: repo}
+}
+
+func (r *nonceRepository) Use(ctx context.Context, key string, expiresAt, now time.Time) (_ bool, err error) {
+	ctx, span := startRepositorySpan(ctx, "NonceRepository.Use")
+	defer func() { endSpan(span, err) }()
+	return r.NonceRepository.Use(ctx, key, expiresAt, now)
+}
+
+func startRepositorySpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("db.operation", name)),
+	)
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/company/payment-service/internal/infrastructure/memory"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestUseCaseAndRepositorySpans(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(sdktrace.NewTracerProvider()) })
+
+	store := memory.NewStore()
+	store.LoadSampleData()
+	uc := TracePaymentUseCase(usecase.NewPaymentUseCase(
+		TracePaymentRepository(memory.NewPaymentRepository(store)),
+		TraceMerchantRepository(memory.NewMerchantRepository(store)),
+		TraceCustomerRepository(memory.NewCustomerRepository(store)),
+		memory.NewCardRepository(store),
+		memory.NewPaymentMethodRepository(store),
+		memory.NewInvoiceRepository(store),
+		memory.NewJobRepository(store),
+		memory.NewBankTransferRepository(store),
+		usecase.BankTransferConfig{},
+		memory.NewWalletActionRepository(store),
+		usecase.WalletConfig{},
+		nil,
+		nil,
+		nil,
+	))
+
+	_, err := uc.CreatePayment(context.Background(), usecase.CreatePaymentRequest{
+		MerchantID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440001"),
+		CustomerID: uuid.MustParse("550e8400-e29b-41d4-a716-446655440101"),
+		Amount:     1000,
+		Currency:   "USD",
+		Method:     entity.PaymentMethodCreditCard,
+		Reference:  "TRACE_" + uuid.NewString(),
+	})
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	byName := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+
+	root, ok := byName["PaymentUseCase.CreatePayment"]
+	require.True(t, ok)
+	for _, name := range []string{"MerchantRepository.GetByID", "CustomerRepository.GetByID", "PaymentRepository.Create"} {
+		child, ok := byName[name]
+		require.True(t, ok, name)
+		assert.Equal(t, root.SpanContext().SpanID(), child.Parent().SpanID(), name)
+		assert.Equal(t, root.SpanContext().TraceID(), child.SpanContext().TraceID(), name)
+	}
+
+	recorder = tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	require.Error(t, uc.ProcessPayment(context.Background(), uuid.New()))
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, codes.Error, span.Status().Code, span.Name())
+	}
+}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/company/payment-service/internal/domain/entity"
+	"github.com/company/payment-service/internal/domain/usecase"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type paymentUseCase struct {
+	usecase.PaymentUseCase
+}
+
+// TracePaymentUseCase 為每個 use case 方法建立 span，repository 的 span 會成為其子 span
+func TracePaymentUseCase(uc usecase.PaymentUseCase) usecase.PaymentUseCase {
+	return &paymentUseCase{PaymentUseCase: uc}
+}
+
+func (u *paymentUseCase) CreatePayment(ctx context.Context, req usecase.CreatePaymentRequest) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.CreatePayment",
+		attribute.String("merchant.id", req.MerchantID.String()),
+		attribute.String("customer.id", req.CustomerID.String()),
+		attribute.String("payment.method", string(req.Method)),
+		attribute.String("payment.currency", req.Currency),
+	)
+	defer func() { endSpan(span, err) }()
+
+	payment, err := u.PaymentUseCase.CreatePayment(ctx, req)
+	if payment != nil {
+		span.SetAttributes(attribute.String("payment.id", payment.ID.String()))
+	}
+	return payment, err
+}
+
+func (u *paymentUseCase) GetPayment(ctx context.Context, id uuid.UUID) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.GetPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.GetPayment(ctx, id)
+}
+
+func (u *paymentUseCase) GetMerchantPayment(ctx context.Context, merchantID, id uuid.UUID) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.GetMerchantPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.GetMerchantPayment(ctx, merchantID, id)
+}
+
+func (u *paymentUseCase) ProcessPayment(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.ProcessPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.ProcessPayment(ctx, id)
+}
+
+func (u *paymentUseCase) SubmitPayment(ctx context.Context, id uuid.UUID) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.SubmitPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.SubmitPayment(ctx, id)
+}
+
+func (u *paymentUseCase) ExecutePayment(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.ExecutePayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.ExecutePayment(ctx, id)
+}
+
+func (u *paymentUseCase) FailPayment(ctx context.Context, id uuid.UUID, reason string) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.FailPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.FailPayment(ctx, id, reason)
+}
+
+func (u *paymentUseCase) SettlePayment(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.SettlePayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.SettlePayment(ctx, id)
+}
+
+func (u *paymentUseCase) CompleteAction(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.CompleteAction", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.CompleteAction(ctx, id)
+}
+
+func (u *paymentUseCase) FailAction(ctx context.Context, id uuid.UUID, reason string) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.FailAction", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.FailAction(ctx, id, reason)
+}
+
+func (u *paymentUseCase) ApplyGatewayResult(ctx context.Context, id uuid.UUID, status entity.PaymentStatus, reason string) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.ApplyGatewayResult",
+		attribute.String("payment.id", id.String()),
+		attribute.String("payment.status", string(status)),
+	)
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.ApplyGatewayResult(ctx, id, status, reason)
+}
+
+func (u *paymentUseCase) CancelPayment(ctx context.Context, id uuid.UUID) (err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.CancelPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.CancelPayment(ctx, id)
+}
+
+func (u *paymentUseCase) ApprovePayment(ctx context.Context, merchantID, id uuid.UUID) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.ApprovePayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.ApprovePayment(ctx, merchantID, id)
+}
+
+func (u *paymentUseCase) RejectPayment(ctx context.Context, merchantID, id uuid.UUID, reason string) (_ *entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.RejectPayment", attribute.String("payment.id", id.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.RejectPayment(ctx, merchantID, id, reason)
+}
+
+func (u *paymentUseCase) GetMerchantPayments(ctx context.Context, merchantID uuid.UUID, limit, offset int) (_ []*entity.Payment, err error) {
+	ctx, span := startSpan(ctx, "PaymentUseCase.GetMerchantPayments", attribute.String("merchant.id", merchantID.String()))
+	defer func() { endSpan(span, err) }()
+	return u.PaymentUseCase.GetMerchantPayments(ctx, merchantID, limit, offset)
+}
+
+func startSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
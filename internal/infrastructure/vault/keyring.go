@@ -0,0 +1,133 @@
+// Package vault 以信封加密保護卡號：每張卡產生一把隨機資料金鑰（DEK）以 AES-256-GCM
+// 加密卡號，DEK 再由設定檔提供的主金鑰（KEK）加密後與密文一起保存。
+// 更換 KEK 時只需重新包裝 DEK，並以 key ID 區分各筆資料使用的主金鑰。
+package vault
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+)
+
+const keySize = 32
+
+// Keyring 保存本機主金鑰。新資料一律以目前的主金鑰加密，
+// 舊的主金鑰只用於解密輪替前寫入的資料。
+type Keyring struct {
+	currentID      string
+	keys           map[string][]byte
+	fingerprintKey []byte
+}
+
+// NewLocalKeyring 以單一 32 bytes 主金鑰建立 keyring
+func NewLocalKeyring(keyID string, kek []byte) (*Keyring, error) {
+	if keyID == "" {
+		return nil, fmt.Errorf("vault key id is required")
+	}
+	if len(kek) != keySize {
+		return nil, fmt.Errorf("vault key %s must be %d bytes, got %d", keyID, keySize, len(kek))
+	}
+
+	// 指紋金鑰由 KEK 衍生，避免以未加鹽的雜湊暴露卡號
+	mac := hmac.New(sha256.New, kek)
+	mac.Write([]byte("card-fingerprint"))
+
+	return &Keyring{
+		currentID:      keyID,
+		keys:           map[string][]byte{keyID: append([]byte(nil), kek...)},
+		fingerprintKey: mac.Sum(nil),
+	}, nil
+}
+
+// AddDecryptionKey 加入輪替前的主金鑰，只用於解密
+func (k *Keyring) AddDecryptionKey(keyID string, kek []byte) error {
+	if len(kek) != keySize {
+		return fmt.Errorf("vault key %s must be %d bytes, got %d", keyID, keySize, len(kek))
+	}
+	if _, exists := k.keys[keyID]; exists {
+		return fmt.Errorf("vault key %s already registered", keyID)
+	}
+	k.keys[keyID] = append([]byte(nil), kek...)
+	return nil
+}
+
+// Seal 以新的 DEK 加密 plaintext，回傳密文、被 KEK 包裝的 DEK 與 KEK 的 ID。
+// associatedData 會一併驗證，用來把密文綁定在特定 token 上。
+func (k *Keyring) Seal(plaintext, associatedData []byte) (ciphertext, wrappedKey []byte, keyID string, err error) {
+	dek := make([]byte, keySize)
+	if _, err := io.ReadFull(rand.Reader, dek); err != nil {
+		return nil, nil, "", fmt.Errorf("failed to generate data key: %w", err)
+	}
+
+	ciphertext, err = encrypt(dek, plaintext, associatedData)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	wrappedKey, err = encrypt(k.keys[k.currentID], dek, []byte(k.currentID))
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return ciphertext, wrappedKey, k.currentID, nil
+}
+
+// Open 解開 DEK 後解密資料
+func (k *Keyring) Open(ciphertext, wrappedKey []byte, keyID string, associatedData []byte) ([]byte, error) {
+	kek, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown vault key %s", keyID)
+	}
+	dek, err := decrypt(kek, wrappedKey, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	plaintext, err := decrypt(dek, ciphertext, associatedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt card data: %w", err)
+	}
+	return plaintext, nil
+}
+
+// Fingerprint 回傳卡號的 keyed hash，同一卡號得到相同結果但無法反推卡號
+func (k *Keyring) Fingerprint(pan string) string {
+	mac := hmac.New(sha256.New, k.fingerprintKey)
+	mac.Write([]byte(pan))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// encrypt 以 AES-256-GCM 加密，輸出格式為 nonce || ciphertext
+func encrypt(key, plaintext, associatedData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, associatedData), nil
+}
+
+func decrypt(key, data, associatedData []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, associatedData)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
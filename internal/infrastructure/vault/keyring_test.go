@@ -0,0 +1,59 @@
+package vault
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func TestKeyringSealOpen(t *testing.T) {
+	keyring, err := NewLocalKeyring("k1", testKey(1))
+	require.NoError(t, err)
+
+	ciphertext, wrapped, keyID, err := keyring.Seal([]byte("4111111111111111"), []byte("tok_1"))
+	require.NoError(t, err)
+	assert.Equal(t, "k1", keyID)
+	assert.NotContains(t, string(ciphertext), "4111111111111111")
+
+	plaintext, err := keyring.Open(ciphertext, wrapped, keyID, []byte("tok_1"))
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", string(plaintext))
+
+	_, err = keyring.Open(ciphertext, wrapped, keyID, []byte("tok_2"))
+	assert.Error(t, err, "ciphertext must be bound to its token")
+}
+
+func TestKeyringRotation(t *testing.T) {
+	old, err := NewLocalKeyring("k1", testKey(1))
+	require.NoError(t, err)
+	ciphertext, wrapped, keyID, err := old.Seal([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	rotated, err := NewLocalKeyring("k2", testKey(2))
+	require.NoError(t, err)
+	_, err = rotated.Open(ciphertext, wrapped, keyID, nil)
+	assert.Error(t, err)
+
+	require.NoError(t, rotated.AddDecryptionKey("k1", testKey(1)))
+	plaintext, err := rotated.Open(ciphertext, wrapped, keyID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "secret", string(plaintext))
+}
+
+func TestNewLocalKeyringRejectsShortKey(t *testing.T) {
+	_, err := NewLocalKeyring("k1", []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestFingerprintIsStable(t *testing.T) {
+	keyring, err := NewLocalKeyring("k1", testKey(1))
+	require.NoError(t, err)
+	assert.Equal(t, keyring.Fingerprint("4111111111111111"), keyring.Fingerprint("4111111111111111"))
+	assert.NotEqual(t, keyring.Fingerprint("4111111111111111"), keyring.Fingerprint("5555555555554444"))
+}
@@ -0,0 +1,210 @@
+// Package bankfile 解析銀行對帳單檔案（CSV 與 ISO 20022 camt.053），
+// 供銀行轉帳入帳與對帳使用。金額一律轉為以分為單位的整數。
+package bankfile
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Entry 為對帳單上的一筆交易
+type Entry struct {
+	TransactionID string
+	BookingDate   time.Time
+	Amount        int64 // 以分為單位，入帳為正數、支出為負數
+	Currency      string
+	Reference     string // 付款人填寫的附言
+	Account       string // 收款帳號，使用虛擬帳號時用於對應付款
+	Payer         string
+}
+
+const (
+	FormatCSV     = "csv"
+	FormatCAMT053 = "camt053"
+)
+
+// Statement 為一份對帳單，涵蓋 [From, To) 期間的交易
+type Statement struct {
+	Format   string
+	ID       string // 銀行的對帳單編號，CSV 沒有此欄位
+	Account  string
+	Currency string // 對帳單所有交易的幣別相同時才有值
+	From     time.Time
+	To       time.Time
+	Entries  []Entry
+}
+
+// csvColumns 為 CSV 的欄位名稱，前四個為必要欄位
+var csvColumns = []string{"transaction_id", "date", "amount", "currency", "reference", "account", "payer"}
+
+const requiredCSVColumns = 4
+
+// ParseStatement 解析指定格式的對帳單，format 為空時以內容判斷：
+// 以 < 開頭的視為 camt.053，其餘為 CSV
+func ParseStatement(r io.Reader, format string) (*Statement, error) {
+	reader := bufio.NewReader(r)
+	if format == "" {
+		format = FormatCSV
+		if head, _ := reader.Peek(512); strings.HasPrefix(strings.TrimLeft(strings.TrimPrefix(string(head), "\ufeff"), " \t\r\n"), "<") {
+			format = FormatCAMT053
+		}
+	}
+	switch format {
+	case FormatCSV:
+		return ParseCSVStatement(reader)
+	case FormatCAMT053:
+		return ParseCAMT053(reader)
+	default:
+		return nil, fmt.Errorf("unsupported statement format %q", format)
+	}
+}
+
+// ParseCSV 解析 CSV 對帳單中的入帳，支出列會被略過
+func ParseCSV(r io.Reader) ([]Entry, error) {
+	statement, err := ParseCSVStatement(r)
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	for _, entry := range statement.Entries {
+		if entry.Amount > 0 {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// ParseCSVStatement 解析 CSV 對帳單。第一列為欄位名稱（不分大小寫、順序不限），
+// date 接受 2006-01-02 或 RFC 3339，amount 為最多兩位小數的十進位數字。
+// 期間取交易日期的最早與最晚一天，金額為零的列會被略過
+func ParseCSVStatement(r io.Reader) (*Statement, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("empty statement")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid header: %w", err)
+	}
+	// Excel 匯出的 UTF-8 檔案開頭帶有 BOM
+	index := make(map[string]int)
+	for i, name := range header {
+		index[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	for _, name := range csvColumns[:requiredCSVColumns] {
+		if _, ok := index[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	statement := &Statement{Format: FormatCSV}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := reader.FieldPos(0)
+		field := func(name string) string {
+			i, ok := index[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		amount, err := ParseAmount(field("amount"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if amount == 0 {
+			continue
+		}
+		date, err := parseDate(field("date"))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		entry := Entry{
+			TransactionID: field("transaction_id"),
+			BookingDate:   date,
+			Amount:        amount,
+			Currency:      strings.ToUpper(field("currency")),
+			Reference:     field("reference"),
+			Account:       field("account"),
+			Payer:         field("payer"),
+		}
+		if entry.TransactionID == "" {
+			return nil, fmt.Errorf("line %d: missing transaction_id", line)
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.fillFromEntries()
+	return statement, nil
+}
+
+// fillFromEntries 以交易補上檔案未提供的期間與幣別
+func (s *Statement) fillFromEntries() {
+	currencies := make(map[string]bool)
+	for i, entry := range s.Entries {
+		currencies[entry.Currency] = true
+		day := time.Date(entry.BookingDate.Year(), entry.BookingDate.Month(), entry.BookingDate.Day(), 0, 0, 0, 0, time.UTC)
+		if i == 0 || day.Before(s.From) {
+			s.From = day
+		}
+		if end := day.AddDate(0, 0, 1); i == 0 || end.After(s.To) {
+			s.To = end
+		}
+	}
+	if s.Currency == "" && len(currencies) == 1 {
+		for currency := range currencies {
+			s.Currency = currency
+		}
+	}
+}
+
+// ParseAmount 將十進位金額（例如 "1,234.5"、"-20.00"）轉為以分為單位的整數
+func ParseAmount(s string) (int64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", "")
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "-"), "+")
+	units, cents, found := strings.Cut(s, ".")
+	if units == "" || len(cents) > 2 || (found && cents == "") {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	for len(cents) < 2 {
+		cents += "0"
+	}
+	n, err := strconv.ParseUint(units+cents, 10, 63)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", s)
+	}
+	if negative {
+		return -int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// dateLayouts 為接受的日期格式，沒有時區的時間視為 UTC
+var dateLayouts = []string{"2006-01-02", time.RFC3339, "2006-01-02T15:04:05"}
+
+func parseDate(s string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
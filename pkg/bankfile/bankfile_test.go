@@ -0,0 +1,70 @@
+package bankfile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCSV(t *testing.T) {
+	statement := "\ufeffDate,Transaction_ID,Amount,Currency,Reference,Account,Payer\n" +
+		"2026-10-01,TX1,\"1,250.50\",usd,Order BT7K2M9QXP4R,,Jane Smith\n" +
+		"2026-10-01,TX2,-20.00,USD,Bank fee,,\n" +
+		"2026-10-02T09:30:00Z,TX3,99,USD,,99001234567897,\n"
+
+	entries, err := ParseCSV(strings.NewReader(statement))
+	require.NoError(t, err)
+	require.Len(t, entries, 2, "debits are skipped")
+
+	assert.Equal(t, Entry{
+		TransactionID: "TX1",
+		BookingDate:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Amount:        125050,
+		Currency:      "USD",
+		Reference:     "Order BT7K2M9QXP4R",
+		Payer:         "Jane Smith",
+	}, entries[0])
+	assert.Equal(t, "TX3", entries[1].TransactionID)
+	assert.Equal(t, int64(9900), entries[1].Amount)
+	assert.Equal(t, "99001234567897", entries[1].Account)
+	assert.Equal(t, time.Date(2026, 10, 2, 9, 30, 0, 0, time.UTC), entries[1].BookingDate)
+}
+
+func TestParseCSVErrors(t *testing.T) {
+	for name, statement := range map[string]string{
+		"empty":          "",
+		"missing column": "transaction_id,date,amount\nTX1,2026-10-01,10\n",
+		"bad amount":     "transaction_id,date,amount,currency\nTX1,2026-10-01,10.001,USD\n",
+		"bad date":       "transaction_id,date,amount,currency\nTX1,01/10/2026,10,USD\n",
+		"missing id":     "transaction_id,date,amount,currency\n,2026-10-01,10,USD\n",
+	} {
+		_, err := ParseCSV(strings.NewReader(statement))
+		assert.Error(t, err, name)
+	}
+
+	_, err := ParseCSV(strings.NewReader("transaction_id,date,amount,currency\nTX1,2026-10-01,10,USD\nTX2,2026-10-01,x,USD\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 3")
+}
+
+func TestParseAmount(t *testing.T) {
+	for input, want := range map[string]int64{
+		"10":       1000,
+		"10.5":     1050,
+		"1,234.56": 123456,
+		"-20.00":   -2000,
+		"+0.01":    1,
+		" 7.00 ":   700,
+	} {
+		got, err := ParseAmount(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	for _, input := range []string{"", "1.", ".5", "1.234", "abc", "1e3"} {
+		_, err := ParseAmount(input)
+		assert.Error(t, err, input)
+	}
+}
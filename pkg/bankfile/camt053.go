@@ -0,0 +1,205 @@
+package bankfile
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// camt.053 (BankToCustomerStatement) 的結構，只宣告對帳需要的欄位。
+// 未指定 namespace，可同時解析 camt.053.001.02 到 .001.08 等版本
+type camtDocument struct {
+	Statements []camtStatement `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtStatement struct {
+	ID       string      `xml:"Id"`
+	IBAN     string      `xml:"Acct>Id>IBAN"`
+	Other    string      `xml:"Acct>Id>Othr>Id"`
+	Currency string      `xml:"Acct>Ccy"`
+	From     string      `xml:"FrToDt>FrDtTm"`
+	To       string      `xml:"FrToDt>ToDtTm"`
+	Entries  []camtEntry `xml:"Ntry"`
+}
+
+type camtAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+// camtStatus 在 .001.02 為文字內容，.001.08 起改為 <Cd>
+type camtStatus struct {
+	Text string `xml:",chardata"`
+	Code string `xml:"Cd"`
+}
+
+func (s camtStatus) String() string {
+	if s.Code != "" {
+		return strings.TrimSpace(s.Code)
+	}
+	return strings.TrimSpace(s.Text)
+}
+
+type camtEntry struct {
+	Reference    string          `xml:"NtryRef"`
+	Amount       camtAmount      `xml:"Amt"`
+	CreditDebit  string          `xml:"CdtDbtInd"`
+	Status       camtStatus      `xml:"Sts"`
+	BookingDate  string          `xml:"BookgDt>Dt"`
+	BookingTime  string          `xml:"BookgDt>DtTm"`
+	ServicerRef  string          `xml:"AcctSvcrRef"`
+	Transactions []camtTxDetails `xml:"NtryDtls>TxDtls"`
+}
+
+type camtTxDetails struct {
+	ServicerRef  string     `xml:"Refs>AcctSvcrRef"`
+	EndToEndID   string     `xml:"Refs>EndToEndId"`
+	Amount       camtAmount `xml:"Amt"`
+	TxAmount     camtAmount `xml:"AmtDtls>TxAmt>Amt"`
+	Debtor       string     `xml:"RltdPties>Dbtr>Nm"`
+	DebtorParty  string     `xml:"RltdPties>Dbtr>Pty>Nm"`
+	Creditor     string     `xml:"RltdPties>Cdtr>Nm"`
+	CreditorRef  string     `xml:"RmtInf>Strd>CdtrRefInf>Ref"`
+	Unstructured []string   `xml:"RmtInf>Ustrd"`
+}
+
+// ParseCAMT053 解析 ISO 20022 camt.053 對帳單。只處理已入帳（BOOK）的交易，
+// 批次入帳的交易明細會拆成多筆。一個檔案只能包含一份對帳單
+func ParseCAMT053(r io.Reader) (*Statement, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid camt.053 document: %w", err)
+	}
+	switch len(doc.Statements) {
+	case 0:
+		return nil, fmt.Errorf("no statement found in camt.053 document")
+	case 1:
+	default:
+		return nil, fmt.Errorf("camt.053 document contains %d statements, import them separately", len(doc.Statements))
+	}
+
+	stmt := doc.Statements[0]
+	statement := &Statement{
+		Format:   FormatCAMT053,
+		ID:       strings.TrimSpace(stmt.ID),
+		Account:  strings.TrimSpace(stmt.IBAN),
+		Currency: strings.ToUpper(strings.TrimSpace(stmt.Currency)),
+	}
+	if statement.Account == "" {
+		statement.Account = strings.TrimSpace(stmt.Other)
+	}
+
+	for i, ntry := range stmt.Entries {
+		if status := ntry.Status.String(); status != "" && status != "BOOK" {
+			continue
+		}
+		entries, err := camtEntries(ntry)
+		if err != nil {
+			return nil, fmt.Errorf("entry %d: %w", i+1, err)
+		}
+		statement.Entries = append(statement.Entries, entries...)
+	}
+
+	from, to := strings.TrimSpace(stmt.From), strings.TrimSpace(stmt.To)
+	statement.fillFromEntries()
+	if from != "" && to != "" {
+		var err error
+		if statement.From, err = parseDate(from); err != nil {
+			return nil, fmt.Errorf("statement period: %w", err)
+		}
+		if statement.To, err = parseDate(to); err != nil {
+			return nil, fmt.Errorf("statement period: %w", err)
+		}
+	}
+	return statement, nil
+}
+
+// camtEntries 將一筆 Ntry 轉為交易；有多筆 TxDtls 時每筆明細各自成為一筆交易
+func camtEntries(ntry camtEntry) ([]Entry, error) {
+	date := strings.TrimSpace(ntry.BookingDate)
+	if date == "" {
+		date = strings.TrimSpace(ntry.BookingTime)
+	}
+	bookingDate, err := parseDate(date)
+	if err != nil {
+		return nil, err
+	}
+	sign := int64(1)
+	switch strings.TrimSpace(ntry.CreditDebit) {
+	case "CRDT":
+	case "DBIT":
+		sign = -1
+	default:
+		return nil, fmt.Errorf("invalid credit/debit indicator %q", ntry.CreditDebit)
+	}
+	id := strings.TrimSpace(ntry.ServicerRef)
+	if id == "" {
+		id = strings.TrimSpace(ntry.Reference)
+	}
+
+	details := ntry.Transactions
+	if len(details) == 0 {
+		details = []camtTxDetails{{}}
+	}
+	entries := make([]Entry, 0, len(details))
+	for i, tx := range details {
+		amount := ntry.Amount
+		if len(details) > 1 {
+			amount = tx.Amount
+			if strings.TrimSpace(amount.Value) == "" {
+				amount = tx.TxAmount
+			}
+		}
+		value, err := ParseAmount(amount.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		txID := strings.TrimSpace(tx.ServicerRef)
+		if txID == "" {
+			txID = id
+			if len(details) > 1 {
+				txID = fmt.Sprintf("%s/%d", id, i+1)
+			}
+		}
+		if txID == "" {
+			return nil, fmt.Errorf("missing transaction reference")
+		}
+
+		counterparty := tx.Debtor
+		if counterparty == "" {
+			counterparty = tx.DebtorParty
+		}
+		if sign < 0 {
+			counterparty = tx.Creditor
+		}
+
+		entries = append(entries, Entry{
+			TransactionID: txID,
+			BookingDate:   bookingDate,
+			Amount:        sign * value,
+			Currency:      strings.ToUpper(strings.TrimSpace(amount.Currency)),
+			Reference:     camtReference(tx),
+			Payer:         strings.TrimSpace(counterparty),
+		})
+	}
+	return entries, nil
+}
+
+// camtReference 合併端到端編號、結構化參考與附言，供依參考號對應付款
+func camtReference(tx camtTxDetails) string {
+	var parts []string
+	if id := strings.TrimSpace(tx.EndToEndID); id != "" && id != "NOTPROVIDED" {
+		parts = append(parts, id)
+	}
+	if ref := strings.TrimSpace(tx.CreditorRef); ref != "" {
+		parts = append(parts, ref)
+	}
+	for _, text := range tx.Unstructured {
+		if text = strings.TrimSpace(text); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,123 @@
+package bankfile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const camtSample = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+ <BkToCstmrStmt>
+  <GrpHdr><MsgId>MSG-1</MsgId><CreDtTm>2026-10-02T06:00:00Z</CreDtTm></GrpHdr>
+  <Stmt>
+   <Id>STMT-2026-10-01</Id>
+   <FrToDt><FrDtTm>2026-10-01T00:00:00Z</FrDtTm><ToDtTm>2026-10-02T00:00:00Z</ToDtTm></FrToDt>
+   <Acct><Id><IBAN>DE89370400440532013000</IBAN></Id><Ccy>EUR</Ccy></Acct>
+   <Ntry>
+    <NtryRef>N1</NtryRef>
+    <Amt Ccy="EUR">125.50</Amt>
+    <CdtDbtInd>CRDT</CdtDbtInd>
+    <Sts>BOOK</Sts>
+    <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+    <AcctSvcrRef>BANKREF-1</AcctSvcrRef>
+    <NtryDtls><TxDtls>
+     <Refs><EndToEndId>ORDER-42</EndToEndId></Refs>
+     <RltdPties><Dbtr><Nm>Jane Smith</Nm></Dbtr></RltdPties>
+     <RmtInf><Ustrd>Invoice 42</Ustrd></RmtInf>
+    </TxDtls></NtryDtls>
+   </Ntry>
+   <Ntry>
+    <Amt Ccy="EUR">30.00</Amt>
+    <CdtDbtInd>CRDT</CdtDbtInd>
+    <Sts>BOOK</Sts>
+    <BookgDt><DtTm>2026-10-01T15:00:00</DtTm></BookgDt>
+    <AcctSvcrRef>BATCH-7</AcctSvcrRef>
+    <NtryDtls>
+     <TxDtls><Refs><EndToEndId>NOTPROVIDED</EndToEndId></Refs><Amt Ccy="EUR">10.00</Amt><RmtInf><Strd><CdtrRefInf><Ref>RF18539007547034</Ref></CdtrRefInf></Strd></RmtInf></TxDtls>
+     <TxDtls><Refs><AcctSvcrRef>TX-B</AcctSvcrRef></Refs><AmtDtls><TxAmt><Amt Ccy="EUR">20.00</Amt></TxAmt></AmtDtls></TxDtls>
+    </NtryDtls>
+   </Ntry>
+   <Ntry>
+    <Amt Ccy="EUR">2.50</Amt>
+    <CdtDbtInd>DBIT</CdtDbtInd>
+    <Sts><Cd>BOOK</Cd></Sts>
+    <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+    <AcctSvcrRef>FEE-1</AcctSvcrRef>
+   </Ntry>
+   <Ntry>
+    <Amt Ccy="EUR">99.00</Amt>
+    <CdtDbtInd>CRDT</CdtDbtInd>
+    <Sts>PDNG</Sts>
+    <BookgDt><Dt>2026-10-01</Dt></BookgDt>
+    <AcctSvcrRef>PENDING-1</AcctSvcrRef>
+   </Ntry>
+  </Stmt>
+ </BkToCstmrStmt>
+</Document>`
+
+func TestParseCAMT053(t *testing.T) {
+	statement, err := ParseCAMT053(strings.NewReader(camtSample))
+	require.NoError(t, err)
+
+	assert.Equal(t, FormatCAMT053, statement.Format)
+	assert.Equal(t, "STMT-2026-10-01", statement.ID)
+	assert.Equal(t, "DE89370400440532013000", statement.Account)
+	assert.Equal(t, "EUR", statement.Currency)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), statement.From)
+	assert.Equal(t, time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC), statement.To)
+
+	require.Len(t, statement.Entries, 4, "pending entries are skipped")
+	assert.Equal(t, Entry{
+		TransactionID: "BANKREF-1",
+		BookingDate:   time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		Amount:        12550,
+		Currency:      "EUR",
+		Reference:     "ORDER-42 Invoice 42",
+		Payer:         "Jane Smith",
+	}, statement.Entries[0])
+
+	// 批次入帳依明細拆開
+	assert.Equal(t, "BATCH-7/1", statement.Entries[1].TransactionID)
+	assert.Equal(t, int64(1000), statement.Entries[1].Amount)
+	assert.Equal(t, "RF18539007547034", statement.Entries[1].Reference)
+	assert.Equal(t, "TX-B", statement.Entries[2].TransactionID)
+	assert.Equal(t, int64(2000), statement.Entries[2].Amount)
+	assert.Equal(t, time.Date(2026, 10, 1, 15, 0, 0, 0, time.UTC), statement.Entries[2].BookingDate)
+
+	assert.Equal(t, "FEE-1", statement.Entries[3].TransactionID)
+	assert.Equal(t, int64(-250), statement.Entries[3].Amount)
+}
+
+func TestParseCAMT053Errors(t *testing.T) {
+	for name, doc := range map[string]string{
+		"not xml":        "transaction_id,date",
+		"no statement":   `<Document><BkToCstmrStmt></BkToCstmrStmt></Document>`,
+		"two statements": `<Document><BkToCstmrStmt><Stmt><Id>1</Id></Stmt><Stmt><Id>2</Id></Stmt></BkToCstmrStmt></Document>`,
+		"bad indicator": `<Document><BkToCstmrStmt><Stmt><Ntry><Amt Ccy="EUR">1.00</Amt><CdtDbtInd>X</CdtDbtInd>` +
+			`<BookgDt><Dt>2026-10-01</Dt></BookgDt><AcctSvcrRef>R</AcctSvcrRef></Ntry></Stmt></BkToCstmrStmt></Document>`,
+	} {
+		_, err := ParseCAMT053(strings.NewReader(doc))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestParseStatementDetectsFormat(t *testing.T) {
+	statement, err := ParseStatement(strings.NewReader("\ufeff\n"+camtSample), "")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCAMT053, statement.Format)
+
+	statement, err = ParseStatement(strings.NewReader("transaction_id,date,amount,currency\nTX1,2026-10-01,10,USD\nTX2,2026-10-03,-1,USD\n"), "")
+	require.NoError(t, err)
+	assert.Equal(t, FormatCSV, statement.Format)
+	assert.Len(t, statement.Entries, 2, "statements keep debits")
+	assert.Equal(t, "USD", statement.Currency)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), statement.From)
+	assert.Equal(t, time.Date(2026, 10, 4, 0, 0, 0, 0, time.UTC), statement.To)
+
+	_, err = ParseStatement(strings.NewReader(""), "mt940")
+	assert.Error(t, err)
+}
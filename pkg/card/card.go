@@ -0,0 +1,122 @@
+// Package card 提供卡號正規化、Luhn 檢查與卡別判斷，不保存任何卡片資料。
+package card
+
+import (
+	"strings"
+	"time"
+)
+
+type Brand string
+
+const (
+	BrandVisa       Brand = "visa"
+	BrandMastercard Brand = "mastercard"
+	BrandAmex       Brand = "amex"
+	BrandDiscover   Brand = "discover"
+	BrandJCB        Brand = "jcb"
+	BrandDiners     Brand = "diners"
+	BrandUnionPay   Brand = "unionpay"
+	BrandUnknown    Brand = "unknown"
+)
+
+// Normalize 移除卡號中的空白與連字號
+func Normalize(number string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(number)
+}
+
+// LuhnValid 檢查卡號的 Luhn 校驗碼，非數字字元會被忽略
+func LuhnValid(number string) bool {
+	sum, n := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if n%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
+
+// DetectBrand 依 IIN 範圍判斷卡別，並檢查該卡別允許的長度
+func DetectBrand(number string) Brand {
+	n := len(number)
+	switch {
+	case hasPrefix(number, "4") && (n == 13 || n == 16 || n == 19):
+		return BrandVisa
+	case (inRange(number, 2, 51, 55) || inRange(number, 4, 2221, 2720)) && n == 16:
+		return BrandMastercard
+	case (hasPrefix(number, "34") || hasPrefix(number, "37")) && n == 15:
+		return BrandAmex
+	case (hasPrefix(number, "6011") || hasPrefix(number, "65") || inRange(number, 3, 644, 649)) && n >= 16 && n <= 19:
+		return BrandDiscover
+	case inRange(number, 4, 3528, 3589) && n >= 16 && n <= 19:
+		return BrandJCB
+	case (inRange(number, 3, 300, 305) || hasPrefix(number, "36") || hasPrefix(number, "38") || hasPrefix(number, "39")) && n >= 14 && n <= 19:
+		return BrandDiners
+	case hasPrefix(number, "62") && n >= 16 && n <= 19:
+		return BrandUnionPay
+	default:
+		return BrandUnknown
+	}
+}
+
+// CVVLength 回傳卡別的安全碼長度
+func CVVLength(brand Brand) int {
+	if brand == BrandAmex {
+		return 4
+	}
+	return 3
+}
+
+// Expired 判斷卡片在 now 當下是否已過期；卡片在到期月份的最後一天之後才失效
+func Expired(month, year int, now time.Time) bool {
+	if month < 1 || month > 12 {
+		return true
+	}
+	expiry := time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.UTC)
+	return !now.Before(expiry)
+}
+
+// Last4 回傳卡號末四碼
+func Last4(number string) string {
+	if len(number) <= 4 {
+		return number
+	}
+	return number[len(number)-4:]
+}
+
+// IsDigits 回報字串是否只包含數字
+func IsDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func hasPrefix(number, prefix string) bool {
+	return strings.HasPrefix(number, prefix)
+}
+
+func inRange(number string, digits, low, high int) bool {
+	if len(number) < digits {
+		return false
+	}
+	v := 0
+	for _, c := range number[:digits] {
+		v = v*10 + int(c-'0')
+	}
+	return v >= low && v <= high
+}
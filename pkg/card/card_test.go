@@ -0,0 +1,45 @@
+package card
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLuhnValid(t *testing.T) {
+	assert.True(t, LuhnValid("4111111111111111"))
+	assert.True(t, LuhnValid("378282246310005"))
+	assert.False(t, LuhnValid("4111111111111112"))
+	assert.False(t, LuhnValid(""))
+}
+
+func TestDetectBrand(t *testing.T) {
+	tests := map[string]Brand{
+		"4111111111111111": BrandVisa,
+		"5555555555554444": BrandMastercard,
+		"2223003122003222": BrandMastercard,
+		"378282246310005":  BrandAmex,
+		"6011111111111117": BrandDiscover,
+		"3530111333300000": BrandJCB,
+		"30569309025904":   BrandDiners,
+		"6200000000000005": BrandUnionPay,
+		"411111111111":     BrandUnknown,
+		"9999999999999995": BrandUnknown,
+	}
+	for number, want := range tests {
+		assert.Equal(t, want, DetectBrand(number), number)
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2026, time.March, 15, 0, 0, 0, 0, time.UTC)
+	assert.False(t, Expired(3, 2026, now))
+	assert.True(t, Expired(2, 2026, now))
+	assert.False(t, Expired(12, 2030, now))
+	assert.True(t, Expired(13, 2030, now))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "4111111111111111", Normalize("4111 1111-1111 1111"))
+}
@@ -1,6 +1,7 @@
 package errors
 
 import (
+	stderrors "errors"
 	"fmt"
 	"runtime"
 )
@@ -20,6 +21,10 @@ func (e *AppError) Error() string {
 	return fmt.Sprintf("%s (at %s:%d)", e.Message, e.File, e.Line)
 }
 
+func (e *AppError) Unwrap() error {
+	return e.Cause
+}
+
 func New(message string) *AppError {
 	_, file, line, _ := runtime.Caller(1)
 	return &AppError{
@@ -55,4 +60,15 @@ func WithCode(err error, code string) *AppError {
 		File:    file,
 		Line:    line,
 	}
-}
\ No newline at end of file
+}
+
+// Code 回傳錯誤鏈中第一個設定了代碼的 AppError 代碼，沒有時回傳空字串
+func Code(err error) string {
+	for err != nil {
+		if appErr, ok := err.(*AppError); ok && appErr.Code != "" {
+			return appErr.Code
+		}
+		err = stderrors.Unwrap(err)
+	}
+	return ""
+}
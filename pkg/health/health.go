@@ -0,0 +1,116 @@
+// Package health 提供可插拔的健康檢查，用於 readiness 探針與監控。
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
+
+type checkerFunc struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
+func (c checkerFunc) Name() string                    { return c.name }
+func (c checkerFunc) Check(ctx context.Context) error { return c.check(ctx) }
+
+// NewChecker 以函式建立 Checker
+func NewChecker(name string, check func(ctx context.Context) error) Checker {
+	return checkerFunc{name: name, check: check}
+}
+
+// BacklogChecker 在待處理數量超過門檻時回報失敗，例如對外佇列積壓
+func BacklogChecker(name string, threshold int64, count func(ctx context.Context) (int64, error)) Checker {
+	return NewChecker(name, func(ctx context.Context) error {
+		n, err := count(ctx)
+		if err != nil {
+			return err
+		}
+		if n > threshold {
+			return fmt.Errorf("backlog %d exceeds threshold %d", n, threshold)
+		}
+		return nil
+	})
+}
+
+type Result struct {
+	Name      string  `json:"name"`
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMS float64 `json:"latency_ms"`
+}
+
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Run 並行執行所有檢查，每個檢查各自套用 timeout；任一失敗則整體為 fail
+func Run(ctx context.Context, timeout time.Duration, checkers ...Checker) Report {
+	results := make([]Result, len(checkers))
+
+	var wg sync.WaitGroup
+	for i, checker := range checkers {
+		wg.Add(1)
+		go func(i int, checker Checker) {
+			defer wg.Done()
+			results[i] = runCheck(ctx, timeout, checker)
+		}(i, checker)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func runCheck(ctx context.Context, timeout time.Duration, checker Checker) Result {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- checker.Check(ctx) }()
+
+	// 檢查本身若不尊重 context，仍以 timeout 為準回報失敗
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := Result{
+		Name:      checker.Name(),
+		Status:    StatusOK,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
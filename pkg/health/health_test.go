@@ -0,0 +1,66 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("all checks pass", func(t *testing.T) {
+		report := Run(ctx, time.Second,
+			NewChecker("a", func(ctx context.Context) error { return nil }),
+			NewChecker("b", func(ctx context.Context) error { return nil }),
+		)
+		assert.True(t, report.OK())
+		require.Len(t, report.Checks, 2)
+		assert.Equal(t, "a", report.Checks[0].Name)
+		assert.Equal(t, StatusOK, report.Checks[1].Status)
+	})
+
+	t.Run("one failing check fails the report", func(t *testing.T) {
+		report := Run(ctx, time.Second,
+			NewChecker("ok", func(ctx context.Context) error { return nil }),
+			NewChecker("broken", func(ctx context.Context) error { return errors.New("connection refused") }),
+		)
+		assert.False(t, report.OK())
+		assert.Equal(t, StatusFail, report.Checks[1].Status)
+		assert.Equal(t, "connection refused", report.Checks[1].Error)
+	})
+
+	t.Run("slow check times out", func(t *testing.T) {
+		block := make(chan struct{})
+		defer close(block)
+
+		start := time.Now()
+		report := Run(ctx, 20*time.Millisecond,
+			NewChecker("slow", func(ctx context.Context) error { <-block; return nil }),
+		)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.False(t, report.OK())
+		assert.Contains(t, report.Checks[0].Error, "deadline exceeded")
+		assert.GreaterOrEqual(t, report.Checks[0].LatencyMS, float64(20))
+	})
+
+	t.Run("no checks is healthy", func(t *testing.T) {
+		assert.True(t, Run(ctx, time.Second).OK())
+	})
+}
+
+func TestBacklogChecker(t *testing.T) {
+	ctx := context.Background()
+	count := int64(0)
+	checker := BacklogChecker("queue", 10, func(ctx context.Context) (int64, error) { return count, nil })
+
+	count = 10
+	assert.NoError(t, checker.Check(ctx))
+
+	count = 11
+	assert.EqualError(t, checker.Check(ctx), "backlog 11 exceeds threshold 10")
+}
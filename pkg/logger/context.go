@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+var defaultLogger atomic.Value
+
+func init() {
+	defaultLogger.Store(Logger(&zapLogger{zap: zap.NewNop()}))
+}
+
+// SetDefault 設定 context 中沒有 logger 時使用的預設 logger
+func SetDefault(l Logger) {
+	defaultLogger.Store(l)
+}
+
+// NewFromZap 以既有的 zap logger 建立 Logger
+func NewFromZap(z *zap.Logger) Logger {
+	return &zapLogger{zap: z}
+}
+
+// WithContext 將帶有請求欄位（request_id、trace_id 等）的 logger 放入 context
+func WithContext(ctx context.Context, l Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext 取出請求範圍的 logger，沒有時回傳預設 logger
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return defaultLogger.Load().(Logger)
+}
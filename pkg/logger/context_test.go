@@ -0,0 +1,34 @@
+package logger
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	base := NewFromZap(zap.New(core))
+
+	ctx := WithContext(context.Background(), base.With(zap.String("request_id", "req_1")))
+	FromContext(ctx).Info("scoped")
+
+	entries := logs.All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "req_1", entries[0].ContextMap()["request_id"])
+	}
+}
+
+func TestFromContextFallsBackToDefault(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	SetDefault(NewFromZap(zap.New(core)))
+	t.Cleanup(func() { SetDefault(NewFromZap(zap.NewNop())) })
+
+	FromContext(context.Background()).Info("background")
+
+	assert.Equal(t, 1, logs.FilterMessage("background").Len())
+}
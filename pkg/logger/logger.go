@@ -80,6 +80,9 @@ func NewLogger(cfg Config) (Logger, error) {
 		encoder = zapcore.NewJSONEncoder(encoderConfig)
 	}
 
+	// 卡號、CVV、電子郵件與電話號碼在寫出前一律遮蔽
+	encoder = NewRedactingEncoder(encoder)
+
 	var writeSyncer zapcore.WriteSyncer
 	if cfg.OutputPath == "stdout" || cfg.OutputPath == "" {
 		writeSyncer = zapcore.AddSync(os.Stdout)
@@ -95,4 +98,4 @@ func NewLogger(cfg Config) (Logger, error) {
 	logger := zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel))
 
 	return &zapLogger{zap: logger}, nil
-}
\ No newline at end of file
+}
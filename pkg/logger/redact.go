@@ -0,0 +1,306 @@
+package logger
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/company/payment-service/pkg/card"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// 結構欄位以 `redact:"<kind>"` 標記敏感資料，kind 可為
+// pan、email、phone、name、secret（完全遮蔽）或 text（掃描內文中的敏感格式）
+const redactTag = "redact"
+
+const redactedPlaceholder = "[REDACTED]"
+
+var (
+	panPattern   = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// 只比對帶國碼的號碼，避免誤遮 ID、金額或時間
+	phonePattern = regexp.MustCompile(`\+\d[\d\s\-().]{6,18}\d`)
+	cvvPattern   = regexp.MustCompile(`(?i)(\b(?:cvv2?|cvc2?|security[_ ]?code)\b["']?\s*[:=]\s*["']?)\d{3,4}`)
+)
+
+// 欄位名稱（不分大小寫）屬於以下之一時整個值會被遮蔽
+var sensitiveKeys = map[string]string{
+	"cvv":           "secret",
+	"cvv2":          "secret",
+	"cvc":           "secret",
+	"security_code": "secret",
+	"password":      "secret",
+	"secret":        "secret",
+	"api_key":       "secret",
+	"pan":           "pan",
+	"card_number":   "pan",
+	"email":         "email",
+	"phone":         "phone",
+}
+
+// RedactString 遮蔽字串中的卡號（通過 Luhn 檢查）、CVV、電子郵件與電話號碼
+func RedactString(s string) string {
+	if s == "" {
+		return s
+	}
+	s = cvvPattern.ReplaceAllString(s, "${1}***")
+	s = panPattern.ReplaceAllStringFunc(s, func(m string) string {
+		if !luhnValid(m) {
+			return m
+		}
+		return maskPAN(m)
+	})
+	s = emailPattern.ReplaceAllStringFunc(s, maskEmail)
+	s = phonePattern.ReplaceAllStringFunc(s, maskPhone)
+	return s
+}
+
+// RedactValue 回傳遮蔽後的副本：字串會掃描敏感格式，結構體依 redact 標籤處理，
+// 結果以 JSON 欄位名稱組成 map，可直接交給 zap 或 JSON 序列化
+func RedactValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return redactReflect(reflect.ValueOf(v))
+}
+
+// Sensitive 建立遮蔽後的 zap 欄位，適合記錄 entity 等含個資的結構
+func Sensitive(key string, v interface{}) zap.Field {
+	return zap.Any(key, RedactValue(v))
+}
+
+// RedactFields 是 zap 欄位的遮蔽 hook：敏感欄位名稱整個遮蔽，
+// 字串、錯誤與任意結構則掃描其內容
+func RedactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = redactField(f)
+	}
+	return out
+}
+
+func redactField(f zapcore.Field) zapcore.Field {
+	if kind, ok := sensitiveKeys[strings.ToLower(f.Key)]; ok {
+		switch f.Type {
+		case zapcore.StringType:
+			return zap.String(f.Key, redactKind(kind, f.String))
+		case zapcore.SkipType:
+			return f
+		default:
+			return zap.String(f.Key, redactedPlaceholder)
+		}
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		return zap.String(f.Key, RedactString(f.String))
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			return zap.String(f.Key, RedactString(string(b)))
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			return zap.String(f.Key, RedactString(err.Error()))
+		}
+	case zapcore.StringerType:
+		if s, ok := f.Interface.(interface{ String() string }); ok {
+			return zap.String(f.Key, RedactString(s.String()))
+		}
+	case zapcore.ReflectType:
+		return zap.Any(f.Key, RedactValue(f.Interface))
+	}
+	return f
+}
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+func redactReflect(v reflect.Value) interface{} {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return nil
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return redactReflect(v.Elem())
+	case reflect.String:
+		return RedactString(v.String())
+	case reflect.Struct:
+		// time.Time 等自帶序列化方式的型別維持原樣
+		if v.Type().Implements(jsonMarshalerType) || reflect.PtrTo(v.Type()).Implements(jsonMarshalerType) {
+			return v.Interface()
+		}
+		return redactStruct(v)
+	case reflect.Slice, reflect.Array:
+		elem := v.Type().Elem()
+		for elem.Kind() == reflect.Ptr {
+			elem = elem.Elem()
+		}
+		if elem.Kind() != reflect.Struct && elem.Kind() != reflect.String && elem.Kind() != reflect.Interface {
+			return v.Interface()
+		}
+		out := make([]interface{}, v.Len())
+		for i := range out {
+			out[i] = redactReflect(v.Index(i))
+		}
+		return out
+	default:
+		return v.Interface()
+	}
+}
+
+func redactStruct(v reflect.Value) map[string]interface{} {
+	t := v.Type()
+	out := make(map[string]interface{}, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := field.Name
+		if tag := field.Tag.Get("json"); tag != "" {
+			tagName := strings.Split(tag, ",")[0]
+			if tagName == "-" {
+				continue
+			}
+			if tagName != "" {
+				name = tagName
+			}
+		}
+
+		fv := v.Field(i)
+		if kind := field.Tag.Get(redactTag); kind != "" {
+			out[name] = redactTagged(kind, fv)
+			continue
+		}
+		out[name] = redactReflect(fv)
+	}
+	return out
+}
+
+func redactTagged(kind string, v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.String {
+		return redactedPlaceholder
+	}
+	return redactKind(kind, v.String())
+}
+
+func redactKind(kind, s string) string {
+	if s == "" {
+		return s
+	}
+	switch kind {
+	case "pan":
+		return maskPAN(s)
+	case "email":
+		return maskEmail(s)
+	case "phone":
+		return maskPhone(s)
+	case "name":
+		return maskName(s)
+	case "text":
+		return RedactString(s)
+	default:
+		return redactedPlaceholder
+	}
+}
+
+// maskPAN 只保留最後四碼，分隔符號維持原位
+func maskPAN(s string) string {
+	return maskDigits(s, 4)
+}
+
+// maskPhone 只保留最後四碼
+func maskPhone(s string) string {
+	return maskDigits(s, 4)
+}
+
+func maskDigits(s string, keep int) string {
+	total := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			total++
+		}
+	}
+	var b strings.Builder
+	seen := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			seen++
+			if seen <= total-keep {
+				b.WriteByte('*')
+				continue
+			}
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func maskEmail(s string) string {
+	at := strings.LastIndex(s, "@")
+	if at <= 0 {
+		return redactedPlaceholder
+	}
+	return s[:1] + "***" + s[at:]
+}
+
+func maskName(s string) string {
+	words := strings.Fields(s)
+	for i, w := range words {
+		r := []rune(w)
+		words[i] = string(r[0]) + strings.Repeat("*", len(r)-1)
+	}
+	return strings.Join(words, " ")
+}
+
+// luhnValid 只對長度符合卡號（13 碼以上）的數字串做 Luhn 檢查
+func luhnValid(s string) bool {
+	digits := 0
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits++
+		}
+	}
+	return digits >= 13 && card.LuhnValid(s)
+}
+
+// redactingEncoder 包裝 zap encoder，讓 logger.With 加入的欄位與每筆日誌的訊息、欄位都先經過遮蔽
+type redactingEncoder struct {
+	zapcore.Encoder
+}
+
+// NewRedactingEncoder 以遮蔽規則包裝既有的 encoder
+func NewRedactingEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return &redactingEncoder{Encoder: enc}
+}
+
+func (e *redactingEncoder) Clone() zapcore.Encoder {
+	return &redactingEncoder{Encoder: e.Encoder.Clone()}
+}
+
+func (e *redactingEncoder) AddString(key, value string) {
+	redactField(zap.String(key, value)).AddTo(e.Encoder)
+}
+
+func (e *redactingEncoder) AddByteString(key string, value []byte) {
+	redactField(zap.ByteString(key, value)).AddTo(e.Encoder)
+}
+
+func (e *redactingEncoder) AddReflected(key string, value interface{}) error {
+	return e.Encoder.AddReflected(key, RedactValue(value))
+}
+
+func (e *redactingEncoder) EncodeEntry(entry zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	entry.Message = RedactString(entry.Message)
+	return e.Encoder.EncodeEntry(entry, RedactFields(fields))
+}
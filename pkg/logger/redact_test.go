@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type testCustomer struct {
+	ID    string `json:"id"`
+	Name  string `json:"name" redact:"name"`
+	Email string `json:"email" redact:"email"`
+	Phone string `json:"phone" redact:"phone"`
+	Note  string `json:"note" redact:"text"`
+	Token string `json:"-"`
+}
+
+func TestRedactString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"pan", "card 4111111111111111 declined", "card ************1111 declined"},
+		{"pan with separators", "card 4111-1111-1111-1111", "card ****-****-****-1111"},
+		{"non luhn digits kept", "order 1234567890123456", "order 1234567890123456"},
+		{"uuid kept", "payment 550e8400-e29b-41d4-a716-446655440001", "payment 550e8400-e29b-41d4-a716-446655440001"},
+		{"cvv", `{"cvv": "123"}`, `{"cvv": "***"}`},
+		{"email", "sent to john.doe@example.com", "sent to j***@example.com"},
+		{"phone", "call +1 (234) 567-8901", "call +* (***) ***-8901"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, RedactString(tt.in))
+		})
+	}
+}
+
+func TestRedactValueHonoursStructTags(t *testing.T) {
+	got := RedactValue(&testCustomer{
+		ID:    "cus_1",
+		Name:  "John Doe",
+		Email: "john@example.com",
+		Phone: "+1234567890",
+		Note:  "paid with 4111111111111111",
+		Token: "secret",
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"id":    "cus_1",
+		"name":  "J*** D**",
+		"email": "j***@example.com",
+		"phone": "+******7890",
+		"note":  "paid with ************1111",
+	}, got)
+}
+
+func TestNewLoggerRedactsOutput(t *testing.T) {
+	for _, format := range []string{"json", "console"} {
+		t.Run(format, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out.log")
+			log, err := NewLogger(Config{Level: "debug", Format: format, OutputPath: path})
+			require.NoError(t, err)
+
+			log = log.With(zap.String("cvv", "123"), zap.String("contact", "jane@example.com"))
+			log.Info("charging 4111111111111111",
+				zap.String("email", "john@example.com"),
+				zap.String("phone", "+1234567890"),
+				zap.Error(errors.New("card 5555555555554444 declined")),
+				zap.Any("customer", testCustomer{Name: "John Doe", Email: "john@example.com"}),
+			)
+
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			out := string(raw)
+
+			for _, leaked := range []string{"4111111111111111", "5555555555554444", "john@example.com", "jane@example.com", "+1234567890", `"123"`, "John Doe"} {
+				assert.NotContains(t, out, leaked)
+			}
+			assert.Contains(t, out, "************1111")
+			assert.Contains(t, out, "************4444")
+			assert.Contains(t, out, "j***@example.com")
+			assert.Contains(t, out, "+******7890")
+			assert.Contains(t, out, "[REDACTED]")
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocumentBytes(t *testing.T) {
+	d := New()
+	d.Text(50, 60, 12, HelveticaBold, "Invoice (draft)")
+	d.Line(50, 70, 545, 70)
+	d.AddPage()
+	d.TextRight(545, 60, 10, Helvetica, "Page 2")
+	out := d.Bytes()
+
+	assert.True(t, bytes.HasPrefix(out, []byte("%PDF-1.4\n")))
+	assert.True(t, bytes.HasSuffix(out, []byte("%%EOF\n")))
+	assert.Contains(t, string(out), "/Count 2")
+	assert.Contains(t, string(out), `(Invoice \(draft\)) Tj`)
+	assert.Contains(t, string(out), "BT /F2 12 Tf 50 782 Td", "y is measured from the top")
+
+	// startxref 與每個 xref 項目都必須指向正確的位元組位置
+	m := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(out)
+	require.NotNil(t, m)
+	xref, err := strconv.Atoi(string(m[1]))
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(out[xref:], []byte("xref\n")))
+
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(out, -1)
+	require.Len(t, entries, 8)
+	for i, e := range entries {
+		offset, _ := strconv.Atoi(string(e[1]))
+		assert.True(t, bytes.HasPrefix(out[offset:], []byte(strconv.Itoa(i+1)+" 0 obj")), "object %d", i+1)
+	}
+}
+
+func TestEscape(t *testing.T) {
+	assert.Equal(t, `a\\b`, escape(`a\b`))
+	assert.Equal(t, `Caf\351`, escape("Café"))
+	assert.Equal(t, "??", escape("王明"))
+}
+
+func TestTextWidth(t *testing.T) {
+	assert.InDelta(t, 5.56*3, TextWidth("100", 10), 0.001)
+	assert.Less(t, TextWidth("iii", 10), TextWidth("MMM", 10))
+}
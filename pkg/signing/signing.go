@@ -0,0 +1,198 @@
+// Package signing 以 HMAC-SHA256 簽署 API 請求，取代在 header 中傳送長期有效的 API key。
+// 簽章放在 Authorization header：
+//
+//	Authorization: HMAC-SHA256 key_id=<merchant id>,timestamp=<unix>,nonce=<nonce>,signature=<hex>
+//
+// 簽署的內容為以換行連接的 scheme、timestamp、nonce、大寫的 method、
+// request URI（路徑與查詢字串）與 body 的 SHA-256（hex）。
+package signing
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Scheme 為 Authorization header 的驗證方式
+const Scheme = "HMAC-SHA256"
+
+// DefaultTolerance 為伺服器未指定時，請求時間戳記與伺服器時間可接受的差距
+const DefaultTolerance = 5 * time.Minute
+
+const (
+	minNonceLength = 16
+	maxNonceLength = 128
+)
+
+var ErrInvalidSignature = errors.New("invalid request signature")
+
+// Signature 為 Authorization header 解析出的簽章
+type Signature struct {
+	KeyID     string
+	Timestamp int64
+	Nonce     string
+	Value     string
+}
+
+// IsSigned 回傳 Authorization header 是否使用 HMAC 簽章
+func IsSigned(authorization string) bool {
+	return strings.HasPrefix(authorization, Scheme+" ")
+}
+
+// Parse 解析 Authorization header，欄位缺少或格式錯誤時回傳 ErrInvalidSignature。
+// nonce 為 16 到 128 個英數字、- 或 _
+func Parse(authorization string) (*Signature, error) {
+	if !IsSigned(authorization) {
+		return nil, ErrInvalidSignature
+	}
+	sig := &Signature{}
+	var timestamp string
+	for _, part := range strings.Split(strings.TrimPrefix(authorization, Scheme+" "), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "key_id":
+			sig.KeyID = value
+		case "timestamp":
+			timestamp = value
+		case "nonce":
+			sig.Nonce = value
+		case "signature":
+			sig.Value = strings.ToLower(value)
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || sig.KeyID == "" || sig.Value == "" || !validNonce(sig.Nonce) {
+		return nil, ErrInvalidSignature
+	}
+	sig.Timestamp = unix
+	return sig, nil
+}
+
+// Time 回傳簽署時間
+func (s *Signature) Time() time.Time {
+	return time.Unix(s.Timestamp, 0)
+}
+
+// Verify 以 secret 重新計算簽章並比對，不檢查時間戳記與 nonce 是否重複
+func (s *Signature) Verify(secret, method, uri string, body []byte) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+	expected := Compute(secret, s.Timestamp, s.Nonce, method, uri, body)
+	if !hmac.Equal([]byte(expected), []byte(s.Value)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Compute 回傳請求的 HMAC-SHA256 簽章（hex）
+func Compute(secret string, timestamp int64, nonce, method, uri string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strings.Join([]string{
+		Scheme,
+		strconv.FormatInt(timestamp, 10),
+		nonce,
+		strings.ToUpper(method),
+		uri,
+		hex.EncodeToString(bodyHash[:]),
+	}, "\n")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Signer 供 Go 客戶端簽署請求，KeyID 為商戶 ID，Secret 為平台發行的簽章金鑰
+type Signer struct {
+	KeyID  string
+	Secret string
+	// Now 預設為 time.Now，測試時可替換
+	Now func() time.Time
+}
+
+func NewSigner(keyID, secret string) *Signer {
+	return &Signer{KeyID: keyID, Secret: secret, Now: time.Now}
+}
+
+// Sign 讀取 req 的 body 計算簽章並設定 Authorization header，body 讀取後會重新放回 req。
+// 每次呼叫產生新的 nonce，重送請求時需重新簽署
+func (s *Signer) Sign(req *http.Request) error {
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("read request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	nonce, err := newNonce()
+	if err != nil {
+		return fmt.Errorf("generate nonce: %w", err)
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	timestamp := now().Unix()
+	signature := Compute(s.Secret, timestamp, nonce, req.Method, req.URL.RequestURI(), body)
+	req.Header.Set("Authorization", fmt.Sprintf("%s key_id=%s,timestamp=%d,nonce=%s,signature=%s",
+		Scheme, s.KeyID, timestamp, nonce, signature))
+	return nil
+}
+
+// Transport 回傳送出前簽署每個請求的 RoundTripper，base 為 nil 時使用 http.DefaultTransport
+func (s *Signer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{signer: s, base: base}
+}
+
+type transport struct {
+	signer *Signer
+	base   http.RoundTripper
+}
+
+// RoundTrip 簽署請求的副本，不修改呼叫端的請求
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	if err := t.signer.Sign(signed); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(signed)
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func validNonce(nonce string) bool {
+	if len(nonce) < minNonceLength || len(nonce) > maxNonceLength {
+		return false
+	}
+	for _, r := range nonce {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+		default:
+			return false
+		}
+	}
+	return true
+}
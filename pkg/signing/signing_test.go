@@ -0,0 +1,99 @@
+package signing
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	signer := NewSigner("merchant_1", "secret")
+	signer.Now = func() time.Time { return now }
+
+	body := `{"amount":100}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments?expand=customer", strings.NewReader(body))
+	require.NoError(t, signer.Sign(req))
+
+	// body 重新放回請求
+	sent, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(sent))
+
+	sig, err := Parse(req.Header.Get("Authorization"))
+	require.NoError(t, err)
+	assert.Equal(t, "merchant_1", sig.KeyID)
+	assert.Equal(t, now.Unix(), sig.Time().Unix())
+	assert.Len(t, sig.Nonce, 32)
+	assert.NoError(t, sig.Verify("secret", "post", "/api/v1/payments?expand=customer", []byte(body)))
+
+	assert.ErrorIs(t, sig.Verify("other", http.MethodPost, "/api/v1/payments?expand=customer", []byte(body)), ErrInvalidSignature)
+	assert.ErrorIs(t, sig.Verify("secret", http.MethodPut, "/api/v1/payments?expand=customer", []byte(body)), ErrInvalidSignature)
+	assert.ErrorIs(t, sig.Verify("secret", http.MethodPost, "/api/v1/payments", []byte(body)), ErrInvalidSignature)
+	assert.ErrorIs(t, sig.Verify("secret", http.MethodPost, "/api/v1/payments?expand=customer", []byte(`{"amount":1}`)), ErrInvalidSignature)
+	assert.ErrorIs(t, sig.Verify("", http.MethodPost, "/api/v1/payments?expand=customer", []byte(body)), ErrInvalidSignature)
+
+	// 每次簽署使用新的 nonce
+	again := httptest.NewRequest(http.MethodPost, "/api/v1/payments?expand=customer", strings.NewReader(body))
+	require.NoError(t, signer.Sign(again))
+	other, err := Parse(again.Header.Get("Authorization"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sig.Nonce, other.Nonce)
+}
+
+func TestParse(t *testing.T) {
+	valid := "HMAC-SHA256 key_id=k,timestamp=1700000000,nonce=0123456789abcdef,signature=ABCD"
+	sig, err := Parse(valid)
+	require.NoError(t, err)
+	assert.Equal(t, &Signature{KeyID: "k", Timestamp: 1700000000, Nonce: "0123456789abcdef", Value: "abcd"}, sig)
+
+	for _, header := range []string{
+		"",
+		"Bearer api_key_merchant_1",
+		"HMAC-SHA256 timestamp=1700000000,nonce=0123456789abcdef,signature=abcd",
+		"HMAC-SHA256 key_id=k,timestamp=now,nonce=0123456789abcdef,signature=abcd",
+		"HMAC-SHA256 key_id=k,timestamp=1700000000,nonce=short,signature=abcd",
+		"HMAC-SHA256 key_id=k,timestamp=1700000000,nonce=0123456789abcdef!,signature=abcd",
+		"HMAC-SHA256 key_id=k,timestamp=1700000000,nonce=0123456789abcdef",
+	} {
+		_, err := Parse(header)
+		assert.ErrorIs(t, err, ErrInvalidSignature, header)
+	}
+}
+
+func TestTransport(t *testing.T) {
+	var received *Signature
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		sig, err := Parse(r.Header.Get("Authorization"))
+		if err != nil || sig.Verify("secret", r.Method, r.URL.RequestURI(), body) != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		received = sig
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: NewSigner("merchant_1", "secret").Transport(nil)}
+	req, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/payments", strings.NewReader(`{"amount":100}`))
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	require.NotNil(t, received)
+	assert.Equal(t, "merchant_1", received.KeyID)
+	assert.Empty(t, req.Header.Get("Authorization"), "caller's request is not modified")
+
+	resp, err = client.Get(server.URL + "/api/v1/limits")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
@@ -0,0 +1,183 @@
+// Package webhook 驗證與解析支付網關送來的 webhook。每個網關以 Verifier 檢查簽章，
+// 事件內容統一為 Event 格式：
+//
+//	{"id": "evt_1", "type": "payment.succeeded", "data": {"payment_id": "...", "reason": "..."}}
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// SchemeTimestampedHMAC 的簽章 header 為 "t=<unix>,v1=<hex>"，對 "<t>.<body>" 做 HMAC-SHA256；
+	// 輪替金鑰期間可帶多個 v1
+	SchemeTimestampedHMAC = "timestamped_hmac"
+	// SchemeHMACSHA256 的簽章 header 為 body 的 HMAC-SHA256，以 base64 表示，沒有時間戳記
+	SchemeHMACSHA256 = "hmac_sha256"
+)
+
+// DefaultTolerance 為 SchemeTimestampedHMAC 未指定時可接受的時間差距
+const DefaultTolerance = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Verifier 檢查 webhook 的簽章，失敗時回傳 ErrInvalidSignature 或說明原因的錯誤
+type Verifier interface {
+	Verify(header http.Header, body []byte, now time.Time) error
+}
+
+// NewVerifier 依簽章格式建立 Verifier；secret 為空時所有請求都會被拒絕
+func NewVerifier(scheme, secret, header string, tolerance time.Duration) (Verifier, error) {
+	if header == "" {
+		return nil, fmt.Errorf("signature header is required")
+	}
+	switch scheme {
+	case SchemeTimestampedHMAC:
+		if tolerance <= 0 {
+			tolerance = DefaultTolerance
+		}
+		return &TimestampedHMAC{Secret: secret, Header: header, Tolerance: tolerance}, nil
+	case SchemeHMACSHA256:
+		return &HMACSHA256{Secret: secret, Header: header}, nil
+	default:
+		return nil, fmt.Errorf("unsupported signature scheme %q", scheme)
+	}
+}
+
+// TimestampedHMAC 驗證帶時間戳記的簽章，時間差距超過 Tolerance 的請求視為重送
+type TimestampedHMAC struct {
+	Secret    string
+	Header    string
+	Tolerance time.Duration
+}
+
+func (v *TimestampedHMAC) Verify(header http.Header, body []byte, now time.Time) error {
+	if v.Secret == "" {
+		return ErrInvalidSignature
+	}
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get(v.Header), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, strings.ToLower(value))
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := timestampedSignature(v.Secret, timestamp, body)
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(expected), []byte(signature)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if skew := now.Sub(time.Unix(unix, 0)); skew > v.Tolerance || skew < -v.Tolerance {
+		return fmt.Errorf("webhook timestamp is outside the allowed window")
+	}
+	return nil
+}
+
+// SignTimestamped 產生 SchemeTimestampedHMAC 的 header 值，供測試與模擬網關使用
+func SignTimestamped(secret string, body []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + timestampedSignature(secret, timestamp, body)
+}
+
+func timestampedSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// HMACSHA256 驗證 body 的 base64 HMAC-SHA256 簽章
+type HMACSHA256 struct {
+	Secret string
+	Header string
+}
+
+func (v *HMACSHA256) Verify(header http.Header, body []byte, now time.Time) error {
+	if v.Secret == "" {
+		return ErrInvalidSignature
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(header.Get(v.Header)))
+	if err != nil || !hmac.Equal(signature, hmacSHA256(v.Secret, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// SignHMACSHA256 產生 SchemeHMACSHA256 的 header 值，供測試與模擬網關使用
+func SignHMACSHA256(secret string, body []byte) string {
+	return base64.StdEncoding.EncodeToString(hmacSHA256(secret, body))
+}
+
+func hmacSHA256(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
+
+// 網關事件類型
+const (
+	EventPaymentSucceeded = "payment.succeeded"
+	EventPaymentFailed    = "payment.failed"
+	EventPaymentCanceled  = "payment.canceled"
+)
+
+// Event 為網關通知的事件，ID 由網關產生，同一事件重送時相同
+type Event struct {
+	ID        string
+	Type      string
+	PaymentID string
+	Reason    string // 失敗或取消的原因
+}
+
+type envelope struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		PaymentID string `json:"payment_id"`
+		Reason    string `json:"reason"`
+	} `json:"data"`
+}
+
+// Parse 解析事件內容，id 與 type 為必要欄位
+func Parse(body []byte) (*Event, error) {
+	var e envelope
+	if err := json.Unmarshal(body, &e); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+	if strings.TrimSpace(e.ID) == "" {
+		return nil, fmt.Errorf("webhook event id is required")
+	}
+	if strings.TrimSpace(e.Type) == "" {
+		return nil, fmt.Errorf("webhook event type is required")
+	}
+	return &Event{
+		ID:        strings.TrimSpace(e.ID),
+		Type:      strings.TrimSpace(e.Type),
+		PaymentID: strings.TrimSpace(e.Data.PaymentID),
+		Reason:    e.Data.Reason,
+	}, nil
+}
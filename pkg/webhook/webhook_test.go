@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampedHMAC(t *testing.T) {
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	body := []byte(`{"id":"evt_1"}`)
+	v, err := NewVerifier(SchemeTimestampedHMAC, "secret", "Examplepay-Signature", 0)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("Examplepay-Signature", SignTimestamped("secret", body, now))
+	assert.NoError(t, v.Verify(header, body, now.Add(time.Minute)))
+
+	// 輪替金鑰期間帶多個簽章，任一個正確即可
+	header.Set("Examplepay-Signature", SignTimestamped("old", body, now)+",v1="+timestampedSignature("secret", strconv.FormatInt(now.Unix(), 10), body))
+	assert.NoError(t, v.Verify(header, body, now))
+
+	header.Set("Examplepay-Signature", SignTimestamped("secret", body, now))
+	assert.ErrorIs(t, v.Verify(header, []byte(`{"id":"evt_2"}`), now), ErrInvalidSignature)
+	assert.Error(t, v.Verify(header, body, now.Add(DefaultTolerance+time.Second)), "stale timestamp")
+
+	header.Set("Examplepay-Signature", "v1=abc")
+	assert.ErrorIs(t, v.Verify(header, body, now), ErrInvalidSignature)
+	assert.ErrorIs(t, v.Verify(http.Header{}, body, now), ErrInvalidSignature)
+
+	empty := &TimestampedHMAC{Header: "Examplepay-Signature", Tolerance: time.Minute}
+	header.Set("Examplepay-Signature", SignTimestamped("", body, now))
+	assert.ErrorIs(t, empty.Verify(header, body, now), ErrInvalidSignature, "empty secret rejects everything")
+}
+
+func TestHMACSHA256(t *testing.T) {
+	body := []byte(`{"id":"evt_1"}`)
+	v, err := NewVerifier(SchemeHMACSHA256, "secret", "X-Signature", 0)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Signature", SignHMACSHA256("secret", body))
+	assert.NoError(t, v.Verify(header, body, time.Now()))
+
+	header.Set("X-Signature", SignHMACSHA256("other", body))
+	assert.ErrorIs(t, v.Verify(header, body, time.Now()), ErrInvalidSignature)
+	header.Set("X-Signature", "not base64!")
+	assert.ErrorIs(t, v.Verify(header, body, time.Now()), ErrInvalidSignature)
+}
+
+func TestNewVerifierRejectsUnknownScheme(t *testing.T) {
+	_, err := NewVerifier("rsa", "secret", "X-Signature", 0)
+	assert.Error(t, err)
+	_, err = NewVerifier(SchemeHMACSHA256, "secret", "", 0)
+	assert.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	event, err := Parse([]byte(`{"id":" evt_1 ","type":"payment.failed","data":{"payment_id":"p1","reason":"insufficient funds"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, &Event{ID: "evt_1", Type: EventPaymentFailed, PaymentID: "p1", Reason: "insufficient funds"}, event)
+
+	_, err = Parse([]byte(`{"type":"payment.failed"}`))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`{"id":"evt_1"}`))
+	assert.Error(t, err)
+	_, err = Parse([]byte(`not json`))
+	assert.Error(t, err)
+}
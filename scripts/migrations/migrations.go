@@ -0,0 +1,8 @@
+// Package migrations 內嵌各資料庫方言的 SQL 遷移腳本。
+// 每個方言一個子目錄，檔名以遞增版本號開頭（例如 001_initial_schema.sql）。
+package migrations
+
+import "embed"
+
+//go:embed postgres/*.sql sqlite/*.sql
+var FS embed.FS
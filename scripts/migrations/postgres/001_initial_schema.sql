@@ -1,6 +1,12 @@
 -- Create extension for UUID generation
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+-- Track applied migrations so the service can migrate on startup
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT PRIMARY KEY,
+    applied_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
 -- Create merchants table
 CREATE TABLE merchants (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
@@ -74,4 +80,6 @@ INSERT INTO merchants (id, name, email, api_key, is_active) VALUES
 
 INSERT INTO customers (id, name, email, phone) VALUES
     ('550e8400-e29b-41d4-a716-446655440101', 'John Doe', 'john@example.com', '+1234567890'),
-    ('550e8400-e29b-41d4-a716-446655440102', 'Jane Smith', 'jane@example.com', '+1234567891');
\ No newline at end of file
+    ('550e8400-e29b-41d4-a716-446655440102', 'Jane Smith', 'jane@example.com', '+1234567891');
+
+INSERT INTO schema_migrations (version) VALUES (1) ON CONFLICT (version) DO NOTHING;
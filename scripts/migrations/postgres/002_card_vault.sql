@@ -0,0 +1,21 @@
+-- Card vault: PANs are envelope-encrypted, CVVs are never stored
+CREATE TABLE cards (
+    token VARCHAR(64) PRIMARY KEY,
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    brand VARCHAR(20) NOT NULL,
+    last4 VARCHAR(4) NOT NULL,
+    exp_month INTEGER NOT NULL,
+    exp_year INTEGER NOT NULL,
+    fingerprint VARCHAR(64) NOT NULL,
+    encrypted_pan BYTEA NOT NULL,
+    encrypted_key BYTEA NOT NULL,
+    key_id VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_cards_merchant_fingerprint ON cards(merchant_id, fingerprint);
+
+-- Payments reference vaulted cards by token instead of raw card data
+ALTER TABLE payments ADD COLUMN payment_method_token VARCHAR(64) NOT NULL DEFAULT '';
+
+INSERT INTO schema_migrations (version) VALUES (2) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,31 @@
+-- Saved payment methods let repeat customers pay without re-entering details
+CREATE TABLE payment_methods (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    type VARCHAR(50) NOT NULL,
+    token VARCHAR(255) NOT NULL,
+    last4 VARCHAR(4) NOT NULL DEFAULT '',
+    brand VARCHAR(20) NOT NULL DEFAULT '',
+    exp_month INTEGER NOT NULL DEFAULT 0,
+    exp_year INTEGER NOT NULL DEFAULT 0,
+    billing_name VARCHAR(255) NOT NULL DEFAULT '',
+    billing_email VARCHAR(255) NOT NULL DEFAULT '',
+    billing_phone VARCHAR(50) NOT NULL DEFAULT '',
+    billing_line1 VARCHAR(255) NOT NULL DEFAULT '',
+    billing_line2 VARCHAR(255) NOT NULL DEFAULT '',
+    billing_city VARCHAR(100) NOT NULL DEFAULT '',
+    billing_postal_code VARCHAR(20) NOT NULL DEFAULT '',
+    billing_country VARCHAR(2) NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_payment_methods_customer_id ON payment_methods(customer_id);
+-- 每位客戶最多一個預設付款方式
+CREATE UNIQUE INDEX idx_payment_methods_default ON payment_methods(customer_id) WHERE is_default;
+
+ALTER TABLE payments ADD COLUMN payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+INSERT INTO schema_migrations (version) VALUES (3) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,46 @@
+-- Recurring billing: plans define the price and interval, subscriptions track billing periods
+CREATE TABLE plans (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    name VARCHAR(255) NOT NULL,
+    amount BIGINT NOT NULL, -- 以分為單位
+    currency VARCHAR(3) NOT NULL,
+    billing_interval VARCHAR(10) NOT NULL,
+    interval_count INTEGER NOT NULL DEFAULT 1,
+    trial_days INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_plans_merchant_id ON plans(merchant_id);
+
+CREATE TABLE subscriptions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    plan_id UUID NOT NULL REFERENCES plans(id),
+    payment_method_id UUID REFERENCES payment_methods(id) ON DELETE SET NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    status VARCHAR(20) NOT NULL,
+    billing_anchor TIMESTAMP WITH TIME ZONE NOT NULL,
+    current_period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    current_period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    trial_end TIMESTAMP WITH TIME ZONE,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT false,
+    canceled_at TIMESTAMP WITH TIME ZONE,
+    proration_balance BIGINT NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    next_retry_at TIMESTAMP WITH TIME ZONE,
+    latest_payment_id UUID REFERENCES payments(id),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_subscriptions_merchant_id ON subscriptions(merchant_id);
+CREATE INDEX idx_subscriptions_customer_id ON subscriptions(customer_id);
+-- 排程器只掃描仍在計費中的訂閱
+CREATE INDEX idx_subscriptions_due ON subscriptions(current_period_end)
+    WHERE status IN ('trialing', 'active', 'past_due');
+
+INSERT INTO schema_migrations (version) VALUES (4) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,46 @@
+-- Invoices with line items; payments may reference the invoice they settle
+CREATE TABLE invoices (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    customer_id UUID NOT NULL REFERENCES customers(id),
+    number VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    memo TEXT NOT NULL DEFAULT '',
+    subtotal BIGINT NOT NULL DEFAULT 0, -- 以分為單位
+    discount_total BIGINT NOT NULL DEFAULT 0,
+    tax_total BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    due_date TIMESTAMP WITH TIME ZONE,
+    payment_id UUID REFERENCES payments(id),
+    finalized_at TIMESTAMP WITH TIME ZONE,
+    paid_at TIMESTAMP WITH TIME ZONE,
+    voided_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_invoices_merchant_id ON invoices(merchant_id);
+CREATE INDEX idx_invoices_customer_id ON invoices(customer_id);
+-- 帳單編號在定稿時產生，同一商戶內唯一
+CREATE UNIQUE INDEX idx_invoices_number ON invoices(merchant_id, number) WHERE number <> '';
+
+CREATE TABLE invoice_line_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    invoice_id UUID NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_amount BIGINT NOT NULL,
+    discount BIGINT NOT NULL DEFAULT 0,
+    tax_name VARCHAR(50) NOT NULL DEFAULT '',
+    tax_rate INTEGER NOT NULL DEFAULT 0, -- 萬分比
+    amount BIGINT NOT NULL,
+    tax_amount BIGINT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_invoice_line_items_invoice_id ON invoice_line_items(invoice_id, position);
+
+ALTER TABLE payments ADD COLUMN invoice_id UUID REFERENCES invoices(id);
+
+INSERT INTO schema_migrations (version) VALUES (5) ON CONFLICT (version) DO NOTHING;
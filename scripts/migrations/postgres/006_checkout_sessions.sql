@@ -0,0 +1,24 @@
+-- Hosted checkout sessions
+CREATE TABLE checkout_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    customer_id UUID REFERENCES customers(id),
+    customer_email VARCHAR(255) NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL, -- 以分為單位
+    currency VARCHAR(3) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    allowed_methods VARCHAR(255) NOT NULL, -- 以逗號分隔的付款方式
+    success_url TEXT NOT NULL,
+    cancel_url TEXT NOT NULL,
+    payment_id UUID REFERENCES payments(id),
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_checkout_sessions_merchant_id ON checkout_sessions(merchant_id);
+
+INSERT INTO schema_migrations (version) VALUES (6) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+-- Payment links
+CREATE TABLE payment_links (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL DEFAULT 0, -- 以分為單位，0 表示由客戶輸入
+    currency VARCHAR(3) NOT NULL,
+    single_use BOOLEAN NOT NULL DEFAULT FALSE,
+    quantity_limit INTEGER,
+    payment_count INTEGER NOT NULL DEFAULT 0,
+    allowed_methods VARCHAR(255) NOT NULL, -- 以逗號分隔的付款方式
+    success_url TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_payment_links_merchant_id ON payment_links(merchant_id);
+
+ALTER TABLE payments ADD COLUMN payment_link_id UUID REFERENCES payment_links(id);
+CREATE INDEX idx_payments_payment_link_id ON payments(payment_link_id);
+
+ALTER TABLE checkout_sessions ADD COLUMN payment_link_id UUID REFERENCES payment_links(id);
+
+INSERT INTO schema_migrations (version) VALUES (7) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,20 @@
+-- Background job queue
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(100) NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}', -- JSON
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    locked_by VARCHAR(64) NOT NULL DEFAULT '', -- 取得工作時寫入的識別碼
+    locked_until TIMESTAMP WITH TIME ZONE,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- worker 以 SELECT ... FOR UPDATE SKIP LOCKED 輪詢 queued 與逾時的 running 工作
+CREATE INDEX idx_jobs_status_run_at ON jobs(status, run_at);
+CREATE INDEX idx_jobs_locked_by ON jobs(locked_by);
+
+INSERT INTO schema_migrations (version) VALUES (8) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,39 @@
+-- Bank transfer instructions and incoming bank credits
+CREATE TABLE bank_transfers (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    reference VARCHAR(32) NOT NULL UNIQUE,
+    virtual_account VARCHAR(32) NOT NULL UNIQUE,
+    bank_name VARCHAR(255) NOT NULL DEFAULT '',
+    amount_expected BIGINT NOT NULL, -- 以分為單位
+    amount_received BIGINT NOT NULL DEFAULT 0,
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'awaiting_funds',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 逾期排程查詢仍在等待款項的匯款
+CREATE INDEX idx_bank_transfers_status_expires_at ON bank_transfers(status, expires_at);
+
+CREATE TABLE bank_credits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    transaction_id VARCHAR(255) NOT NULL UNIQUE, -- 銀行交易序號，用於去除重複匯入
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    virtual_account VARCHAR(64) NOT NULL DEFAULT '',
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    payer_name VARCHAR(255) NOT NULL DEFAULT '',
+    received_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'unmatched',
+    payment_id UUID REFERENCES payments(id),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_bank_credits_status_received_at ON bank_credits(status, received_at);
+CREATE INDEX idx_bank_credits_payment_id ON bank_credits(payment_id);
+
+INSERT INTO schema_migrations (version) VALUES (9) ON CONFLICT (version) DO NOTHING;
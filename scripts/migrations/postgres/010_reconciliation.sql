@@ -0,0 +1,52 @@
+-- Imported bank statements and their reconciliation against payments
+CREATE TABLE statements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    format VARCHAR(20) NOT NULL,
+    bank_statement_id VARCHAR(255) NOT NULL DEFAULT '',
+    account VARCHAR(64) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL DEFAULT '',
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    period_end TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_statements_created_at ON statements(created_at);
+
+CREATE TABLE statement_lines (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    statement_id UUID NOT NULL REFERENCES statements(id) ON DELETE CASCADE,
+    transaction_id VARCHAR(255) NOT NULL,
+    booking_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    amount BIGINT NOT NULL, -- 以分為單位，支出為負數
+    currency VARCHAR(3) NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    counterparty VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL,
+    payment_id UUID REFERENCES payments(id),
+    note TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_statement_lines_statement_id ON statement_lines(statement_id, booking_date);
+
+-- 期間內已完成但對帳單上沒有對應交易的付款
+CREATE TABLE statement_exceptions (
+    statement_id UUID NOT NULL REFERENCES statements(id) ON DELETE CASCADE,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    reference VARCHAR(255) NOT NULL DEFAULT '',
+    completed_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    resolved_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (statement_id, payment_id)
+);
+
+-- 對帳時依完成時間查詢期間內的付款
+CREATE INDEX idx_payments_completed_at ON payments(completed_at) WHERE status = 'completed';
+
+INSERT INTO schema_migrations (version) VALUES (10) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,17 @@
+-- Customer confirmation step for digital wallet payments
+CREATE TABLE wallet_actions (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    type VARCHAR(32) NOT NULL,
+    redirect_url TEXT NOT NULL DEFAULT '',
+    qr_payload TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- 逾時排程查詢仍在等待確認的步驟
+CREATE INDEX idx_wallet_actions_status_expires_at ON wallet_actions(status, expires_at);
+
+INSERT INTO schema_migrations (version) VALUES (11) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,21 @@
+-- Inbound gateway webhooks, kept for deduplication and replay
+CREATE TABLE webhook_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    provider VARCHAR(50) NOT NULL,
+    event_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payment_id UUID, -- 網關通知的付款，可能不存在於系統中，因此不設外鍵
+    status VARCHAR(20) NOT NULL DEFAULT 'received',
+    payload TEXT NOT NULL, -- 原始內容
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    processed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_webhook_events_status ON webhook_events(status);
+CREATE INDEX idx_webhook_events_payment_id ON webhook_events(payment_id);
+
+INSERT INTO schema_migrations (version) VALUES (12) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,62 @@
+-- Customer disputes (chargebacks) on completed payments
+CREATE TABLE disputes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL UNIQUE REFERENCES payments(id),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    reason VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'needs_response',
+    evidence_due_by TIMESTAMP WITH TIME ZONE NOT NULL,
+    submitted_at TIMESTAMP WITH TIME ZONE,
+    closed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_disputes_merchant_id_created_at ON disputes(merchant_id, created_at);
+
+CREATE TABLE dispute_evidence (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    dispute_id UUID NOT NULL REFERENCES disputes(id),
+    description TEXT NOT NULL DEFAULT '',
+    file_name VARCHAR(255) NOT NULL DEFAULT '',
+    content_type VARCHAR(100) NOT NULL DEFAULT '',
+    size BIGINT NOT NULL DEFAULT 0,
+    storage_key VARCHAR(255) NOT NULL DEFAULT '', -- 附件在證據目錄中的位置
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_dispute_evidence_dispute_id ON dispute_evidence(dispute_id);
+
+CREATE TABLE dispute_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    type VARCHAR(50) NOT NULL,
+    dispute_id UUID NOT NULL REFERENCES disputes(id),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    status VARCHAR(20) NOT NULL,
+    amount BIGINT NOT NULL,
+    currency VARCHAR(3) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_dispute_events_merchant_id_created_at ON dispute_events(merchant_id, created_at);
+
+-- Merchant balance adjustments; a dispute is reversed at most once
+CREATE TABLE ledger_entries (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    dispute_id UUID REFERENCES disputes(id),
+    type VARCHAR(32) NOT NULL,
+    amount BIGINT NOT NULL, -- 負數表示從商戶扣回
+    currency VARCHAR(3) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (dispute_id, type)
+);
+
+CREATE INDEX idx_ledger_entries_merchant_id_created_at ON ledger_entries(merchant_id, created_at);
+
+INSERT INTO schema_migrations (version) VALUES (13) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+-- Per-merchant transaction limits; an empty method applies to all payment methods
+CREATE TABLE merchant_limits (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    method VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    max_amount BIGINT NOT NULL DEFAULT 0, -- 0 表示不限制
+    daily_amount BIGINT NOT NULL DEFAULT 0,
+    daily_count INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (merchant_id, method, currency)
+);
+
+-- Daily usage counters, incremented with conditional updates when payments are created
+CREATE TABLE merchant_limit_usage (
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    method VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    day VARCHAR(10) NOT NULL, -- UTC 日期，格式為 YYYY-MM-DD
+    amount BIGINT NOT NULL DEFAULT 0,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (merchant_id, method, currency, day)
+);
+
+INSERT INTO schema_migrations (version) VALUES (15) ON CONFLICT (version) DO NOTHING;
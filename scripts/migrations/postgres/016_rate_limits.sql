@@ -0,0 +1,11 @@
+-- Merchant tiers select the request rate limits from config
+ALTER TABLE merchants ADD COLUMN tier VARCHAR(50) NOT NULL DEFAULT 'standard';
+
+-- Token buckets shared by all instances when rate_limit.store is database
+CREATE TABLE rate_limit_buckets (
+    bucket_key VARCHAR(255) PRIMARY KEY, -- 商戶 ID，路由規則另加方法與路徑
+    tokens DOUBLE PRECISION NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+
+INSERT INTO schema_migrations (version) VALUES (16) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,14 @@
+-- Shared secrets for HMAC request signing
+ALTER TABLE merchants ADD COLUMN signing_secret VARCHAR(255) NOT NULL DEFAULT ''; -- 空字串表示不接受簽章
+
+-- Nonces of signed requests, kept until the request timestamp leaves the allowed window
+CREATE TABLE request_nonces (
+    nonce_key VARCHAR(255) PRIMARY KEY, -- 商戶 ID 與 nonce
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX idx_request_nonces_expires_at ON request_nonces(expires_at);
+
+UPDATE merchants SET signing_secret = 'signing_secret_merchant_1'
+WHERE id = '550e8400-e29b-41d4-a716-446655440001';
+
+INSERT INTO schema_migrations (version) VALUES (18) ON CONFLICT (version) DO NOTHING;
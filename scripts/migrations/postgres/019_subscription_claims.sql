@@ -0,0 +1,4 @@
+-- Billing claims: a scheduler instance locks a due subscription before charging it
+ALTER TABLE subscriptions ADD COLUMN billing_locked_until TIMESTAMP WITH TIME ZONE; -- 逾時後其他實例可重新取得
+
+INSERT INTO schema_migrations (version) VALUES (19) ON CONFLICT (version) DO NOTHING;
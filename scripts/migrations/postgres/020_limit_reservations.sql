@@ -0,0 +1,13 @@
+-- Usage counters each payment incremented, so releasing a payment undoes exactly its reservation
+CREATE TABLE merchant_limit_reservations (
+    payment_id UUID NOT NULL, -- 在付款寫入前建立，因此不參照 payments
+    merchant_id UUID NOT NULL REFERENCES merchants(id),
+    method VARCHAR(50) NOT NULL DEFAULT '',
+    currency VARCHAR(3) NOT NULL,
+    day VARCHAR(10) NOT NULL, -- 付款建立的 UTC 日期
+    amount BIGINT NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (payment_id, method, currency)
+);
+
+INSERT INTO schema_migrations (version) VALUES (20) ON CONFLICT (version) DO NOTHING;
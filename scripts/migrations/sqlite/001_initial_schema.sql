@@ -0,0 +1,59 @@
+-- Track applied migrations so the service can migrate on startup
+CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create merchants table
+CREATE TABLE merchants (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT UNIQUE NOT NULL,
+    api_key TEXT UNIQUE NOT NULL,
+    is_active BOOLEAN DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create customers table
+CREATE TABLE customers (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    email TEXT UNIQUE NOT NULL,
+    phone TEXT,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create payments table
+CREATE TABLE payments (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    amount INTEGER NOT NULL, -- 以分為單位
+    currency TEXT NOT NULL DEFAULT 'USD',
+    method TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    description TEXT,
+    reference TEXT UNIQUE,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    completed_at DATETIME
+);
+
+-- Create indexes for better performance
+CREATE INDEX idx_payments_merchant_id ON payments(merchant_id);
+CREATE INDEX idx_payments_customer_id ON payments(customer_id);
+CREATE INDEX idx_payments_status ON payments(status);
+CREATE INDEX idx_payments_created_at ON payments(created_at);
+
+-- Insert sample data
+INSERT INTO merchants (id, name, email, api_key, is_active) VALUES
+    ('550e8400-e29b-41d4-a716-446655440001', 'Test Merchant 1', 'merchant1@example.com', 'api_key_merchant_1', 1),
+    ('550e8400-e29b-41d4-a716-446655440002', 'Test Merchant 2', 'merchant2@example.com', 'api_key_merchant_2', 1);
+
+INSERT INTO customers (id, name, email, phone) VALUES
+    ('550e8400-e29b-41d4-a716-446655440101', 'John Doe', 'john@example.com', '+1234567890'),
+    ('550e8400-e29b-41d4-a716-446655440102', 'Jane Smith', 'jane@example.com', '+1234567891');
+
+INSERT INTO schema_migrations (version) VALUES (1) ON CONFLICT (version) DO NOTHING;
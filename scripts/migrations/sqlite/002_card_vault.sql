@@ -0,0 +1,21 @@
+-- Card vault: PANs are envelope-encrypted, CVVs are never stored
+CREATE TABLE cards (
+    token TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    brand TEXT NOT NULL,
+    last4 TEXT NOT NULL,
+    exp_month INTEGER NOT NULL,
+    exp_year INTEGER NOT NULL,
+    fingerprint TEXT NOT NULL,
+    encrypted_pan BLOB NOT NULL,
+    encrypted_key BLOB NOT NULL,
+    key_id TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_cards_merchant_fingerprint ON cards(merchant_id, fingerprint);
+
+-- Payments reference vaulted cards by token instead of raw card data
+ALTER TABLE payments ADD COLUMN payment_method_token TEXT NOT NULL DEFAULT '';
+
+INSERT INTO schema_migrations (version) VALUES (2) ON CONFLICT (version) DO NOTHING;
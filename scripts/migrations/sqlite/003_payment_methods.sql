@@ -0,0 +1,31 @@
+-- Saved payment methods let repeat customers pay without re-entering details
+CREATE TABLE payment_methods (
+    id TEXT PRIMARY KEY,
+    customer_id TEXT NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    type TEXT NOT NULL,
+    token TEXT NOT NULL,
+    last4 TEXT NOT NULL DEFAULT '',
+    brand TEXT NOT NULL DEFAULT '',
+    exp_month INTEGER NOT NULL DEFAULT 0,
+    exp_year INTEGER NOT NULL DEFAULT 0,
+    billing_name TEXT NOT NULL DEFAULT '',
+    billing_email TEXT NOT NULL DEFAULT '',
+    billing_phone TEXT NOT NULL DEFAULT '',
+    billing_line1 TEXT NOT NULL DEFAULT '',
+    billing_line2 TEXT NOT NULL DEFAULT '',
+    billing_city TEXT NOT NULL DEFAULT '',
+    billing_postal_code TEXT NOT NULL DEFAULT '',
+    billing_country TEXT NOT NULL DEFAULT '',
+    is_default BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_methods_customer_id ON payment_methods(customer_id);
+-- 每位客戶最多一個預設付款方式
+CREATE UNIQUE INDEX idx_payment_methods_default ON payment_methods(customer_id) WHERE is_default;
+
+ALTER TABLE payments ADD COLUMN payment_method_id TEXT REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+INSERT INTO schema_migrations (version) VALUES (3) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,46 @@
+-- Recurring billing: plans define the price and interval, subscriptions track billing periods
+CREATE TABLE plans (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    name TEXT NOT NULL,
+    amount INTEGER NOT NULL, -- 以分為單位
+    currency TEXT NOT NULL,
+    billing_interval TEXT NOT NULL,
+    interval_count INTEGER NOT NULL DEFAULT 1,
+    trial_days INTEGER NOT NULL DEFAULT 0,
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_plans_merchant_id ON plans(merchant_id);
+
+CREATE TABLE subscriptions (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    plan_id TEXT NOT NULL REFERENCES plans(id),
+    payment_method_id TEXT REFERENCES payment_methods(id) ON DELETE SET NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    status TEXT NOT NULL,
+    billing_anchor DATETIME NOT NULL,
+    current_period_start DATETIME NOT NULL,
+    current_period_end DATETIME NOT NULL,
+    trial_end DATETIME,
+    cancel_at_period_end BOOLEAN NOT NULL DEFAULT 0,
+    canceled_at DATETIME,
+    proration_balance INTEGER NOT NULL DEFAULT 0,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    next_retry_at DATETIME,
+    latest_payment_id TEXT REFERENCES payments(id),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_subscriptions_merchant_id ON subscriptions(merchant_id);
+CREATE INDEX idx_subscriptions_customer_id ON subscriptions(customer_id);
+-- 排程器只掃描仍在計費中的訂閱
+CREATE INDEX idx_subscriptions_due ON subscriptions(current_period_end)
+    WHERE status IN ('trialing', 'active', 'past_due');
+
+INSERT INTO schema_migrations (version) VALUES (4) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,46 @@
+-- Invoices with line items; payments may reference the invoice they settle
+CREATE TABLE invoices (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    number TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL,
+    memo TEXT NOT NULL DEFAULT '',
+    subtotal INTEGER NOT NULL DEFAULT 0, -- 以分為單位
+    discount_total INTEGER NOT NULL DEFAULT 0,
+    tax_total INTEGER NOT NULL DEFAULT 0,
+    total INTEGER NOT NULL DEFAULT 0,
+    due_date DATETIME,
+    payment_id TEXT REFERENCES payments(id),
+    finalized_at DATETIME,
+    paid_at DATETIME,
+    voided_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_invoices_merchant_id ON invoices(merchant_id);
+CREATE INDEX idx_invoices_customer_id ON invoices(customer_id);
+-- 帳單編號在定稿時產生，同一商戶內唯一
+CREATE UNIQUE INDEX idx_invoices_number ON invoices(merchant_id, number) WHERE number <> '';
+
+CREATE TABLE invoice_line_items (
+    id TEXT PRIMARY KEY,
+    invoice_id TEXT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    description TEXT NOT NULL,
+    quantity INTEGER NOT NULL,
+    unit_amount INTEGER NOT NULL,
+    discount INTEGER NOT NULL DEFAULT 0,
+    tax_name TEXT NOT NULL DEFAULT '',
+    tax_rate INTEGER NOT NULL DEFAULT 0, -- 萬分比
+    amount INTEGER NOT NULL,
+    tax_amount INTEGER NOT NULL DEFAULT 0
+);
+
+CREATE INDEX idx_invoice_line_items_invoice_id ON invoice_line_items(invoice_id, position);
+
+ALTER TABLE payments ADD COLUMN invoice_id TEXT REFERENCES invoices(id);
+
+INSERT INTO schema_migrations (version) VALUES (5) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,24 @@
+-- Hosted checkout sessions
+CREATE TABLE checkout_sessions (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    customer_id TEXT REFERENCES customers(id),
+    customer_email TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL, -- 以分為單位
+    currency TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    reference TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    allowed_methods TEXT NOT NULL, -- 以逗號分隔的付款方式
+    success_url TEXT NOT NULL,
+    cancel_url TEXT NOT NULL,
+    payment_id TEXT REFERENCES payments(id),
+    expires_at DATETIME NOT NULL,
+    completed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_checkout_sessions_merchant_id ON checkout_sessions(merchant_id);
+
+INSERT INTO schema_migrations (version) VALUES (6) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+-- Payment links
+CREATE TABLE payment_links (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    name TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL DEFAULT 0, -- 以分為單位，0 表示由客戶輸入
+    currency TEXT NOT NULL,
+    single_use BOOLEAN NOT NULL DEFAULT 0,
+    quantity_limit INTEGER,
+    payment_count INTEGER NOT NULL DEFAULT 0,
+    allowed_methods TEXT NOT NULL, -- 以逗號分隔的付款方式
+    success_url TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT 1,
+    expires_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_payment_links_merchant_id ON payment_links(merchant_id);
+
+ALTER TABLE payments ADD COLUMN payment_link_id TEXT REFERENCES payment_links(id);
+CREATE INDEX idx_payments_payment_link_id ON payments(payment_link_id);
+
+ALTER TABLE checkout_sessions ADD COLUMN payment_link_id TEXT REFERENCES payment_links(id);
+
+INSERT INTO schema_migrations (version) VALUES (7) ON CONFLICT (version) DO NOTHING;
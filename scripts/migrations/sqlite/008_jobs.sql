@@ -0,0 +1,19 @@
+-- Background job queue
+CREATE TABLE jobs (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    payload TEXT NOT NULL DEFAULT '{}', -- JSON
+    status TEXT NOT NULL DEFAULT 'queued',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by TEXT NOT NULL DEFAULT '', -- 取得工作時寫入的識別碼
+    locked_until DATETIME,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_status_run_at ON jobs(status, run_at);
+CREATE INDEX idx_jobs_locked_by ON jobs(locked_by);
+
+INSERT INTO schema_migrations (version) VALUES (8) ON CONFLICT (version) DO NOTHING;
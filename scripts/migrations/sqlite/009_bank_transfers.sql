@@ -0,0 +1,38 @@
+-- Bank transfer instructions and incoming bank credits
+CREATE TABLE bank_transfers (
+    payment_id TEXT PRIMARY KEY REFERENCES payments(id),
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    reference TEXT NOT NULL UNIQUE,
+    virtual_account TEXT NOT NULL UNIQUE,
+    bank_name TEXT NOT NULL DEFAULT '',
+    amount_expected INTEGER NOT NULL, -- 以分為單位
+    amount_received INTEGER NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'awaiting_funds',
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bank_transfers_status_expires_at ON bank_transfers(status, expires_at);
+
+CREATE TABLE bank_credits (
+    id TEXT PRIMARY KEY,
+    transaction_id TEXT NOT NULL UNIQUE, -- 銀行交易序號，用於去除重複匯入
+    reference TEXT NOT NULL DEFAULT '',
+    virtual_account TEXT NOT NULL DEFAULT '',
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    payer_name TEXT NOT NULL DEFAULT '',
+    received_at DATETIME NOT NULL,
+    status TEXT NOT NULL DEFAULT 'unmatched',
+    payment_id TEXT REFERENCES payments(id),
+    note TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_bank_credits_status_received_at ON bank_credits(status, received_at);
+CREATE INDEX idx_bank_credits_payment_id ON bank_credits(payment_id);
+
+INSERT INTO schema_migrations (version) VALUES (9) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,50 @@
+-- Imported bank statements and their reconciliation against payments
+CREATE TABLE statements (
+    id TEXT PRIMARY KEY,
+    format TEXT NOT NULL,
+    bank_statement_id TEXT NOT NULL DEFAULT '',
+    account TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL DEFAULT '',
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_statements_created_at ON statements(created_at);
+
+CREATE TABLE statement_lines (
+    id TEXT PRIMARY KEY,
+    statement_id TEXT NOT NULL REFERENCES statements(id) ON DELETE CASCADE,
+    transaction_id TEXT NOT NULL,
+    booking_date DATETIME NOT NULL,
+    amount INTEGER NOT NULL, -- 以分為單位，支出為負數
+    currency TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    counterparty TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL,
+    payment_id TEXT REFERENCES payments(id),
+    note TEXT NOT NULL DEFAULT '',
+    resolved_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_statement_lines_statement_id ON statement_lines(statement_id, booking_date);
+
+CREATE TABLE statement_exceptions (
+    statement_id TEXT NOT NULL REFERENCES statements(id) ON DELETE CASCADE,
+    payment_id TEXT NOT NULL REFERENCES payments(id),
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    reference TEXT NOT NULL DEFAULT '',
+    completed_at DATETIME NOT NULL,
+    status TEXT NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    resolved_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (statement_id, payment_id)
+);
+
+CREATE INDEX idx_payments_completed_at ON payments(completed_at) WHERE status = 'completed';
+
+INSERT INTO schema_migrations (version) VALUES (10) ON CONFLICT (version) DO NOTHING;
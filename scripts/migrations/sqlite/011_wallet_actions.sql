@@ -0,0 +1,16 @@
+-- Customer confirmation step for digital wallet payments
+CREATE TABLE wallet_actions (
+    payment_id TEXT PRIMARY KEY REFERENCES payments(id),
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    type TEXT NOT NULL,
+    redirect_url TEXT NOT NULL DEFAULT '',
+    qr_payload TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_wallet_actions_status_expires_at ON wallet_actions(status, expires_at);
+
+INSERT INTO schema_migrations (version) VALUES (11) ON CONFLICT (version) DO NOTHING;
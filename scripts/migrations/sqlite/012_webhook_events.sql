@@ -0,0 +1,21 @@
+-- Inbound gateway webhooks, kept for deduplication and replay
+CREATE TABLE webhook_events (
+    id TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    event_id TEXT NOT NULL,
+    event_type TEXT NOT NULL,
+    payment_id TEXT, -- 網關通知的付款，可能不存在於系統中，因此不設外鍵
+    status TEXT NOT NULL DEFAULT 'received',
+    payload TEXT NOT NULL, -- 原始內容
+    error TEXT NOT NULL DEFAULT '',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    processed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, event_id)
+);
+
+CREATE INDEX idx_webhook_events_status ON webhook_events(status);
+CREATE INDEX idx_webhook_events_payment_id ON webhook_events(payment_id);
+
+INSERT INTO schema_migrations (version) VALUES (12) ON CONFLICT (version) DO NOTHING;
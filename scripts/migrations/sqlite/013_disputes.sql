@@ -0,0 +1,62 @@
+-- Customer disputes (chargebacks) on completed payments
+CREATE TABLE disputes (
+    id TEXT PRIMARY KEY,
+    payment_id TEXT NOT NULL UNIQUE REFERENCES payments(id),
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    reason TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'needs_response',
+    evidence_due_by DATETIME NOT NULL,
+    submitted_at DATETIME,
+    closed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_disputes_merchant_id_created_at ON disputes(merchant_id, created_at);
+
+CREATE TABLE dispute_evidence (
+    id TEXT PRIMARY KEY,
+    dispute_id TEXT NOT NULL REFERENCES disputes(id),
+    description TEXT NOT NULL DEFAULT '',
+    file_name TEXT NOT NULL DEFAULT '',
+    content_type TEXT NOT NULL DEFAULT '',
+    size INTEGER NOT NULL DEFAULT 0,
+    storage_key TEXT NOT NULL DEFAULT '', -- 附件在證據目錄中的位置
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dispute_evidence_dispute_id ON dispute_evidence(dispute_id);
+
+CREATE TABLE dispute_events (
+    id TEXT PRIMARY KEY,
+    type TEXT NOT NULL,
+    dispute_id TEXT NOT NULL REFERENCES disputes(id),
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    payment_id TEXT NOT NULL REFERENCES payments(id),
+    status TEXT NOT NULL,
+    amount INTEGER NOT NULL,
+    currency TEXT NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_dispute_events_merchant_id_created_at ON dispute_events(merchant_id, created_at);
+
+-- Merchant balance adjustments; a dispute is reversed at most once
+CREATE TABLE ledger_entries (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    payment_id TEXT NOT NULL REFERENCES payments(id),
+    dispute_id TEXT REFERENCES disputes(id),
+    type TEXT NOT NULL,
+    amount INTEGER NOT NULL, -- 負數表示從商戶扣回
+    currency TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (dispute_id, type)
+);
+
+CREATE INDEX idx_ledger_entries_merchant_id_created_at ON ledger_entries(merchant_id, created_at);
+
+INSERT INTO schema_migrations (version) VALUES (13) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Risk screening
+ALTER TABLE payments ADD COLUMN risk_score INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE payments ADD COLUMN risk_decision TEXT NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN risk_reasons TEXT NOT NULL DEFAULT ''; -- 以逗號分隔的規則代碼
+ALTER TABLE payments ADD COLUMN client_ip TEXT NOT NULL DEFAULT '';
+
+-- 付款頻率規則依客戶與 IP 統計時間窗內的付款數
+CREATE INDEX idx_payments_customer_id_created_at ON payments(customer_id, created_at);
+CREATE INDEX idx_payments_client_ip_created_at ON payments(client_ip, created_at);
+
+INSERT INTO schema_migrations (version) VALUES (14) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,27 @@
+-- Per-merchant transaction limits; an empty method applies to all payment methods
+CREATE TABLE merchant_limits (
+    id TEXT PRIMARY KEY,
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    method TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL,
+    max_amount INTEGER NOT NULL DEFAULT 0, -- 0 表示不限制
+    daily_amount INTEGER NOT NULL DEFAULT 0,
+    daily_count INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (merchant_id, method, currency)
+);
+
+-- Daily usage counters, incremented with conditional updates when payments are created
+CREATE TABLE merchant_limit_usage (
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    method TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL,
+    day TEXT NOT NULL, -- UTC 日期，格式為 YYYY-MM-DD
+    amount INTEGER NOT NULL DEFAULT 0,
+    count INTEGER NOT NULL DEFAULT 0,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (merchant_id, method, currency, day)
+);
+
+INSERT INTO schema_migrations (version) VALUES (15) ON CONFLICT (version) DO NOTHING;
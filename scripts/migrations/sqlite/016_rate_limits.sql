@@ -0,0 +1,11 @@
+-- Merchant tiers select the request rate limits from config
+ALTER TABLE merchants ADD COLUMN tier TEXT NOT NULL DEFAULT 'standard';
+
+-- Token buckets shared by all instances when rate_limit.store is database
+CREATE TABLE rate_limit_buckets (
+    bucket_key TEXT PRIMARY KEY, -- 商戶 ID，路由規則另加方法與路徑
+    tokens REAL NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+INSERT INTO schema_migrations (version) VALUES (16) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,11 @@
+-- Publishable keys for browser calls and the origins allowed to use them
+ALTER TABLE merchants ADD COLUMN publishable_key TEXT; -- 未發行時為 NULL
+ALTER TABLE merchants ADD COLUMN allowed_origins TEXT NOT NULL DEFAULT ''; -- 以逗號分隔
+CREATE UNIQUE INDEX idx_merchants_publishable_key ON merchants(publishable_key);
+
+UPDATE merchants SET publishable_key = 'pk_merchant_1', allowed_origins = 'http://localhost:3000'
+WHERE id = '550e8400-e29b-41d4-a716-446655440001';
+UPDATE merchants SET publishable_key = 'pk_merchant_2'
+WHERE id = '550e8400-e29b-41d4-a716-446655440002';
+
+INSERT INTO schema_migrations (version) VALUES (17) ON CONFLICT (version) DO NOTHING;
@@ -0,0 +1,13 @@
+-- Usage counters each payment incremented, so releasing a payment undoes exactly its reservation
+CREATE TABLE merchant_limit_reservations (
+    payment_id TEXT NOT NULL, -- 在付款寫入前建立，因此不參照 payments
+    merchant_id TEXT NOT NULL REFERENCES merchants(id),
+    method TEXT NOT NULL DEFAULT '',
+    currency TEXT NOT NULL,
+    day TEXT NOT NULL, -- 付款建立的 UTC 日期
+    amount INTEGER NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (payment_id, method, currency)
+);
+
+INSERT INTO schema_migrations (version) VALUES (20) ON CONFLICT (version) DO NOTHING;